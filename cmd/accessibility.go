@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/andrewhowdencom/ruf/internal/assets"
+	"github.com/andrewhowdencom/ruf/internal/model"
+	"github.com/andrewhowdencom/ruf/internal/processor"
+)
+
+// accessibilityIssues renders the content of a call as it is sent by email, and returns the
+// accessibility problems of the HTML. Calls that are not sent by email are not checked, as email is
+// the only destination type sent as HTML.
+func accessibilityIssues(cache *assets.Cache, call *model.Call) ([]processor.Issue, error) {
+	if !sentByEmail(call) {
+		return nil, nil
+	}
+
+	callData, err := cache.Data(call)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch data: %w", err)
+	}
+	data := make(map[string]interface{}, len(callData)+1)
+	for k, v := range callData {
+		data[k] = v
+	}
+	data["ScheduledAt"] = call.ScheduledAt
+
+	content, err := processor.ProcessorStack{
+		processor.NewTemplateProcessor(processor.WithFuncs(cache.Funcs(call))),
+		processor.NewMarkdownToHTMLProcessor(),
+	}.Process(call.Content, data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render content: %w", err)
+	}
+	return processor.CheckAccessibility(content)
+}
+
+// sentByEmail reports whether any destination of a call, or of one of its triggers, is an email.
+func sentByEmail(call *model.Call) bool {
+	destinations := call.Destinations
+	for _, trigger := range call.Triggers {
+		destinations = append(destinations[:len(destinations):len(destinations)], trigger.Destinations...)
+	}
+	for _, d := range destinations {
+		if d.Type == "email" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,20 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// approvalsCmd represents the approvals command
+var approvalsCmd = &cobra.Command{
+	Use:   "approvals",
+	Short: "Approve or reject the calls that require approval.",
+	Long: `Approve or reject the calls that require approval.
+
+A call with requires_approval is held once it is due, pending approval, until
+an approver approves it, and is cancelled if they reject it. Scheduled calls are
+identified by their ID, or the short ID shown by 'approvals list'.`,
+}
+
+func init() {
+	rootCmd.AddCommand(approvalsCmd)
+}
@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/andrewhowdencom/ruf/internal/approval"
+	"github.com/andrewhowdencom/ruf/internal/datastore"
+	"github.com/andrewhowdencom/ruf/internal/kv"
+	"github.com/spf13/cobra"
+)
+
+var approvalsBy string
+
+// approvalsApproveCmd represents the approvals approve command
+var approvalsApproveCmd = &cobra.Command{
+	Use:   "approve <id>",
+	Short: "Approve a scheduled call that requires approval.",
+	Long: `Approve a scheduled call that requires approval, recording who approved it and
+when. The call is sent when it is due, or on the next tick if it already is. A
+call that was rejected can be approved after all.`,
+	Annotations: mutating,
+	Args:        cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store, err := datastore.NewStore(false)
+		if err != nil {
+			return fmt.Errorf("failed to create a new datastore: %w", err)
+		}
+		defer store.Close()
+
+		return doApprovalsApprove(store, cmd.OutOrStdout(), args[0], approvalsBy, time.Now())
+	},
+}
+
+func doApprovalsApprove(store kv.Storer, w io.Writer, id, by string, now time.Time) error {
+	if by == "" {
+		return fmt.Errorf("--by is required when $USER is not set")
+	}
+
+	call, err := approval.Find(store, id)
+	if err != nil {
+		return fmt.Errorf("could not find a scheduled call with ID '%s': %w", id, err)
+	}
+	a, err := approval.Approve(store, call, by, now)
+	if err != nil {
+		return fmt.Errorf("failed to approve '%s': %w", call.ID, err)
+	}
+	fmt.Fprintf(w, "Approved call '%s' as %s; it will be sent when it is due.\n", call.ID, a.By)
+	return nil
+}
+
+func init() {
+	approvalsCmd.AddCommand(approvalsApproveCmd)
+	approvalsApproveCmd.Flags().StringVar(&approvalsBy, "by", os.Getenv("USER"), "Who is approving the call.")
+}
@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/andrewhowdencom/ruf/internal/approval"
+	"github.com/andrewhowdencom/ruf/internal/datastore"
+	"github.com/andrewhowdencom/ruf/internal/kv"
+	"github.com/andrewhowdencom/ruf/internal/owners"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// approvalsListCmd represents the approvals list command
+var approvalsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the scheduled calls that require approval.",
+	Long:  `List the scheduled calls that require approval, whether they have been approved, and by whom.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store, err := datastore.NewStore(true)
+		if err != nil {
+			return fmt.Errorf("failed to create a new datastore: %w", err)
+		}
+		defer store.Close()
+
+		router, err := buildOwners()
+		if err != nil {
+			return err
+		}
+		return doApprovalsList(store, router, viper.GetStringSlice("approvals.approvers"), cmd.OutOrStdout())
+	},
+}
+
+func doApprovalsList(store kv.Storer, router *owners.Router, approvers []string, w io.Writer) error {
+	calls, err := store.ListScheduledCalls()
+	if err != nil {
+		return fmt.Errorf("failed to list scheduled calls: %w", err)
+	}
+	sort.Slice(calls, func(i, j int) bool { return calls[i].ScheduledAt.Before(calls[j].ScheduledAt) })
+
+	table := tablewriter.NewWriter(w)
+	table.Header("Short ID", "Call ID", "Scheduled At", "Status", "Decided", "Approvers")
+	rows := 0
+	for _, call := range calls {
+		if !call.RequiresApproval {
+			continue
+		}
+		a, err := approval.Status(store, call)
+		if err != nil {
+			return fmt.Errorf("failed to get the approval of '%s': %w", call.ID, err)
+		}
+
+		decided := ""
+		if a.Status != kv.ApprovalPending {
+			decided = fmt.Sprintf("%s, %s", a.By, a.At.Format(time.RFC3339))
+			if a.Reason != "" {
+				decided += ": " + a.Reason
+			}
+		}
+		callApprovers := approvers
+		if len(callApprovers) == 0 {
+			callApprovers, _ = router.Owners(call.Call.Campaign)
+		}
+		table.Append([]string{kv.GenerateShortID(call.ID), call.ID, call.ScheduledAt.Format(time.RFC1123), string(a.Status), decided, strings.Join(callApprovers, "\n")})
+		rows++
+	}
+	if rows == 0 {
+		fmt.Fprintln(w, "No scheduled calls require approval.")
+		return nil
+	}
+	table.Render()
+	return nil
+}
+
+func init() {
+	approvalsCmd.AddCommand(approvalsListCmd)
+}
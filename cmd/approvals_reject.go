@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/andrewhowdencom/ruf/internal/approval"
+	"github.com/andrewhowdencom/ruf/internal/datastore"
+	"github.com/andrewhowdencom/ruf/internal/kv"
+	"github.com/spf13/cobra"
+)
+
+var (
+	approvalsRejectBy     string
+	approvalsRejectReason string
+)
+
+// approvalsRejectCmd represents the approvals reject command
+var approvalsRejectCmd = &cobra.Command{
+	Use:   "reject <id>",
+	Short: "Reject a scheduled call that requires approval.",
+	Long: `Reject a scheduled call that requires approval, recording who rejected it, when
+and why. The call is cancelled, and recorded as such rather than sent when it is
+due.`,
+	Annotations: mutating,
+	Args:        cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store, err := datastore.NewStore(false)
+		if err != nil {
+			return fmt.Errorf("failed to create a new datastore: %w", err)
+		}
+		defer store.Close()
+
+		return doApprovalsReject(store, cmd.OutOrStdout(), args[0], approvalsRejectBy, approvalsRejectReason, time.Now())
+	},
+}
+
+func doApprovalsReject(store kv.Storer, w io.Writer, id, by, reason string, now time.Time) error {
+	if by == "" {
+		return fmt.Errorf("--by is required when $USER is not set")
+	}
+
+	call, err := approval.Find(store, id)
+	if err != nil {
+		return fmt.Errorf("could not find a scheduled call with ID '%s': %w", id, err)
+	}
+	a, err := approval.Reject(store, call, by, reason, now)
+	if err != nil {
+		return fmt.Errorf("failed to reject '%s': %w", call.ID, err)
+	}
+	fmt.Fprintf(w, "Rejected call '%s' as %s; it will not be sent.\n", call.ID, a.By)
+	return nil
+}
+
+func init() {
+	approvalsCmd.AddCommand(approvalsRejectCmd)
+	approvalsRejectCmd.Flags().StringVar(&approvalsRejectBy, "by", os.Getenv("USER"), "Who is rejecting the call.")
+	approvalsRejectCmd.Flags().StringVar(&approvalsRejectReason, "reason", "", "Why the call is rejected.")
+}
@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/andrewhowdencom/ruf/internal/datastore"
+	"github.com/andrewhowdencom/ruf/internal/kv"
+	"github.com/andrewhowdencom/ruf/internal/model"
+	"github.com/andrewhowdencom/ruf/internal/owners"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApprovals(t *testing.T) {
+	store := datastore.NewMockStore()
+	for _, id := range []string{"launch", "press"} {
+		require.NoError(t, store.AddScheduledCall(&kv.ScheduledCall{
+			Call:        model.Call{ID: id, RequiresApproval: true, Campaign: model.Campaign{ID: "campaign", Name: "Campaign"}},
+			ScheduledAt: time.Date(2025, 6, 2, 9, 0, 0, 0, time.UTC),
+		}))
+	}
+	require.NoError(t, store.AddScheduledCall(&kv.ScheduledCall{
+		Call:        model.Call{ID: "internal", Campaign: model.Campaign{ID: "campaign"}},
+		ScheduledAt: time.Date(2025, 6, 2, 9, 0, 0, 0, time.UTC),
+	}))
+	var out bytes.Buffer
+
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	require.NoError(t, doApprovalsApprove(store, &out, kv.GenerateShortID("launch"), "jane", now))
+	assert.Contains(t, out.String(), "Approved call 'launch' as jane")
+	require.NoError(t, doApprovalsReject(store, &out, "press", "joe", "wrong date", now))
+	assert.Contains(t, out.String(), "Rejected call 'press' as joe")
+
+	out.Reset()
+	router, err := owners.New([]owners.Rule{{Match: "camp*", Owners: []string{"launch-team@example.com"}}})
+	require.NoError(t, err)
+	require.NoError(t, doApprovalsList(store, router, nil, &out))
+	assert.Contains(t, out.String(), "approved")
+	assert.Contains(t, out.String(), "jane, 2025-06-01T12:00:00Z")
+	assert.Contains(t, out.String(), "joe, 2025-06-01T12:00:00Z: wrong date")
+	assert.Contains(t, out.String(), "launch-team@example.com")
+	assert.NotContains(t, out.String(), "internal")
+
+	out.Reset()
+	require.NoError(t, doApprovalsList(store, router, []string{"legal@example.com"}, &out))
+	assert.Contains(t, out.String(), "legal@example.com")
+	assert.NotContains(t, out.String(), "launch-team@example.com")
+
+	assert.ErrorContains(t, doApprovalsApprove(store, &out, "internal", "jane", now), "does not require approval")
+	assert.ErrorContains(t, doApprovalsApprove(store, &out, "missing", "jane", now), "could not find")
+	assert.ErrorContains(t, doApprovalsReject(store, &out, "launch", "", "", now), "--by is required")
+}
@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"log/slog"
+
+	"github.com/andrewhowdencom/ruf/internal/chaos"
+	"github.com/andrewhowdencom/ruf/internal/clients/email"
+	"github.com/andrewhowdencom/ruf/internal/clients/slack"
+	"github.com/andrewhowdencom/ruf/internal/kv"
+	"github.com/spf13/viper"
+)
+
+// chaosInjector returns the fault injector configured under chaos.<name>, or nil if fault injection
+// is disabled.
+func chaosInjector(name string) *chaos.Injector {
+	if !viper.GetBool("chaos.enabled") {
+		return nil
+	}
+	fault := chaos.Fault{
+		FailureRate: viper.GetFloat64("chaos." + name + ".failure_rate"),
+		Latency:     viper.GetDuration("chaos." + name + ".latency"),
+	}
+	slog.Warn("injecting faults", "dependency", name, "failure_rate", fault.FailureRate, "latency", fault.Latency)
+	return chaos.NewInjector(name, fault)
+}
+
+// chaosStore injects the configured faults into the datastore.
+func chaosStore(store kv.Storer) kv.Storer {
+	if injector := chaosInjector("datastore"); injector != nil {
+		return chaos.Store(store, injector)
+	}
+	return store
+}
+
+// chaosSlack injects the configured faults into the Slack client.
+func chaosSlack(client slack.Client) slack.Client {
+	if injector := chaosInjector("slack"); injector != nil {
+		return chaos.Slack(client, injector)
+	}
+	return client
+}
+
+// chaosEmail injects the configured faults into the email client.
+func chaosEmail(client email.Client) email.Client {
+	if injector := chaosInjector("email"); injector != nil {
+		return chaos.Email(client, injector)
+	}
+	return client
+}
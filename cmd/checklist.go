@@ -0,0 +1,20 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// checklistCmd represents the checklist command
+var checklistCmd = &cobra.Command{
+	Use:   "checklist",
+	Short: "Sign off the checklists that gate the calls of a campaign.",
+	Long: `Sign off the checklists that gate the calls of a campaign.
+
+A campaign with a checklist has none of its calls sent until every item of the
+checklist has been signed off for that call. Scheduled calls are identified by
+their ID, or the short ID shown by 'checklist list'.`,
+}
+
+func init() {
+	rootCmd.AddCommand(checklistCmd)
+}
@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/andrewhowdencom/ruf/internal/checklist"
+	"github.com/andrewhowdencom/ruf/internal/datastore"
+	"github.com/andrewhowdencom/ruf/internal/kv"
+	"github.com/spf13/cobra"
+)
+
+var checklistBy string
+
+// checklistCheckCmd represents the checklist check command
+var checklistCheckCmd = &cobra.Command{
+	Use:   "check <id> <item>",
+	Short: "Sign off an item of the checklist of a scheduled call.",
+	Long: `Sign off an item of the checklist of a scheduled call, recording who signed it
+off and when. The call is sent once every item has been signed off.`,
+	Annotations: mutating,
+	Args:        cobra.MinimumNArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store, err := datastore.NewStore(false)
+		if err != nil {
+			return fmt.Errorf("failed to create a new datastore: %w", err)
+		}
+		defer store.Close()
+
+		return doChecklistCheck(store, cmd.OutOrStdout(), args[0], strings.Join(args[1:], " "), checklistBy, time.Now())
+	},
+}
+
+func doChecklistCheck(store kv.Storer, w io.Writer, id, item, by string, now time.Time) error {
+	if by == "" {
+		return fmt.Errorf("--by is required when $USER is not set")
+	}
+
+	call, err := checklist.Find(store, id)
+	if err != nil {
+		return fmt.Errorf("could not find a scheduled call with ID '%s': %w", id, err)
+	}
+	so, err := checklist.SignOff(store, call, item, by, now)
+	if err != nil {
+		return fmt.Errorf("failed to sign off '%s': %w", item, err)
+	}
+
+	pending, err := checklist.Pending(store, call)
+	if err != nil {
+		return fmt.Errorf("failed to get the checklist of '%s': %w", call.ID, err)
+	}
+	fmt.Fprintf(w, "Signed off '%s' for call '%s' as %s.\n", so.Item, call.ID, so.By)
+	if len(pending) > 0 {
+		fmt.Fprintf(w, "Still pending: %s.\n", strings.Join(pending, ", "))
+	} else {
+		fmt.Fprintln(w, "Every item is signed off; the call will be sent when it is due.")
+	}
+	return nil
+}
+
+func init() {
+	checklistCmd.AddCommand(checklistCheckCmd)
+	checklistCheckCmd.Flags().StringVar(&checklistBy, "by", os.Getenv("USER"), "Who is signing the item off.")
+}
@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/andrewhowdencom/ruf/internal/checklist"
+	"github.com/andrewhowdencom/ruf/internal/datastore"
+	"github.com/andrewhowdencom/ruf/internal/kv"
+	"github.com/andrewhowdencom/ruf/internal/owners"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+// checklistListCmd represents the checklist list command
+var checklistListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the checklists of the scheduled calls.",
+	Long:  `List the scheduled calls of campaigns with a checklist, and who signed off each item.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store, err := datastore.NewStore(true)
+		if err != nil {
+			return fmt.Errorf("failed to create a new datastore: %w", err)
+		}
+		defer store.Close()
+
+		router, err := buildOwners()
+		if err != nil {
+			return err
+		}
+		return doChecklistList(store, router, cmd.OutOrStdout())
+	},
+}
+
+func doChecklistList(store kv.Storer, router *owners.Router, w io.Writer) error {
+	calls, err := store.ListScheduledCalls()
+	if err != nil {
+		return fmt.Errorf("failed to list scheduled calls: %w", err)
+	}
+	sort.Slice(calls, func(i, j int) bool { return calls[i].ScheduledAt.Before(calls[j].ScheduledAt) })
+
+	table := tablewriter.NewWriter(w)
+	table.Header("Short ID", "Call ID", "Scheduled At", "Checklist", "Owners")
+	rows := 0
+	for _, call := range calls {
+		items, err := checklist.Status(store, call)
+		if err != nil {
+			return fmt.Errorf("failed to get the checklist of '%s': %w", call.ID, err)
+		}
+		if len(items) == 0 {
+			continue
+		}
+
+		var lines []string
+		for _, item := range items {
+			if item.Done() {
+				lines = append(lines, fmt.Sprintf("[x] %s (%s, %s)", item.Name, item.Signoff.By, item.Signoff.At.Format(time.RFC3339)))
+			} else {
+				lines = append(lines, "[ ] "+item.Name)
+			}
+		}
+		callOwners, _ := router.Owners(call.Call.Campaign)
+		table.Append([]string{kv.GenerateShortID(call.ID), call.ID, call.ScheduledAt.Format(time.RFC1123), strings.Join(lines, "\n"), strings.Join(callOwners, "\n")})
+		rows++
+	}
+	if rows == 0 {
+		fmt.Fprintln(w, "No scheduled calls have a checklist.")
+		return nil
+	}
+	table.Render()
+	return nil
+}
+
+func init() {
+	checklistCmd.AddCommand(checklistListCmd)
+}
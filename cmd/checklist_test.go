@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/andrewhowdencom/ruf/internal/datastore"
+	"github.com/andrewhowdencom/ruf/internal/kv"
+	"github.com/andrewhowdencom/ruf/internal/model"
+	"github.com/andrewhowdencom/ruf/internal/owners"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChecklist(t *testing.T) {
+	store := datastore.NewMockStore()
+	require.NoError(t, store.AddScheduledCall(&kv.ScheduledCall{
+		Call: model.Call{
+			ID:       "launch",
+			Campaign: model.Campaign{ID: "campaign", Name: "Campaign", Checklist: []string{"content reviewed", "legal"}},
+		},
+		ScheduledAt: time.Date(2025, 6, 2, 9, 0, 0, 0, time.UTC),
+	}))
+	require.NoError(t, store.AddScheduledCall(&kv.ScheduledCall{
+		Call:        model.Call{ID: "unchecked", Campaign: model.Campaign{ID: "other"}},
+		ScheduledAt: time.Date(2025, 6, 2, 9, 0, 0, 0, time.UTC),
+	}))
+	var out bytes.Buffer
+
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	require.NoError(t, doChecklistCheck(store, &out, kv.GenerateShortID("launch"), "legal", "jane", now))
+	assert.Contains(t, out.String(), "Signed off 'legal' for call 'launch' as jane.")
+	assert.Contains(t, out.String(), "Still pending: content reviewed.")
+
+	out.Reset()
+	router, err := owners.New([]owners.Rule{{Match: "camp*", Owners: []string{"launch-team@example.com"}}})
+	require.NoError(t, err)
+	require.NoError(t, doChecklistList(store, router, &out))
+	assert.Contains(t, out.String(), "[x] legal (jane, 2025-06-01T12:00:00Z)")
+	assert.Contains(t, out.String(), "launch-team@example.com")
+	assert.Contains(t, out.String(), "[ ] content reviewed")
+	assert.NotContains(t, out.String(), "unchecked")
+
+	out.Reset()
+	require.NoError(t, doChecklistCheck(store, &out, "launch", "content reviewed", "joe", now))
+	assert.Contains(t, out.String(), "Every item is signed off")
+
+	assert.ErrorContains(t, doChecklistCheck(store, &out, "launch", "translations", "joe", now), "unknown checklist item")
+	assert.ErrorContains(t, doChecklistCheck(store, &out, "missing", "legal", "joe", now), "could not find")
+	assert.ErrorContains(t, doChecklistCheck(store, &out, "launch", "legal", "", now), "--by is required")
+}
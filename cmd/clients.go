@@ -0,0 +1,179 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/andrewhowdencom/ruf/internal/clients/chatwork"
+	"github.com/andrewhowdencom/ruf/internal/clients/homeassistant"
+	"github.com/andrewhowdencom/ruf/internal/clients/line"
+	"github.com/andrewhowdencom/ruf/internal/clients/slackworkflow"
+	"github.com/andrewhowdencom/ruf/internal/model"
+	"github.com/andrewhowdencom/ruf/internal/owners"
+	"github.com/andrewhowdencom/ruf/internal/policy"
+	"github.com/andrewhowdencom/ruf/internal/worker"
+	"github.com/spf13/viper"
+)
+
+// workflowConfig is the configuration of a Slack workflow that calls can start.
+type workflowConfig struct {
+	URL    string            `mapstructure:"url"`
+	Inputs map[string]string `mapstructure:"inputs"`
+}
+
+// workerOptions builds the clients for the optional and webhook destination types that have been
+// configured, and the policies that calls are checked against before they are sent.
+func workerOptions() ([]worker.Option, error) {
+	var opts []worker.Option
+	if token := viper.GetString("chatwork.token"); token != "" {
+		opts = append(opts, worker.WithChatworkClient(chatwork.NewClient(token)))
+	}
+	if token := viper.GetString("line.channel.token"); token != "" {
+		opts = append(opts, worker.WithLineClient(line.NewClient(token)))
+	}
+	if url := viper.GetString("homeassistant.url"); url != "" {
+		var payloads map[string]string
+		if err := viper.UnmarshalKey("homeassistant.payload_templates", &payloads); err != nil {
+			return nil, fmt.Errorf("failed to parse homeassistant.payload_templates: %w", err)
+		}
+		var haOpts []homeassistant.Option
+		for target, tmpl := range payloads {
+			haOpts = append(haOpts, homeassistant.WithPayloadTemplate(target, tmpl))
+		}
+		client, err := homeassistant.NewClient(url, viper.GetString("homeassistant.token"), haOpts...)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, worker.WithHomeAssistantClient(client))
+	}
+	if viper.IsSet("slack.workflows") {
+		var configs map[string]workflowConfig
+		if err := viper.UnmarshalKey("slack.workflows", &configs); err != nil {
+			return nil, fmt.Errorf("failed to parse slack.workflows: %w", err)
+		}
+		workflows := make(map[string]slackworkflow.Workflow, len(configs))
+		for name, c := range configs {
+			workflows[name] = slackworkflow.Workflow{URL: c.URL, Inputs: c.Inputs}
+		}
+		client, err := slackworkflow.NewClient(workflows)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, worker.WithSlackWorkflowClient(client))
+	}
+	hooks, err := buildWebhooks()
+	if err != nil {
+		return nil, err
+	}
+	for name, client := range hooks {
+		opts = append(opts, worker.WithWebhook(name, client))
+	}
+	if viper.GetBool("worker.notify_failures") {
+		opts = append(opts, worker.WithFailureNotifications())
+	}
+	if viper.IsSet("notifications.on_failure") {
+		var dest model.Destination
+		if err := viper.UnmarshalKey("notifications.on_failure", &dest); err != nil {
+			return nil, fmt.Errorf("failed to parse notifications.on_failure: %w", err)
+		}
+		if dest.Type == "" || len(dest.To) == 0 {
+			return nil, fmt.Errorf("invalid notifications.on_failure: must have a type and at least one recipient")
+		}
+		opts = append(opts, worker.WithFailureChannel(dest))
+	}
+	cache, err := buildAssets()
+	if err != nil {
+		return nil, err
+	}
+	opts = append(opts, worker.WithAssets(cache))
+	if viper.GetBool("worker.record_content") {
+		opts = append(opts, worker.WithContentSnapshots())
+	}
+	if viper.GetBool("worker.strict_templates") {
+		opts = append(opts, worker.WithStrictTemplates())
+	}
+	if interval := viper.GetDuration("worker.tick.interval"); interval > 0 {
+		opts = append(opts, worker.WithTickInterval(interval))
+	}
+	if maxCalls, maxDuration := viper.GetInt("worker.tick.max_calls"), viper.GetDuration("worker.tick.max_duration"); maxCalls > 0 || maxDuration > 0 {
+		opts = append(opts, worker.WithTickBudget(maxCalls, maxDuration))
+	}
+	if timeout := viper.GetDuration("worker.send_timeout"); timeout > 0 {
+		opts = append(opts, worker.WithSendTimeout(timeout))
+	}
+	if timeout := viper.GetDuration("worker.shutdown_timeout"); timeout > 0 {
+		opts = append(opts, worker.WithShutdownTimeout(timeout))
+	}
+	if window := viper.GetDuration("worker.undo_window"); window > 0 {
+		opts = append(opts, worker.WithUndoWindow(window))
+	}
+	if approvers := viper.GetStringSlice("approvals.approvers"); len(approvers) > 0 {
+		opts = append(opts, worker.WithApprovers(approvers...))
+	}
+	switch fallback := viper.GetString("confirmation.default"); fallback {
+	case "", model.ConfirmSend, model.ConfirmCancel:
+		opts = append(opts, worker.WithConfirmation(viper.GetDuration("confirmation.before"), fallback))
+	default:
+		return nil, fmt.Errorf("invalid confirmation.default '%s': must be %s or %s", fallback, model.ConfirmSend, model.ConfirmCancel)
+	}
+
+	engine, err := buildPolicy()
+	if err != nil {
+		return nil, err
+	}
+	if engine != nil {
+		opts = append(opts, worker.WithPolicy(engine))
+	}
+
+	l, err := buildLimits()
+	if err != nil {
+		return nil, err
+	}
+	if l != nil {
+		opts = append(opts, worker.WithLimits(l))
+	}
+	rates, err := buildRateLimits()
+	if err != nil {
+		return nil, err
+	}
+	opts = append(opts, worker.WithRateLimits(rates))
+
+	middleware, err := buildMiddleware()
+	if err != nil {
+		return nil, err
+	}
+	opts = append(opts, middleware...)
+	processors, err := buildProcessors()
+	if err != nil {
+		return nil, err
+	}
+	opts = append(opts, processors...)
+
+	router, err := buildOwners()
+	if err != nil {
+		return nil, err
+	}
+	opts = append(opts, worker.WithOwners(router))
+	return opts, nil
+}
+
+// buildOwners creates the router of the owners rules under owners.
+func buildOwners() (*owners.Router, error) {
+	var rules []owners.Rule
+	if err := viper.UnmarshalKey("owners", &rules); err != nil {
+		return nil, fmt.Errorf("failed to parse owners: %w", err)
+	}
+	return owners.New(rules)
+}
+
+// buildPolicy loads the policy scripts listed under policy.files. It returns nil if there are none.
+func buildPolicy() (*policy.Engine, error) {
+	files := viper.GetStringSlice("policy.files")
+	if len(files) == 0 {
+		return nil, nil
+	}
+	engine, err := policy.Load(files...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load policies: %w", err)
+	}
+	return engine, nil
+}
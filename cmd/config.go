@@ -0,0 +1,16 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// configCmd represents the config command
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Show and validate the configuration.",
+	Long:  `Show and validate the configuration, as it is merged from the config file, profile, environment and flags.`,
+}
+
+func init() {
+	rootCmd.AddCommand(configCmd)
+}
@@ -0,0 +1,172 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// maskedValue replaces the value of a secret setting when the configuration is shown.
+const maskedValue = "********"
+
+// configShowCmd represents the config show command
+var configShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Show the effective configuration.",
+	Long: `Show the effective configuration: the defaults, merged with the config file, the profile, the
+environment and --set, exactly as the other commands read it.
+
+Secrets, such as tokens, passwords and the headers sent to OpenTelemetry exporters, are masked. A
+secret that is not set is shown empty, so that it can be told apart from one that is.
+
+Example:
+  ruf --profile production config show -o json`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		output, _ := cmd.Flags().GetString("output")
+		return doConfigShow(cmd.OutOrStdout(), output)
+	},
+}
+
+func doConfigShow(w io.Writer, output string) error {
+	// The map settings are copied before viper.AllSettings runs, as it writes the nesting it makes up
+	// for the dots in their keys into the maps it shares with viper.
+	whole := mapSettings("")
+	for name := range viper.GetStringMap("profiles") {
+		for key, value := range mapSettings("profiles." + name + ".") {
+			whole[key] = value
+		}
+	}
+	all := viper.AllSettings()
+	for key, value := range whole {
+		setSetting(all, key, value)
+	}
+	settings := maskSettings("", all, false).(map[string]interface{})
+	switch output {
+	case "", outputYAML:
+		b, err := yaml.Marshal(settings)
+		if err != nil {
+			return fmt.Errorf("failed to encode the configuration: %w", err)
+		}
+		_, err = w.Write(b)
+		return err
+	case outputJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(settings)
+	default:
+		return fmt.Errorf("invalid output '%s': must be %s or %s", output, outputYAML, outputJSON)
+	}
+}
+
+// mapSettings returns copies of the known map settings under prefix that are set, by their key.
+// viper.AllSettings reads the dots in the keys of a map as nesting, so that git.tokens.github.com would
+// be shown as the token of the host "com" under "github", while viper.Get keeps the keys whole.
+func mapSettings(prefix string) map[string]interface{} {
+	values := make(map[string]interface{})
+	for key, s := range knownSettings {
+		if s.kind != kindMap || key == "profiles" || !viper.IsSet(prefix+key) {
+			continue
+		}
+		values[prefix+key] = copySetting(viper.Get(prefix + key))
+	}
+	return values
+}
+
+// setSetting sets the setting at key in settings, nested by the parts of the key.
+func setSetting(settings map[string]interface{}, key string, value interface{}) {
+	parts := strings.Split(key, ".")
+	for _, part := range parts[:len(parts)-1] {
+		child, ok := settings[part].(map[string]interface{})
+		if !ok {
+			child = make(map[string]interface{})
+			settings[part] = child
+		}
+		settings = child
+	}
+	settings[parts[len(parts)-1]] = value
+}
+
+// copySetting returns a deep copy of the value of a setting, with its maps as map[string]interface{}
+// and its lists as []interface{}, whatever type viper holds them as.
+func copySetting(value interface{}) interface{} {
+	rv := reflect.ValueOf(value)
+	switch {
+	case rv.Kind() == reflect.Map && rv.Type().Key().Kind() == reflect.String:
+		m := make(map[string]interface{}, rv.Len())
+		for iter := rv.MapRange(); iter.Next(); {
+			m[iter.Key().String()] = copySetting(iter.Value().Interface())
+		}
+		return m
+	case rv.Kind() == reflect.Slice:
+		l := make([]interface{}, rv.Len())
+		for i := range l {
+			l[i] = copySetting(rv.Index(i).Interface())
+		}
+		return l
+	}
+	return value
+}
+
+// maskSettings returns a copy of the value of the setting at key with its secrets masked. Every value
+// under a secret setting is masked, such as each of git.tokens.
+func maskSettings(key string, value interface{}, secret bool) interface{} {
+	if key != "" {
+		secret = secret || isSecretSetting(key)
+	}
+	switch v := value.(type) {
+	case map[string]interface{}:
+		masked := make(map[string]interface{}, len(v))
+		for name, child := range v {
+			masked[name] = maskSettings(joinKey(key, name), child, secret)
+		}
+		return masked
+	case []interface{}:
+		masked := make([]interface{}, len(v))
+		for i, child := range v {
+			// The fields of the items of a list are read as settings under the list.
+			masked[i] = maskSettings(key, child, secret)
+		}
+		return masked
+	}
+	if rv := reflect.ValueOf(value); (rv.Kind() == reflect.Map || rv.Kind() == reflect.Slice) && rv.Len() == 0 {
+		return value
+	}
+	if secret && !isEmptySetting(value) {
+		return maskedValue
+	}
+	return value
+}
+
+// isSecretSetting returns whether the setting at key is a secret: a known setting that is one, or any
+// setting whose name says it is, such as git.auth.<host>.token.
+func isSecretSetting(key string) bool {
+	if s, _, ok := lookupSetting(key); ok && s.secret {
+		return true
+	}
+	name := strings.ToLower(key[strings.LastIndex(key, ".")+1:])
+	for _, word := range []string{"token", "password", "secret"} {
+		if strings.Contains(name, word) {
+			return true
+		}
+	}
+	return false
+}
+
+// joinKey returns the key of the setting name under parent.
+func joinKey(parent, name string) string {
+	if parent == "" {
+		return name
+	}
+	return parent + "." + name
+}
+
+func init() {
+	configCmd.AddCommand(configShowCmd)
+	configShowCmd.Flags().StringP("output", "o", outputYAML, "The format to show in: yaml or json.")
+}
@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigShow(t *testing.T) {
+	// The defaults set as the commands are built are left out, so that only what is seeded is shown.
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+	// As it is by default, which makes viper.AllSettings read git.tokens as a setting of its own.
+	viper.SetDefault("git.tokens", map[string]string{})
+	require.NoError(t, viper.MergeConfigMap(map[string]interface{}{
+		"email": map[string]interface{}{"host": "smtp.example.com", "password": "hunter2"},
+		"slack": map[string]interface{}{"app": map[string]interface{}{"token": "xoxb-1", "signing_secret": ""}},
+		"git": map[string]interface{}{
+			"tokens": map[string]interface{}{"github.com": "ghp-1"},
+			"auth":   map[string]interface{}{"gitlab": map[string]interface{}{"username": "ruf", "token": "glpat-1"}},
+		},
+		"profiles": map[string]interface{}{
+			"production": map[string]interface{}{
+				"email": map[string]interface{}{"password": "hunter3"},
+				"git":   map[string]interface{}{"tokens": map[string]interface{}{"gitlab.example.com": "glpat-2"}},
+			},
+		},
+	}))
+
+	var buf bytes.Buffer
+	require.NoError(t, doConfigShow(&buf, outputJSON))
+	var got map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &got))
+	email := got["email"].(map[string]interface{})
+	assert.Equal(t, "smtp.example.com", email["host"])
+	assert.Equal(t, maskedValue, email["password"])
+	// A secret that is not set is shown empty.
+	assert.Equal(t, map[string]interface{}{"app": map[string]interface{}{"token": maskedValue, "signing_secret": ""}}, got["slack"])
+	assert.Equal(t, map[string]interface{}{
+		"tokens": map[string]interface{}{"github.com": maskedValue},
+		"auth":   map[string]interface{}{"gitlab": map[string]interface{}{"username": "ruf", "token": maskedValue}},
+	}, got["git"])
+	production := got["profiles"].(map[string]interface{})["production"].(map[string]interface{})
+	assert.Equal(t, maskedValue, production["email"].(map[string]interface{})["password"])
+	assert.Equal(t, map[string]interface{}{"tokens": map[string]interface{}{"gitlab.example.com": maskedValue}}, production["git"], "the dots in the keys of a map are not nesting")
+
+	buf.Reset()
+	require.NoError(t, doConfigShow(&buf, outputYAML))
+	assert.Contains(t, buf.String(), "host: smtp.example.com")
+	assert.NotContains(t, buf.String(), "hunter2")
+	assert.NotContains(t, buf.String(), "glpat-1")
+
+	assert.EqualError(t, doConfigShow(&buf, outputCSV), "invalid output 'csv': must be yaml or json")
+}
+
+func TestConfigValidate(t *testing.T) {
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+	viper.SetDefault("worker.calculation.after", "168h")
+	viper.SetDefault("policy.files", []string{})
+	require.NoError(t, viper.MergeConfigMap(map[string]interface{}{
+		"email":  map[string]interface{}{"port": 587, "password": "hunter2"},
+		"limits": map[string]interface{}{"slack": map[string]interface{}{"#general": map[string]interface{}{"max_per_day": 3}}},
+		"owners": []interface{}{map[string]interface{}{"match": "*", "owners": []interface{}{"comms@example.com"}}},
+		"chaos":  map[string]interface{}{"slack": map[string]interface{}{"failure_rate": 0.1, "latency": "500ms"}},
+		"profiles": map[string]interface{}{
+			"production": map[string]interface{}{"datastore": map[string]interface{}{"type": "firestore"}},
+		},
+	}))
+
+	var buf bytes.Buffer
+	require.NoError(t, doConfigValidate(&buf))
+	assert.Equal(t, "The configuration is valid.\n", buf.String())
+
+	require.NoError(t, viper.MergeConfigMap(map[string]interface{}{
+		"email":     map[string]interface{}{"port": "smtp", "password": []interface{}{"hunter2"}},
+		"worker":    map[string]interface{}{"calcualtion": map[string]interface{}{"after": "72h"}},
+		"datastore": map[string]interface{}{"cache": map[string]interface{}{"ttl": 60}},
+		"profiles": map[string]interface{}{
+			"production": map[string]interface{}{"datastore": map[string]interface{}{"typ": "firestore"}},
+		},
+		"frobnicate": true,
+	}))
+	viper.Set("worker.tick.max_calls", "ten")
+
+	buf.Reset()
+	assert.EqualError(t, doConfigValidate(&buf), "the configuration is invalid: problems: 7")
+	assert.Equal(t, `datastore.cache.ttl: must be a duration, such as 90s or 1h30m, not "60"
+email.password: must be a string, not a list
+email.port: must be an integer, not "smtp"
+frobnicate: is not a known setting
+profiles.production.datastore.typ: is not a known setting; did you mean profiles.production.datastore.type?
+worker.calcualtion.after: is not a known setting; did you mean worker.calculation.after?
+worker.tick.max_calls: must be an integer, not "ten"
+`, buf.String())
+}
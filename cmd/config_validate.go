@@ -0,0 +1,379 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ghodss/yaml"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// settingKind is the type of the value of a setting.
+type settingKind string
+
+// The types of the values of settings.
+const (
+	kindString   settingKind = "a string"
+	kindInt      settingKind = "an integer"
+	kindFloat    settingKind = "a number"
+	kindBool     settingKind = "a boolean"
+	kindDuration settingKind = "a duration, such as 90s or 1h30m"
+	kindStrings  settingKind = "a list of strings"
+	kindList     settingKind = "a list"
+	// kindMap is a map whose keys are chosen by the config, such as the names of destinations, so that
+	// the settings under it are not checked.
+	kindMap settingKind = "a map"
+)
+
+// knownSetting is a setting that ruf reads.
+type knownSetting struct {
+	kind   settingKind
+	secret bool
+}
+
+// knownSettings are the settings that ruf reads, by their key. Settings under a profile, as
+// profiles.<name>.<key>, are looked up by their key.
+var knownSettings = map[string]knownSetting{
+	"profile":                     {kind: kindString},
+	"profiles":                    {kind: kindMap},
+	"safety.require_confirmation": {kind: kindBool},
+	"safety.dry_run_send":         {kind: kindBool},
+	"log.level":                   {kind: kindString},
+
+	"email.host":                      {kind: kindString},
+	"email.port":                      {kind: kindInt},
+	"email.username":                  {kind: kindString},
+	"email.password":                  {kind: kindString, secret: true},
+	"email.from":                      {kind: kindString},
+	"chatwork.token":                  {kind: kindString, secret: true},
+	"line.channel.token":              {kind: kindString, secret: true},
+	"homeassistant.url":               {kind: kindString},
+	"homeassistant.token":             {kind: kindString, secret: true},
+	"homeassistant.payload_templates": {kind: kindMap},
+	"types":                           {kind: kindMap},
+	"slack.app.token":                 {kind: kindString, secret: true},
+	"slack.app.signing_secret":        {kind: kindString, secret: true},
+	"slack.workflows":                 {kind: kindMap},
+	"checklist.api.token":             {kind: kindString, secret: true},
+	"approvals.approvers":             {kind: kindStrings},
+	"confirmation.before":             {kind: kindDuration},
+	"confirmation.default":            {kind: kindString},
+	"owners":                          {kind: kindList},
+	"notifications.on_failure":        {kind: kindMap},
+	"dispatcher.dry_run":              {kind: kindBool},
+	"policy.files":                    {kind: kindStrings},
+
+	"git.tokens": {kind: kindMap, secret: true},
+	"git.auth":   {kind: kindMap},
+
+	"datastore.type":                            {kind: kindString},
+	"datastore.path":                            {kind: kindString},
+	"datastore.project_id":                      {kind: kindString},
+	"datastore.cache.ttl":                       {kind: kindDuration},
+	"datastore.firestore.timeout":               {kind: kindDuration},
+	"datastore.firestore.retry.attempts":        {kind: kindInt},
+	"datastore.firestore.retry.initial_backoff": {kind: kindDuration},
+	"datastore.firestore.retry.max_backoff":     {kind: kindDuration},
+	"datastore.firestore.connections":           {kind: kindInt},
+
+	"source.urls":                 {kind: kindStrings},
+	"source.git.cache_dir":        {kind: kindString},
+	"source.assets.cache_dir":     {kind: kindString},
+	"source.cache.enabled":        {kind: kindBool},
+	"source.push.dir":             {kind: kindString},
+	"source.s3.region":            {kind: kindString},
+	"source.s3.access_key_id":     {kind: kindString},
+	"source.s3.secret_access_key": {kind: kindString, secret: true},
+	"source.s3.session_token":     {kind: kindString, secret: true},
+	"source.s3.endpoint":          {kind: kindString},
+	"source.gcs.endpoint":         {kind: kindString},
+	"source.azblob.account":       {kind: kindString},
+	"source.azblob.sas_token":     {kind: kindString, secret: true},
+	"source.azblob.endpoint":      {kind: kindString},
+	"source.events.lookback":      {kind: kindDuration},
+	"source.events.lookahead":     {kind: kindDuration},
+
+	"scheduling.horizon.before":      {kind: kindDuration},
+	"scheduling.horizon.after":       {kind: kindDuration},
+	"worker.missed_lookback":         {kind: kindDuration},
+	"worker.notify_failures":         {kind: kindBool},
+	"worker.strict_templates":        {kind: kindBool},
+	"worker.record_content":          {kind: kindBool},
+	"worker.calculation.before":      {kind: kindDuration},
+	"worker.calculation.after":       {kind: kindDuration},
+	"worker.calculation.concurrency": {kind: kindInt},
+	"worker.tick.interval":           {kind: kindDuration},
+	"worker.tick.max_calls":          {kind: kindInt},
+	"worker.tick.max_duration":       {kind: kindDuration},
+	"worker.send_timeout":            {kind: kindDuration},
+	"worker.shutdown_timeout":        {kind: kindDuration},
+	"worker.undo_window":             {kind: kindDuration},
+	"worker.middleware":              {kind: kindMap},
+	"worker.processors":              {kind: kindMap},
+	"worker.lease.enabled":           {kind: kindBool},
+	"worker.lease.ttl":               {kind: kindDuration},
+	"worker.lease.holder":            {kind: kindString},
+
+	"watch.port":             {kind: kindInt},
+	"watch.refresh_interval": {kind: kindDuration},
+	"watch.push.token":       {kind: kindString, secret: true},
+	"feeds.enabled":          {kind: kindBool},
+	"feeds.base_url":         {kind: kindString},
+	"feeds.limit":            {kind: kindInt},
+	"feeds.campaigns":        {kind: kindStrings},
+	"update.endpoint":        {kind: kindString},
+	"update.public_key":      {kind: kindString},
+
+	"health.thresholds.min_scheduled_calls": {kind: kindInt},
+	"health.thresholds.max_oldest_overdue":  {kind: kindDuration},
+	"health.thresholds.min_slots_remaining": {kind: kindInt},
+	"health.thresholds.max_refresh_age":     {kind: kindDuration},
+	"health.thresholds.max_tick_age":        {kind: kindDuration},
+
+	"chaos.enabled":                {kind: kindBool},
+	"chaos.slack.failure_rate":     {kind: kindFloat},
+	"chaos.slack.latency":          {kind: kindDuration},
+	"chaos.email.failure_rate":     {kind: kindFloat},
+	"chaos.email.latency":          {kind: kindDuration},
+	"chaos.datastore.failure_rate": {kind: kindFloat},
+	"chaos.datastore.latency":      {kind: kindDuration},
+
+	"otel.exporter.traces.endpoint":  {kind: kindString},
+	"otel.exporter.traces.headers":   {kind: kindMap, secret: true},
+	"otel.exporter.metrics.endpoint": {kind: kindString},
+	"otel.exporter.metrics.headers":  {kind: kindMap, secret: true},
+
+	"slots.timezone":       {kind: kindString},
+	"slots":                {kind: kindMap},
+	"limits":               {kind: kindMap},
+	"rate_limits":          {kind: kindMap},
+	"timezones.recipients": {kind: kindMap},
+	"timezones.slack":      {kind: kindBool},
+	"timezones.cache_ttl":  {kind: kindDuration},
+	"calendar.holidays":    {kind: kindList},
+}
+
+// configValidateCmd represents the config validate command
+var configValidateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Validate the configuration.",
+	Long: `Validate the configuration: every setting, from the config file, the profile, the environment and
+--set, must be one that ruf reads, and must have a value of its type. A setting that ruf does not
+read is reported with the known setting it is most likely a misspelling of.
+
+The settings under profiles.<name> are validated as the settings they override. The command fails if
+any problem is found.
+
+Example:
+  ruf config validate --config ./config.yaml`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return doConfigValidate(cmd.OutOrStdout())
+	},
+}
+
+func doConfigValidate(w io.Writer) error {
+	// A config file that does not parse is only warned about as it is read, so it is checked here.
+	if path := viper.ConfigFileUsed(); path != "" {
+		if b, err := os.ReadFile(path); err == nil {
+			var config map[string]interface{}
+			if err := yaml.Unmarshal(b, &config); err != nil {
+				return fmt.Errorf("failed to parse %s: %w", path, err)
+			}
+		}
+	}
+
+	problems := make(map[string]string)
+	for _, key := range viper.AllKeys() {
+		setting, settingKey, ok := lookupSetting(key)
+		if !ok {
+			problems[key] = "is not a known setting"
+			if suggestion := suggestSetting(key); suggestion != "" {
+				problems[key] += fmt.Sprintf("; did you mean %s?", suggestion)
+			}
+			continue
+		}
+		if _, checked := problems[settingKey]; checked {
+			continue
+		}
+		if !settingHasKind(viper.Get(settingKey), setting.kind) {
+			problems[settingKey] = fmt.Sprintf("must be %s, not %v", setting.kind, describeSetting(viper.Get(settingKey), setting.secret))
+		}
+	}
+
+	if len(problems) == 0 {
+		fmt.Fprintln(w, "The configuration is valid.")
+		return nil
+	}
+	keys := make([]string, 0, len(problems))
+	for key := range problems {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		fmt.Fprintf(w, "%s: %s\n", key, problems[key])
+	}
+	return fmt.Errorf("the configuration is invalid: problems: %d", len(problems))
+}
+
+// lookupSetting finds the known setting that key is, or is under if the setting is a map or a list, and
+// returns it with its key.
+func lookupSetting(key string) (knownSetting, string, bool) {
+	key = strings.ToLower(key)
+	prefix := ""
+	if rest, ok := strings.CutPrefix(key, "profiles."); ok {
+		// A profile overrides the settings under its name.
+		if name, setting, ok := strings.Cut(rest, "."); ok {
+			prefix, key = "profiles."+name+".", setting
+		}
+	}
+	if s, ok := knownSettings[key]; ok {
+		return s, prefix + key, true
+	}
+	for parent := key; strings.Contains(parent, "."); {
+		parent = parent[:strings.LastIndex(parent, ".")]
+		if s, ok := knownSettings[parent]; ok && (s.kind == kindMap || s.kind == kindList) {
+			return s, prefix + parent, true
+		}
+	}
+	return knownSetting{}, "", false
+}
+
+// suggestSetting returns the known setting closest to key, if it is close enough to be a misspelling
+// of it.
+func suggestSetting(key string) string {
+	if rest, ok := strings.CutPrefix(key, "profiles."); ok {
+		if name, setting, ok := strings.Cut(rest, "."); ok {
+			if suggestion := suggestSetting(setting); suggestion != "" {
+				return "profiles." + name + "." + suggestion
+			}
+			return ""
+		}
+	}
+	best, bestDistance := "", len(key)/4+2
+	for known := range knownSettings {
+		if d := editDistance(key, known); d < bestDistance || (d == bestDistance && best != "" && known < best) {
+			best, bestDistance = known, d
+		}
+	}
+	return best
+}
+
+// editDistance returns the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur := make([]int, len(b)+1)
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
+
+// settingHasKind returns whether value is of the kind, or is a string that parses as it, as the values
+// of environment variables and flags are.
+func settingHasKind(value interface{}, kind settingKind) bool {
+	switch kind {
+	case kindMap:
+		return reflect.ValueOf(value).Kind() == reflect.Map || isEmptySetting(value)
+	case kindList:
+		return reflect.ValueOf(value).Kind() == reflect.Slice || isEmptySetting(value)
+	case kindStrings:
+		if v, ok := value.([]interface{}); ok {
+			for _, item := range v {
+				if !isScalar(item) {
+					return false
+				}
+			}
+			return true
+		}
+		_, ok := value.([]string)
+		return ok || isScalar(value)
+	}
+
+	if !isScalar(value) {
+		return false
+	}
+	s, isString := value.(string)
+	switch kind {
+	case kindInt:
+		switch value.(type) {
+		case int, int64, uint64:
+			return true
+		}
+		_, err := strconv.Atoi(s)
+		return isString && err == nil
+	case kindFloat:
+		switch value.(type) {
+		case int, int64, uint64, float64:
+			return true
+		}
+		_, err := strconv.ParseFloat(s, 64)
+		return isString && err == nil
+	case kindBool:
+		if _, ok := value.(bool); ok {
+			return true
+		}
+		_, err := strconv.ParseBool(s)
+		return isString && err == nil
+	case kindDuration:
+		switch v := value.(type) {
+		case time.Duration:
+			return true
+		case int:
+			// A number is read as nanoseconds, which is only meant when it is zero.
+			return v == 0
+		}
+		_, err := time.ParseDuration(s)
+		return isString && (s == "" || err == nil)
+	}
+	return true
+}
+
+// isScalar returns whether value is a single value, rather than a map or a list.
+func isScalar(value interface{}) bool {
+	switch reflect.ValueOf(value).Kind() {
+	case reflect.Map, reflect.Slice:
+		return false
+	}
+	return true
+}
+
+// isEmptySetting returns whether value is a setting that is given, but empty.
+func isEmptySetting(value interface{}) bool {
+	return value == nil || value == ""
+}
+
+// describeSetting describes the value of a setting that is not of its type, without showing secrets.
+func describeSetting(value interface{}, secret bool) string {
+	switch reflect.ValueOf(value).Kind() {
+	case reflect.Map:
+		return "a map"
+	case reflect.Slice:
+		return "a list"
+	}
+	if secret {
+		return "a secret"
+	}
+	return strconv.Quote(fmt.Sprint(value))
+}
+
+func init() {
+	configCmd.AddCommand(configValidateCmd)
+}
@@ -1,26 +1,17 @@
 package cmd
 
 import (
-	"encoding/json"
-	"fmt"
-
 	"github.com/spf13/cobra"
-	"github.com/spf13/viper"
 )
 
 // debugConfigCmd represents the debug config command
 var debugConfigCmd = &cobra.Command{
-	Use:   "config",
-	Short: "Show the configuration.",
-	Long:  `Show the configuration.`,
+	Use:        "config",
+	Short:      "Show the configuration.",
+	Long:       `Show the configuration as JSON, with its secrets masked. It is kept for scripts that use it; use 'ruf config show' instead.`,
+	Deprecated: "use 'ruf config show -o json' instead",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		allSettings := viper.AllSettings()
-		b, err := json.MarshalIndent(allSettings, "", "  ")
-		if err != nil {
-			return err
-		}
-		fmt.Println(string(b))
-		return nil
+		return doConfigShow(cmd.OutOrStdout(), outputJSON)
 	},
 }
 
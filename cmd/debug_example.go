@@ -0,0 +1,213 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/andrewhowdencom/ruf/internal/model"
+	"github.com/andrewhowdencom/ruf/internal/sourcer"
+	"github.com/andrewhowdencom/ruf/internal/validator"
+	rufschema "github.com/andrewhowdencom/ruf/schema"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	exampleTrigger     string
+	exampleDestination string
+)
+
+// debugExampleCmd represents the debug example command
+var debugExampleCmd = &cobra.Command{
+	Use:   "example",
+	Short: "Print an example source file.",
+	Long: `Print a complete, commented example source file.
+
+The example is built from the same types the parser reads source files into,
+annotated with the descriptions in the schema, and validated before it is
+printed, so it always reflects what the parser accepts.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return doDebugExample(cmd.OutOrStdout(), exampleTrigger, exampleDestination)
+	},
+}
+
+func doDebugExample(w io.Writer, trigger, destination string) error {
+	source, err := exampleSource(trigger, destination)
+	if err != nil {
+		return err
+	}
+
+	schema, err := loadSchema()
+	if err != nil {
+		return err
+	}
+
+	var doc yaml.Node
+	if err := doc.Encode(source); err != nil {
+		return fmt.Errorf("failed to encode example: %w", err)
+	}
+	pruneEmpty(&doc)
+	annotate(&doc, schema, schema)
+	doc.HeadComment = fmt.Sprintf("An example source file with a %s trigger, sending to %s.\nGenerated by `ruf debug example`.", trigger, destination)
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return fmt.Errorf("failed to encode example: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return fmt.Errorf("failed to encode example: %w", err)
+	}
+
+	// Check the example the same way a source file would be checked.
+	parser, err := sourcer.NewYAMLParser()
+	if err != nil {
+		return fmt.Errorf("failed to create parser: %w", err)
+	}
+	parsed, err := parser.Parse("file:///example.yaml", buf.Bytes())
+	if err != nil || parsed == nil {
+		return fmt.Errorf("generated example does not match the schema: %v", err)
+	}
+	calls := make([]*model.Call, len(parsed.Calls))
+	for i := range parsed.Calls {
+		calls[i] = &parsed.Calls[i]
+	}
+	if errs := validator.Validate(calls); len(errs) > 0 {
+		return fmt.Errorf("generated example is not valid: %v", errs)
+	}
+
+	_, err = w.Write(buf.Bytes())
+	return err
+}
+
+// exampleSource builds a source with a single call using the given trigger and destination types.
+func exampleSource(trigger, destination string) (*sourcer.Source, error) {
+	call := model.Call{
+		ID:      "weekly-update",
+		Author:  "Platform Team",
+		Subject: "Weekly update",
+		Content: "Hello {{ .audience }}, the weekly update is ready.",
+		Data:    map[string]interface{}{"audience": "everyone"},
+	}
+	source := &sourcer.Source{
+		APIVersion: rufschema.Latest,
+		Campaign:   model.Campaign{ID: "platform-updates", Name: "Platform Updates"},
+	}
+
+	switch destination {
+	case "slack":
+		call.Destinations = []model.Destination{{Type: "slack", To: []string{"#general"}}}
+	case "email":
+		call.Destinations = []model.Destination{{Type: "email", To: []string{"team@example.com"}}}
+	default:
+		return nil, fmt.Errorf("unknown destination type '%s': must be slack or email", destination)
+	}
+
+	switch trigger {
+	case "cron":
+		call.Triggers = []model.Trigger{{Cron: "0 9 * * 1", Timezone: "Europe/Berlin"}}
+	case "rrule":
+		call.Triggers = []model.Trigger{{RRule: "FREQ=WEEKLY;BYDAY=MO", DStart: "TZID=Europe/Berlin:20250106T090000"}}
+	case "sequence":
+		call.ID = "onboarding-day-2"
+		call.Subject = "Settling in"
+		call.Content = "Hello {{ .audience }}, how was your first day?"
+		call.Data = map[string]interface{}{"audience": "new starters"}
+		call.Triggers = []model.Trigger{{Sequence: "onboarding", Delta: "24h"}}
+		source.Events = []model.Event{{
+			Sequence:  "onboarding",
+			StartTime: time.Date(2025, 1, 6, 9, 0, 0, 0, time.UTC),
+		}}
+	case "hijri":
+		call.ID = "ramadan-greeting"
+		call.Subject = "Ramadan Mubarak"
+		call.Content = "Ramadan Mubarak to {{ .audience }}!"
+		call.Triggers = []model.Trigger{{Hijri: "1 ramadan", Time: "09:00", Timezone: "Asia/Jakarta"}}
+	default:
+		return nil, fmt.Errorf("unknown trigger type '%s': must be cron, rrule, sequence or hijri", trigger)
+	}
+
+	source.Calls = []model.Call{call}
+	return source, nil
+}
+
+// loadSchema reads the JSON schema of the latest version of the format of source files.
+func loadSchema() (map[string]interface{}, error) {
+	var schema map[string]interface{}
+	if err := json.Unmarshal(rufschema.Calls, &schema); err != nil {
+		return nil, fmt.Errorf("failed to parse schema: %w", err)
+	}
+	return schema, nil
+}
+
+// pruneEmpty removes keys with empty collections or null values, which the model does not omit.
+func pruneEmpty(node *yaml.Node) {
+	switch node.Kind {
+	case yaml.DocumentNode, yaml.SequenceNode:
+		for _, child := range node.Content {
+			pruneEmpty(child)
+		}
+	case yaml.MappingNode:
+		content := node.Content[:0]
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			if (value.Kind == yaml.SequenceNode || value.Kind == yaml.MappingNode) && len(value.Content) == 0 || value.Tag == "!!null" {
+				continue
+			}
+			pruneEmpty(value)
+			content = append(content, key, value)
+		}
+		node.Content = content
+	}
+}
+
+// annotate attaches the description of each property in the schema to its key as a comment.
+func annotate(node *yaml.Node, schema, root map[string]interface{}) {
+	schema = resolveRef(schema, root)
+
+	switch node.Kind {
+	case yaml.DocumentNode:
+		for _, child := range node.Content {
+			annotate(child, schema, root)
+		}
+	case yaml.SequenceNode:
+		items, _ := schema["items"].(map[string]interface{})
+		for _, child := range node.Content {
+			annotate(child, items, root)
+		}
+	case yaml.MappingNode:
+		properties, _ := schema["properties"].(map[string]interface{})
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			property, _ := properties[key.Value].(map[string]interface{})
+			if description, ok := property["description"].(string); ok {
+				key.HeadComment = description
+			}
+			annotate(value, property, root)
+		}
+	}
+}
+
+// resolveRef follows a local "$ref" to the definition it points to.
+func resolveRef(schema, root map[string]interface{}) map[string]interface{} {
+	ref, ok := schema["$ref"].(string)
+	if !ok {
+		return schema
+	}
+	resolved := root
+	for _, part := range strings.Split(strings.TrimPrefix(ref, "#/"), "/") {
+		resolved, _ = resolved[part].(map[string]interface{})
+	}
+	return resolved
+}
+
+func init() {
+	debugCmd.AddCommand(debugExampleCmd)
+	debugExampleCmd.Flags().StringVar(&exampleTrigger, "trigger", "cron", "The type of trigger to show: cron, rrule, sequence or hijri.")
+	debugExampleCmd.Flags().StringVar(&exampleDestination, "destination", "slack", "The type of destination to show: slack or email.")
+}
@@ -0,0 +1,27 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDebugExample(t *testing.T) {
+	for _, trigger := range []string{"cron", "rrule", "sequence", "hijri"} {
+		for _, destination := range []string{"slack", "email"} {
+			t.Run(trigger+"/"+destination, func(t *testing.T) {
+				var out bytes.Buffer
+				assert.NoError(t, doDebugExample(&out, trigger, destination))
+				assert.Contains(t, out.String(), trigger+":")
+				assert.Contains(t, out.String(), "type: "+destination)
+				assert.Contains(t, out.String(), "# When the message is sent.")
+			})
+		}
+	}
+
+	var out bytes.Buffer
+	assert.ErrorContains(t, doDebugExample(&out, "weekly", "slack"), "unknown trigger type")
+	assert.ErrorContains(t, doDebugExample(&out, "cron", "pigeon"), "unknown destination type")
+	assert.Empty(t, out.String())
+}
@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/andrewhowdencom/ruf/internal/datastore"
+	"github.com/andrewhowdencom/ruf/internal/lint"
+	"github.com/andrewhowdencom/ruf/internal/sourcer"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// debugLintCmd represents the debug lint command
+var debugLintCmd = &cobra.Command{
+	Use:   "lint [uri...]",
+	Short: "Lint sources of calls for likely mistakes.",
+	Long: `Lint sources of calls for mistakes that validation lets through. Each source given is linted, or
+each of source.urls if none is given, and every issue found is written with the rule it breaks:
+
+  invalid            (error)    the call fails validation, or a template does not parse.
+  duplicate-id       (error)    another call of the campaign has the same ID, in any of the sources.
+  empty-destination  (error)    a destination has no recipients in 'to', or an empty one.
+  unknown-variable   (error)    a template refers to a value that is not in the data of the call.
+  no-occurrences     (warning)  none of the triggers of the call fires again.
+  beyond-horizon     (warning)  a trigger next fires after the horizon calls are scheduled over.
+
+The command fails if any error is found, and with --strict if any warning is. Triggers are expanded
+with a temporary datastore, so that paused triggers and moved calls do not change the result, and it
+can be run in CI without access to the datastore.
+
+Example:
+  ruf debug lint file://./calls.yaml --strict`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		strict, _ := cmd.Flags().GetBool("strict")
+		urls := args
+		if len(urls) == 0 {
+			urls = viper.GetStringSlice("source.urls")
+		}
+		if len(urls) == 0 {
+			return fmt.Errorf("no sources to lint: give their URIs, or set source.urls")
+		}
+
+		s, err := buildSourcer()
+		if err != nil {
+			return err
+		}
+		dir, err := os.MkdirTemp("", "ruf-lint-")
+		if err != nil {
+			return fmt.Errorf("failed to create a temporary directory: %w", err)
+		}
+		defer os.RemoveAll(dir)
+		store, err := datastore.NewTestStore(filepath.Join(dir, "ruf.db"))
+		if err != nil {
+			return fmt.Errorf("failed to create the datastore: %w", err)
+		}
+		defer store.Close()
+		sched, err := buildScheduler(store)
+		if err != nil {
+			return fmt.Errorf("failed to build scheduler: %w", err)
+		}
+
+		linter := lint.New(sched, lint.WithTypes(destinationTypes()...), lint.WithHorizon(viper.GetDuration("worker.calculation.after")))
+		return doDebugLint(linter, s, cmd.OutOrStdout(), urls, strict, time.Now())
+	},
+}
+
+func doDebugLint(linter *lint.Linter, s sourcer.Sourcer, w io.Writer, urls []string, strict bool, now time.Time) error {
+	sources := make([]*sourcer.Source, len(urls))
+	for i, url := range urls {
+		source, _, err := s.Source(url)
+		if err != nil {
+			return fmt.Errorf("failed to read source %s: %w", url, err)
+		}
+		sources[i] = source
+	}
+
+	var errs, warnings int
+	for _, issue := range linter.Lint(urls, sources, now) {
+		if issue.Severity == lint.SeverityError {
+			errs++
+		} else {
+			warnings++
+		}
+		fmt.Fprintf(w, "%s: call '%s' of campaign '%s': %s: %s [%s]\n", issue.Source, issue.CallID, issue.CampaignID, issue.Severity, issue.Message, issue.Rule)
+	}
+	if errs == 0 && warnings == 0 {
+		fmt.Fprintln(w, "No issues found.")
+		return nil
+	}
+	fmt.Fprintf(w, "Errors: %d, warnings: %d\n", errs, warnings)
+	if errs > 0 || (strict && warnings > 0) {
+		return fmt.Errorf("lint failed: errors: %d, warnings: %d", errs, warnings)
+	}
+	return nil
+}
+
+func init() {
+	debugCmd.AddCommand(debugLintCmd)
+	debugLintCmd.Flags().Bool("strict", false, "Fail on warnings, as well as on errors.")
+}
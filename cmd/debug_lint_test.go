@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/andrewhowdencom/ruf/internal/datastore"
+	"github.com/andrewhowdencom/ruf/internal/lint"
+	"github.com/andrewhowdencom/ruf/internal/scheduler"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDoDebugLint(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+		return "file://" + path
+	}
+	clean := write("clean.yaml", `
+campaign:
+  id: team
+  name: Team
+calls:
+  - id: standup
+    subject: Standup
+    content: "Time for standup, {{ .Team }}!"
+    data:
+      Team: platform
+    destinations:
+      - type: slack
+        to: ["#general"]
+    triggers:
+      - cron: "0 9 * * 1-5"
+`)
+	past := write("past.yaml", `
+campaign:
+  id: launch
+  name: Launch
+calls:
+  - id: announce
+    subject: Launch
+    content: "We are live!"
+    destinations:
+      - type: slack
+        to: ["#general"]
+    triggers:
+      - scheduled_at: 2025-01-01T09:00:00Z
+`)
+
+	s, err := buildSourcer()
+	require.NoError(t, err)
+	linter := lint.New(scheduler.New(datastore.NewMockStore()))
+	now := time.Date(2025, 6, 2, 12, 0, 0, 0, time.UTC)
+
+	var buf bytes.Buffer
+	require.NoError(t, doDebugLint(linter, s, &buf, []string{clean}, true, now))
+	assert.Equal(t, "No issues found.\n", buf.String())
+
+	// A warning only fails the lint when it is strict.
+	buf.Reset()
+	require.NoError(t, doDebugLint(linter, s, &buf, []string{past}, false, now))
+	assert.Contains(t, buf.String(), "call 'announce' of campaign 'launch': warning: the call has no future occurrences")
+	assert.Contains(t, buf.String(), "[no-occurrences]")
+	err = doDebugLint(linter, s, &buf, []string{past}, true, now)
+	assert.ErrorContains(t, err, "lint failed: errors: 0, warnings: 1")
+
+	buf.Reset()
+	err = doDebugLint(linter, s, &buf, []string{clean, clean}, false, now)
+	assert.ErrorContains(t, err, "lint failed: errors: 1")
+	assert.Contains(t, buf.String(), "[duplicate-id]")
+}
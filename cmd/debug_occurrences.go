@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/andrewhowdencom/ruf/internal/datastore"
+	"github.com/andrewhowdencom/ruf/internal/model"
+	"github.com/andrewhowdencom/ruf/internal/scheduler"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+var (
+	occurrencesTrigger model.Trigger
+	occurrencesCount   int
+)
+
+var debugOccurrencesCmd = &cobra.Command{
+	Use:   "occurrences",
+	Short: "Preview the next occurrences of a trigger",
+	Long: `Preview the next occurrences of a trigger, to check a recurrence before committing it. The trigger is
+given by its flags, which match the fields of a trigger in a source file. Occurrences are moved into slots and
+around holidays as they would be in the schedule.
+
+Example:
+  ruf debug occurrences --cron "0 9 * * 1-5" --timezone Europe/Berlin
+  ruf debug occurrences --rrule "FREQ=MONTHLY;BYDAY=-1FR" --dstart 20250101 -n 12
+  ruf debug occurrences --hijri "1 Ramadan" --time 09:00`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store, err := datastore.NewStore(true)
+		if err != nil {
+			return fmt.Errorf("failed to create store: %w", err)
+		}
+		defer store.Close()
+
+		sched, err := buildScheduler(store)
+		if err != nil {
+			return fmt.Errorf("failed to build scheduler: %w", err)
+		}
+		return doDebugOccurrences(sched, cmd.OutOrStdout(), occurrencesTrigger, occurrencesCount, time.Now())
+	},
+}
+
+func doDebugOccurrences(sched *scheduler.Scheduler, w io.Writer, trigger model.Trigger, n int, now time.Time) error {
+	if trigger.Cron == "" && trigger.RRule == "" && trigger.Hijri == "" {
+		return fmt.Errorf("one of --cron, --rrule or --hijri is required")
+	}
+	loc := time.UTC
+	if trigger.Timezone != "" {
+		var err error
+		if loc, err = time.LoadLocation(trigger.Timezone); err != nil {
+			return fmt.Errorf("invalid timezone '%s': %w", trigger.Timezone, err)
+		}
+	}
+
+	occurrences, err := sched.Preview(trigger, n, now)
+	if err != nil {
+		return err
+	}
+	if len(occurrences) == 0 {
+		fmt.Fprintln(w, "The trigger does not fire again.")
+		return nil
+	}
+
+	table := tablewriter.NewWriter(w)
+	table.Header("#", "At", "Moved")
+	for i, o := range occurrences {
+		var moved []string
+		for _, shift := range o.Shifts {
+			moved = append(moved, fmt.Sprintf("from %s (%s)", shift.From.In(loc).Format(time.RFC3339), shift))
+		}
+		table.Append([]string{strconv.Itoa(i + 1), o.At.In(loc).Format("Mon " + time.RFC3339), strings.Join(moved, ", ")})
+	}
+	table.Render()
+	if len(occurrences) < n {
+		fmt.Fprintf(w, "The trigger fires only %d more times.\n", len(occurrences))
+	}
+	return nil
+}
+
+func init() {
+	debugCmd.AddCommand(debugOccurrencesCmd)
+	debugOccurrencesCmd.Flags().StringVar(&occurrencesTrigger.Cron, "cron", "", "A cron expression, such as \"0 9 * * 1-5\".")
+	debugOccurrencesCmd.Flags().StringVar(&occurrencesTrigger.RRule, "rrule", "", "An RRule, such as \"FREQ=WEEKLY;BYDAY=MO\".")
+	debugOccurrencesCmd.Flags().StringVar(&occurrencesTrigger.Hijri, "hijri", "", "A date in the Hijri calendar, such as \"1 Ramadan\".")
+	debugOccurrencesCmd.Flags().StringVar(&occurrencesTrigger.DStart, "dstart", "", "The start of the recurrence, such as \"20250106\".")
+	debugOccurrencesCmd.Flags().StringVar(&occurrencesTrigger.Time, "time", "", "The time of day a --hijri trigger fires at.")
+	debugOccurrencesCmd.Flags().StringVar(&occurrencesTrigger.Timezone, "timezone", "", "The IANA timezone the trigger is evaluated and shown in.")
+	debugOccurrencesCmd.Flags().IntVarP(&occurrencesCount, "count", "n", 10, "The number of occurrences to show.")
+}
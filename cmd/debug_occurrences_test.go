@@ -0,0 +1,33 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/andrewhowdencom/ruf/internal/datastore"
+	"github.com/andrewhowdencom/ruf/internal/model"
+	"github.com/andrewhowdencom/ruf/internal/scheduler"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDoDebugOccurrences(t *testing.T) {
+	sched := scheduler.New(datastore.NewMockStore())
+	now := time.Date(2025, 3, 3, 8, 0, 0, 0, time.UTC)
+
+	var buf bytes.Buffer
+	assert.NoError(t, doDebugOccurrences(sched, &buf, model.Trigger{Cron: "0 9 * * 1-5", Timezone: "Europe/Berlin"}, 3, now))
+	assert.Contains(t, buf.String(), "Mon 2025-03-03T09:00:00+01:00")
+	assert.Contains(t, buf.String(), "Wed 2025-03-05T09:00:00+01:00")
+	assert.NotContains(t, buf.String(), "2025-03-06")
+
+	buf.Reset()
+	assert.NoError(t, doDebugOccurrences(sched, &buf, model.Trigger{Cron: "0 9 * * *", DStart: "20250303", Count: 2}, 3, now))
+	assert.Contains(t, buf.String(), "The trigger fires only 2 more times.")
+
+	err := doDebugOccurrences(sched, &buf, model.Trigger{RRule: "FREQ=SOMETIMES"}, 3, now)
+	assert.ErrorIs(t, err, scheduler.ErrInvalidTrigger)
+
+	err = doDebugOccurrences(sched, &buf, model.Trigger{}, 3, now)
+	assert.ErrorContains(t, err, "one of --cron, --rrule or --hijri is required")
+}
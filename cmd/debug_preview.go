@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/andrewhowdencom/ruf/internal/model"
+	"github.com/andrewhowdencom/ruf/internal/worker"
+	"github.com/spf13/cobra"
+)
+
+// previewTypes are the destination types every call is previewed for, as well as those it is sent to.
+var previewTypes = []string{"slack", "email"}
+
+var debugPreviewCmd = &cobra.Command{
+	Use:   "preview <call-id>",
+	Short: "Render a call for every destination type.",
+	Long: `Render the subject and content of a call as the worker would send it: as plain text once its
+templates are rendered, and converted for Slack, for email and for each other destination type the call
+is sent to, one after the other.
+
+The call is rendered with its data, including that of its data_from, and the configured template
+functions and processors, as if it were scheduled at --at. The email is also written to a temporary
+HTML file that can be opened in a browser.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		call, err := findCall(cmd, args[0])
+		if err != nil {
+			return err
+		}
+		at, _ := cmd.Flags().GetString("at")
+		now := time.Now().UTC()
+		scheduledAt := now
+		if at != "" {
+			if scheduledAt, err = parseLaterTimeFlag("at", at, now); err != nil {
+				return err
+			}
+		}
+
+		opts, err := workerOptions()
+		if err != nil {
+			return err
+		}
+		return doDebugPreview(cmd.OutOrStdout(), call, scheduledAt, opts...)
+	},
+}
+
+func doDebugPreview(w io.Writer, def *model.Call, scheduledAt time.Time, opts ...worker.Option) error {
+	call := *def
+	call.ScheduledAt = scheduledAt.UTC()
+	types := slices.Clone(previewTypes)
+	for _, dest := range call.Destinations {
+		if !slices.Contains(types, dest.Type) {
+			types = append(types, dest.Type)
+		}
+	}
+
+	fmt.Fprintf(w, "Call '%s', as if scheduled at %s.\n", call.ID, call.ScheduledAt.Format(time.RFC3339))
+	printedText := false
+	for _, destType := range types {
+		r, err := worker.Render(&call, destType, opts...)
+		if err != nil {
+			fmt.Fprintf(w, "\n== %s ==\nFailed to render: %s\n", destType, err)
+			continue
+		}
+		if !printedText {
+			fmt.Fprintf(w, "\n== plain text ==\nSubject: %s\n\n%s\n", r.Subject, r.Text)
+			printedText = true
+		}
+		fmt.Fprintf(w, "\n== %s ==\nSubject: %s\n\n%s\n", destType, r.Subject, r.Content)
+		if destType == "email" {
+			path, err := writeEmailPreview(r)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(w, "\nOpen %s to see the email in a browser.\n", path)
+		}
+	}
+	return nil
+}
+
+// writeEmailPreview writes the rendered email to a temporary HTML file, and returns its path.
+func writeEmailPreview(r *worker.Rendered) (string, error) {
+	f, err := os.CreateTemp("", "ruf-preview-*.html")
+	if err != nil {
+		return "", fmt.Errorf("failed to create the email preview: %w", err)
+	}
+	defer f.Close()
+	_, err = fmt.Fprintf(f, "<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n<title>%s</title>\n</head>\n<body>\n%s\n</body>\n</html>\n",
+		html.EscapeString(r.Subject), r.Content)
+	if err != nil {
+		return "", fmt.Errorf("failed to write the email preview: %w", err)
+	}
+	return f.Name(), nil
+}
+
+func init() {
+	debugCmd.AddCommand(debugPreviewCmd)
+	debugPreviewCmd.Flags().String("at", "", "The time to render the call as if it were scheduled at: a date, an RFC 3339 time or a duration from now (now if unset)")
+}
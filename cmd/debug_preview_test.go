@@ -0,0 +1,39 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/andrewhowdencom/ruf/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDoDebugPreview(t *testing.T) {
+	call := &model.Call{
+		ID:           "launch",
+		Subject:      "Hello {{ .team }}",
+		Content:      "**Launch** on {{ .ScheduledAt.Format \"2006-01-02\" }}",
+		Data:         map[string]interface{}{"team": "platform"},
+		Destinations: []model.Destination{{Type: "slack", To: []string{"#general"}}},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, doDebugPreview(&buf, call, time.Date(2025, 6, 2, 9, 0, 0, 0, time.UTC)))
+	out := buf.String()
+	assert.Contains(t, out, "as if scheduled at 2025-06-02T09:00:00Z")
+	assert.Contains(t, out, "== plain text ==\nSubject: Hello platform\n\n**Launch** on 2025-06-02\n")
+	assert.Contains(t, out, "== slack ==\nSubject: Hello platform\n\n*Launch* on 2025-06-02")
+	assert.Contains(t, out, "<strong>Launch</strong> on 2025-06-02")
+
+	path := regexp.MustCompile(`Open (\S+) to see the email`).FindStringSubmatch(out)
+	require.Len(t, path, 2)
+	defer os.Remove(path[1])
+	page, err := os.ReadFile(path[1])
+	require.NoError(t, err)
+	assert.Contains(t, string(page), "<title>Hello platform</title>")
+	assert.Contains(t, string(page), "<strong>Launch</strong>")
+}
@@ -17,49 +17,32 @@ var debugRenderCmd = &cobra.Command{
 	Long:  `Render a specific call.`,
 	Args:  cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		s, err := buildSourcer()
+		callToRender, err := findCall(cmd, args[0])
 		if err != nil {
-			return fmt.Errorf("failed to build sourcer: %w", err)
+			return err
 		}
 
-		urls := viper.GetStringSlice("source.urls")
-		var allCalls []*model.Call
-
-		for _, url := range urls {
-			source, _, err := s.Source(url)
-			if err != nil {
-				fmt.Fprintf(cmd.ErrOrStderr(), "Error sourcing from %s: %v\n", url, err)
-				continue
-			}
-			if source == nil {
-				continue
-			}
-			for i := range source.Calls {
-				allCalls = append(allCalls, &source.Calls[i])
-			}
+		// Includes and data_from are fetched as they are now, as the call has not been pinned to them.
+		cache, err := buildAssets()
+		if err != nil {
+			return err
 		}
-
-		callID := args[0]
-		var callToRender *model.Call
-		for _, call := range allCalls {
-			if call.ID == callID {
-				callToRender = call
-				break
-			}
+		data, err := cache.Data(callToRender)
+		if err != nil {
+			return fmt.Errorf("failed to fetch data: %w", err)
 		}
-
-		if callToRender == nil {
-			return fmt.Errorf("call with ID '%s' not found", callID)
+		opts := []processor.TemplateOption{processor.WithFuncs(cache.Funcs(callToRender))}
+		if callToRender.Processors.Strict(viper.GetBool("worker.strict_templates")) {
+			opts = append(opts, processor.WithStrict())
 		}
+		p := processor.NewTemplateProcessor(opts...)
 
-		p := processor.NewTemplateProcessor()
-
-		subject, err := p.Process(callToRender.Subject, nil)
+		subject, err := p.Process(callToRender.Subject, data)
 		if err != nil {
 			return fmt.Errorf("failed to render subject: %w", err)
 		}
 
-		content, err := p.Process(callToRender.Content, nil)
+		content, err := p.Process(callToRender.Content, data)
 		if err != nil {
 			return fmt.Errorf("failed to render content: %w", err)
 		}
@@ -96,6 +79,31 @@ var debugRenderCmd = &cobra.Command{
 	},
 }
 
+// findCall returns the call with the given ID from the configured sources.
+func findCall(cmd *cobra.Command, callID string) (*model.Call, error) {
+	s, err := buildSourcer()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build sourcer: %w", err)
+	}
+
+	for _, url := range viper.GetStringSlice("source.urls") {
+		source, _, err := s.Source(url)
+		if err != nil {
+			fmt.Fprintf(cmd.ErrOrStderr(), "Error sourcing from %s: %v\n", url, err)
+			continue
+		}
+		if source == nil {
+			continue
+		}
+		for i := range source.Calls {
+			if source.Calls[i].ID == callID {
+				return &source.Calls[i], nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("call with ID '%s' not found", callID)
+}
+
 func init() {
 	debugCmd.AddCommand(debugRenderCmd)
 }
@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/andrewhowdencom/ruf/internal/model"
+	"github.com/andrewhowdencom/ruf/internal/preview"
+	"github.com/spf13/cobra"
+)
+
+var debugScreenshotCmd = &cobra.Command{
+	Use:   "screenshot [CALL_ID]",
+	Short: "Render a call to an image.",
+	Long: `Render a call as it would appear in Slack or in an email, and capture it as a PNG image with a
+headless Chrome or Chromium, so that reviews of changes to calls can include a visual preview.
+
+If the output file ends in .html, the page is written without capturing it, and no browser is needed.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		call, err := findCall(cmd, args[0])
+		if err != nil {
+			return err
+		}
+
+		destType, _ := cmd.Flags().GetString("type")
+		out, _ := cmd.Flags().GetString("out")
+		browser, _ := cmd.Flags().GetString("browser")
+		width, _ := cmd.Flags().GetInt("width")
+
+		r := preview.NewRenderer(preview.WithExecPath(browser), preview.WithWidth(width))
+		data, err := renderPreview(cmd.Context(), r, call, destType, strings.EqualFold(filepath.Ext(out), ".html"))
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(out, data, 0644); err != nil {
+			return fmt.Errorf("failed to write '%s': %w", out, err)
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "Wrote preview of '%s' to %s\n", call.ID, out)
+		return nil
+	},
+}
+
+// renderPreview renders the call either as an HTML page or as a PNG image.
+func renderPreview(ctx context.Context, r *preview.Renderer, call *model.Call, destType string, html bool) ([]byte, error) {
+	var (
+		data []byte
+		err  error
+	)
+	if html {
+		data, err = r.HTML(call, destType, time.Now())
+	} else {
+		data, err = r.Screenshot(ctx, call, destType, time.Now())
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to render '%s': %w", call.ID, err)
+	}
+	return data, nil
+}
+
+func init() {
+	debugCmd.AddCommand(debugScreenshotCmd)
+	debugScreenshotCmd.Flags().String("type", preview.TypeSlack, "The destination type to render the call as (slack or email)")
+	debugScreenshotCmd.Flags().String("out", "preview.png", "The file to write the preview to")
+	debugScreenshotCmd.Flags().String("browser", "", "The path of the Chrome or Chromium executable (found automatically if unset)")
+	debugScreenshotCmd.Flags().Int("width", preview.DefaultWidth, "The width of the preview, in pixels")
+}
@@ -2,10 +2,9 @@ package cmd
 
 import (
 	"fmt"
-	"path/filepath"
-	"runtime"
 	"strings"
 
+	"github.com/andrewhowdencom/ruf/internal/eventsource"
 	rufhttp "github.com/andrewhowdencom/ruf/internal/http"
 	"github.com/andrewhowdencom/ruf/internal/model"
 	"github.com/andrewhowdencom/ruf/internal/sourcer"
@@ -29,16 +28,13 @@ var debugValidateCmd = &cobra.Command{
 		fetcher.AddFetcher("file", sourcer.NewFileFetcher())
 		// Not including git fetcher for now, as it requires more configuration
 
-		// Get the path to the current source file, and then find the schema file relative to that.
-		_, b, _, _ := runtime.Caller(0)
-		basepath := filepath.Dir(b)
-		schemaPath := filepath.Join(basepath, "..", "schema", "calls.json")
-
-		parser, err := sourcer.NewYAMLParser(schemaPath)
+		parser, err := sourcer.NewYAMLParser()
 		if err != nil {
 			return fmt.Errorf("failed to create parser: %w", err)
 		}
-		s := sourcer.NewSourcer(fetcher, parser)
+		// Event sources are read too, so that their calendars and matches are checked.
+		resolver := eventsource.NewResolver(eventsource.WithHTTPClient(httpClient))
+		s := sourcer.NewSourcer(fetcher, parser, sourcer.WithEventResolver(resolver))
 
 		source, _, err := s.Source(uri)
 		if err != nil {
@@ -55,12 +51,36 @@ var debugValidateCmd = &cobra.Command{
 			callsToValidate[i] = &source.Calls[i]
 		}
 
-		errs := validator.Validate(callsToValidate)
+		errs := validator.Validate(callsToValidate, validator.WithTypes(destinationTypes()...))
+
+		// The content of emails is checked against the accessibility rules of the comms guidelines.
+		cache, err := buildAssets()
+		if err != nil {
+			return err
+		}
+		for _, call := range callsToValidate {
+			issues, err := accessibilityIssues(cache, call)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("call %s: %w", call.ID, err))
+			}
+			for _, issue := range issues {
+				errs = append(errs, fmt.Errorf("call %s: accessibility: %s", call.ID, issue))
+			}
+		}
+
 		if len(errs) > 0 {
 			var errStrings []string
 			for _, err := range errs {
 				errStrings = append(errStrings, err.Error())
 			}
+			// The owners of the campaign are named, so that whoever sees the failure knows who can fix it.
+			router, err := buildOwners()
+			if err != nil {
+				return err
+			}
+			if sourceOwners, _ := router.Owners(source.Campaign); len(sourceOwners) > 0 {
+				errStrings = append(errStrings, "owners: "+strings.Join(sourceOwners, ", "))
+			}
 			return fmt.Errorf("validation failed:\n%s", strings.Join(errStrings, "\n"))
 		}
 
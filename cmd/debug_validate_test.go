@@ -98,6 +98,23 @@ calls:
 		t.Fatal(err)
 	}
 
+	// Test case 6: Email that is not accessible
+	inaccessibleYAML := `
+calls:
+  - id: "test-call"
+    subject: "Test Subject"
+    content: "Read the release notes [here](https://example.com/notes)."
+    destinations:
+      - type: "email"
+        to: ["team@example.com"]
+    triggers:
+      - scheduled_at: "2025-01-01T12:00:00Z"
+`
+	inaccessibleFile := filepath.Join(tmpdir, "inaccessible.yaml")
+	if err := ioutil.WriteFile(inaccessibleFile, []byte(inaccessibleYAML), 0644); err != nil {
+		t.Fatal(err)
+	}
+
 	testCases := []struct {
 		name          string
 		args          []string
@@ -120,7 +137,7 @@ calls:
 			name:          "missing required fields",
 			args:          []string{"validate", "file://" + missingFieldsFile},
 			expectedOutput: "",
-			expectError:   false, // The sourcer will return nil, nil, so the command should not error
+			expectError:   true,
 		},
 		{
 			name:          "invalid cron expression",
@@ -134,6 +151,12 @@ calls:
 			expectedOutput: "",
 			expectError:   true,
 		},
+		{
+			name:          "inaccessible email",
+			args:          []string{"validate", "file://" + inaccessibleFile},
+			expectedOutput: "",
+			expectError:   true,
+		},
 		{
 			name:          "file not found",
 			args:          []string{"validate", "file:///nonexistent.yaml"},
@@ -0,0 +1,17 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// destinationsCmd represents the destinations command
+var destinationsCmd = &cobra.Command{
+	Use:     "destinations",
+	Aliases: []string{"destination"},
+	Short:   "List and check the destinations calls can be sent to.",
+	Long:    `List and check the destinations calls can be sent to, before a call is sent to them.`,
+}
+
+func init() {
+	rootCmd.AddCommand(destinationsCmd)
+}
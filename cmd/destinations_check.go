@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/andrewhowdencom/ruf/internal/clients/slack"
+	"github.com/andrewhowdencom/ruf/internal/worker"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// destinationsCheckCmd represents the destinations check command
+var destinationsCheckCmd = &cobra.Command{
+	Use:   "check <destination>",
+	Short: "Check that a destination can be sent to.",
+	Long: `Check that a destination can be sent to, and resolve it exactly as the worker does when it sends a
+call to it: a Slack channel must exist, not be archived and have the app as a member, and a user
+must exist, and an email address must parse. A Slack destination is resolved to the ID of the
+conversation the call would be posted to.
+
+--type is the type of the destination, slack by default.
+
+Example:
+  ruf destinations check '#general'
+  ruf destinations check team@example.com --type email`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		destType, _ := cmd.Flags().GetString("type")
+		return doDestinationsCheck(cmd.Context(), slackNewClient(viper.GetString("slack.app.token")), cmd.OutOrStdout(), destType, args[0])
+	},
+}
+
+func doDestinationsCheck(ctx context.Context, slackClient slack.Client, w io.Writer, destType, to string) error {
+	if destType == "slack" && viper.GetString("slack.app.token") == "" {
+		return fmt.Errorf("slack.app.token must be set to check Slack destinations")
+	}
+	id, err := worker.CheckDestination(ctx, slackClient, destType, to)
+	if err != nil {
+		return fmt.Errorf("%s destination '%s' cannot be sent to: %w", destType, to, err)
+	}
+	if id == to {
+		fmt.Fprintf(w, "%s destination '%s' can be sent to.\n", destType, to)
+	} else {
+		fmt.Fprintf(w, "%s destination '%s' can be sent to, as %s.\n", destType, to, id)
+	}
+	return nil
+}
+
+func init() {
+	destinationsCmd.AddCommand(destinationsCheckCmd)
+	destinationsCheckCmd.Flags().String("type", "slack", "The type of the destination: slack or email.")
+}
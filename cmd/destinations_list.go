@@ -0,0 +1,148 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/andrewhowdencom/ruf/internal/clients/slack"
+	"github.com/andrewhowdencom/ruf/internal/sourcer"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// destinationsListCmd represents the destinations list command
+var destinationsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the destinations calls can be sent to.",
+	Long: `List the destinations calls can be sent to: the Slack channels the app is a member of, and the
+email addresses the calls of source.urls are sent to. Each is listed with the number of calls of
+source.urls that are sent to it, so that a channel no call is sent to can be told apart from one
+that calls name differently.
+
+--type lists only the destinations of that type, slack or email. Slack channels are only listed when
+slack.app.token is set.
+
+Example:
+  ruf destinations list --type slack`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		destType, _ := cmd.Flags().GetString("type")
+
+		s, err := buildSourcer()
+		if err != nil {
+			return err
+		}
+		var slackClient slack.Client
+		if token := viper.GetString("slack.app.token"); token != "" {
+			slackClient = slackNewClient(token)
+		}
+		return doDestinationsList(cmd.Context(), slackClient, s, cmd.OutOrStdout(), viper.GetStringSlice("source.urls"), destType)
+	},
+}
+
+// listedDestination is a destination that calls can be sent to.
+type listedDestination struct {
+	destType string
+	to       string
+	id       string
+	detail   string
+	calls    int
+}
+
+func doDestinationsList(ctx context.Context, slackClient slack.Client, s sourcer.Sourcer, w io.Writer, urls []string, destType string) error {
+	switch destType {
+	case "", "slack", "email":
+	default:
+		return fmt.Errorf("invalid --type '%s': must be slack or email", destType)
+	}
+
+	// The calls of each source are counted against the destinations they are sent to.
+	used := make(map[string]int)
+	for _, url := range urls {
+		source, _, err := s.Source(url)
+		if err != nil {
+			slog.Warn("failed to read source, its calls are not counted", "source", url, "error", err)
+			continue
+		}
+		if source == nil {
+			continue
+		}
+		for _, call := range source.Calls {
+			dests := slices.Clone(call.Destinations)
+			for _, trigger := range call.Triggers {
+				dests = append(dests, trigger.Destinations...)
+			}
+			for _, dest := range dests {
+				for _, to := range dest.To {
+					used[dest.Type+" "+destinationKey(dest.Type, to)]++
+				}
+			}
+		}
+	}
+
+	var listed []listedDestination
+	if destType == "" || destType == "slack" {
+		if slackClient == nil {
+			if destType == "slack" {
+				return fmt.Errorf("slack.app.token must be set to list Slack channels")
+			}
+		} else {
+			channels, err := slackClient.ListChannels(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to list Slack channels: %w", err)
+			}
+			for _, ch := range channels {
+				d := listedDestination{destType: "slack", to: "#" + ch.Name, id: ch.ID, detail: "public channel"}
+				if ch.Private {
+					d.detail = "private channel"
+				}
+				// A call may name the channel or give its ID.
+				d.calls = used["slack "+destinationKey("slack", d.to)] + used["slack "+ch.ID]
+				listed = append(listed, d)
+			}
+		}
+	}
+	if destType == "" || destType == "email" {
+		var emails []listedDestination
+		for key, calls := range used {
+			if to, ok := strings.CutPrefix(key, "email "); ok {
+				emails = append(emails, listedDestination{destType: "email", to: to, detail: "address", calls: calls})
+			}
+		}
+		sort.Slice(emails, func(i, j int) bool { return emails[i].to < emails[j].to })
+		listed = append(listed, emails...)
+	}
+
+	if len(listed) == 0 {
+		fmt.Fprintln(w, "No destinations were found.")
+		return nil
+	}
+	table := tablewriter.NewWriter(w)
+	table.Header("Type", "Destination", "ID", "Detail", "Calls")
+	for _, d := range listed {
+		table.Append([]string{d.destType, d.to, d.id, d.detail, strconv.Itoa(d.calls)})
+	}
+	return table.Render()
+}
+
+// destinationKey normalises a recipient, so that the names calls give the same destination are counted
+// together: Slack channel names and email addresses are not case sensitive.
+func destinationKey(destType, to string) string {
+	switch {
+	case destType == "slack" && strings.HasPrefix(to, "#"), destType == "email":
+		return strings.ToLower(to)
+	default:
+		return to
+	}
+}
+
+func init() {
+	destinationsCmd.AddCommand(destinationsListCmd)
+	destinationsListCmd.Flags().String("type", "", "Only list destinations of this type: slack or email.")
+}
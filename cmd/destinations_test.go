@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/andrewhowdencom/ruf/internal/clients/slack"
+	"github.com/andrewhowdencom/ruf/internal/worker"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDestinationsList(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "calls.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+campaign:
+  id: team
+  name: Team
+calls:
+  - id: standup
+    subject: Standup
+    content: "Time for standup!"
+    destinations:
+      - type: slack
+        to: ["#General"]
+      - type: email
+        to: ["team@example.com"]
+    triggers:
+      - cron: "0 9 * * 1-5"
+        destinations:
+          - type: slack
+            to: ["C2"]
+`), 0644))
+	s, err := buildSourcer()
+	require.NoError(t, err)
+
+	slackClient := slack.NewMockClient()
+	slackClient.ListChannelsFunc = func(context.Context) ([]slack.Channel, error) {
+		return []slack.Channel{{ID: "C1", Name: "general"}, {ID: "C2", Name: "leads", Private: true}, {ID: "C3", Name: "random"}}, nil
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, doDestinationsList(context.Background(), slackClient, s, &buf, []string{"file://" + path}, ""))
+	out := buf.String()
+	assert.Regexp(t, `#general\s*│\s*C1\s*│\s*public channel\s*│\s*1`, out)
+	assert.Regexp(t, `#leads\s*│\s*C2\s*│\s*private channel\s*│\s*1`, out)
+	assert.Regexp(t, `#random\s*│\s*C3\s*│\s*public channel\s*│\s*0`, out)
+	assert.Regexp(t, `team@example.com\s*│\s*│\s*address\s*│\s*1`, out)
+
+	buf.Reset()
+	require.NoError(t, doDestinationsList(context.Background(), nil, s, &buf, []string{"file://" + path}, "email"))
+	assert.NotContains(t, buf.String(), "#general")
+	assert.Contains(t, buf.String(), "team@example.com")
+
+	err = doDestinationsList(context.Background(), nil, s, &buf, nil, "slack")
+	assert.ErrorContains(t, err, "slack.app.token must be set")
+}
+
+func TestDestinationsCheck(t *testing.T) {
+	t.Cleanup(viper.Reset)
+	viper.Set("slack.app.token", "token")
+
+	slackClient := slack.NewMockClient()
+	slackClient.ValidateDestinationFunc = func(_ context.Context, destination string) error {
+		if destination == "#old" {
+			return fmt.Errorf("%w: channel '%s' is archived", slack.ErrInvalidDestination, destination)
+		}
+		return nil
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, doDestinationsCheck(context.Background(), slackClient, &buf, "slack", "#general"))
+	assert.Equal(t, "slack destination '#general' can be sent to, as C1234567890.\n", buf.String())
+
+	buf.Reset()
+	require.NoError(t, doDestinationsCheck(context.Background(), slackClient, &buf, "email", "team@example.com"))
+	assert.Equal(t, "email destination 'team@example.com' can be sent to.\n", buf.String())
+
+	err := doDestinationsCheck(context.Background(), slackClient, &buf, "slack", "#old")
+	assert.ErrorIs(t, err, slack.ErrInvalidDestination)
+	assert.ErrorContains(t, doDestinationsCheck(context.Background(), slackClient, &buf, "email", "team"), "cannot be sent to")
+	assert.ErrorIs(t, doDestinationsCheck(context.Background(), slackClient, &buf, "line", "U1"), worker.ErrUncheckedDestination)
+}
@@ -16,4 +16,6 @@ func init() {
 	rootCmd.AddCommand(dispatcherCmd)
 	dispatcherCmd.PersistentFlags().Bool("dry-run", false, "Enable dry run mode")
 	viper.BindPFlag("dispatcher.dry_run", dispatcherCmd.PersistentFlags().Lookup("dry-run"))
+	dispatcherCmd.PersistentFlags().Bool("strict-templates", false, "Hold calls whose templates refer to missing values, rather than send them")
+	viper.BindPFlag("worker.strict_templates", dispatcherCmd.PersistentFlags().Lookup("strict-templates"))
 }
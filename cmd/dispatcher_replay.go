@@ -0,0 +1,139 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/andrewhowdencom/ruf/internal/clients/email"
+	"github.com/andrewhowdencom/ruf/internal/clients/slack"
+	"github.com/andrewhowdencom/ruf/internal/kv"
+	"github.com/andrewhowdencom/ruf/internal/worker"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var replayAllFailed bool
+
+// replayCmd represents the dispatcher replay command
+var replayCmd = &cobra.Command{
+	Use:   "replay [<id>...]",
+	Short: "Send messages that failed again, from the dead-letter queue.",
+	Long: `Send messages that failed again, once the cause of the failure has been fixed.
+
+Every message that fails to send is kept in the dead-letter queue with the call it
+was rendered from, its data and the time it was scheduled for, so that it is sent as
+it would have been. Messages are given by the ID or short ID shown by 'ruf sent list',
+or all at once with --all-failed. A message that is sent leaves the queue; one that
+fails again stays in it, with one more attempt.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) == 0 && !replayAllFailed {
+			return fmt.Errorf("give the IDs of the messages to replay, or --all-failed")
+		}
+		if len(args) > 0 && replayAllFailed {
+			return fmt.Errorf("give either the IDs of the messages to replay or --all-failed, not both")
+		}
+
+		store, err := datastoreNewStore(false)
+		if err != nil {
+			return fmt.Errorf("failed to create a new datastore: %w", err)
+		}
+		defer store.Close()
+
+		slackClient := slackNewClient(viper.GetString("slack.app.token"))
+		emailClient := emailNewClient(
+			viper.GetString("email.host"),
+			viper.GetInt("email.port"),
+			viper.GetString("email.username"),
+			viper.GetString("email.password"),
+			viper.GetString("email.from"),
+		)
+
+		opts, err := workerOptions()
+		if err != nil {
+			return err
+		}
+		dryRun := sendDryRun(cmd.ErrOrStderr())
+		if !dryRun {
+			if err := guardProduction(cmd); err != nil {
+				return err
+			}
+		}
+		return doDispatcherReplay(cmd.Context(), store, slackClient, emailClient, cmd.OutOrStdout(), args, replayAllFailed, dryRun, opts...)
+	},
+}
+
+func doDispatcherReplay(ctx context.Context, store kv.Storer, slackClient slack.Client, emailClient email.Client, w io.Writer, ids []string, all, dryRun bool, opts ...worker.Option) error {
+	var letters []*kv.DeadLetter
+	if all {
+		var err error
+		if letters, err = store.ListDeadLetters(); err != nil {
+			return fmt.Errorf("failed to list dead letters: %w", err)
+		}
+		sort.Slice(letters, func(i, j int) bool { return letters[i].FailedAt.Before(letters[j].FailedAt) })
+		if len(letters) == 0 {
+			fmt.Fprintln(w, "No failed messages to replay.")
+			return nil
+		}
+	}
+	for _, id := range ids {
+		letter, err := findDeadLetter(store, id)
+		if err != nil {
+			return err
+		}
+		letters = append(letters, letter)
+	}
+
+	failed := 0
+	for _, letter := range letters {
+		shortID := kv.GenerateShortID(letter.ID)
+		sm, err := worker.Replay(ctx, letter, store, slackClient, emailClient, dryRun, opts...)
+		switch {
+		case err != nil:
+			failed++
+			fmt.Fprintf(w, "Could not replay %s to %s (%s): %v\n", shortID, letter.Destination, letter.Type, err)
+		case dryRun:
+			fmt.Fprintf(w, "Dry run: %s not replayed to %s (%s)\n", shortID, letter.Destination, letter.Type)
+		case sm.Failed():
+			failed++
+			fmt.Fprintf(w, "Replayed %s to %s (%s), but it failed again: %s\n", shortID, letter.Destination, letter.Type, sm.Error)
+		default:
+			fmt.Fprintf(w, "Replayed %s to %s (%s): %s\n", shortID, letter.Destination, letter.Type, sm.Status)
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d messages could not be replayed", failed, len(letters))
+	}
+	return nil
+}
+
+// findDeadLetter returns the dead letter of a sent message, given its ID or short ID.
+func findDeadLetter(store kv.Storer, id string) (*kv.DeadLetter, error) {
+	letter, err := store.GetDeadLetter(id)
+	if err == nil {
+		return letter, nil
+	}
+	if !errors.Is(err, kv.ErrNotFound) {
+		return nil, err
+	}
+
+	sm, err := store.GetSentMessageByShortID(id)
+	if errors.Is(err, kv.ErrNotFound) {
+		return nil, fmt.Errorf("could not find a failed message with ID '%s': %w", id, err)
+	}
+	if err != nil {
+		return nil, err
+	}
+	letter, err = store.GetDeadLetter(sm.ID)
+	if errors.Is(err, kv.ErrNotFound) {
+		return nil, fmt.Errorf("message '%s' is %s, and not waiting to be replayed: %w", id, sm.Status, err)
+	}
+	return letter, err
+}
+
+func init() {
+	dispatcherCmd.AddCommand(replayCmd)
+	replayCmd.Flags().BoolVar(&replayAllFailed, "all-failed", false, "Replay every message in the dead-letter queue")
+}
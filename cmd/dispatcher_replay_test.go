@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/andrewhowdencom/ruf/internal/clients/email"
+	"github.com/andrewhowdencom/ruf/internal/clients/slack"
+	"github.com/andrewhowdencom/ruf/internal/datastore"
+	"github.com/andrewhowdencom/ruf/internal/kv"
+	"github.com/andrewhowdencom/ruf/internal/model"
+	"github.com/andrewhowdencom/ruf/internal/worker"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDispatcherReplay(t *testing.T) {
+	store := datastore.NewMockStore()
+	scheduledAt := time.Date(2025, 6, 2, 9, 0, 0, 0, time.UTC)
+	call := &model.Call{
+		ID:           "launch",
+		Content:      "Hello, {{ .name }}!",
+		Data:         map[string]interface{}{"name": "world"},
+		ScheduledAt:  scheduledAt,
+		Destinations: []model.Destination{{Type: "slack", To: []string{"#general", "#random"}}},
+		Campaign:     model.Campaign{ID: "campaign", Name: "Campaign"},
+	}
+
+	// Both messages fail, and are kept in the dead-letter queue.
+	slackClient := slack.NewMockClient()
+	slackClient.PostMessageFunc = func(_ context.Context, channel, author, subject, text string, campaign model.Campaign) (string, string, error) {
+		return "", "", errors.New("not_in_channel")
+	}
+	require.NoError(t, worker.ProcessCall(context.Background(), call, store, slackClient, email.NewMockClient(), false))
+	letters, err := store.ListDeadLetters()
+	require.NoError(t, err)
+	require.Len(t, letters, 2)
+
+	// A message that fails again stays in the queue, with another attempt.
+	var out bytes.Buffer
+	general := kv.GenerateID("campaign", "launch", scheduledAt, "slack", "#general")
+	err = doDispatcherReplay(context.Background(), store, slackClient, email.NewMockClient(), &out, []string{kv.GenerateShortID(general)}, false, false)
+	assert.ErrorContains(t, err, "1 of 1 messages could not be replayed")
+	assert.Contains(t, out.String(), "failed again: not_in_channel")
+	letter, err := store.GetDeadLetter(general)
+	require.NoError(t, err)
+	assert.Equal(t, 2, letter.Attempts)
+
+	// Once the cause is fixed, every message is sent as it was rendered, and leaves the queue.
+	out.Reset()
+	slackClient = slack.NewMockClient()
+	require.NoError(t, doDispatcherReplay(context.Background(), store, slackClient, email.NewMockClient(), &out, nil, true, false))
+	assert.Contains(t, out.String(), "to #general (slack): sent")
+	assert.Contains(t, out.String(), "to #random (slack): sent")
+	if assert.Len(t, slackClient.PostMessageCalls(), 2) {
+		assert.Equal(t, "Hello, world!", slackClient.PostMessageCalls()[0].Text)
+	}
+	sm, err := store.GetSentMessage(general)
+	require.NoError(t, err)
+	assert.Equal(t, kv.StatusSent, sm.Status)
+	assert.Equal(t, scheduledAt, sm.ScheduledAt)
+	assert.Equal(t, 2, sm.Retries, "the attempts that failed are the retries of the message")
+	random, err := store.GetSentMessage(kv.GenerateID("campaign", "launch", scheduledAt, "slack", "#random"))
+	require.NoError(t, err)
+	assert.Equal(t, 1, random.Retries)
+	letters, err = store.ListDeadLetters()
+	require.NoError(t, err)
+	assert.Empty(t, letters)
+
+	out.Reset()
+	require.NoError(t, doDispatcherReplay(context.Background(), store, slackClient, email.NewMockClient(), &out, nil, true, false))
+	assert.Contains(t, out.String(), "No failed messages to replay.")
+	err = doDispatcherReplay(context.Background(), store, slackClient, email.NewMockClient(), &out, []string{kv.GenerateShortID(general)}, false, false)
+	assert.ErrorContains(t, err, "is sent, and not waiting to be replayed")
+}
@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"time"
 
@@ -8,6 +9,7 @@ import (
 	"github.com/andrewhowdencom/ruf/internal/clients/slack"
 	"github.com/andrewhowdencom/ruf/internal/datastore"
 	"github.com/andrewhowdencom/ruf/internal/model"
+	"github.com/andrewhowdencom/ruf/internal/sourcer"
 	"github.com/andrewhowdencom/ruf/internal/worker"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -23,7 +25,14 @@ var (
 var sendCmd = &cobra.Command{
 	Use:   "send",
 	Short: "Send a message from a call to a specific destination.",
-	Long:  `Send a message from a call to a specific destination.`,
+	Long: `Send a message from a call to a specific destination.
+
+The call is found in the sources of source.urls, or in those given with --source instead. A source of
+"-" is read from standard input, so that a script can pipe a generated call straight into ruf:
+
+  generate-call | ruf dispatcher send --source - --destination "#general" --type slack
+
+--id may be left out when the sources define a single call.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		// Get flags
 		id, _ := cmd.Flags().GetString("id")
@@ -35,10 +44,20 @@ var sendCmd = &cobra.Command{
 			return fmt.Errorf("failed to build sourcer: %w", err)
 		}
 		urls := viper.GetStringSlice("source.urls")
+		if sources, _ := cmd.Flags().GetStringSlice("source"); len(sources) > 0 {
+			urls = sources
+		}
 		var selectedCall *model.Call
+		var calls int
 
 		for _, url := range urls {
 			source, _, err := s.Source(url)
+			// Sources that are not valid are skipped, as they are by the worker.
+			var verr *sourcer.ValidationError
+			if errors.As(err, &verr) {
+				fmt.Fprintf(cmd.ErrOrStderr(), "Warning: skipping source: %v\n", err)
+				continue
+			}
 			if err != nil {
 				return fmt.Errorf("could not source calls from %s: %w", url, err)
 			}
@@ -48,16 +67,22 @@ var sendCmd = &cobra.Command{
 			}
 
 			for i := range source.Calls {
-				if source.Calls[i].ID == id {
+				calls++
+				if id == "" {
+					selectedCall = &source.Calls[i]
+				} else if source.Calls[i].ID == id {
 					selectedCall = &source.Calls[i]
 					break
 				}
 			}
-			if selectedCall != nil {
+			if selectedCall != nil && id != "" {
 				break
 			}
 		}
 
+		if id == "" && calls != 1 {
+			return fmt.Errorf("the sources define %d calls: choose one with --id", calls)
+		}
 		if selectedCall == nil {
 			return fmt.Errorf("call with id '%s' not found", id)
 		}
@@ -86,10 +111,24 @@ var sendCmd = &cobra.Command{
 			viper.GetString("email.from"),
 		)
 
-		if err := worker.ProcessCall(selectedCall, store, slackClient, emailClient, viper.GetBool("dispatcher.dry_run")); err != nil {
+		opts, err := workerOptions()
+		if err != nil {
+			return err
+		}
+		dryRun := sendDryRun(cmd.ErrOrStderr())
+		if !dryRun {
+			if err := guardProduction(cmd); err != nil {
+				return err
+			}
+		}
+		if err := worker.ProcessCall(cmd.Context(), selectedCall, store, slackClient, emailClient, dryRun, opts...); err != nil {
 			return fmt.Errorf("failed to process call: %w", err)
 		}
 
+		if dryRun {
+			fmt.Fprintf(cmd.OutOrStdout(), "Dry run: message not sent to %s\n", dest)
+			return nil
+		}
 		fmt.Fprintf(cmd.OutOrStdout(), "Message sent successfully to %s\n", dest)
 		return nil
 	},
@@ -97,11 +136,11 @@ var sendCmd = &cobra.Command{
 
 func init() {
 	dispatcherCmd.AddCommand(sendCmd)
-	sendCmd.Flags().String("id", "", "ID of the call to send")
+	sendCmd.Flags().String("id", "", "ID of the call to send, which may be left out when the sources define a single call")
 	sendCmd.Flags().String("destination", "", "Destination to send the message to")
 	sendCmd.Flags().String("type", "", "Type of the destination (e.g., slack, email)")
+	sendCmd.Flags().StringSlice("source", nil, "Source URLs to find the call in instead of source.urls, such as - for standard input")
 
-	sendCmd.MarkFlagRequired("id")
 	sendCmd.MarkFlagRequired("destination")
 	sendCmd.MarkFlagRequired("type")
 }
@@ -3,12 +3,14 @@ package cmd
 import (
 	"bytes"
 	"os"
+	"strings"
 	"testing"
 
 	"github.com/andrewhowdencom/ruf/internal/clients/email"
 	"github.com/andrewhowdencom/ruf/internal/clients/slack"
 	"github.com/andrewhowdencom/ruf/internal/datastore"
 	"github.com/andrewhowdencom/ruf/internal/kv"
+	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
 	"github.com/stretchr/testify/assert"
 )
@@ -62,7 +64,7 @@ func TestSendCmdSlack(t *testing.T) {
 	datastoreNewStore = func(readOnly bool) (kv.Storer, error) {
 		return test.mockStore, nil
 	}
-	slackNewClient = func(token string) slack.Client {
+	slackNewClient = func(token string, opts ...slack.Option) slack.Client {
 		return test.mockSlackClient
 	}
 
@@ -135,3 +137,48 @@ func TestSendCmdEmail(t *testing.T) {
 	assert.Equal(t, "email", sentMessages[0].Type)
 	assert.Equal(t, "test@example.com", sentMessages[0].Destination)
 }
+
+func TestSendCmdStdin(t *testing.T) {
+	test := &sendCmdTest{}
+	test.setup(t)
+
+	datastoreNewStore = func(readOnly bool) (kv.Storer, error) {
+		return test.mockStore, nil
+	}
+	slackNewClient = func(token string, opts ...slack.Option) slack.Client {
+		return test.mockSlackClient
+	}
+	sourceStdin = strings.NewReader(`
+calls:
+  - id: generated
+    content: "Generated by a script."
+    destinations:
+      - type: slack
+        to: ["#dummy"]
+    triggers:
+      - scheduled_at: "2024-01-01T00:00:00Z"
+`)
+	t.Cleanup(func() {
+		sourceStdin = os.Stdin
+		sendCmd.Flags().Lookup("source").Value.(pflag.SliceValue).Replace(nil)
+	})
+
+	var buf bytes.Buffer
+	rootCmd.SetOut(&buf)
+	rootCmd.SetErr(&buf)
+
+	// The call is read from standard input, and is found without its ID as it is the only one.
+	rootCmd.SetArgs([]string{"dispatcher", "send", "--source", "-", "--id", "", "--destination", "#general", "--type", "slack"})
+	assert.NoError(t, rootCmd.Execute())
+	assert.Contains(t, buf.String(), "Message sent successfully to #general")
+	if assert.Len(t, test.mockSlackClient.PostMessageCalls(), 1) {
+		assert.Equal(t, "Generated by a script.", test.mockSlackClient.PostMessageCalls()[0].Text)
+	}
+
+	sentMessages, err := test.mockStore.ListSentMessages()
+	assert.NoError(t, err)
+	if assert.Len(t, sentMessages, 1) {
+		assert.Equal(t, "generated", sentMessages[0].SourceID)
+		assert.Equal(t, "stdin", sentMessages[0].CampaignName)
+	}
+}
@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/andrewhowdencom/ruf/internal/kv"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+// dispatcherStatusCmd represents the dispatcher status command
+var dispatcherStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show whether each watcher is alive and current.",
+	Long: `Show the status each watcher sharing the datastore last wrote to it: whether it leads or stands
+by, when it last refreshed its sources and processed the schedule, the counts of its last tick, and
+the errors of each. A watcher writes its status after each refresh and tick, so one that has not
+written it for longer than its refresh interval, or a minute while leading, is no longer running.
+
+The same statuses are served by a running 'ruf dispatcher watch' at /status/workers.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store, err := datastoreNewStore(true)
+		if err != nil {
+			return fmt.Errorf("failed to create a new datastore: %w", err)
+		}
+		defer store.Close()
+		return doDispatcherStatus(store, cmd.OutOrStdout(), time.Now())
+	},
+}
+
+func doDispatcherStatus(store kv.Storer, w io.Writer, now time.Time) error {
+	statuses, err := store.ListWorkerStatuses()
+	if err != nil {
+		return fmt.Errorf("failed to list worker statuses: %w", err)
+	}
+	if len(statuses) == 0 {
+		fmt.Fprintln(w, "No watchers have written their status.")
+		return nil
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].UpdatedAt.After(statuses[j].UpdatedAt) })
+
+	table := tablewriter.NewWriter(w)
+	table.Header("Holder", "Role", "Updated", "Last Refresh", "Last Tick", "Scheduled", "Due", "Processed", "Kept", "Errors")
+	for _, ws := range statuses {
+		role := "standby"
+		switch {
+		case !ws.StoppedAt.IsZero():
+			role = "stopped"
+		case ws.Leader:
+			role = "leader"
+		}
+		if ws.DryRun {
+			role += " (dry run)"
+		}
+
+		var problems []string
+		if ws.RefreshError != "" {
+			problems = append(problems, "refresh: "+ws.RefreshError)
+		}
+		if ws.TickError != "" {
+			problems = append(problems, "tick: "+ws.TickError)
+		}
+		if ws.Errors > 0 {
+			problems = append(problems, fmt.Sprintf("%d calls could not be processed", ws.Errors))
+		}
+
+		table.Append([]string{
+			ws.Holder,
+			role,
+			age(now, ws.UpdatedAt),
+			age(now, ws.LastRefresh),
+			age(now, ws.LastTick),
+			strconv.Itoa(ws.Scheduled),
+			strconv.Itoa(ws.Due),
+			strconv.Itoa(ws.Processed),
+			strconv.Itoa(ws.Kept),
+			strings.Join(problems, "\n"),
+		})
+	}
+	table.Render()
+	return nil
+}
+
+// age describes how long before now a time was, or "never" if it is zero.
+func age(now, t time.Time) string {
+	if t.IsZero() {
+		return "never"
+	}
+	return now.Sub(t).Round(time.Second).String() + " ago"
+}
+
+func init() {
+	dispatcherCmd.AddCommand(dispatcherStatusCmd)
+}
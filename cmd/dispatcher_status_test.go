@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/andrewhowdencom/ruf/internal/datastore"
+	"github.com/andrewhowdencom/ruf/internal/kv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDispatcherStatus(t *testing.T) {
+	now := time.Date(2025, 6, 2, 9, 0, 0, 0, time.UTC)
+	store := datastore.NewMockStore()
+
+	var out bytes.Buffer
+	require.NoError(t, doDispatcherStatus(store, &out, now))
+	assert.Contains(t, out.String(), "No watchers have written their status.")
+
+	require.NoError(t, store.SetWorkerStatus(&kv.WorkerStatus{
+		Holder:      "primary",
+		Leader:      true,
+		UpdatedAt:   now.Add(-30 * time.Second),
+		LastRefresh: now.Add(-10 * time.Minute),
+		LastTick:    now.Add(-30 * time.Second),
+		TickError:   "failed to list scheduled calls: unavailable",
+		Scheduled:   12,
+		Due:         3,
+		Processed:   2,
+		Kept:        1,
+	}))
+	require.NoError(t, store.SetWorkerStatus(&kv.WorkerStatus{Holder: "standby", UpdatedAt: now.Add(-time.Minute), LastRefresh: now.Add(-time.Minute)}))
+	require.NoError(t, store.SetWorkerStatus(&kv.WorkerStatus{Holder: "old", Leader: true, UpdatedAt: now.Add(-time.Hour), StoppedAt: now.Add(-time.Hour)}))
+
+	out.Reset()
+	require.NoError(t, doDispatcherStatus(store, &out, now))
+	output := out.String()
+	assert.Regexp(t, `primary\s+│\s+leader\s+│\s+30s ago\s+│\s+10m0s ago\s+│\s+30s ago\s+│\s+12\s+│\s+3\s+│\s+2\s+│\s+1`, output)
+	assert.Contains(t, output, "tick: failed to list scheduled calls: unavailable")
+	assert.Regexp(t, `standby\s+│\s+standby\s+│\s+1m0s ago\s+│\s+1m0s ago\s+│\s+never`, output)
+	assert.Regexp(t, `old\s+│\s+stopped`, output)
+}
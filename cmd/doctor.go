@@ -0,0 +1,244 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/andrewhowdencom/ruf/internal/clients/email"
+	"github.com/andrewhowdencom/ruf/internal/clients/slack"
+	"github.com/andrewhowdencom/ruf/internal/kv"
+	"github.com/andrewhowdencom/ruf/internal/migration"
+	"github.com/andrewhowdencom/ruf/internal/model"
+	"github.com/andrewhowdencom/ruf/internal/validator"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"gopkg.in/yaml.v3"
+)
+
+// doctorTimeout is how long each check that reaches out to a service has to complete.
+const doctorTimeout = 10 * time.Second
+
+// doctorCmd represents the doctor command
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check the configuration of a deployment end to end",
+	Long: `Check the configuration of a deployment end to end, against the services it uses.
+
+The config file is read and the clients, policies and calendars it configures are built. The
+datastore is opened read-only and its schema version compared with that of this ruf. The Slack
+token is checked for the scopes ruf needs, and the SMTP server is logged in to without sending
+anything. Each source of source.urls is fetched, parsed and validated.
+
+A table of the checks is written, and the command fails if any check fails. Checks of services that
+are not configured are skipped.
+
+Example:
+  ruf doctor --profile production`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return doDoctor(cmd.Context(), cmd.OutOrStdout(), datastoreNewStore)
+	},
+}
+
+// doctorCheck is the result of a check of the doctor. A check that is skipped was not run, as what it
+// checks is not configured.
+type doctorCheck struct {
+	Name    string
+	Detail  string
+	Err     error
+	Skipped bool
+}
+
+func doDoctor(ctx context.Context, w io.Writer, openStore func(readOnly bool) (kv.Storer, error), slackOpts ...slack.Option) error {
+	checks := []doctorCheck{doctorConfigFile(), doctorSettings(), doctorDatastore(openStore)}
+	checks = append(checks, doctorSlack(ctx, slackOpts...), doctorSMTP(ctx))
+	checks = append(checks, doctorSources()...)
+
+	failed := 0
+	table := tablewriter.NewWriter(w)
+	table.Header("Check", "Result", "Detail")
+	for _, c := range checks {
+		switch {
+		case c.Err != nil:
+			failed++
+			table.Append([]string{c.Name, "FAIL", c.Err.Error()})
+		case c.Skipped:
+			table.Append([]string{c.Name, "SKIP", c.Detail})
+		default:
+			table.Append([]string{c.Name, "PASS", c.Detail})
+		}
+	}
+	if err := table.Render(); err != nil {
+		return err
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d checks failed", failed, len(checks))
+	}
+	fmt.Fprintln(w, "Every check passed.")
+	return nil
+}
+
+// doctorConfigFile checks that a config file was found, and that it is valid YAML.
+func doctorConfigFile() doctorCheck {
+	c := doctorCheck{Name: "config file"}
+	path := viper.ConfigFileUsed()
+	if path == "" {
+		c.Err = fmt.Errorf("no config file was found; set one with --config")
+		return c
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		c.Err = fmt.Errorf("failed to read %s: %w", path, err)
+		return c
+	}
+	var config map[string]any
+	if err := yaml.Unmarshal(b, &config); err != nil {
+		c.Err = fmt.Errorf("failed to parse %s: %w", path, err)
+		return c
+	}
+	c.Detail = path
+	if profile := viper.GetString("profile"); profile != "" {
+		c.Detail += fmt.Sprintf(" (profile %s)", profile)
+	}
+	return c
+}
+
+// doctorSettings checks that the clients, policies, limits and calendars of the config can be built,
+// as the watcher builds them when it starts.
+func doctorSettings() doctorCheck {
+	c := doctorCheck{Name: "settings"}
+	if _, err := workerOptions(); err != nil {
+		c.Err = err
+		return c
+	}
+	if _, err := buildHolidayProviders(); err != nil {
+		c.Err = err
+		return c
+	}
+	if _, err := buildTimezones(); err != nil {
+		c.Err = err
+		return c
+	}
+	c.Detail = "built the clients, policies, limits and calendars"
+	return c
+}
+
+// doctorDatastore checks that the datastore can be opened, and that every migration has been applied to
+// it.
+func doctorDatastore(openStore func(readOnly bool) (kv.Storer, error)) doctorCheck {
+	c := doctorCheck{Name: "datastore"}
+	store, err := openStore(true)
+	if err != nil {
+		c.Err = fmt.Errorf("failed to open the %s datastore: %w", viper.GetString("datastore.type"), err)
+		return c
+	}
+	defer store.Close()
+
+	version, err := store.GetSchemaVersion()
+	if err != nil {
+		c.Err = fmt.Errorf("failed to get the schema version: %w", err)
+		return c
+	}
+	switch latest := migration.Latest(); {
+	case version < latest:
+		c.Err = fmt.Errorf("schema version %d is behind %d; run 'ruf migrate db'", version, latest)
+	case version > latest:
+		c.Err = fmt.Errorf("schema version %d is ahead of %d; update ruf", version, latest)
+	default:
+		c.Detail = fmt.Sprintf("%s at schema version %d", viper.GetString("datastore.type"), version)
+	}
+	return c
+}
+
+// doctorSlack checks that the Slack token is valid, and that it has been granted every scope ruf needs.
+func doctorSlack(ctx context.Context, opts ...slack.Option) doctorCheck {
+	c := doctorCheck{Name: "slack"}
+	token := viper.GetString("slack.app.token")
+	if token == "" {
+		c.Skipped, c.Detail = true, "slack.app.token is not set"
+		return c
+	}
+	ctx, cancel := context.WithTimeout(ctx, doctorTimeout)
+	defer cancel()
+
+	auth, err := slack.AuthTest(ctx, token, opts...)
+	if err != nil {
+		c.Err = fmt.Errorf("failed to authenticate: %w", err)
+		return c
+	}
+	if missing := auth.Missing(); len(missing) > 0 {
+		c.Err = fmt.Errorf("the token is missing the scopes %s", strings.Join(missing, ", "))
+		return c
+	}
+	c.Detail = fmt.Sprintf("authenticated as %s in %s", auth.User, auth.Team)
+	return c
+}
+
+// doctorSMTP checks that the SMTP server can be logged in to.
+func doctorSMTP(ctx context.Context) doctorCheck {
+	c := doctorCheck{Name: "email"}
+	host, port := viper.GetString("email.host"), viper.GetInt("email.port")
+	if host == "" {
+		c.Skipped, c.Detail = true, "email.host is not set"
+		return c
+	}
+	ctx, cancel := context.WithTimeout(ctx, doctorTimeout)
+	defer cancel()
+
+	username := viper.GetString("email.username")
+	if err := email.Login(ctx, host, port, username, viper.GetString("email.password")); err != nil {
+		c.Err = fmt.Errorf("failed to log in to %s:%d: %w", host, port, err)
+		return c
+	}
+	c.Detail = fmt.Sprintf("logged in to %s:%d as %s", host, port, username)
+	return c
+}
+
+// doctorSources checks that each source of source.urls can be fetched and parsed, and that its calls are
+// valid.
+func doctorSources() []doctorCheck {
+	urls := viper.GetStringSlice("source.urls")
+	if len(urls) == 0 {
+		return []doctorCheck{{Name: "sources", Skipped: true, Detail: "source.urls is not set"}}
+	}
+	s, err := buildSourcer()
+	if err != nil {
+		return []doctorCheck{{Name: "sources", Err: err}}
+	}
+
+	checks := make([]doctorCheck, 0, len(urls))
+	for _, url := range urls {
+		c := doctorCheck{Name: "source " + url}
+		source, _, err := s.Source(url)
+		switch {
+		case err != nil:
+			c.Err = err
+		case source == nil:
+			c.Detail = "no calls"
+		default:
+			calls := make([]*model.Call, len(source.Calls))
+			for i := range source.Calls {
+				calls[i] = &source.Calls[i]
+			}
+			errs := validator.Validate(calls, validator.WithTypes(destinationTypes()...))
+			switch {
+			case len(errs) == 1:
+				c.Err = errs[0]
+			case len(errs) > 1:
+				c.Err = fmt.Errorf("%w, and %d more problems", errs[0], len(errs)-1)
+			default:
+				c.Detail = fmt.Sprintf("valid, calls: %d", len(calls))
+			}
+		}
+		checks = append(checks, c)
+	}
+	return checks
+}
+
+func init() {
+	rootCmd.AddCommand(doctorCmd)
+}
@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/andrewhowdencom/ruf/internal/clients/slack"
+	"github.com/andrewhowdencom/ruf/internal/datastore"
+	"github.com/andrewhowdencom/ruf/internal/kv"
+	"github.com/andrewhowdencom/ruf/internal/migration"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDoDoctor(t *testing.T) {
+	t.Cleanup(viper.Reset)
+
+	dir := t.TempDir()
+	config := filepath.Join(dir, "config.yaml")
+	require.NoError(t, os.WriteFile(config, []byte("slack:\n  app:\n    token: token\n"), 0644))
+	valid := filepath.Join(dir, "valid.yaml")
+	require.NoError(t, os.WriteFile(valid, []byte(`
+campaign:
+  id: team
+  name: Team
+calls:
+  - id: standup
+    subject: Standup
+    content: "Time for standup!"
+    destinations:
+      - type: slack
+        to: ["#general"]
+    triggers:
+      - cron: "0 9 * * 1-5"
+`), 0644))
+	viper.SetConfigFile(config)
+	viper.Set("slack.app.token", "token")
+	viper.Set("source.urls", []string{"file://" + valid})
+
+	scopes := "channels:read,groups:read,chat:write,im:write,users:read.email"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-OAuth-Scopes", scopes)
+		fmt.Fprint(w, `{"ok": true, "team": "Example", "user": "ruf"}`)
+	}))
+	defer server.Close()
+
+	store := datastore.NewMockStore()
+	require.NoError(t, store.SetSchemaVersion(migration.Latest()))
+	openStore := func(bool) (kv.Storer, error) { return store, nil }
+
+	t.Run("passes a working deployment", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, doDoctor(context.Background(), &buf, openStore, slack.WithEndpoint(server.URL)))
+		out := buf.String()
+		assert.Contains(t, out, config)
+		assert.Contains(t, out, "authenticated as ruf in Example")
+		assert.Contains(t, out, "email.host is not set")
+		assert.Contains(t, out, "valid, calls: 1")
+		assert.Contains(t, out, "Every check passed.")
+	})
+
+	t.Run("fails each broken check", func(t *testing.T) {
+		scopes = "chat:write"
+		require.NoError(t, store.SetSchemaVersion(0))
+		viper.Set("source.urls", []string{"file://" + filepath.Join(dir, "missing.yaml")})
+
+		var buf bytes.Buffer
+		err := doDoctor(context.Background(), &buf, openStore, slack.WithEndpoint(server.URL))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "3 of 6 checks failed")
+		out := buf.String()
+		assert.Contains(t, out, "run 'ruf migrate db'")
+		assert.Contains(t, out, "channels:read")
+		assert.Contains(t, out, "missing.yaml")
+	})
+}
@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/andrewhowdencom/ruf/internal/limits"
+	"github.com/go-viper/mapstructure/v2"
+	"github.com/spf13/viper"
+)
+
+// limitConfig is the configuration of the limit of a destination.
+type limitConfig struct {
+	MaxPerDay  int                `mapstructure:"max_per_day"`
+	QuietHours []quietHoursConfig `mapstructure:"quiet_hours"`
+	Timezone   string             `mapstructure:"timezone"`
+}
+
+// quietHoursConfig is a window of the day in which no calls are sent.
+type quietHoursConfig struct {
+	Start string `mapstructure:"start"`
+	End   string `mapstructure:"end"`
+}
+
+// buildLimits reads the limits of destinations from limits.<type>.<destination>, where either may be
+// "default". It returns nil if there are none. Destinations are read from the raw map rather than as
+// keys, as they may contain dots.
+func buildLimits() (*limits.Limits, error) {
+	raw := viper.GetStringMap("limits")
+	if len(raw) == 0 {
+		return nil, nil
+	}
+
+	l := limits.New()
+	for destType, v := range raw {
+		if destType == limits.Default {
+			rule, err := decodeLimit(v)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse limits.%s: %w", destType, err)
+			}
+			l.Set(limits.Default, "", rule)
+			continue
+		}
+
+		var destinations map[string]interface{}
+		if err := mapstructure.Decode(v, &destinations); err != nil {
+			return nil, fmt.Errorf("failed to parse limits.%s: %w", destType, err)
+		}
+		for to, v := range destinations {
+			rule, err := decodeLimit(v)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse limits.%s.%s: %w", destType, to, err)
+			}
+			l.Set(destType, to, rule)
+		}
+	}
+	return l, nil
+}
+
+// decodeLimit decodes the configuration of a single limit into its rule.
+func decodeLimit(v interface{}) (limits.Rule, error) {
+	var c limitConfig
+	if err := mapstructure.WeakDecode(v, &c); err != nil {
+		return limits.Rule{}, err
+	}
+	if c.MaxPerDay < 0 {
+		return limits.Rule{}, fmt.Errorf("%w: max_per_day must not be negative", limits.ErrInvalidLimit)
+	}
+
+	rule := limits.Rule{MaxPerDay: c.MaxPerDay, Location: time.UTC}
+	if c.Timezone != "" {
+		loc, err := time.LoadLocation(c.Timezone)
+		if err != nil {
+			return limits.Rule{}, fmt.Errorf("%w: unknown timezone '%s': %w", limits.ErrInvalidLimit, c.Timezone, err)
+		}
+		rule.Location = loc
+	}
+	for _, q := range c.QuietHours {
+		w, err := limits.ParseWindow(q.Start, q.End)
+		if err != nil {
+			return limits.Rule{}, err
+		}
+		rule.QuietHours = append(rule.QuietHours, w)
+	}
+	return rule, nil
+}
+
+// rateConfig is the configuration of the rate of a destination type.
+type rateConfig struct {
+	PerSecond float64 `mapstructure:"per_second"`
+	Burst     int     `mapstructure:"burst"`
+}
+
+// buildRateLimits reads the rates of destination types from rate_limits.<type>, where the type may be
+// "default". The buckets are returned without rates when there are none, so that destination types
+// that rate limit the worker are still paused.
+func buildRateLimits() (*limits.Buckets, error) {
+	b := limits.NewBuckets()
+	for destType, v := range viper.GetStringMap("rate_limits") {
+		var c rateConfig
+		if err := mapstructure.WeakDecode(v, &c); err != nil {
+			return nil, fmt.Errorf("failed to parse rate_limits.%s: %w", destType, err)
+		}
+		if c.PerSecond < 0 || c.Burst < 0 {
+			return nil, fmt.Errorf("failed to parse rate_limits.%s: %w: per_second and burst must not be negative", destType, limits.ErrInvalidLimit)
+		}
+		b.Set(destType, limits.Rate{PerSecond: c.PerSecond, Burst: c.Burst})
+	}
+	return b, nil
+}
@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildLimits(t *testing.T) {
+	t.Cleanup(viper.Reset)
+	viper.SetConfigType("yaml")
+	require.NoError(t, viper.ReadConfig(strings.NewReader(`
+limits:
+  default:
+    quiet_hours:
+      - start: "18:00"
+        end: "09:00"
+  slack:
+    "#general":
+      max_per_day: 3
+      timezone: "Europe/Berlin"
+  email:
+    team@example.com:
+      max_per_day: 1
+`)))
+
+	l, err := buildLimits()
+	require.NoError(t, err)
+
+	rule, ok := l.For("slack", "#general")
+	assert.True(t, ok)
+	assert.Equal(t, 3, rule.MaxPerDay)
+	assert.Equal(t, "Europe/Berlin", rule.Location.String())
+
+	rule, ok = l.For("email", "team@example.com")
+	assert.True(t, ok)
+	assert.Equal(t, 1, rule.MaxPerDay)
+
+	rule, ok = l.For("slack", "#random")
+	assert.True(t, ok)
+	assert.Equal(t, 0, rule.MaxPerDay)
+	assert.Len(t, rule.QuietHours, 1)
+	assert.Equal(t, 18*time.Hour, rule.QuietHours[0].Start)
+
+	viper.Set("limits.slack.#general.max_per_day", -1)
+	_, err = buildLimits()
+	assert.Error(t, err)
+}
+
+func TestBuildRateLimits(t *testing.T) {
+	t.Cleanup(viper.Reset)
+	viper.SetConfigType("yaml")
+	require.NoError(t, viper.ReadConfig(strings.NewReader(`
+rate_limits:
+  slack:
+    per_second: 2
+    burst: 1
+`)))
+
+	b, err := buildRateLimits()
+	require.NoError(t, err)
+	now := time.Now()
+	assert.Equal(t, time.Duration(0), b.Reserve("slack", now))
+	assert.Equal(t, 500*time.Millisecond, b.Reserve("slack", now))
+	assert.Equal(t, time.Duration(0), b.Reserve("email", now))
+
+	viper.Set("rate_limits", map[string]interface{}{"slack": map[string]interface{}{"per_second": -1}})
+	_, err = buildRateLimits()
+	assert.Error(t, err)
+}
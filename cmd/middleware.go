@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/andrewhowdencom/ruf/internal/worker"
+	"github.com/go-viper/mapstructure/v2"
+	"github.com/spf13/viper"
+	"go.opentelemetry.io/otel"
+)
+
+// buildMiddleware reads the middleware sending is wrapped with from worker.middleware.<type>, where the
+// type may be "all", as lists of the names of built-in middleware in the order they wrap it.
+func buildMiddleware() ([]worker.Option, error) {
+	var opts []worker.Option
+	for destType, v := range viper.GetStringMap("worker.middleware") {
+		var names []string
+		if err := mapstructure.WeakDecode(v, &names); err != nil {
+			return nil, fmt.Errorf("failed to parse worker.middleware.%s: %w", destType, err)
+		}
+
+		var chain []worker.Middleware
+		for _, name := range names {
+			switch name {
+			case "log":
+				chain = append(chain, worker.Logging())
+			case "metrics":
+				mw, err := worker.Metrics(otel.Meter("github.com/andrewhowdencom/ruf"))
+				if err != nil {
+					return nil, fmt.Errorf("failed to create the metrics of worker.middleware.%s: %w", destType, err)
+				}
+				chain = append(chain, mw)
+			default:
+				return nil, fmt.Errorf("unknown middleware '%s' in worker.middleware.%s: must be log or metrics", name, destType)
+			}
+		}
+		opts = append(opts, worker.WithMiddleware(destType, chain...))
+	}
+	return opts, nil
+}
@@ -0,0 +1,29 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildMiddleware(t *testing.T) {
+	t.Cleanup(viper.Reset)
+	viper.SetConfigType("yaml")
+	require.NoError(t, viper.ReadConfig(strings.NewReader(`
+worker:
+  middleware:
+    all: [metrics]
+    email: [log]
+`)))
+
+	opts, err := buildMiddleware()
+	require.NoError(t, err)
+	assert.Len(t, opts, 2)
+
+	viper.Set("worker.middleware.slack", []string{"dedup"})
+	_, err = buildMiddleware()
+	assert.ErrorContains(t, err, "unknown middleware 'dedup' in worker.middleware.slack")
+}
@@ -9,9 +9,10 @@ import (
 )
 
 var migrateDbCmd = &cobra.Command{
-	Use:   "db",
-	Short: "Apply all pending database migrations.",
-	Long:  `Apply all pending database migrations.`,
+	Use:         "db",
+	Short:       "Apply all pending database migrations.",
+	Long:        `Apply all pending database migrations.`,
+	Annotations: mutating,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		store, err := datastore.NewStore(false)
 		if err != nil {
@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/andrewhowdencom/ruf/internal/model"
+	rufschema "github.com/andrewhowdencom/ruf/schema"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// sourceFile is the structure of a v1 (and v2) source file.
+type sourceFile struct {
+	APIVersion string         `json:"apiVersion,omitempty" yaml:"apiVersion,omitempty"`
+	Campaign   model.Campaign `json:"campaign" yaml:"campaign"`
+	Calls      []model.Call   `json:"calls" yaml:"calls"`
+	Events     []model.Event  `json:"events" yaml:"events"`
+}
+
+var migrateV2Cmd = &cobra.Command{
+	Use:   "v2 [file]",
+	Short: "Migrate a YAML file from the v1 format to the v2 format.",
+	Long: `Migrate a YAML file from the v1 format to the v2 format.
+
+Calls that differ only in their destinations and triggers are merged into a single call, with
+each trigger carrying the destinations of the call it came from. Triggers moved from another call
+also carry its ID as their call_id, so that the calls they schedule keep their IDs, and what was
+already sent for them is not sent again.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		data, err := os.ReadFile(args[0])
+		if err != nil {
+			return fmt.Errorf("failed to read file: %w", err)
+		}
+
+		var source sourceFile
+		if err := yaml.Unmarshal(data, &source); err != nil {
+			return fmt.Errorf("failed to unmarshal YAML: %w", err)
+		}
+
+		calls, err := mergeCallsByContent(source.Calls)
+		if err != nil {
+			return err
+		}
+		source.Calls = calls
+		source.APIVersion = rufschema.V2
+
+		newData, err := yaml.Marshal(source)
+		if err != nil {
+			return fmt.Errorf("failed to marshal new YAML: %w", err)
+		}
+
+		fmt.Fprint(cmd.OutOrStdout(), string(newData))
+		return nil
+	},
+}
+
+// mergeCallsByContent merges calls with identical content into a single call, moving the
+// destinations of each onto its triggers, and the ID of each but the first into the call ID of its
+// triggers. Calls without a duplicate are returned unchanged.
+func mergeCallsByContent(calls []model.Call) ([]model.Call, error) {
+	var keys []string
+	groups := make(map[string][]model.Call)
+	for _, call := range calls {
+		key, err := json.Marshal(struct {
+			Author, Subject, Content string
+			Campaign                 model.Campaign
+			Data                     map[string]interface{}
+		}{call.Author, call.Subject, call.Content, call.Campaign, call.Data})
+		if err != nil {
+			return nil, fmt.Errorf("failed to compare call '%s': %w", call.ID, err)
+		}
+		if _, ok := groups[string(key)]; !ok {
+			keys = append(keys, string(key))
+		}
+		groups[string(key)] = append(groups[string(key)], call)
+	}
+
+	merged := make([]model.Call, 0, len(keys))
+	for _, key := range keys {
+		group := groups[key]
+		if len(group) == 1 {
+			merged = append(merged, group[0])
+			continue
+		}
+
+		call := group[0]
+		call.Destinations = nil
+		call.Triggers = nil
+		for i, c := range group {
+			for _, trigger := range c.Triggers {
+				if len(trigger.Destinations) == 0 {
+					trigger.Destinations = c.Destinations
+				}
+				// The calls of a trigger keep the ID of the call it came from, so that the messages
+				// already sent for them are not sent again.
+				if i > 0 && trigger.CallID == "" {
+					trigger.CallID = c.ID
+				}
+				call.Triggers = append(call.Triggers, trigger)
+			}
+		}
+		merged = append(merged, call)
+	}
+	return merged, nil
+}
+
+func init() {
+	migrateSourceCmd.AddCommand(migrateV2Cmd)
+}
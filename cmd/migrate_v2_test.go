@@ -0,0 +1,127 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/andrewhowdencom/ruf/internal/datastore"
+	"github.com/andrewhowdencom/ruf/internal/kv"
+	"github.com/andrewhowdencom/ruf/internal/model"
+	"github.com/andrewhowdencom/ruf/internal/scheduler"
+	"github.com/andrewhowdencom/ruf/internal/sourcer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestMigrateV2Cmd(t *testing.T) {
+	v1YAML := `
+campaign:
+  id: "my-campaign"
+  name: "My Campaign"
+calls:
+  - id: "standup-slack"
+    subject: "Standup"
+    content: "Standup is starting."
+    destinations:
+      - type: "slack"
+        to: ["#team"]
+    triggers:
+      - cron: "0 9 * * 1-5"
+  - id: "standup-email"
+    subject: "Standup"
+    content: "Standup is starting."
+    destinations:
+      - type: "email"
+        to: ["team@example.com"]
+    triggers:
+      - scheduled_at: "2025-01-01T12:00:00Z"
+  - id: "other"
+    subject: "Other"
+    content: "Something else."
+    destinations:
+      - type: "slack"
+        to: ["#team"]
+    triggers:
+      - cron: "0 10 * * *"
+`
+	file := filepath.Join(t.TempDir(), "v1.yaml")
+	assert.NoError(t, os.WriteFile(file, []byte(v1YAML), 0644))
+
+	var stdout bytes.Buffer
+	rootCmd.SetOut(&stdout)
+	rootCmd.SetArgs([]string{"migrate", "source", "v2", file})
+	assert.NoError(t, rootCmd.Execute())
+
+	var migrated sourceFile
+	assert.NoError(t, yaml.Unmarshal(stdout.Bytes(), &migrated))
+	assert.Equal(t, "v2", migrated.APIVersion)
+	assert.Len(t, migrated.Calls, 2)
+
+	merged := migrated.Calls[0]
+	assert.Equal(t, "standup-slack", merged.ID)
+	assert.Empty(t, merged.Destinations)
+	assert.Len(t, merged.Triggers, 2)
+	assert.Equal(t, []model.Destination{{Type: "slack", To: []string{"#team"}}}, merged.Triggers[0].Destinations)
+	assert.Equal(t, []model.Destination{{Type: "email", To: []string{"team@example.com"}}}, merged.Triggers[1].Destinations)
+	assert.Empty(t, merged.Triggers[0].CallID)
+	assert.Equal(t, "standup-email", merged.Triggers[1].CallID, "triggers moved from another call keep its ID")
+
+	assert.Equal(t, "other", migrated.Calls[1].ID)
+	assert.Len(t, migrated.Calls[1].Destinations, 1)
+}
+
+func TestMergeCallsByContentKeepsSent(t *testing.T) {
+	campaign := model.Campaign{ID: "my-campaign", Name: "My Campaign"}
+	calls := []model.Call{
+		{
+			ID: "standup-slack", Subject: "Standup", Content: "Standup is starting.", Campaign: campaign,
+			Destinations: []model.Destination{{Type: "slack", To: []string{"#team"}}},
+			Triggers:     []model.Trigger{{Cron: "0 9 * * 1-5"}},
+		},
+		{
+			ID: "standup-email", Subject: "Standup", Content: "Standup is starting.", Campaign: campaign,
+			Destinations: []model.Destination{{Type: "email", To: []string{"team@example.com"}}},
+			Triggers:     []model.Trigger{{Cron: "0 9 * * 1-5"}},
+		},
+	}
+	merged, err := mergeCallsByContent(calls)
+	require.NoError(t, err)
+	require.Len(t, merged, 1)
+
+	store := datastore.NewMockStore()
+	sched := scheduler.New(store)
+	now := time.Date(2025, 6, 4, 12, 0, 0, 0, time.UTC) // A Wednesday
+	expand := func(calls []model.Call) []*model.Call {
+		return sched.Expand([]*sourcer.Source{{Campaign: campaign, Calls: calls}}, now, 72*time.Hour, 0)
+	}
+
+	// Every occurrence sent before the migration is recorded against the calls it was sent for.
+	before := expand(calls)
+	require.NotEmpty(t, before)
+	for _, call := range before {
+		dest := call.Destinations[0]
+		require.NoError(t, store.AddSentMessage(campaign.ID, call.ID, &kv.SentMessage{
+			ID:          kv.GenerateID(campaign.ID, call.ID, call.OccurredAt(), dest.Type, dest.To[0]),
+			SourceID:    call.ID,
+			ScheduledAt: call.ScheduledAt,
+			OccurredAt:  call.OccurredAt(),
+			Type:        dest.Type,
+			Destination: dest.To[0],
+			Status:      kv.StatusSent,
+			CampaignID:  campaign.ID,
+		}))
+	}
+
+	after := expand(merged)
+	assert.Len(t, after, len(before))
+	for _, call := range after {
+		dest := call.Destinations[0]
+		sent, err := store.HasBeenSent(campaign.ID, call.ID, call.OccurredAt(), dest.Type, dest.To[0])
+		require.NoError(t, err)
+		assert.True(t, sent, "%s is not sent again once the calls are merged", call.ID)
+	}
+}
@@ -0,0 +1,16 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// newCmd represents the new command
+var newCmd = &cobra.Command{
+	Use:   "new",
+	Short: "Generate new definitions, such as calls.",
+	Long:  `Generate new definitions for source files, such as calls, that are valid from the start.`,
+}
+
+func init() {
+	rootCmd.AddCommand(newCmd)
+}
@@ -0,0 +1,441 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/andrewhowdencom/ruf/internal/model"
+	"github.com/andrewhowdencom/ruf/internal/sourcer"
+	"github.com/andrewhowdencom/ruf/internal/validator"
+	rufschema "github.com/andrewhowdencom/ruf/schema"
+	"github.com/gorhill/cronexpr"
+	"github.com/spf13/cobra"
+	"github.com/teambition/rrule-go"
+	"gopkg.in/yaml.v3"
+)
+
+// The types of trigger a new call can be generated with.
+const (
+	triggerCron        = "cron"
+	triggerRRule       = "rrule"
+	triggerScheduledAt = "scheduled_at"
+)
+
+// newCallOptions are the values of a new call given as flags. Those that are empty are prompted for.
+type newCallOptions struct {
+	ID          string
+	Subject     string
+	Content     string
+	Destination string
+	To          []string
+	Trigger     string
+	Cron        string
+	RRule       string
+	DStart      string
+	At          string
+	Timezone    string
+	// File is the source file the call is appended to. The call is printed if it is empty.
+	File string
+	// NoInput makes a value that is missing an error rather than a prompt.
+	NoInput bool
+}
+
+var newCallOpts newCallOptions
+
+// newCallCmd represents the new call command
+var newCallCmd = &cobra.Command{
+	Use:   "call",
+	Short: "Generate a new call.",
+	Long: `Generate a new call, and append it to the calls of a source file with --file, or print it to paste
+into one.
+
+Each value that is not given as a flag is prompted for. The trigger is checked as it is entered, so a
+cron expression or rrule that cannot be read is asked for again, and the call is checked against the
+schema of source files before it is written.
+
+Example:
+  ruf new call
+  ruf new call --id standup --subject Standup --content "Time for standup!" \
+    --to '#team' --cron "0 9 * * 1-5" --timezone Europe/Berlin --file calls.yaml --no-input`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return doNewCall(cmd.InOrStdin(), cmd.ErrOrStderr(), cmd.OutOrStdout(), newCallOpts)
+	},
+}
+
+func doNewCall(in io.Reader, prompts, w io.Writer, opts newCallOptions) error {
+	p := &prompter{in: bufio.NewReader(in), out: prompts, disabled: opts.NoInput}
+
+	call := model.Call{ID: opts.ID, Subject: opts.Subject, Content: opts.Content}
+	var err error
+	if call.ID, err = p.ask("ID", call.ID, ""); err != nil {
+		return err
+	}
+	if call.Subject, err = p.ask("Subject", call.Subject, ""); err != nil {
+		return err
+	}
+	if call.Content, err = p.ask("Content", call.Content, ""); err != nil {
+		return err
+	}
+	dest := model.Destination{Type: opts.Destination, To: opts.To}
+	if dest.Type, err = p.ask("Destination type", dest.Type, "slack"); err != nil {
+		return err
+	}
+	if len(dest.To) == 0 {
+		to, err := p.ask("Recipients, separated by commas", "", "")
+		if err != nil {
+			return err
+		}
+		for _, r := range strings.Split(to, ",") {
+			if r = strings.TrimSpace(r); r != "" {
+				dest.To = append(dest.To, r)
+			}
+		}
+	}
+	call.Destinations = []model.Destination{dest}
+
+	for {
+		trigger, err := newTrigger(p, opts)
+		if err == nil {
+			err = checkTrigger(trigger)
+		}
+		if err == nil {
+			call.Triggers = []model.Trigger{trigger}
+			break
+		}
+		if p.disabled || errors.Is(err, io.EOF) {
+			return err
+		}
+		// The trigger is asked for again, from its type.
+		fmt.Fprintf(prompts, "%s\n", err)
+		opts.Trigger, opts.Cron, opts.RRule, opts.DStart, opts.At, opts.Timezone = "", "", "", "", "", ""
+	}
+
+	if opts.File != "" {
+		if err := appendCall(opts.File, call); err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "Added call '%s' to %s\n", call.ID, opts.File)
+		return nil
+	}
+
+	if err := checkCall(call); err != nil {
+		return err
+	}
+	stanza, err := encodeYAML([]model.Call{call})
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(stanza)
+	return err
+}
+
+// newTrigger builds the trigger of a new call from its flags, prompting for those that are missing.
+// Each value is checked as it is given, and asked for again if it cannot be read.
+func newTrigger(p *prompter, opts newCallOptions) (model.Trigger, error) {
+	var trigger model.Trigger
+	kind := opts.Trigger
+	switch {
+	case kind != "":
+	case opts.Cron != "":
+		kind = triggerCron
+	case opts.RRule != "":
+		kind = triggerRRule
+	case opts.At != "":
+		kind = triggerScheduledAt
+	}
+	kind, err := p.askValid(fmt.Sprintf("Trigger type (%s, %s or %s)", triggerCron, triggerRRule, triggerScheduledAt), kind, triggerCron, func(kind string) error {
+		switch kind {
+		case triggerCron, triggerRRule, triggerScheduledAt:
+			return nil
+		}
+		return fmt.Errorf("invalid trigger type '%s': must be %s, %s or %s", kind, triggerCron, triggerRRule, triggerScheduledAt)
+	})
+	if err != nil {
+		return trigger, err
+	}
+
+	switch kind {
+	case triggerCron:
+		trigger.Cron, err = p.askValid("Cron expression, such as '0 9 * * 1-5'", opts.Cron, "", func(cron string) error {
+			if _, err := cronexpr.Parse(cron); err != nil {
+				return fmt.Errorf("invalid cron expression '%s': %w", cron, err)
+			}
+			return nil
+		})
+	case triggerRRule:
+		trigger.RRule, err = p.askValid("RRule, such as 'FREQ=WEEKLY;BYDAY=MO'", opts.RRule, "", func(rule string) error {
+			if _, err := rrule.StrToRRule(rule); err != nil {
+				return fmt.Errorf("invalid rrule '%s': %w", rule, err)
+			}
+			return nil
+		})
+		if err == nil {
+			trigger.DStart, err = p.ask("Start, such as 'TZID=Europe/Berlin:20250106T090000'", opts.DStart, "")
+		}
+	case triggerScheduledAt:
+		var at string
+		at, err = p.askValid("Time, such as '2025-06-02T09:00:00Z'", opts.At, "", func(at string) error {
+			if _, err := time.Parse(time.RFC3339, at); err != nil {
+				return fmt.Errorf("invalid time '%s': must be an RFC 3339 time", at)
+			}
+			return nil
+		})
+		if err == nil {
+			trigger.ScheduledAt, _ = time.Parse(time.RFC3339, at)
+		}
+		return trigger, err
+	}
+	if err != nil {
+		return trigger, err
+	}
+
+	trigger.Timezone = opts.Timezone
+	for {
+		if trigger.Timezone, err = p.optional("Timezone, such as 'Europe/Berlin' (UTC if empty)", trigger.Timezone); err != nil {
+			return trigger, err
+		}
+		_, err := time.LoadLocation(trigger.Timezone)
+		if err == nil {
+			return trigger, nil
+		}
+		if p.disabled {
+			return trigger, fmt.Errorf("invalid timezone '%s': %w", trigger.Timezone, err)
+		}
+		fmt.Fprintf(p.out, "invalid timezone '%s': %s\n", trigger.Timezone, err)
+		trigger.Timezone = ""
+	}
+}
+
+// checkTrigger checks a trigger as part of a call that is otherwise valid, so that any error is the
+// trigger's.
+func checkTrigger(trigger model.Trigger) error {
+	call := model.Call{
+		ID:           "new",
+		Subject:      "New",
+		Content:      "New",
+		Destinations: []model.Destination{{Type: "slack", To: []string{"#new"}}},
+		Triggers:     []model.Trigger{trigger},
+	}
+	return checkCall(call)
+}
+
+// checkCall checks a call as the single call of a source file.
+func checkCall(call model.Call) error {
+	data, err := encodeYAML(&sourcer.Source{
+		APIVersion: rufschema.Latest,
+		Campaign:   model.Campaign{ID: "new", Name: "New"},
+		Calls:      []model.Call{call},
+	})
+	if err != nil {
+		return err
+	}
+	return checkSourceYAML(data)
+}
+
+// checkSourceYAML checks a source file the way it is checked when it is read, and by
+// 'ruf debug validate'.
+func checkSourceYAML(data []byte) error {
+	parser, err := sourcer.NewYAMLParser()
+	if err != nil {
+		return fmt.Errorf("failed to create parser: %w", err)
+	}
+	source, err := parser.Parse("file:///new.yaml", data)
+	if err != nil {
+		return fmt.Errorf("the call is not valid: %w", err)
+	}
+	if source == nil {
+		return fmt.Errorf("the call is not valid: the source is empty")
+	}
+	calls := make([]*model.Call, len(source.Calls))
+	for i := range source.Calls {
+		calls[i] = &source.Calls[i]
+	}
+	if errs := validator.Validate(calls, validator.WithTypes(destinationTypes()...)); len(errs) > 0 {
+		return fmt.Errorf("the call is not valid: %w", errors.Join(errs...))
+	}
+	return nil
+}
+
+// appendCall appends the call to the calls of the last document of a source file that has any, or of
+// its first document if none do, and writes the file back if it is still valid.
+func appendCall(path string, call model.Call) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read '%s': %w", path, err)
+	}
+	var docs []*yaml.Node
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	for {
+		var doc yaml.Node
+		if err := dec.Decode(&doc); errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return fmt.Errorf("failed to parse '%s': %w", path, err)
+		}
+		docs = append(docs, &doc)
+	}
+	if len(docs) == 0 || len(docs[0].Content) == 0 || docs[0].Content[0].Kind != yaml.MappingNode {
+		return fmt.Errorf("'%s' is not a source file: it must have a campaign and calls", path)
+	}
+
+	var calls *yaml.Node
+	for _, doc := range docs {
+		if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+			continue
+		}
+		root := doc.Content[0]
+		for i := 0; i+1 < len(root.Content); i += 2 {
+			if root.Content[i].Value != "calls" || root.Content[i+1].Kind != yaml.SequenceNode {
+				continue
+			}
+			calls = root.Content[i+1]
+			for _, existing := range calls.Content {
+				var c model.Call
+				if err := existing.Decode(&c); err == nil && c.ID == call.ID {
+					return fmt.Errorf("'%s' already has a call with ID '%s'", path, call.ID)
+				}
+			}
+		}
+	}
+	if calls == nil {
+		root := docs[0].Content[0]
+		calls = &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		root.Content = append(root.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: "calls"}, calls)
+	}
+
+	var node yaml.Node
+	if err := node.Encode(call); err != nil {
+		return fmt.Errorf("failed to encode call: %w", err)
+	}
+	pruneEmpty(&node)
+	calls.Content = append(calls.Content, &node)
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	for _, doc := range docs {
+		if err := enc.Encode(doc); err != nil {
+			return fmt.Errorf("failed to encode '%s': %w", path, err)
+		}
+	}
+	if err := enc.Close(); err != nil {
+		return fmt.Errorf("failed to encode '%s': %w", path, err)
+	}
+	if err := checkSourceYAML(buf.Bytes()); err != nil {
+		return err
+	}
+	return os.WriteFile(path, buf.Bytes(), 0644)
+}
+
+// encodeYAML encodes a value as YAML, leaving out empty collections and null values.
+func encodeYAML(v any) ([]byte, error) {
+	var node yaml.Node
+	if err := node.Encode(v); err != nil {
+		return nil, fmt.Errorf("failed to encode: %w", err)
+	}
+	pruneEmpty(&node)
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&node); err != nil {
+		return nil, fmt.Errorf("failed to encode: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return nil, fmt.Errorf("failed to encode: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// prompter asks for values that were not given as flags.
+type prompter struct {
+	in  *bufio.Reader
+	out io.Writer
+	// disabled makes a value that is missing an error rather than a prompt.
+	disabled bool
+}
+
+// ask returns the value if it is set, or asks for it, offering the default if there is one. A value
+// is required, so it is asked for again until one is given.
+func (p *prompter) ask(label, value, def string) (string, error) {
+	for value == "" {
+		if p.disabled {
+			if def != "" {
+				return def, nil
+			}
+			return "", fmt.Errorf("%s is required", strings.ToLower(label))
+		}
+		prompt := label
+		if def != "" {
+			prompt += fmt.Sprintf(" [%s]", def)
+		}
+		line, err := p.line(prompt)
+		if err != nil {
+			return "", err
+		}
+		if value = line; value == "" {
+			value = def
+		}
+	}
+	return value, nil
+}
+
+// askValid asks for a value like ask, and asks for it again until it passes the check.
+func (p *prompter) askValid(label, value, def string, check func(string) error) (string, error) {
+	for {
+		var err error
+		if value, err = p.ask(label, value, def); err != nil {
+			return "", err
+		}
+		err = check(value)
+		if err == nil {
+			return value, nil
+		}
+		if p.disabled {
+			return "", err
+		}
+		fmt.Fprintf(p.out, "%s\n", err)
+		value = ""
+	}
+}
+
+// optional returns the value if it is set, or asks for it, allowing it to be left empty.
+func (p *prompter) optional(label, value string) (string, error) {
+	if value != "" || p.disabled {
+		return value, nil
+	}
+	return p.line(label)
+}
+
+// line prompts for and reads a single line.
+func (p *prompter) line(prompt string) (string, error) {
+	fmt.Fprintf(p.out, "%s: ", prompt)
+	line, err := p.in.ReadString('\n')
+	if err != nil && (!errors.Is(err, io.EOF) || line == "") {
+		return "", fmt.Errorf("failed to read %s: %w", strings.ToLower(prompt), err)
+	}
+	return strings.TrimSpace(line), nil
+}
+
+func init() {
+	newCmd.AddCommand(newCallCmd)
+	f := newCallCmd.Flags()
+	f.StringVar(&newCallOpts.ID, "id", "", "The ID of the call.")
+	f.StringVar(&newCallOpts.Subject, "subject", "", "The subject of the call.")
+	f.StringVar(&newCallOpts.Content, "content", "", "The content of the call, in Markdown.")
+	f.StringVar(&newCallOpts.Destination, "destination", "", "The type of destination to send the call to, such as slack or email.")
+	f.StringSliceVar(&newCallOpts.To, "to", nil, "The recipients of the call, such as '#general'.")
+	f.StringVar(&newCallOpts.Trigger, "trigger", "", "The type of trigger: cron, rrule or scheduled_at.")
+	f.StringVar(&newCallOpts.Cron, "cron", "", "The cron expression of the trigger.")
+	f.StringVar(&newCallOpts.RRule, "rrule", "", "The rrule of the trigger.")
+	f.StringVar(&newCallOpts.DStart, "dstart", "", "The start of the rrule of the trigger, such as 'TZID=Europe/Berlin:20250106T090000'.")
+	f.StringVar(&newCallOpts.At, "at", "", "The RFC 3339 time of a scheduled_at trigger.")
+	f.StringVar(&newCallOpts.Timezone, "timezone", "", "The timezone the trigger is evaluated in.")
+	f.StringVar(&newCallOpts.File, "file", "", "The source file to append the call to, instead of printing it.")
+	f.BoolVar(&newCallOpts.NoInput, "no-input", false, "Fail on missing values instead of prompting for them.")
+}
@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDoNewCall(t *testing.T) {
+	opts := newCallOptions{ID: "standup", Subject: "Standup", Content: "Time for standup!", To: []string{"#team"}, Cron: "0 9 * * 1-5", Timezone: "Europe/Berlin", NoInput: true}
+	var out, prompts bytes.Buffer
+	require.NoError(t, doNewCall(strings.NewReader(""), &prompts, &out, opts))
+	assert.Equal(t, `- id: standup
+  subject: Standup
+  content: Time for standup!
+  destinations:
+    - type: slack
+      to:
+        - '#team'
+  triggers:
+    - cron: 0 9 * * 1-5
+      timezone: Europe/Berlin
+`, out.String())
+	assert.Empty(t, prompts.String())
+
+	opts.Cron = "0 9 * *"
+	assert.ErrorContains(t, doNewCall(strings.NewReader(""), &prompts, &out, opts), "invalid cron expression")
+
+	opts = newCallOptions{ID: "standup", NoInput: true}
+	assert.ErrorContains(t, doNewCall(strings.NewReader(""), &prompts, &out, opts), "subject is required")
+}
+
+func TestDoNewCall_Interactive(t *testing.T) {
+	input := strings.Join([]string{
+		"retro", "Retro", "Retro in an hour", "", "#team, #leads",
+		"weekly", "rrule", "FREQ=SOMETIMES", "FREQ=WEEKLY;BYDAY=TU", "TZID=Europe/Berlin:20250107T140000", "",
+	}, "\n") + "\n"
+	var out, prompts bytes.Buffer
+	require.NoError(t, doNewCall(strings.NewReader(input), &prompts, &out, newCallOptions{}))
+	assert.Contains(t, prompts.String(), "invalid trigger type 'weekly'")
+	assert.Contains(t, prompts.String(), "invalid rrule 'FREQ=SOMETIMES'")
+	assert.Contains(t, out.String(), "- rrule: FREQ=WEEKLY;BYDAY=TU\n      dstart: TZID=Europe/Berlin:20250107T140000\n")
+	assert.Contains(t, out.String(), "- '#leads'")
+}
+
+func TestDoNewCall_File(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "calls.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`campaign:
+  id: team
+  name: Team
+calls:
+  # The daily standup.
+  - id: standup
+    subject: Standup
+    content: "Time for standup!"
+    destinations:
+      - type: slack
+        to: ["#team"]
+    triggers:
+      - cron: "0 9 * * 1-5"
+`), 0644))
+	opts := newCallOptions{ID: "retro", Subject: "Retro", Content: "Retro in an hour", To: []string{"#team"}, At: "2025-06-03T14:00:00Z", File: path, NoInput: true}
+
+	var out, prompts bytes.Buffer
+	require.NoError(t, doNewCall(strings.NewReader(""), &prompts, &out, opts))
+	assert.Equal(t, "Added call 'retro' to "+path+"\n", out.String())
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "# The daily standup.\n  - id: standup\n")
+	assert.Contains(t, string(data), "  - id: retro\n")
+	assert.Contains(t, string(data), "      - scheduled_at: 2025-06-03T14:00:00Z\n")
+
+	assert.ErrorContains(t, doNewCall(strings.NewReader(""), &prompts, &out, opts), "already has a call with ID 'retro'")
+}
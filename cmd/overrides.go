@@ -0,0 +1,31 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/viper"
+	"gopkg.in/yaml.v3"
+)
+
+// overrides are the settings given with --set, as key=value.
+var overrides []string
+
+// applyOverrides sets each key=value over every other source of config, for this invocation only. The
+// value is read as YAML, so that numbers, booleans, lists and maps keep their type, and is otherwise
+// kept as it is given.
+func applyOverrides(sets []string) error {
+	for _, set := range sets {
+		key, raw, ok := strings.Cut(set, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return fmt.Errorf("invalid --set '%s': must be key=value", set)
+		}
+		var value interface{} = raw
+		if err := yaml.Unmarshal([]byte(raw), &value); err != nil || value == nil {
+			value = raw
+		}
+		viper.Set(key, value)
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyOverrides(t *testing.T) {
+	t.Cleanup(viper.Reset)
+	viper.SetDefault("worker.calculation.after", "168h")
+	require.NoError(t, viper.MergeConfigMap(map[string]interface{}{
+		"datastore": map[string]interface{}{"type": "bbolt", "path": "ruf.db"},
+		"profiles": map[string]interface{}{
+			"staging": map[string]interface{}{"datastore": map[string]interface{}{"path": "staging.db"}},
+		},
+	}))
+
+	require.NoError(t, applyOverrides([]string{
+		"worker.calculation.after=72h",
+		"datastore.path=/tmp/test.db",
+		"email.port=2525",
+		"worker.notify_failures=false",
+		"source.urls=[file://a.yaml, file://b.yaml]",
+		"slack.app.token=",
+		"email.from=Ruf <ruf@example.com>",
+	}))
+	assert.Equal(t, 72*time.Hour, viper.GetDuration("worker.calculation.after"))
+	assert.Equal(t, 2525, viper.GetInt("email.port"))
+	assert.False(t, viper.GetBool("worker.notify_failures"))
+	assert.Equal(t, []string{"file://a.yaml", "file://b.yaml"}, viper.GetStringSlice("source.urls"))
+	assert.True(t, viper.IsSet("slack.app.token"))
+	assert.Equal(t, "", viper.GetString("slack.app.token"))
+	assert.Equal(t, "Ruf <ruf@example.com>", viper.GetString("email.from"))
+
+	// Overrides take precedence over the settings of a profile.
+	require.NoError(t, applyProfile("staging"))
+	assert.Equal(t, "/tmp/test.db", viper.GetString("datastore.path"))
+	assert.Equal(t, "bbolt", viper.GetString("datastore.type"))
+
+	assert.EqualError(t, applyOverrides([]string{"datastore.path"}), "invalid --set 'datastore.path': must be key=value")
+	assert.EqualError(t, applyOverrides([]string{"=value"}), "invalid --set '=value': must be key=value")
+}
@@ -0,0 +1,28 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/andrewhowdencom/ruf/internal/processor"
+	"github.com/andrewhowdencom/ruf/internal/worker"
+	"github.com/go-viper/mapstructure/v2"
+	"github.com/spf13/viper"
+)
+
+// buildProcessors reads the processors the content of calls is converted with from
+// worker.processors.<type>, as lists of the names of processors in the order they run.
+func buildProcessors() ([]worker.Option, error) {
+	var opts []worker.Option
+	for destType, v := range viper.GetStringMap("worker.processors") {
+		var names []string
+		if err := mapstructure.WeakDecode(v, &names); err != nil {
+			return nil, fmt.Errorf("failed to parse worker.processors.%s: %w", destType, err)
+		}
+		stack, err := processor.Stack(names...)
+		if err != nil {
+			return nil, fmt.Errorf("invalid worker.processors.%s: %w", destType, err)
+		}
+		opts = append(opts, worker.WithContentProcessors(destType, stack))
+	}
+	return opts, nil
+}
@@ -0,0 +1,30 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/andrewhowdencom/ruf/internal/processor"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildProcessors(t *testing.T) {
+	t.Cleanup(viper.Reset)
+	viper.SetConfigType("yaml")
+	require.NoError(t, viper.ReadConfig(strings.NewReader(`
+worker:
+  processors:
+    email: []
+    status-page: [markdown_to_html, accessibility]
+`)))
+
+	opts, err := buildProcessors()
+	require.NoError(t, err)
+	assert.Len(t, opts, 2)
+
+	viper.Set("worker.processors.email", []string{"markdown_to_pdf"})
+	_, err = buildProcessors()
+	assert.ErrorIs(t, err, processor.ErrUnknownProcessor)
+}
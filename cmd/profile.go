@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// annotationMutating marks the commands that change the datastore or what has been sent, so that
+// profiles that ask for it refuse to run them without --confirm-production.
+const annotationMutating = "ruf/mutating"
+
+// mutating is the annotation of a command that changes the datastore or what has been sent.
+var mutating = map[string]string{annotationMutating: "true"}
+
+var confirmProduction bool
+
+// applyProfile merges the settings of the named profile, under profiles.<name>, over the config. Flags
+// and environment variables still take precedence over them.
+func applyProfile(name string) error {
+	if !viper.IsSet("profiles." + name) {
+		return fmt.Errorf("profile '%s' is not configured", name)
+	}
+	return viper.MergeConfigMap(viper.GetStringMap("profiles." + name))
+}
+
+// guardProduction refuses to run a mutating command under a profile that requires confirmation,
+// unless --confirm-production is given. 'dispatcher send' and 'dispatcher replay' are guarded once they
+// know they are not a dry run.
+func guardProduction(cmd *cobra.Command) error {
+	if confirmProduction || !viper.GetBool("safety.require_confirmation") {
+		return nil
+	}
+	return fmt.Errorf("profile '%s' requires --confirm-production to run '%s'", viper.GetString("profile"), cmd.CommandPath())
+}
+
+// sendDryRun returns whether 'dispatcher send' or 'dispatcher replay' only logs what it would send: when
+// --dry-run is given, or by default under a profile that sends dry runs until --confirm-production is
+// given.
+func sendDryRun(w io.Writer) bool {
+	if viper.GetBool("dispatcher.dry_run") {
+		return true
+	}
+	if confirmProduction || !viper.GetBool("safety.dry_run_send") {
+		return false
+	}
+	fmt.Fprintf(w, "Profile '%s' sends dry runs by default; pass --confirm-production to send.\n", viper.GetString("profile"))
+	return true
+}
@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyProfile(t *testing.T) {
+	t.Cleanup(viper.Reset)
+	require.NoError(t, viper.MergeConfigMap(map[string]interface{}{
+		"datastore": map[string]interface{}{"type": "bbolt", "path": "ruf.db"},
+		"profiles": map[string]interface{}{
+			"production": map[string]interface{}{
+				"datastore": map[string]interface{}{"type": "firestore"},
+				"safety":    map[string]interface{}{"require_confirmation": true},
+			},
+		},
+	}))
+
+	require.NoError(t, applyProfile("production"))
+	assert.Equal(t, "firestore", viper.GetString("datastore.type"))
+	assert.Equal(t, "ruf.db", viper.GetString("datastore.path"))
+	assert.True(t, viper.GetBool("safety.require_confirmation"))
+
+	assert.EqualError(t, applyProfile("staging"), "profile 'staging' is not configured")
+}
+
+func TestGuardProduction(t *testing.T) {
+	t.Cleanup(viper.Reset)
+	t.Cleanup(func() { confirmProduction = false })
+	viper.Set("profile", "production")
+
+	assert.NoError(t, guardProduction(sentDeleteCmd))
+
+	viper.Set("safety.require_confirmation", true)
+	assert.EqualError(t, guardProduction(sentDeleteCmd), "profile 'production' requires --confirm-production to run 'ruf sent delete'")
+
+	confirmProduction = true
+	assert.NoError(t, guardProduction(sentDeleteCmd))
+}
+
+func TestSendDryRun(t *testing.T) {
+	t.Cleanup(viper.Reset)
+	t.Cleanup(func() { confirmProduction = false })
+	viper.Set("profile", "production")
+
+	var w bytes.Buffer
+	assert.False(t, sendDryRun(&w))
+
+	viper.Set("safety.dry_run_send", true)
+	assert.True(t, sendDryRun(&w))
+	assert.Equal(t, "Profile 'production' sends dry runs by default; pass --confirm-production to send.\n", w.String())
+
+	confirmProduction = true
+	assert.False(t, sendDryRun(&w))
+
+	viper.Set("dispatcher.dry_run", true)
+	assert.True(t, sendDryRun(&w))
+}
@@ -26,8 +26,12 @@ var rootCmd = &cobra.Command{
 
 This application is a CLI tool to send calls to different platforms.
 Currently, it supports Slack.`,
-	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
 		InitConfig()
+		if cmd.Annotations[annotationMutating] == "" {
+			return nil
+		}
+		return guardProduction(cmd)
 	},
 }
 
@@ -44,25 +48,75 @@ func init() {
 	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $XDG_CONFIG_HOME/ruf/config.yaml)")
 	rootCmd.PersistentFlags().StringVar(&logLevel, "log-level", "info", "Log level (debug, info, warn, error)")
 	viper.BindPFlag("log.level", rootCmd.PersistentFlags().Lookup("log-level"))
+	rootCmd.PersistentFlags().String("profile", "", "Profile of the config to use, from profiles.<name>")
+	viper.BindPFlag("profile", rootCmd.PersistentFlags().Lookup("profile"))
+	rootCmd.PersistentFlags().BoolVar(&confirmProduction, "confirm-production", false, "Confirm a command that changes a production profile")
+	rootCmd.PersistentFlags().StringArrayVar(&overrides, "set", nil, "Override a setting for this invocation, as key=value (can be repeated)")
+
+	viper.SetDefault("profile", "")
+	viper.SetDefault("safety.require_confirmation", false)
+	viper.SetDefault("safety.dry_run_send", false)
 
 	viper.SetDefault("email.host", "")
 	viper.SetDefault("email.port", 587)
 	viper.SetDefault("email.username", "")
 	viper.SetDefault("email.password", "")
 	viper.SetDefault("email.from", "")
+	viper.SetDefault("chatwork.token", "")
+	viper.SetDefault("line.channel.token", "")
+	viper.SetDefault("homeassistant.url", "")
+	viper.SetDefault("homeassistant.token", "")
 	viper.SetDefault("git.tokens", map[string]string{})
+	viper.SetDefault("source.git.cache_dir", "")
+	viper.SetDefault("source.assets.cache_dir", "")
+	viper.SetDefault("source.push.dir", "")
+	viper.SetDefault("source.cache.enabled", true)
+	viper.SetDefault("source.s3.region", "")
+	viper.SetDefault("source.s3.access_key_id", "")
+	viper.SetDefault("source.s3.secret_access_key", "")
+	viper.SetDefault("source.s3.session_token", "")
+	viper.SetDefault("source.s3.endpoint", "")
+	viper.SetDefault("source.gcs.endpoint", "")
+	viper.SetDefault("source.azblob.account", "")
+	viper.SetDefault("source.azblob.sas_token", "")
+	viper.SetDefault("source.azblob.endpoint", "")
+	viper.SetDefault("source.events.lookback", "168h")
+	viper.SetDefault("source.events.lookahead", "720h")
+	viper.SetDefault("policy.files", []string{})
+	viper.SetDefault("chaos.enabled", false)
 	viper.SetDefault("datastore.type", "bbolt")
 	viper.SetDefault("datastore.project_id", "")
+	viper.SetDefault("datastore.cache.ttl", "1m")
+	viper.SetDefault("datastore.firestore.timeout", "30s")
+	viper.SetDefault("datastore.firestore.retry.attempts", 5)
+	viper.SetDefault("datastore.firestore.retry.initial_backoff", "100ms")
+	viper.SetDefault("datastore.firestore.retry.max_backoff", "5s")
+	viper.SetDefault("datastore.firestore.connections", 0)
 
 	viper.SetDefault("worker.missed_lookback", "24h")
+	viper.SetDefault("worker.notify_failures", true)
+	viper.SetDefault("worker.strict_templates", false)
+	viper.SetDefault("worker.record_content", true)
 	viper.SetDefault("worker.calculation.before", "24h")
 	viper.SetDefault("worker.calculation.after", "168h")
+	viper.SetDefault("worker.calculation.concurrency", 0)
+	viper.SetDefault("worker.tick.interval", "1m")
+	viper.SetDefault("worker.tick.max_calls", 0)
+	viper.SetDefault("worker.tick.max_duration", "0s")
+	viper.SetDefault("worker.send_timeout", "1m")
+	viper.SetDefault("worker.shutdown_timeout", "30s")
+	viper.SetDefault("worker.undo_window", "0s")
+	viper.SetDefault("confirmation.before", "30m")
+	viper.SetDefault("confirmation.default", "send")
 
 	viper.SetDefault("otel.exporter.traces.endpoint", "")
 	viper.SetDefault("otel.exporter.traces.headers", map[string]string{})
 	viper.SetDefault("otel.exporter.metrics.endpoint", "")
 	viper.SetDefault("otel.exporter.metrics.headers", map[string]string{})
 
+	viper.SetDefault("timezones.slack", false)
+	viper.SetDefault("timezones.cache_ttl", "24h")
+
 	viper.SetDefault("slots.timezone", "UTC")
 	viper.SetDefault("slots.default", map[string][]string{
 		"monday":    {"09:00", "14:00"},
@@ -113,6 +167,10 @@ func InitConfig() {
 
 	configReadErr := viper.ReadInConfig()
 
+	// Overrides are applied before anything reads the config, so that they can set the log level or the
+	// profile too.
+	overrideErr := applyOverrides(overrides)
+
 	// Initialise the logger
 	var programLevel = new(slog.LevelVar)
 	switch strings.ToLower(viper.GetString("log.level")) {
@@ -135,6 +193,23 @@ func InitConfig() {
 			slog.Warn("could not read config file, using defaults", "error", configReadErr)
 		}
 	}
+	if overrideErr != nil {
+		slog.Error("could not apply overrides", "error", overrideErr)
+		os.Exit(1)
+	}
+
+	if profile := viper.GetString("profile"); profile != "" {
+		if err := applyProfile(profile); err != nil {
+			slog.Error("could not apply profile", "error", err)
+			os.Exit(1)
+		}
+		slog.Debug("applied profile", "profile", profile)
+	}
+
+	if err := applySchedulingHorizon(); err != nil {
+		slog.Error("invalid scheduling horizon", "error", err)
+		os.Exit(1)
+	}
 
 	// Initialise OpenTelemetry
 	if viper.GetString("otel.exporter.traces.endpoint") != "" || viper.GetString("otel.exporter.metrics.endpoint") != "" {
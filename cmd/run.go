@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"log/slog"
 
@@ -8,7 +9,6 @@ import (
 	"github.com/andrewhowdencom/ruf/internal/clients/slack"
 	"github.com/andrewhowdencom/ruf/internal/datastore"
 	"github.com/andrewhowdencom/ruf/internal/poller"
-	"github.com/andrewhowdencom/ruf/internal/scheduler"
 	"github.com/andrewhowdencom/ruf/internal/worker"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -20,11 +20,11 @@ var runCmd = &cobra.Command{
 	Short: "Perform a single run of the dispatcher",
 	Long:  `Perform a single run of the dispatcher.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		return doRun()
+		return doRun(cmd.Context())
 	},
 }
 
-func doRun() error {
+func doRun(ctx context.Context) error {
 	slog.Debug("performing a single run")
 
 	store, err := datastore.NewStore(false)
@@ -32,17 +32,18 @@ func doRun() error {
 		return fmt.Errorf("failed to create store: %w", err)
 	}
 	defer store.Close()
+	store = chaosStore(store)
 
 	slackToken := viper.GetString("slack.app.token")
-	slackClient := slack.NewClient(slackToken)
+	slackClient := chaosSlack(slack.NewClient(slackToken))
 
-	emailClient := email.NewClient(
+	emailClient := chaosEmail(email.NewClient(
 		viper.GetString("email.host"),
 		viper.GetInt("email.port"),
 		viper.GetString("email.username"),
 		viper.GetString("email.password"),
 		viper.GetString("email.from"),
-	)
+	))
 
 	s, err := buildSourcer()
 	if err != nil {
@@ -51,14 +52,21 @@ func doRun() error {
 
 	// For a single run, the refresh interval isn't used by the poller,
 	// but we pass a zero value to the worker constructor.
-	p := poller.New(s, 0)
+	p := poller.New(s, 0, pollerOptions(store)...)
 
-	sched := scheduler.New(store)
-	w, err := worker.New(store, slackClient, emailClient, p, sched, 0, viper.GetBool("dispatcher.dry_run"))
+	sched, err := buildScheduler(store)
+	if err != nil {
+		return fmt.Errorf("failed to build scheduler: %w", err)
+	}
+	opts, err := workerOptions()
+	if err != nil {
+		return err
+	}
+	w, err := worker.New(store, slackClient, emailClient, p, sched, 0, viper.GetBool("dispatcher.dry_run"), opts...)
 	if err != nil {
 		return fmt.Errorf("failed to create worker: %w", err)
 	}
-	return w.RunOnce()
+	return w.RunOnce(ctx)
 }
 
 func init() {
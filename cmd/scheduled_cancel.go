@@ -0,0 +1,39 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/andrewhowdencom/ruf/internal/datastore"
+	"github.com/spf13/cobra"
+)
+
+// scheduledCancelCmd represents the scheduled cancel command
+var scheduledCancelCmd = &cobra.Command{
+	Use:   "cancel <id|short-id>",
+	Short: "Cancel a scheduled call, so that it is skipped.",
+	Long: `Cancel a scheduled call, given its ID or short ID, so that it is skipped when it is due.
+
+The cancellation is kept for the occurrence of the call rather than the schedule, so refreshing the
+schedule does not bring the call back: the worker records it as cancelled instead of sending it. This
+is the same as 'ruf sent cancel'.`,
+	Annotations: mutating,
+	Args:        cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store, err := datastore.NewStore(false)
+		if err != nil {
+			return fmt.Errorf("failed to create a new datastore: %w", err)
+		}
+		defer store.Close()
+
+		return doSentCancel(store, cmd.OutOrStdout(), args[0], cancelBy, cancelReason, cancelAllRecipients, time.Now())
+	},
+}
+
+func init() {
+	scheduledCmd.AddCommand(scheduledCancelCmd)
+	scheduledCancelCmd.Flags().StringVar(&cancelBy, "by", os.Getenv("USER"), "Who is cancelling the call.")
+	scheduledCancelCmd.Flags().StringVar(&cancelReason, "reason", "", "Why the call is cancelled.")
+	scheduledCancelCmd.Flags().BoolVar(&cancelAllRecipients, "all-recipients", false, "Cancel the copies of the same occurrence sent to every other recipient too.")
+}
@@ -0,0 +1,168 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/andrewhowdencom/ruf/internal/datastore"
+	"github.com/andrewhowdencom/ruf/internal/scheduler"
+	"github.com/andrewhowdencom/ruf/internal/sourcer"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// scheduledDiffCmd represents the scheduled diff command
+var scheduledDiffCmd = &cobra.Command{
+	Use:   "diff <old> <new>",
+	Short: "Show how two revisions of the sources schedule calls differently.",
+	Long: `Show how two revisions of the sources schedule calls differently over a window of time: the
+occurrences that are added, removed, or moved to another time, such as a reminder moved from Tuesday to
+Thursday. Both revisions are expanded as 'ruf scheduled simulate' would, without changing the schedule.
+
+Each revision is a source URL, a file, or a ref, such as a branch, tag or commit, of the git sources
+under source.urls, which are read at that ref instead.
+
+--from and --to are each a date (YYYY-MM-DD, read as midnight UTC), an RFC 3339 time or a duration from
+now. The window is the week from now by default.
+
+Example:
+  ruf scheduled diff main my-branch --to 720h
+  ruf scheduled diff calls.yaml calls.new.yaml`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		fromFlag, _ := cmd.Flags().GetString("from")
+		toFlag, _ := cmd.Flags().GetString("to")
+
+		now := time.Now().UTC()
+		from, to := now, time.Time{}
+		var err error
+		if fromFlag != "" {
+			if from, err = parseLaterTimeFlag("from", fromFlag, now); err != nil {
+				return err
+			}
+		}
+		if to, err = parseLaterTimeFlag("to", toFlag, now); err != nil {
+			return err
+		}
+		if to.IsZero() {
+			to = from.Add(defaultSimulation)
+		}
+
+		previous, err := revisionURLs(args[0], viper.GetStringSlice("source.urls"))
+		if err != nil {
+			return err
+		}
+		next, err := revisionURLs(args[1], viper.GetStringSlice("source.urls"))
+		if err != nil {
+			return err
+		}
+
+		s, err := buildSourcer()
+		if err != nil {
+			return fmt.Errorf("failed to build sourcer: %w", err)
+		}
+
+		store, err := datastore.NewStore(true)
+		if err != nil {
+			return fmt.Errorf("failed to create store: %w", err)
+		}
+		defer store.Close()
+
+		sched, err := buildScheduler(store)
+		if err != nil {
+			return fmt.Errorf("failed to build scheduler: %w", err)
+		}
+		return doScheduledDiff(s, sched, cmd.OutOrStdout(), previous, next, from, to)
+	},
+}
+
+func doScheduledDiff(s sourcer.Sourcer, sched *scheduler.Scheduler, w io.Writer, previous, next []string, from, to time.Time) error {
+	if !to.After(from) {
+		return fmt.Errorf("invalid window: --to %s is not after --from %s", to.Format(time.RFC3339), from.Format(time.RFC3339))
+	}
+
+	read := func(urls []string) []*sourcer.Source {
+		var sources []*sourcer.Source
+		for _, url := range urls {
+			source, _, err := s.Source(url)
+			if err != nil {
+				fmt.Fprintf(w, "Warning: failed to source from %s: %v\n", url, err)
+				continue
+			}
+			if source != nil {
+				sources = append(sources, source)
+			}
+		}
+		return sources
+	}
+
+	changes := sched.Diff(read(previous), read(next), from, to)
+	window := fmt.Sprintf("from %s to %s", from.UTC().Format(time.RFC3339), to.UTC().Format(time.RFC3339))
+	if len(changes) == 0 {
+		fmt.Fprintf(w, "No calls are scheduled differently %s.\n", window)
+		return nil
+	}
+
+	fmt.Fprintf(w, "Calls scheduled differently %s: %d\n", window, len(changes))
+	table := tablewriter.NewWriter(w)
+	table.Header("Change", "Call", "Subject", "Destination", "From", "To")
+	for _, c := range changes {
+		table.Append([]string{
+			c.Kind, c.CampaignID + "/" + c.CallID, c.Subject,
+			fmt.Sprintf("%s: %s", c.Destination.Type, strings.Join(c.Destination.To, ", ")),
+			formatChangeTime(c.From), formatChangeTime(c.To),
+		})
+	}
+	return table.Render()
+}
+
+// formatChangeTime formats when a revision sends an occurrence, with its weekday so that a move from
+// one day to another stands out, or as "-" if the revision does not send it.
+func formatChangeTime(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	return t.UTC().Format("Mon " + time.RFC3339)
+}
+
+// revisionURLs returns the URLs of the sources of a revision: a URL, a file, or a ref at which the git
+// sources among the configured ones are read.
+func revisionURLs(revision string, configured []string) ([]string, error) {
+	if strings.Contains(revision, "://") {
+		return []string{revision}, nil
+	}
+	if _, err := os.Stat(revision); err == nil {
+		path, err := filepath.Abs(revision)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find '%s': %w", revision, err)
+		}
+		return []string{"file://" + path}, nil
+	}
+
+	var urls []string
+	for _, url := range configured {
+		if !strings.HasPrefix(url, "git") {
+			continue
+		}
+		at, err := sourcer.GitURLAtRef(url, revision)
+		if err != nil {
+			return nil, err
+		}
+		urls = append(urls, at)
+	}
+	if len(urls) == 0 {
+		return nil, fmt.Errorf("'%s' is not a URL or a file, and no git sources are configured to read at it as a ref", revision)
+	}
+	return urls, nil
+}
+
+func init() {
+	scheduledCmd.AddCommand(scheduledDiffCmd)
+	scheduledDiffCmd.Flags().String("from", "", "The start of the window: a date, an RFC 3339 time or a duration from now (now if unset)")
+	scheduledDiffCmd.Flags().String("to", "", "The end of the window: a date, an RFC 3339 time or a duration from now (a week after --from if unset)")
+}
@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/andrewhowdencom/ruf/internal/datastore"
+	"github.com/andrewhowdencom/ruf/internal/scheduler"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDoScheduledDiff(t *testing.T) {
+	t.Cleanup(viper.Reset)
+
+	dir := t.TempDir()
+	source := func(name, cron string) string {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, []byte(`
+campaign:
+  id: team
+  name: Team
+calls:
+  - id: retro
+    subject: Retro
+    content: "Retro in an hour!"
+    destinations:
+      - type: slack
+        to: ["#team"]
+    triggers:
+      - cron: "`+cron+`"
+`), 0644))
+		return path
+	}
+	previous, err := revisionURLs(source("old.yaml", "0 14 * * 2"), nil)
+	require.NoError(t, err)
+	next, err := revisionURLs(source("new.yaml", "0 14 * * 4"), nil)
+	require.NoError(t, err)
+
+	s, err := buildSourcer()
+	require.NoError(t, err)
+	sched := scheduler.New(datastore.NewMockStore())
+	from := time.Date(2025, 6, 2, 0, 0, 0, 0, time.UTC) // A Monday
+
+	var buf bytes.Buffer
+	require.NoError(t, doScheduledDiff(s, sched, &buf, previous, next, from, from.AddDate(0, 0, 7)))
+	assert.Contains(t, buf.String(), "Calls scheduled differently from 2025-06-02T00:00:00Z to 2025-06-09T00:00:00Z: 1")
+	assert.Contains(t, buf.String(), "moved")
+	assert.Contains(t, buf.String(), "Tue 2025-06-03T14:00:00Z")
+	assert.Contains(t, buf.String(), "Thu 2025-06-05T14:00:00Z")
+
+	buf.Reset()
+	require.NoError(t, doScheduledDiff(s, sched, &buf, previous, previous, from, from.AddDate(0, 0, 7)))
+	assert.Contains(t, buf.String(), "No calls are scheduled differently")
+}
+
+func TestRevisionURLs(t *testing.T) {
+	urls, err := revisionURLs("https://example.com/calls.yaml", nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"https://example.com/calls.yaml"}, urls)
+
+	configured := []string{"https://example.com/calls.yaml", "git://github.com/org/repo?ref=main&path=calls"}
+	urls, err = revisionURLs("my-branch", configured)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"git+https://github.com/org/repo.git?path=calls&ref=my-branch"}, urls)
+
+	_, err = revisionURLs("my-branch", configured[:1])
+	assert.Error(t, err)
+}
@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/andrewhowdencom/ruf/internal/datastore"
+	"github.com/andrewhowdencom/ruf/internal/scheduler"
+	"github.com/andrewhowdencom/ruf/internal/sourcer"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// scheduledExplainCmd represents the scheduled explain command
+var scheduledExplainCmd = &cobra.Command{
+	Use:   "explain <call-id>",
+	Short: "Explain how a call is scheduled",
+	Long: `Explain how a call is scheduled. The sources are expanded as they would be by a refresh of the
+schedule, over the same window, without storing anything. Every occurrence a trigger of the call fired is
+listed, along with why it was skipped or moved, and when it is scheduled.
+
+The call is found by its ID, or by its campaign and ID, such as 'onboarding/welcome'.
+
+Example:
+  ruf scheduled explain standup`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		s, err := buildSourcer()
+		if err != nil {
+			return fmt.Errorf("failed to build sourcer: %w", err)
+		}
+
+		store, err := datastore.NewStore(true)
+		if err != nil {
+			return fmt.Errorf("failed to create store: %w", err)
+		}
+		defer store.Close()
+
+		sched, err := buildScheduler(store)
+		if err != nil {
+			return fmt.Errorf("failed to build scheduler: %w", err)
+		}
+		return doScheduledExplain(s, sched, cmd.OutOrStdout(), args[0], time.Now())
+	},
+}
+
+func doScheduledExplain(s sourcer.Sourcer, sched *scheduler.Scheduler, w io.Writer, callID string, now time.Time) error {
+	before, err := time.ParseDuration(viper.GetString("worker.calculation.before"))
+	if err != nil {
+		return fmt.Errorf("failed to parse worker.calculation.before: %w", err)
+	}
+	after, err := time.ParseDuration(viper.GetString("worker.calculation.after"))
+	if err != nil {
+		return fmt.Errorf("failed to parse worker.calculation.after: %w", err)
+	}
+
+	var sources []*sourcer.Source
+	for _, url := range viper.GetStringSlice("source.urls") {
+		source, _, err := s.Source(url)
+		if err != nil {
+			fmt.Fprintf(w, "Warning: failed to source from %s: %v\n", url, err)
+			continue
+		}
+		sources = append(sources, source)
+	}
+
+	found := false
+	for _, trace := range sched.Explain(sources, now, before, after) {
+		if trace.CallID != callID && trace.CampaignID+"/"+trace.CallID != callID {
+			continue
+		}
+		found = true
+
+		fmt.Fprintf(w, "Call '%s' of campaign '%s', from %s to %s\n", trace.CallID, trace.CampaignID,
+			now.Add(-before).UTC().Format(time.RFC3339), now.Add(after).UTC().Format(time.RFC3339))
+		for i, trigger := range trace.Triggers {
+			fmt.Fprintf(w, "  Trigger %d: %s\n", i, trigger)
+		}
+
+		table := tablewriter.NewWriter(w)
+		table.Header("Trigger", "Occurrence", "Step")
+		for _, step := range trace.Steps {
+			table.Append([]string{strconv.Itoa(step.Trigger), step.CallID, step.Message})
+		}
+		table.Render()
+	}
+	if !found {
+		return fmt.Errorf("could not find a call with ID '%s' in the sources", callID)
+	}
+	return nil
+}
+
+func init() {
+	scheduledCmd.AddCommand(scheduledExplainCmd)
+}
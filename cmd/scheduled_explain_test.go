@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/andrewhowdencom/ruf/internal/datastore"
+	"github.com/andrewhowdencom/ruf/internal/scheduler"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDoScheduledExplain(t *testing.T) {
+	t.Cleanup(viper.Reset)
+
+	path := filepath.Join(t.TempDir(), "standup.yaml")
+	assert.NoError(t, os.WriteFile(path, []byte(`
+campaign:
+  id: team
+  name: Team
+calls:
+  - id: standup
+    subject: Standup
+    content: "Time for standup!"
+    destinations:
+      - type: slack
+        to: ["#general"]
+    triggers:
+      - cron: "0 9 * * *"
+        exdates: ["2025-03-04"]
+`), 0644))
+	viper.Set("source.urls", []string{"file://" + path})
+	viper.Set("worker.calculation.before", "0s")
+	viper.Set("worker.calculation.after", "48h")
+
+	s, err := buildSourcer()
+	assert.NoError(t, err)
+	sched := scheduler.New(datastore.NewMockStore())
+	now := time.Date(2025, 3, 3, 8, 0, 0, 0, time.UTC)
+
+	var buf bytes.Buffer
+	assert.NoError(t, doScheduledExplain(s, sched, &buf, "team/standup", now))
+	assert.Contains(t, buf.String(), "Call 'standup' of campaign 'team', from 2025-03-03T08:00:00Z to 2025-03-05T08:00:00Z")
+	assert.Contains(t, buf.String(), "Trigger 0: cron 0 9 * * *")
+	assert.Contains(t, buf.String(), "skipped: exdate 2025-03-04")
+	assert.Contains(t, buf.String(), "scheduled at 2025-03-03T09:00:00Z")
+
+	err = doScheduledExplain(s, sched, &buf, "missing", now)
+	assert.ErrorContains(t, err, "could not find a call with ID 'missing' in the sources")
+}
@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/andrewhowdencom/ruf/internal/datastore"
+	"github.com/andrewhowdencom/ruf/internal/ics"
+	"github.com/andrewhowdencom/ruf/internal/kv"
+	"github.com/spf13/cobra"
+)
+
+// formatICS is the iCalendar format the schedule is exported in.
+const formatICS = "ics"
+
+// scheduledExportCmd represents the scheduled export command
+var scheduledExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export the upcoming scheduled calls as a calendar.",
+	Long: `Export the upcoming scheduled calls as an iCalendar file, so that teams can subscribe to the
+announcement schedule in their calendars. Each call is an event at the time it is due to be sent,
+with its subject as the summary, its content as the description, its destinations as the location and
+its campaign as the category. Events keep their UID across exports, so a calendar that subscribes to
+the file again updates them rather than adding them twice.
+
+Calls waiting for another call to be sent are left out, as they have no time yet. --campaign,
+--type and --destination export only the calls that match them.
+
+Example:
+  ruf scheduled export --format ics --campaign releases > releases.ics`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		format, _ := cmd.Flags().GetString("format")
+		name, _ := cmd.Flags().GetString("name")
+		campaign, _ := cmd.Flags().GetString("campaign")
+		destType, _ := cmd.Flags().GetString("type")
+		destination, _ := cmd.Flags().GetString("destination")
+
+		store, err := datastore.NewStore(true)
+		if err != nil {
+			return fmt.Errorf("failed to create store: %w", err)
+		}
+		defer store.Close()
+
+		return doScheduledExport(store, cmd.OutOrStdout(), format, name, campaign, destType, destination, time.Now().UTC())
+	},
+}
+
+func doScheduledExport(store kv.Storer, w io.Writer, format, name, campaign, destType, destination string, now time.Time) error {
+	if format != formatICS {
+		return fmt.Errorf("invalid format '%s': must be %s", format, formatICS)
+	}
+
+	scheduled, err := store.ListScheduledCalls()
+	if err != nil {
+		return fmt.Errorf("failed to list scheduled calls: %w", err)
+	}
+	var upcoming []*kv.ScheduledCall
+	for _, call := range scheduled {
+		if call.ScheduledAt.Before(now) {
+			continue
+		}
+		if campaign != "" && call.Campaign.ID != campaign {
+			continue
+		}
+		if !matchesDestination(call.Destinations, destType, destination) {
+			continue
+		}
+		upcoming = append(upcoming, call)
+	}
+
+	_, err = w.Write(ics.New(name, upcoming, now).Encode())
+	return err
+}
+
+func init() {
+	scheduledCmd.AddCommand(scheduledExportCmd)
+	scheduledExportCmd.Flags().String("format", formatICS, "The format to export in: ics.")
+	scheduledExportCmd.Flags().String("name", "ruf", "The name of the calendar.")
+	scheduledExportCmd.Flags().String("campaign", "", "Only export calls of the campaign with this ID.")
+	scheduledExportCmd.Flags().String("type", "", "Only export calls sent to destinations of this type (e.g., 'slack', 'email').")
+	scheduledExportCmd.Flags().String("destination", "", "Only export calls sent to this destination (e.g., '#channel', 'user@example.com').")
+}
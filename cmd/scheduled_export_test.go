@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/andrewhowdencom/ruf/internal/datastore"
+	"github.com/andrewhowdencom/ruf/internal/kv"
+	"github.com/andrewhowdencom/ruf/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScheduledExport(t *testing.T) {
+	store := datastore.NewMockStore()
+	now := time.Date(2025, 6, 2, 8, 0, 0, 0, time.UTC)
+	add := func(campaign, id, subject string, dest model.Destination, at time.Time) {
+		require.NoError(t, store.AddScheduledCall(&kv.ScheduledCall{
+			Call: model.Call{
+				ID:           id + ":" + dest.Type + ":" + dest.To[0],
+				Subject:      subject,
+				Campaign:     model.Campaign{ID: campaign, Name: strings.ToUpper(campaign)},
+				Destinations: []model.Destination{dest},
+			},
+			ScheduledAt: at,
+		}))
+	}
+	general := model.Destination{Type: "slack", To: []string{"#general"}}
+	team := model.Destination{Type: "email", To: []string{"team@example.com"}}
+	add("releases", "launch:scheduled_at:2025-06-02T09:00:00Z", "Launch", general, now.Add(time.Hour))
+	add("releases", "launch:scheduled_at:2025-06-02T09:00:00Z", "Launch", team, now.Add(time.Hour))
+	add("releases", "recap:scheduled_at:2025-06-01T09:00:00Z", "Recap", general, now.Add(-23*time.Hour))
+	add("ops", "standup:cron:0 9 * * *:2025-06-03T09:00:00Z", "Standup", team, now.Add(25*time.Hour))
+
+	var out bytes.Buffer
+	require.NoError(t, doScheduledExport(store, &out, formatICS, "ruf", "", "", "", now))
+	assert.Equal(t, 2, strings.Count(out.String(), "BEGIN:VEVENT"), "past calls are left out, and copies are one event")
+	assert.Contains(t, out.String(), "SUMMARY:Launch\r\n")
+	assert.Contains(t, out.String(), "LOCATION:email: team@example.com\\; slack: #general\r\n")
+	assert.NotContains(t, out.String(), "Recap")
+
+	out.Reset()
+	require.NoError(t, doScheduledExport(store, &out, formatICS, "ruf", "ops", "", "", now))
+	assert.Contains(t, out.String(), "SUMMARY:Standup\r\n")
+	assert.NotContains(t, out.String(), "Launch")
+
+	out.Reset()
+	require.NoError(t, doScheduledExport(store, &out, formatICS, "ruf", "", "slack", "", now))
+	assert.Equal(t, 1, strings.Count(out.String(), "BEGIN:VEVENT"))
+	assert.Contains(t, out.String(), "LOCATION:slack: #general\r\n")
+
+	out.Reset()
+	require.NoError(t, doScheduledExport(store, &out, formatICS, "ruf", "", "", "team@example.com", now))
+	assert.Equal(t, 2, strings.Count(out.String(), "BEGIN:VEVENT"))
+
+	assert.EqualError(t, doScheduledExport(store, &out, "csv", "ruf", "", "", "", now), "invalid format 'csv': must be ics")
+}
@@ -3,6 +3,7 @@ package cmd
 import (
 	"fmt"
 	"io"
+	"slices"
 	"sort"
 	"strings"
 	"time"
@@ -41,7 +42,10 @@ type scheduledCall struct {
 	Subject       string
 	Content       string
 	IsEvent       bool
-	EventSequence string // Only for event-based calls.
+	EventSequence string        // Only for event-based calls.
+	After         string        // Only for calls waiting for another call to be sent.
+	TriggeredAt   time.Time     // The time the trigger fired at, before the call was moved.
+	Shifts        []model.Shift // Why the call was moved from the time its trigger fired.
 	Destinations  []model.Destination
 }
 
@@ -56,28 +60,20 @@ func doScheduledList(store kv.Storer, w io.Writer, destType, destination string)
 
 	for _, pCall := range expandedCalls {
 		call := pCall.Call
-		// If filters are provided, check if the call has a matching destination.
-		if destType != "" || destination != "" {
-			matchFound := false
-			for _, d := range call.Destinations {
-				typeMatch := destType == "" || d.Type == destType
-				destMatch := destination == ""
-				if !destMatch {
-					for _, to := range d.To {
-						if to == destination {
-							destMatch = true
-							break
-						}
-					}
-				}
-				if typeMatch && destMatch {
-					matchFound = true
-					break
-				}
-			}
-			if !matchFound {
-				continue // Skip this call if it doesn't match the filters.
-			}
+		if !matchesDestination(call.Destinations, destType, destination) {
+			continue // Skip this call if it doesn't match the filters.
+		}
+
+		if pCall.DependsOn != nil && pCall.ScheduledAt.IsZero() {
+			allScheduledCalls = append(allScheduledCalls, scheduledCall{
+				ScheduleDef:  call.ID,
+				Campaign:     call.Campaign.Name,
+				Subject:      call.Subject,
+				Content:      truncateContent(call.Content),
+				After:        pCall.DependsOn.CallID,
+				Destinations: call.Destinations,
+			})
+			continue
 		}
 
 		if pCall.ScheduledAt.Before(now) {
@@ -91,6 +87,8 @@ func doScheduledList(store kv.Storer, w io.Writer, destType, destination string)
 			Subject:      call.Subject,
 			Content:      truncateContent(call.Content),
 			IsEvent:      false, // Expanded calls are always time-based
+			TriggeredAt:  pCall.TriggeredAt(),
+			Shifts:       call.Shifts,
 			Destinations: call.Destinations,
 		})
 	}
@@ -99,6 +97,23 @@ func doScheduledList(store kv.Storer, w io.Writer, destType, destination string)
 	return nil
 }
 
+// matchesDestination returns whether any of the destinations is of the type and sends to destination.
+// An empty type or destination matches any.
+func matchesDestination(dests []model.Destination, destType, destination string) bool {
+	if destType == "" && destination == "" {
+		return true
+	}
+	for _, d := range dests {
+		if destType != "" && d.Type != destType {
+			continue
+		}
+		if destination == "" || slices.Contains(d.To, destination) {
+			return true
+		}
+	}
+	return false
+}
+
 func sortAndDisplay(calls []scheduledCall, w io.Writer) {
 	if len(calls) == 0 {
 		fmt.Fprintln(w, "No scheduled calls found matching the criteria.")
@@ -117,6 +132,12 @@ func sortAndDisplay(calls []scheduledCall, w io.Writer) {
 		if c.IsEvent {
 			nextRunDisplay = fmt.Sprintf("On Event '%s'", c.EventSequence)
 		}
+		if c.After != "" {
+			nextRunDisplay = fmt.Sprintf("After '%s'", c.After)
+		}
+		if len(c.Shifts) > 0 {
+			nextRunDisplay += fmt.Sprintf("\nTriggered %s\n(%s)", c.TriggeredAt.Format(time.RFC1123), shiftReasons(c.Shifts))
+		}
 
 		var destStrings []string
 		for _, d := range c.Destinations {
@@ -129,6 +150,15 @@ func sortAndDisplay(calls []scheduledCall, w io.Writer) {
 	table.Render()
 }
 
+// shiftReasons lists why a call was moved, in the order it was moved.
+func shiftReasons(shifts []model.Shift) string {
+	reasons := make([]string, 0, len(shifts))
+	for _, s := range shifts {
+		reasons = append(reasons, s.String())
+	}
+	return strings.Join(reasons, ", ")
+}
+
 func init() {
 	scheduledCmd.AddCommand(scheduledListCmd)
 	scheduledListCmd.Flags().String("type", "", "Filter by destination type (e.g., 'slack', 'email')")
@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"bytes"
+	"fmt"
 	"strings"
 	"testing"
 	"time"
@@ -96,3 +97,41 @@ func TestDoScheduledListRefactored(t *testing.T) {
 		assert.NotContains(t, output, "Far Future Call")
 	})
 }
+
+func TestScheduledShifts(t *testing.T) {
+	triggeredAt := time.Now().UTC().Add(24 * time.Hour).Truncate(24 * time.Hour)
+	slot := triggeredAt.Add(10 * time.Hour)
+	deferred := slot.Add(6 * time.Hour)
+
+	store := datastore.NewMockStore()
+	store.AddScheduledCall(&kv.ScheduledCall{
+		Call: model.Call{
+			ID:           "moved-call",
+			Subject:      "Moved Call",
+			Destinations: []model.Destination{{Type: "slack", To: []string{"#general"}}},
+			Shifts: []model.Shift{
+				{Reason: model.ShiftSlot, From: triggeredAt, To: slot},
+				{Reason: model.ShiftLimits, Detail: "slack #general", From: slot, To: deferred},
+			},
+		},
+		ScheduledAt: deferred,
+	})
+
+	t.Run("list shows the trigger time and why the call was moved", func(t *testing.T) {
+		var buf bytes.Buffer
+		assert.NoError(t, doScheduledList(store, &buf, "", ""))
+		assert.Contains(t, buf.String(), deferred.Format(time.RFC1123))
+		assert.Contains(t, buf.String(), "Triggered "+triggeredAt.Format(time.RFC1123))
+		assert.Contains(t, buf.String(), "(slot, limits: slack #general)")
+	})
+
+	t.Run("show lists every move", func(t *testing.T) {
+		var buf bytes.Buffer
+		assert.NoError(t, doScheduledShow(store, &buf, "moved-call"))
+		assert.Contains(t, buf.String(), triggeredAt.Format(time.RFC1123))
+		assert.Contains(t, buf.String(), fmt.Sprintf("%s -> %s (slot)", triggeredAt.Format(time.RFC1123), slot.Format(time.RFC1123)))
+		assert.Contains(t, buf.String(), "(limits: slack #general)")
+
+		assert.ErrorContains(t, doScheduledShow(store, &buf, "missing"), "could not find a scheduled call with ID 'missing'")
+	})
+}
@@ -43,7 +43,10 @@ Example:
 		}
 		defer store.Close()
 
-		sched := scheduler.New(store)
+		sched, err := buildScheduler(store)
+		if err != nil {
+			return fmt.Errorf("failed to build scheduler: %w", err)
+		}
 		return doScheduledMissed(s, store, sched, cmd.OutOrStdout(), days)
 	},
 }
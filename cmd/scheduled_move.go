@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/andrewhowdencom/ruf/internal/datastore"
+	"github.com/andrewhowdencom/ruf/internal/kv"
+	"github.com/andrewhowdencom/ruf/internal/scheduler"
+	"github.com/spf13/cobra"
+)
+
+// nextSlot is the value of --to that moves a call into the next free slot.
+const nextSlot = "next-slot"
+
+var (
+	moveTo string
+	moveBy string
+)
+
+// scheduledMoveCmd represents the scheduled move command
+var scheduledMoveCmd = &cobra.Command{
+	Use:   "move <id|short-id> --to <time|next-slot>",
+	Short: "Move a scheduled call to another time.",
+	Long: `Move a scheduled call to another time without editing its source.
+
+--to is a date (YYYY-MM-DD, read as midnight UTC), an RFC 3339 time, a duration from now, such as 2h,
+or next-slot to move the call into the next free slot after the time it is scheduled at. A call moved
+into a slot holds it, and a call moved anywhere else gives up the slot it held.
+
+The move is kept for the occurrence of the call, so refreshing the schedule moves it again rather than
+putting it back.`,
+	Annotations: mutating,
+	Args:        cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store, err := datastore.NewStore(false)
+		if err != nil {
+			return fmt.Errorf("failed to create a new datastore: %w", err)
+		}
+		defer store.Close()
+
+		sched, err := buildScheduler(store)
+		if err != nil {
+			return fmt.Errorf("failed to build scheduler: %w", err)
+		}
+		return doScheduledMove(store, sched, cmd.OutOrStdout(), args[0], moveTo, moveBy, time.Now())
+	},
+}
+
+func doScheduledMove(store kv.Storer, sched *scheduler.Scheduler, w io.Writer, id, to, by string, now time.Time) error {
+	var at time.Time
+	switch to {
+	case "":
+		return fmt.Errorf("--to is required: a time, or %s", nextSlot)
+	case nextSlot:
+	default:
+		var err error
+		if at, err = parseLaterTimeFlag("to", to, now); err != nil {
+			return err
+		}
+		if !at.After(now) {
+			return fmt.Errorf("invalid --to '%s': must be in the future", to)
+		}
+	}
+
+	call, err := kv.FindScheduledCall(store, id)
+	if err != nil {
+		return fmt.Errorf("could not find a scheduled call with ID '%s': %w", id, err)
+	}
+	from := call.ScheduledAt
+	moved, err := sched.Move(call.Call.ID, at, by, now)
+	if err != nil {
+		return fmt.Errorf("failed to move '%s': %w", call.Call.ID, err)
+	}
+
+	fmt.Fprintf(w, "Moved call '%s' (%s) from %s to %s.\n", moved.Call.ID, kv.GenerateShortID(moved.Call.ID),
+		from.UTC().Format(time.RFC3339), moved.ScheduledAt.UTC().Format(time.RFC3339))
+	return nil
+}
+
+func init() {
+	scheduledCmd.AddCommand(scheduledMoveCmd)
+	scheduledMoveCmd.Flags().StringVar(&moveTo, "to", "", "The time to move the call to, or next-slot.")
+	scheduledMoveCmd.Flags().StringVar(&moveBy, "by", os.Getenv("USER"), "Who is moving the call.")
+}
@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/andrewhowdencom/ruf/internal/datastore"
+	"github.com/andrewhowdencom/ruf/internal/kv"
+	"github.com/andrewhowdencom/ruf/internal/model"
+	"github.com/andrewhowdencom/ruf/internal/scheduler"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScheduledMove(t *testing.T) {
+	store := datastore.NewMockStore()
+	id := "launch:scheduled_at:2025-06-02T09:00:00Z:slack:#general"
+	require.NoError(t, store.AddScheduledCall(&kv.ScheduledCall{
+		Call: model.Call{
+			ID:           id,
+			Destinations: []model.Destination{{Type: "slack", To: []string{"#general"}}},
+			Campaign:     model.Campaign{ID: "campaign"},
+		},
+		ScheduledAt: time.Date(2025, 6, 2, 9, 0, 0, 0, time.UTC),
+	}))
+	now := time.Date(2025, 6, 2, 8, 0, 0, 0, time.UTC)
+	sched := scheduler.New(store)
+	var out bytes.Buffer
+
+	assert.Error(t, doScheduledMove(store, sched, &out, id, "", "jane", now))
+	assert.Error(t, doScheduledMove(store, sched, &out, id, "2025-06-01T09:00:00Z", "jane", now))
+
+	require.NoError(t, doScheduledMove(store, sched, &out, kv.GenerateShortID(id), "3h", "jane", now))
+	assert.Contains(t, out.String(), "Moved call '"+id+"'")
+	assert.Contains(t, out.String(), "from 2025-06-02T09:00:00Z to 2025-06-02T11:00:00Z")
+
+	call, err := store.GetScheduledCall(id)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2025, 6, 2, 11, 0, 0, 0, time.UTC), call.ScheduledAt)
+	reschedules, err := store.ListReschedules()
+	require.NoError(t, err)
+	assert.Equal(t, []*kv.Reschedule{{CallID: id, To: call.ScheduledAt, By: "jane", At: now}}, reschedules)
+}
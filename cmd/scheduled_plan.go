@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/andrewhowdencom/ruf/internal/assets"
+	"github.com/andrewhowdencom/ruf/internal/datastore"
+	"github.com/andrewhowdencom/ruf/internal/kv"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+// scheduledPlanCmd represents the scheduled plan command
+var scheduledPlanCmd = &cobra.Command{
+	Use:   "plan",
+	Short: "Check what the upcoming scheduled calls will send.",
+	Long: `Check what the upcoming scheduled calls will send. The content of each call that is sent by
+email is rendered as it will be sent, with the content it was pinned to, and checked for accessibility
+problems: images without alt text, text that contrasts too little with its background, text shouted in
+capitals, and links whose text does not say where they go.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store, err := datastore.NewStore(true)
+		if err != nil {
+			return fmt.Errorf("failed to create store: %w", err)
+		}
+		defer store.Close()
+
+		cache, err := buildAssets()
+		if err != nil {
+			return err
+		}
+		return doScheduledPlan(store, cache, cmd.OutOrStdout(), time.Now())
+	},
+}
+
+func doScheduledPlan(store kv.Storer, cache *assets.Cache, w io.Writer, now time.Time) error {
+	calls, err := store.ListScheduledCalls()
+	if err != nil {
+		return fmt.Errorf("failed to list scheduled calls: %w", err)
+	}
+	sort.Slice(calls, func(i, j int) bool { return calls[i].ScheduledAt.Before(calls[j].ScheduledAt) })
+
+	table := tablewriter.NewWriter(w)
+	table.Header("Short ID", "Call ID", "Scheduled At", "Accessibility")
+	rows := 0
+	for _, call := range calls {
+		if call.ScheduledAt.Before(now) || !sentByEmail(&call.Call) {
+			continue
+		}
+
+		status := "ok"
+		issues, err := accessibilityIssues(cache, &call.Call)
+		if err != nil {
+			status = err.Error()
+		} else if len(issues) > 0 {
+			lines := make([]string, len(issues))
+			for i, issue := range issues {
+				lines[i] = issue.String()
+			}
+			status = strings.Join(lines, "\n")
+		}
+		table.Append([]string{kv.GenerateShortID(call.ID), call.ID, call.ScheduledAt.Format(time.RFC1123), status})
+		rows++
+	}
+	if rows == 0 {
+		fmt.Fprintln(w, "No upcoming scheduled calls are sent by email.")
+		return nil
+	}
+	table.Render()
+	return nil
+}
+
+func init() {
+	scheduledCmd.AddCommand(scheduledPlanCmd)
+}
@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/andrewhowdencom/ruf/internal/assets"
+	"github.com/andrewhowdencom/ruf/internal/datastore"
+	"github.com/andrewhowdencom/ruf/internal/kv"
+	"github.com/andrewhowdencom/ruf/internal/model"
+	"github.com/andrewhowdencom/ruf/internal/sourcer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDoScheduledPlan(t *testing.T) {
+	store := datastore.NewMockStore()
+	now := time.Date(2025, 6, 1, 9, 0, 0, 0, time.UTC)
+	schedule := func(id, content string, destType string) {
+		require.NoError(t, store.AddScheduledCall(&kv.ScheduledCall{
+			Call: model.Call{
+				ID:           id,
+				Content:      content,
+				Destinations: []model.Destination{{Type: destType, To: []string{"team@example.com"}}},
+			},
+			ScheduledAt: now.Add(time.Hour),
+		}))
+	}
+	schedule("newsletter", "Read the notes [here](https://example.com/notes). ![](chart.png)", "email")
+	schedule("reminder", "Read the [release notes](https://example.com/notes).", "email")
+	schedule("standup", "CLICK [HERE](https://example.com) NOW", "slack")
+
+	var out bytes.Buffer
+	require.NoError(t, doScheduledPlan(store, assets.New(t.TempDir(), sourcer.NewCompositeFetcher()), &out, now))
+	assert.Contains(t, out.String(), "link-text")
+	assert.Contains(t, out.String(), "image-alt")
+	assert.Contains(t, out.String(), "ok")
+	// Calls that are not sent by email are not sent as HTML, so they are not checked.
+	assert.NotContains(t, out.String(), "standup")
+}
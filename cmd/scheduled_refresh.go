@@ -7,7 +7,6 @@ import (
 
 	"github.com/andrewhowdencom/ruf/internal/datastore"
 	"github.com/andrewhowdencom/ruf/internal/poller"
-	"github.com/andrewhowdencom/ruf/internal/scheduler"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -22,6 +21,7 @@ This command will:
 - Clear the existing schedule from the datastore.
 - Expand all call definitions into individual, scheduled instances.
 - Persist the new schedule to the datastore.`,
+	Annotations: mutating,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		store, err := datastore.NewStore(false)
 		if err != nil {
@@ -29,14 +29,17 @@ This command will:
 		}
 		defer store.Close()
 
-		s := scheduler.New(store)
+		s, err := buildScheduler(store)
+		if err != nil {
+			return fmt.Errorf("failed to build scheduler: %w", err)
+		}
 
 		sourcerImpl, err := buildSourcer()
 		if err != nil {
 			return fmt.Errorf("failed to build sourcer: %w", err)
 		}
 
-		p := poller.New(sourcerImpl, 0)
+		p := poller.New(sourcerImpl, 0, pollerOptions(store)...)
 
 		sources, err := p.Poll(viper.GetStringSlice("source.urls"))
 		if err != nil {
@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/andrewhowdencom/ruf/internal/datastore"
+	"github.com/andrewhowdencom/ruf/internal/kv"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+// scheduledShowCmd represents the scheduled show command
+var scheduledShowCmd = &cobra.Command{
+	Use:   "show <id>",
+	Short: "Show a scheduled call, including why it is scheduled when it is.",
+	Long: `Show a scheduled call. If the call was moved from the time its trigger fired, such as into a time
+slot, off a holiday or by the limits of its destination, both times are shown along with every move.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store, err := datastore.NewStore(true)
+		if err != nil {
+			return fmt.Errorf("failed to create a new datastore: %w", err)
+		}
+		defer store.Close()
+
+		return doScheduledShow(store, cmd.OutOrStdout(), args[0])
+	},
+}
+
+func doScheduledShow(store kv.Storer, w io.Writer, id string) error {
+	call, err := store.GetScheduledCall(id)
+	if err != nil {
+		if errors.Is(err, kv.ErrNotFound) {
+			return fmt.Errorf("could not find a scheduled call with ID '%s'", id)
+		}
+		return fmt.Errorf("failed to get scheduled call: %w", err)
+	}
+
+	var destinations []string
+	for _, d := range call.Destinations {
+		destinations = append(destinations, fmt.Sprintf("%s: %s", d.Type, strings.Join(d.To, ", ")))
+	}
+
+	table := tablewriter.NewWriter(w)
+	table.Header("Field", "Value")
+	table.Append([]string{"ID", call.ID})
+	table.Append([]string{"Campaign", call.Campaign.Name})
+	table.Append([]string{"Subject", call.Subject})
+	table.Append([]string{"Destinations", strings.Join(destinations, "\n")})
+	if call.DependsOn != nil && call.ScheduledAt.IsZero() {
+		table.Append([]string{"Scheduled At", fmt.Sprintf("%s after '%s' is sent", call.DependsOn.Delay, call.DependsOn.CallID)})
+	} else {
+		table.Append([]string{"Scheduled At", call.ScheduledAt.Format(time.RFC1123)})
+		table.Append([]string{"Triggered At", call.TriggeredAt().Format(time.RFC1123)})
+	}
+	for _, s := range call.Shifts {
+		table.Append([]string{"Moved", fmt.Sprintf("%s -> %s (%s)", s.From.Format(time.RFC1123), s.To.Format(time.RFC1123), s)})
+	}
+	table.Render()
+
+	return nil
+}
+
+func init() {
+	scheduledCmd.AddCommand(scheduledShowCmd)
+}
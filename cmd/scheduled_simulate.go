@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/andrewhowdencom/ruf/internal/datastore"
+	"github.com/andrewhowdencom/ruf/internal/kv"
+	"github.com/andrewhowdencom/ruf/internal/scheduler"
+	"github.com/andrewhowdencom/ruf/internal/sourcer"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// defaultSimulation is how long the window of a simulation is when --to is not set.
+const defaultSimulation = 7 * 24 * time.Hour
+
+// scheduledSimulateCmd represents the scheduled simulate command
+var scheduledSimulateCmd = &cobra.Command{
+	Use:   "simulate",
+	Short: "Show what would be sent over a window of time.",
+	Long: `Show what would be sent from --from up to --to: each call, when it would be sent, to where, and
+why it was moved from when its trigger fired, such as into a time slot. The sources are expanded as a
+refresh of the schedule at --from would, without changing the schedule or its slot reservations.
+
+--from and --to are each a date (YYYY-MM-DD, read as midnight UTC), an RFC 3339 time or a duration from
+now. The window is the week from now by default. --source simulates other sources than those
+configured, such as those changed by a pull request.
+
+Example:
+  ruf scheduled simulate --from 2025-06-01 --to 2025-07-01 --source file://./calls.yaml`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		fromFlag, _ := cmd.Flags().GetString("from")
+		toFlag, _ := cmd.Flags().GetString("to")
+		urls, _ := cmd.Flags().GetStringSlice("source")
+		if len(urls) == 0 {
+			urls = viper.GetStringSlice("source.urls")
+		}
+
+		now := time.Now().UTC()
+		from, to := now, time.Time{}
+		var err error
+		if fromFlag != "" {
+			if from, err = parseLaterTimeFlag("from", fromFlag, now); err != nil {
+				return err
+			}
+		}
+		if to, err = parseLaterTimeFlag("to", toFlag, now); err != nil {
+			return err
+		}
+		if to.IsZero() {
+			to = from.Add(defaultSimulation)
+		}
+
+		s, err := buildSourcer()
+		if err != nil {
+			return fmt.Errorf("failed to build sourcer: %w", err)
+		}
+
+		store, err := datastore.NewStore(true)
+		if err != nil {
+			return fmt.Errorf("failed to create store: %w", err)
+		}
+		defer store.Close()
+
+		sched, err := buildScheduler(store)
+		if err != nil {
+			return fmt.Errorf("failed to build scheduler: %w", err)
+		}
+		return doScheduledSimulate(s, sched, cmd.OutOrStdout(), urls, from, to)
+	},
+}
+
+func doScheduledSimulate(s sourcer.Sourcer, sched *scheduler.Scheduler, w io.Writer, urls []string, from, to time.Time) error {
+	if !to.After(from) {
+		return fmt.Errorf("invalid window: --to %s is not after --from %s", to.Format(time.RFC3339), from.Format(time.RFC3339))
+	}
+
+	var sources []*sourcer.Source
+	for _, url := range urls {
+		source, _, err := s.Source(url)
+		if err != nil {
+			fmt.Fprintf(w, "Warning: failed to source from %s: %v\n", url, err)
+			continue
+		}
+		if source != nil {
+			sources = append(sources, source)
+		}
+	}
+
+	calls := sched.Simulate(sources, from, to)
+	window := fmt.Sprintf("from %s to %s", from.UTC().Format(time.RFC3339), to.UTC().Format(time.RFC3339))
+	if len(calls) == 0 {
+		fmt.Fprintf(w, "No calls would be sent %s.\n", window)
+		return nil
+	}
+
+	fmt.Fprintf(w, "Calls that would be sent %s: %d\n", window, len(calls))
+	table := tablewriter.NewWriter(w)
+	table.Header("Scheduled At", "Short ID", "Campaign", "Subject", "Destination", "Moved")
+	for _, call := range calls {
+		var destinations, moves []string
+		for _, d := range call.Destinations {
+			destinations = append(destinations, fmt.Sprintf("%s: %s", d.Type, strings.Join(d.To, ", ")))
+		}
+		for _, shift := range call.Shifts {
+			moves = append(moves, fmt.Sprintf("from %s (%s)", shift.From.Format(time.RFC3339), shift))
+		}
+		table.Append([]string{
+			call.ScheduledAt.Format(time.RFC3339), kv.GenerateShortID(call.ID), call.Campaign.Name, call.Subject,
+			strings.Join(destinations, "\n"), strings.Join(moves, "\n"),
+		})
+	}
+	return table.Render()
+}
+
+func init() {
+	scheduledCmd.AddCommand(scheduledSimulateCmd)
+	scheduledSimulateCmd.Flags().String("from", "", "The start of the window: a date, an RFC 3339 time or a duration from now (now if unset)")
+	scheduledSimulateCmd.Flags().String("to", "", "The end of the window: a date, an RFC 3339 time or a duration from now (a week after --from if unset)")
+	scheduledSimulateCmd.Flags().StringSlice("source", nil, "The URLs of the sources to simulate (source.urls if unset)")
+}
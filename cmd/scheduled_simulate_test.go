@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/andrewhowdencom/ruf/internal/datastore"
+	"github.com/andrewhowdencom/ruf/internal/scheduler"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDoScheduledSimulate(t *testing.T) {
+	t.Cleanup(viper.Reset)
+
+	path := filepath.Join(t.TempDir(), "calls.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+campaign:
+  id: team
+  name: Team
+calls:
+  - id: standup
+    subject: Standup
+    content: "Time for standup!"
+    horizon:
+      after: 1h
+    destinations:
+      - type: slack
+        to: ["#general"]
+    triggers:
+      - cron: "0 9 * * 1-5"
+  - id: launch
+    subject: Launch
+    content: "We are live!"
+    destinations:
+      - type: email
+        to: ["team@example.com"]
+    triggers:
+      - scheduled_at: 2025-06-04T00:00:00Z
+`), 0644))
+	viper.Set("slots.timezone", "UTC")
+	viper.Set("slots.default", map[string][]string{"wednesday": {"14:00"}})
+
+	s, err := buildSourcer()
+	require.NoError(t, err)
+	store := datastore.NewMockStore()
+	sched := scheduler.New(store)
+	from := time.Date(2025, 6, 2, 0, 0, 0, 0, time.UTC) // A Monday
+
+	var buf bytes.Buffer
+	require.NoError(t, doScheduledSimulate(s, sched, &buf, []string{"file://" + path}, from, from.Add(72*time.Hour)))
+	out := buf.String()
+	assert.Contains(t, out, "Calls that would be sent from 2025-06-02T00:00:00Z to 2025-06-05T00:00:00Z: 4")
+	// The horizon of the standup does not cut the window short.
+	assert.Contains(t, out, "2025-06-04T09:00:00Z")
+	// The launch is moved into the slot of the day.
+	assert.Contains(t, out, "2025-06-04T14:00:00Z")
+	assert.Contains(t, out, "from 2025-06-04T00:00:00Z (slot)")
+
+	// Nothing is stored.
+	slots, err := store.ListSlots()
+	require.NoError(t, err)
+	assert.Empty(t, slots)
+	scheduled, err := store.ListScheduledCalls()
+	require.NoError(t, err)
+	assert.Empty(t, scheduled)
+
+	buf.Reset()
+	require.NoError(t, doScheduledSimulate(s, sched, &buf, []string{"file://" + path}, from.AddDate(0, 0, 5), from.AddDate(0, 0, 7)))
+	assert.Contains(t, buf.String(), "No calls would be sent")
+
+	assert.Error(t, doScheduledSimulate(s, sched, &buf, nil, from, from))
+}
@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/andrewhowdencom/ruf/internal/calendar"
+	"github.com/andrewhowdencom/ruf/internal/http"
+	"github.com/andrewhowdencom/ruf/internal/kv"
+	"github.com/andrewhowdencom/ruf/internal/scheduler"
+	"github.com/spf13/viper"
+)
+
+// holidayConfig is the configuration of a single holiday provider.
+type holidayConfig struct {
+	Type    string `mapstructure:"type"`
+	Path    string `mapstructure:"path"`
+	URL     string `mapstructure:"url"`
+	Country string `mapstructure:"country"`
+}
+
+// buildScheduler creates a new scheduler, consulting the configured holiday calendars, policies,
+// destination limits and recipient timezones. Call definitions are expanded by up to
+// worker.calculation.concurrency at once.
+func buildScheduler(store kv.Storer) (*scheduler.Scheduler, error) {
+	providers, err := buildHolidayProviders()
+	if err != nil {
+		return nil, err
+	}
+	opts := []scheduler.Option{
+		scheduler.WithHolidays(providers...),
+		scheduler.WithConcurrency(viper.GetInt("worker.calculation.concurrency")),
+	}
+
+	engine, err := buildPolicy()
+	if err != nil {
+		return nil, err
+	}
+	if engine != nil {
+		opts = append(opts, scheduler.WithPolicy(engine))
+	}
+
+	l, err := buildLimits()
+	if err != nil {
+		return nil, err
+	}
+	if l != nil {
+		opts = append(opts, scheduler.WithLimits(l))
+	}
+
+	timezones, err := buildTimezones()
+	if err != nil {
+		return nil, err
+	}
+	if timezones != nil {
+		opts = append(opts, scheduler.WithTimezones(timezones))
+	}
+	return scheduler.New(store, opts...), nil
+}
+
+// buildHolidayProviders creates the holiday providers listed under calendar.holidays.
+func buildHolidayProviders() ([]calendar.Provider, error) {
+	var configs []holidayConfig
+	if err := viper.UnmarshalKey("calendar.holidays", &configs); err != nil {
+		return nil, fmt.Errorf("failed to parse calendar.holidays: %w", err)
+	}
+
+	httpClient := http.NewClient()
+	providers := make([]calendar.Provider, 0, len(configs))
+	for _, c := range configs {
+		switch c.Type {
+		case "static":
+			p, err := calendar.LoadStatic(c.Path)
+			if err != nil {
+				return nil, err
+			}
+			providers = append(providers, p)
+		case "ical":
+			providers = append(providers, calendar.NewICal(c.URL, calendar.WithHTTPClient(httpClient)))
+		case "api":
+			providers = append(providers, calendar.NewPublicHolidayAPI(c.Country, calendar.WithHTTPClient(httpClient)))
+		default:
+			return nil, fmt.Errorf("unknown holiday provider type: %s", c.Type)
+		}
+	}
+	return providers, nil
+}
@@ -0,0 +1,39 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/andrewhowdencom/ruf/internal/scheduler"
+	"github.com/spf13/viper"
+)
+
+// applySchedulingHorizon sets the window triggers are expanded in, and how late the worker still sends a
+// call, from scheduling.horizon, so that they cannot disagree: its before bound is both how far back
+// triggers are expanded and the lookback of the worker, and its after bound is how far ahead they are
+// expanded. It takes precedence over the worker settings it derives. The resulting window is then
+// checked, whether it was derived or configured on its own.
+func applySchedulingHorizon() error {
+	if viper.IsSet("scheduling.horizon.before") {
+		before := viper.GetString("scheduling.horizon.before")
+		viper.Set("worker.calculation.before", before)
+		viper.Set("worker.missed_lookback", before)
+	}
+	if viper.IsSet("scheduling.horizon.after") {
+		viper.Set("worker.calculation.after", viper.GetString("scheduling.horizon.after"))
+	}
+
+	var window [3]time.Duration
+	for i, key := range []string{"worker.calculation.before", "worker.calculation.after", "worker.missed_lookback"} {
+		// A setting that is not configured at all is reported by what reads it.
+		if viper.GetString(key) == "" {
+			return nil
+		}
+		d, err := time.ParseDuration(viper.GetString(key))
+		if err != nil {
+			return fmt.Errorf("failed to parse %s: %w", key, err)
+		}
+		window[i] = d
+	}
+	return scheduler.CheckWindow(window[0], window[1], window[2])
+}
@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/andrewhowdencom/ruf/internal/scheduler"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApplySchedulingHorizon(t *testing.T) {
+	setDefaults := func(t *testing.T) {
+		t.Cleanup(viper.Reset)
+		viper.SetDefault("worker.missed_lookback", "24h")
+		viper.SetDefault("worker.calculation.before", "24h")
+		viper.SetDefault("worker.calculation.after", "168h")
+	}
+
+	t.Run("defaults agree", func(t *testing.T) {
+		setDefaults(t)
+		assert.NoError(t, applySchedulingHorizon())
+	})
+
+	t.Run("horizon sets the window and the lookback", func(t *testing.T) {
+		setDefaults(t)
+		viper.Set("worker.missed_lookback", "1h")
+		viper.Set("scheduling.horizon.before", "48h")
+		viper.Set("scheduling.horizon.after", "720h")
+
+		assert.NoError(t, applySchedulingHorizon())
+		assert.Equal(t, "48h", viper.GetString("worker.calculation.before"))
+		assert.Equal(t, "48h", viper.GetString("worker.missed_lookback"))
+		assert.Equal(t, "720h", viper.GetString("worker.calculation.after"))
+	})
+
+	t.Run("lookback shorter than the window", func(t *testing.T) {
+		setDefaults(t)
+		viper.Set("worker.missed_lookback", "10m")
+		assert.ErrorIs(t, applySchedulingHorizon(), scheduler.ErrInconsistentWindow)
+	})
+
+	t.Run("negative bound", func(t *testing.T) {
+		setDefaults(t)
+		viper.Set("scheduling.horizon.after", "-1h")
+		assert.ErrorIs(t, applySchedulingHorizon(), scheduler.ErrInconsistentWindow)
+	})
+
+	t.Run("invalid duration", func(t *testing.T) {
+		setDefaults(t)
+		viper.Set("scheduling.horizon.before", "soon")
+		assert.ErrorContains(t, applySchedulingHorizon(), "failed to parse worker.calculation.before")
+	})
+}
@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/andrewhowdencom/ruf/internal/release"
+	"github.com/spf13/cobra"
+)
+
+// selfUpdateCmd represents the self-update command
+var selfUpdateCmd = &cobra.Command{
+	Use:   "self-update",
+	Short: "Update ruf to the latest release.",
+	Long: `Update ruf to the latest release.
+
+The latest release is read from the configured update.endpoint. The ed25519
+signature of the published checksums is verified with update.public_key, or the
+key embedded in the binary when it was built, and the archive for the current
+platform is verified against the checksums, before the running binary is
+replaced. A release that cannot be verified is refused.
+
+--insecure-skip-signature installs a release checked only against the checksums
+published alongside it, which does not protect against a tampered release.
+
+Example:
+  # Replace the running binary with the latest release
+  ruf self-update`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		force, _ := cmd.Flags().GetBool("force")
+		skipSignature, _ := cmd.Flags().GetBool("insecure-skip-signature")
+		ctx := context.Background()
+
+		updater, err := buildUpdater(skipSignature)
+		if err != nil {
+			return err
+		}
+		if skipSignature {
+			fmt.Fprintln(cmd.ErrOrStderr(), "Warning: the signature of the release is not verified; it is only checked against the checksums published with it.")
+		}
+		latest, err := updater.Latest(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to check for updates: %w", err)
+		}
+
+		newer, err := release.IsNewer(latest, buildVersion)
+		if err != nil {
+			return err
+		}
+		if !newer && !force {
+			fmt.Fprintf(cmd.OutOrStdout(), "ruf %s is already the latest version.\n", buildVersion)
+			return nil
+		}
+
+		binary, err := updater.Download(ctx, latest)
+		if errors.Is(err, release.ErrNoPublicKey) {
+			return fmt.Errorf("failed to download release %s: %w; set update.public_key, or pass --insecure-skip-signature", latest.Version(), err)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to download release %s: %w", latest.Version(), err)
+		}
+
+		path, err := os.Executable()
+		if err != nil {
+			return fmt.Errorf("failed to locate the running binary: %w", err)
+		}
+		if path, err = filepath.EvalSymlinks(path); err != nil {
+			return fmt.Errorf("failed to locate the running binary: %w", err)
+		}
+
+		if err := release.Install(path, binary); err != nil {
+			return err
+		}
+
+		fmt.Fprintf(cmd.OutOrStdout(), "Updated ruf from %s to %s.\n", buildVersion, latest.Version())
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(selfUpdateCmd)
+	selfUpdateCmd.Flags().Bool("force", false, "Install the latest release even if it is not newer")
+	selfUpdateCmd.Flags().Bool("insecure-skip-signature", false, "Install a release without verifying its signature")
+}
@@ -0,0 +1,34 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/andrewhowdencom/ruf/internal/selftest"
+	"github.com/spf13/cobra"
+)
+
+// selftestCmd represents the selftest command
+var selftestCmd = &cobra.Command{
+	Use:   "selftest",
+	Short: "Check the pipeline end to end, without sending anything",
+	Long: `Check the pipeline end to end, without sending anything.
+
+A canned source is fetched, scheduled and dispatched against stand-ins for Slack, an SMTP server and
+a source server that run inside the process, with a temporary datastore. Every stage is checked, and
+a report of the checks is written. The command fails if any check fails.
+
+Example:
+  ruf selftest`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		report, err := selftest.Run()
+		if err != nil {
+			return fmt.Errorf("failed to run the self test: %w", err)
+		}
+		report.Write(cmd.OutOrStdout())
+		return report.Err()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(selftestCmd)
+}
@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+
+	"github.com/andrewhowdencom/ruf/internal/datastore"
+	"github.com/andrewhowdencom/ruf/internal/kv"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+// sentCampaignsCmd represents the sent campaigns command
+var sentCampaignsCmd = &cobra.Command{
+	Use:   "campaigns",
+	Short: "List the campaigns that calls have been sent for.",
+	Long: `List the campaigns that calls have been sent for, from the history in the datastore.
+
+Campaigns are listed whether or not they are still in a source, so the history of a campaign whose
+source has been removed can be found and listed with 'ruf sent list --campaign <id>'. Calls sent
+before campaigns were recorded with them are listed once 'ruf migrate db' has been run.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store, err := datastore.NewStore(true)
+		if err != nil {
+			return fmt.Errorf("failed to create a new datastore: %w", err)
+		}
+		defer store.Close()
+
+		return doSentCampaigns(store, cmd.OutOrStdout())
+	},
+}
+
+// campaignHistory summarises the calls sent for a campaign.
+type campaignHistory struct {
+	id     string
+	name   string
+	sent   int
+	failed int
+	last   *kv.SentMessage
+}
+
+func doSentCampaigns(store kv.Storer, w io.Writer) error {
+	messages, err := store.ListSentMessages()
+	if err != nil {
+		return fmt.Errorf("failed to list sent messages: %w", err)
+	}
+
+	byID := make(map[string]*campaignHistory)
+	for _, m := range messages {
+		if m.CampaignID == "" {
+			continue
+		}
+		h, ok := byID[m.CampaignID]
+		if !ok {
+			h = &campaignHistory{id: m.CampaignID}
+			byID[m.CampaignID] = h
+		}
+		if m.Failed() {
+			h.failed++
+		} else {
+			h.sent++
+		}
+		// The campaign is named as it was when it was last sent.
+		if h.last == nil || m.ScheduledAt.After(h.last.ScheduledAt) {
+			h.last = m
+			h.name = m.CampaignName
+		}
+	}
+
+	histories := make([]*campaignHistory, 0, len(byID))
+	for _, h := range byID {
+		histories = append(histories, h)
+	}
+	sort.Slice(histories, func(i, j int) bool {
+		return histories[i].last.ScheduledAt.After(histories[j].last.ScheduledAt)
+	})
+
+	table := tablewriter.NewWriter(w)
+	table.Header("Campaign ID", "Name", "Sent", "Failed", "Last Scheduled At")
+	for _, h := range histories {
+		table.Append([]string{h.id, h.name, strconv.Itoa(h.sent), strconv.Itoa(h.failed), h.last.ScheduledAt.String()})
+	}
+	return table.Render()
+}
+
+func init() {
+	sentCmd.AddCommand(sentCampaignsCmd)
+}
@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/andrewhowdencom/ruf/internal/datastore"
+	"github.com/andrewhowdencom/ruf/internal/kv"
+	"github.com/spf13/cobra"
+)
+
+var (
+	cancelBy            string
+	cancelReason        string
+	cancelAllRecipients bool
+)
+
+// sentCancelCmd represents the sent cancel command
+var sentCancelCmd = &cobra.Command{
+	Use:   "cancel <id>",
+	Short: "Cancel a scheduled call that has not been sent.",
+	Long: `Cancel a scheduled call that has not been sent, given its ID or short ID, or the
+ID or short ID 'ruf sent list' shows for it while it is pending in the undo window.
+
+The worker checks for a cancellation before sending the call to each recipient, so a
+call that is due, or is part way through being sent, is not sent to the recipients
+that remain. They are recorded as cancelled. With --all-recipients, the copies of the
+same occurrence sent to other recipients, such as those of a staged rollout spread over
+a window, are cancelled too.`,
+	Annotations: mutating,
+	Args:        cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store, err := datastore.NewStore(false)
+		if err != nil {
+			return fmt.Errorf("failed to create a new datastore: %w", err)
+		}
+		defer store.Close()
+
+		return doSentCancel(store, cmd.OutOrStdout(), args[0], cancelBy, cancelReason, cancelAllRecipients, time.Now())
+	},
+}
+
+func doSentCancel(store kv.Storer, w io.Writer, id, by, reason string, allRecipients bool, now time.Time) error {
+	call, err := findCancellable(store, id)
+	if err != nil {
+		return fmt.Errorf("could not find a scheduled call with ID '%s', it may already have been sent: %w", id, err)
+	}
+
+	calls := []*kv.ScheduledCall{call}
+	if allRecipients {
+		scheduled, err := store.ListScheduledCalls()
+		if err != nil {
+			return fmt.Errorf("failed to list scheduled calls: %w", err)
+		}
+		calls = calls[:0]
+		for _, c := range scheduled {
+			if c.Campaign.ID == call.Campaign.ID && occurrence(c) == occurrence(call) {
+				calls = append(calls, c)
+			}
+		}
+	}
+
+	cancelled := 0
+	for _, c := range calls {
+		var remaining []string
+		for _, to := range c.Destinations[0].To {
+			sent, err := store.HasBeenSent(c.Campaign.ID, c.ID, c.OccurredAt(), c.Destinations[0].Type, to)
+			if err != nil {
+				return fmt.Errorf("failed to check if '%s' has been sent: %w", c.ID, err)
+			}
+			if !sent {
+				remaining = append(remaining, to)
+			}
+		}
+		if len(remaining) == 0 {
+			fmt.Fprintf(w, "Call '%s' has already been sent to every recipient.\n", c.ID)
+			continue
+		}
+
+		if err := store.CancelCall(&kv.Cancellation{CallID: c.ID, By: by, Reason: reason, At: now.UTC()}); err != nil {
+			return fmt.Errorf("failed to cancel '%s': %w", c.ID, err)
+		}
+		cancelled++
+		fmt.Fprintf(w, "Cancelled call '%s' (%s) for %s.\n", c.ID, kv.GenerateShortID(c.ID), strings.Join(remaining, ", "))
+	}
+	if cancelled > 0 {
+		fmt.Fprintln(w, "Recipients the worker has not sent the call to before it next checks will not be sent it.")
+	}
+	return nil
+}
+
+// findCancellable returns the scheduled call with an ID or short ID, or that of a message held pending
+// for the undo window, given the ID or short ID shown for it by 'ruf sent list'.
+func findCancellable(store kv.Storer, id string) (*kv.ScheduledCall, error) {
+	call, err := kv.FindScheduledCall(store, id)
+	if !errors.Is(err, kv.ErrNotFound) {
+		return call, err
+	}
+	sm, smErr := store.GetSentMessage(id)
+	if smErr != nil || sm.Status != kv.StatusPending {
+		return nil, err
+	}
+	return kv.FindScheduledCall(store, sm.SourceID)
+}
+
+// occurrence returns the ID shared by the copies of an occurrence of a call sent to each recipient,
+// which only differ in the destination their ID ends with.
+func occurrence(call *kv.ScheduledCall) string {
+	if len(call.Destinations) == 0 || len(call.Destinations[0].To) == 0 {
+		return call.ID
+	}
+	dest := call.Destinations[0]
+	return strings.TrimSuffix(call.ID, ":"+dest.Type+":"+dest.To[0])
+}
+
+func init() {
+	sentCmd.AddCommand(sentCancelCmd)
+	sentCancelCmd.Flags().StringVar(&cancelBy, "by", os.Getenv("USER"), "Who is cancelling the call.")
+	sentCancelCmd.Flags().StringVar(&cancelReason, "reason", "", "Why the call is cancelled.")
+	sentCancelCmd.Flags().BoolVar(&cancelAllRecipients, "all-recipients", false, "Cancel the copies of the same occurrence sent to every other recipient too.")
+}
@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/andrewhowdencom/ruf/internal/datastore"
+	"github.com/andrewhowdencom/ruf/internal/kv"
+	"github.com/andrewhowdencom/ruf/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSentCancel(t *testing.T) {
+	store := datastore.NewMockStore()
+	occurrence := "rollout:cron:0 9 * * *:2025-06-02T09:00:00Z"
+	for _, to := range []string{"#one", "#two", "#three"} {
+		require.NoError(t, store.AddScheduledCall(&kv.ScheduledCall{
+			Call: model.Call{
+				ID:           occurrence + ":slack:" + to,
+				Destinations: []model.Destination{{Type: "slack", To: []string{to}}},
+				Campaign:     model.Campaign{ID: "campaign"},
+			},
+			ScheduledAt: time.Date(2025, 6, 2, 9, 0, 0, 0, time.UTC),
+		}))
+	}
+	require.NoError(t, store.AddSentMessage("campaign", occurrence+":slack:#one", &kv.SentMessage{Type: "slack", Destination: "#one", Status: kv.StatusSent}))
+	now := time.Date(2025, 6, 2, 9, 5, 0, 0, time.UTC)
+	var out bytes.Buffer
+
+	require.NoError(t, doSentCancel(store, &out, kv.GenerateShortID(occurrence+":slack:#two"), "jane", "wrong date", false, now))
+	assert.Contains(t, out.String(), "Cancelled call '"+occurrence+":slack:#two'")
+	c, err := store.GetCancellation(occurrence + ":slack:#two")
+	require.NoError(t, err)
+	assert.Equal(t, &kv.Cancellation{CallID: occurrence + ":slack:#two", By: "jane", Reason: "wrong date", At: now}, c)
+	_, err = store.GetCancellation(occurrence + ":slack:#three")
+	assert.ErrorIs(t, err, kv.ErrNotFound)
+
+	out.Reset()
+	require.NoError(t, doSentCancel(store, &out, occurrence+":slack:#two", "jane", "", true, now))
+	assert.Contains(t, out.String(), "Call '"+occurrence+":slack:#one' has already been sent to every recipient.")
+	_, err = store.GetCancellation(occurrence + ":slack:#three")
+	assert.NoError(t, err, "the rest of the rollout is cancelled")
+
+	assert.ErrorContains(t, doSentCancel(store, &out, "missing", "jane", "", false, now), "could not find a scheduled call")
+}
+
+func TestSentCancel_Pending(t *testing.T) {
+	store := datastore.NewMockStore()
+	call := &kv.ScheduledCall{
+		Call: model.Call{
+			ID:           "launch",
+			Destinations: []model.Destination{{Type: "slack", To: []string{"#wrong-channel"}}},
+			Campaign:     model.Campaign{ID: "campaign"},
+		},
+		ScheduledAt: time.Date(2025, 6, 2, 9, 0, 0, 0, time.UTC),
+	}
+	require.NoError(t, store.AddScheduledCall(call))
+	pending := &kv.SentMessage{SourceID: "launch", Type: "slack", Destination: "#wrong-channel", OccurredAt: call.ScheduledAt, Status: kv.StatusPending}
+	require.NoError(t, store.AddSentMessage("campaign", "launch", pending))
+	var out bytes.Buffer
+
+	// The short ID of the pending message, as 'ruf sent list' shows it, cancels the call it is held for.
+	require.NoError(t, doSentCancel(store, &out, pending.ShortID, "jane", "wrong channel", false, call.ScheduledAt.Add(time.Minute)))
+	assert.Contains(t, out.String(), "Cancelled call 'launch'")
+	_, err := store.GetCancellation("launch")
+	assert.NoError(t, err)
+
+	// Messages that are not pending are not cancelled through their ID.
+	sent := &kv.SentMessage{SourceID: "launch", Type: "slack", Destination: "#general", Status: kv.StatusSent}
+	require.NoError(t, store.AddSentMessage("campaign", "launch", sent))
+	assert.ErrorContains(t, doSentCancel(store, &out, sent.ShortID, "jane", "", false, call.ScheduledAt), "could not find a scheduled call")
+}
@@ -15,9 +15,10 @@ var callID string
 
 // sentDeleteCmd represents the sent delete command
 var sentDeleteCmd = &cobra.Command{
-	Use:   "delete",
-	Short: "Delete a sent call.",
-	Long:  `Delete a sent call.`,
+	Use:         "delete",
+	Short:       "Delete a sent call.",
+	Long:        `Delete a sent call.`,
+	Annotations: mutating,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		store, err := datastore.NewStore(false)
 		if err != nil {
@@ -35,7 +36,7 @@ var sentDeleteCmd = &cobra.Command{
 
 		if sm.Type == "slack" {
 			client := slack.NewClient(viper.GetString("slack.app.token"))
-			if err := client.DeleteMessage(sm.Destination, sm.Timestamp); err != nil {
+			if err := client.DeleteMessage(cmd.Context(), sm.Destination, sm.Timestamp); err != nil {
 				return fmt.Errorf("failed to delete message from slack: %w", err)
 			}
 		}
@@ -0,0 +1,151 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/andrewhowdencom/ruf/internal/datastore"
+	"github.com/andrewhowdencom/ruf/internal/kv"
+	"github.com/pmezard/go-difflib/difflib"
+	"github.com/spf13/cobra"
+)
+
+var diffColor bool
+
+// ANSI escapes used to colour a diff.
+const (
+	ansiRed   = "\x1b[31m"
+	ansiGreen = "\x1b[32m"
+	ansiCyan  = "\x1b[36m"
+	ansiReset = "\x1b[0m"
+)
+
+// sentDiffCmd represents the sent diff command
+var sentDiffCmd = &cobra.Command{
+	Use:   "diff <id1> <id2>",
+	Short: "Show how the content of two sent calls differs.",
+	Long: `Show how the content of two sent calls differs, as a unified diff of their subjects and of their
+content after its template was rendered. It is useful to check that a recurring call whose template reads
+live data, such as a weekly report, changed between occurrences.
+
+The calls are given by their IDs or short IDs. Their content is only recorded if worker.record_content is
+enabled.`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store, err := datastore.NewStore(true)
+		if err != nil {
+			return fmt.Errorf("failed to create a new datastore: %w", err)
+		}
+		defer store.Close()
+
+		return doSentDiff(store, cmd.OutOrStdout(), args[0], args[1], diffColor)
+	},
+}
+
+func doSentDiff(store kv.Storer, w io.Writer, id1, id2 string, color bool) error {
+	a, err := findSentMessage(store, id1)
+	if err != nil {
+		return err
+	}
+	b, err := findSentMessage(store, id2)
+	if err != nil {
+		return err
+	}
+
+	textA, err := snapshotText(a)
+	if err != nil {
+		return err
+	}
+	textB, err := snapshotText(b)
+	if err != nil {
+		return err
+	}
+
+	diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(textA),
+		B:        difflib.SplitLines(textB),
+		FromFile: diffLabel(a),
+		ToFile:   diffLabel(b),
+		Context:  3,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to diff the content: %w", err)
+	}
+	if diff == "" {
+		fmt.Fprintln(w, "The content of the two calls is identical.")
+		return nil
+	}
+	if color {
+		diff = colorDiff(diff)
+	}
+	fmt.Fprint(w, diff)
+	return nil
+}
+
+// findSentMessage finds a sent message by its ID or short ID.
+func findSentMessage(store kv.Storer, id string) (*kv.SentMessage, error) {
+	sm, err := store.GetSentMessage(id)
+	if errors.Is(err, kv.ErrNotFound) {
+		sm, err = store.GetSentMessageByShortID(id)
+	}
+	if errors.Is(err, kv.ErrNotFound) {
+		return nil, fmt.Errorf("could not find a call with ID '%s'", id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sent message '%s': %w", id, err)
+	}
+	return sm, nil
+}
+
+// snapshotText returns the subject and content a sent message was sent with, as the text they are
+// diffed as.
+func snapshotText(sm *kv.SentMessage) (string, error) {
+	if sm.Snapshot == nil || sm.Snapshot.Content == "" {
+		return "", fmt.Errorf("the content of '%s' was not recorded; enable worker.record_content to record it", sm.ID)
+	}
+	text := sm.Snapshot.Content
+	if sm.Snapshot.Subject != "" {
+		text = "Subject: " + sm.Snapshot.Subject + "\n\n" + text
+	}
+	// Each line is terminated when the text is split, so a final newline would add an empty line.
+	return strings.TrimSuffix(text, "\n"), nil
+}
+
+// diffLabel names a sent message in the header of a diff.
+func diffLabel(sm *kv.SentMessage) string {
+	at := sm.SentAt
+	if at.IsZero() {
+		at = sm.ScheduledAt
+	}
+	return fmt.Sprintf("%s (%s to %s, %s)", sm.ShortID, sm.Type, sm.Destination, at.UTC().Format(time.RFC3339))
+}
+
+// colorDiff colours the removed, added and hunk lines of a unified diff.
+func colorDiff(diff string) string {
+	lines := strings.SplitAfter(diff, "\n")
+	for i, line := range lines {
+		var color string
+		switch {
+		case strings.HasPrefix(line, "---"), strings.HasPrefix(line, "+++"):
+			continue
+		case strings.HasPrefix(line, "-"):
+			color = ansiRed
+		case strings.HasPrefix(line, "+"):
+			color = ansiGreen
+		case strings.HasPrefix(line, "@@"):
+			color = ansiCyan
+		default:
+			continue
+		}
+		lines[i] = color + strings.TrimSuffix(line, "\n") + ansiReset + "\n"
+	}
+	return strings.Join(lines, "")
+}
+
+func init() {
+	sentCmd.AddCommand(sentDiffCmd)
+	sentDiffCmd.Flags().BoolVar(&diffColor, "color", false, "Colour the removed and added lines.")
+}
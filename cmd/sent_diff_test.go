@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/andrewhowdencom/ruf/internal/datastore"
+	"github.com/andrewhowdencom/ruf/internal/kv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSentDiff(t *testing.T) {
+	store := datastore.NewMockStore()
+	send := func(callID, subject, content string, at time.Time) *kv.SentMessage {
+		sm := &kv.SentMessage{Type: "slack", Destination: "#team", SentAt: at}
+		if content != "" {
+			sm.Snapshot = &kv.Snapshot{Subject: subject, Content: content}
+		}
+		require.NoError(t, store.AddSentMessage("reports", callID, sm))
+		return sm
+	}
+	first := send("weekly:cron:0 9 * * 1:2025-06-02T09:00:00Z:slack:#team", "Weekly report", "Signups: 120\nChurn: 3%\nSee you next week.", time.Date(2025, 6, 2, 9, 0, 0, 0, time.UTC))
+	second := send("weekly:cron:0 9 * * 1:2025-06-09T09:00:00Z:slack:#team", "Weekly report", "Signups: 135\nChurn: 3%\nSee you next week.", time.Date(2025, 6, 9, 9, 0, 0, 0, time.UTC))
+	unrecorded := send("weekly:cron:0 9 * * 1:2025-06-16T09:00:00Z:slack:#team", "", "", time.Date(2025, 6, 16, 9, 0, 0, 0, time.UTC))
+
+	var out bytes.Buffer
+	require.NoError(t, doSentDiff(store, &out, first.ID, second.ShortID, false))
+	assert.Equal(t, "--- "+first.ShortID+" (slack to #team, 2025-06-02T09:00:00Z)\n"+
+		"+++ "+second.ShortID+" (slack to #team, 2025-06-09T09:00:00Z)\n"+
+		"@@ -1,5 +1,5 @@\n"+
+		" Subject: Weekly report\n"+
+		" \n"+
+		"-Signups: 120\n"+
+		"+Signups: 135\n"+
+		" Churn: 3%\n"+
+		" See you next week.\n", out.String())
+
+	out.Reset()
+	require.NoError(t, doSentDiff(store, &out, first.ID, second.ID, true))
+	assert.Contains(t, out.String(), "\x1b[31m-Signups: 120\x1b[0m\n")
+	assert.Contains(t, out.String(), "\x1b[32m+Signups: 135\x1b[0m\n")
+
+	out.Reset()
+	require.NoError(t, doSentDiff(store, &out, first.ID, first.ShortID, false))
+	assert.Equal(t, "The content of the two calls is identical.\n", out.String())
+
+	assert.ErrorContains(t, doSentDiff(store, &out, first.ID, unrecorded.ID, false), "enable worker.record_content")
+	assert.ErrorContains(t, doSentDiff(store, &out, first.ID, "missing", false), "could not find a call with ID 'missing'")
+}
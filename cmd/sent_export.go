@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/andrewhowdencom/ruf/internal/datastore"
+	"github.com/andrewhowdencom/ruf/internal/export"
+	"github.com/andrewhowdencom/ruf/internal/kv"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// sentExportCmd represents the sent export command
+var sentExportCmd = &cobra.Command{
+	Use:   "export <id>",
+	Short: "Export a sent call as it was delivered.",
+	Long: `Export a sent call as it was delivered, for archival or legal requests.
+
+The payload is reconstructed from the content recorded when the call was sent. Calls whose content was not
+recorded are rendered again from their current definition, with the data recorded when they were sent.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store, err := datastore.NewStore(true)
+		if err != nil {
+			return fmt.Errorf("failed to create a new datastore: %w", err)
+		}
+		defer store.Close()
+
+		sm, err := store.GetSentMessage(args[0])
+		if err != nil {
+			if errors.Is(err, kv.ErrNotFound) {
+				return fmt.Errorf("could not find a call with ID '%s'", args[0])
+			}
+			return fmt.Errorf("failed to get sent message: %w", err)
+		}
+
+		if sm.Snapshot == nil || sm.Snapshot.Content == "" {
+			defID, _, _ := strings.Cut(sm.SourceID, ":")
+			call, err := findCall(cmd, defID)
+			if err != nil {
+				return fmt.Errorf("no content was recorded for '%s', and it cannot be rendered again: %w", args[0], err)
+			}
+			if sm.Snapshot, err = export.Render(call, sm); err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.ErrOrStderr(), "Warning: no content was recorded for '%s'; rendered again from the current definition of '%s'.\n", args[0], defID)
+		}
+
+		format, _ := cmd.Flags().GetString("format")
+		out, err := export.Message(sm, format, export.WithFrom(viper.GetString("email.from")))
+		if err != nil {
+			return err
+		}
+		_, err = cmd.OutOrStdout().Write(out)
+		return err
+	},
+}
+
+func init() {
+	sentCmd.AddCommand(sentExportCmd)
+	sentExportCmd.Flags().String("format", export.FormatMarkdown, "The format to export in: eml, blocks or markdown")
+}
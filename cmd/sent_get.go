@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/andrewhowdencom/ruf/internal/datastore"
+	"github.com/andrewhowdencom/ruf/internal/kv"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+// sentGetCmd represents the sent get command
+var sentGetCmd = &cobra.Command{
+	Use:   "get <id|short-id>",
+	Short: "Show a sent call, including its delivery metadata.",
+	Long: `Show a sent call: the metadata reported by the provider that delivered it, what was rendered
+and sent, if the worker recorded it, and each status it has had.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store, err := datastore.NewStore(true)
+		if err != nil {
+			return fmt.Errorf("failed to create a new datastore: %w", err)
+		}
+		defer store.Close()
+
+		return doSentGet(store, cmd.OutOrStdout(), args[0])
+	},
+}
+
+func doSentGet(store kv.Storer, w io.Writer, id string) error {
+	sm, err := store.GetSentMessage(id)
+	if err != nil {
+		if errors.Is(err, kv.ErrNotFound) {
+			return fmt.Errorf("could not find a call with ID '%s'", id)
+		}
+		return fmt.Errorf("failed to get sent message: %w", err)
+	}
+
+	table := tablewriter.NewWriter(w)
+	table.Header("Field", "Value")
+	table.Append([]string{"ID", sm.ID})
+	table.Append([]string{"Short ID", sm.ShortID})
+	table.Append([]string{"Campaign", sm.CampaignName})
+	table.Append([]string{"Campaign ID", sm.CampaignID})
+	table.Append([]string{"Source ID", sm.SourceID})
+	table.Append([]string{"Type", sm.Type})
+	table.Append([]string{"Destination", sm.Destination})
+	table.Append([]string{"Status", string(sm.Status)})
+	table.Append([]string{"Scheduled At", sm.ScheduledAt.String()})
+	if !sm.OccurredAt.IsZero() && !sm.OccurredAt.Equal(sm.ScheduledAt) {
+		table.Append([]string{"Occurred At", sm.OccurredAt.String()})
+	}
+	if !sm.SentAt.IsZero() {
+		table.Append([]string{"Sent At", sm.SentAt.String()})
+	}
+	if !sm.DeleteAt.IsZero() {
+		table.Append([]string{"Delete At", sm.DeleteAt.String()})
+	}
+	table.Append([]string{"Message ID", sm.MessageID})
+	if sm.Timestamp != "" {
+		table.Append([]string{"Timestamp", sm.Timestamp})
+	}
+	if sm.ThreadID != "" {
+		table.Append([]string{"Thread ID", sm.ThreadID})
+	}
+	table.Append([]string{"Permalink", sm.Permalink})
+	table.Append([]string{"Latency", sm.Latency.String()})
+	table.Append([]string{"Retries", fmt.Sprint(sm.Retries)})
+	table.Append([]string{"Error", sm.Error})
+	if err := table.Render(); err != nil {
+		return err
+	}
+
+	// What was rendered is printed as it is, rather than in a table that would wrap it.
+	if snapshot := sm.Snapshot; snapshot != nil {
+		fmt.Fprintln(w, "\nRendered:")
+		if snapshot.Author != "" {
+			fmt.Fprintf(w, "Author: %s\n", snapshot.Author)
+		}
+		fmt.Fprintf(w, "Subject: %s\n\n%s\n", snapshot.Subject, snapshot.Content)
+		if len(snapshot.Data) > 0 {
+			data, err := json.MarshalIndent(snapshot.Data, "", "  ")
+			if err != nil {
+				return fmt.Errorf("failed to encode the data of the sent message: %w", err)
+			}
+			fmt.Fprintf(w, "\nData:\n%s\n", data)
+		}
+	}
+
+	if len(sm.History) > 0 {
+		fmt.Fprintln(w, "\nHistory:")
+		table := tablewriter.NewWriter(w)
+		table.Header("At", "Status", "Error")
+		for _, change := range sm.History {
+			at := "unknown"
+			if !change.At.IsZero() {
+				at = change.At.Format(time.RFC3339)
+			}
+			table.Append([]string{at, string(change.Status), change.Error})
+		}
+		if err := table.Render(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func init() {
+	sentCmd.AddCommand(sentGetCmd)
+}
@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/andrewhowdencom/ruf/internal/clients/slack"
+	"github.com/andrewhowdencom/ruf/internal/datastore"
+	"github.com/andrewhowdencom/ruf/internal/kv"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSentGet(t *testing.T) {
+	store := datastore.NewMockStore()
+	sm := &kv.SentMessage{
+		Type:        "slack",
+		Destination: "#general",
+		ScheduledAt: time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC),
+		Status:      kv.StatusSending,
+	}
+	assert.NoError(t, store.AddSentMessage("campaign", "launch", sm))
+	sent := *sm
+	sent.Status, sent.MessageID, sent.History = kv.StatusSent, "1234567890.123456", nil
+	sent.Snapshot = &kv.Snapshot{Subject: "Launch", Content: "We are live", Data: map[string]interface{}{"version": "v2"}}
+	assert.NoError(t, store.AddSentMessage("campaign", "launch", &sent))
+
+	var out bytes.Buffer
+	assert.NoError(t, doSentGet(store, &out, sm.ShortID))
+	assert.Contains(t, out.String(), "1234567890.123456")
+	assert.Contains(t, out.String(), "We are live")
+	assert.Contains(t, out.String(), `"version": "v2"`)
+	assert.Regexp(t, `(?s)History:.*sending.*sent`, out.String())
+
+	assert.ErrorContains(t, doSentGet(store, &out, "missing"), "could not find")
+}
+
+func TestSentUnsend(t *testing.T) {
+	store := datastore.NewMockStore()
+	slackClient := slack.NewMockClient()
+	var deleted []string
+	slackClient.DeleteMessageFunc = func(_ context.Context, channel, timestamp string) error {
+		deleted = append(deleted, channel, timestamp)
+		return nil
+	}
+	sm := &kv.SentMessage{Type: "slack", Destination: "#general", Timestamp: "1234567890.123456", Status: kv.StatusSent}
+	assert.NoError(t, store.AddSentMessage("campaign", "launch", sm))
+	mail := &kv.SentMessage{Type: "email", Destination: "list@example.com", Status: kv.StatusSent}
+	assert.NoError(t, store.AddSentMessage("campaign", "launch", mail))
+
+	var out bytes.Buffer
+	assert.NoError(t, doSentUnsend(context.Background(), store, slackClient, &out, sm.ShortID))
+	assert.Equal(t, []string{"#general", "1234567890.123456"}, deleted)
+	got, err := store.GetSentMessage(sm.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, kv.StatusDeleted, got.Status)
+
+	// A message that is already deleted, or was sent where messages cannot be deleted, is left alone.
+	assert.ErrorContains(t, doSentUnsend(context.Background(), store, slackClient, &out, sm.ShortID), "only sent calls")
+	assert.ErrorContains(t, doSentUnsend(context.Background(), store, slackClient, &out, mail.ShortID), "cannot be deleted")
+
+	// A message Slack would not delete stays recorded as sent.
+	failing := &kv.SentMessage{Type: "slack", Destination: "#random", Timestamp: "1", Status: kv.StatusSent}
+	assert.NoError(t, store.AddSentMessage("campaign", "launch", failing))
+	slackClient.DeleteMessageFunc = func(_ context.Context, channel, timestamp string) error {
+		return errors.New("message_not_found")
+	}
+	assert.ErrorContains(t, doSentUnsend(context.Background(), store, slackClient, &out, failing.ShortID), "message_not_found")
+	assert.Equal(t, kv.StatusSent, failing.Status)
+}
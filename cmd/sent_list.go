@@ -1,45 +1,177 @@
 package cmd
 
 import (
+	"encoding/csv"
+	"encoding/json"
 	"fmt"
-	"os"
+	"io"
+	"strconv"
+	"time"
 
 	"github.com/andrewhowdencom/ruf/internal/datastore"
+	"github.com/andrewhowdencom/ruf/internal/kv"
+	"github.com/ghodss/yaml"
 	"github.com/olekukonko/tablewriter"
 	"github.com/spf13/cobra"
 )
 
+// The formats sent calls can be listed in.
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+	outputYAML  = "yaml"
+	outputCSV   = "csv"
+)
+
 // sentListCmd represents the sent list command
 var sentListCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List all sent calls.",
-	Long:  `List all sent calls.`,
+	Long: `List all sent calls, most recently scheduled first.
+
+With --destination, only the calls sent to that destination are listed. These are read from an
+index of each destination's history, so they are listed quickly no matter how many calls have been
+sent elsewhere.
+
+With --campaign, only the calls sent for that campaign are listed. The campaign is recorded with
+each call as it is sent, so its history can be listed even after the campaign has been removed from
+its source.
+
+--status and --type narrow the list further, and --since and --until bound when the calls were
+scheduled, each as a date (YYYY-MM-DD, read as midnight UTC), an RFC 3339 time, or a duration
+before now, such as 24h.
+
+--output lists the calls as a table, or as json, yaml or csv for other tools to read.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
+		destination, _ := cmd.Flags().GetString("destination")
+		campaign, _ := cmd.Flags().GetString("campaign")
+		destType, _ := cmd.Flags().GetString("type")
+		statuses, _ := cmd.Flags().GetStringSlice("status")
+		since, _ := cmd.Flags().GetString("since")
+		until, _ := cmd.Flags().GetString("until")
+		last, _ := cmd.Flags().GetInt("last")
+		output, _ := cmd.Flags().GetString("output")
+
+		q := kv.SentQuery{CampaignID: campaign, Destination: destination, Type: destType, Limit: last}
+		for _, status := range statuses {
+			q.Statuses = append(q.Statuses, kv.Status(status))
+		}
+		now := time.Now().UTC()
+		var err error
+		if q.Since, err = parseTimeFlag("since", since, now); err != nil {
+			return err
+		}
+		if q.Until, err = parseTimeFlag("until", until, now); err != nil {
+			return err
+		}
+
 		store, err := datastore.NewStore(true)
 		if err != nil {
 			return fmt.Errorf("failed to create a new datastore: %w", err)
 		}
 		defer store.Close()
 
-		messages, err := store.ListSentMessages()
+		return doSentList(store, cmd.OutOrStdout(), q, output)
+	},
+}
+
+func doSentList(store kv.Storer, w io.Writer, q kv.SentQuery, output string) error {
+	switch output {
+	case "", outputTable, outputJSON, outputYAML, outputCSV:
+	default:
+		return fmt.Errorf("invalid output '%s': must be %s, %s, %s or %s", output, outputTable, outputJSON, outputYAML, outputCSV)
+	}
+
+	messages, err := kv.QuerySentMessages(store, q)
+	if err != nil {
+		return fmt.Errorf("failed to list sent messages: %w", err)
+	}
+
+	switch output {
+	case outputJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(messages)
+	case outputYAML:
+		b, err := yaml.Marshal(messages)
 		if err != nil {
-			return fmt.Errorf("failed to list sent messages: %w", err)
+			return fmt.Errorf("failed to encode sent messages: %w", err)
 		}
+		_, err = w.Write(b)
+		return err
+	case outputCSV:
+		return writeSentCSV(w, messages)
+	}
 
-		// TODO: Investigate why tablewriter dependency update is not working.
-		table := tablewriter.NewWriter(os.Stdout)
-		table.Header("ID", "Short ID", "Campaign", "Status", "Source ID", "Scheduled At", "Timestamp")
+	// TODO: Investigate why tablewriter dependency update is not working.
+	table := tablewriter.NewWriter(w)
+	table.Header("ID", "Short ID", "Campaign", "Status", "Source ID", "Scheduled At", "Timestamp")
 
-		for _, m := range messages {
-			table.Append([]string{m.ID, m.ShortID, m.CampaignName, string(m.Status), m.SourceID, m.ScheduledAt.String(), m.Timestamp})
-		}
+	for _, m := range messages {
+		table.Append([]string{m.ID, m.ShortID, m.CampaignName, string(m.Status), m.SourceID, m.ScheduledAt.String(), m.Timestamp})
+	}
 
-		table.Render()
+	return table.Render()
+}
 
-		return nil
-	},
+// writeSentCSV writes the sent messages as CSV, with a header row, leaving out their snapshots.
+func writeSentCSV(w io.Writer, messages []*kv.SentMessage) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"id", "short_id", "campaign_id", "campaign_name", "source_id", "type", "destination", "status", "scheduled_at", "sent_at", "message_id", "permalink", "retries", "error"})
+	for _, m := range messages {
+		cw.Write([]string{
+			m.ID, m.ShortID, m.CampaignID, m.CampaignName, m.SourceID, m.Type, m.Destination, string(m.Status),
+			formatTime(m.ScheduledAt), formatTime(m.SentAt), m.MessageID, m.Permalink, strconv.Itoa(m.Retries), m.Error,
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// formatTime formats a time as RFC 3339, or as nothing if it is zero.
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+// parseTimeFlag parses the value of a flag that is a time: a date, read as midnight UTC, an RFC 3339
+// time, or a duration before now. An empty value is the zero time.
+func parseTimeFlag(name, s string, now time.Time) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if d, err := time.ParseDuration(s); err == nil {
+		return now.Add(-d), nil
+	}
+	if t, err := time.Parse(time.DateOnly, s); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid --%s '%s': must be a date (YYYY-MM-DD), an RFC 3339 time or a duration", name, s)
+	}
+	return t.UTC(), nil
+}
+
+// parseLaterTimeFlag parses the value of a flag that is a time like parseTimeFlag, except that a duration
+// is after now rather than before it.
+func parseLaterTimeFlag(name, s string, now time.Time) (time.Time, error) {
+	if d, err := time.ParseDuration(s); err == nil {
+		return now.Add(d), nil
+	}
+	return parseTimeFlag(name, s, now)
 }
 
 func init() {
 	sentCmd.AddCommand(sentListCmd)
+	sentListCmd.Flags().String("destination", "", "Only list calls sent to this destination, such as '#general'.")
+	sentListCmd.Flags().String("campaign", "", "Only list calls sent for the campaign with this ID.")
+	sentListCmd.Flags().String("type", "", "Only list calls sent to this destination type, such as 'slack'.")
+	sentListCmd.Flags().StringSlice("status", nil, "Only list calls with these statuses, such as 'failed'.")
+	sentListCmd.Flags().String("since", "", "Only list calls scheduled at or after this date, time or duration before now.")
+	sentListCmd.Flags().String("until", "", "Only list calls scheduled before this date, time or duration before now.")
+	sentListCmd.Flags().Int("last", 0, "Only list the most recently scheduled calls, up to this many.")
+	sentListCmd.Flags().StringP("output", "o", outputTable, "The format to list in: table, json, yaml or csv.")
 }
@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/andrewhowdencom/ruf/internal/datastore"
+	"github.com/andrewhowdencom/ruf/internal/kv"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSentList(t *testing.T) {
+	store := datastore.NewMockStore()
+	start := time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC)
+	assert.NoError(t, store.AddSentMessage("campaign", "old", &kv.SentMessage{Type: "slack", Destination: "#general", ScheduledAt: start, SourceID: "old"}))
+	assert.NoError(t, store.AddSentMessage("campaign", "new", &kv.SentMessage{Type: "slack", Destination: "#general", ScheduledAt: start.Add(time.Hour), SourceID: "new"}))
+	assert.NoError(t, store.AddSentMessage("campaign", "other", &kv.SentMessage{Type: "slack", Destination: "#random", ScheduledAt: start, SourceID: "other"}))
+
+	var out bytes.Buffer
+	assert.NoError(t, doSentList(store, &out, kv.SentQuery{Destination: "#general", Limit: 1}, ""))
+	assert.Contains(t, out.String(), "campaign@new@slack@#general")
+	assert.NotContains(t, out.String(), "campaign@old@slack@#general")
+	assert.NotContains(t, out.String(), "#random")
+
+	out.Reset()
+	assert.NoError(t, doSentList(store, &out, kv.SentQuery{}, ""))
+	assert.Contains(t, out.String(), "#random")
+}
+
+func TestSentList_Campaign(t *testing.T) {
+	store := datastore.NewMockStore()
+	start := time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC)
+	assert.NoError(t, store.AddSentMessage("retired", "launch", &kv.SentMessage{Type: "slack", Destination: "#general", ScheduledAt: start, CampaignName: "Retired"}))
+	assert.NoError(t, store.AddSentMessage("retired", "launch", &kv.SentMessage{Type: "slack", Destination: "#random", ScheduledAt: start.Add(time.Hour), CampaignName: "Retired"}))
+	assert.NoError(t, store.AddSentMessage("current", "launch", &kv.SentMessage{Type: "slack", Destination: "#general", ScheduledAt: start.Add(2 * time.Hour), CampaignName: "Current"}))
+
+	var out bytes.Buffer
+	assert.NoError(t, doSentList(store, &out, kv.SentQuery{CampaignID: "retired"}, ""))
+	assert.Contains(t, out.String(), "retired@launch@slack@#general")
+	assert.Contains(t, out.String(), "retired@launch@slack@#random")
+	assert.NotContains(t, out.String(), "current@")
+
+	out.Reset()
+	assert.NoError(t, doSentList(store, &out, kv.SentQuery{Destination: "#general", CampaignID: "retired", Limit: 1}, ""))
+	assert.Contains(t, out.String(), "retired@launch@slack@#general")
+	assert.NotContains(t, out.String(), "current@")
+	assert.NotContains(t, out.String(), "#random")
+
+	out.Reset()
+	assert.NoError(t, doSentCampaigns(store, &out))
+	assert.Regexp(t, `retired\s+.*Retired\s+.*2`, out.String())
+	assert.Regexp(t, `current\s+.*Current\s+.*1`, out.String())
+}
+
+func TestSentList_Filters(t *testing.T) {
+	store := datastore.NewMockStore()
+	start := time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC)
+	assert.NoError(t, store.AddSentMessage("campaign", "sent", &kv.SentMessage{Type: "slack", Destination: "#general", ScheduledAt: start, Status: kv.StatusSent}))
+	assert.NoError(t, store.AddSentMessage("campaign", "failed", &kv.SentMessage{Type: "slack", Destination: "#general", ScheduledAt: start.Add(time.Hour), Status: kv.StatusFailed, Error: "channel_not_found"}))
+	assert.NoError(t, store.AddSentMessage("campaign", "email", &kv.SentMessage{Type: "email", Destination: "list@example.com", ScheduledAt: start.Add(2 * time.Hour), Status: kv.StatusFailed}))
+
+	var out bytes.Buffer
+	assert.NoError(t, doSentList(store, &out, kv.SentQuery{Statuses: []kv.Status{kv.StatusFailed}, Type: "slack"}, outputJSON))
+	var messages []*kv.SentMessage
+	assert.NoError(t, json.Unmarshal(out.Bytes(), &messages))
+	if assert.Len(t, messages, 1) {
+		assert.Equal(t, "channel_not_found", messages[0].Error)
+	}
+
+	out.Reset()
+	assert.NoError(t, doSentList(store, &out, kv.SentQuery{Since: start.Add(time.Hour), Until: start.Add(2 * time.Hour)}, outputCSV))
+	records, err := csv.NewReader(&out).ReadAll()
+	assert.NoError(t, err)
+	if assert.Len(t, records, 2) {
+		assert.Equal(t, "id", records[0][0])
+		assert.Equal(t, "campaign@failed@slack@#general", records[1][0])
+	}
+
+	out.Reset()
+	assert.NoError(t, doSentList(store, &out, kv.SentQuery{Type: "email"}, outputYAML))
+	assert.Contains(t, out.String(), "destination: list@example.com")
+	assert.NotContains(t, out.String(), "#general")
+
+	assert.Error(t, doSentList(store, &out, kv.SentQuery{}, "xml"))
+}
+
+func TestParseTimeFlag(t *testing.T) {
+	now := time.Date(2025, 1, 2, 9, 0, 0, 0, time.UTC)
+
+	got, err := parseTimeFlag("since", "24h", now)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC), got)
+
+	got, err = parseTimeFlag("since", "2025-01-01", now)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), got)
+
+	got, err = parseTimeFlag("since", "", now)
+	assert.NoError(t, err)
+	assert.True(t, got.IsZero())
+
+	_, err = parseTimeFlag("since", "yesterday", now)
+	assert.ErrorContains(t, err, "--since")
+}
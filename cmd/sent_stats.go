@@ -0,0 +1,237 @@
+package cmd
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/andrewhowdencom/ruf/internal/datastore"
+	"github.com/andrewhowdencom/ruf/internal/kv"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+// What sent calls can be grouped by for their statistics.
+const (
+	statsByCampaign    = "campaign"
+	statsByType        = "type"
+	statsByDestination = "destination"
+	statsByStatus      = "status"
+)
+
+// The periods sent calls can be grouped into for their statistics.
+const (
+	periodDay  = "day"
+	periodWeek = "week"
+)
+
+// sentStatsCmd represents the sent stats command
+var sentStatsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Report how many calls have been sent, and how many failed.",
+	Long: `Report how many calls have been sent, and how many failed, from the history in the datastore.
+
+The calls are grouped by what --by lists: campaign, type, destination and status, and with --period
+by the day or the week (starting on Monday, in UTC) they were scheduled for. Each group is counted,
+with how many of its calls were sent and how many failed. The failure rate is of the calls that were
+sent or failed, leaving out those that were cancelled, held or kept back by a trial.
+
+--campaign, --type, --since and --until select the calls as they do for 'ruf sent list', and --output
+reports as a table or as json.
+
+Example:
+  ruf sent stats --by campaign --period week --since 720h`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		campaign, _ := cmd.Flags().GetString("campaign")
+		destType, _ := cmd.Flags().GetString("type")
+		since, _ := cmd.Flags().GetString("since")
+		until, _ := cmd.Flags().GetString("until")
+		by, _ := cmd.Flags().GetStringSlice("by")
+		period, _ := cmd.Flags().GetString("period")
+		output, _ := cmd.Flags().GetString("output")
+
+		q := kv.SentQuery{CampaignID: campaign, Type: destType}
+		now := time.Now().UTC()
+		var err error
+		if q.Since, err = parseTimeFlag("since", since, now); err != nil {
+			return err
+		}
+		if q.Until, err = parseTimeFlag("until", until, now); err != nil {
+			return err
+		}
+
+		store, err := datastore.NewStore(true)
+		if err != nil {
+			return fmt.Errorf("failed to create a new datastore: %w", err)
+		}
+		defer store.Close()
+
+		return doSentStats(store, cmd.OutOrStdout(), q, by, period, output)
+	},
+}
+
+// sentStats counts the sent calls of a group. The fields the calls were not grouped by are empty.
+type sentStats struct {
+	Period      string    `json:"period,omitempty"`
+	CampaignID  string    `json:"campaign_id,omitempty"`
+	Type        string    `json:"type,omitempty"`
+	Destination string    `json:"destination,omitempty"`
+	Status      kv.Status `json:"status,omitempty"`
+	Total       int       `json:"total"`
+	Sent        int       `json:"sent"`
+	Failed      int       `json:"failed"`
+	FailureRate float64   `json:"failure_rate"`
+}
+
+func doSentStats(store kv.Storer, w io.Writer, q kv.SentQuery, by []string, period, output string) error {
+	for _, b := range by {
+		switch b {
+		case statsByCampaign, statsByType, statsByDestination, statsByStatus:
+		default:
+			return fmt.Errorf("invalid --by '%s': must be %s, %s, %s or %s", b, statsByCampaign, statsByType, statsByDestination, statsByStatus)
+		}
+	}
+	switch period {
+	case "", periodDay, periodWeek:
+	default:
+		return fmt.Errorf("invalid --period '%s': must be %s or %s", period, periodDay, periodWeek)
+	}
+	switch output {
+	case "", outputTable, outputJSON:
+	default:
+		return fmt.Errorf("invalid output '%s': must be %s or %s", output, outputTable, outputJSON)
+	}
+
+	messages, err := kv.QuerySentMessages(store, q)
+	if err != nil {
+		return fmt.Errorf("failed to list sent messages: %w", err)
+	}
+	stats := aggregateSent(messages, by, period)
+
+	if output == outputJSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(stats)
+	}
+
+	// Only the columns of what the calls were grouped by are shown.
+	var header []any
+	if period != "" {
+		header = append(header, strings.ToUpper(period[:1])+period[1:])
+	}
+	for _, b := range by {
+		header = append(header, strings.ToUpper(b[:1])+b[1:])
+	}
+	table := tablewriter.NewWriter(w)
+	table.Header(append(header, "Total", "Sent", "Failed", "Failure Rate")...)
+	for _, s := range stats {
+		var row []string
+		if period != "" {
+			row = append(row, s.Period)
+		}
+		for _, b := range by {
+			row = append(row, s.group(b))
+		}
+		row = append(row, strconv.Itoa(s.Total), strconv.Itoa(s.Sent), strconv.Itoa(s.Failed), fmt.Sprintf("%.1f%%", s.FailureRate*100))
+		table.Append(row)
+	}
+	return table.Render()
+}
+
+// aggregateSent groups the messages by the fields of by and the period they were scheduled in, and counts
+// each group. The groups are ordered by period, then by the fields they were grouped by.
+func aggregateSent(messages []*kv.SentMessage, by []string, period string) []*sentStats {
+	groups := make(map[sentStats]*sentStats)
+	for _, m := range messages {
+		key := sentStats{Period: statsPeriod(m.ScheduledAt, period)}
+		for _, b := range by {
+			switch b {
+			case statsByCampaign:
+				key.CampaignID = m.CampaignID
+			case statsByType:
+				key.Type = m.Type
+			case statsByDestination:
+				key.Destination = m.Destination
+			case statsByStatus:
+				key.Status = m.Status
+			}
+		}
+		s, ok := groups[key]
+		if !ok {
+			s = &key
+			groups[key] = s
+		}
+		s.Total++
+		switch {
+		case m.Failed():
+			s.Failed++
+		case m.Status == kv.StatusSent, m.Status == kv.StatusExpired:
+			// An expired message was sent, and deleted once it was no longer needed.
+			s.Sent++
+		}
+	}
+
+	stats := make([]*sentStats, 0, len(groups))
+	for _, s := range groups {
+		if attempted := s.Sent + s.Failed; attempted > 0 {
+			s.FailureRate = float64(s.Failed) / float64(attempted)
+		}
+		stats = append(stats, s)
+	}
+	slices.SortFunc(stats, func(a, b *sentStats) int {
+		return cmp.Or(
+			cmp.Compare(a.Period, b.Period),
+			cmp.Compare(a.CampaignID, b.CampaignID),
+			cmp.Compare(a.Type, b.Type),
+			cmp.Compare(a.Destination, b.Destination),
+			cmp.Compare(a.Status, b.Status),
+		)
+	})
+	return stats
+}
+
+// group returns the value of the field of the statistics that the calls were grouped by.
+func (s *sentStats) group(by string) string {
+	switch by {
+	case statsByCampaign:
+		return s.CampaignID
+	case statsByType:
+		return s.Type
+	case statsByDestination:
+		return s.Destination
+	default:
+		return string(s.Status)
+	}
+}
+
+// statsPeriod returns the date of the period a call scheduled at t falls in: its day, or the Monday of its
+// week. Without a period, it is empty.
+func statsPeriod(t time.Time, period string) string {
+	t = t.UTC()
+	switch period {
+	case periodDay:
+		return t.Format(time.DateOnly)
+	case periodWeek:
+		// Weeks start on Monday, as they do in ISO 8601.
+		offset := (int(t.Weekday()) + 6) % 7
+		return t.AddDate(0, 0, -offset).Format(time.DateOnly)
+	default:
+		return ""
+	}
+}
+
+func init() {
+	sentCmd.AddCommand(sentStatsCmd)
+	sentStatsCmd.Flags().StringSlice("by", []string{statsByCampaign, statsByType}, "What to group calls by: campaign, type, destination or status.")
+	sentStatsCmd.Flags().String("period", "", "Group calls by the day or week they were scheduled for.")
+	sentStatsCmd.Flags().String("campaign", "", "Only count calls sent for the campaign with this ID.")
+	sentStatsCmd.Flags().String("type", "", "Only count calls sent to this destination type, such as 'slack'.")
+	sentStatsCmd.Flags().String("since", "", "Only count calls scheduled at or after this date, time or duration before now.")
+	sentStatsCmd.Flags().String("until", "", "Only count calls scheduled before this date, time or duration before now.")
+	sentStatsCmd.Flags().StringP("output", "o", outputTable, "The format to report in: table or json.")
+}
@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/andrewhowdencom/ruf/internal/datastore"
+	"github.com/andrewhowdencom/ruf/internal/kv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSentStats(t *testing.T) {
+	store := datastore.NewMockStore()
+	monday := time.Date(2025, 6, 2, 9, 0, 0, 0, time.UTC)
+	add := func(campaign, call, destType string, status kv.Status, at time.Time) {
+		require.NoError(t, store.AddSentMessage(campaign, call, &kv.SentMessage{Type: destType, Destination: "#general", Status: status, ScheduledAt: at}))
+	}
+	add("team", "standup-1", "slack", kv.StatusSent, monday)
+	add("team", "standup-2", "slack", kv.StatusFailed, monday.Add(24*time.Hour))
+	add("team", "standup-3", "slack", kv.StatusCancelled, monday.Add(48*time.Hour))
+	add("team", "standup-4", "slack", kv.StatusExpired, monday.AddDate(0, 0, 7))
+	add("launch", "announce", "email", kv.StatusSent, monday)
+
+	t.Run("groups by campaign", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, doSentStats(store, &buf, kv.SentQuery{}, []string{statsByCampaign}, "", outputJSON))
+		var stats []sentStats
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &stats))
+		assert.Equal(t, []sentStats{
+			{CampaignID: "launch", Total: 1, Sent: 1},
+			// The cancelled call is counted, but not towards the failure rate.
+			{CampaignID: "team", Total: 4, Sent: 2, Failed: 1, FailureRate: 1.0 / 3},
+		}, stats)
+	})
+
+	t.Run("groups by week", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, doSentStats(store, &buf, kv.SentQuery{CampaignID: "team"}, nil, periodWeek, outputJSON))
+		var stats []sentStats
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &stats))
+		require.Len(t, stats, 2)
+		assert.Equal(t, "2025-06-02", stats[0].Period)
+		assert.Equal(t, 3, stats[0].Total)
+		assert.Equal(t, "2025-06-09", stats[1].Period)
+		assert.Equal(t, 1, stats[1].Sent)
+	})
+
+	t.Run("writes a table", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, doSentStats(store, &buf, kv.SentQuery{}, []string{statsByType, statsByStatus}, periodDay, ""))
+		out := buf.String()
+		assert.Contains(t, out, "DAY")
+		assert.Contains(t, out, "2025-06-03")
+		assert.Contains(t, out, "100.0%")
+	})
+
+	t.Run("rejects an unknown grouping", func(t *testing.T) {
+		err := doSentStats(store, &bytes.Buffer{}, kv.SentQuery{}, []string{"author"}, "", "")
+		assert.ErrorContains(t, err, "invalid --by 'author'")
+	})
+}
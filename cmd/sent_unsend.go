@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/andrewhowdencom/ruf/internal/clients/slack"
+	"github.com/andrewhowdencom/ruf/internal/datastore"
+	"github.com/andrewhowdencom/ruf/internal/kv"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// sentUnsendCmd represents the sent unsend command
+var sentUnsendCmd = &cobra.Command{
+	Use:   "unsend <id|short-id>",
+	Short: "Delete a sent call from its destination.",
+	Long: `Delete a sent call from its destination, and record it as deleted.
+
+Only messages that were sent to Slack can be deleted; an email cannot be taken back once it has been
+delivered. The call is not sent again, as its occurrence is still recorded as settled.`,
+	Annotations: mutating,
+	Args:        cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store, err := datastore.NewStore(false)
+		if err != nil {
+			return fmt.Errorf("failed to create a new datastore: %w", err)
+		}
+		defer store.Close()
+
+		return doSentUnsend(cmd.Context(), store, slack.NewClient(viper.GetString("slack.app.token")), cmd.OutOrStdout(), args[0])
+	},
+}
+
+func doSentUnsend(ctx context.Context, store kv.Storer, slackClient slack.Client, w io.Writer, id string) error {
+	sm, err := store.GetSentMessage(id)
+	if err != nil {
+		if errors.Is(err, kv.ErrNotFound) {
+			return fmt.Errorf("could not find a call with ID '%s'", id)
+		}
+		return fmt.Errorf("failed to get sent message: %w", err)
+	}
+	if sm.Status != kv.StatusSent {
+		return fmt.Errorf("call '%s' is %s, only sent calls can be unsent", id, sm.Status)
+	}
+
+	switch sm.Type {
+	case "slack":
+		if err := slackClient.DeleteMessage(ctx, sm.Destination, sm.Timestamp); err != nil {
+			return fmt.Errorf("failed to delete message from slack: %w", err)
+		}
+	default:
+		return fmt.Errorf("call '%s' was sent to %s, which messages cannot be deleted from", id, sm.Type)
+	}
+
+	if err := store.DeleteSentMessage(sm.ID); err != nil {
+		return fmt.Errorf("failed to record the sent message as deleted: %w", err)
+	}
+
+	fmt.Fprintf(w, "Deleted call '%s' from %s and recorded it as deleted.\n", sm.ShortID, sm.Destination)
+	return nil
+}
+
+func init() {
+	sentCmd.AddCommand(sentUnsendCmd)
+}
@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/andrewhowdencom/ruf/internal/kv"
+	"github.com/spf13/cobra"
+)
+
+// seriesCmd represents the series command
+var seriesCmd = &cobra.Command{
+	Use:   "series",
+	Short: "Pause, resume, cancel and shift series of calls.",
+	Long: `Pause, resume, cancel and shift series of calls as a unit.
+
+A series is identified by its ID. Changes take effect the next time the schedule
+is refreshed, except that cancelling a series also cancels the steps that are
+already scheduled.`,
+}
+
+// seriesOverride returns the override of a series, or a new one if it has none.
+func seriesOverride(store kv.Storer, seriesID string) (*kv.SeriesOverride, error) {
+	overrides, err := store.ListSeriesOverrides()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list series overrides: %w", err)
+	}
+	for _, o := range overrides {
+		if o.SeriesID == seriesID {
+			return o, nil
+		}
+	}
+	return &kv.SeriesOverride{SeriesID: seriesID}, nil
+}
+
+// saveSeriesOverride stores the override of a series, or removes it once it changes nothing.
+func saveSeriesOverride(store kv.Storer, o *kv.SeriesOverride) error {
+	if o.Shift == 0 && !o.Paused && !o.Cancelled {
+		if err := store.DeleteSeriesOverride(o.SeriesID); err != nil && !errors.Is(err, kv.ErrNotFound) {
+			return fmt.Errorf("failed to delete series override: %w", err)
+		}
+		return nil
+	}
+	if err := store.SetSeriesOverride(o); err != nil {
+		return fmt.Errorf("failed to save series override: %w", err)
+	}
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(seriesCmd)
+}
@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/andrewhowdencom/ruf/internal/datastore"
+	"github.com/andrewhowdencom/ruf/internal/kv"
+	"github.com/spf13/cobra"
+)
+
+var (
+	seriesCancelBy     string
+	seriesCancelReason string
+)
+
+// seriesCancelCmd represents the series cancel command
+var seriesCancelCmd = &cobra.Command{
+	Use:   "cancel <series-id>",
+	Short: "Cancel the steps of a series that have not been sent.",
+	Long: `Cancel the steps of a series that have not been sent, for good.
+
+The steps that are already scheduled are cancelled as 'sent cancel' cancels a
+call, so they are recorded as cancelled, and the rest are no longer scheduled.`,
+	Annotations: mutating,
+	Args:        cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store, err := datastore.NewStore(false)
+		if err != nil {
+			return fmt.Errorf("failed to create a new datastore: %w", err)
+		}
+		defer store.Close()
+
+		return doSeriesCancel(store, cmd.OutOrStdout(), args[0], seriesCancelBy, seriesCancelReason, time.Now())
+	},
+}
+
+func doSeriesCancel(store kv.Storer, w io.Writer, seriesID, by, reason string, now time.Time) error {
+	o, err := seriesOverride(store, seriesID)
+	if err != nil {
+		return err
+	}
+	if o.Cancelled {
+		return fmt.Errorf("series '%s' is already cancelled", seriesID)
+	}
+
+	scheduled, err := store.ListScheduledCalls()
+	if err != nil {
+		return fmt.Errorf("failed to list scheduled calls: %w", err)
+	}
+	for _, c := range scheduled {
+		if c.Series != seriesID {
+			continue
+		}
+		if err := store.CancelCall(&kv.Cancellation{CallID: c.ID, By: by, Reason: reason, At: now.UTC()}); err != nil {
+			return fmt.Errorf("failed to cancel '%s': %w", c.ID, err)
+		}
+		fmt.Fprintf(w, "Cancelled call '%s' (%s).\n", c.ID, kv.GenerateShortID(c.ID))
+	}
+
+	o.Cancelled = true
+	if err := saveSeriesOverride(store, o); err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "Cancelled series '%s'.\n", seriesID)
+	return nil
+}
+
+func init() {
+	seriesCmd.AddCommand(seriesCancelCmd)
+	seriesCancelCmd.Flags().StringVar(&seriesCancelBy, "by", os.Getenv("USER"), "Who is cancelling the series.")
+	seriesCancelCmd.Flags().StringVar(&seriesCancelReason, "reason", "", "Why the series is cancelled.")
+}
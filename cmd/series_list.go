@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/andrewhowdencom/ruf/internal/datastore"
+	"github.com/andrewhowdencom/ruf/internal/kv"
+	"github.com/andrewhowdencom/ruf/internal/model"
+	"github.com/andrewhowdencom/ruf/internal/scheduler"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// seriesListCmd represents the series list command
+var seriesListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the series of the sources, and the state of their steps.",
+	Long: `List the series of the sources, and when each of their steps is sent once any
+shift is applied, along with whether the step was sent or the series is paused or cancelled.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		s, err := buildSourcer()
+		if err != nil {
+			return fmt.Errorf("failed to build sourcer: %w", err)
+		}
+
+		var series []model.Series
+		for _, url := range viper.GetStringSlice("source.urls") {
+			source, _, err := s.Source(url)
+			if err != nil {
+				fmt.Fprintf(cmd.ErrOrStderr(), "Error sourcing from %s: %v\n", url, err)
+				continue
+			}
+			if source == nil {
+				continue
+			}
+			series = append(series, source.Series...)
+		}
+
+		store, err := datastore.NewStore(true)
+		if err != nil {
+			return fmt.Errorf("failed to create a new datastore: %w", err)
+		}
+		defer store.Close()
+
+		return doSeriesList(store, cmd.OutOrStdout(), series)
+	},
+}
+
+func doSeriesList(store kv.Storer, w io.Writer, series []model.Series) error {
+	if len(series) == 0 {
+		fmt.Fprintln(w, "No series found.")
+		return nil
+	}
+
+	overrides, err := store.ListSeriesOverrides()
+	if err != nil {
+		return fmt.Errorf("failed to list series overrides: %w", err)
+	}
+	byID := make(map[string]*kv.SeriesOverride, len(overrides))
+	for _, o := range overrides {
+		byID[o.SeriesID] = o
+	}
+
+	table := tablewriter.NewWriter(w)
+	table.Header("Series", "Step", "At", "Status")
+	for _, s := range series {
+		o := byID[s.ID]
+		if o == nil {
+			o = &kv.SeriesOverride{SeriesID: s.ID}
+		}
+		for _, step := range s.Steps {
+			offset, err := model.ParseOffset(step.Offset)
+			if err != nil {
+				return fmt.Errorf("series '%s': step '%s': %w", s.ID, step.ID, err)
+			}
+			_, sent, err := scheduler.SentAt(store, s.Campaign.ID, model.StepID(s.ID, step.ID))
+			if err != nil {
+				return err
+			}
+
+			status := "pending"
+			switch {
+			case sent:
+				status = "sent"
+			case o.Cancelled:
+				status = "cancelled"
+			case o.Paused:
+				status = "paused"
+			}
+			at := s.Anchor.Add(offset)
+			if !sent {
+				at = at.Add(o.Shift)
+			}
+			table.Append([]string{s.ID, step.ID, at.Format(time.RFC3339), status})
+		}
+	}
+	table.Render()
+	return nil
+}
+
+func init() {
+	seriesCmd.AddCommand(seriesListCmd)
+}
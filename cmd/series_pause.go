@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/andrewhowdencom/ruf/internal/datastore"
+	"github.com/andrewhowdencom/ruf/internal/kv"
+	"github.com/spf13/cobra"
+)
+
+// seriesPauseCmd represents the series pause command
+var seriesPauseCmd = &cobra.Command{
+	Use:   "pause <series-id>",
+	Short: "Stop the steps of a series from being scheduled.",
+	Long: `Stop the steps of a series from being scheduled until it is resumed.
+
+Steps that are already scheduled are removed when the schedule is next refreshed.`,
+	Annotations: mutating,
+	Args:        cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store, err := datastore.NewStore(false)
+		if err != nil {
+			return fmt.Errorf("failed to create a new datastore: %w", err)
+		}
+		defer store.Close()
+
+		return doSeriesPause(store, cmd.OutOrStdout(), args[0])
+	},
+}
+
+// seriesResumeCmd represents the series resume command
+var seriesResumeCmd = &cobra.Command{
+	Use:   "resume <series-id>",
+	Short: "Resume a series paused with 'series pause'.",
+	Long: `Resume a series paused with 'series pause'.
+
+Steps whose time passed while the series was paused are sent if they are still
+within worker.missed_lookback, and missed otherwise.`,
+	Annotations: mutating,
+	Args:        cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store, err := datastore.NewStore(false)
+		if err != nil {
+			return fmt.Errorf("failed to create a new datastore: %w", err)
+		}
+		defer store.Close()
+
+		return doSeriesResume(store, cmd.OutOrStdout(), args[0])
+	},
+}
+
+func doSeriesPause(store kv.Storer, w io.Writer, seriesID string) error {
+	o, err := seriesOverride(store, seriesID)
+	if err != nil {
+		return err
+	}
+	if o.Cancelled {
+		return fmt.Errorf("series '%s' is cancelled", seriesID)
+	}
+	o.Paused = true
+	if err := saveSeriesOverride(store, o); err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "Paused series '%s'.\n", seriesID)
+	return nil
+}
+
+func doSeriesResume(store kv.Storer, w io.Writer, seriesID string) error {
+	o, err := seriesOverride(store, seriesID)
+	if err != nil {
+		return err
+	}
+	if o.Cancelled {
+		return fmt.Errorf("series '%s' is cancelled, and cannot be resumed", seriesID)
+	}
+	if !o.Paused {
+		return fmt.Errorf("series '%s' is not paused", seriesID)
+	}
+	o.Paused = false
+	if err := saveSeriesOverride(store, o); err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "Resumed series '%s'.\n", seriesID)
+	return nil
+}
+
+func init() {
+	seriesCmd.AddCommand(seriesPauseCmd)
+	seriesCmd.AddCommand(seriesResumeCmd)
+}
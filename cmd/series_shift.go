@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/andrewhowdencom/ruf/internal/datastore"
+	"github.com/andrewhowdencom/ruf/internal/kv"
+	"github.com/andrewhowdencom/ruf/internal/model"
+	"github.com/spf13/cobra"
+)
+
+var seriesShiftBy string
+
+// seriesShiftCmd represents the series shift command
+var seriesShiftCmd = &cobra.Command{
+	Use:   "shift <series-id>",
+	Short: "Move the anchor of a series, and with it every step.",
+	Long: `Move the anchor of a series, and with it every step that has not been sent,
+such as when the program it announces slips.
+
+--by is a duration, such as 48h, or a number of days, such as 7d, and is negative
+to move the series earlier. Shifts add up, and a shift that brings the series back
+to its anchor removes it. Steps that were sent before the shift are not sent again.`,
+	Annotations: mutating,
+	Args:        cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store, err := datastore.NewStore(false)
+		if err != nil {
+			return fmt.Errorf("failed to create a new datastore: %w", err)
+		}
+		defer store.Close()
+
+		return doSeriesShift(store, cmd.OutOrStdout(), args[0], seriesShiftBy)
+	},
+}
+
+func doSeriesShift(store kv.Storer, w io.Writer, seriesID, by string) error {
+	shift, err := model.ParseOffset(by)
+	if err != nil {
+		return fmt.Errorf("invalid --by: %w", err)
+	}
+	o, err := seriesOverride(store, seriesID)
+	if err != nil {
+		return err
+	}
+	if o.Cancelled {
+		return fmt.Errorf("series '%s' is cancelled", seriesID)
+	}
+	o.Shift += shift
+	if err := saveSeriesOverride(store, o); err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "Shifted series '%s' by %s, %s from its anchor.\n", seriesID, shift, o.Shift)
+	return nil
+}
+
+func init() {
+	seriesCmd.AddCommand(seriesShiftCmd)
+	seriesShiftCmd.Flags().StringVar(&seriesShiftBy, "by", "", "How far to move the series, such as 48h or -7d.")
+	seriesShiftCmd.MarkFlagRequired("by")
+}
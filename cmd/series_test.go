@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/andrewhowdencom/ruf/internal/datastore"
+	"github.com/andrewhowdencom/ruf/internal/kv"
+	"github.com/andrewhowdencom/ruf/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSeries(t *testing.T) {
+	store := datastore.NewMockStore()
+	var out bytes.Buffer
+	now := time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC)
+
+	series := []model.Series{{
+		ID:     "beta",
+		Anchor: time.Date(2025, 12, 10, 9, 0, 0, 0, time.UTC),
+		Steps: []model.SeriesStep{
+			{ID: "kickoff", Offset: "-7d"},
+			{ID: "launch", Offset: "0s"},
+		},
+	}}
+
+	assert.NoError(t, doSeriesPause(store, &out, "beta"))
+	assert.Contains(t, out.String(), "Paused series 'beta'")
+	out.Reset()
+	assert.NoError(t, doSeriesList(store, &out, series))
+	assert.Contains(t, out.String(), "paused")
+
+	out.Reset()
+	assert.NoError(t, doSeriesResume(store, &out, "beta"))
+	assert.Contains(t, out.String(), "Resumed series 'beta'")
+	assert.ErrorContains(t, doSeriesResume(store, &out, "beta"), "is not paused")
+	// An override that changes nothing is removed.
+	overrides, err := store.ListSeriesOverrides()
+	assert.NoError(t, err)
+	assert.Empty(t, overrides)
+
+	out.Reset()
+	assert.NoError(t, doSeriesShift(store, &out, "beta", "2d"))
+	assert.NoError(t, doSeriesShift(store, &out, "beta", "-24h"))
+	assert.Contains(t, out.String(), "24h0m0s from its anchor")
+	out.Reset()
+	assert.NoError(t, doSeriesList(store, &out, series))
+	assert.Contains(t, out.String(), "2025-12-11T09:00:00Z")
+	assert.ErrorContains(t, doSeriesShift(store, &out, "beta", "soon"), "invalid --by")
+
+	assert.NoError(t, store.AddScheduledCall(&kv.ScheduledCall{Call: model.Call{ID: "beta.launch:1", Series: "beta"}}))
+	assert.NoError(t, store.AddScheduledCall(&kv.ScheduledCall{Call: model.Call{ID: "standup"}}))
+	out.Reset()
+	assert.NoError(t, doSeriesCancel(store, &out, "beta", "alice", "postponed", now))
+	assert.Contains(t, out.String(), "Cancelled call 'beta.launch:1'")
+	assert.NotContains(t, out.String(), "standup")
+	assert.Contains(t, out.String(), "Cancelled series 'beta'")
+
+	assert.ErrorContains(t, doSeriesCancel(store, &out, "beta", "alice", "", now), "already cancelled")
+	assert.ErrorContains(t, doSeriesPause(store, &out, "beta"), "is cancelled")
+	out.Reset()
+	assert.NoError(t, doSeriesList(store, &out, series))
+	assert.Contains(t, out.String(), "cancelled")
+}
@@ -1,33 +1,196 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
-	"path/filepath"
-	"runtime"
+	"io"
+	"log/slog"
+	nethttp "net/http"
+	"os"
+	"sync"
 
+	"github.com/adrg/xdg"
+	"github.com/andrewhowdencom/ruf/internal/assets"
+	"github.com/andrewhowdencom/ruf/internal/eventsource"
 	"github.com/andrewhowdencom/ruf/internal/http"
+	"github.com/andrewhowdencom/ruf/internal/kv"
+	"github.com/andrewhowdencom/ruf/internal/poller"
 	"github.com/andrewhowdencom/ruf/internal/sourcer"
+	"github.com/spf13/viper"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
 )
 
+// sourceStdin is where the stdin:// source is read from.
+var sourceStdin io.Reader = os.Stdin
+
 // buildSourcer creates a new sourcer with the default fetchers.
 func buildSourcer() (sourcer.Sourcer, error) {
 	httpClient := http.NewClient()
 
+	fetcher := buildRemoteFetcher(httpClient)
+	git, err := buildGitFetcher()
+	if err != nil {
+		return nil, err
+	}
+	for _, scheme := range []string{"git", "git+https", "git+http", "git+file"} {
+		fetcher.AddFetcher(scheme, git)
+	}
+	fetcher.AddFetcher("gsheets", sourcer.NewSheetsFetcher())
+	push, err := buildPushFetcher()
+	if err != nil {
+		return nil, err
+	}
+	fetcher.AddFetcher("push", push)
+	fetcher.AddFetcher("stdin", sourcer.NewStdinFetcher(sourceStdin))
+
+	yamlParser, err := sourcer.NewYAMLParser()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create parser: %w", err)
+	}
+	parser := sourcer.NewCompositeParser(yamlParser)
+	parser.AddParser("gsheets", sourcer.NewSheetParser())
+
+	resolver := eventsource.NewResolver(
+		eventsource.WithHTTPClient(httpClient),
+		eventsource.WithWindow(viper.GetDuration("source.events.lookback"), viper.GetDuration("source.events.lookahead")),
+	)
+
+	return sourcer.NewSourcer(fetcher, parser, sourcer.WithEventResolver(resolver)), nil
+}
+
+// buildRemoteFetcher creates a fetcher of files, and of content over HTTP and from object storage.
+func buildRemoteFetcher(httpClient *nethttp.Client) *sourcer.CompositeFetcher {
 	fetcher := sourcer.NewCompositeFetcher()
 	fetcher.AddFetcher("http", sourcer.NewHTTPFetcher(httpClient))
 	fetcher.AddFetcher("https", sourcer.NewHTTPFetcher(httpClient))
 	fetcher.AddFetcher("file", sourcer.NewFileFetcher())
-	fetcher.AddFetcher("git", sourcer.NewGitFetcher())
+	fetcher.AddFetcher("s3", buildS3Fetcher(httpClient))
+	fetcher.AddFetcher("gs", buildGCSFetcher(httpClient))
+	fetcher.AddFetcher("azblob", buildAzureBlobFetcher(httpClient))
+	return fetcher
+}
 
-	// Get the path to the current source file, and then find the schema file relative to that.
-	_, b, _, _ := runtime.Caller(0)
-	basepath := filepath.Dir(b)
-	schemaPath := filepath.Join(basepath, "..", "schema", "calls.json")
+// buildAssets creates the cache of the remote content that calls render, kept under
+// source.assets.cache_dir, or the cache directory of the user by default. Assets are fetched as
+// sources are, but not from git.
+func buildAssets() (*assets.Cache, error) {
+	dir := viper.GetString("source.assets.cache_dir")
+	if dir == "" {
+		var err error
+		if dir, err = xdg.CacheFile("ruf/assets"); err != nil {
+			return nil, fmt.Errorf("failed to find the asset cache directory: %w", err)
+		}
+	}
+	return assets.New(dir, buildRemoteFetcher(http.NewClient())), nil
+}
 
-	parser, err := sourcer.NewYAMLParser(schemaPath)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create parser: %w", err)
+// buildGitFetcher creates the fetcher of git sources, which keeps its clones under
+// source.git.cache_dir, or the cache directory of the user by default.
+func buildGitFetcher() (*sourcer.GitFetcher, error) {
+	dir := viper.GetString("source.git.cache_dir")
+	if dir == "" {
+		var err error
+		if dir, err = xdg.CacheFile("ruf/git"); err != nil {
+			return nil, fmt.Errorf("failed to find the git cache directory: %w", err)
+		}
 	}
+	return sourcer.NewGitFetcher(sourcer.WithCacheDir(dir)), nil
+}
+
+// pollerOptions returns the options of the poller of the sources, which caches them in the store
+// unless source.cache.enabled is off.
+func pollerOptions(store kv.Storer) []poller.Option {
+	if !viper.GetBool("source.cache.enabled") {
+		return nil
+	}
+	return []poller.Option{poller.WithCache(store)}
+}
 
-	return sourcer.NewSourcer(fetcher, parser), nil
+// buildPushFetcher creates the fetcher of push:// sources, whose content is pushed to the watch server
+// and kept under source.push.dir, or the data directory of the user by default.
+func buildPushFetcher() (*sourcer.PushFetcher, error) {
+	dir := viper.GetString("source.push.dir")
+	if dir == "" {
+		var err error
+		if dir, err = xdg.DataFile("ruf/push"); err != nil {
+			return nil, fmt.Errorf("failed to find the push directory: %w", err)
+		}
+	}
+	return sourcer.NewPushFetcher(dir), nil
+}
+
+// buildS3Fetcher creates the fetcher of s3:// sources. Settings under source.s3 that are not set are
+// read from the environment variables of the AWS CLI.
+func buildS3Fetcher(client *nethttp.Client) *sourcer.ObjectFetcher {
+	creds := sourcer.AWSCredentials{
+		AccessKeyID:     settingOrEnv("source.s3.access_key_id", "AWS_ACCESS_KEY_ID"),
+		SecretAccessKey: settingOrEnv("source.s3.secret_access_key", "AWS_SECRET_ACCESS_KEY"),
+		SessionToken:    settingOrEnv("source.s3.session_token", "AWS_SESSION_TOKEN"),
+	}
+	region := settingOrEnv("source.s3.region", "AWS_REGION")
+	if region == "" {
+		region = "us-east-1"
+	}
+	return sourcer.NewS3Fetcher(client, region, creds, objectOptions("source.s3.endpoint")...)
+}
+
+// buildGCSFetcher creates the fetcher of gs:// sources, which reads buckets with the application
+// default credentials, or without credentials if there are none.
+func buildGCSFetcher(client *nethttp.Client) *sourcer.ObjectFetcher {
+	authorized := &nethttp.Client{
+		Timeout:   client.Timeout,
+		Transport: &googleTransport{base: client.Transport, scope: "https://www.googleapis.com/auth/devstorage.read_only"},
+	}
+	return sourcer.NewGCSFetcher(authorized, objectOptions("source.gcs.endpoint")...)
+}
+
+// buildAzureBlobFetcher creates the fetcher of azblob:// sources. Settings under source.azblob that are
+// not set are read from the environment variables of the Azure CLI.
+func buildAzureBlobFetcher(client *nethttp.Client) *sourcer.ObjectFetcher {
+	return sourcer.NewAzureBlobFetcher(client,
+		settingOrEnv("source.azblob.account", "AZURE_STORAGE_ACCOUNT"),
+		settingOrEnv("source.azblob.sas_token", "AZURE_STORAGE_SAS_TOKEN"),
+		objectOptions("source.azblob.endpoint")...,
+	)
+}
+
+func objectOptions(endpointKey string) []sourcer.ObjectOption {
+	if endpoint := viper.GetString(endpointKey); endpoint != "" {
+		return []sourcer.ObjectOption{sourcer.WithEndpoint(endpoint)}
+	}
+	return nil
+}
+
+// settingOrEnv returns the setting, or the environment variable if it is not set.
+func settingOrEnv(key, env string) string {
+	if v := viper.GetString(key); v != "" {
+		return v
+	}
+	return os.Getenv(env)
+}
+
+// googleTransport authorizes requests with the application default credentials. They are found on the
+// first request, so that commands that read no bucket do not look for them.
+type googleTransport struct {
+	base  nethttp.RoundTripper
+	scope string
+
+	once sync.Once
+	ts   oauth2.TokenSource
+}
+
+func (t *googleTransport) RoundTrip(req *nethttp.Request) (*nethttp.Response, error) {
+	t.once.Do(func() {
+		ts, err := google.DefaultTokenSource(context.Background(), t.scope)
+		if err != nil {
+			slog.Debug("no google cloud credentials found, reading buckets without them", "error", err)
+			return
+		}
+		t.ts = ts
+	})
+	if t.ts == nil {
+		return t.base.RoundTrip(req)
+	}
+	return (&oauth2.Transport{Source: t.ts, Base: t.base}).RoundTrip(req)
 }
@@ -0,0 +1,17 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// sourcesCmd represents the sources command
+var sourcesCmd = &cobra.Command{
+	Use:     "sources",
+	Aliases: []string{"source"},
+	Short:   "Report on the sources of calls.",
+	Long:    `Report on the sources of calls listed under source.urls.`,
+}
+
+func init() {
+	rootCmd.AddCommand(sourcesCmd)
+}
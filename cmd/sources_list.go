@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/andrewhowdencom/ruf/internal/poller"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// sourcesListCmd represents the sources list command
+var sourcesListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List each configured source with its health.",
+	Long: `List each source under source.urls with its health: how long it took to read, the number of calls
+read from it, when it was last read and the state of its content, such as its ETag. Sources are read as
+the worker reads them. With --remote, the health is instead that of the last poll of a running
+'ruf dispatcher watch'; a source it has not polled is listed as unknown.
+
+The health of a source is one of:
+  ok              the source was read.
+  partly skipped  files, documents or rows of the source that are not valid were left out.
+  skipped         the source was skipped, as it is not valid.
+  stale           the source could not be fetched, and its cached content was used instead.
+  failing         the source could not be read.
+
+Example:
+  ruf source list
+  ruf source list --remote http://localhost:8080`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		statuses, err := sourcesStatus(cmd)
+		if err != nil {
+			return err
+		}
+		return doSourcesList(viper.GetStringSlice("source.urls"), statuses, cmd.OutOrStdout())
+	},
+}
+
+// doSourcesList lists the configured sources, in order, with the status of their last poll, followed by
+// any other sources that were polled.
+func doSourcesList(urls []string, statuses []poller.Status, w io.Writer) error {
+	if len(urls) == 0 && len(statuses) == 0 {
+		fmt.Fprintln(w, "No sources are configured.")
+		return nil
+	}
+
+	byURL := make(map[string]poller.Status, len(statuses))
+	for _, status := range statuses {
+		byURL[status.URL] = status
+	}
+	listed := make(map[string]bool, len(urls))
+	for _, url := range urls {
+		listed[url] = true
+	}
+	for _, status := range statuses {
+		if !listed[status.URL] {
+			urls = append(urls, status.URL)
+		}
+	}
+
+	table := tablewriter.NewWriter(w)
+	table.Header("Source", "Health", "Calls", "Duration", "Last Success", "State")
+	for _, url := range urls {
+		status, ok := byURL[url]
+		if !ok {
+			table.Append([]string{url, "unknown", "", "", "", ""})
+			continue
+		}
+		lastSuccess := "never"
+		if !status.LastSuccessAt.IsZero() {
+			lastSuccess = status.LastSuccessAt.Format(time.RFC3339)
+		}
+		table.Append([]string{
+			url,
+			status.Health(),
+			strconv.Itoa(status.Calls),
+			status.Duration.Round(time.Millisecond).String(),
+			lastSuccess,
+			status.State,
+		})
+	}
+	table.Render()
+	return nil
+}
+
+func init() {
+	sourcesCmd.AddCommand(sourcesListCmd)
+	sourcesListCmd.Flags().String("remote", "", "The base URL of a running watcher to read the health from")
+}
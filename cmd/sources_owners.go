@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/andrewhowdencom/ruf/internal/owners"
+	"github.com/andrewhowdencom/ruf/internal/sourcer"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// sourcesOwnersCmd represents the sources owners command
+var sourcesOwnersCmd = &cobra.Command{
+	Use:   "owners",
+	Short: "List the owners of the campaign of each source.",
+	Long: `List the owners of the campaign of each source, and where they come from:
+the owners of the campaign itself, or the rule under owners that matched it.
+Sources without owners have their failures go to the authors of their calls only.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		s, err := buildSourcer()
+		if err != nil {
+			return fmt.Errorf("failed to build sourcer: %w", err)
+		}
+		router, err := buildOwners()
+		if err != nil {
+			return err
+		}
+		return doSourcesOwners(s, router, viper.GetStringSlice("source.urls"), cmd.OutOrStdout(), cmd.ErrOrStderr())
+	},
+}
+
+func doSourcesOwners(s sourcer.Sourcer, router *owners.Router, urls []string, w, errW io.Writer) error {
+	table := tablewriter.NewWriter(w)
+	table.Header("Source", "Campaign", "Owners", "From")
+	for _, url := range urls {
+		source, _, err := s.Source(url)
+		if err != nil {
+			fmt.Fprintf(errW, "Error sourcing from %s: %v\n", url, err)
+			continue
+		}
+		if source == nil {
+			continue
+		}
+
+		sourceOwners, from := router.Owners(source.Campaign)
+		if from == "" {
+			from = "none"
+		} else if from != "campaign" {
+			from = "rule " + from
+		}
+		table.Append([]string{url, source.Campaign.ID, strings.Join(sourceOwners, "\n"), from})
+	}
+	table.Render()
+	return nil
+}
+
+func init() {
+	sourcesCmd.AddCommand(sourcesOwnersCmd)
+}
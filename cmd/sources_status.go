@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	nethttp "net/http"
+	"strings"
+	"time"
+
+	"github.com/andrewhowdencom/ruf/internal/http"
+	"github.com/andrewhowdencom/ruf/internal/poller"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// sourcesStatusCmd represents the sources status command
+var sourcesStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show whether each source could be read, or was skipped as it is not valid.",
+	Long: `Show whether each source could be read, or was skipped as it is not valid, with each problem
+found in it. Sources are read as the worker reads them. With --remote, the status is instead read from
+the last poll of a running 'ruf dispatcher watch', served at /status/sources.
+
+Example:
+  ruf source status
+  ruf source status --remote http://localhost:8080`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		statuses, err := sourcesStatus(cmd)
+		if err != nil {
+			return err
+		}
+		return doSourcesStatus(statuses, cmd.OutOrStdout())
+	},
+}
+
+// sourcesStatus reads the sources as the worker does, or reads the status of the last poll from the
+// watcher given by the --remote flag.
+func sourcesStatus(cmd *cobra.Command) ([]poller.Status, error) {
+	if remote, _ := cmd.Flags().GetString("remote"); remote != "" {
+		return remoteSourcesStatus(http.NewClient(), remote)
+	}
+	s, err := buildSourcer()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build sourcer: %w", err)
+	}
+	p := poller.New(s, 0)
+	// The sources that could not be read are recorded, so the error is already in the status.
+	p.Poll(viper.GetStringSlice("source.urls"))
+	return p.Status(), nil
+}
+
+// remoteSourcesStatus reads the status of the sources from the watcher at the base URL.
+func remoteSourcesStatus(client *nethttp.Client, baseURL string) ([]poller.Status, error) {
+	resp, err := client.Get(strings.TrimSuffix(baseURL, "/") + "/status/sources")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the status of the sources: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != nethttp.StatusOK {
+		return nil, fmt.Errorf("failed to get the status of the sources: status code %d", resp.StatusCode)
+	}
+
+	var statuses []poller.Status
+	if err := json.NewDecoder(resp.Body).Decode(&statuses); err != nil {
+		return nil, fmt.Errorf("failed to decode the status of the sources: %w", err)
+	}
+	return statuses, nil
+}
+
+func doSourcesStatus(statuses []poller.Status, w io.Writer) error {
+	if len(statuses) == 0 {
+		fmt.Fprintln(w, "No sources have been read.")
+		return nil
+	}
+
+	table := tablewriter.NewWriter(w)
+	table.Header("Source", "Checked At", "Status", "Problems")
+	for _, status := range statuses {
+		state := "ok"
+		var problems []string
+		switch {
+		case status.Skipped:
+			state = "skipped: not valid"
+		case status.Stale():
+			state = "stale: cached " + status.CachedAt.Format(time.RFC3339)
+			problems = append(problems, status.Error)
+		case status.Error != "":
+			state = "error"
+			problems = append(problems, status.Error)
+		case len(status.Invalid) > 0:
+			state = "partly skipped: not valid"
+		}
+		for _, verr := range status.Invalid {
+			for _, field := range verr.Fields {
+				problem := field.String()
+				if verr.URL != status.URL {
+					problem = verr.URL + ": " + problem
+				}
+				problems = append(problems, problem)
+			}
+		}
+		table.Append([]string{status.URL, status.CheckedAt.Format(time.RFC3339), state, strings.Join(problems, "\n")})
+	}
+	table.Render()
+	return nil
+}
+
+func init() {
+	sourcesCmd.AddCommand(sourcesStatusCmd)
+	sourcesStatusCmd.Flags().String("remote", "", "The base URL of a running watcher to read the status from")
+}
@@ -0,0 +1,115 @@
+package cmd
+
+import (
+	"bytes"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/andrewhowdencom/ruf/internal/owners"
+	"github.com/andrewhowdencom/ruf/internal/poller"
+	"github.com/andrewhowdencom/ruf/internal/sourcer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDoSourcesOwners(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, campaign string) string {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, []byte(campaign+`
+calls:
+  - id: call-1
+    destinations:
+      - type: slack
+        to: ["#general"]
+    content: "Hello"
+    triggers:
+      - cron: "0 9 * * *"
+`), 0o644))
+		return "file://" + path
+	}
+	own := write("own.yaml", "campaign:\n  id: launch\n  name: Launch\n  owners: [launch@example.com]")
+	ruled := write("ruled.yaml", "campaign:\n  id: team-a-standup\n  name: Standup")
+	none := write("none.yaml", "campaign:\n  id: other\n  name: Other")
+
+	fetcher := sourcer.NewCompositeFetcher()
+	fetcher.AddFetcher("file", sourcer.NewFileFetcher())
+	parser, err := sourcer.NewYAMLParser()
+	require.NoError(t, err)
+	router, err := owners.New([]owners.Rule{{Match: "team-a-*", Owners: []string{"team-a@example.com"}}})
+	require.NoError(t, err)
+
+	var out, errOut bytes.Buffer
+	err = doSourcesOwners(sourcer.NewSourcer(fetcher, parser), router, []string{own, ruled, none, "file://" + filepath.Join(dir, "missing.yaml")}, &out, &errOut)
+	require.NoError(t, err)
+
+	assert.Contains(t, out.String(), "launch@example.com")
+	assert.Contains(t, out.String(), "campaign")
+	assert.Contains(t, out.String(), "team-a@example.com")
+	assert.Contains(t, out.String(), "rule team-a-*")
+	assert.Contains(t, out.String(), "none")
+	assert.Contains(t, errOut.String(), "missing.yaml")
+}
+
+func TestDoSourcesStatus(t *testing.T) {
+	dir := t.TempDir()
+	invalid := filepath.Join(dir, "invalid.yaml")
+	require.NoError(t, os.WriteFile(invalid, []byte("calls:\n  - id: call-1\n    content: Hello\n"), 0o644))
+
+	fetcher := sourcer.NewCompositeFetcher()
+	fetcher.AddFetcher("file", sourcer.NewFileFetcher())
+	parser, err := sourcer.NewYAMLParser()
+	require.NoError(t, err)
+	p := poller.New(sourcer.NewSourcer(fetcher, parser), 0)
+	p.Poll([]string{"file://" + invalid})
+
+	// The status is served by the watcher, and read back by the command.
+	server := httptest.NewServer(poller.NewHandler(p))
+	defer server.Close()
+	statuses, err := remoteSourcesStatus(server.Client(), server.URL)
+	require.NoError(t, err)
+
+	var out bytes.Buffer
+	require.NoError(t, doSourcesStatus(statuses, &out))
+	assert.Contains(t, out.String(), "skipped: not valid")
+	assert.Contains(t, out.String(), "triggers is required")
+}
+
+func TestDoSourcesList(t *testing.T) {
+	dir := t.TempDir()
+	valid := "file://" + filepath.Join(dir, "valid.yaml")
+	missing := "file://" + filepath.Join(dir, "missing.yaml")
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "valid.yaml"), []byte(`
+calls:
+  - id: call-1
+    content: Hello
+    destinations: [{type: slack, to: ["#general"]}]
+    triggers: [{cron: "0 9 * * 1"}]
+`), 0o644))
+
+	fetcher := sourcer.NewCompositeFetcher()
+	fetcher.AddFetcher("file", sourcer.NewFileFetcher())
+	parser, err := sourcer.NewYAMLParser()
+	require.NoError(t, err)
+	p := poller.New(sourcer.NewSourcer(fetcher, parser), 0)
+	p.Poll([]string{valid, missing})
+
+	var out bytes.Buffer
+	require.NoError(t, doSourcesList([]string{valid, missing, "https://example.com/calls.yaml"}, p.Status(), &out))
+	lines := strings.Split(out.String(), "\n")
+	find := func(url string) string {
+		for _, line := range lines {
+			if strings.Contains(line, url) {
+				return line
+			}
+		}
+		return ""
+	}
+	assert.Regexp(t, `valid\.yaml +│ ok +│ 1 +│`, find(valid))
+	assert.Contains(t, find(missing), "failing")
+	assert.Contains(t, find(missing), "never")
+	assert.Contains(t, find("https://example.com/calls.yaml"), "unknown")
+}
@@ -0,0 +1,38 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/andrewhowdencom/ruf/internal/timezone"
+	"github.com/go-viper/mapstructure/v2"
+	"github.com/spf13/viper"
+)
+
+// buildTimezones creates the resolver of the timezones of recipients sent calls at their local time,
+// from timezones.recipients.<type>.<recipient>, then the Slack profile of the recipient if
+// timezones.slack is set. It returns nil if neither is configured. Recipients are read from the raw
+// map rather than as keys, as they may contain dots.
+func buildTimezones() (timezone.Resolver, error) {
+	var chain timezone.Chain
+
+	var recipients map[string]map[string]string
+	if err := mapstructure.Decode(viper.GetStringMap("timezones.recipients"), &recipients); err != nil {
+		return nil, fmt.Errorf("failed to parse timezones.recipients: %w", err)
+	}
+	if len(recipients) > 0 {
+		static, err := timezone.NewStatic(recipients)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse timezones.recipients: %w", err)
+		}
+		chain = append(chain, static)
+	}
+
+	if viper.GetBool("timezones.slack") {
+		chain = append(chain, timezone.NewSlack(slackNewClient(viper.GetString("slack.app.token")), viper.GetDuration("timezones.cache_ttl")))
+	}
+
+	if len(chain) == 0 {
+		return nil, nil
+	}
+	return chain, nil
+}
@@ -0,0 +1,20 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// triggerCmd represents the trigger command
+var triggerCmd = &cobra.Command{
+	Use:   "trigger",
+	Short: "Pause and resume individual triggers of a call.",
+	Long: `Pause and resume individual triggers of a call.
+
+Triggers are identified by the ID of their call and their zero-based index in
+the call's list of triggers. Changes take effect the next time the schedule is
+refreshed.`,
+}
+
+func init() {
+	rootCmd.AddCommand(triggerCmd)
+}
@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/andrewhowdencom/ruf/internal/datastore"
+	"github.com/andrewhowdencom/ruf/internal/kv"
+	"github.com/spf13/cobra"
+)
+
+var (
+	triggerIndex int
+	triggerUntil string
+)
+
+// triggerDisableCmd represents the trigger disable command
+var triggerDisableCmd = &cobra.Command{
+	Use:   "disable <call-id>",
+	Short: "Pause a single trigger of a call.",
+	Long: `Pause a single trigger of a call, leaving its other triggers untouched.
+
+Occurrences before --until, a date (YYYY-MM-DD, read as midnight UTC) or an
+RFC 3339 time, are not scheduled. Without --until, the trigger is paused until
+it is enabled again.`,
+	Annotations: mutating,
+	Args:        cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store, err := datastore.NewStore(false)
+		if err != nil {
+			return fmt.Errorf("failed to create a new datastore: %w", err)
+		}
+		defer store.Close()
+
+		return doTriggerDisable(store, cmd.OutOrStdout(), args[0], triggerIndex, triggerUntil)
+	},
+}
+
+func doTriggerDisable(store kv.Storer, w io.Writer, callID string, index int, until string) error {
+	if index < 0 {
+		return fmt.Errorf("index must not be negative, got %d", index)
+	}
+
+	override := &kv.TriggerOverride{CallID: callID, Index: index}
+	if until != "" {
+		t, err := parseUntil(until)
+		if err != nil {
+			return err
+		}
+		override.Until = t
+	}
+
+	if err := store.SetTriggerOverride(override); err != nil {
+		return fmt.Errorf("failed to save trigger override: %w", err)
+	}
+
+	if override.Until.IsZero() {
+		fmt.Fprintf(w, "Disabled trigger %d of call '%s'.\n", index, callID)
+	} else {
+		fmt.Fprintf(w, "Disabled trigger %d of call '%s' until %s.\n", index, callID, override.Until.Format(time.RFC3339))
+	}
+	return nil
+}
+
+// parseUntil parses a date, read as midnight UTC, or an RFC 3339 time.
+func parseUntil(s string) (time.Time, error) {
+	if t, err := time.Parse(time.DateOnly, s); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid --until '%s': must be a date (YYYY-MM-DD) or an RFC 3339 time", s)
+	}
+	return t.UTC(), nil
+}
+
+func init() {
+	triggerCmd.AddCommand(triggerDisableCmd)
+	triggerDisableCmd.Flags().IntVar(&triggerIndex, "index", 0, "The zero-based index of the trigger in the call's list of triggers.")
+	triggerDisableCmd.Flags().StringVar(&triggerUntil, "until", "", "When the trigger resumes, as a date (YYYY-MM-DD) or an RFC 3339 time.")
+	triggerDisableCmd.MarkFlagRequired("index")
+}
@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/andrewhowdencom/ruf/internal/datastore"
+	"github.com/andrewhowdencom/ruf/internal/kv"
+	"github.com/spf13/cobra"
+)
+
+// triggerEnableCmd represents the trigger enable command
+var triggerEnableCmd = &cobra.Command{
+	Use:   "enable <call-id>",
+	Short: "Resume a trigger paused with 'trigger disable'.",
+	Long: `Resume a trigger paused with 'trigger disable' by removing its override.
+
+Triggers disabled with 'enabled: false' in their source must be enabled there.`,
+	Annotations: mutating,
+	Args:        cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store, err := datastore.NewStore(false)
+		if err != nil {
+			return fmt.Errorf("failed to create a new datastore: %w", err)
+		}
+		defer store.Close()
+
+		return doTriggerEnable(store, cmd.OutOrStdout(), args[0], triggerIndex)
+	},
+}
+
+func doTriggerEnable(store kv.Storer, w io.Writer, callID string, index int) error {
+	if err := store.DeleteTriggerOverride(callID, index); err != nil {
+		if errors.Is(err, kv.ErrNotFound) {
+			return fmt.Errorf("trigger %d of call '%s' is not disabled", index, callID)
+		}
+		return fmt.Errorf("failed to delete trigger override: %w", err)
+	}
+	fmt.Fprintf(w, "Enabled trigger %d of call '%s'.\n", index, callID)
+	return nil
+}
+
+func init() {
+	triggerCmd.AddCommand(triggerEnableCmd)
+	triggerEnableCmd.Flags().IntVar(&triggerIndex, "index", 0, "The zero-based index of the trigger in the call's list of triggers.")
+	triggerEnableCmd.MarkFlagRequired("index")
+}
@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/andrewhowdencom/ruf/internal/datastore"
+	"github.com/andrewhowdencom/ruf/internal/kv"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+// triggerListCmd represents the trigger list command
+var triggerListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the triggers paused with 'trigger disable'.",
+	Long:  `List the triggers paused with 'trigger disable'.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store, err := datastore.NewStore(true)
+		if err != nil {
+			return fmt.Errorf("failed to create a new datastore: %w", err)
+		}
+		defer store.Close()
+
+		return doTriggerList(store, cmd.OutOrStdout())
+	},
+}
+
+func doTriggerList(store kv.Storer, w io.Writer) error {
+	overrides, err := store.ListTriggerOverrides()
+	if err != nil {
+		return fmt.Errorf("failed to list trigger overrides: %w", err)
+	}
+	if len(overrides) == 0 {
+		fmt.Fprintln(w, "No triggers are disabled.")
+		return nil
+	}
+
+	sort.Slice(overrides, func(i, j int) bool { return overrides[i].Key() < overrides[j].Key() })
+
+	table := tablewriter.NewWriter(w)
+	table.Header("Call ID", "Index", "Until")
+	for _, o := range overrides {
+		until := "indefinitely"
+		if !o.Until.IsZero() {
+			until = o.Until.Format(time.RFC3339)
+		}
+		table.Append([]string{o.CallID, fmt.Sprint(o.Index), until})
+	}
+	table.Render()
+	return nil
+}
+
+func init() {
+	triggerCmd.AddCommand(triggerListCmd)
+}
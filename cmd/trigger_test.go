@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/andrewhowdencom/ruf/internal/datastore"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTriggerDisableEnable(t *testing.T) {
+	store := datastore.NewMockStore()
+	var out bytes.Buffer
+
+	assert.NoError(t, doTriggerDisable(store, &out, "standup", 1, "2025-12-01"))
+	assert.Contains(t, out.String(), "Disabled trigger 1 of call 'standup' until 2025-12-01T00:00:00Z")
+
+	overrides, err := store.ListTriggerOverrides()
+	assert.NoError(t, err)
+	assert.Len(t, overrides, 1)
+	assert.Equal(t, time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC), overrides[0].Until)
+
+	out.Reset()
+	assert.NoError(t, doTriggerList(store, &out))
+	assert.Contains(t, out.String(), "standup")
+
+	out.Reset()
+	assert.NoError(t, doTriggerEnable(store, &out, "standup", 1))
+	assert.Contains(t, out.String(), "Enabled trigger 1 of call 'standup'")
+
+	assert.ErrorContains(t, doTriggerEnable(store, &out, "standup", 1), "is not disabled")
+	assert.ErrorContains(t, doTriggerDisable(store, &out, "standup", 0, "next week"), "invalid --until")
+	assert.ErrorContains(t, doTriggerDisable(store, &out, "standup", -1, ""), "must not be negative")
+}
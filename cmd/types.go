@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/andrewhowdencom/ruf/internal/clients/webhook"
+	"github.com/spf13/viper"
+)
+
+// nativeTypes are the destination types with a client of their own, which types cannot replace.
+var nativeTypes = []string{"slack", "email", "chatwork", "line", "homeassistant", "slack_workflow"}
+
+// typeConfig is the configuration of a destination type sent through a webhook.
+type typeConfig struct {
+	Webhook         string            `mapstructure:"webhook"`
+	Method          string            `mapstructure:"method"`
+	Headers         map[string]string `mapstructure:"headers"`
+	ContentType     string            `mapstructure:"content_type"`
+	PayloadTemplate string            `mapstructure:"payload_template"`
+	Format          string            `mapstructure:"format"`
+}
+
+// buildWebhooks creates a client for each destination type defined under types.
+func buildWebhooks() (map[string]webhook.Client, error) {
+	var configs map[string]typeConfig
+	if err := viper.UnmarshalKey("types", &configs); err != nil {
+		return nil, fmt.Errorf("failed to parse types: %w", err)
+	}
+
+	clients := make(map[string]webhook.Client, len(configs))
+	for name, c := range configs {
+		for _, native := range nativeTypes {
+			if name == native {
+				return nil, fmt.Errorf("types.%s: %s is a built-in destination type", name, name)
+			}
+		}
+		client, err := webhook.NewClient(name, webhook.Type{
+			URL:             c.Webhook,
+			Method:          c.Method,
+			Headers:         c.Headers,
+			ContentType:     c.ContentType,
+			PayloadTemplate: c.PayloadTemplate,
+			Format:          c.Format,
+		})
+		if err != nil {
+			return nil, err
+		}
+		clients[name] = client
+	}
+	return clients, nil
+}
+
+// destinationTypes returns the names of the destination types defined under types, in order.
+func destinationTypes() []string {
+	var names []string
+	for name := range viper.GetStringMap("types") {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/andrewhowdencom/ruf/internal/clients/webhook"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildWebhooks(t *testing.T) {
+	t.Cleanup(viper.Reset)
+	viper.SetConfigType("yaml")
+	require.NoError(t, viper.ReadConfig(strings.NewReader(`
+types:
+  statusbot:
+    webhook: "https://status.example.com/{{ .To }}"
+    format: html
+  pager:
+    webhook: "https://pager.example.com"
+`)))
+
+	hooks, err := buildWebhooks()
+	require.NoError(t, err)
+	assert.Len(t, hooks, 2)
+	assert.Equal(t, webhook.FormatHTML, hooks["statusbot"].Format())
+	assert.Equal(t, webhook.FormatText, hooks["pager"].Format())
+	assert.Equal(t, []string{"pager", "statusbot"}, destinationTypes())
+}
+
+func TestBuildWebhooks_NativeType(t *testing.T) {
+	t.Cleanup(viper.Reset)
+	viper.SetConfigType("yaml")
+	require.NoError(t, viper.ReadConfig(strings.NewReader(`
+types:
+  slack:
+    webhook: "https://hooks.example.com"
+`)))
+
+	_, err := buildWebhooks()
+	assert.ErrorContains(t, err, "built-in destination type")
+}
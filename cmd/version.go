@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	rufhttp "github.com/andrewhowdencom/ruf/internal/http"
+	"github.com/andrewhowdencom/ruf/internal/release"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// Build information for the running binary.
+var (
+	buildVersion = "dev"
+	buildCommit  = "none"
+	buildDate    = "unknown"
+)
+
+// releaseKey is the base64 encoded ed25519 key releases are signed with, embedded at build time.
+var releaseKey string
+
+// SetReleaseKey sets the key releases are verified with when update.public_key is not set.
+func SetReleaseKey(key string) {
+	releaseKey = key
+}
+
+// SetVersionInfo sets the build information reported by the version command.
+func SetVersionInfo(version, commit, date string) {
+	buildVersion = version
+	buildCommit = commit
+	buildDate = date
+}
+
+// versionCmd represents the version command
+var versionCmd = &cobra.Command{
+	Use:   "version",
+	Short: "Show the version of ruf.",
+	Long: `Show the version of ruf.
+
+Example:
+  # Show the version, and whether a newer release is available
+  ruf version --check`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		fmt.Fprintf(cmd.OutOrStdout(), "ruf %s (commit %s, built %s)\n", buildVersion, buildCommit, buildDate)
+
+		check, _ := cmd.Flags().GetBool("check")
+		if !check {
+			return nil
+		}
+
+		updater, err := buildUpdater(false)
+		if err != nil {
+			return err
+		}
+		latest, err := updater.Latest(context.Background())
+		if err != nil {
+			return fmt.Errorf("failed to check for updates: %w", err)
+		}
+		newer, err := release.IsNewer(latest, buildVersion)
+		if err != nil {
+			return err
+		}
+		if newer {
+			fmt.Fprintf(cmd.OutOrStdout(), "A newer version is available: %s. Run 'ruf self-update' to install it.\n", latest.Version())
+		} else {
+			fmt.Fprintln(cmd.OutOrStdout(), "ruf is up to date.")
+		}
+		return nil
+	},
+}
+
+// buildUpdater creates a release updater from the configuration. Releases are verified with the key
+// in update.public_key, or the key embedded at build time. Without either, or with skipSignature, only
+// the checksums published with the release are checked.
+func buildUpdater(skipSignature bool) (*release.Updater, error) {
+	opts := []release.Option{
+		release.WithEndpoint(viper.GetString("update.endpoint")),
+	}
+	if skipSignature {
+		opts = append(opts, release.WithoutSignature())
+	}
+	encoded, setting := viper.GetString("update.public_key"), "update.public_key"
+	if encoded == "" {
+		encoded, setting = releaseKey, "embedded release key"
+	}
+	if encoded != "" && !skipSignature {
+		key, err := release.ParsePublicKey(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", setting, err)
+		}
+		opts = append(opts, release.WithPublicKey(key))
+	}
+	return release.NewUpdater(rufhttp.NewClient(), opts...), nil
+}
+
+func init() {
+	rootCmd.AddCommand(versionCmd)
+	versionCmd.Flags().Bool("check", false, "Check whether a newer release is available")
+
+	viper.SetDefault("update.endpoint", release.DefaultEndpoint)
+	viper.SetDefault("update.public_key", "")
+}
@@ -1,18 +1,26 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"log/slog"
+	"os"
+	"time"
 
+	"github.com/andrewhowdencom/ruf/internal/approval"
+	"github.com/andrewhowdencom/ruf/internal/checklist"
 	"github.com/andrewhowdencom/ruf/internal/clients/email"
 	"github.com/andrewhowdencom/ruf/internal/clients/slack"
 	"github.com/andrewhowdencom/ruf/internal/datastore"
+	"github.com/andrewhowdencom/ruf/internal/feed"
+	"github.com/andrewhowdencom/ruf/internal/health"
 	"github.com/andrewhowdencom/ruf/internal/http"
+	"github.com/andrewhowdencom/ruf/internal/kv"
 	"github.com/andrewhowdencom/ruf/internal/poller"
-	"github.com/andrewhowdencom/ruf/internal/scheduler"
 	"github.com/andrewhowdencom/ruf/internal/worker"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
+	"go.opentelemetry.io/otel"
 )
 
 // watchCmd represents the watch command
@@ -21,31 +29,52 @@ var watchCmd = &cobra.Command{
 	Short: "Run the watcher to send calls",
 	Long:  `Run the watcher to send calls.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		return runWatch()
+		return runWatch(cmd.Context())
 	},
 }
 
-func runWatch() error {
+func runWatch(ctx context.Context) error {
 	slog.Debug("running watch")
 
-	go http.Start(viper.GetInt("watch.port"))
-
 	store, err := datastore.NewStore(false)
 	if err != nil {
 		return fmt.Errorf("failed to create store: %w", err)
 	}
 	defer store.Close()
+	store = chaosStore(store)
 
+	monitor, err := buildMonitor(store)
+	if err != nil {
+		return fmt.Errorf("failed to build health monitor: %w", err)
+	}
+	if err := monitor.RegisterMetrics(otel.Meter("github.com/andrewhowdencom/ruf")); err != nil {
+		return fmt.Errorf("failed to register metrics: %w", err)
+	}
+	httpOpts := []http.Option{http.WithHealthCheck(monitor.Check)}
+	if token := viper.GetString("checklist.api.token"); token != "" {
+		httpOpts = append(httpOpts, http.WithHandler("/checklist/", checklist.NewHandler(store, token)))
+	}
+	if secret := viper.GetString("slack.app.signing_secret"); secret != "" {
+		httpOpts = append(httpOpts, http.WithHandler("POST /slack/commands", checklist.NewSlackHandler(store, secret)))
+		httpOpts = append(httpOpts, http.WithHandler("POST /slack/interactions", approval.NewSlackHandler(store, secret)))
+	}
+	if viper.GetBool("feeds.enabled") {
+		httpOpts = append(httpOpts, http.WithHandler("GET /feeds/", feed.NewHandler(store,
+			feed.WithBaseURL(viper.GetString("feeds.base_url")),
+			feed.WithLimit(viper.GetInt("feeds.limit")),
+			feed.WithCampaigns(viper.GetStringSlice("feeds.campaigns")...),
+		)))
+	}
 	slackToken := viper.GetString("slack.app.token")
-	slackClient := slack.NewClient(slackToken)
+	slackClient := chaosSlack(slack.NewClient(slackToken))
 
-	emailClient := email.NewClient(
+	emailClient := chaosEmail(email.NewClient(
 		viper.GetString("email.host"),
 		viper.GetInt("email.port"),
 		viper.GetString("email.username"),
 		viper.GetString("email.password"),
 		viper.GetString("email.from"),
-	)
+	))
 
 	s, err := buildSourcer()
 	if err != nil {
@@ -53,18 +82,93 @@ func runWatch() error {
 	}
 
 	refreshInterval := viper.GetDuration("watch.refresh_interval")
-	p := poller.New(s, refreshInterval)
+	p := poller.New(s, refreshInterval, pollerOptions(store)...)
+	if err := p.RegisterMetrics(otel.Meter("github.com/andrewhowdencom/ruf")); err != nil {
+		return fmt.Errorf("failed to register metrics: %w", err)
+	}
+	httpOpts = append(httpOpts, http.WithHandler("GET /status/sources", poller.NewHandler(p)))
+	httpOpts = append(httpOpts, http.WithHandler("GET /status/workers", worker.NewStatusHandler(store)))
 
-	sched := scheduler.New(store)
-	w, err := worker.New(store, slackClient, emailClient, p, sched, refreshInterval, viper.GetBool("dispatcher.dry_run"))
+	sched, err := buildScheduler(store)
+	if err != nil {
+		return fmt.Errorf("failed to build scheduler: %w", err)
+	}
+	opts, err := workerOptions()
+	if err != nil {
+		return err
+	}
+	opts = append(opts, worker.WithMonitor(monitor), worker.WithStatus(leaseHolder()))
+	if viper.GetBool("worker.lease.enabled") {
+		opts = append(opts, worker.WithLease(leaseHolder(), viper.GetDuration("worker.lease.ttl")))
+	}
+	w, err := worker.New(store, slackClient, emailClient, p, sched, refreshInterval, viper.GetBool("dispatcher.dry_run"), opts...)
 	if err != nil {
 		return fmt.Errorf("failed to create worker: %w", err)
 	}
-	return w.Run()
+	if err := w.RegisterMetrics(otel.Meter("github.com/andrewhowdencom/ruf")); err != nil {
+		return fmt.Errorf("failed to register metrics: %w", err)
+	}
+
+	if token := viper.GetString("watch.push.token"); token != "" {
+		push, err := buildPushFetcher()
+		if err != nil {
+			return err
+		}
+		httpOpts = append(httpOpts, http.WithHandler("POST /v1/sources/", poller.NewPushHandler(push, token, w.Refresh)))
+	}
+	go http.Start(viper.GetInt("watch.port"), httpOpts...)
+
+	return w.Run(ctx)
 }
 
 func init() {
 	dispatcherCmd.AddCommand(watchCmd)
 	viper.SetDefault("watch.refresh_interval", "1h")
 	viper.SetDefault("watch.port", 8080)
+	viper.SetDefault("watch.push.token", "")
+	viper.SetDefault("checklist.api.token", "")
+	viper.SetDefault("feeds.enabled", false)
+	viper.SetDefault("feeds.base_url", "")
+	viper.SetDefault("feeds.limit", 50)
+	viper.SetDefault("feeds.campaigns", []string{})
+	viper.SetDefault("worker.lease.enabled", false)
+	viper.SetDefault("worker.lease.ttl", "30s")
+	viper.SetDefault("worker.lease.holder", "")
+	viper.SetDefault("health.thresholds.min_scheduled_calls", 0)
+	viper.SetDefault("health.thresholds.max_oldest_overdue", "0s")
+	viper.SetDefault("health.thresholds.min_slots_remaining", 0)
+	viper.SetDefault("health.thresholds.max_refresh_age", "0s")
+	viper.SetDefault("health.thresholds.max_tick_age", "0s")
+}
+
+// leaseHolder returns the name this instance holds the worker lease under, which defaults to the
+// hostname and process ID.
+func leaseHolder() string {
+	if holder := viper.GetString("worker.lease.holder"); holder != "" {
+		return holder
+	}
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+	return fmt.Sprintf("%s/%d", hostname, os.Getpid())
+}
+
+// buildMonitor creates the health monitor for the schedule, configured with the alert thresholds.
+func buildMonitor(store kv.Storer) (*health.Monitor, error) {
+	loc, err := time.LoadLocation(viper.GetString("slots.timezone"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load timezone: %w", err)
+	}
+
+	return health.NewMonitor(store,
+		health.WithSlots(viper.GetStringMapStringSlice("slots.default"), loc),
+		health.WithThresholds(health.Thresholds{
+			MinScheduledCalls: viper.GetInt("health.thresholds.min_scheduled_calls"),
+			MaxOldestOverdue:  viper.GetDuration("health.thresholds.max_oldest_overdue"),
+			MinSlotsRemaining: viper.GetInt("health.thresholds.min_slots_remaining"),
+			MaxRefreshAge:     viper.GetDuration("health.thresholds.max_refresh_age"),
+			MaxTickAge:        viper.GetDuration("health.thresholds.max_tick_age"),
+		}),
+	), nil
 }
@@ -0,0 +1,93 @@
+// Package approval holds the calls that require approval, such as external announcements, until an
+// approver has approved them. Decisions are kept in the datastore with who made them and when, so that
+// they can be audited.
+package approval
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/andrewhowdencom/ruf/internal/kv"
+)
+
+// ErrNotRequired is returned when deciding on a call that does not require approval.
+var ErrNotRequired = errors.New("call does not require approval")
+
+// Find returns the scheduled call with the given ID, or the short ID generated from it.
+func Find(store kv.Storer, id string) (*kv.ScheduledCall, error) {
+	return kv.FindScheduledCall(store, id)
+}
+
+// Status returns the approval of a call that requires one. A call that has not been decided on is
+// pending, whether or not it is due yet.
+func Status(store kv.Storer, call *kv.ScheduledCall) (*kv.Approval, error) {
+	a, err := store.GetApproval(call.ID)
+	if errors.Is(err, kv.ErrNotFound) {
+		return &kv.Approval{CampaignID: call.Campaign.ID, CallID: call.ID, Status: kv.ApprovalPending}, nil
+	}
+	return a, err
+}
+
+// Await returns the approval of a due call that requires one. The first time a pending call is awaited
+// it is recorded as pending approval, and requested reports so, so that the approvers are asked once,
+// even across restarts of the worker.
+func Await(store kv.Storer, call *kv.ScheduledCall, now time.Time) (a *kv.Approval, requested bool, err error) {
+	a, err = Status(store, call)
+	if err != nil {
+		return nil, false, err
+	}
+	if a.Status != kv.ApprovalPending || !a.RequestedAt.IsZero() {
+		return a, false, nil
+	}
+	a.RequestedAt = now.UTC()
+	if err := store.SetApproval(a); err != nil {
+		return nil, false, err
+	}
+	return a, true, nil
+}
+
+// Approve approves a call, so that it is sent when it is due. Approving a call that was rejected
+// lifts the cancellation the rejection made.
+func Approve(store kv.Storer, call *kv.ScheduledCall, by string, at time.Time) (*kv.Approval, error) {
+	a, err := decide(store, call, kv.ApprovalApproved, by, "", at)
+	if err != nil {
+		return nil, err
+	}
+	if err := store.DeleteCancellation(call.ID); err != nil && !errors.Is(err, kv.ErrNotFound) {
+		return nil, err
+	}
+	return a, nil
+}
+
+// Reject rejects a call, which cancels it, so that it is recorded as cancelled rather than sent when it
+// is due.
+func Reject(store kv.Storer, call *kv.ScheduledCall, by, reason string, at time.Time) (*kv.Approval, error) {
+	a, err := decide(store, call, kv.ApprovalRejected, by, reason, at)
+	if err != nil {
+		return nil, err
+	}
+	cancelReason := "rejected"
+	if reason != "" {
+		cancelReason += ": " + reason
+	}
+	if err := store.CancelCall(&kv.Cancellation{CallID: call.ID, By: by, Reason: cancelReason, At: a.At}); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+func decide(store kv.Storer, call *kv.ScheduledCall, status kv.ApprovalStatus, by, reason string, at time.Time) (*kv.Approval, error) {
+	if !call.RequiresApproval {
+		return nil, fmt.Errorf("%w: '%s'", ErrNotRequired, call.ID)
+	}
+	a, err := Status(store, call)
+	if err != nil {
+		return nil, err
+	}
+	a.Status, a.By, a.Reason, a.At = status, by, reason, at.UTC()
+	if err := store.SetApproval(a); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
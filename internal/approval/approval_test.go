@@ -0,0 +1,120 @@
+package approval_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/andrewhowdencom/ruf/internal/approval"
+	"github.com/andrewhowdencom/ruf/internal/datastore"
+	"github.com/andrewhowdencom/ruf/internal/kv"
+	"github.com/andrewhowdencom/ruf/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newStore(t *testing.T) (*datastore.MockStore, *kv.ScheduledCall) {
+	store := datastore.NewMockStore()
+	call := &kv.ScheduledCall{
+		Call: model.Call{
+			ID:               "launch:scheduled_at:2025-06-02T09:00:00Z:slack:#announcements",
+			Content:          "We are live!",
+			RequiresApproval: true,
+			Campaign:         model.Campaign{ID: "launch", Name: "Launch"},
+		},
+		ScheduledAt: time.Date(2025, 6, 2, 9, 0, 0, 0, time.UTC),
+	}
+	require.NoError(t, store.AddScheduledCall(call))
+	return store, call
+}
+
+func TestAwait(t *testing.T) {
+	store, call := newStore(t)
+	now := time.Date(2025, 6, 2, 9, 0, 0, 0, time.UTC)
+
+	// The call is pending before it is due, without having been requested.
+	a, err := approval.Status(store, call)
+	require.NoError(t, err)
+	assert.Equal(t, kv.ApprovalPending, a.Status)
+	assert.True(t, a.RequestedAt.IsZero())
+
+	// The approvers are asked once it is due, and not again.
+	a, requested, err := approval.Await(store, call, now)
+	require.NoError(t, err)
+	assert.True(t, requested)
+	assert.Equal(t, &kv.Approval{CampaignID: "launch", CallID: call.ID, Status: kv.ApprovalPending, RequestedAt: now}, a)
+	_, requested, err = approval.Await(store, call, now.Add(time.Minute))
+	require.NoError(t, err)
+	assert.False(t, requested)
+
+	a, err = approval.Approve(store, call, "legal", now.Add(time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, &kv.Approval{CampaignID: "launch", CallID: call.ID, Status: kv.ApprovalApproved, RequestedAt: now, By: "legal", At: now.Add(time.Hour)}, a)
+	a, requested, err = approval.Await(store, call, now.Add(2*time.Hour))
+	require.NoError(t, err)
+	assert.False(t, requested)
+	assert.Equal(t, kv.ApprovalApproved, a.Status)
+}
+
+func TestReject(t *testing.T) {
+	store, call := newStore(t)
+	at := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	// Rejecting a call cancels it.
+	a, err := approval.Reject(store, call, "legal", "wrong date", at)
+	require.NoError(t, err)
+	assert.Equal(t, kv.ApprovalRejected, a.Status)
+	c, err := store.GetCancellation(call.ID)
+	require.NoError(t, err)
+	assert.Equal(t, &kv.Cancellation{CallID: call.ID, By: "legal", Reason: "rejected: wrong date", At: at}, c)
+
+	// Approving it after all lifts the cancellation.
+	_, err = approval.Approve(store, call, "legal", at.Add(time.Hour))
+	require.NoError(t, err)
+	_, err = store.GetCancellation(call.ID)
+	assert.ErrorIs(t, err, kv.ErrNotFound)
+
+	// A call that does not require approval cannot be decided on.
+	call.RequiresApproval = false
+	_, err = approval.Approve(store, call, "legal", at)
+	assert.ErrorIs(t, err, approval.ErrNotRequired)
+}
+
+func TestConfirmation(t *testing.T) {
+	store, call := newStore(t)
+	call.Confirm = &model.Confirm{Default: model.ConfirmCancel}
+	now := time.Date(2025, 6, 2, 8, 30, 0, 0, time.UTC)
+
+	// The author is asked once.
+	c, requested, err := approval.AwaitConfirmation(store, call, now)
+	require.NoError(t, err)
+	assert.True(t, requested)
+	assert.Equal(t, &kv.Confirmation{CampaignID: "launch", CallID: call.ID, Status: kv.ConfirmationPending, RequestedAt: now}, c)
+	_, requested, err = approval.AwaitConfirmation(store, call, now.Add(time.Minute))
+	require.NoError(t, err)
+	assert.False(t, requested)
+
+	// Cancelling the call cancels it for its author, and confirming it after all lifts the cancellation.
+	c, err = approval.Cancel(store, call, "jane", now.Add(time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, kv.ConfirmationCancelled, c.Status)
+	cancellation, err := store.GetCancellation(call.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "cancelled by its author", cancellation.Reason)
+
+	c, err = approval.Confirm(store, call, "jane", now.Add(2*time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, kv.ConfirmationConfirmed, c.Status)
+	_, err = store.GetCancellation(call.ID)
+	assert.ErrorIs(t, err, kv.ErrNotFound)
+
+	// A call that lapses is cancelled by no one.
+	c, err = approval.Lapse(store, call, now.Add(time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, kv.ConfirmationCancelled, c.Status)
+	assert.Empty(t, c.By)
+
+	// A call that does not ask to be confirmed cannot be.
+	call.Confirm = nil
+	_, err = approval.Confirm(store, call, "jane", now)
+	assert.ErrorIs(t, err, approval.ErrNotConfirmable)
+}
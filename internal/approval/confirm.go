@@ -0,0 +1,94 @@
+package approval
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/andrewhowdencom/ruf/internal/kv"
+)
+
+// ErrNotConfirmable is returned when confirming a call that does not ask its author to confirm it.
+var ErrNotConfirmable = errors.New("call does not ask to be confirmed")
+
+// ConfirmationOf returns the confirmation of a call. A call whose author has not answered is pending,
+// whether or not they have been asked yet.
+func ConfirmationOf(store kv.Storer, call *kv.ScheduledCall) (*kv.Confirmation, error) {
+	c, err := store.GetConfirmation(call.ID)
+	if errors.Is(err, kv.ErrNotFound) {
+		return &kv.Confirmation{CampaignID: call.Campaign.ID, CallID: call.ID, Status: kv.ConfirmationPending}, nil
+	}
+	return c, err
+}
+
+// AwaitConfirmation returns the confirmation of a call whose author is to be asked to confirm it. The
+// first time a pending call is awaited it is recorded as requested, and requested reports so, so that
+// the author is asked once, even across restarts of the worker.
+func AwaitConfirmation(store kv.Storer, call *kv.ScheduledCall, now time.Time) (c *kv.Confirmation, requested bool, err error) {
+	c, err = ConfirmationOf(store, call)
+	if err != nil {
+		return nil, false, err
+	}
+	if c.Status != kv.ConfirmationPending || !c.RequestedAt.IsZero() {
+		return c, false, nil
+	}
+	c.RequestedAt = now.UTC()
+	if err := store.SetConfirmation(c); err != nil {
+		return nil, false, err
+	}
+	return c, true, nil
+}
+
+// Confirm confirms a call, so that it is sent when it is due. Confirming a call that was cancelled lifts
+// the cancellation.
+func Confirm(store kv.Storer, call *kv.ScheduledCall, by string, at time.Time) (*kv.Confirmation, error) {
+	c, err := answer(store, call, kv.ConfirmationConfirmed, by, at)
+	if err != nil {
+		return nil, err
+	}
+	if err := store.DeleteCancellation(call.ID); err != nil && !errors.Is(err, kv.ErrNotFound) {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Cancel cancels a call for its author, so that it is recorded as cancelled rather than sent when it is
+// due.
+func Cancel(store kv.Storer, call *kv.ScheduledCall, by string, at time.Time) (*kv.Confirmation, error) {
+	c, err := answer(store, call, kv.ConfirmationCancelled, by, at)
+	if err != nil {
+		return nil, err
+	}
+	if err := store.CancelCall(&kv.Cancellation{CallID: call.ID, By: by, Reason: "cancelled by its author", At: c.At}); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Lapse cancels a call whose author did not answer before it was due, when the call is not to be sent
+// without their confirmation.
+func Lapse(store kv.Storer, call *kv.ScheduledCall, at time.Time) (*kv.Confirmation, error) {
+	c, err := answer(store, call, kv.ConfirmationCancelled, "", at)
+	if err != nil {
+		return nil, err
+	}
+	if err := store.CancelCall(&kv.Cancellation{CallID: call.ID, By: "ruf", Reason: "not confirmed by its author before it was due", At: c.At}); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func answer(store kv.Storer, call *kv.ScheduledCall, status kv.ConfirmationStatus, by string, at time.Time) (*kv.Confirmation, error) {
+	if call.Confirm == nil {
+		return nil, fmt.Errorf("%w: '%s'", ErrNotConfirmable, call.ID)
+	}
+	c, err := ConfirmationOf(store, call)
+	if err != nil {
+		return nil, err
+	}
+	c.Status, c.By, c.At = status, by, at.UTC()
+	if err := store.SetConfirmation(c); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
@@ -0,0 +1,115 @@
+package approval
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+
+	slackclient "github.com/andrewhowdencom/ruf/internal/clients/slack"
+	rufhttp "github.com/andrewhowdencom/ruf/internal/http"
+	"github.com/andrewhowdencom/ruf/internal/kv"
+	"github.com/slack-go/slack"
+)
+
+// NewSlackHandler returns the handler of the interactions of a Slack app, which approves or rejects a
+// call when an approver presses a button of the message that asked them to, and confirms or cancels a
+// call when its author presses a button of its preview. Requests are verified with the signing secret
+// of the Slack app, and the message is replaced with the decision.
+func NewSlackHandler(store kv.Storer, signingSecret string) http.Handler {
+	httpClient := rufhttp.NewClient()
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		verifier, err := slack.NewSecretsVerifier(r.Header, signingSecret)
+		if err != nil {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		r.Body = io.NopCloser(io.TeeReader(r.Body, &verifier))
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, "invalid interaction", http.StatusBadRequest)
+			return
+		}
+		if err := verifier.Ensure(); err != nil {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		var callback slack.InteractionCallback
+		if err := json.Unmarshal([]byte(r.PostForm.Get("payload")), &callback); err != nil {
+			http.Error(w, "invalid interaction", http.StatusBadRequest)
+			return
+		}
+
+		for _, action := range callback.ActionCallback.BlockActions {
+			reply, ok := interaction(store, action.ActionID, action.Value, callback.User.Name)
+			if !ok {
+				continue
+			}
+			if callback.ResponseURL != "" {
+				msg := &slack.WebhookMessage{Text: reply, ReplaceOriginal: true}
+				if err := slack.PostWebhookCustomHTTPContext(r.Context(), callback.ResponseURL, httpClient, msg); err != nil {
+					slog.Error("failed to reply to interaction", "call_id", action.Value, "error", err)
+				}
+			}
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+}
+
+// interaction runs an action of an approval or confirmation request, and returns the reply. It reports
+// false for the actions of other messages.
+func interaction(store kv.Storer, actionID, callID, user string) (string, bool) {
+	switch actionID {
+	case slackclient.ActionApprove, slackclient.ActionReject:
+	case slackclient.ActionConfirm, slackclient.ActionCancel:
+		return confirmation(store, actionID, callID, user), true
+	default:
+		return "", false
+	}
+
+	call, err := Find(store, callID)
+	if err != nil {
+		return fmt.Sprintf("Could not find the call '%s': %s", callID, err), true
+	}
+	if actionID == slackclient.ActionApprove {
+		a, err := Approve(store, call, user, time.Now())
+		if err != nil {
+			return fmt.Sprintf("Failed to approve '%s': %s", call.ID, err), true
+		}
+		slog.Info("approved call", "call_id", call.ID, "by", a.By)
+		return fmt.Sprintf("Approved '%s' as %s; it will be sent when it is due.", call.ID, a.By), true
+	}
+	a, err := Reject(store, call, user, "", time.Now())
+	if err != nil {
+		return fmt.Sprintf("Failed to reject '%s': %s", call.ID, err), true
+	}
+	slog.Info("rejected call", "call_id", call.ID, "by", a.By)
+	return fmt.Sprintf("Rejected '%s' as %s; it will not be sent.", call.ID, a.By), true
+}
+
+// confirmation runs an action of a confirmation request, and returns the reply.
+func confirmation(store kv.Storer, actionID, callID, user string) string {
+	call, err := Find(store, callID)
+	if errors.Is(err, kv.ErrNotFound) {
+		return fmt.Sprintf("The call '%s' is no longer scheduled; it has already been sent or cancelled.", callID)
+	}
+	if err != nil {
+		return fmt.Sprintf("Could not find the call '%s': %s", callID, err)
+	}
+	if actionID == slackclient.ActionConfirm {
+		c, err := Confirm(store, call, user, time.Now())
+		if err != nil {
+			return fmt.Sprintf("Failed to confirm '%s': %s", call.ID, err)
+		}
+		slog.Info("confirmed call", "call_id", call.ID, "by", c.By)
+		return fmt.Sprintf("Confirmed '%s'; it will be sent at %s.", call.ID, call.ScheduledAt.Format(time.RFC1123))
+	}
+	c, err := Cancel(store, call, user, time.Now())
+	if err != nil {
+		return fmt.Sprintf("Failed to cancel '%s': %s", call.ID, err)
+	}
+	slog.Info("cancelled call for its author", "call_id", call.ID, "by", c.By)
+	return fmt.Sprintf("Cancelled '%s'; it will not be sent.", call.ID)
+}
@@ -0,0 +1,78 @@
+package approval_test
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/andrewhowdencom/ruf/internal/approval"
+	slackclient "github.com/andrewhowdencom/ruf/internal/clients/slack"
+	"github.com/andrewhowdencom/ruf/internal/kv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSlackHandler(t *testing.T) {
+	store, call := newStore(t)
+	handler := approval.NewSlackHandler(store, "signing-secret")
+
+	// The decision replaces the message that asked for it.
+	var replies []map[string]interface{}
+	responses := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var reply map[string]interface{}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&reply))
+		replies = append(replies, reply)
+	}))
+	defer responses.Close()
+
+	interact := func(actionID, secret string) *httptest.ResponseRecorder {
+		payload, err := json.Marshal(map[string]interface{}{
+			"type":         "block_actions",
+			"user":         map[string]string{"name": "jane"},
+			"response_url": responses.URL,
+			"actions":      []map[string]string{{"action_id": actionID, "block_id": "approval", "value": call.ID, "type": "button"}},
+		})
+		require.NoError(t, err)
+		body := url.Values{"payload": {string(payload)}}.Encode()
+		ts := strconv.FormatInt(time.Now().Unix(), 10)
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write([]byte("v0:" + ts + ":" + body))
+
+		req := httptest.NewRequest(http.MethodPost, "/slack/interactions", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.Header.Set("X-Slack-Request-Timestamp", ts)
+		req.Header.Set("X-Slack-Signature", "v0="+hex.EncodeToString(mac.Sum(nil)))
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	assert.Equal(t, http.StatusUnauthorized, interact(slackclient.ActionApprove, "wrong").Code)
+	a, err := approval.Status(store, call)
+	require.NoError(t, err)
+	assert.Equal(t, kv.ApprovalPending, a.Status)
+
+	require.Equal(t, http.StatusOK, interact(slackclient.ActionApprove, "signing-secret").Code)
+	a, err = approval.Status(store, call)
+	require.NoError(t, err)
+	assert.Equal(t, kv.ApprovalApproved, a.Status)
+	assert.Equal(t, "jane", a.By)
+
+	require.Equal(t, http.StatusOK, interact(slackclient.ActionReject, "signing-secret").Code)
+	a, err = approval.Status(store, call)
+	require.NoError(t, err)
+	assert.Equal(t, kv.ApprovalRejected, a.Status)
+
+	require.Len(t, replies, 2)
+	assert.Equal(t, true, replies[0]["replace_original"])
+	assert.Contains(t, replies[0]["text"], "Approved")
+	assert.Contains(t, replies[1]["text"], "Rejected")
+}
@@ -0,0 +1,172 @@
+// Package assets prefetches the remote content that calls render, such as the templates they include
+// and the data they are rendered with, and keeps it by the hash of its content. Calls are pinned to
+// those hashes when their sources are refreshed, so that they render the same content when they are
+// sent, whether or not it can still be fetched by then.
+package assets
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"text/template"
+
+	"github.com/andrewhowdencom/ruf/internal/model"
+	"github.com/andrewhowdencom/ruf/internal/sourcer"
+	"gopkg.in/yaml.v3"
+)
+
+// ErrInvalidData is returned when the document a call takes its data from is not a mapping.
+var ErrInvalidData = errors.New("data_from is not a mapping")
+
+// includePattern matches the URLs given to the include function of a template.
+var includePattern = regexp.MustCompile(`\binclude\s+"([^"]+)"`)
+
+// Cache keeps the content of assets under a directory, by the SHA-256 of the content.
+type Cache struct {
+	dir     string
+	fetcher sourcer.Fetcher
+}
+
+// New creates a cache of assets under the directory, fetched with the fetcher.
+func New(dir string, fetcher sourcer.Fetcher) *Cache {
+	return &Cache{dir: dir, fetcher: fetcher}
+}
+
+// References returns the URLs of the assets a call renders: its data_from, and those its subject and
+// content include.
+func References(call *model.Call) []string {
+	var urls []string
+	if call.DataFrom != "" {
+		urls = append(urls, call.DataFrom)
+	}
+	for _, text := range []string{call.Subject, call.Content} {
+		for _, match := range includePattern.FindAllStringSubmatch(text, -1) {
+			urls = append(urls, match[1])
+		}
+	}
+	return urls
+}
+
+// Prefetch fetches the assets of every call of the sources, and pins the calls to them. A call whose
+// assets cannot be fetched is left without a pin for them, and they are fetched when it is sent.
+func (c *Cache) Prefetch(sources []*sourcer.Source) error {
+	var errs []error
+	for _, source := range sources {
+		for i := range source.Calls {
+			if err := c.Pin(&source.Calls[i]); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Pin fetches the assets of a call, keeps them, and records the hash of each on the call.
+func (c *Cache) Pin(call *model.Call) error {
+	var errs []error
+	for _, url := range References(call) {
+		data, _, err := c.fetcher.Fetch(url)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to fetch asset %s of call %s: %w", url, call.ID, err))
+			continue
+		}
+		hash, err := c.put(data)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to keep asset %s of call %s: %w", url, call.ID, err))
+			continue
+		}
+		if call.Assets == nil {
+			call.Assets = make(map[string]string)
+		}
+		call.Assets[url] = hash
+	}
+	return errors.Join(errs...)
+}
+
+// Get returns the content of an asset of a call, as it was when the call was pinned to it. Assets that
+// are not pinned, or are no longer kept, are fetched again.
+func (c *Cache) Get(call *model.Call, url string) ([]byte, error) {
+	if hash, ok := call.Assets[url]; ok && len(hash) == 2*sha256.Size {
+		data, err := os.ReadFile(c.path(hash))
+		if err == nil {
+			return data, nil
+		}
+		slog.Warn("pinned asset is no longer kept, fetching it again", "call_id", call.ID, "url", url, "hash", hash, "error", err)
+	}
+
+	data, _, err := c.fetcher.Fetch(url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch asset %s: %w", url, err)
+	}
+	return data, nil
+}
+
+// Data returns the data a call is rendered with: the fields of the JSON or YAML document of its
+// data_from, if any, overridden by its own data.
+func (c *Cache) Data(call *model.Call) (map[string]interface{}, error) {
+	if call.DataFrom == "" {
+		return call.Data, nil
+	}
+	raw, err := c.Get(call, call.DataFrom)
+	if err != nil {
+		return nil, err
+	}
+	var data map[string]interface{}
+	if err := yaml.Unmarshal(raw, &data); err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", ErrInvalidData, call.DataFrom, err)
+	}
+	if data == nil {
+		data = make(map[string]interface{})
+	}
+	for k, v := range call.Data {
+		data[k] = v
+	}
+	return data, nil
+}
+
+// Funcs returns the functions that templates of a call render its assets with.
+func (c *Cache) Funcs(call *model.Call) template.FuncMap {
+	return template.FuncMap{
+		"include": func(url string) (string, error) {
+			data, err := c.Get(call, url)
+			return string(data), err
+		},
+	}
+}
+
+// put keeps content by its hash, which it returns.
+func (c *Cache) put(data []byte) (string, error) {
+	sum := sha256.Sum256(data)
+	hash := hex.EncodeToString(sum[:])
+	path := c.path(hash)
+	if _, err := os.Stat(path); err == nil {
+		return hash, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return "", err
+	}
+	// The content is written aside and moved into place, so that a reader never sees it in part.
+	f, err := os.CreateTemp(filepath.Dir(path), hash+".*")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return "", err
+	}
+	if err := f.Close(); err != nil {
+		return "", err
+	}
+	return hash, os.Rename(f.Name(), path)
+}
+
+func (c *Cache) path(hash string) string {
+	return filepath.Join(c.dir, hash[:2], hash)
+}
@@ -0,0 +1,72 @@
+package assets
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/andrewhowdencom/ruf/internal/model"
+	"github.com/andrewhowdencom/ruf/internal/processor"
+	"github.com/andrewhowdencom/ruf/internal/sourcer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCache_Prefetch(t *testing.T) {
+	available := true
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !available {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		switch r.URL.Path {
+		case "/footer.md":
+			w.Write([]byte("-- The Release Team"))
+		case "/data.json":
+			w.Write([]byte(`{"version": "1.2.0", "team": "Release"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	fetcher := sourcer.NewCompositeFetcher()
+	fetcher.AddFetcher("http", sourcer.NewHTTPFetcher(server.Client()))
+	cache := New(t.TempDir(), fetcher)
+
+	source := &sourcer.Source{Calls: []model.Call{{
+		ID:       "release",
+		Content:  `Version {{ .version }} by {{ .team }} is out. {{ include "` + server.URL + `/footer.md" }}`,
+		Data:     map[string]interface{}{"team": "Platform"},
+		DataFrom: server.URL + "/data.json",
+	}}}
+	require.NoError(t, cache.Prefetch([]*sourcer.Source{source}))
+	call := &source.Calls[0]
+	assert.Len(t, call.Assets, 2)
+
+	// The call renders the content it was pinned to, once it can no longer be fetched.
+	available = false
+	data, err := cache.Data(call)
+	require.NoError(t, err)
+	content, err := processor.NewTemplateProcessor(processor.WithFuncs(cache.Funcs(call))).Process(call.Content, data)
+	require.NoError(t, err)
+	assert.Equal(t, "Version 1.2.0 by Platform is out. -- The Release Team", content)
+
+	// Assets that were never pinned are fetched when they are rendered.
+	_, err = cache.Data(&model.Call{ID: "unpinned", DataFrom: server.URL + "/data.json"})
+	assert.Error(t, err)
+}
+
+func TestReferences(t *testing.T) {
+	call := &model.Call{
+		Subject:  `{{ include "https://example.com/subject.txt" }}`,
+		Content:  `{{ include "https://example.com/a.md" }} and {{include "https://example.com/b.md"}}`,
+		DataFrom: "https://example.com/data.yaml",
+	}
+	assert.Equal(t, []string{
+		"https://example.com/data.yaml",
+		"https://example.com/subject.txt",
+		"https://example.com/a.md",
+		"https://example.com/b.md",
+	}, References(call))
+}
@@ -0,0 +1,144 @@
+package calendar
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Err* are common errors returned when loading holidays.
+var (
+	ErrFetchFailed       = errors.New("failed to fetch holidays")
+	ErrInvalidCalendar   = errors.New("invalid holiday calendar")
+	ErrInvalidNthWeekday = errors.New("invalid nth weekday")
+)
+
+// Holiday is a day on which calls may be skipped or moved.
+type Holiday struct {
+	Date time.Time `json:"date" yaml:"date"`
+	Name string    `json:"name" yaml:"name"`
+}
+
+// Provider is a source of holidays.
+type Provider interface {
+	// Holidays returns the holidays that fall in the given year.
+	Holidays(ctx context.Context, year int) ([]Holiday, error)
+}
+
+// Calendar answers whether a given day is a holiday or a business day.
+type Calendar struct {
+	holidays map[string]string
+}
+
+// Load builds a calendar from the holidays every provider returns for the years between from and to.
+func Load(ctx context.Context, from, to time.Time, providers ...Provider) (*Calendar, error) {
+	c := &Calendar{holidays: make(map[string]string)}
+	for _, provider := range providers {
+		for year := from.Year(); year <= to.Year(); year++ {
+			holidays, err := provider.Holidays(ctx, year)
+			if err != nil {
+				return nil, err
+			}
+			for _, holiday := range holidays {
+				c.holidays[holiday.Date.Format(time.DateOnly)] = holiday.Name
+			}
+		}
+	}
+	return c, nil
+}
+
+// Holiday returns the name of the holiday on the day of t, in t's location.
+func (c *Calendar) Holiday(t time.Time) (string, bool) {
+	if c == nil {
+		return "", false
+	}
+	name, ok := c.holidays[t.Format(time.DateOnly)]
+	return name, ok
+}
+
+// IsBusinessDay reports whether the day of t is neither a weekend nor a holiday.
+func (c *Calendar) IsBusinessDay(t time.Time) bool {
+	if t.Weekday() == time.Saturday || t.Weekday() == time.Sunday {
+		return false
+	}
+	_, holiday := c.Holiday(t)
+	return !holiday
+}
+
+// NextBusinessDay returns t moved forward by whole days until it falls on a business day,
+// keeping its wall clock time. If t already falls on a business day it is returned unchanged.
+func (c *Calendar) NextBusinessDay(t time.Time) (time.Time, error) {
+	// A year is far more than any real run of holidays, and guards against a calendar that marks
+	// every day as a holiday.
+	for i := 0; i < 366; i++ {
+		if c.IsBusinessDay(t) {
+			return t, nil
+		}
+		t = t.AddDate(0, 0, 1)
+	}
+	return time.Time{}, fmt.Errorf("%w: no business day within a year of %s", ErrInvalidCalendar, t.Format(time.DateOnly))
+}
+
+// FirstBusinessDay returns midnight, in loc, of the first business day of the month. It returns
+// false if every day of the month is a weekend or a holiday.
+func (c *Calendar) FirstBusinessDay(year int, month time.Month, loc *time.Location) (time.Time, bool) {
+	for day := time.Date(year, month, 1, 0, 0, 0, 0, loc); day.Month() == month; day = day.AddDate(0, 0, 1) {
+		if c.IsBusinessDay(day) {
+			return day, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// LastBusinessDay returns midnight, in loc, of the last business day of the month. It returns
+// false if every day of the month is a weekend or a holiday.
+func (c *Calendar) LastBusinessDay(year int, month time.Month, loc *time.Location) (time.Time, bool) {
+	for day := time.Date(year, month+1, 0, 0, 0, 0, 0, loc); day.Month() == month; day = day.AddDate(0, 0, -1) {
+		if c.IsBusinessDay(day) {
+			return day, true
+		}
+	}
+	return time.Time{}, false
+}
+
+var ordinals = map[string]int{
+	"1st": 1, "first": 1,
+	"2nd": 2, "second": 2,
+	"3rd": 3, "third": 3,
+	"4th": 4, "fourth": 4,
+	"5th": 5, "fifth": 5,
+	"last": -1,
+}
+
+// ParseNthWeekday parses a phrase such as "2nd tuesday" or "last friday" into the ordinal of the
+// weekday within a month, where -1 is the last, and the weekday itself.
+func ParseNthWeekday(s string) (int, time.Weekday, error) {
+	fields := strings.Fields(strings.ToLower(s))
+	if len(fields) != 2 {
+		return 0, 0, fmt.Errorf("%w: '%s' must be an ordinal and a weekday, such as '2nd tuesday'", ErrInvalidNthWeekday, s)
+	}
+	n, ok := ordinals[fields[0]]
+	if !ok {
+		return 0, 0, fmt.Errorf("%w: unknown ordinal '%s'", ErrInvalidNthWeekday, fields[0])
+	}
+	for weekday := time.Sunday; weekday <= time.Saturday; weekday++ {
+		if strings.ToLower(weekday.String()) == fields[1] {
+			return n, weekday, nil
+		}
+	}
+	return 0, 0, fmt.Errorf("%w: unknown weekday '%s'", ErrInvalidNthWeekday, fields[1])
+}
+
+// NthWeekday returns midnight, in loc, of the nth weekday of the month, where -1 is the last. It
+// returns false if the month has no such day, such as a 5th Monday.
+func NthWeekday(year int, month time.Month, n int, weekday time.Weekday, loc *time.Location) (time.Time, bool) {
+	if n < 0 {
+		last := time.Date(year, month+1, 0, 0, 0, 0, 0, loc)
+		return last.AddDate(0, 0, -((int(last.Weekday()) - int(weekday) + 7) % 7)), true
+	}
+	first := time.Date(year, month, 1, 0, 0, 0, 0, loc)
+	day := first.AddDate(0, 0, (int(weekday)-int(first.Weekday())+7)%7+7*(n-1))
+	return day, day.Month() == month
+}
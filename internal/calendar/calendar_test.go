@@ -0,0 +1,141 @@
+package calendar_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/andrewhowdencom/ruf/internal/calendar"
+	"github.com/stretchr/testify/assert"
+)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestCalendar(t *testing.T) {
+	cal, err := calendar.Load(context.Background(), date(2025, 1, 1), date(2026, 12, 31), calendar.NewStatic(
+		calendar.Holiday{Date: date(2025, 12, 25), Name: "Christmas Day"},
+		calendar.Holiday{Date: date(2025, 12, 26), Name: "Boxing Day"},
+	))
+	assert.NoError(t, err)
+
+	name, ok := cal.Holiday(time.Date(2025, 12, 25, 9, 0, 0, 0, time.UTC))
+	assert.True(t, ok)
+	assert.Equal(t, "Christmas Day", name)
+
+	assert.True(t, cal.IsBusinessDay(date(2025, 12, 24)))
+	assert.False(t, cal.IsBusinessDay(date(2025, 12, 27)), "Saturday")
+
+	next, err := cal.NextBusinessDay(time.Date(2025, 12, 25, 9, 30, 0, 0, time.UTC))
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2025, 12, 29, 9, 30, 0, 0, time.UTC), next)
+}
+
+func TestLoadStatic(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "holidays.yaml")
+	assert.NoError(t, os.WriteFile(path, []byte("holidays:\n  - date: 2025-12-25\n    name: Christmas Day\n  - date: 2026-01-01\n    name: New Year's Day\n"), 0644))
+
+	p, err := calendar.LoadStatic(path)
+	assert.NoError(t, err)
+	holidays, err := p.Holidays(context.Background(), 2025)
+	assert.NoError(t, err)
+	assert.Equal(t, []calendar.Holiday{{Date: date(2025, 12, 25), Name: "Christmas Day"}}, holidays)
+
+	assert.NoError(t, os.WriteFile(path, []byte("holidays:\n  - date: 25/12/2025\n"), 0644))
+	_, err = calendar.LoadStatic(path)
+	assert.ErrorIs(t, err, calendar.ErrInvalidCalendar)
+}
+
+func TestICal(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "BEGIN:VCALENDAR\r\n"+
+			"BEGIN:VEVENT\r\nDTSTART;VALUE=DATE:20251225\r\nSUMMARY:Christmas\r\n  Day\r\nEND:VEVENT\r\n"+
+			"BEGIN:VEVENT\r\nDTSTART:20260101T000000Z\r\nSUMMARY:New Year's Day\r\nEND:VEVENT\r\n"+
+			"END:VCALENDAR\r\n")
+	}))
+	defer server.Close()
+
+	holidays, err := calendar.NewICal(server.URL, calendar.WithHTTPClient(server.Client())).Holidays(context.Background(), 2025)
+	assert.NoError(t, err)
+	assert.Equal(t, []calendar.Holiday{{Date: date(2025, 12, 25), Name: "Christmas Day"}}, holidays)
+}
+
+func TestPublicHolidayAPI(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/PublicHolidays/2025/DE" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, `[{"date":"2025-12-25","localName":"Erster Weihnachtstag","name":"Christmas Day"}]`)
+	}))
+	defer server.Close()
+
+	api := calendar.NewPublicHolidayAPI("de", calendar.WithEndpoint(server.URL), calendar.WithHTTPClient(server.Client()))
+	holidays, err := api.Holidays(context.Background(), 2025)
+	assert.NoError(t, err)
+	assert.Equal(t, []calendar.Holiday{{Date: date(2025, 12, 25), Name: "Erster Weihnachtstag"}}, holidays)
+
+	_, err = api.Holidays(context.Background(), 2024)
+	assert.ErrorIs(t, err, calendar.ErrFetchFailed)
+}
+
+func TestNthWeekday(t *testing.T) {
+	tests := []struct {
+		phrase string
+		month  time.Month
+		want   time.Time
+		ok     bool
+	}{
+		{"2nd tuesday", time.March, date(2025, 3, 11), true},
+		{"First Monday", time.September, date(2025, 9, 1), true},
+		{"last friday", time.October, date(2025, 10, 31), true},
+		{"last sunday", time.March, date(2025, 3, 30), true},
+		{"5th monday", time.February, date(2025, 3, 3), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.phrase, func(t *testing.T) {
+			n, weekday, err := calendar.ParseNthWeekday(tt.phrase)
+			assert.NoError(t, err)
+			got, ok := calendar.NthWeekday(2025, tt.month, n, weekday, time.UTC)
+			assert.Equal(t, tt.ok, ok)
+			if tt.ok {
+				assert.Equal(t, tt.want, got)
+			}
+		})
+	}
+
+	for _, phrase := range []string{"tuesday", "6th tuesday", "2nd tues"} {
+		_, _, err := calendar.ParseNthWeekday(phrase)
+		assert.ErrorIs(t, err, calendar.ErrInvalidNthWeekday, phrase)
+	}
+}
+
+func TestBusinessDays(t *testing.T) {
+	cal, err := calendar.Load(context.Background(), date(2025, 1, 1), date(2025, 12, 31), calendar.NewStatic(
+		calendar.Holiday{Date: date(2025, 12, 1), Name: "Company Day"},
+	))
+	assert.NoError(t, err)
+
+	// 2025-11-01 is a Saturday and 2025-11-30 is a Sunday.
+	first, ok := cal.FirstBusinessDay(2025, time.November, time.UTC)
+	assert.True(t, ok)
+	assert.Equal(t, date(2025, 11, 3), first)
+	last, ok := cal.LastBusinessDay(2025, time.November, time.UTC)
+	assert.True(t, ok)
+	assert.Equal(t, date(2025, 11, 28), last)
+
+	first, ok = cal.FirstBusinessDay(2025, time.December, time.UTC)
+	assert.True(t, ok)
+	assert.Equal(t, date(2025, 12, 2), first, "skips the holiday")
+
+	var none *calendar.Calendar
+	first, ok = none.FirstBusinessDay(2025, time.December, time.UTC)
+	assert.True(t, ok)
+	assert.Equal(t, date(2025, 12, 1), first, "weekends only without a calendar")
+}
@@ -0,0 +1,259 @@
+package calendar
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Chinese is the Chinese lunisolar calendar, as observed in China (UTC+8).
+//
+// Months start on the day of the new moon, and month 11 contains the winter solstice. When 13
+// months fall between two solstices, the first month without a major solar term is a leap month,
+// numbered after the month before it. New moons and solar longitudes use the algorithms in
+// Meeus, "Astronomical Algorithms", which are accurate to a few minutes; a new moon or solar term
+// within minutes of midnight in Beijing may fall on the neighbouring day.
+//
+// The year of a Chinese date is the Gregorian year in which its new year falls.
+type Chinese struct{}
+
+const (
+	beijingOffset = 8.0 / 24     // Days ahead of UTC.
+	fixedEpochJD  = 1721424.5    // The Julian day of midnight UTC before fixed day 1.
+	synodicMonth  = 29.530588861 // The mean length of a lunar month, in days.
+	deltaT        = 69.0 / 86400 // Terrestrial time ahead of universal time, in days.
+)
+
+// Name returns "chinese".
+func (Chinese) Name() string { return "chinese" }
+
+// ParseDate parses a day and a month number, such as "15 8" for the Mid-Autumn Festival, or a
+// leap month, such as "1 leap 4".
+func (Chinese) ParseDate(s string) (Date, error) {
+	day, rest, err := parseDayMonth(s)
+	if err != nil {
+		return Date{}, err
+	}
+	leap := false
+	if after, ok := strings.CutPrefix(rest, "leap "); ok {
+		leap, rest = true, after
+	}
+	month, err := strconv.Atoi(rest)
+	if err != nil || month < 1 || month > 12 {
+		return Date{}, fmt.Errorf("%w: invalid chinese month '%s': must be a number from 1 to 12", ErrInvalidDate, rest)
+	}
+	if day > 30 {
+		return Date{}, fmt.Errorf("%w: chinese months have at most 30 days", ErrInvalidDate)
+	}
+	return Date{Month: month, Day: day, Leap: leap}, nil
+}
+
+// YearOf returns the Gregorian year in which the Chinese year of the date began.
+func (Chinese) YearOf(t time.Time) int {
+	year := t.Year()
+	if fixedFromTime(t) < chineseYear(year)[0].start {
+		year--
+	}
+	return year
+}
+
+// MonthName returns the number of a Chinese month, as they are not named, such as "Month 8" or
+// "Leap month 4".
+func (Chinese) MonthName(d Date) string {
+	if d.Leap {
+		return fmt.Sprintf("Leap month %d", d.Month)
+	}
+	return fmt.Sprintf("Month %d", d.Month)
+}
+
+// ToGregorian returns the Gregorian date of a Chinese date.
+func (Chinese) ToGregorian(d Date) (time.Time, bool) {
+	for _, m := range chineseYear(d.Year) {
+		if m.number == d.Month && m.leap == d.Leap {
+			if d.Day > m.end-m.start {
+				return time.Time{}, false
+			}
+			return timeFromFixed(m.start + d.Day - 1), true
+		}
+	}
+	return time.Time{}, false
+}
+
+// chineseMonth is a month of the Chinese calendar, running from fixed day start until end.
+type chineseMonth struct {
+	start, end int
+	number     int
+	leap       bool
+}
+
+// chineseYear returns the months of the Chinese year whose new year falls in the Gregorian year.
+func chineseYear(year int) []chineseMonth {
+	months := append(chineseSui(year-1), chineseSui(year)...)
+	first := -1
+	for i, m := range months {
+		if m.number == 1 && !m.leap {
+			if first >= 0 {
+				return months[first:i]
+			}
+			first = i
+		}
+	}
+	return months[first:]
+}
+
+// chineseSui returns the months from the one containing the winter solstice of the Gregorian
+// year until the one containing the next solstice, numbered from 11.
+func chineseSui(year int) []chineseMonth {
+	k := newMoonOnOrBefore(beijingDay(winterSolstice(year)))
+	next := newMoonOnOrBefore(beijingDay(winterSolstice(year + 1)))
+
+	months := make([]chineseMonth, 0, next-k)
+	for i := k; i < next; i++ {
+		months = append(months, chineseMonth{start: beijingDay(newMoon(i)), end: beijingDay(newMoon(i + 1))})
+	}
+
+	leap := -1
+	if len(months) == 13 {
+		for i := 1; i < len(months); i++ {
+			if !hasMajorTerm(months[i]) {
+				leap = i
+				break
+			}
+		}
+	}
+
+	number := 10
+	for i := range months {
+		if i == leap {
+			months[i].number, months[i].leap = number, true
+			continue
+		}
+		number = number%12 + 1
+		months[i].number = number
+	}
+	return months
+}
+
+// hasMajorTerm reports whether the sun's longitude passes a multiple of 30° during the month.
+func hasMajorTerm(m chineseMonth) bool {
+	return int(solarLongitude(beijingMidnight(m.start))/30) != int(solarLongitude(beijingMidnight(m.end))/30)
+}
+
+// beijingDay returns the fixed day number of a Julian day in Beijing.
+func beijingDay(jd float64) int {
+	return int(math.Floor(jd + beijingOffset - fixedEpochJD))
+}
+
+// beijingMidnight returns the Julian day of the start of a fixed day in Beijing.
+func beijingMidnight(fixed int) float64 {
+	return float64(fixed) + fixedEpochJD - beijingOffset
+}
+
+// newMoonOnOrBefore returns the number of the last new moon on or before the fixed day in Beijing.
+func newMoonOnOrBefore(fixed int) int {
+	k := int(math.Round((beijingMidnight(fixed) - 2451550.09766) / synodicMonth))
+	for beijingDay(newMoon(k)) > fixed {
+		k--
+	}
+	for beijingDay(newMoon(k+1)) <= fixed {
+		k++
+	}
+	return k
+}
+
+// newMoon returns the Julian day, in universal time, of new moon number k, counted from the new
+// moon of January 6th, 2000. See Meeus, chapter 49.
+func newMoon(k int) float64 {
+	kf := float64(k)
+	t := kf / 1236.85
+	t2, t3, t4 := t*t, t*t*t, t*t*t*t
+
+	jde := 2451550.09766 + synodicMonth*kf + 0.00015437*t2 - 0.000000150*t3 + 0.00000000073*t4
+	e := 1 - 0.002516*t - 0.0000074*t2
+	m := radians(2.5534 + 29.10535670*kf - 0.0000014*t2 - 0.00000011*t3)
+	mp := radians(201.5643 + 385.81693528*kf + 0.0107582*t2 + 0.00001238*t3 - 0.000000058*t4)
+	f := radians(160.7108 + 390.67050284*kf - 0.0016118*t2 - 0.00000227*t3 + 0.000000011*t4)
+	omega := radians(124.7746 - 1.56375588*kf + 0.0020672*t2 + 0.00000215*t3)
+
+	jde += -0.40720*math.Sin(mp) +
+		0.17241*e*math.Sin(m) +
+		0.01608*math.Sin(2*mp) +
+		0.01039*math.Sin(2*f) +
+		0.00739*e*math.Sin(mp-m) -
+		0.00514*e*math.Sin(mp+m) +
+		0.00208*e*e*math.Sin(2*m) -
+		0.00111*math.Sin(mp-2*f) -
+		0.00057*math.Sin(mp+2*f) +
+		0.00056*e*math.Sin(2*mp+m) -
+		0.00042*math.Sin(3*mp) +
+		0.00042*e*math.Sin(m+2*f) +
+		0.00038*e*math.Sin(m-2*f) -
+		0.00024*e*math.Sin(2*mp-m) -
+		0.00017*math.Sin(omega) -
+		0.00007*math.Sin(mp+2*m) +
+		0.00004*math.Sin(2*mp-2*f) +
+		0.00004*math.Sin(3*m) +
+		0.00003*math.Sin(mp+m-2*f) +
+		0.00003*math.Sin(2*mp+2*f) -
+		0.00003*math.Sin(mp+m+2*f) +
+		0.00003*math.Sin(mp-m+2*f) -
+		0.00002*math.Sin(mp-m-2*f) -
+		0.00002*math.Sin(3*mp+m) +
+		0.00002*math.Sin(4*mp)
+
+	// Planetary arguments.
+	planetary := []struct{ coefficient, base, rate float64 }{
+		{0.000325, 299.77, 0.107408},
+		{0.000165, 251.88, 0.016321},
+		{0.000164, 251.83, 26.651886},
+		{0.000126, 349.42, 36.412478},
+		{0.000110, 84.66, 18.206239},
+		{0.000062, 141.74, 53.303771},
+		{0.000060, 207.14, 2.453732},
+		{0.000056, 154.84, 7.306860},
+		{0.000047, 34.52, 27.261239},
+		{0.000042, 207.19, 0.121824},
+		{0.000040, 291.34, 1.844379},
+		{0.000037, 161.72, 24.198154},
+		{0.000035, 239.56, 25.513099},
+		{0.000023, 331.55, 3.592518},
+	}
+	for i, p := range planetary {
+		a := p.base + p.rate*kf
+		if i == 0 {
+			a -= 0.009173 * t2
+		}
+		jde += p.coefficient * math.Sin(radians(a))
+	}
+
+	return jde - deltaT
+}
+
+// solarLongitude returns the apparent longitude of the sun, in degrees, at a Julian day. See
+// Meeus, chapter 25.
+func solarLongitude(jd float64) float64 {
+	t := (jd + deltaT - 2451545) / 36525
+	l0 := 280.46646 + 36000.76983*t + 0.0003032*t*t
+	m := radians(357.52911 + 35999.05029*t - 0.0001537*t*t)
+	c := (1.914602-0.004817*t-0.000014*t*t)*math.Sin(m) +
+		(0.019993-0.000101*t)*math.Sin(2*m) +
+		0.000289*math.Sin(3*m)
+	omega := radians(125.04 - 1934.136*t)
+	return math.Mod(math.Mod(l0+c-0.00569-0.00478*math.Sin(omega), 360)+360, 360)
+}
+
+// winterSolstice returns the Julian day, in universal time, of the December solstice of the year.
+func winterSolstice(year int) float64 {
+	jd := beijingMidnight(fixedFromTime(fromGregorian(year, time.December, 21)))
+	for i := 0; i < 5; i++ {
+		diff := math.Mod(270-solarLongitude(jd)+540, 360) - 180
+		jd += diff * 365.2422 / 360
+	}
+	return jd
+}
+
+func radians(degrees float64) float64 {
+	return degrees * math.Pi / 180
+}
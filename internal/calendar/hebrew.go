@@ -0,0 +1,182 @@
+package calendar
+
+import (
+	"fmt"
+	"time"
+)
+
+// Hebrew is the Hebrew calendar. Months are numbered from Nisan, so Tishrei, which starts the
+// year, is month 7 and Adar II, in leap years, is month 13.
+//
+// The arithmetic follows Reingold and Dershowitz, "Calendrical Calculations".
+type Hebrew struct{}
+
+const hebrewEpoch = -1373427 // Tishrei 1, AM 1, as a fixed day number.
+
+var hebrewMonths = [][]string{
+	{"Nisan"},
+	{"Iyyar", "iyar"},
+	{"Sivan"},
+	{"Tammuz", "tamuz"},
+	{"Av"},
+	{"Elul"},
+	{"Tishrei", "tishri"},
+	{"Cheshvan", "heshvan", "marcheshvan"},
+	{"Kislev"},
+	{"Tevet"},
+	{"Shevat", "shvat"},
+	{"Adar", "adar i"},
+	{"Adar II"},
+}
+
+// Name returns "hebrew".
+func (Hebrew) Name() string { return "hebrew" }
+
+// ParseDate parses a date such as "1 tishrei". In leap years "adar" refers to Adar II, when
+// Purim is observed; "adar i" and "adar ii" name the months explicitly.
+func (Hebrew) ParseDate(s string) (Date, error) {
+	day, name, err := parseDayMonth(s)
+	if err != nil {
+		return Date{}, err
+	}
+	month, ok := monthNumber(hebrewMonths, name)
+	if !ok {
+		return Date{}, fmt.Errorf("%w: unknown hebrew month '%s'", ErrInvalidDate, name)
+	}
+	if day > 30 {
+		return Date{}, fmt.Errorf("%w: hebrew months have at most 30 days", ErrInvalidDate)
+	}
+	// Leap marks a plain "adar", which moves to Adar II in leap years.
+	return Date{Month: month, Day: day, Leap: name == "adar"}, nil
+}
+
+// YearOf returns the Hebrew year of the Gregorian date.
+func (h Hebrew) YearOf(t time.Time) int {
+	fixed := fixedFromTime(t)
+	year := t.Year() + 3760
+	if fixed >= hebrewNewYear(year+1) {
+		year++
+	}
+	return year
+}
+
+// MonthName returns the name of a Hebrew month, such as "Tishrei". In leap years, month 12 is Adar I.
+func (Hebrew) MonthName(d Date) string {
+	if d.Month == 12 && hebrewLeapYear(d.Year) {
+		// Leap marks a plain "adar", which is Adar II in leap years.
+		if d.Leap {
+			return "Adar II"
+		}
+		return "Adar I"
+	}
+	return monthName(hebrewMonths, d.Month)
+}
+
+// ToGregorian returns the Gregorian date of a Hebrew date.
+func (Hebrew) ToGregorian(d Date) (time.Time, bool) {
+	month := d.Month
+	if hebrewLeapYear(d.Year) && d.Leap && month == 12 {
+		month = 13
+	}
+	if !hebrewLeapYear(d.Year) && month == 13 {
+		month = 12
+	}
+	if d.Day > hebrewMonthLength(month, d.Year) {
+		return time.Time{}, false
+	}
+
+	fixed := hebrewNewYear(d.Year) + d.Day - 1
+	if month < 7 {
+		for m := 7; m <= hebrewLastMonth(d.Year); m++ {
+			fixed += hebrewMonthLength(m, d.Year)
+		}
+		for m := 1; m < month; m++ {
+			fixed += hebrewMonthLength(m, d.Year)
+		}
+	} else {
+		for m := 7; m < month; m++ {
+			fixed += hebrewMonthLength(m, d.Year)
+		}
+	}
+	return timeFromFixed(fixed), true
+}
+
+func hebrewLeapYear(year int) bool {
+	return mod(7*year+1, 19) < 7
+}
+
+func hebrewLastMonth(year int) int {
+	if hebrewLeapYear(year) {
+		return 13
+	}
+	return 12
+}
+
+// hebrewElapsedDays returns the days from the epoch to the molad of Tishrei, delayed to avoid
+// Sunday, Wednesday and Friday.
+func hebrewElapsedDays(year int) int {
+	monthsElapsed := floorDiv(235*year-234, 19)
+	partsElapsed := 12084 + 13753*monthsElapsed
+	days := 29*monthsElapsed + floorDiv(partsElapsed, 25920)
+	if mod(3*(days+1), 7) < 3 {
+		return days + 1
+	}
+	return days
+}
+
+// hebrewYearLengthCorrection delays the new year to keep year lengths valid.
+func hebrewYearLengthCorrection(year int) int {
+	ny0, ny1, ny2 := hebrewElapsedDays(year-1), hebrewElapsedDays(year), hebrewElapsedDays(year+1)
+	switch {
+	case ny2-ny1 == 356:
+		return 2
+	case ny1-ny0 == 382:
+		return 1
+	}
+	return 0
+}
+
+func hebrewNewYear(year int) int {
+	return hebrewEpoch + hebrewElapsedDays(year) + hebrewYearLengthCorrection(year)
+}
+
+func hebrewMonthLength(month, year int) int {
+	daysInYear := hebrewNewYear(year+1) - hebrewNewYear(year)
+	switch {
+	case month == 2, month == 4, month == 6, month == 10, month == 13:
+		return 29
+	case month == 12 && !hebrewLeapYear(year):
+		return 29
+	case month == 8 && mod(daysInYear, 10) != 5: // Cheshvan is long only in complete years.
+		return 29
+	case month == 9 && mod(daysInYear, 10) == 3: // Kislev is short in deficient years.
+		return 29
+	}
+	return 30
+}
+
+// unixEpochFixed is January 1st, 1970 as a fixed day number, where day 1 is January 1st, 1 CE.
+const unixEpochFixed = 719163
+
+// fixedFromTime returns the fixed day number of the day of t, in t's location.
+func fixedFromTime(t time.Time) int {
+	date := fromGregorian(t.Year(), t.Month(), t.Day())
+	return floorDiv(int(date.Unix()), 86400) + unixEpochFixed
+}
+
+// timeFromFixed returns midnight UTC of a fixed day number.
+func timeFromFixed(fixed int) time.Time {
+	return time.Unix(int64(fixed-unixEpochFixed)*86400, 0).UTC()
+}
+
+func floorDiv(a, b int) int {
+	q := a / b
+	if (a%b != 0) && ((a < 0) != (b < 0)) {
+		q--
+	}
+	return q
+}
+
+func mod(a, b int) int {
+	return a - b*floorDiv(a, b)
+}
@@ -0,0 +1,121 @@
+package calendar
+
+import (
+	"fmt"
+	"time"
+)
+
+// SolarHijri is the Solar Hijri (Persian) calendar, whose year starts at the March equinox.
+//
+// Leap years follow the 33-year cycle breaks of the jalaali algorithm by Borkowski, which
+// agrees with the astronomical calendar for the years 1178 to 1633.
+type SolarHijri struct{}
+
+var solarHijriMonths = [][]string{
+	{"Farvardin"},
+	{"Ordibehesht"},
+	{"Khordad"},
+	{"Tir"},
+	{"Mordad", "amordad"},
+	{"Shahrivar"},
+	{"Mehr"},
+	{"Aban"},
+	{"Azar"},
+	{"Dey", "dei"},
+	{"Bahman"},
+	{"Esfand"},
+}
+
+var solarHijriBreaks = []int{-61, 9, 38, 199, 426, 686, 756, 818, 1111, 1181, 1210, 1635, 2060, 2097, 2192, 2262, 2324, 2394, 2456, 3178}
+
+// Name returns "solar_hijri".
+func (SolarHijri) Name() string { return "solar_hijri" }
+
+// ParseDate parses a date such as "1 farvardin".
+func (SolarHijri) ParseDate(s string) (Date, error) {
+	day, name, err := parseDayMonth(s)
+	if err != nil {
+		return Date{}, err
+	}
+	month, ok := monthNumber(solarHijriMonths, name)
+	if !ok {
+		return Date{}, fmt.Errorf("%w: unknown solar hijri month '%s'", ErrInvalidDate, name)
+	}
+	if length := solarHijriMonthLength(month, true); day > length {
+		return Date{}, fmt.Errorf("%w: %s has at most %d days", ErrInvalidDate, name, length)
+	}
+	return Date{Month: month, Day: day}, nil
+}
+
+// YearOf returns the Solar Hijri year of the Gregorian date.
+func (s SolarHijri) YearOf(t time.Time) int {
+	year := t.Year() - 621
+	newYear, _ := s.ToGregorian(Date{Year: year, Month: 1, Day: 1})
+	if fromGregorian(t.Year(), t.Month(), t.Day()).Before(newYear) {
+		year--
+	}
+	return year
+}
+
+// MonthName returns the name of a Solar Hijri month, such as "Farvardin".
+func (SolarHijri) MonthName(d Date) string {
+	return monthName(solarHijriMonths, d.Month)
+}
+
+// ToGregorian returns the Gregorian date of a Solar Hijri date.
+func (SolarHijri) ToGregorian(d Date) (time.Time, bool) {
+	if d.Leap || d.Year < solarHijriBreaks[0]+1 || d.Year >= solarHijriBreaks[len(solarHijriBreaks)-1] {
+		return time.Time{}, false
+	}
+	leap, march := solarHijriYear(d.Year)
+	if d.Day > solarHijriMonthLength(d.Month, leap) {
+		return time.Time{}, false
+	}
+	offset := (d.Month-1)*31 - (d.Month/7)*(d.Month-7) + d.Day - 1
+	return fromGregorian(d.Year+621, time.March, march).AddDate(0, 0, offset), true
+}
+
+func solarHijriMonthLength(month int, leap bool) int {
+	switch {
+	case month <= 6:
+		return 31
+	case month <= 11, leap:
+		return 30
+	}
+	return 29
+}
+
+// solarHijriYear reports whether the year is a leap year, and the day of March in the
+// Gregorian calendar on which it starts.
+func solarHijriYear(year int) (bool, int) {
+	gy := year + 621
+	leapJ := -14
+	jp := solarHijriBreaks[0]
+	var jump, n int
+	for _, jm := range solarHijriBreaks[1:] {
+		jump = jm - jp
+		if year < jm {
+			break
+		}
+		leapJ += jump/33*8 + mod(jump, 33)/4
+		jp = jm
+	}
+	n = year - jp
+
+	// Leap years of the Julian calendar up to the year, and the Gregorian correction.
+	leapJ += n/33*8 + (mod(n, 33)+3)/4
+	if mod(jump, 33) == 4 && jump-n == 4 {
+		leapJ++
+	}
+	leapG := gy/4 - (gy/100+1)*3/4 - 150
+	march := 20 + leapJ - leapG
+
+	if jump-n < 6 {
+		n = n - jump + (jump+4)/33*33
+	}
+	leap := mod(mod(n+1, 33)-1, 4)
+	if leap == -1 {
+		leap = 4
+	}
+	return leap == 0, march
+}
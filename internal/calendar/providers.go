@@ -0,0 +1,254 @@
+package calendar
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	rufhttp "github.com/andrewhowdencom/ruf/internal/http"
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultPublicHolidayEndpoint is the base URL of the public holiday API (https://date.nager.at).
+const DefaultPublicHolidayEndpoint = "https://date.nager.at/api/v3"
+
+// Option configures the providers that fetch holidays over HTTP.
+type Option func(*fetcher)
+
+// WithHTTPClient overrides the HTTP client used to fetch holidays.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(f *fetcher) {
+		f.httpClient = httpClient
+	}
+}
+
+// WithEndpoint overrides the base URL of the public holiday API.
+func WithEndpoint(endpoint string) Option {
+	return func(f *fetcher) {
+		f.endpoint = strings.TrimSuffix(endpoint, "/")
+	}
+}
+
+type fetcher struct {
+	endpoint   string
+	httpClient *http.Client
+}
+
+func newFetcher(endpoint string, opts []Option) fetcher {
+	f := fetcher{endpoint: endpoint, httpClient: rufhttp.NewClient()}
+	for _, opt := range opts {
+		opt(&f)
+	}
+	return f
+}
+
+func (f fetcher) get(ctx context.Context, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrFetchFailed, err)
+	}
+	resp, err := f.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrFetchFailed, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: %s: status code %d", ErrFetchFailed, url, resp.StatusCode)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrFetchFailed, err)
+	}
+	return body, nil
+}
+
+// Static is a provider serving a fixed list of holidays.
+type Static struct {
+	holidays []Holiday
+}
+
+// NewStatic creates a provider serving the given holidays.
+func NewStatic(holidays ...Holiday) *Static {
+	return &Static{holidays: holidays}
+}
+
+// LoadStatic reads a YAML file listing holidays, such as:
+//
+//	holidays:
+//	  - date: 2025-12-25
+//	    name: Christmas Day
+func LoadStatic(path string) (*Static, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidCalendar, err)
+	}
+
+	var file struct {
+		Holidays []struct {
+			Date string `yaml:"date"`
+			Name string `yaml:"name"`
+		} `yaml:"holidays"`
+	}
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("%w: %s: %w", ErrInvalidCalendar, path, err)
+	}
+
+	holidays := make([]Holiday, 0, len(file.Holidays))
+	for _, h := range file.Holidays {
+		date, err := time.Parse(time.DateOnly, h.Date)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s: invalid date '%s': %w", ErrInvalidCalendar, path, h.Date, err)
+		}
+		holidays = append(holidays, Holiday{Date: date, Name: h.Name})
+	}
+	return NewStatic(holidays...), nil
+}
+
+// Holidays returns the holidays in the list that fall in the given year.
+func (s *Static) Holidays(_ context.Context, year int) ([]Holiday, error) {
+	return inYear(s.holidays, year), nil
+}
+
+// ICal is a provider reading all-day events from an iCalendar feed.
+type ICal struct {
+	fetcher
+	url string
+}
+
+// NewICal creates a provider reading holidays from the iCalendar feed at the given URL.
+func NewICal(url string, opts ...Option) *ICal {
+	return &ICal{fetcher: newFetcher("", opts), url: url}
+}
+
+// Holidays returns the events in the feed that start in the given year.
+func (c *ICal) Holidays(ctx context.Context, year int) ([]Holiday, error) {
+	body, err := c.get(ctx, c.url)
+	if err != nil {
+		return nil, err
+	}
+	holidays, err := parseICal(body)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %w", ErrInvalidCalendar, c.url, err)
+	}
+	return inYear(holidays, year), nil
+}
+
+// parseICal extracts the start date and summary of every event in an iCalendar document.
+func parseICal(data []byte) ([]Holiday, error) {
+	var (
+		holidays []Holiday
+		current  *Holiday
+	)
+
+	scanner := bufio.NewScanner(bytes.NewReader(unfoldICal(data)))
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		name, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		// Strip parameters, such as DTSTART;VALUE=DATE.
+		name, _, _ = strings.Cut(name, ";")
+
+		switch strings.ToUpper(name) {
+		case "BEGIN":
+			if value == "VEVENT" {
+				current = &Holiday{}
+			}
+		case "END":
+			if value == "VEVENT" && current != nil {
+				if current.Date.IsZero() {
+					return nil, fmt.Errorf("event '%s' has no start date", current.Name)
+				}
+				holidays = append(holidays, *current)
+				current = nil
+			}
+		case "DTSTART":
+			if current == nil {
+				continue
+			}
+			if len(value) < 8 {
+				return nil, fmt.Errorf("invalid start date '%s'", value)
+			}
+			date, err := time.Parse("20060102", value[:8])
+			if err != nil {
+				return nil, fmt.Errorf("invalid start date '%s': %w", value, err)
+			}
+			current.Date = date
+		case "SUMMARY":
+			if current != nil {
+				current.Name = strings.ReplaceAll(value, `\,`, ",")
+			}
+		}
+	}
+	return holidays, scanner.Err()
+}
+
+// unfoldICal joins content lines that have been folded onto continuation lines.
+func unfoldICal(data []byte) []byte {
+	data = bytes.ReplaceAll(data, []byte("\r\n "), nil)
+	data = bytes.ReplaceAll(data, []byte("\r\n\t"), nil)
+	data = bytes.ReplaceAll(data, []byte("\n "), nil)
+	return bytes.ReplaceAll(data, []byte("\n\t"), nil)
+}
+
+// PublicHolidayAPI is a provider fetching the public holidays of a country from the Nager.Date API.
+type PublicHolidayAPI struct {
+	fetcher
+	country string
+}
+
+// NewPublicHolidayAPI creates a provider for the public holidays of the given ISO 3166-1 country code.
+func NewPublicHolidayAPI(country string, opts ...Option) *PublicHolidayAPI {
+	return &PublicHolidayAPI{fetcher: newFetcher(DefaultPublicHolidayEndpoint, opts), country: strings.ToUpper(country)}
+}
+
+// Holidays returns the public holidays of the country in the given year.
+func (a *PublicHolidayAPI) Holidays(ctx context.Context, year int) ([]Holiday, error) {
+	body, err := a.get(ctx, fmt.Sprintf("%s/PublicHolidays/%d/%s", a.endpoint, year, url.PathEscape(a.country)))
+	if err != nil {
+		return nil, err
+	}
+
+	var response []struct {
+		Date      string `json:"date"`
+		LocalName string `json:"localName"`
+		Name      string `json:"name"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, fmt.Errorf("%w: failed to decode public holidays: %w", ErrFetchFailed, err)
+	}
+
+	holidays := make([]Holiday, 0, len(response))
+	for _, r := range response {
+		date, err := time.Parse(time.DateOnly, r.Date)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid date '%s': %w", ErrFetchFailed, r.Date, err)
+		}
+		name := r.LocalName
+		if name == "" {
+			name = r.Name
+		}
+		holidays = append(holidays, Holiday{Date: date, Name: name})
+	}
+	return holidays, nil
+}
+
+func inYear(holidays []Holiday, year int) []Holiday {
+	var filtered []Holiday
+	for _, h := range holidays {
+		if h.Date.Year() == year {
+			filtered = append(filtered, h)
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,192 @@
+package calendar
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hablullah/go-hijri"
+)
+
+// ErrInvalidDate is returned when a date in a calendar system cannot be parsed.
+var ErrInvalidDate = errors.New("invalid date")
+
+// Date is a day in a calendar system. Leap marks the leap month of a lunisolar calendar.
+type Date struct {
+	Year  int
+	Month int
+	Day   int
+	Leap  bool
+}
+
+// System converts the days of a non-Gregorian calendar to and from Gregorian dates.
+type System interface {
+	// Name returns the name of the calendar system, such as "hebrew".
+	Name() string
+	// ParseDate parses a day and month without a year, such as "1 tishrei".
+	ParseDate(s string) (Date, error)
+	// YearOf returns the year of the calendar system that the Gregorian date falls in.
+	YearOf(t time.Time) int
+	// ToGregorian returns midnight UTC of the Gregorian date of d. It returns false if d does not
+	// exist in its year, such as the 30th of a short month or the leap month of a common year.
+	ToGregorian(d Date) (time.Time, bool)
+	// MonthName returns the name of the month of d, as it is written in English, such as "Tishrei".
+	MonthName(d Date) string
+}
+
+// Systems returns the calendar systems, by name.
+func Systems() map[string]System {
+	systems := make(map[string]System)
+	for _, s := range []System{Hijri{}, Hebrew{}, Chinese{}, SolarHijri{}} {
+		systems[s.Name()] = s
+	}
+	return systems
+}
+
+// DateOf returns the date in the calendar system of the day of t, in t's location. It returns false if
+// the day falls outside the years the calendar system can convert.
+func DateOf(s System, t time.Time) (Date, bool) {
+	day := fromGregorian(t.Year(), t.Month(), t.Day())
+	year := s.YearOf(day)
+
+	// The month of the day is the one that starts last on or before it. Leap months are tried after
+	// the others, so that a month that only moves in leap years is found by its usual number.
+	var found Date
+	var start time.Time
+	for _, leap := range []bool{false, true} {
+		for month := 1; month <= 13; month++ {
+			d := Date{Year: year, Month: month, Day: 1, Leap: leap}
+			g, ok := s.ToGregorian(d)
+			if !ok || g.After(day) || !g.After(start) {
+				continue
+			}
+			found, start = d, g
+		}
+	}
+	if start.IsZero() {
+		return Date{}, false
+	}
+	found.Day = int(day.Sub(start).Hours()/24) + 1
+	return found, true
+}
+
+// Format returns the day of t, in t's location, in the calendar system, such as "1 Ramadan 1447".
+func Format(s System, t time.Time) (string, error) {
+	d, ok := DateOf(s, t)
+	if !ok {
+		return "", fmt.Errorf("%w: %s is outside the years the %s calendar can convert", ErrInvalidDate, t.Format(time.DateOnly), s.Name())
+	}
+	return fmt.Sprintf("%d %s %d", d.Day, s.MonthName(d), d.Year), nil
+}
+
+// NextOccurrence returns midnight UTC of the first Gregorian date after t on which the day and
+// month of d fall, searching the next few years of the calendar system.
+func NextOccurrence(s System, d Date, t time.Time) (time.Time, bool) {
+	start := s.YearOf(t)
+	for year := start; year <= start+3; year++ {
+		d.Year = year
+		g, ok := s.ToGregorian(d)
+		if ok && g.After(t) {
+			return g, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// parseDayMonth splits a date such as "1 tishrei" into its day and the lower case name of its month.
+func parseDayMonth(s string) (int, string, error) {
+	fields := strings.Fields(strings.ToLower(s))
+	if len(fields) < 2 {
+		return 0, "", fmt.Errorf("%w: '%s' must be a day and a month, such as '1 tishrei'", ErrInvalidDate, s)
+	}
+	day, err := strconv.Atoi(fields[0])
+	if err != nil || day < 1 || day > 31 {
+		return 0, "", fmt.Errorf("%w: invalid day '%s'", ErrInvalidDate, fields[0])
+	}
+	return day, strings.Join(fields[1:], " "), nil
+}
+
+// monthNumber looks a month up by its names, returning its one-based number. The first name of each
+// month is the one it is written with.
+func monthNumber(names [][]string, month string) (int, bool) {
+	for i, aliases := range names {
+		for _, alias := range aliases {
+			if strings.EqualFold(alias, month) {
+				return i + 1, true
+			}
+		}
+	}
+	return 0, false
+}
+
+// monthName returns the name a month is written with, given its one-based number.
+func monthName(names [][]string, month int) string {
+	if month < 1 || month > len(names) {
+		return strconv.Itoa(month)
+	}
+	return names[month-1][0]
+}
+
+// fromGregorian returns midnight UTC of a Gregorian date.
+func fromGregorian(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// Hijri is the tabular Islamic calendar.
+type Hijri struct{}
+
+var hijriMonths = [][]string{
+	{"Muharram"},
+	{"Safar"},
+	{"Rabi' al-Awwal", "rabi al-awwal", "rabi'ul-awwal", "rabi'ul awwal"},
+	{"Rabi' al-Thani", "rabi al-thani", "rabi'ul-athir", "rabi'ul athir"},
+	{"Jumada al-Ula", "jumada al-awwal"},
+	{"Jumada al-Thani", "jumada al-akhirah"},
+	{"Rajab"},
+	{"Sha'ban", "shaban"},
+	{"Ramadan"},
+	{"Shawwal"},
+	{"Dhu al-Qi'dah", "dhu al-qid'ah"},
+	{"Dhu al-Hijjah"},
+}
+
+// Name returns "hijri".
+func (Hijri) Name() string { return "hijri" }
+
+// ParseDate parses a date such as "1 ramadan".
+func (Hijri) ParseDate(s string) (Date, error) {
+	day, name, err := parseDayMonth(s)
+	if err != nil {
+		return Date{}, err
+	}
+	month, ok := monthNumber(hijriMonths, name)
+	if !ok {
+		return Date{}, fmt.Errorf("%w: unknown hijri month '%s'", ErrInvalidDate, name)
+	}
+	if day > 30 {
+		return Date{}, fmt.Errorf("%w: hijri months have at most 30 days", ErrInvalidDate)
+	}
+	return Date{Month: month, Day: day}, nil
+}
+
+// YearOf returns the Hijri year of the Gregorian date.
+func (Hijri) YearOf(t time.Time) int {
+	d, _ := hijri.CreateHijriDate(t, hijri.Default)
+	return int(d.Year)
+}
+
+// MonthName returns the name of a Hijri month, such as "Ramadan".
+func (Hijri) MonthName(d Date) string {
+	return monthName(hijriMonths, d.Month)
+}
+
+// ToGregorian returns the Gregorian date of a Hijri date.
+func (Hijri) ToGregorian(d Date) (time.Time, bool) {
+	if d.Leap {
+		return time.Time{}, false
+	}
+	g := hijri.HijriDate{Year: int64(d.Year), Month: int64(d.Month), Day: int64(d.Day)}.ToGregorian()
+	return fromGregorian(g.Year(), g.Month(), g.Day()), true
+}
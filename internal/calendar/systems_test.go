@@ -0,0 +1,127 @@
+package calendar_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/andrewhowdencom/ruf/internal/calendar"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSystems(t *testing.T) {
+	tests := []struct {
+		system calendar.System
+		date   string
+		year   int
+		want   time.Time
+	}{
+		{calendar.Hijri{}, "1 muharram", 1447, date(2025, 6, 27)},
+		{calendar.Hebrew{}, "1 tishrei", 5786, date(2025, 9, 23)},
+		{calendar.Hebrew{}, "15 nisan", 5785, date(2025, 4, 13)},
+		{calendar.Hebrew{}, "14 adar", 5784, date(2024, 3, 24)}, // Adar II in a leap year.
+		{calendar.Hebrew{}, "14 adar i", 5784, date(2024, 2, 23)},
+		{calendar.Hebrew{}, "14 adar", 5785, date(2025, 3, 14)},
+		{calendar.Hebrew{}, "25 kislev", 5786, date(2025, 12, 15)},
+		{calendar.Chinese{}, "1 1", 2024, date(2024, 2, 10)},
+		{calendar.Chinese{}, "1 1", 2025, date(2025, 1, 29)},
+		{calendar.Chinese{}, "15 8", 2025, date(2025, 10, 6)},
+		{calendar.Chinese{}, "1 leap 4", 2020, date(2020, 5, 23)},
+		{calendar.Chinese{}, "1 leap 6", 2025, date(2025, 7, 25)},
+		{calendar.SolarHijri{}, "1 farvardin", 1404, date(2025, 3, 21)},
+		{calendar.SolarHijri{}, "1 dey", 1403, date(2024, 12, 21)},
+		{calendar.SolarHijri{}, "30 esfand", 1403, date(2025, 3, 20)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.system.Name()+" "+tt.date, func(t *testing.T) {
+			d, err := tt.system.ParseDate(tt.date)
+			assert.NoError(t, err)
+			d.Year = tt.year
+
+			got, ok := tt.system.ToGregorian(d)
+			assert.True(t, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestSystems_MissingDates(t *testing.T) {
+	// 1402 is a common year, so Esfand has 29 days.
+	d, err := calendar.SolarHijri{}.ParseDate("30 esfand")
+	assert.NoError(t, err)
+	d.Year = 1402
+	_, ok := calendar.SolarHijri{}.ToGregorian(d)
+	assert.False(t, ok)
+
+	// 2024 has no leap month.
+	d, err = calendar.Chinese{}.ParseDate("1 leap 4")
+	assert.NoError(t, err)
+	d.Year = 2024
+	_, ok = calendar.Chinese{}.ToGregorian(d)
+	assert.False(t, ok)
+}
+
+func TestSystems_ParseDate(t *testing.T) {
+	for _, tt := range []struct {
+		system calendar.System
+		date   string
+	}{
+		{calendar.Hijri{}, "ramadan"},
+		{calendar.Hebrew{}, "1 shmarch"},
+		{calendar.Hebrew{}, "31 tishrei"},
+		{calendar.Chinese{}, "1 13"},
+		{calendar.Chinese{}, "1 leap"},
+		{calendar.SolarHijri{}, "31 mehr"},
+	} {
+		_, err := tt.system.ParseDate(tt.date)
+		assert.ErrorIs(t, err, calendar.ErrInvalidDate, "%s %s", tt.system.Name(), tt.date)
+	}
+}
+
+func TestNextOccurrence(t *testing.T) {
+	d, err := calendar.Hebrew{}.ParseDate("1 tishrei")
+	assert.NoError(t, err)
+
+	got, ok := calendar.NextOccurrence(calendar.Hebrew{}, d, date(2025, 9, 23))
+	assert.True(t, ok)
+	assert.Equal(t, date(2026, 9, 12), got)
+
+	got, ok = calendar.NextOccurrence(calendar.Hebrew{}, d, date(2025, 1, 1))
+	assert.True(t, ok)
+	assert.Equal(t, date(2025, 9, 23), got)
+}
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		system calendar.System
+		day    time.Time
+		want   string
+	}{
+		{calendar.Hijri{}, date(2025, 6, 27), "1 Muharram 1447"},
+		{calendar.Hijri{}, date(2025, 3, 1), "1 Ramadan 1446"},
+		{calendar.Hebrew{}, date(2025, 9, 23), "1 Tishrei 5786"},
+		{calendar.Hebrew{}, date(2025, 4, 13), "15 Nisan 5785"},
+		{calendar.Hebrew{}, date(2024, 3, 24), "14 Adar II 5784"},
+		{calendar.Hebrew{}, date(2024, 2, 23), "14 Adar I 5784"},
+		{calendar.Hebrew{}, date(2025, 3, 14), "14 Adar 5785"},
+		{calendar.Chinese{}, date(2025, 10, 6), "15 Month 8 2025"},
+		{calendar.Chinese{}, date(2020, 5, 23), "1 Leap month 4 2020"},
+		{calendar.Chinese{}, date(2025, 1, 28), "29 Month 12 2024"},
+		{calendar.SolarHijri{}, date(2025, 3, 20), "30 Esfand 1403"},
+		{calendar.SolarHijri{}, date(2025, 3, 21), "1 Farvardin 1404"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.system.Name()+" "+tt.want, func(t *testing.T) {
+			got, err := calendar.Format(tt.system, tt.day)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	// The day is taken in the location of the time.
+	tokyo := time.FixedZone("JST", 9*60*60)
+	got, err := calendar.Format(calendar.Hebrew{}, time.Date(2025, 9, 22, 20, 0, 0, 0, time.UTC).In(tokyo))
+	assert.NoError(t, err)
+	assert.Equal(t, "1 Tishrei 5786", got)
+}
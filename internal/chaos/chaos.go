@@ -0,0 +1,77 @@
+// Package chaos injects faults into the clients and the datastore, so that the handling of failures
+// (retries, failed sends and alerts) can be exercised end-to-end in staging before it is relied on
+// in production.
+package chaos
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"time"
+)
+
+// Err* are common errors returned by injected faults.
+var (
+	ErrInjected = errors.New("injected fault")
+)
+
+// Fault describes the faults injected into the calls made to a dependency.
+type Fault struct {
+	// FailureRate is the probability, from 0 to 1, that a call fails.
+	FailureRate float64
+	// Latency is added to every call, whether or not it fails.
+	Latency time.Duration
+}
+
+// Injector decides whether each call to a dependency fails.
+type Injector struct {
+	name  string
+	fault Fault
+	rand  func() float64
+	sleep func(time.Duration)
+}
+
+// Option configures the Injector.
+type Option func(*Injector)
+
+// WithRand sets the source of random numbers in [0, 1) that failures are drawn from.
+func WithRand(rand func() float64) Option {
+	return func(i *Injector) {
+		i.rand = rand
+	}
+}
+
+// WithSleep sets the function used to add latency.
+func WithSleep(sleep func(time.Duration)) Option {
+	return func(i *Injector) {
+		i.sleep = sleep
+	}
+}
+
+// NewInjector creates an Injector for the named dependency.
+func NewInjector(name string, fault Fault, opts ...Option) *Injector {
+	i := &Injector{
+		name:  name,
+		fault: fault,
+		rand:  rand.Float64,
+		sleep: time.Sleep,
+	}
+	for _, opt := range opts {
+		opt(i)
+	}
+	return i
+}
+
+// Inject adds the latency of the fault, then returns an error wrapping ErrInjected if the operation
+// is chosen to fail.
+func (i *Injector) Inject(operation string) error {
+	if i.fault.Latency > 0 {
+		i.sleep(i.fault.Latency)
+	}
+	if i.fault.FailureRate > 0 && i.rand() < i.fault.FailureRate {
+		slog.Warn("injecting fault", "dependency", i.name, "operation", operation)
+		return fmt.Errorf("%w: %s %s", ErrInjected, i.name, operation)
+	}
+	return nil
+}
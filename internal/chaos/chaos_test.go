@@ -0,0 +1,59 @@
+package chaos_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/andrewhowdencom/ruf/internal/chaos"
+	"github.com/andrewhowdencom/ruf/internal/clients/email"
+	"github.com/andrewhowdencom/ruf/internal/clients/slack"
+	"github.com/andrewhowdencom/ruf/internal/datastore"
+	"github.com/andrewhowdencom/ruf/internal/kv"
+	"github.com/andrewhowdencom/ruf/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInjector(t *testing.T) {
+	draws := []float64{0.05, 0.5}
+	var slept []time.Duration
+	injector := chaos.NewInjector("slack", chaos.Fault{FailureRate: 0.1, Latency: 200 * time.Millisecond},
+		chaos.WithRand(func() float64 {
+			d := draws[0]
+			draws = draws[1:]
+			return d
+		}),
+		chaos.WithSleep(func(d time.Duration) { slept = append(slept, d) }),
+	)
+
+	err := injector.Inject("PostMessage")
+	assert.ErrorIs(t, err, chaos.ErrInjected)
+	assert.EqualError(t, err, "injected fault: slack PostMessage")
+	assert.NoError(t, injector.Inject("PostMessage"))
+	assert.Equal(t, []time.Duration{200 * time.Millisecond, 200 * time.Millisecond}, slept)
+}
+
+func TestWrappers(t *testing.T) {
+	failing := chaos.NewInjector("test", chaos.Fault{FailureRate: 1})
+
+	slackClient := slack.NewMockClient()
+	_, _, err := chaos.Slack(slackClient, failing).PostMessage(context.Background(), "#general", "", "", "Hello", model.Campaign{})
+	assert.ErrorIs(t, err, chaos.ErrInjected)
+	assert.Empty(t, slackClient.PostMessageCalls())
+
+	emailClient := email.NewMockClient()
+	_, err = chaos.Email(emailClient, failing).Send(context.Background(), []string{"team@example.com"}, "", "", "Hello", model.Campaign{})
+	assert.ErrorIs(t, err, chaos.ErrInjected)
+	assert.Empty(t, emailClient.SendCalls())
+
+	store := chaos.Store(datastore.NewMockStore(), failing)
+	_, err = store.ListScheduledCalls()
+	assert.ErrorIs(t, err, chaos.ErrInjected)
+	assert.ErrorIs(t, err, kv.ErrDBOperationFailed)
+
+	// Without a failure rate, calls go through to the dependency.
+	passing := chaos.NewInjector("test", chaos.Fault{})
+	_, _, err = chaos.Slack(slackClient, passing).PostMessage(context.Background(), "#general", "", "", "Hello", model.Campaign{})
+	assert.NoError(t, err)
+	assert.Len(t, slackClient.PostMessageCalls(), 1)
+}
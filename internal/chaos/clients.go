@@ -0,0 +1,137 @@
+package chaos
+
+import (
+	"context"
+	"time"
+
+	"github.com/andrewhowdencom/ruf/internal/clients/email"
+	"github.com/andrewhowdencom/ruf/internal/clients/slack"
+	"github.com/andrewhowdencom/ruf/internal/model"
+)
+
+// slackClient injects faults into the calls made to Slack.
+type slackClient struct {
+	slack.Client
+	injector *Injector
+}
+
+// Slack wraps a Slack client so that its calls are subject to the faults of the injector.
+func Slack(client slack.Client, injector *Injector) slack.Client {
+	return &slackClient{Client: client, injector: injector}
+}
+
+func (c *slackClient) PostMessage(ctx context.Context, destination, author, subject, text string, campaign model.Campaign) (string, string, error) {
+	if err := c.injector.Inject("PostMessage"); err != nil {
+		return "", "", err
+	}
+	return c.Client.PostMessage(ctx, destination, author, subject, text, campaign)
+}
+
+func (c *slackClient) NotifyAuthor(ctx context.Context, authorEmail, channelId, messageTimestamp, channelName string) error {
+	if err := c.injector.Inject("NotifyAuthor"); err != nil {
+		return err
+	}
+	return c.Client.NotifyAuthor(ctx, authorEmail, channelId, messageTimestamp, channelName)
+}
+
+func (c *slackClient) NotifyAuthorOfFailure(ctx context.Context, authorEmail, destination, reason, retry string) error {
+	if err := c.injector.Inject("NotifyAuthorOfFailure"); err != nil {
+		return err
+	}
+	return c.Client.NotifyAuthorOfFailure(ctx, authorEmail, destination, reason, retry)
+}
+
+func (c *slackClient) RequestSignoff(ctx context.Context, ownerEmail, callID, shortID string, pending []string) error {
+	if err := c.injector.Inject("RequestSignoff"); err != nil {
+		return err
+	}
+	return c.Client.RequestSignoff(ctx, ownerEmail, callID, shortID, pending)
+}
+
+func (c *slackClient) RequestApproval(ctx context.Context, approverEmail, callID, shortID, summary string) error {
+	if err := c.injector.Inject("RequestApproval"); err != nil {
+		return err
+	}
+	return c.Client.RequestApproval(ctx, approverEmail, callID, shortID, summary)
+}
+
+func (c *slackClient) RequestConfirmation(ctx context.Context, authorEmail, callID, shortID, preview, fallback string, at time.Time) error {
+	if err := c.injector.Inject("RequestConfirmation"); err != nil {
+		return err
+	}
+	return c.Client.RequestConfirmation(ctx, authorEmail, callID, shortID, preview, fallback, at)
+}
+
+func (c *slackClient) UpdateMessage(ctx context.Context, destination, timestamp, subject, text string) (string, string, error) {
+	if err := c.injector.Inject("UpdateMessage"); err != nil {
+		return "", "", err
+	}
+	return c.Client.UpdateMessage(ctx, destination, timestamp, subject, text)
+}
+
+func (c *slackClient) DeleteMessage(ctx context.Context, channel, timestamp string) error {
+	if err := c.injector.Inject("DeleteMessage"); err != nil {
+		return err
+	}
+	return c.Client.DeleteMessage(ctx, channel, timestamp)
+}
+
+func (c *slackClient) GetChannelID(ctx context.Context, destination string) (string, error) {
+	if err := c.injector.Inject("GetChannelID"); err != nil {
+		return "", err
+	}
+	return c.Client.GetChannelID(ctx, destination)
+}
+
+func (c *slackClient) GetPermalink(ctx context.Context, channelID, timestamp string) (string, error) {
+	if err := c.injector.Inject("GetPermalink"); err != nil {
+		return "", err
+	}
+	return c.Client.GetPermalink(ctx, channelID, timestamp)
+}
+
+func (c *slackClient) GetUserTimezone(ctx context.Context, destination string) (string, error) {
+	if err := c.injector.Inject("GetUserTimezone"); err != nil {
+		return "", err
+	}
+	return c.Client.GetUserTimezone(ctx, destination)
+}
+
+func (c *slackClient) ValidateDestination(ctx context.Context, destination string) error {
+	if err := c.injector.Inject("ValidateDestination"); err != nil {
+		return err
+	}
+	return c.Client.ValidateDestination(ctx, destination)
+}
+
+func (c *slackClient) ListChannels(ctx context.Context) ([]slack.Channel, error) {
+	if err := c.injector.Inject("ListChannels"); err != nil {
+		return nil, err
+	}
+	return c.Client.ListChannels(ctx)
+}
+
+// emailClient injects faults into the emails that are sent.
+type emailClient struct {
+	email.Client
+	injector *Injector
+}
+
+// Email wraps an email client so that its sends are subject to the faults of the injector.
+func Email(client email.Client, injector *Injector) email.Client {
+	return &emailClient{Client: client, injector: injector}
+}
+
+func (c *emailClient) Send(ctx context.Context, to []string, author, subject, body string, campaign model.Campaign) (string, error) {
+	if err := c.injector.Inject("Send"); err != nil {
+		return "", err
+	}
+	return c.Client.Send(ctx, to, author, subject, body, campaign)
+}
+
+func (c *emailClient) Reply(ctx context.Context, to []string, author, subject, body string, campaign model.Campaign, references []string) (string, error) {
+	if err := c.injector.Inject("Reply"); err != nil {
+		return "", err
+	}
+	return c.Client.Reply(ctx, to, author, subject, body, campaign, references)
+}
@@ -0,0 +1,384 @@
+package chaos
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/andrewhowdencom/ruf/internal/kv"
+)
+
+// store injects faults into the operations of a datastore. Injected failures also wrap
+// kv.ErrDBOperationFailed, as a real failure of the datastore would.
+type store struct {
+	kv.Storer
+	injector *Injector
+}
+
+// Store wraps a datastore so that its operations, other than Close, are subject to the faults of the
+// injector.
+func Store(storer kv.Storer, injector *Injector) kv.Storer {
+	return &store{Storer: storer, injector: injector}
+}
+
+// WithContext returns the store with the operations of the datastore it wraps bound to the context.
+func (s *store) WithContext(ctx context.Context) kv.Storer {
+	return &store{Storer: kv.WithContext(ctx, s.Storer), injector: s.injector}
+}
+
+func (s *store) inject(operation string) error {
+	if err := s.injector.Inject(operation); err != nil {
+		return fmt.Errorf("%w: %w", kv.ErrDBOperationFailed, err)
+	}
+	return nil
+}
+
+func (s *store) AddSentMessage(campaignID, callID string, sm *kv.SentMessage) error {
+	if err := s.inject("AddSentMessage"); err != nil {
+		return err
+	}
+	return s.Storer.AddSentMessage(campaignID, callID, sm)
+}
+
+func (s *store) UpdateSentMessage(sm *kv.SentMessage) error {
+	if err := s.inject("UpdateSentMessage"); err != nil {
+		return err
+	}
+	return s.Storer.UpdateSentMessage(sm)
+}
+
+func (s *store) HasBeenSent(campaignID, callID string, occurredAt time.Time, destType, destination string) (bool, error) {
+	if err := s.inject("HasBeenSent"); err != nil {
+		return false, err
+	}
+	return s.Storer.HasBeenSent(campaignID, callID, occurredAt, destType, destination)
+}
+
+func (s *store) ListSentMessages() ([]*kv.SentMessage, error) {
+	if err := s.inject("ListSentMessages"); err != nil {
+		return nil, err
+	}
+	return s.Storer.ListSentMessages()
+}
+
+func (s *store) ListSentMessagesByDestination(destination string, limit int) ([]*kv.SentMessage, error) {
+	if err := s.inject("ListSentMessagesByDestination"); err != nil {
+		return nil, err
+	}
+	return s.Storer.ListSentMessagesByDestination(destination, limit)
+}
+
+func (s *store) ListSentMessagesByCampaign(campaignID string) ([]*kv.SentMessage, error) {
+	if err := s.inject("ListSentMessagesByCampaign"); err != nil {
+		return nil, err
+	}
+	return s.Storer.ListSentMessagesByCampaign(campaignID)
+}
+
+func (s *store) ListExpiringMessages(before time.Time) ([]*kv.SentMessage, error) {
+	if err := s.inject("ListExpiringMessages"); err != nil {
+		return nil, err
+	}
+	return s.Storer.ListExpiringMessages(before)
+}
+
+func (s *store) GetSentMessage(id string) (*kv.SentMessage, error) {
+	if err := s.inject("GetSentMessage"); err != nil {
+		return nil, err
+	}
+	return s.Storer.GetSentMessage(id)
+}
+
+func (s *store) GetSentMessageByShortID(shortID string) (*kv.SentMessage, error) {
+	if err := s.inject("GetSentMessageByShortID"); err != nil {
+		return nil, err
+	}
+	return s.Storer.GetSentMessageByShortID(shortID)
+}
+
+func (s *store) DeleteSentMessage(id string) error {
+	if err := s.inject("DeleteSentMessage"); err != nil {
+		return err
+	}
+	return s.Storer.DeleteSentMessage(id)
+}
+
+func (s *store) ReserveSlot(slot time.Time, callID string) (bool, error) {
+	if err := s.inject("ReserveSlot"); err != nil {
+		return false, err
+	}
+	return s.Storer.ReserveSlot(slot, callID)
+}
+
+func (s *store) ListSlots() (map[time.Time]string, error) {
+	if err := s.inject("ListSlots"); err != nil {
+		return nil, err
+	}
+	return s.Storer.ListSlots()
+}
+
+func (s *store) ClearAllSlots() error {
+	if err := s.inject("ClearAllSlots"); err != nil {
+		return err
+	}
+	return s.Storer.ClearAllSlots()
+}
+
+func (s *store) AddScheduledCall(call *kv.ScheduledCall) error {
+	if err := s.inject("AddScheduledCall"); err != nil {
+		return err
+	}
+	return s.Storer.AddScheduledCall(call)
+}
+
+func (s *store) AddScheduledCalls(calls []*kv.ScheduledCall) error {
+	if err := s.inject("AddScheduledCalls"); err != nil {
+		return err
+	}
+	return s.Storer.AddScheduledCalls(calls)
+}
+
+func (s *store) GetScheduledCall(id string) (*kv.ScheduledCall, error) {
+	if err := s.inject("GetScheduledCall"); err != nil {
+		return nil, err
+	}
+	return s.Storer.GetScheduledCall(id)
+}
+
+func (s *store) ListScheduledCalls() ([]*kv.ScheduledCall, error) {
+	if err := s.inject("ListScheduledCalls"); err != nil {
+		return nil, err
+	}
+	return s.Storer.ListScheduledCalls()
+}
+
+func (s *store) DeleteScheduledCall(id string) error {
+	if err := s.inject("DeleteScheduledCall"); err != nil {
+		return err
+	}
+	return s.Storer.DeleteScheduledCall(id)
+}
+
+func (s *store) ClearScheduledCalls() error {
+	if err := s.inject("ClearScheduledCalls"); err != nil {
+		return err
+	}
+	return s.Storer.ClearScheduledCalls()
+}
+
+func (s *store) ReplaceSchedule(calls []*kv.ScheduledCall, slots map[time.Time]string) error {
+	if err := s.inject("ReplaceSchedule"); err != nil {
+		return err
+	}
+	return s.Storer.ReplaceSchedule(calls, slots)
+}
+
+func (s *store) SetTriggerOverride(o *kv.TriggerOverride) error {
+	if err := s.inject("SetTriggerOverride"); err != nil {
+		return err
+	}
+	return s.Storer.SetTriggerOverride(o)
+}
+
+func (s *store) ListTriggerOverrides() ([]*kv.TriggerOverride, error) {
+	if err := s.inject("ListTriggerOverrides"); err != nil {
+		return nil, err
+	}
+	return s.Storer.ListTriggerOverrides()
+}
+
+func (s *store) DeleteTriggerOverride(callID string, index int) error {
+	if err := s.inject("DeleteTriggerOverride"); err != nil {
+		return err
+	}
+	return s.Storer.DeleteTriggerOverride(callID, index)
+}
+
+func (s *store) SetSeriesOverride(o *kv.SeriesOverride) error {
+	if err := s.inject("SetSeriesOverride"); err != nil {
+		return err
+	}
+	return s.Storer.SetSeriesOverride(o)
+}
+
+func (s *store) ListSeriesOverrides() ([]*kv.SeriesOverride, error) {
+	if err := s.inject("ListSeriesOverrides"); err != nil {
+		return nil, err
+	}
+	return s.Storer.ListSeriesOverrides()
+}
+
+func (s *store) DeleteSeriesOverride(seriesID string) error {
+	if err := s.inject("DeleteSeriesOverride"); err != nil {
+		return err
+	}
+	return s.Storer.DeleteSeriesOverride(seriesID)
+}
+
+func (s *store) AddSignoff(so *kv.Signoff) error {
+	if err := s.inject("AddSignoff"); err != nil {
+		return err
+	}
+	return s.Storer.AddSignoff(so)
+}
+
+func (s *store) ListSignoffs(campaignID, callID string) ([]*kv.Signoff, error) {
+	if err := s.inject("ListSignoffs"); err != nil {
+		return nil, err
+	}
+	return s.Storer.ListSignoffs(campaignID, callID)
+}
+
+func (s *store) CancelCall(c *kv.Cancellation) error {
+	if err := s.inject("CancelCall"); err != nil {
+		return err
+	}
+	return s.Storer.CancelCall(c)
+}
+
+func (s *store) GetCancellation(callID string) (*kv.Cancellation, error) {
+	if err := s.inject("GetCancellation"); err != nil {
+		return nil, err
+	}
+	return s.Storer.GetCancellation(callID)
+}
+
+func (s *store) DeleteCancellation(callID string) error {
+	if err := s.inject("DeleteCancellation"); err != nil {
+		return err
+	}
+	return s.Storer.DeleteCancellation(callID)
+}
+
+func (s *store) SetReschedule(r *kv.Reschedule) error {
+	if err := s.inject("SetReschedule"); err != nil {
+		return err
+	}
+	return s.Storer.SetReschedule(r)
+}
+
+func (s *store) ListReschedules() ([]*kv.Reschedule, error) {
+	if err := s.inject("ListReschedules"); err != nil {
+		return nil, err
+	}
+	return s.Storer.ListReschedules()
+}
+
+func (s *store) DeleteReschedule(callID string) error {
+	if err := s.inject("DeleteReschedule"); err != nil {
+		return err
+	}
+	return s.Storer.DeleteReschedule(callID)
+}
+
+func (s *store) SetApproval(a *kv.Approval) error {
+	if err := s.inject("SetApproval"); err != nil {
+		return err
+	}
+	return s.Storer.SetApproval(a)
+}
+
+func (s *store) GetApproval(callID string) (*kv.Approval, error) {
+	if err := s.inject("GetApproval"); err != nil {
+		return nil, err
+	}
+	return s.Storer.GetApproval(callID)
+}
+
+func (s *store) ListApprovals() ([]*kv.Approval, error) {
+	if err := s.inject("ListApprovals"); err != nil {
+		return nil, err
+	}
+	return s.Storer.ListApprovals()
+}
+
+func (s *store) SetConfirmation(c *kv.Confirmation) error {
+	if err := s.inject("SetConfirmation"); err != nil {
+		return err
+	}
+	return s.Storer.SetConfirmation(c)
+}
+
+func (s *store) GetConfirmation(callID string) (*kv.Confirmation, error) {
+	if err := s.inject("GetConfirmation"); err != nil {
+		return nil, err
+	}
+	return s.Storer.GetConfirmation(callID)
+}
+
+func (s *store) AddDeadLetter(d *kv.DeadLetter) error {
+	if err := s.inject("AddDeadLetter"); err != nil {
+		return err
+	}
+	return s.Storer.AddDeadLetter(d)
+}
+
+func (s *store) GetDeadLetter(id string) (*kv.DeadLetter, error) {
+	if err := s.inject("GetDeadLetter"); err != nil {
+		return nil, err
+	}
+	return s.Storer.GetDeadLetter(id)
+}
+
+func (s *store) ListDeadLetters() ([]*kv.DeadLetter, error) {
+	if err := s.inject("ListDeadLetters"); err != nil {
+		return nil, err
+	}
+	return s.Storer.ListDeadLetters()
+}
+
+func (s *store) DeleteDeadLetter(id string) error {
+	if err := s.inject("DeleteDeadLetter"); err != nil {
+		return err
+	}
+	return s.Storer.DeleteDeadLetter(id)
+}
+
+func (s *store) SetWorkerStatus(ws *kv.WorkerStatus) error {
+	if err := s.inject("SetWorkerStatus"); err != nil {
+		return err
+	}
+	return s.Storer.SetWorkerStatus(ws)
+}
+
+func (s *store) ListWorkerStatuses() ([]*kv.WorkerStatus, error) {
+	if err := s.inject("ListWorkerStatuses"); err != nil {
+		return nil, err
+	}
+	return s.Storer.ListWorkerStatuses()
+}
+
+func (s *store) SetCachedSource(c *kv.CachedSource) error {
+	if err := s.inject("SetCachedSource"); err != nil {
+		return err
+	}
+	return s.Storer.SetCachedSource(c)
+}
+
+func (s *store) GetCachedSource(url string) (*kv.CachedSource, error) {
+	if err := s.inject("GetCachedSource"); err != nil {
+		return nil, err
+	}
+	return s.Storer.GetCachedSource(url)
+}
+
+func (s *store) AcquireLease(name, holder string, now time.Time, ttl time.Duration) (*kv.Lease, error) {
+	if err := s.inject("AcquireLease"); err != nil {
+		return nil, err
+	}
+	return s.Storer.AcquireLease(name, holder, now, ttl)
+}
+
+func (s *store) GetSchemaVersion() (int, error) {
+	if err := s.inject("GetSchemaVersion"); err != nil {
+		return 0, err
+	}
+	return s.Storer.GetSchemaVersion()
+}
+
+func (s *store) SetSchemaVersion(version int) error {
+	if err := s.inject("SetSchemaVersion"); err != nil {
+		return err
+	}
+	return s.Storer.SetSchemaVersion(version)
+}
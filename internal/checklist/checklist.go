@@ -0,0 +1,103 @@
+// Package checklist gates the calls of a campaign on a checklist, such as a content review or a
+// legal sign-off, that must be signed off for each scheduled call before it is sent. Sign-offs are kept
+// in the datastore with who made them and when, so that they can be audited.
+package checklist
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/andrewhowdencom/ruf/internal/kv"
+)
+
+// Err* are common errors returned when signing off checklist items.
+var (
+	ErrNoChecklist = errors.New("campaign has no checklist")
+	ErrUnknownItem = errors.New("unknown checklist item")
+)
+
+// Item is an item of a checklist, and its sign-off if it has been signed off.
+type Item struct {
+	Name    string      `json:"name"`
+	Signoff *kv.Signoff `json:"signoff,omitempty"`
+}
+
+// Done reports whether the item has been signed off.
+func (i Item) Done() bool {
+	return i.Signoff != nil
+}
+
+// Find returns the scheduled call with the given ID, or the short ID generated from it.
+func Find(store kv.Storer, id string) (*kv.ScheduledCall, error) {
+	return kv.FindScheduledCall(store, id)
+}
+
+// Status returns the checklist of the campaign of a call, in the order the campaign lists it, with the
+// sign-offs made for the call.
+func Status(store kv.Storer, call *kv.ScheduledCall) ([]Item, error) {
+	if len(call.Campaign.Checklist) == 0 {
+		return nil, nil
+	}
+
+	signoffs, err := store.ListSignoffs(call.Campaign.ID, call.ID)
+	if err != nil {
+		return nil, err
+	}
+	byItem := make(map[string]*kv.Signoff, len(signoffs))
+	for _, so := range signoffs {
+		byItem[so.Item] = so
+	}
+
+	items := make([]Item, 0, len(call.Campaign.Checklist))
+	for _, name := range call.Campaign.Checklist {
+		items = append(items, Item{Name: name, Signoff: byItem[name]})
+	}
+	return items, nil
+}
+
+// Pending returns the items of the checklist of the campaign of a call that have not been signed off
+// for it. A call with nothing pending may be sent.
+func Pending(store kv.Storer, call *kv.ScheduledCall) ([]string, error) {
+	items, err := Status(store, call)
+	if err != nil {
+		return nil, err
+	}
+	var pending []string
+	for _, item := range items {
+		if !item.Done() {
+			pending = append(pending, item.Name)
+		}
+	}
+	return pending, nil
+}
+
+// SignOff marks an item of the checklist of the campaign of a call as done for it. The item is matched
+// regardless of case.
+func SignOff(store kv.Storer, call *kv.ScheduledCall, item, by string, at time.Time) (*kv.Signoff, error) {
+	if len(call.Campaign.Checklist) == 0 {
+		return nil, fmt.Errorf("%w: '%s'", ErrNoChecklist, call.Campaign.ID)
+	}
+
+	name, ok := lookup(call.Campaign.Checklist, item)
+	if !ok {
+		return nil, fmt.Errorf("%w: '%s', expected one of: %s", ErrUnknownItem, item, strings.Join(call.Campaign.Checklist, ", "))
+	}
+
+	so := &kv.Signoff{CampaignID: call.Campaign.ID, CallID: call.ID, Item: name, By: by, At: at.UTC()}
+	if err := store.AddSignoff(so); err != nil {
+		return nil, err
+	}
+	return so, nil
+}
+
+func lookup(checklist []string, item string) (string, bool) {
+	item = strings.TrimSpace(item)
+	for _, name := range checklist {
+		if strings.EqualFold(name, item) {
+			return name, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,97 @@
+package checklist_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/andrewhowdencom/ruf/internal/checklist"
+	"github.com/andrewhowdencom/ruf/internal/datastore"
+	"github.com/andrewhowdencom/ruf/internal/kv"
+	"github.com/andrewhowdencom/ruf/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newStore(t *testing.T) (*datastore.MockStore, *kv.ScheduledCall) {
+	store := datastore.NewMockStore()
+	call := &kv.ScheduledCall{
+		Call: model.Call{
+			ID:       "launch:scheduled_at:2025-06-02T09:00:00Z:slack:#general",
+			Campaign: model.Campaign{ID: "launch", Name: "Launch", Checklist: []string{"Content reviewed", "Legal sign-off"}},
+		},
+		ScheduledAt: time.Date(2025, 6, 2, 9, 0, 0, 0, time.UTC),
+	}
+	require.NoError(t, store.AddScheduledCall(call))
+	return store, call
+}
+
+func TestSignOff(t *testing.T) {
+	store, call := newStore(t)
+
+	found, err := checklist.Find(store, kv.GenerateShortID(call.ID))
+	require.NoError(t, err)
+	assert.Equal(t, call.ID, found.ID)
+	_, err = checklist.Find(store, "missing")
+	assert.ErrorIs(t, err, kv.ErrNotFound)
+
+	pending, err := checklist.Pending(store, call)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Content reviewed", "Legal sign-off"}, pending)
+
+	at := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	so, err := checklist.SignOff(store, call, "legal SIGN-OFF", "jane", at)
+	require.NoError(t, err)
+	assert.Equal(t, "Legal sign-off", so.Item)
+
+	items, err := checklist.Status(store, call)
+	require.NoError(t, err)
+	if assert.Len(t, items, 2) {
+		assert.False(t, items[0].Done())
+		assert.True(t, items[1].Done())
+		assert.Equal(t, "jane", items[1].Signoff.By)
+		assert.Equal(t, at, items[1].Signoff.At)
+	}
+
+	_, err = checklist.SignOff(store, call, "translations ready", "jane", at)
+	assert.ErrorIs(t, err, checklist.ErrUnknownItem)
+
+	call.Campaign.Checklist = nil
+	_, err = checklist.SignOff(store, call, "legal sign-off", "jane", at)
+	assert.ErrorIs(t, err, checklist.ErrNoChecklist)
+}
+
+func TestHandler(t *testing.T) {
+	store, call := newStore(t)
+	handler := checklist.NewHandler(store, "secret")
+	path := "/checklist/" + kv.GenerateShortID(call.ID)
+
+	do := func(method, path, token, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	assert.Equal(t, http.StatusUnauthorized, do(http.MethodGet, path, "", "").Code)
+	assert.Equal(t, http.StatusUnauthorized, do(http.MethodGet, path, "wrong", "").Code)
+	assert.Equal(t, http.StatusNotFound, do(http.MethodGet, "/checklist/missing", "secret", "").Code)
+	assert.Equal(t, http.StatusBadRequest, do(http.MethodPost, path, "secret", `{"item": "nope", "by": "jane"}`).Code)
+	assert.Equal(t, http.StatusBadRequest, do(http.MethodPost, path, "secret", `{"item": "legal sign-off"}`).Code)
+
+	rec := do(http.MethodPost, path, "secret", `{"item": "legal sign-off", "by": "jane"}`)
+	require.Equal(t, http.StatusOK, rec.Code)
+	var status struct {
+		CallID  string   `json:"call_id"`
+		Pending []string `json:"pending"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &status))
+	assert.Equal(t, call.ID, status.CallID)
+	assert.Equal(t, []string{"Content reviewed"}, status.Pending)
+}
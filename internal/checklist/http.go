@@ -0,0 +1,114 @@
+package checklist
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/andrewhowdencom/ruf/internal/kv"
+)
+
+// status is the checklist of a scheduled call, as returned by the API.
+type status struct {
+	CampaignID string   `json:"campaign_id"`
+	CallID     string   `json:"call_id"`
+	Items      []Item   `json:"items"`
+	Pending    []string `json:"pending"`
+}
+
+// signoffRequest is the body of a request to sign off a checklist item.
+type signoffRequest struct {
+	Item string `json:"item"`
+	By   string `json:"by"`
+}
+
+// NewHandler returns the handler of the checklist API, which requires the token as a bearer token:
+//
+//	GET  /checklist/{id}  returns the checklist of a scheduled call.
+//	POST /checklist/{id}  signs off an item, given as {"item": "...", "by": "..."}.
+//
+// The ID is the ID of the scheduled call, or its short ID.
+func NewHandler(store kv.Storer, token string) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /checklist/{id}", func(w http.ResponseWriter, r *http.Request) {
+		call, ok := find(w, store, r.PathValue("id"))
+		if !ok {
+			return
+		}
+		items, err := Status(store, call)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		writeStatus(w, call, items)
+	})
+	mux.HandleFunc("POST /checklist/{id}", func(w http.ResponseWriter, r *http.Request) {
+		var req signoffRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "invalid request body: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		if req.By == "" {
+			http.Error(w, "'by' is required", http.StatusBadRequest)
+			return
+		}
+		call, ok := find(w, store, r.PathValue("id"))
+		if !ok {
+			return
+		}
+		if _, err := SignOff(store, call, req.Item, req.By, time.Now()); err != nil {
+			code := http.StatusInternalServerError
+			if errors.Is(err, ErrUnknownItem) || errors.Is(err, ErrNoChecklist) {
+				code = http.StatusBadRequest
+			}
+			http.Error(w, err.Error(), code)
+			return
+		}
+		items, err := Status(store, call)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		writeStatus(w, call, items)
+	})
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		given, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(given), []byte(token)) != 1 {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		mux.ServeHTTP(w, r)
+	})
+}
+
+// find writes the error response and returns false if the scheduled call cannot be found.
+func find(w http.ResponseWriter, store kv.Storer, id string) (*kv.ScheduledCall, bool) {
+	call, err := Find(store, id)
+	switch {
+	case errors.Is(err, kv.ErrNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return nil, false
+	case errors.Is(err, kv.ErrAmbiguousID):
+		http.Error(w, err.Error(), http.StatusConflict)
+		return nil, false
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return nil, false
+	}
+	return call, true
+}
+
+func writeStatus(w http.ResponseWriter, call *kv.ScheduledCall, items []Item) {
+	s := status{CampaignID: call.Campaign.ID, CallID: call.ID, Items: items, Pending: []string{}}
+	for _, item := range items {
+		if !item.Done() {
+			s.Pending = append(s.Pending, item.Name)
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(s)
+}
@@ -0,0 +1,79 @@
+package checklist
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/andrewhowdencom/ruf/internal/kv"
+	"github.com/slack-go/slack"
+)
+
+// NewSlackHandler returns the handler of a Slack slash command, such as /ruf-checklist, that signs off
+// checklist items. Requests are verified with the signing secret of the Slack app.
+//
+// The text of the command is the short ID of a scheduled call, which shows its checklist, followed by
+// an item to sign it off as the user who ran the command.
+func NewSlackHandler(store kv.Storer, signingSecret string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		verifier, err := slack.NewSecretsVerifier(r.Header, signingSecret)
+		if err != nil {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		r.Body = io.NopCloser(io.TeeReader(r.Body, &verifier))
+		cmd, err := slack.SlashCommandParse(r)
+		if err != nil {
+			http.Error(w, "invalid slash command", http.StatusBadRequest)
+			return
+		}
+		if err := verifier.Ensure(); err != nil {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		fmt.Fprint(w, slashCommand(store, cmd.Text, cmd.UserName))
+	})
+}
+
+// slashCommand runs the text of a slash command, and returns the reply.
+func slashCommand(store kv.Storer, text, user string) string {
+	id, item, _ := strings.Cut(strings.TrimSpace(text), " ")
+	if id == "" {
+		return "Usage: <short-id> [item]"
+	}
+
+	call, err := Find(store, id)
+	if err != nil {
+		return err.Error()
+	}
+	if item = strings.TrimSpace(item); item != "" {
+		so, err := SignOff(store, call, item, user, time.Now())
+		if err != nil {
+			return err.Error()
+		}
+		slog.Info("signed off checklist item", "call_id", call.ID, "item", so.Item, "by", so.By)
+	}
+
+	items, err := Status(store, call)
+	if err != nil {
+		return err.Error()
+	}
+	if len(items) == 0 {
+		return fmt.Sprintf("Campaign '%s' has no checklist.", call.Campaign.ID)
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "Checklist of '%s' (%s):\n", call.ID, kv.GenerateShortID(call.ID))
+	for _, i := range items {
+		if i.Done() {
+			fmt.Fprintf(&b, "[x] %s, by %s at %s\n", i.Name, i.Signoff.By, i.Signoff.At.Format(time.RFC3339))
+		} else {
+			fmt.Fprintf(&b, "[ ] %s\n", i.Name)
+		}
+	}
+	return b.String()
+}
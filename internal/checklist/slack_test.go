@@ -0,0 +1,50 @@
+package checklist_test
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/andrewhowdencom/ruf/internal/checklist"
+	"github.com/andrewhowdencom/ruf/internal/kv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSlackHandler(t *testing.T) {
+	store, call := newStore(t)
+	handler := checklist.NewSlackHandler(store, "signing-secret")
+
+	command := func(text, secret string) *httptest.ResponseRecorder {
+		body := url.Values{"command": {"/ruf-checklist"}, "text": {text}, "user_name": {"jane"}}.Encode()
+		ts := strconv.FormatInt(time.Now().Unix(), 10)
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write([]byte("v0:" + ts + ":" + body))
+
+		req := httptest.NewRequest(http.MethodPost, "/slack/commands", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.Header.Set("X-Slack-Request-Timestamp", ts)
+		req.Header.Set("X-Slack-Signature", "v0="+hex.EncodeToString(mac.Sum(nil)))
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	shortID := kv.GenerateShortID(call.ID)
+	assert.Equal(t, http.StatusUnauthorized, command(shortID, "wrong").Code)
+
+	rec := command(shortID+" content reviewed", "signing-secret")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "[x] Content reviewed, by jane")
+	assert.Contains(t, rec.Body.String(), "[ ] Legal sign-off")
+
+	rec = command(shortID+" translations", "signing-secret")
+	assert.Contains(t, rec.Body.String(), "unknown checklist item")
+}
@@ -0,0 +1,120 @@
+package chatwork
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	rufhttp "github.com/andrewhowdencom/ruf/internal/http"
+	"github.com/andrewhowdencom/ruf/internal/model"
+)
+
+// DefaultEndpoint is the base URL of the Chatwork API.
+const DefaultEndpoint = "https://api.chatwork.com/v2"
+
+// Err* are common errors returned by the Chatwork client.
+var (
+	ErrPostFailed = errors.New("failed to post chatwork message")
+)
+
+// Client is an interface that defines the methods for interacting with the Chatwork API.
+type Client interface {
+	PostMessage(roomID, author, subject, body string, campaign model.Campaign) (string, error)
+}
+
+// client is the concrete implementation of the Client interface.
+type client struct {
+	token      string
+	endpoint   string
+	httpClient *http.Client
+}
+
+// Option configures the client.
+type Option func(*client)
+
+// WithEndpoint overrides the base URL of the Chatwork API.
+func WithEndpoint(endpoint string) Option {
+	return func(c *client) {
+		c.endpoint = strings.TrimSuffix(endpoint, "/")
+	}
+}
+
+// WithHTTPClient overrides the HTTP client used to call the API.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *client) {
+		c.httpClient = httpClient
+	}
+}
+
+// NewClient creates a new Chatwork client authenticating with the given API token.
+func NewClient(token string, opts ...Option) Client {
+	c := &client{
+		token:      token,
+		endpoint:   DefaultEndpoint,
+		httpClient: rufhttp.NewClient(),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// PostMessage posts a message to a Chatwork room, returning the ID of the message.
+func (c *client) PostMessage(roomID, author, subject, body string, campaign model.Campaign) (string, error) {
+	form := url.Values{"body": {formatMessage(author, subject, body, campaign)}}
+
+	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("%s/rooms/%s/messages", c.endpoint, url.PathEscape(roomID)), strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrPostFailed, err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("X-ChatWorkToken", c.token)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrPostFailed, err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrPostFailed, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("%w: status code %d: %s", ErrPostFailed, resp.StatusCode, strings.TrimSpace(string(respBody)))
+	}
+
+	var result struct {
+		MessageID string `json:"message_id"`
+	}
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return "", fmt.Errorf("%w: failed to decode response: %w", ErrPostFailed, err)
+	}
+	return result.MessageID, nil
+}
+
+// Permalink returns a link to a message posted in a room.
+func Permalink(roomID, messageID string) string {
+	return fmt.Sprintf("https://www.chatwork.com/#!rid%s-%s", roomID, messageID)
+}
+
+// formatMessage renders the message using Chatwork's message notation, titling it with the subject
+// and attributing it to the author or campaign.
+func formatMessage(author, subject, body string, campaign model.Campaign) string {
+	message := body
+	if subject != "" {
+		message = fmt.Sprintf("[info][title]%s[/title]%s[/info]", subject, body)
+	}
+
+	switch {
+	case author != "":
+		message = fmt.Sprintf("%s\n\n---\nThx: %s", message, author)
+	case campaign.Name != "":
+		message = fmt.Sprintf("%s\n\n---\n%s", message, campaign.Name)
+	}
+	return message
+}
@@ -0,0 +1,43 @@
+package chatwork_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/andrewhowdencom/ruf/internal/clients/chatwork"
+	"github.com/andrewhowdencom/ruf/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClient_PostMessage(t *testing.T) {
+	t.Run("posts the message to the room", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/rooms/12345/messages", r.URL.Path)
+			assert.Equal(t, "token", r.Header.Get("X-ChatWorkToken"))
+			assert.NoError(t, r.ParseForm())
+			assert.Equal(t, "[info][title]Subject[/title]Hello![/info]\n\n---\nCampaign", r.PostForm.Get("body"))
+			w.Write([]byte(`{"message_id":"9876"}`))
+		}))
+		defer server.Close()
+
+		client := chatwork.NewClient("token", chatwork.WithEndpoint(server.URL), chatwork.WithHTTPClient(server.Client()))
+		id, err := client.PostMessage("12345", "", "Subject", "Hello!", model.Campaign{Name: "Campaign"})
+		assert.NoError(t, err)
+		assert.Equal(t, "9876", id)
+		assert.Equal(t, "https://www.chatwork.com/#!rid12345-9876", chatwork.Permalink("12345", id))
+	})
+
+	t.Run("returns the error reported by the API", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"errors":["You don't have permission to send messages in this room"]}`))
+		}))
+		defer server.Close()
+
+		client := chatwork.NewClient("token", chatwork.WithEndpoint(server.URL), chatwork.WithHTTPClient(server.Client()))
+		_, err := client.PostMessage("12345", "", "", "Hello!", model.Campaign{})
+		assert.ErrorIs(t, err, chatwork.ErrPostFailed)
+		assert.ErrorContains(t, err, "permission")
+	})
+}
@@ -0,0 +1,48 @@
+package chatwork
+
+import "github.com/andrewhowdencom/ruf/internal/model"
+
+// MockClient is a mock implementation of the Client interface for testing.
+type MockClient struct {
+	PostMessageFunc func(roomID, author, subject, body string, campaign model.Campaign) (string, error)
+
+	postMessageCalls []struct {
+		RoomID   string
+		Author   string
+		Subject  string
+		Body     string
+		Campaign model.Campaign
+	}
+}
+
+// NewMockClient creates a new MockClient.
+func NewMockClient() *MockClient {
+	return &MockClient{
+		PostMessageFunc: func(roomID, author, subject, body string, campaign model.Campaign) (string, error) {
+			return "1234567890", nil
+		},
+	}
+}
+
+// PostMessage calls the PostMessageFunc.
+func (m *MockClient) PostMessage(roomID, author, subject, body string, campaign model.Campaign) (string, error) {
+	m.postMessageCalls = append(m.postMessageCalls, struct {
+		RoomID   string
+		Author   string
+		Subject  string
+		Body     string
+		Campaign model.Campaign
+	}{roomID, author, subject, body, campaign})
+	return m.PostMessageFunc(roomID, author, subject, body, campaign)
+}
+
+// PostMessageCalls returns the recorded calls to PostMessage.
+func (m *MockClient) PostMessageCalls() []struct {
+	RoomID   string
+	Author   string
+	Subject  string
+	Body     string
+	Campaign model.Campaign
+} {
+	return m.postMessageCalls
+}
@@ -1,20 +1,45 @@
 package email
 
 import (
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"net"
+	"net/mail"
 	"net/smtp"
+	"sort"
+	"strings"
 
 	"github.com/andrewhowdencom/ruf/internal/model"
 )
 
+// ErrInvalidAddress is returned for a recipient that is not an email address.
+var ErrInvalidAddress = errors.New("invalid email address")
+
+// ValidateAddress checks that a recipient is an email address, before an email is sent to it.
+func ValidateAddress(to string) error {
+	if _, err := mail.ParseAddress(to); err != nil {
+		return fmt.Errorf("%w '%s': %w", ErrInvalidAddress, to, err)
+	}
+	return nil
+}
+
 // Client is an interface for sending emails.
 type Client interface {
-	Send(to []string, author, subject, body string, campaign model.Campaign) error
+	// Send sends the email, returning the Message-ID it was sent with.
+	Send(ctx context.Context, to []string, author, subject, body string, campaign model.Campaign) (string, error)
+	// Reply sends the email in the thread of the emails with the Message-IDs of references, oldest
+	// first, returning the Message-ID it was sent with.
+	Reply(ctx context.Context, to []string, author, subject, body string, campaign model.Campaign, references []string) (string, error)
 }
 
 // SMTPClient is a client for sending emails using SMTP.
 type SMTPClient struct {
 	addr string
+	host string
 	auth smtp.Auth
 	from string
 }
@@ -26,33 +51,44 @@ func NewClient(host string, port int, username, password, from string) Client {
 
 	return &SMTPClient{
 		addr: addr,
+		host: host,
 		auth: auth,
 		from: from,
 	}
 }
 
 // Send sends an email to the specified recipients.
-func (c *SMTPClient) Send(to []string, author, subject, body string, campaign model.Campaign) error {
+func (c *SMTPClient) Send(ctx context.Context, to []string, author, subject, body string, campaign model.Campaign) (string, error) {
+	return c.send(ctx, to, author, subject, body, campaign, nil)
+}
+
+// Reply sends an email to the specified recipients, with the headers that thread it under the emails
+// of references.
+func (c *SMTPClient) Reply(ctx context.Context, to []string, author, subject, body string, campaign model.Campaign, references []string) (string, error) {
+	return c.send(ctx, to, author, subject, body, campaign, references)
+}
+
+func (c *SMTPClient) send(ctx context.Context, to []string, author, subject, body string, campaign model.Campaign, references []string) (string, error) {
+	messageID, err := c.newMessageID()
+	if err != nil {
+		return "", err
+	}
+
 	var errs []error
 	for _, recipient := range to {
 		// Default headers
 		headers := map[string]string{
-			"To":      recipient,
-			"Subject": subject,
+			"To":         recipient,
+			"Subject":    Subject(subject, campaign),
+			"Message-ID": messageID,
 		}
-
-		if campaign.Name != "" {
-			headers["Subject"] = fmt.Sprintf("[%s] %s", campaign.Name, subject)
+		if len(references) > 0 {
+			headers["In-Reply-To"] = references[len(references)-1]
+			headers["References"] = strings.Join(references, " ")
 		}
 
-		// Build message body
 		buildMessage := func(hdrs map[string]string) string {
-			msg := ""
-			for k, v := range hdrs {
-				msg += fmt.Sprintf("%s: %s\r\n", k, v)
-			}
-			msg += "\r\n" + body
-			return msg
+			return Message(hdrs, body)
 		}
 
 		// If author is present, first attempt to send from author's email.
@@ -62,7 +98,7 @@ func (c *SMTPClient) Send(to []string, author, subject, body string, campaign mo
 			msg := buildMessage(headers)
 
 			// Attempt to send with the author's email as the SMTP FROM address.
-			err := smtp.SendMail(c.addr, c.auth, author, []string{recipient}, []byte(msg))
+			err := c.sendMail(ctx, author, recipient, []byte(msg))
 			if err == nil {
 				continue // Success, move to next recipient
 			}
@@ -82,27 +118,131 @@ func (c *SMTPClient) Send(to []string, author, subject, body string, campaign mo
 
 		msg := buildMessage(headers)
 
-		err := smtp.SendMail(c.addr, c.auth, c.from, []string{recipient}, []byte(msg))
+		err := c.sendMail(ctx, c.from, recipient, []byte(msg))
 		if err != nil {
 			errs = append(errs, fmt.Errorf("failed to send email to %s: %w", recipient, err))
 		}
 	}
 
 	if len(errs) > 0 {
-		return fmt.Errorf("failed to send email to some recipients: %v", errs)
+		return messageID, fmt.Errorf("failed to send email to some recipients: %v", errs)
 	}
 
-	return nil
+	return messageID, nil
+}
+
+// sendMail sends a message as smtp.SendMail does, but gives up once the context is done, so that an
+// SMTP server that stops responding cannot hold the sender forever.
+func (c *SMTPClient) sendMail(ctx context.Context, from, to string, msg []byte) error {
+	return c.session(ctx, func(client *smtp.Client) error {
+		if err := client.Mail(from); err != nil {
+			return err
+		}
+		if err := client.Rcpt(to); err != nil {
+			return err
+		}
+		w, err := client.Data()
+		if err != nil {
+			return err
+		}
+		if _, err := w.Write(msg); err != nil {
+			return err
+		}
+		return w.Close()
+	})
+}
+
+// Login connects to the SMTP server and logs in with the credentials of the client, without sending
+// anything, so that the configuration of the server can be checked.
+func Login(ctx context.Context, host string, port int, username, password string) error {
+	c := NewClient(host, port, username, password, "").(*SMTPClient)
+	return c.session(ctx, func(*smtp.Client) error { return nil })
+}
+
+// session connects to the SMTP server, upgrading the connection to TLS and logging in where the server
+// supports it, and runs fn before quitting. The connection is closed once the context is done.
+func (c *SMTPClient) session(ctx context.Context, fn func(*smtp.Client) error) error {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", c.addr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	// Closing the connection interrupts the exchange when the context is cancelled.
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	client, err := smtp.NewClient(conn, c.host)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: c.host}); err != nil {
+			return err
+		}
+	}
+	if ok, _ := client.Extension("AUTH"); ok && c.auth != nil {
+		if err := client.Auth(c.auth); err != nil {
+			return err
+		}
+	}
+	if err := fn(client); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+// Subject returns the subject line of an email, prefixed with the name of its campaign.
+func Subject(subject string, campaign model.Campaign) string {
+	if campaign.Name != "" {
+		return fmt.Sprintf("[%s] %s", campaign.Name, subject)
+	}
+	return subject
+}
+
+// Message builds an email from its headers, written in order of their names, and its body.
+func Message(headers map[string]string, body string) string {
+	names := make([]string, 0, len(headers))
+	for k := range headers {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	var msg strings.Builder
+	for _, k := range names {
+		fmt.Fprintf(&msg, "%s: %s\r\n", k, headers[k])
+	}
+	msg.WriteString("\r\n" + body)
+	return msg.String()
+}
+
+// newMessageID generates a unique Message-ID header in the domain of the sender.
+func (c *SMTPClient) newMessageID() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate message id: %w", err)
+	}
+
+	domain := "localhost"
+	if i := strings.LastIndex(c.from, "@"); i >= 0 {
+		domain = strings.Trim(c.from[i+1:], "> ")
+	}
+	return fmt.Sprintf("<%s@%s>", hex.EncodeToString(buf), domain), nil
 }
 
 // MockClient is a mock implementation of the Client interface.
 type MockClient struct {
 	sendCalls []struct {
-		To       []string
-		Author   string
-		Subject  string
-		Body     string
-		Campaign model.Campaign
+		To         []string
+		Author     string
+		Subject    string
+		Body       string
+		Campaign   model.Campaign
+		References []string
 	}
 }
 
@@ -112,24 +252,31 @@ func NewMockClient() *MockClient {
 }
 
 // Send is the mock implementation of the Send method.
-func (m *MockClient) Send(to []string, author, subject, body string, campaign model.Campaign) error {
+func (m *MockClient) Send(ctx context.Context, to []string, author, subject, body string, campaign model.Campaign) (string, error) {
+	return m.Reply(ctx, to, author, subject, body, campaign, nil)
+}
+
+// Reply is the mock implementation of the Reply method. Replies are recorded with the sends.
+func (m *MockClient) Reply(_ context.Context, to []string, author, subject, body string, campaign model.Campaign, references []string) (string, error) {
 	m.sendCalls = append(m.sendCalls, struct {
-		To       []string
-		Author   string
-		Subject  string
-		Body     string
-		Campaign model.Campaign
-	}{to, author, subject, body, campaign})
-	return nil
+		To         []string
+		Author     string
+		Subject    string
+		Body       string
+		Campaign   model.Campaign
+		References []string
+	}{to, author, subject, body, campaign, references})
+	return fmt.Sprintf("<%d@example.com>", len(m.sendCalls)), nil
 }
 
-// SendCalls returns the recorded calls to Send.
+// SendCalls returns the recorded calls to Send and Reply.
 func (m *MockClient) SendCalls() []struct {
-	To       []string
-	Author   string
-	Subject  string
-	Body     string
-	Campaign model.Campaign
+	To         []string
+	Author     string
+	Subject    string
+	Body       string
+	Campaign   model.Campaign
+	References []string
 } {
 	return m.sendCalls
 }
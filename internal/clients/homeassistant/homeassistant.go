@@ -0,0 +1,173 @@
+// Package homeassistant sends calls to Home Assistant by calling one of its services, such as a
+// notify service that reaches a mobile app, or a script that flashes the lights or speaks through a
+// speaker.
+package homeassistant
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"text/template"
+
+	rufhttp "github.com/andrewhowdencom/ruf/internal/http"
+	"github.com/andrewhowdencom/ruf/internal/model"
+)
+
+// DefaultDomain is the domain of the service called when a destination names only the service.
+const DefaultDomain = "notify"
+
+// DefaultPayloadTemplate is the service data sent when none is configured. It is understood by the
+// notify services.
+const DefaultPayloadTemplate = `{"title": {{ json .Subject }}, "message": {{ json .Body }}}`
+
+// Err* are common errors returned by the Home Assistant client.
+var (
+	ErrInvalidConfig = errors.New("invalid home assistant configuration")
+	ErrInvalidTarget = errors.New("invalid home assistant service")
+	ErrCallFailed    = errors.New("failed to call home assistant service")
+)
+
+// Payload is the data the payload templates are executed with.
+type Payload struct {
+	// Domain and Service are the service being called.
+	Domain  string
+	Service string
+	Author  string
+	Subject string
+	Body    string
+	// Campaign is the campaign of the call.
+	Campaign model.Campaign
+}
+
+// Client is an interface that defines the methods for calling Home Assistant services.
+type Client interface {
+	// CallService calls the service named by to, either "<domain>.<service>" or, for a notify
+	// service, just "<service>". The returned ID is always empty, as services do not identify
+	// what they did.
+	CallService(to, author, subject, body string, campaign model.Campaign) (string, error)
+}
+
+// client is the concrete implementation of the Client interface.
+type client struct {
+	url        string
+	token      string
+	templates  map[string]string
+	payloads   map[string]*template.Template
+	httpClient *http.Client
+}
+
+// Option configures the client.
+type Option func(*client)
+
+// WithHTTPClient overrides the HTTP client used to call the API.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithPayloadTemplate sets the template of the service data sent to the services of a domain, or
+// to a single "<domain>.<service>". The template of a service takes precedence over that of its
+// domain, which takes precedence over DefaultPayloadTemplate.
+func WithPayloadTemplate(target, tmpl string) Option {
+	return func(c *client) {
+		c.templates[target] = tmpl
+	}
+}
+
+// NewClient creates a client of the Home Assistant instance at the URL, authenticating with a
+// long-lived access token, and parses its payload templates.
+func NewClient(url, token string, opts ...Option) (Client, error) {
+	if url == "" || token == "" {
+		return nil, fmt.Errorf("%w: both a url and a token are required", ErrInvalidConfig)
+	}
+	c := &client{
+		url:        strings.TrimSuffix(url, "/"),
+		token:      token,
+		templates:  map[string]string{DefaultDomain: DefaultPayloadTemplate},
+		payloads:   make(map[string]*template.Template),
+		httpClient: rufhttp.NewClient(),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	funcs := template.FuncMap{"json": toJSON}
+	for target, tmpl := range c.templates {
+		t, err := template.New(target).Funcs(funcs).Parse(tmpl)
+		if err != nil {
+			return nil, fmt.Errorf("%w: payload template of %s: %w", ErrInvalidConfig, target, err)
+		}
+		c.payloads[target] = t
+	}
+	return c, nil
+}
+
+// CallService renders the service data and calls the service.
+func (c *client) CallService(to, author, subject, body string, campaign model.Campaign) (string, error) {
+	domain, service, err := ParseTarget(to)
+	if err != nil {
+		return "", err
+	}
+	data := Payload{Domain: domain, Service: service, Author: author, Subject: subject, Body: body, Campaign: campaign}
+
+	var payload bytes.Buffer
+	if err := c.payload(domain, service).Execute(&payload, data); err != nil {
+		return "", fmt.Errorf("%w: %s.%s: payload: %w", ErrCallFailed, domain, service, err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("%s/api/services/%s/%s", c.url, domain, service), &payload)
+	if err != nil {
+		return "", fmt.Errorf("%w: %s.%s: %w", ErrCallFailed, domain, service, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+c.token)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("%w: %s.%s: %w", ErrCallFailed, domain, service, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return "", fmt.Errorf("%w: %s.%s: status code %d: %s", ErrCallFailed, domain, service, resp.StatusCode, strings.TrimSpace(string(respBody)))
+	}
+	return "", nil
+}
+
+// payload returns the template of the service data of a service.
+func (c *client) payload(domain, service string) *template.Template {
+	if t, ok := c.payloads[domain+"."+service]; ok {
+		return t
+	}
+	if t, ok := c.payloads[domain]; ok {
+		return t
+	}
+	return c.payloads[DefaultDomain]
+}
+
+// ParseTarget splits the address of a destination into the domain and service it calls.
+func ParseTarget(to string) (string, string, error) {
+	domain, service, ok := strings.Cut(to, ".")
+	if !ok {
+		domain, service = DefaultDomain, to
+	}
+	if domain == "" || service == "" || strings.ContainsAny(domain+service, "./ ") {
+		return "", "", fmt.Errorf("%w: %s: must be <service> or <domain>.<service>", ErrInvalidTarget, to)
+	}
+	return domain, service, nil
+}
+
+// toJSON encodes a value as JSON, so that templates can place text in a JSON payload safely.
+func toJSON(v interface{}) (string, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
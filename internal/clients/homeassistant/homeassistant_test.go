@@ -0,0 +1,75 @@
+package homeassistant_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/andrewhowdencom/ruf/internal/clients/homeassistant"
+	"github.com/andrewhowdencom/ruf/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClient_CallService(t *testing.T) {
+	t.Run("calls a notify service with the default payload", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/api/services/notify/mobile_app_pixel", r.URL.Path)
+			assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+			body, err := io.ReadAll(r.Body)
+			assert.NoError(t, err)
+			assert.JSONEq(t, `{"title": "Bins", "message": "Put the \"green\" bin out."}`, string(body))
+		}))
+		defer server.Close()
+
+		client, err := homeassistant.NewClient(server.URL+"/", "token", homeassistant.WithHTTPClient(server.Client()))
+		assert.NoError(t, err)
+		_, err = client.CallService("mobile_app_pixel", "", "Bins", `Put the "green" bin out.`, model.Campaign{})
+		assert.NoError(t, err)
+	})
+
+	t.Run("renders the payload template of the service", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/api/services/tts/speak", r.URL.Path)
+			body, err := io.ReadAll(r.Body)
+			assert.NoError(t, err)
+			assert.JSONEq(t, `{"entity_id": "tts.piper", "message": "Dinner is ready."}`, string(body))
+		}))
+		defer server.Close()
+
+		client, err := homeassistant.NewClient(server.URL, "token",
+			homeassistant.WithHTTPClient(server.Client()),
+			homeassistant.WithPayloadTemplate("tts", `{"entity_id": "tts.other", "message": {{ json .Body }}}`),
+			homeassistant.WithPayloadTemplate("tts.speak", `{"entity_id": "tts.piper", "message": {{ json .Body }}}`),
+		)
+		assert.NoError(t, err)
+		_, err = client.CallService("tts.speak", "", "", "Dinner is ready.", model.Campaign{})
+		assert.NoError(t, err)
+	})
+
+	t.Run("returns the error reported by the API", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`Service notify.missing not found.`))
+		}))
+		defer server.Close()
+
+		client, err := homeassistant.NewClient(server.URL, "token", homeassistant.WithHTTPClient(server.Client()))
+		assert.NoError(t, err)
+		_, err = client.CallService("missing", "", "", "Hello!", model.Campaign{})
+		assert.ErrorIs(t, err, homeassistant.ErrCallFailed)
+		assert.ErrorContains(t, err, "not found")
+	})
+
+	t.Run("rejects an invalid service", func(t *testing.T) {
+		client, err := homeassistant.NewClient("http://localhost:8123", "token")
+		assert.NoError(t, err)
+		_, err = client.CallService("notify.", "", "", "Hello!", model.Campaign{})
+		assert.ErrorIs(t, err, homeassistant.ErrInvalidTarget)
+	})
+
+	t.Run("rejects an invalid payload template", func(t *testing.T) {
+		_, err := homeassistant.NewClient("http://localhost:8123", "token", homeassistant.WithPayloadTemplate("notify", "{{ .Body"))
+		assert.ErrorIs(t, err, homeassistant.ErrInvalidConfig)
+	})
+}
@@ -0,0 +1,48 @@
+package homeassistant
+
+import "github.com/andrewhowdencom/ruf/internal/model"
+
+// MockClient is a mock implementation of the Client interface for testing.
+type MockClient struct {
+	CallServiceFunc func(to, author, subject, body string, campaign model.Campaign) (string, error)
+
+	callServiceCalls []struct {
+		To       string
+		Author   string
+		Subject  string
+		Body     string
+		Campaign model.Campaign
+	}
+}
+
+// NewMockClient creates a new MockClient.
+func NewMockClient() *MockClient {
+	return &MockClient{
+		CallServiceFunc: func(to, author, subject, body string, campaign model.Campaign) (string, error) {
+			return "", nil
+		},
+	}
+}
+
+// CallService calls the CallServiceFunc.
+func (m *MockClient) CallService(to, author, subject, body string, campaign model.Campaign) (string, error) {
+	m.callServiceCalls = append(m.callServiceCalls, struct {
+		To       string
+		Author   string
+		Subject  string
+		Body     string
+		Campaign model.Campaign
+	}{to, author, subject, body, campaign})
+	return m.CallServiceFunc(to, author, subject, body, campaign)
+}
+
+// CallServiceCalls returns the recorded calls to CallService.
+func (m *MockClient) CallServiceCalls() []struct {
+	To       string
+	Author   string
+	Subject  string
+	Body     string
+	Campaign model.Campaign
+} {
+	return m.callServiceCalls
+}
@@ -0,0 +1,168 @@
+package line
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	rufhttp "github.com/andrewhowdencom/ruf/internal/http"
+	"github.com/andrewhowdencom/ruf/internal/model"
+)
+
+// DefaultEndpoint is the base URL of the LINE Messaging API.
+const DefaultEndpoint = "https://api.line.me/v2/bot"
+
+// altTextLimit is the maximum length of the alternative text LINE shows in notifications.
+const altTextLimit = 400
+
+// Err* are common errors returned by the LINE client.
+var (
+	ErrPushFailed = errors.New("failed to push line message")
+)
+
+// Client is an interface that defines the methods for interacting with the LINE Messaging API.
+type Client interface {
+	PushMessage(to, author, subject, body string, campaign model.Campaign) (string, error)
+}
+
+// client is the concrete implementation of the Client interface.
+type client struct {
+	token      string
+	endpoint   string
+	httpClient *http.Client
+}
+
+// Option configures the client.
+type Option func(*client)
+
+// WithEndpoint overrides the base URL of the LINE Messaging API.
+func WithEndpoint(endpoint string) Option {
+	return func(c *client) {
+		c.endpoint = strings.TrimSuffix(endpoint, "/")
+	}
+}
+
+// WithHTTPClient overrides the HTTP client used to call the API.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *client) {
+		c.httpClient = httpClient
+	}
+}
+
+// NewClient creates a new LINE client authenticating with the given channel access token.
+func NewClient(token string, opts ...Option) Client {
+	c := &client{
+		token:      token,
+		endpoint:   DefaultEndpoint,
+		httpClient: rufhttp.NewClient(),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// PushMessage pushes a Flex Message to a user, group or room, returning the ID of the message.
+func (c *client) PushMessage(to, author, subject, body string, campaign model.Campaign) (string, error) {
+	payload, err := json.Marshal(map[string]interface{}{
+		"to":       to,
+		"messages": []interface{}{FlexMessage(author, subject, body, campaign)},
+	})
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrPushFailed, err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, c.endpoint+"/message/push", bytes.NewReader(payload))
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrPushFailed, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+c.token)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrPushFailed, err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrPushFailed, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("%w: status code %d: %s", ErrPushFailed, resp.StatusCode, strings.TrimSpace(string(respBody)))
+	}
+
+	var result struct {
+		SentMessages []struct {
+			ID string `json:"id"`
+		} `json:"sentMessages"`
+	}
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return "", fmt.Errorf("%w: failed to decode response: %w", ErrPushFailed, err)
+	}
+	if len(result.SentMessages) == 0 {
+		return "", nil
+	}
+	return result.SentMessages[0].ID, nil
+}
+
+// FlexMessage renders a message as a LINE Flex Message bubble, with the campaign (and its icon) in
+// the header, the subject and body in the body, and the author attributed in the footer.
+func FlexMessage(author, subject, body string, campaign model.Campaign) map[string]interface{} {
+	bubble := map[string]interface{}{"type": "bubble"}
+
+	if campaign.Name != "" {
+		var header []interface{}
+		if campaign.IconURL != "" {
+			header = append(header, map[string]interface{}{
+				"type": "image", "url": campaign.IconURL, "size": "xxs", "flex": 0,
+			})
+		}
+		header = append(header, map[string]interface{}{
+			"type": "text", "text": campaign.Name, "weight": "bold", "gravity": "center", "margin": "md",
+		})
+		bubble["header"] = map[string]interface{}{
+			"type": "box", "layout": "horizontal", "contents": header,
+		}
+	}
+
+	var contents []interface{}
+	if subject != "" {
+		contents = append(contents, map[string]interface{}{
+			"type": "text", "text": subject, "weight": "bold", "size": "lg", "wrap": true,
+		})
+	}
+	contents = append(contents, map[string]interface{}{
+		"type": "text", "text": body, "wrap": true, "margin": "md",
+	})
+	bubble["body"] = map[string]interface{}{
+		"type": "box", "layout": "vertical", "contents": contents,
+	}
+
+	if author != "" {
+		bubble["footer"] = map[string]interface{}{
+			"type": "box", "layout": "vertical", "contents": []interface{}{
+				map[string]interface{}{"type": "text", "text": "Thx: " + author, "size": "xs", "color": "#999999"},
+			},
+		}
+	}
+
+	altText := body
+	if subject != "" {
+		altText = subject
+	}
+	if runes := []rune(altText); len(runes) > altTextLimit {
+		altText = string(runes[:altTextLimit])
+	}
+
+	return map[string]interface{}{
+		"type":     "flex",
+		"altText":  altText,
+		"contents": bubble,
+	}
+}
@@ -0,0 +1,54 @@
+package line_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/andrewhowdencom/ruf/internal/clients/line"
+	"github.com/andrewhowdencom/ruf/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClient_PushMessage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/message/push", r.URL.Path)
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+
+		var payload struct {
+			To       string                   `json:"to"`
+			Messages []map[string]interface{} `json:"messages"`
+		}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+		assert.Equal(t, "U1234", payload.To)
+		assert.Len(t, payload.Messages, 1)
+		assert.Equal(t, "flex", payload.Messages[0]["type"])
+		assert.Equal(t, "Subject", payload.Messages[0]["altText"])
+
+		w.Write([]byte(`{"sentMessages":[{"id":"461230966842064897","quoteToken":"abc"}]}`))
+	}))
+	defer server.Close()
+
+	client := line.NewClient("token", line.WithEndpoint(server.URL), line.WithHTTPClient(server.Client()))
+	id, err := client.PushMessage("U1234", "", "Subject", "Hello!", model.Campaign{Name: "Campaign"})
+	assert.NoError(t, err)
+	assert.Equal(t, "461230966842064897", id)
+}
+
+func TestFlexMessage(t *testing.T) {
+	msg := line.FlexMessage("author@example.com", "Subject", "Hello!", model.Campaign{Name: "Campaign", IconURL: "https://example.com/icon.png"})
+
+	bubble := msg["contents"].(map[string]interface{})
+	header := bubble["header"].(map[string]interface{})["contents"].([]interface{})
+	assert.Len(t, header, 2)
+	assert.Equal(t, "https://example.com/icon.png", header[0].(map[string]interface{})["url"])
+	assert.Equal(t, "Campaign", header[1].(map[string]interface{})["text"])
+
+	body := bubble["body"].(map[string]interface{})["contents"].([]interface{})
+	assert.Equal(t, "Subject", body[0].(map[string]interface{})["text"])
+	assert.Equal(t, "Hello!", body[1].(map[string]interface{})["text"])
+
+	footer := bubble["footer"].(map[string]interface{})["contents"].([]interface{})
+	assert.Equal(t, "Thx: author@example.com", footer[0].(map[string]interface{})["text"])
+}
@@ -0,0 +1,48 @@
+package line
+
+import "github.com/andrewhowdencom/ruf/internal/model"
+
+// MockClient is a mock implementation of the Client interface for testing.
+type MockClient struct {
+	PushMessageFunc func(to, author, subject, body string, campaign model.Campaign) (string, error)
+
+	pushMessageCalls []struct {
+		To       string
+		Author   string
+		Subject  string
+		Body     string
+		Campaign model.Campaign
+	}
+}
+
+// NewMockClient creates a new MockClient.
+func NewMockClient() *MockClient {
+	return &MockClient{
+		PushMessageFunc: func(to, author, subject, body string, campaign model.Campaign) (string, error) {
+			return "461230966842064897", nil
+		},
+	}
+}
+
+// PushMessage calls the PushMessageFunc.
+func (m *MockClient) PushMessage(to, author, subject, body string, campaign model.Campaign) (string, error) {
+	m.pushMessageCalls = append(m.pushMessageCalls, struct {
+		To       string
+		Author   string
+		Subject  string
+		Body     string
+		Campaign model.Campaign
+	}{to, author, subject, body, campaign})
+	return m.PushMessageFunc(to, author, subject, body, campaign)
+}
+
+// PushMessageCalls returns the recorded calls to PushMessage.
+func (m *MockClient) PushMessageCalls() []struct {
+	To       string
+	Author   string
+	Subject  string
+	Body     string
+	Campaign model.Campaign
+} {
+	return m.pushMessageCalls
+}
@@ -0,0 +1,77 @@
+package slack
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/slack-go/slack"
+)
+
+// RequiredScopes are the OAuth scopes the token of the app needs for every call ruf makes to Slack.
+var RequiredScopes = []string{"channels:read", "groups:read", "chat:write", "im:write", "users:read.email"}
+
+// Auth is who a token authenticates as, and the OAuth scopes it was granted.
+type Auth struct {
+	Team   string
+	User   string
+	Scopes []string
+}
+
+// Missing returns the required scopes that the token was not granted.
+func (a *Auth) Missing() []string {
+	var missing []string
+	for _, scope := range RequiredScopes {
+		if !slices.Contains(a.Scopes, scope) {
+			missing = append(missing, scope)
+		}
+	}
+	return missing
+}
+
+// AuthTest checks the token against auth.test, returning who it authenticates as and its scopes. The
+// scopes are only reported in a header of the response, which the Slack library does not expose, so
+// the method is called directly.
+func AuthTest(ctx context.Context, token string, opts ...Option) (*Auth, error) {
+	o := &options{endpoint: slack.APIURL}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, o.endpoint+"auth.test", strings.NewReader(url.Values{"token": {token}}.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call auth.test: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to call auth.test: %s", resp.Status)
+	}
+
+	var body struct {
+		slack.SlackResponse
+		slack.AuthTestResponse
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("failed to decode the response of auth.test: %w", err)
+	}
+	if err := body.Err(); err != nil {
+		return nil, err
+	}
+
+	auth := &Auth{Team: body.Team, User: body.User}
+	for _, scope := range strings.Split(resp.Header.Get("X-OAuth-Scopes"), ",") {
+		if scope = strings.TrimSpace(scope); scope != "" {
+			auth.Scopes = append(auth.Scopes, scope)
+		}
+	}
+	return auth, nil
+}
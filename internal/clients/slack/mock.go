@@ -1,13 +1,28 @@
 package slack
 
-import "github.com/andrewhowdencom/ruf/internal/model"
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/andrewhowdencom/ruf/internal/model"
+)
 
 // MockClient is a mock implementation of the Client interface for testing.
 type MockClient struct {
-	PostMessageFunc   func(channel, author, subject, text string, campaign model.Campaign) (string, string, error)
-	NotifyAuthorFunc  func(authorEmail, channelId, messageTimestamp, channelName string) error
-	DeleteMessageFunc func(channel, timestamp string) error
-	GetChannelIDFunc  func(channelName string) (string, error)
+	PostMessageFunc           func(ctx context.Context, channel, author, subject, text string, campaign model.Campaign) (string, string, error)
+	NotifyAuthorFunc          func(ctx context.Context, authorEmail, channelId, messageTimestamp, channelName string) error
+	NotifyAuthorOfFailureFunc func(ctx context.Context, authorEmail, destination, reason, retry string) error
+	RequestSignoffFunc        func(ctx context.Context, ownerEmail, callID, shortID string, pending []string) error
+	RequestApprovalFunc       func(ctx context.Context, approverEmail, callID, shortID, summary string) error
+	RequestConfirmationFunc   func(ctx context.Context, authorEmail, callID, shortID, preview, fallback string, at time.Time) error
+	UpdateMessageFunc         func(ctx context.Context, destination, timestamp, subject, text string) (string, string, error)
+	DeleteMessageFunc         func(ctx context.Context, channel, timestamp string) error
+	GetChannelIDFunc          func(ctx context.Context, channelName string) (string, error)
+	GetPermalinkFunc          func(ctx context.Context, channelID, timestamp string) (string, error)
+	GetUserTimezoneFunc       func(ctx context.Context, destination string) (string, error)
+	ValidateDestinationFunc   func(ctx context.Context, destination string) error
+	ListChannelsFunc          func(ctx context.Context) ([]Channel, error)
 
 	postMessageCalls []struct {
 		Destination string
@@ -21,23 +36,50 @@ type MockClient struct {
 // NewMockClient creates a new MockClient.
 func NewMockClient() *MockClient {
 	return &MockClient{
-		PostMessageFunc: func(channel, author, subject, text string, campaign model.Campaign) (string, string, error) {
+		PostMessageFunc: func(_ context.Context, channel, author, subject, text string, campaign model.Campaign) (string, string, error) {
 			return "C1234567890", "1234567890.123456", nil
 		},
-		NotifyAuthorFunc: func(authorEmail, channelId, messageTimestamp, channelName string) error {
+		NotifyAuthorFunc: func(_ context.Context, authorEmail, channelId, messageTimestamp, channelName string) error {
+			return nil
+		},
+		NotifyAuthorOfFailureFunc: func(_ context.Context, authorEmail, destination, reason, retry string) error {
+			return nil
+		},
+		RequestSignoffFunc: func(_ context.Context, ownerEmail, callID, shortID string, pending []string) error {
 			return nil
 		},
-		DeleteMessageFunc: func(channel, timestamp string) error {
+		RequestApprovalFunc: func(_ context.Context, approverEmail, callID, shortID, summary string) error {
 			return nil
 		},
-		GetChannelIDFunc: func(channelName string) (string, error) {
+		RequestConfirmationFunc: func(_ context.Context, authorEmail, callID, shortID, preview, fallback string, at time.Time) error {
+			return nil
+		},
+		UpdateMessageFunc: func(_ context.Context, destination, timestamp, subject, text string) (string, string, error) {
+			return "C1234567890", timestamp, nil
+		},
+		DeleteMessageFunc: func(_ context.Context, channel, timestamp string) error {
+			return nil
+		},
+		GetChannelIDFunc: func(_ context.Context, channelName string) (string, error) {
 			return "C1234567890", nil
 		},
+		GetPermalinkFunc: func(_ context.Context, channelID, timestamp string) (string, error) {
+			return "https://example.slack.com/archives/" + channelID + "/p" + strings.ReplaceAll(timestamp, ".", ""), nil
+		},
+		GetUserTimezoneFunc: func(_ context.Context, destination string) (string, error) {
+			return "", nil
+		},
+		ValidateDestinationFunc: func(_ context.Context, destination string) error {
+			return nil
+		},
+		ListChannelsFunc: func(_ context.Context) ([]Channel, error) {
+			return nil, nil
+		},
 	}
 }
 
 // PostMessage calls the PostMessageFunc.
-func (m *MockClient) PostMessage(destination, author, subject, text string, campaign model.Campaign) (string, string, error) {
+func (m *MockClient) PostMessage(ctx context.Context, destination, author, subject, text string, campaign model.Campaign) (string, string, error) {
 	m.postMessageCalls = append(m.postMessageCalls, struct {
 		Destination string
 		Author      string
@@ -45,22 +87,67 @@ func (m *MockClient) PostMessage(destination, author, subject, text string, camp
 		Text        string
 		Campaign    model.Campaign
 	}{destination, author, subject, text, campaign})
-	return m.PostMessageFunc(destination, author, subject, text, campaign)
+	return m.PostMessageFunc(ctx, destination, author, subject, text, campaign)
 }
 
 // NotifyAuthor calls the NotifyAuthorFunc.
-func (m *MockClient) NotifyAuthor(authorEmail, channelId, messageTimestamp, channelName string) error {
-	return m.NotifyAuthorFunc(authorEmail, channelId, messageTimestamp, channelName)
+func (m *MockClient) NotifyAuthor(ctx context.Context, authorEmail, channelId, messageTimestamp, channelName string) error {
+	return m.NotifyAuthorFunc(ctx, authorEmail, channelId, messageTimestamp, channelName)
+}
+
+// NotifyAuthorOfFailure calls the NotifyAuthorOfFailureFunc.
+func (m *MockClient) NotifyAuthorOfFailure(ctx context.Context, authorEmail, destination, reason, retry string) error {
+	return m.NotifyAuthorOfFailureFunc(ctx, authorEmail, destination, reason, retry)
+}
+
+// RequestSignoff calls the RequestSignoffFunc.
+func (m *MockClient) RequestSignoff(ctx context.Context, ownerEmail, callID, shortID string, pending []string) error {
+	return m.RequestSignoffFunc(ctx, ownerEmail, callID, shortID, pending)
+}
+
+// RequestApproval calls the RequestApprovalFunc.
+func (m *MockClient) RequestApproval(ctx context.Context, approverEmail, callID, shortID, summary string) error {
+	return m.RequestApprovalFunc(ctx, approverEmail, callID, shortID, summary)
+}
+
+// RequestConfirmation calls the RequestConfirmationFunc.
+func (m *MockClient) RequestConfirmation(ctx context.Context, authorEmail, callID, shortID, preview, fallback string, at time.Time) error {
+	return m.RequestConfirmationFunc(ctx, authorEmail, callID, shortID, preview, fallback, at)
+}
+
+// UpdateMessage calls the UpdateMessageFunc.
+func (m *MockClient) UpdateMessage(ctx context.Context, destination, timestamp, subject, text string) (string, string, error) {
+	return m.UpdateMessageFunc(ctx, destination, timestamp, subject, text)
 }
 
 // DeleteMessage calls the DeleteMessageFunc.
-func (m *MockClient) DeleteMessage(channel, timestamp string) error {
-	return m.DeleteMessageFunc(channel, timestamp)
+func (m *MockClient) DeleteMessage(ctx context.Context, channel, timestamp string) error {
+	return m.DeleteMessageFunc(ctx, channel, timestamp)
 }
 
 // GetChannelID calls the GetChannelIDFunc.
-func (m *MockClient) GetChannelID(channelName string) (string, error) {
-	return m.GetChannelIDFunc(channelName)
+func (m *MockClient) GetChannelID(ctx context.Context, channelName string) (string, error) {
+	return m.GetChannelIDFunc(ctx, channelName)
+}
+
+// GetPermalink calls the GetPermalinkFunc.
+func (m *MockClient) GetPermalink(ctx context.Context, channelID, timestamp string) (string, error) {
+	return m.GetPermalinkFunc(ctx, channelID, timestamp)
+}
+
+// GetUserTimezone calls the GetUserTimezoneFunc.
+func (m *MockClient) GetUserTimezone(ctx context.Context, destination string) (string, error) {
+	return m.GetUserTimezoneFunc(ctx, destination)
+}
+
+// ValidateDestination calls the ValidateDestinationFunc.
+func (m *MockClient) ValidateDestination(ctx context.Context, destination string) error {
+	return m.ValidateDestinationFunc(ctx, destination)
+}
+
+// ListChannels calls the ListChannelsFunc.
+func (m *MockClient) ListChannels(ctx context.Context) ([]Channel, error) {
+	return m.ListChannelsFunc(ctx)
 }
 
 // PostMessageCalls returns the recorded calls to PostMessage.
@@ -1,19 +1,58 @@
 package slack
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/andrewhowdencom/ruf/internal/model"
 	"github.com/slack-go/slack"
 )
 
+// ErrInvalidDestination is returned for a destination that cannot be sent to as it stands: a channel that
+// does not exist, has been archived or that the app is not a member of, or a user that does not exist.
+var ErrInvalidDestination = errors.New("invalid destination")
+
+// invalidDestinationErrors are the errors of the Slack API that mean the destination cannot be sent to.
+var invalidDestinationErrors = []string{"channel_not_found", "is_archived", "not_in_channel", "users_not_found", "user_not_found"}
+
+// The action IDs of the buttons of an approval request, whose value is the ID of the call.
+const (
+	ActionApprove = "ruf_approve"
+	ActionReject  = "ruf_reject"
+)
+
+// The action IDs of the buttons of a confirmation request, whose value is the ID of the call.
+const (
+	ActionConfirm = "ruf_confirm"
+	ActionCancel  = "ruf_cancel"
+)
+
 // Client is an interface that defines the methods for interacting with the Slack API.
 type Client interface {
-	PostMessage(destination, author, subject, text string, campaign model.Campaign) (string, string, error)
-	NotifyAuthor(authorEmail, channelId, messageTimestamp, channelName string) error
-	DeleteMessage(channel, timestamp string) error
-	GetChannelID(destination string) (string, error)
+	PostMessage(ctx context.Context, destination, author, subject, text string, campaign model.Campaign) (string, string, error)
+	NotifyAuthor(ctx context.Context, authorEmail, channelId, messageTimestamp, channelName string) error
+	NotifyAuthorOfFailure(ctx context.Context, authorEmail, destination, reason, retry string) error
+	RequestSignoff(ctx context.Context, ownerEmail, callID, shortID string, pending []string) error
+	RequestApproval(ctx context.Context, approverEmail, callID, shortID, summary string) error
+	RequestConfirmation(ctx context.Context, authorEmail, callID, shortID, preview, fallback string, at time.Time) error
+	UpdateMessage(ctx context.Context, destination, timestamp, subject, text string) (string, string, error)
+	DeleteMessage(ctx context.Context, channel, timestamp string) error
+	GetChannelID(ctx context.Context, destination string) (string, error)
+	GetPermalink(ctx context.Context, channelID, timestamp string) (string, error)
+	GetUserTimezone(ctx context.Context, destination string) (string, error)
+	ValidateDestination(ctx context.Context, destination string) error
+	ListChannels(ctx context.Context) ([]Channel, error)
+}
+
+// Channel is a channel that the app is a member of, and so can post to.
+type Channel struct {
+	ID      string
+	Name    string
+	Private bool
 }
 
 // client is the concrete implementation of the Client interface.
@@ -21,19 +60,36 @@ type client struct {
 	api *slack.Client
 }
 
+// Option configures the client.
+type Option func(*options)
+
+type options struct {
+	api      []slack.Option
+	endpoint string
+}
+
+// WithEndpoint overrides the base URL of the Slack API.
+func WithEndpoint(endpoint string) Option {
+	return func(o *options) {
+		o.endpoint = strings.TrimSuffix(endpoint, "/") + "/"
+		o.api = append(o.api, slack.OptionAPIURL(o.endpoint))
+	}
+}
+
 // NewClient creates a new Slack client.
-func NewClient(token string) Client {
+func NewClient(token string, opts ...Option) Client {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
 	return &client{
-		api: slack.New(token),
+		api: slack.New(token, o.api...),
 	}
 }
 
 // PostMessage sends a message to a Slack destination.
-func (c *client) PostMessage(destination, author, subject, text string, campaign model.Campaign) (string, string, error) {
-	message := text
-	if subject != "" {
-		message = fmt.Sprintf("*%s*\n%s", subject, text)
-	}
+func (c *client) PostMessage(ctx context.Context, destination, author, subject, text string, campaign model.Campaign) (string, string, error) {
+	message := Text(subject, text)
 
 	// Default message options.
 	options := []slack.MsgOption{
@@ -42,7 +98,7 @@ func (c *client) PostMessage(destination, author, subject, text string, campaign
 
 	// If an author is specified, try to use their profile for the message.
 	if author != "" {
-		user, err := c.api.GetUserByEmail(author)
+		user, err := c.api.GetUserByEmailContext(ctx, author)
 		if err == nil && user != nil {
 			// User found, customize username and icon.
 			username := user.RealName
@@ -71,36 +127,65 @@ func (c *client) PostMessage(destination, author, subject, text string, campaign
 		}
 	}
 
-	channelID, err := c.GetChannelID(destination)
+	channelID, err := c.GetChannelID(ctx, destination)
 	if err != nil {
 		return "", "", fmt.Errorf("failed to get channel id for '%s': %w", destination, err)
 	}
 
 	// Post the message with the specified options.
-	_, timestamp, err := c.api.PostMessage(channelID, options...)
+	_, timestamp, err := c.api.PostMessageContext(ctx, channelID, options...)
 	if err != nil {
-		return "", "", fmt.Errorf("failed to post message: %w", err)
+		return "", "", fmt.Errorf("failed to post message: %w", invalidDestination(err))
 	}
 	return channelID, timestamp, nil
 }
 
-// NotifyAuthor sends a direct message to the author of a message with a permalink to the original message.
-func (c *client) NotifyAuthor(authorEmail, channelId, messageTimestamp, channelName string) error {
-	user, err := c.api.GetUserByEmail(authorEmail)
+// UpdateMessage replaces the text of a message that has been posted to a Slack destination. The
+// author and icon of the message are kept as they were posted, as Slack does not allow them to change.
+func (c *client) UpdateMessage(ctx context.Context, destination, timestamp, subject, text string) (string, string, error) {
+	channelID, err := c.GetChannelID(ctx, destination)
 	if err != nil {
-		return fmt.Errorf("failed to get user by email: %w", err)
+		return "", "", fmt.Errorf("failed to get channel id for '%s': %w", destination, err)
 	}
-
-	// Open a direct message channel with the user.
-	im, _, _, err := c.api.OpenConversation(&slack.OpenConversationParameters{
-		Users: []string{user.ID},
-	})
+	_, timestamp, _, err = c.api.UpdateMessageContext(ctx, channelID, timestamp, slack.MsgOptionText(Text(subject, text), false))
 	if err != nil {
-		return fmt.Errorf("failed to open conversation: %w", err)
+		return "", "", fmt.Errorf("failed to update message: %w", err)
+	}
+	return channelID, timestamp, nil
+}
+
+// RetryAfter reports how long Slack asked the client to wait before trying again, when an error is
+// because the client was rate limited.
+func RetryAfter(err error) (time.Duration, bool) {
+	var rle *slack.RateLimitedError
+	if errors.As(err, &rle) {
+		return rle.RetryAfter, true
+	}
+	return 0, false
+}
+
+// invalidDestination wraps an error of the Slack API with ErrInvalidDestination when it means the
+// destination cannot be sent to.
+func invalidDestination(err error) error {
+	var resp slack.SlackErrorResponse
+	if errors.As(err, &resp) && slices.Contains(invalidDestinationErrors, resp.Err) {
+		return fmt.Errorf("%w: %w", ErrInvalidDestination, err)
+	}
+	return err
+}
+
+// Text returns the text of a message, with its subject in bold above it.
+func Text(subject, text string) string {
+	if subject != "" {
+		return fmt.Sprintf("*%s*\n%s", subject, text)
 	}
+	return text
+}
 
+// NotifyAuthor sends a direct message to the author of a message with a permalink to the original message.
+func (c *client) NotifyAuthor(ctx context.Context, authorEmail, channelId, messageTimestamp, channelName string) error {
 	// Get the permalink for the original message.
-	permalink, err := c.api.GetPermalink(&slack.PermalinkParameters{
+	permalink, err := c.api.GetPermalinkContext(ctx, &slack.PermalinkParameters{
 		Channel: channelId,
 		Ts:      messageTimestamp,
 	})
@@ -108,8 +193,72 @@ func (c *client) NotifyAuthor(authorEmail, channelId, messageTimestamp, channelN
 		return fmt.Errorf("failed to get permalink: %w", err)
 	}
 
+	return c.directMessage(ctx, authorEmail, fmt.Sprintf("I have just sent your message to %s. You can view it here: %s", channelName, permalink))
+}
+
+// NotifyAuthorOfFailure sends a direct message to the author of a message that could not be sent, with the
+// reason and the command that retries it.
+func (c *client) NotifyAuthorOfFailure(ctx context.Context, authorEmail, destination, reason, retry string) error {
+	return c.directMessage(ctx, authorEmail, fmt.Sprintf("I could not send your message to %s: %s\nTo try again, run: `%s`", destination, reason, retry))
+}
+
+// RequestSignoff sends a direct message to an owner of a campaign asking them to sign off the items of the
+// checklist of a call that is due, but is held until they are signed off.
+func (c *client) RequestSignoff(ctx context.Context, ownerEmail, callID, shortID string, pending []string) error {
+	return c.directMessage(ctx, ownerEmail, fmt.Sprintf("The call %s is due, but waits for sign-off of: %s\nTo sign off an item, run: `ruf checklist check %s <item>`", callID, strings.Join(pending, ", "), shortID))
+}
+
+// RequestApproval asks an approver to approve a call, with buttons that approve or reject it when the
+// Slack app handles interactions, and the commands that do so otherwise.
+func (c *client) RequestApproval(ctx context.Context, approverEmail, callID, shortID, summary string) error {
+	text := fmt.Sprintf("The call %s is due, but waits for approval:\n>%s\nTo approve it, run: `ruf approvals approve %s`, or to reject it: `ruf approvals reject %s`", callID, summary, shortID, shortID)
+	return c.directMessage(ctx, approverEmail, text, slack.MsgOptionBlocks(
+		slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType, text, false, false), nil, nil),
+		slack.NewActionBlock("approval",
+			slack.NewButtonBlockElement(ActionApprove, callID, slack.NewTextBlockObject(slack.PlainTextType, "Approve", false, false)).WithStyle(slack.StylePrimary),
+			slack.NewButtonBlockElement(ActionReject, callID, slack.NewTextBlockObject(slack.PlainTextType, "Reject", false, false)).WithStyle(slack.StyleDanger),
+		),
+	))
+}
+
+// RequestConfirmation sends the author of a call a preview of it before it is due at the given time, with
+// buttons that confirm or cancel it when the Slack app handles interactions, and the command that cancels
+// it otherwise. The fallback is what happens if the author does not answer, model.ConfirmSend or
+// model.ConfirmCancel.
+func (c *client) RequestConfirmation(ctx context.Context, authorEmail, callID, shortID, preview, fallback string, at time.Time) error {
+	unanswered := "it will be sent as it is"
+	if fallback == model.ConfirmCancel {
+		unanswered = "it will be cancelled"
+	}
+	text := fmt.Sprintf("Your call %s will be sent at %s:\n>%s\nIf you do not answer by then, %s. To cancel it, run: `ruf sent cancel %s`",
+		callID, at.Format(time.RFC1123), strings.ReplaceAll(preview, "\n", "\n>"), unanswered, shortID)
+	return c.directMessage(ctx, authorEmail, text, slack.MsgOptionBlocks(
+		slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType, text, false, false), nil, nil),
+		slack.NewActionBlock("confirmation",
+			slack.NewButtonBlockElement(ActionConfirm, callID, slack.NewTextBlockObject(slack.PlainTextType, "Send", false, false)).WithStyle(slack.StylePrimary),
+			slack.NewButtonBlockElement(ActionCancel, callID, slack.NewTextBlockObject(slack.PlainTextType, "Cancel", false, false)).WithStyle(slack.StyleDanger),
+		),
+	))
+}
+
+// directMessage sends a direct message to the user with the given email address. The text is shown in
+// notifications, and in place of any blocks given with the options.
+func (c *client) directMessage(ctx context.Context, email, text string, opts ...slack.MsgOption) error {
+	user, err := c.api.GetUserByEmailContext(ctx, email)
+	if err != nil {
+		return fmt.Errorf("failed to get user by email: %w", err)
+	}
+
+	// Open a direct message channel with the user.
+	im, _, _, err := c.api.OpenConversationContext(ctx, &slack.OpenConversationParameters{
+		Users: []string{user.ID},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to open conversation: %w", err)
+	}
+
 	// Send the direct message.
-	_, _, err = c.api.PostMessage(im.ID, slack.MsgOptionText(fmt.Sprintf("I have just sent your message to %s. You can view it here: %s", channelName, permalink), false))
+	_, _, err = c.api.PostMessageContext(ctx, im.ID, append([]slack.MsgOption{slack.MsgOptionText(text, false)}, opts...)...)
 	if err != nil {
 		return fmt.Errorf("failed to post message: %w", err)
 	}
@@ -117,13 +266,25 @@ func (c *client) NotifyAuthor(authorEmail, channelId, messageTimestamp, channelN
 	return nil
 }
 
+// GetPermalink returns a link to a message that has been posted.
+func (c *client) GetPermalink(ctx context.Context, channelID, timestamp string) (string, error) {
+	permalink, err := c.api.GetPermalinkContext(ctx, &slack.PermalinkParameters{
+		Channel: channelID,
+		Ts:      timestamp,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to get permalink: %w", err)
+	}
+	return permalink, nil
+}
+
 // DeleteMessage deletes a message from a Slack channel.
-func (c *client) DeleteMessage(channel, timestamp string) error {
-	channelID, err := c.GetChannelID(channel)
+func (c *client) DeleteMessage(ctx context.Context, channel, timestamp string) error {
+	channelID, err := c.GetChannelID(ctx, channel)
 	if err != nil {
 		return fmt.Errorf("failed to get channel id: %w", err)
 	}
-	_, _, err = c.api.DeleteMessage(channelID, timestamp)
+	_, _, err = c.api.DeleteMessageContext(ctx, channelID, timestamp)
 	if err != nil {
 		return fmt.Errorf("failed to delete message: %w", err)
 	}
@@ -134,52 +295,155 @@ func (c *client) DeleteMessage(channel, timestamp string) error {
 // The destination can be a public channel ("#general"), a user email ("user@example.com"),
 // or a user handle ("@username"). If the destination does not match these formats,
 // it is assumed to be a raw channel/conversation ID.
-func (c *client) GetChannelID(destination string) (string, error) {
+func (c *client) GetChannelID(ctx context.Context, destination string) (string, error) {
 	// Handle public/private channel names
 	if strings.HasPrefix(destination, "#") {
-		var channels []slack.Channel
-		params := &slack.GetConversationsParameters{
-			Limit: 1000,
-			Types: []string{"public_channel", "private_channel"},
+		channel, err := c.findChannel(ctx, destination)
+		if err != nil {
+			return "", err
 		}
-		for {
-			page, nextCursor, err := c.api.GetConversations(params)
-			if err != nil {
-				return "", fmt.Errorf("failed to get conversations: %w", err)
-			}
-			channels = append(channels, page...)
-			if nextCursor == "" {
-				break
-			}
-			params.Cursor = nextCursor
+		return channel.ID, nil
+	}
+
+	user, err := c.lookupUser(ctx, destination)
+	if err != nil {
+		return "", err
+	}
+
+	// If we found a user by email or username, open a DM channel with them.
+	if user != nil {
+		im, _, _, err := c.api.OpenConversationContext(ctx, &slack.OpenConversationParameters{
+			Users: []string{user.ID},
+		})
+		if err != nil {
+			return "", fmt.Errorf("failed to open conversation with user '%s': %w", destination, err)
 		}
+		return im.ID, nil
+	}
 
-		// Normalize channel name for case-insensitive comparison.
-		normalizedChannelName := strings.TrimPrefix(strings.ToLower(destination), "#")
+	// Otherwise, assume it's a raw ID and return it.
+	return destination, nil
+}
 
-		for _, channel := range channels {
-			if strings.ToLower(channel.Name) == normalizedChannelName {
-				return channel.ID, nil
-			}
+// findChannel finds the public or private channel a destination such as "#general" names.
+func (c *client) findChannel(ctx context.Context, destination string) (*slack.Channel, error) {
+	channels, err := c.conversations(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Normalize channel name for case-insensitive comparison.
+	normalizedChannelName := strings.TrimPrefix(strings.ToLower(destination), "#")
+
+	for i := range channels {
+		if strings.ToLower(channels[i].Name) == normalizedChannelName {
+			return &channels[i], nil
 		}
+	}
 
-		return "", fmt.Errorf("channel '%s' not found", destination)
+	return nil, fmt.Errorf("%w: channel '%s' not found", ErrInvalidDestination, destination)
+}
+
+// conversations lists every public and private channel the app can see.
+func (c *client) conversations(ctx context.Context) ([]slack.Channel, error) {
+	var channels []slack.Channel
+	params := &slack.GetConversationsParameters{
+		Limit: 1000,
+		Types: []string{"public_channel", "private_channel"},
+	}
+	for {
+		page, nextCursor, err := c.api.GetConversationsContext(ctx, params)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get conversations: %w", err)
+		}
+		channels = append(channels, page...)
+		if nextCursor == "" {
+			return channels, nil
+		}
+		params.Cursor = nextCursor
 	}
+}
 
+// ListChannels lists the channels that the app is a member of and that are not archived, which are
+// those calls can be sent to, ordered by name.
+func (c *client) ListChannels(ctx context.Context) ([]Channel, error) {
+	conversations, err := c.conversations(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var channels []Channel
+	for _, ch := range conversations {
+		if ch.IsMember && !ch.IsArchived {
+			channels = append(channels, Channel{ID: ch.ID, Name: ch.Name, Private: ch.IsPrivate})
+		}
+	}
+	slices.SortFunc(channels, func(a, b Channel) int { return strings.Compare(a.Name, b.Name) })
+	return channels, nil
+}
+
+// ValidateDestination checks that a message can be sent to a destination before it is sent: that a
+// channel exists, is not archived and has the app as a member, and that a user exists. It returns an
+// error wrapping ErrInvalidDestination when it cannot be sent to, and other errors when the check itself
+// could not be made. Conversation IDs other than those of channels, such as direct messages, are not
+// checked.
+func (c *client) ValidateDestination(ctx context.Context, destination string) error {
+	var channel *slack.Channel
+	switch {
+	case strings.HasPrefix(destination, "#"):
+		var err error
+		if channel, err = c.findChannel(ctx, destination); err != nil {
+			return err
+		}
+	case strings.Contains(destination, "@"):
+		_, err := c.lookupUser(ctx, destination)
+		return err
+	case strings.HasPrefix(destination, "C"), strings.HasPrefix(destination, "G"):
+		var err error
+		channel, err = c.api.GetConversationInfoContext(ctx, &slack.GetConversationInfoInput{ChannelID: destination})
+		if err != nil {
+			return fmt.Errorf("failed to get conversation '%s': %w", destination, invalidDestination(err))
+		}
+	default:
+		return nil
+	}
+
+	if channel.IsArchived {
+		return fmt.Errorf("%w: channel '%s' is archived", ErrInvalidDestination, destination)
+	}
+	if !channel.IsMember {
+		return fmt.Errorf("%w: the app is not a member of channel '%s'", ErrInvalidDestination, destination)
+	}
+	return nil
+}
+
+// GetUserTimezone returns the IANA timezone, such as "Asia/Tokyo", that a user has set in their Slack
+// profile. The destination is a user email ("user@example.com") or handle ("@username"); channels have
+// no timezone, so an empty timezone is returned for them.
+func (c *client) GetUserTimezone(ctx context.Context, destination string) (string, error) {
+	user, err := c.lookupUser(ctx, destination)
+	if err != nil || user == nil {
+		return "", err
+	}
+	return user.TZ, nil
+}
+
+// lookupUser finds the user a destination names by email or handle. It returns no user for other
+// destinations, such as channels.
+func (c *client) lookupUser(ctx context.Context, destination string) (*slack.User, error) {
 	var user *slack.User
 	var err error
 
 	// Handle emails for DMs
 	if strings.Contains(destination, "@") && !strings.HasPrefix(destination, "@") {
-		user, err = c.api.GetUserByEmail(destination)
+		user, err = c.api.GetUserByEmailContext(ctx, destination)
 		if err != nil {
-			return "", fmt.Errorf("failed to get user by email '%s': %w", destination, err)
+			return nil, fmt.Errorf("failed to get user by email '%s': %w", destination, invalidDestination(err))
 		}
 	} else if strings.HasPrefix(destination, "@") {
 		// Handle usernames for DMs (this is inefficient, but the only way)
-		users, err := c.api.GetUsers()
+		users, err := c.api.GetUsersContext(ctx)
 		if err != nil {
-			return "", fmt.Errorf("failed to list users: %w", err)
+			return nil, fmt.Errorf("failed to list users: %w", err)
 		}
 
 		userName := strings.TrimPrefix(destination, "@")
@@ -192,22 +456,8 @@ func (c *client) GetChannelID(destination string) (string, error) {
 			}
 		}
 		if !found {
-			return "", fmt.Errorf("user '%s' not found", destination)
-		}
-	}
-
-	// If we found a user by email or username, open a DM channel with them.
-	if user != nil {
-		im, _, _, err := c.api.OpenConversation(&slack.OpenConversationParameters{
-			Users: []string{user.ID},
-		})
-		if err != nil {
-			return "", fmt.Errorf("failed to open conversation with user '%s': %w", destination, err)
+			return nil, fmt.Errorf("%w: user '%s' not found", ErrInvalidDestination, destination)
 		}
-		return im.ID, nil
 	}
-
-	// Otherwise, assume it's a raw ID and return it.
-	return destination, nil
+	return user, nil
 }
-
@@ -1,6 +1,11 @@
 package slack
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 )
 
@@ -10,7 +15,7 @@ func TestGetChannelID(t *testing.T) {
 	c := NewClient("").(*client)
 
 	t.Run("should return the channel ID if it is not prefixed with a #", func(t *testing.T) {
-		channelID, err := c.GetChannelID("C1234567890")
+		channelID, err := c.GetChannelID(context.Background(), "C1234567890")
 		if err != nil {
 			t.Errorf("expected no error, got %v", err)
 		}
@@ -23,9 +28,105 @@ func TestGetChannelID(t *testing.T) {
 		// This will fail because we are not using a real token.
 		// However, we can assert that an error is returned, which proves that
 		// the code is attempting to make an API call.
-		_, err := c.GetChannelID("#random")
+		_, err := c.GetChannelID(context.Background(), "#random")
 		if err == nil {
 			t.Errorf("expected an error, got nil")
 		}
 	})
 }
+
+func TestValidateDestination(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/conversations.list", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"ok": true, "channels": [
+			{"id": "C1", "name": "general", "is_member": true},
+			{"id": "C2", "name": "old", "is_member": true, "is_archived": true},
+			{"id": "C3", "name": "private"}
+		], "response_metadata": {"next_cursor": ""}}`)
+	})
+	mux.HandleFunc("/conversations.info", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"ok": false, "error": "channel_not_found"}`)
+	})
+	mux.HandleFunc("/users.lookupByEmail", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"ok": false, "error": "users_not_found"}`)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	c := NewClient("token", WithEndpoint(server.URL))
+
+	tests := []struct {
+		destination string
+		invalid     bool
+	}{
+		{"#general", false},
+		{"#old", true},
+		{"#private", true},
+		{"#missing", true},
+		{"C9", true},
+		{"jane@example.com", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.destination, func(t *testing.T) {
+			err := c.ValidateDestination(context.Background(), tt.destination)
+			if got := errors.Is(err, ErrInvalidDestination); got != tt.invalid {
+				t.Errorf("expected invalid to be %v, got error %v", tt.invalid, err)
+			}
+		})
+	}
+}
+
+func TestAuthTest(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/auth.test" {
+			http.NotFound(w, r)
+			return
+		}
+		if r.FormValue("token") != "token" {
+			fmt.Fprint(w, `{"ok": false, "error": "invalid_auth"}`)
+			return
+		}
+		w.Header().Set("X-OAuth-Scopes", "channels:read, chat:write,im:write")
+		fmt.Fprint(w, `{"ok": true, "team": "Example", "user": "ruf"}`)
+	}))
+	defer server.Close()
+
+	auth, err := AuthTest(context.Background(), "token", WithEndpoint(server.URL))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if auth.Team != "Example" || auth.User != "ruf" {
+		t.Errorf("expected to authenticate as ruf in Example, got %s in %s", auth.User, auth.Team)
+	}
+	if missing := fmt.Sprint(auth.Missing()); missing != "[groups:read users:read.email]" {
+		t.Errorf("expected groups:read and users:read.email to be missing, got %s", missing)
+	}
+
+	if _, err := AuthTest(context.Background(), "wrong", WithEndpoint(server.URL)); err == nil {
+		t.Errorf("expected an error for an invalid token, got nil")
+	}
+}
+
+func TestListChannels(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"ok": true, "channels": [
+			{"id": "C2", "name": "random", "is_member": true},
+			{"id": "C1", "name": "general", "is_member": true, "is_private": true},
+			{"id": "C3", "name": "old", "is_member": true, "is_archived": true},
+			{"id": "C4", "name": "elsewhere"}
+		], "response_metadata": {"next_cursor": ""}}`)
+	}))
+	defer server.Close()
+
+	channels, err := NewClient("token", WithEndpoint(server.URL)).ListChannels(context.Background())
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	want := []Channel{{ID: "C1", Name: "general", Private: true}, {ID: "C2", Name: "random"}}
+	if fmt.Sprint(channels) != fmt.Sprint(want) {
+		t.Errorf("expected channels %v, got %v", want, channels)
+	}
+}
@@ -0,0 +1,51 @@
+package slackworkflow
+
+import "github.com/andrewhowdencom/ruf/internal/model"
+
+// MockClient is a mock implementation of the Client interface for testing.
+type MockClient struct {
+	TriggerFunc func(to, author, subject, body string, campaign model.Campaign, data map[string]interface{}) (string, error)
+
+	triggerCalls []struct {
+		To       string
+		Author   string
+		Subject  string
+		Body     string
+		Campaign model.Campaign
+		Data     map[string]interface{}
+	}
+}
+
+// NewMockClient creates a new MockClient.
+func NewMockClient() *MockClient {
+	return &MockClient{
+		TriggerFunc: func(to, author, subject, body string, campaign model.Campaign, data map[string]interface{}) (string, error) {
+			return "", nil
+		},
+	}
+}
+
+// Trigger calls the TriggerFunc.
+func (m *MockClient) Trigger(to, author, subject, body string, campaign model.Campaign, data map[string]interface{}) (string, error) {
+	m.triggerCalls = append(m.triggerCalls, struct {
+		To       string
+		Author   string
+		Subject  string
+		Body     string
+		Campaign model.Campaign
+		Data     map[string]interface{}
+	}{to, author, subject, body, campaign, data})
+	return m.TriggerFunc(to, author, subject, body, campaign, data)
+}
+
+// TriggerCalls returns the recorded calls to Trigger.
+func (m *MockClient) TriggerCalls() []struct {
+	To       string
+	Author   string
+	Subject  string
+	Body     string
+	Campaign model.Campaign
+	Data     map[string]interface{}
+} {
+	return m.triggerCalls
+}
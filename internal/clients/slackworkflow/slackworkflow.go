@@ -0,0 +1,153 @@
+// Package slackworkflow sends calls to Slack workflows built with Workflow Builder, by starting them
+// through their webhook triggers, so that schedules can feed the automations teams already have.
+package slackworkflow
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"text/template"
+
+	rufhttp "github.com/andrewhowdencom/ruf/internal/http"
+	"github.com/andrewhowdencom/ruf/internal/model"
+)
+
+// DefaultInputs are the inputs sent to a workflow that has none configured. The webhook trigger of the
+// workflow must declare each of them as a variable.
+var DefaultInputs = map[string]string{
+	"subject":  "{{ .Subject }}",
+	"content":  "{{ .Body }}",
+	"author":   "{{ .Author }}",
+	"campaign": "{{ .Campaign.Name }}",
+}
+
+// Err* are common errors returned by the Slack workflow client.
+var (
+	ErrInvalidConfig   = errors.New("invalid slack workflow configuration")
+	ErrUnknownWorkflow = errors.New("unknown slack workflow")
+	ErrTriggerFailed   = errors.New("failed to trigger slack workflow")
+)
+
+// Workflow is a Slack workflow that calls can start.
+type Workflow struct {
+	// URL is the address of the webhook trigger of the workflow.
+	URL string
+	// Inputs are the Go templates of the variables sent to the workflow, by name.
+	Inputs map[string]string
+}
+
+// Payload is the data the input templates are executed with.
+type Payload struct {
+	Author  string
+	Subject string
+	Body    string
+	// Campaign is the campaign of the call.
+	Campaign model.Campaign
+	// Data is the data the call was rendered with.
+	Data map[string]interface{}
+}
+
+// Client is an interface that defines the methods for starting Slack workflows.
+type Client interface {
+	// Trigger starts the workflow named by to with the inputs rendered from the call. The returned ID
+	// is always empty, as webhook triggers do not identify the run they start.
+	Trigger(to, author, subject, body string, campaign model.Campaign, data map[string]interface{}) (string, error)
+}
+
+// client is the concrete implementation of the Client interface.
+type client struct {
+	workflows  map[string]workflow
+	httpClient *http.Client
+}
+
+type workflow struct {
+	url    string
+	inputs map[string]*template.Template
+}
+
+// Option configures the client.
+type Option func(*client)
+
+// WithHTTPClient overrides the HTTP client used to trigger workflows.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *client) {
+		c.httpClient = httpClient
+	}
+}
+
+// NewClient creates a client of the workflows, by the name destinations address them with, and parses
+// their input templates.
+func NewClient(workflows map[string]Workflow, opts ...Option) (Client, error) {
+	c := &client{
+		workflows:  make(map[string]workflow, len(workflows)),
+		httpClient: rufhttp.NewClient(),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	for name, w := range workflows {
+		if w.URL == "" {
+			return nil, fmt.Errorf("%w: %s: a url is required", ErrInvalidConfig, name)
+		}
+		inputs := w.Inputs
+		if len(inputs) == 0 {
+			inputs = DefaultInputs
+		}
+		parsed := workflow{url: w.URL, inputs: make(map[string]*template.Template, len(inputs))}
+		for input, tmpl := range inputs {
+			t, err := template.New(input).Parse(tmpl)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %s: input %s: %w", ErrInvalidConfig, name, input, err)
+			}
+			parsed.inputs[input] = t
+		}
+		c.workflows[name] = parsed
+	}
+	return c, nil
+}
+
+// Trigger renders the inputs of the workflow and posts them to its webhook trigger.
+func (c *client) Trigger(to, author, subject, body string, campaign model.Campaign, data map[string]interface{}) (string, error) {
+	w, ok := c.workflows[to]
+	if !ok {
+		return "", fmt.Errorf("%w: %s: configure it under slack.workflows", ErrUnknownWorkflow, to)
+	}
+	p := Payload{Author: author, Subject: subject, Body: body, Campaign: campaign, Data: data}
+
+	// Slack requires every variable of a webhook trigger to be a string.
+	inputs := make(map[string]string, len(w.inputs))
+	names := make([]string, 0, len(w.inputs))
+	for name := range w.inputs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		var value bytes.Buffer
+		if err := w.inputs[name].Execute(&value, p); err != nil {
+			return "", fmt.Errorf("%w: %s: input %s: %w", ErrTriggerFailed, to, name, err)
+		}
+		inputs[name] = value.String()
+	}
+	payload, err := json.Marshal(inputs)
+	if err != nil {
+		return "", fmt.Errorf("%w: %s: %w", ErrTriggerFailed, to, err)
+	}
+
+	resp, err := c.httpClient.Post(w.url, "application/json", bytes.NewReader(payload))
+	if err != nil {
+		return "", fmt.Errorf("%w: %s: %w", ErrTriggerFailed, to, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return "", fmt.Errorf("%w: %s: status code %d: %s", ErrTriggerFailed, to, resp.StatusCode, strings.TrimSpace(string(respBody)))
+	}
+	return "", nil
+}
@@ -0,0 +1,73 @@
+package slackworkflow_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/andrewhowdencom/ruf/internal/clients/slackworkflow"
+	"github.com/andrewhowdencom/ruf/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClient_Trigger(t *testing.T) {
+	t.Run("sends the default inputs", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+			body, err := io.ReadAll(r.Body)
+			assert.NoError(t, err)
+			assert.JSONEq(t, `{"subject": "Release", "content": "Version 1.2.0 is out.", "author": "jane@example.com", "campaign": "Releases"}`, string(body))
+			w.Write([]byte(`{"ok": true}`))
+		}))
+		defer server.Close()
+
+		client, err := slackworkflow.NewClient(map[string]slackworkflow.Workflow{"release": {URL: server.URL}}, slackworkflow.WithHTTPClient(server.Client()))
+		assert.NoError(t, err)
+		_, err = client.Trigger("release", "jane@example.com", "Release", "Version 1.2.0 is out.", model.Campaign{Name: "Releases"}, nil)
+		assert.NoError(t, err)
+	})
+
+	t.Run("renders the input templates of the workflow", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, err := io.ReadAll(r.Body)
+			assert.NoError(t, err)
+			assert.JSONEq(t, `{"title": "Release 1.2.0", "notes": "Version 1.2.0 is out."}`, string(body))
+		}))
+		defer server.Close()
+
+		client, err := slackworkflow.NewClient(map[string]slackworkflow.Workflow{"release": {
+			URL:    server.URL,
+			Inputs: map[string]string{"title": "{{ .Subject }} {{ .Data.version }}", "notes": "{{ .Body }}"},
+		}}, slackworkflow.WithHTTPClient(server.Client()))
+		assert.NoError(t, err)
+		_, err = client.Trigger("release", "", "Release", "Version 1.2.0 is out.", model.Campaign{}, map[string]interface{}{"version": "1.2.0"})
+		assert.NoError(t, err)
+	})
+
+	t.Run("returns the error reported by slack", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"ok": false, "error": "invalid_workflow_input"}`))
+		}))
+		defer server.Close()
+
+		client, err := slackworkflow.NewClient(map[string]slackworkflow.Workflow{"release": {URL: server.URL}}, slackworkflow.WithHTTPClient(server.Client()))
+		assert.NoError(t, err)
+		_, err = client.Trigger("release", "", "", "", model.Campaign{}, nil)
+		assert.ErrorIs(t, err, slackworkflow.ErrTriggerFailed)
+		assert.Contains(t, err.Error(), "invalid_workflow_input")
+	})
+
+	t.Run("rejects unknown workflows and invalid configuration", func(t *testing.T) {
+		client, err := slackworkflow.NewClient(nil)
+		assert.NoError(t, err)
+		_, err = client.Trigger("missing", "", "", "", model.Campaign{}, nil)
+		assert.ErrorIs(t, err, slackworkflow.ErrUnknownWorkflow)
+
+		_, err = slackworkflow.NewClient(map[string]slackworkflow.Workflow{"release": {}})
+		assert.ErrorIs(t, err, slackworkflow.ErrInvalidConfig)
+		_, err = slackworkflow.NewClient(map[string]slackworkflow.Workflow{"release": {URL: "https://example.com", Inputs: map[string]string{"title": "{{ .Subject"}}})
+		assert.ErrorIs(t, err, slackworkflow.ErrInvalidConfig)
+	})
+}
@@ -0,0 +1,37 @@
+package webhook
+
+import "github.com/andrewhowdencom/ruf/internal/model"
+
+// MockClient is a mock implementation of the Client interface for testing.
+type MockClient struct {
+	PostFunc func(to, author, subject, body string, campaign model.Campaign) (string, error)
+	format   string
+
+	postCalls []Payload
+}
+
+// NewMockClient creates a new MockClient that converts content to the given format.
+func NewMockClient(format string) *MockClient {
+	return &MockClient{
+		PostFunc: func(to, author, subject, body string, campaign model.Campaign) (string, error) {
+			return "", nil
+		},
+		format: format,
+	}
+}
+
+// Post calls the PostFunc.
+func (m *MockClient) Post(to, author, subject, body string, campaign model.Campaign) (string, error) {
+	m.postCalls = append(m.postCalls, Payload{To: to, Author: author, Subject: subject, Body: body, Campaign: campaign})
+	return m.PostFunc(to, author, subject, body, campaign)
+}
+
+// Format returns the format the mock was created with.
+func (m *MockClient) Format() string {
+	return m.format
+}
+
+// PostCalls returns the recorded calls to Post.
+func (m *MockClient) PostCalls() []Payload {
+	return m.postCalls
+}
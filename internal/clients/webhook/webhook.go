@@ -0,0 +1,181 @@
+// Package webhook sends calls to destination types that are defined in configuration, by posting a
+// payload rendered from a template to a URL. It lets simple integrations be added without a native
+// client.
+package webhook
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"text/template"
+
+	rufhttp "github.com/andrewhowdencom/ruf/internal/http"
+	"github.com/andrewhowdencom/ruf/internal/model"
+)
+
+// Err* are common errors returned by the webhook client.
+var (
+	ErrInvalidType = errors.New("invalid webhook type")
+	ErrPostFailed  = errors.New("failed to post webhook")
+)
+
+// Formats the content of a call is converted to before it is rendered into the payload.
+const (
+	FormatText  = "text"
+	FormatHTML  = "html"
+	FormatSlack = "slack"
+)
+
+// DefaultPayloadTemplate is the payload posted when a type does not define one.
+const DefaultPayloadTemplate = `{"to": {{ json .To }}, "author": {{ json .Author }}, "subject": {{ json .Subject }}, "text": {{ json .Body }}, "campaign": {{ json .Campaign.Name }}}`
+
+// Type is the definition of a destination type.
+type Type struct {
+	// URL is where the payload is posted. It is a template, executed with the same data as the
+	// payload, so that the recipient can be part of it.
+	URL string
+	// Method is the HTTP method of the request. It defaults to POST.
+	Method string
+	// Headers are added to the request.
+	Headers map[string]string
+	// ContentType is the content type of the payload. It defaults to application/json.
+	ContentType string
+	// PayloadTemplate renders the body of the request. It defaults to DefaultPayloadTemplate.
+	PayloadTemplate string
+	// Format is what the content of a call is converted to: text (the default), html or slack.
+	Format string
+}
+
+// Payload is the data the URL and payload templates are executed with.
+type Payload struct {
+	To       string
+	Author   string
+	Subject  string
+	Body     string
+	Campaign model.Campaign
+}
+
+// Client is an interface that defines the methods for sending calls to a webhook.
+type Client interface {
+	Post(to, author, subject, body string, campaign model.Campaign) (string, error)
+	// Format returns what the content of a call is converted to before it is posted.
+	Format() string
+}
+
+// client is the concrete implementation of the Client interface.
+type client struct {
+	name       string
+	def        Type
+	url        *template.Template
+	payload    *template.Template
+	httpClient *http.Client
+}
+
+// Option configures the client.
+type Option func(*client)
+
+// WithHTTPClient overrides the HTTP client used to post the payload.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *client) {
+		c.httpClient = httpClient
+	}
+}
+
+// NewClient creates a client for the named type, parsing its templates.
+func NewClient(name string, def Type, opts ...Option) (Client, error) {
+	if def.URL == "" {
+		return nil, fmt.Errorf("%w: %s: a webhook URL is required", ErrInvalidType, name)
+	}
+	if def.Method == "" {
+		def.Method = http.MethodPost
+	}
+	if def.ContentType == "" {
+		def.ContentType = "application/json"
+	}
+	if def.PayloadTemplate == "" {
+		def.PayloadTemplate = DefaultPayloadTemplate
+	}
+	switch def.Format {
+	case "":
+		def.Format = FormatText
+	case FormatText, FormatHTML, FormatSlack:
+		// Valid
+	default:
+		return nil, fmt.Errorf("%w: %s: format must be one of %s, %s or %s", ErrInvalidType, name, FormatText, FormatHTML, FormatSlack)
+	}
+
+	funcs := template.FuncMap{"json": toJSON}
+	url, err := template.New(name + " url").Funcs(funcs).Parse(def.URL)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: url: %w", ErrInvalidType, name, err)
+	}
+	payload, err := template.New(name + " payload").Funcs(funcs).Parse(def.PayloadTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: payload_template: %w", ErrInvalidType, name, err)
+	}
+
+	c := &client{
+		name:       name,
+		def:        def,
+		url:        url,
+		payload:    payload,
+		httpClient: rufhttp.NewClient(),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c, nil
+}
+
+// Format returns what the content of a call is converted to before it is posted.
+func (c *client) Format() string {
+	return c.def.Format
+}
+
+// Post renders the payload and posts it to the webhook. Webhooks do not identify the messages they
+// receive, so the returned ID is always empty.
+func (c *client) Post(to, author, subject, body string, campaign model.Campaign) (string, error) {
+	data := Payload{To: to, Author: author, Subject: subject, Body: body, Campaign: campaign}
+
+	var url, payload bytes.Buffer
+	if err := c.url.Execute(&url, data); err != nil {
+		return "", fmt.Errorf("%w: %s: url: %w", ErrPostFailed, c.name, err)
+	}
+	if err := c.payload.Execute(&payload, data); err != nil {
+		return "", fmt.Errorf("%w: %s: payload: %w", ErrPostFailed, c.name, err)
+	}
+
+	req, err := http.NewRequest(c.def.Method, url.String(), &payload)
+	if err != nil {
+		return "", fmt.Errorf("%w: %s: %w", ErrPostFailed, c.name, err)
+	}
+	req.Header.Set("Content-Type", c.def.ContentType)
+	for k, v := range c.def.Headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("%w: %s: %w", ErrPostFailed, c.name, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return "", fmt.Errorf("%w: %s: status code %d: %s", ErrPostFailed, c.name, resp.StatusCode, strings.TrimSpace(string(respBody)))
+	}
+	return "", nil
+}
+
+// toJSON encodes a value as JSON, so that templates can place text in a JSON payload safely.
+func toJSON(v interface{}) (string, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
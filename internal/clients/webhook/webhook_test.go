@@ -0,0 +1,82 @@
+package webhook_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/andrewhowdencom/ruf/internal/clients/webhook"
+	"github.com/andrewhowdencom/ruf/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClient_Post(t *testing.T) {
+	var payload map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPut, r.Method)
+		assert.Equal(t, "/rooms/ops", r.URL.Path)
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	client, err := webhook.NewClient("statusbot", webhook.Type{
+		URL:             server.URL + "/rooms/{{ .To }}",
+		Method:          http.MethodPut,
+		Headers:         map[string]string{"Authorization": "Bearer token"},
+		PayloadTemplate: `{"title": {{ json .Subject }}, "message": {{ json .Body }}}`,
+	}, webhook.WithHTTPClient(server.Client()))
+	assert.NoError(t, err)
+
+	_, err = client.Post("ops", "", "Deploy", "Say \"hello\"\nto the new release.", model.Campaign{})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"title": "Deploy", "message": "Say \"hello\"\nto the new release."}, payload)
+}
+
+func TestClient_PostDefaultPayload(t *testing.T) {
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		body, _ = io.ReadAll(r.Body)
+	}))
+	defer server.Close()
+
+	client, err := webhook.NewClient("hook", webhook.Type{URL: server.URL}, webhook.WithHTTPClient(server.Client()))
+	assert.NoError(t, err)
+	_, err = client.Post("ops", "author@example.com", "Subject", "Hello!", model.Campaign{Name: "Campaign"})
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"to": "ops", "author": "author@example.com", "subject": "Subject", "text": "Hello!", "campaign": "Campaign"}`, string(body))
+}
+
+func TestClient_PostFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "no such room", http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	client, err := webhook.NewClient("hook", webhook.Type{URL: server.URL}, webhook.WithHTTPClient(server.Client()))
+	assert.NoError(t, err)
+	_, err = client.Post("ops", "", "", "Hello!", model.Campaign{})
+	assert.ErrorIs(t, err, webhook.ErrPostFailed)
+	assert.ErrorContains(t, err, "no such room")
+}
+
+func TestNewClient_Invalid(t *testing.T) {
+	for name, def := range map[string]webhook.Type{
+		"no url":     {},
+		"bad format": {URL: "https://example.com", Format: "pdf"},
+		"bad template": {
+			URL:             "https://example.com",
+			PayloadTemplate: "{{ .Body",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := webhook.NewClient("hook", def)
+			assert.ErrorIs(t, err, webhook.ErrInvalidType)
+		})
+	}
+}
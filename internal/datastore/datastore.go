@@ -5,6 +5,7 @@ import (
 
 	"github.com/andrewhowdencom/ruf/internal/kv"
 	"github.com/andrewhowdencom/ruf/internal/kv/bbolt"
+	"github.com/andrewhowdencom/ruf/internal/kv/cache"
 	"github.com/andrewhowdencom/ruf/internal/kv/firestore"
 	"github.com/spf13/viper"
 )
@@ -23,7 +24,23 @@ func NewStore(readOnly bool) (kv.Storer, error) {
 		if projectID == "" {
 			return nil, fmt.Errorf("datastore.project_id must be set when using firestore")
 		}
-		return firestore.NewStore(projectID)
+		store, err := firestore.NewStore(projectID,
+			firestore.WithTimeout(viper.GetDuration("datastore.firestore.timeout")),
+			firestore.WithRetry(
+				viper.GetInt("datastore.firestore.retry.attempts"),
+				viper.GetDuration("datastore.firestore.retry.initial_backoff"),
+				viper.GetDuration("datastore.firestore.retry.max_backoff"),
+			),
+			firestore.WithConnectionPool(viper.GetInt("datastore.firestore.connections")),
+		)
+		if err != nil {
+			return nil, err
+		}
+		// Firestore point reads are slow and billable, so they are cached unless disabled.
+		if ttl := viper.GetDuration("datastore.cache.ttl"); ttl > 0 {
+			return cache.New(store, cache.WithTTL(ttl)), nil
+		}
+		return store, nil
 	default:
 		return nil, fmt.Errorf("unknown datastore type: %s", datastoreType)
 	}
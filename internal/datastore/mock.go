@@ -2,6 +2,7 @@ package datastore
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -13,6 +14,18 @@ import (
 type MockStore struct {
 	sentMessages   map[string]*kv.SentMessage
 	scheduledCalls map[string]*kv.ScheduledCall
+	overrides      map[string]*kv.TriggerOverride
+	series         map[string]*kv.SeriesOverride
+	leases         map[string]*kv.Lease
+	signoffs       map[string]*kv.Signoff
+	cancellations  map[string]*kv.Cancellation
+	reschedules    map[string]*kv.Reschedule
+	approvals      map[string]*kv.Approval
+	confirmations  map[string]*kv.Confirmation
+	deadLetters    map[string]*kv.DeadLetter
+	workers        map[string]*kv.WorkerStatus
+	sources        map[string]*kv.CachedSource
+	slots          map[time.Time]string
 	schemaVersion  int
 	mu             sync.Mutex
 }
@@ -22,6 +35,18 @@ func NewMockStore() *MockStore {
 	return &MockStore{
 		sentMessages:   make(map[string]*kv.SentMessage),
 		scheduledCalls: make(map[string]*kv.ScheduledCall),
+		overrides:      make(map[string]*kv.TriggerOverride),
+		series:         make(map[string]*kv.SeriesOverride),
+		leases:         make(map[string]*kv.Lease),
+		signoffs:       make(map[string]*kv.Signoff),
+		cancellations:  make(map[string]*kv.Cancellation),
+		reschedules:    make(map[string]*kv.Reschedule),
+		approvals:      make(map[string]*kv.Approval),
+		confirmations:  make(map[string]*kv.Confirmation),
+		deadLetters:    make(map[string]*kv.DeadLetter),
+		workers:        make(map[string]*kv.WorkerStatus),
+		sources:        make(map[string]*kv.CachedSource),
+		slots:          make(map[time.Time]string),
 	}
 }
 
@@ -29,14 +54,16 @@ func NewMockStore() *MockStore {
 func (s *MockStore) AddSentMessage(campaignID, callID string, sm *kv.SentMessage) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	sm.ID = s.generateID(campaignID, callID, sm.Type, sm.Destination)
+	sm.ID = kv.GenerateID(campaignID, callID, sm.OccurredAt, sm.Type, sm.Destination)
 	sm.ShortID = kv.GenerateShortID(sm.ID)
-	s.sentMessages[sm.ID] = sm
+	sm.CampaignID = campaignID
 
 	// if the status is not set, default to sent
 	if sm.Status == "" {
 		sm.Status = kv.StatusSent
 	}
+	sm.Supersede(s.sentMessages[sm.ID], time.Now())
+	s.sentMessages[sm.ID] = sm
 	return nil
 }
 
@@ -44,27 +71,21 @@ func (s *MockStore) AddSentMessage(campaignID, callID string, sm *kv.SentMessage
 func (s *MockStore) UpdateSentMessage(sm *kv.SentMessage) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
+	sm.Supersede(s.sentMessages[sm.ID], time.Now())
 	s.sentMessages[sm.ID] = sm
 	return nil
 }
 
-// HasBeenSent checks if a message with the given sourceID and scheduledAt time has been sent.
-func (s *MockStore) HasBeenSent(campaignID, callID, destType, destination string) (bool, error) {
+// HasBeenSent checks if the message for an occurrence of a call has been sent.
+func (s *MockStore) HasBeenSent(campaignID, callID string, occurredAt time.Time, destType, destination string) (bool, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	id := s.generateID(campaignID, callID, destType, destination)
-	sm, ok := s.sentMessages[id]
-	return ok && (sm.Status == kv.StatusSent || sm.Status == kv.StatusDeleted), nil
-}
-
-func (s *MockStore) generateID(campaignID, callID, destType, destination string) string {
-	parts := []string{
-		campaignID,
-		callID,
-		destType,
-		destination,
+	for _, id := range kv.SentIDs(campaignID, callID, occurredAt, destType, destination) {
+		if sm, ok := s.sentMessages[id]; ok && sm.Settles(occurredAt) {
+			return true, nil
+		}
 	}
-	return strings.Join(parts, "@")
+	return false, nil
 }
 
 // ListSentMessages retrieves all sent messages from the mock store.
@@ -78,6 +99,54 @@ func (s *MockStore) ListSentMessages() ([]*kv.SentMessage, error) {
 	return sentMessages, nil
 }
 
+// ListSentMessagesByDestination retrieves the messages sent to a destination, most recently scheduled first.
+func (s *MockStore) ListSentMessagesByDestination(destination string, limit int) ([]*kv.SentMessage, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var sentMessages []*kv.SentMessage
+	for _, sm := range s.sentMessages {
+		if sm.Destination == destination {
+			sentMessages = append(sentMessages, sm)
+		}
+	}
+	sort.Slice(sentMessages, func(i, j int) bool {
+		return sentMessages[i].ScheduledAt.After(sentMessages[j].ScheduledAt)
+	})
+	if limit > 0 && len(sentMessages) > limit {
+		sentMessages = sentMessages[:limit]
+	}
+	return sentMessages, nil
+}
+
+// ListSentMessagesByCampaign retrieves the messages sent for a campaign, most recently scheduled first.
+func (s *MockStore) ListSentMessagesByCampaign(campaignID string) ([]*kv.SentMessage, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var sentMessages []*kv.SentMessage
+	for _, sm := range s.sentMessages {
+		if sm.CampaignID == campaignID {
+			sentMessages = append(sentMessages, sm)
+		}
+	}
+	sort.Slice(sentMessages, func(i, j int) bool {
+		return sentMessages[i].ScheduledAt.After(sentMessages[j].ScheduledAt)
+	})
+	return sentMessages, nil
+}
+
+// ListExpiringMessages retrieves the sent messages that are due to be deleted at or before a time.
+func (s *MockStore) ListExpiringMessages(before time.Time) ([]*kv.SentMessage, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var sentMessages []*kv.SentMessage
+	for _, sm := range s.sentMessages {
+		if sm.Status == kv.StatusSent && !sm.DeleteAt.IsZero() && !sm.DeleteAt.After(before) {
+			sentMessages = append(sentMessages, sm)
+		}
+	}
+	return sentMessages, nil
+}
+
 // GetSentMessage retrieves a single sent message from the mock store.
 func (s *MockStore) GetSentMessage(id string) (*kv.SentMessage, error) {
 	s.mu.Lock()
@@ -119,7 +188,10 @@ func (s *MockStore) DeleteSentMessage(id string) error {
 	if err != nil {
 		return err
 	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	sm.Status = kv.StatusDeleted
+	sm.Supersede(sm, time.Now())
 	return nil
 }
 
@@ -133,9 +205,309 @@ func (m *MockStore) ReserveSlot(slot time.Time, callID string) (bool, error) {
 }
 
 func (m *MockStore) ClearAllSlots() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.slots = make(map[time.Time]string)
+	return nil
+}
+
+// ListSlots returns the slot reservations written with the schedule.
+func (m *MockStore) ListSlots() (map[time.Time]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	slots := make(map[time.Time]string, len(m.slots))
+	for slot, callID := range m.slots {
+		slots[slot] = callID
+	}
+	return slots, nil
+}
+
+// SetTriggerOverride adds or replaces the override for a trigger in the mock store.
+func (s *MockStore) SetTriggerOverride(o *kv.TriggerOverride) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.overrides[o.Key()] = o
+	return nil
+}
+
+// ListTriggerOverrides retrieves all trigger overrides from the mock store.
+func (s *MockStore) ListTriggerOverrides() ([]*kv.TriggerOverride, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	overrides := make([]*kv.TriggerOverride, 0, len(s.overrides))
+	for _, o := range s.overrides {
+		overrides = append(overrides, o)
+	}
+	return overrides, nil
+}
+
+// DeleteTriggerOverride removes the override for a trigger from the mock store.
+func (s *MockStore) DeleteTriggerOverride(callID string, index int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := (&kv.TriggerOverride{CallID: callID, Index: index}).Key()
+	if _, ok := s.overrides[key]; !ok {
+		return fmt.Errorf("%w: trigger override '%s'", kv.ErrNotFound, key)
+	}
+	delete(s.overrides, key)
+	return nil
+}
+
+// SetSeriesOverride adds or replaces the override for a series in the mock store.
+func (s *MockStore) SetSeriesOverride(o *kv.SeriesOverride) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.series[o.SeriesID] = o
+	return nil
+}
+
+// ListSeriesOverrides retrieves all series overrides from the mock store.
+func (s *MockStore) ListSeriesOverrides() ([]*kv.SeriesOverride, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	overrides := make([]*kv.SeriesOverride, 0, len(s.series))
+	for _, o := range s.series {
+		overrides = append(overrides, o)
+	}
+	return overrides, nil
+}
+
+// DeleteSeriesOverride removes the override for a series from the mock store.
+func (s *MockStore) DeleteSeriesOverride(seriesID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.series[seriesID]; !ok {
+		return fmt.Errorf("%w: series override '%s'", kv.ErrNotFound, seriesID)
+	}
+	delete(s.series, seriesID)
 	return nil
 }
 
+// AddSignoff adds or replaces the sign-off of a checklist item in the mock store.
+func (s *MockStore) AddSignoff(so *kv.Signoff) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.signoffs[so.CampaignID+"\x00"+so.CallID+"\x00"+so.Item] = so
+	return nil
+}
+
+// ListSignoffs returns the sign-offs of the checklist items of a scheduled call in the mock store.
+func (s *MockStore) ListSignoffs(campaignID, callID string) ([]*kv.Signoff, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var signoffs []*kv.Signoff
+	for _, so := range s.signoffs {
+		if so.CampaignID == campaignID && so.CallID == callID {
+			signoffs = append(signoffs, so)
+		}
+	}
+	return signoffs, nil
+}
+
+// CancelCall adds or replaces the cancellation of a scheduled call in the mock store.
+func (s *MockStore) CancelCall(c *kv.Cancellation) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cancellations[c.CallID] = c
+	return nil
+}
+
+// GetCancellation returns the cancellation of a scheduled call from the mock store.
+func (s *MockStore) GetCancellation(callID string) (*kv.Cancellation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c, ok := s.cancellations[callID]
+	if !ok {
+		return nil, fmt.Errorf("%w: cancellation of '%s'", kv.ErrNotFound, callID)
+	}
+	return c, nil
+}
+
+// DeleteCancellation removes the cancellation of a scheduled call from the mock store.
+func (s *MockStore) DeleteCancellation(callID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.cancellations[callID]; !ok {
+		return fmt.Errorf("%w: cancellation of '%s'", kv.ErrNotFound, callID)
+	}
+	delete(s.cancellations, callID)
+	return nil
+}
+
+// SetReschedule adds or replaces the move of a scheduled call in the mock store.
+func (s *MockStore) SetReschedule(r *kv.Reschedule) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.reschedules[r.CallID] = r
+	return nil
+}
+
+// ListReschedules returns the moves of every scheduled call from the mock store.
+func (s *MockStore) ListReschedules() ([]*kv.Reschedule, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	reschedules := make([]*kv.Reschedule, 0, len(s.reschedules))
+	for _, r := range s.reschedules {
+		reschedules = append(reschedules, r)
+	}
+	return reschedules, nil
+}
+
+// DeleteReschedule removes the move of a scheduled call from the mock store.
+func (s *MockStore) DeleteReschedule(callID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.reschedules[callID]; !ok {
+		return fmt.Errorf("%w: reschedule of '%s'", kv.ErrNotFound, callID)
+	}
+	delete(s.reschedules, callID)
+	return nil
+}
+
+// SetApproval adds or replaces the approval of a scheduled call in the mock store.
+func (s *MockStore) SetApproval(a *kv.Approval) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.approvals[a.CallID] = a
+	return nil
+}
+
+// GetApproval returns the approval of a scheduled call from the mock store.
+func (s *MockStore) GetApproval(callID string) (*kv.Approval, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	a, ok := s.approvals[callID]
+	if !ok {
+		return nil, fmt.Errorf("%w: approval of '%s'", kv.ErrNotFound, callID)
+	}
+	return a, nil
+}
+
+// ListApprovals returns the approvals of every scheduled call from the mock store.
+func (s *MockStore) ListApprovals() ([]*kv.Approval, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	approvals := make([]*kv.Approval, 0, len(s.approvals))
+	for _, a := range s.approvals {
+		approvals = append(approvals, a)
+	}
+	return approvals, nil
+}
+
+// SetConfirmation adds or replaces the confirmation of a scheduled call in the mock store.
+func (s *MockStore) SetConfirmation(c *kv.Confirmation) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.confirmations[c.CallID] = c
+	return nil
+}
+
+// GetConfirmation returns the confirmation of a scheduled call from the mock store.
+func (s *MockStore) GetConfirmation(callID string) (*kv.Confirmation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c, ok := s.confirmations[callID]
+	if !ok {
+		return nil, fmt.Errorf("%w: confirmation of '%s'", kv.ErrNotFound, callID)
+	}
+	return c, nil
+}
+
+// AddDeadLetter adds or replaces the dead letter of a message that failed to send in the mock store.
+func (s *MockStore) AddDeadLetter(d *kv.DeadLetter) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.deadLetters[d.ID] = d
+	return nil
+}
+
+// GetDeadLetter returns the dead letter of a sent message from the mock store.
+func (s *MockStore) GetDeadLetter(id string) (*kv.DeadLetter, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	d, ok := s.deadLetters[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: dead letter '%s'", kv.ErrNotFound, id)
+	}
+	return d, nil
+}
+
+// ListDeadLetters returns every dead letter from the mock store.
+func (s *MockStore) ListDeadLetters() ([]*kv.DeadLetter, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	letters := make([]*kv.DeadLetter, 0, len(s.deadLetters))
+	for _, d := range s.deadLetters {
+		letters = append(letters, d)
+	}
+	return letters, nil
+}
+
+// DeleteDeadLetter removes the dead letter of a sent message from the mock store.
+func (s *MockStore) DeleteDeadLetter(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.deadLetters[id]; !ok {
+		return fmt.Errorf("%w: dead letter '%s'", kv.ErrNotFound, id)
+	}
+	delete(s.deadLetters, id)
+	return nil
+}
+
+// SetWorkerStatus adds or replaces the status of a worker in the mock store.
+func (s *MockStore) SetWorkerStatus(ws *kv.WorkerStatus) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	copied := *ws
+	s.workers[ws.Holder] = &copied
+	return nil
+}
+
+// ListWorkerStatuses returns the status of every worker from the mock store.
+func (s *MockStore) ListWorkerStatuses() ([]*kv.WorkerStatus, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	statuses := make([]*kv.WorkerStatus, 0, len(s.workers))
+	for _, ws := range s.workers {
+		copied := *ws
+		statuses = append(statuses, &copied)
+	}
+	return statuses, nil
+}
+
+// SetCachedSource adds or replaces the cached content of a source in the mock store.
+func (s *MockStore) SetCachedSource(c *kv.CachedSource) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sources[c.URL] = c
+	return nil
+}
+
+// GetCachedSource returns the cached content of a source from the mock store.
+func (s *MockStore) GetCachedSource(url string) (*kv.CachedSource, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c, ok := s.sources[url]
+	if !ok {
+		return nil, fmt.Errorf("%w: cached source '%s'", kv.ErrNotFound, url)
+	}
+	return c, nil
+}
+
+// AcquireLease takes or renews the named lease for the holder, unless another holder has a lease
+// that has not expired.
+func (s *MockStore) AcquireLease(name, holder string, now time.Time, ttl time.Duration) (*kv.Lease, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if current, ok := s.leases[name]; ok && current.Holder != holder && now.Before(current.Expires) {
+		lease := *current
+		return &lease, fmt.Errorf("%w: '%s' is held by '%s'", kv.ErrLeaseHeld, name, current.Holder)
+	}
+	s.leases[name] = &kv.Lease{Name: name, Holder: holder, Expires: now.Add(ttl)}
+	lease := *s.leases[name]
+	return &lease, nil
+}
+
 // GetSchemaVersion retrieves the current schema version from the mock store.
 func (s *MockStore) GetSchemaVersion() (int, error) {
 	s.mu.Lock()
@@ -159,6 +531,31 @@ func (s *MockStore) AddScheduledCall(call *kv.ScheduledCall) error {
 	return nil
 }
 
+// AddScheduledCalls adds a batch of scheduled calls to the mock store.
+func (s *MockStore) AddScheduledCalls(calls []*kv.ScheduledCall) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, call := range calls {
+		s.scheduledCalls[call.ID] = call
+	}
+	return nil
+}
+
+// ReplaceSchedule replaces all scheduled calls and slot reservations in the mock store.
+func (s *MockStore) ReplaceSchedule(calls []*kv.ScheduledCall, slots map[time.Time]string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.scheduledCalls = make(map[string]*kv.ScheduledCall, len(calls))
+	for _, call := range calls {
+		s.scheduledCalls[call.ID] = call
+	}
+	s.slots = make(map[time.Time]string, len(slots))
+	for slot, callID := range slots {
+		s.slots[slot.UTC()] = callID
+	}
+	return nil
+}
+
 func (s *MockStore) GetScheduledCall(id string) (*kv.ScheduledCall, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
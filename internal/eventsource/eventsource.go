@@ -0,0 +1,179 @@
+// Package eventsource reads events from external calendars, so that sequence triggers can follow
+// real meeting schedules rather than start times copied into source files.
+package eventsource
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	rufhttp "github.com/andrewhowdencom/ruf/internal/http"
+	"github.com/andrewhowdencom/ruf/internal/model"
+	"google.golang.org/api/option"
+)
+
+// Err* are common errors returned when reading events.
+var (
+	ErrFetchFailed   = errors.New("failed to fetch events")
+	ErrInvalidSource = errors.New("invalid event source")
+)
+
+// Default window of events that are read, relative to the current time.
+const (
+	DefaultLookback  = 7 * 24 * time.Hour
+	DefaultLookahead = 30 * 24 * time.Hour
+)
+
+// Option configures the Resolver.
+type Option func(*Resolver)
+
+// WithHTTPClient overrides the HTTP client used to fetch iCalendar feeds.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(r *Resolver) {
+		r.httpClient = httpClient
+	}
+}
+
+// WithGoogleOptions adds options to the Google Calendar client, such as credentials or an endpoint.
+func WithGoogleOptions(opts ...option.ClientOption) Option {
+	return func(r *Resolver) {
+		r.googleOptions = append(r.googleOptions, opts...)
+	}
+}
+
+// WithWindow sets how far before and after the current time events are read.
+func WithWindow(lookback, lookahead time.Duration) Option {
+	return func(r *Resolver) {
+		r.lookback = lookback
+		r.lookahead = lookahead
+	}
+}
+
+// WithClock sets the function used to determine the current time.
+func WithClock(now func() time.Time) Option {
+	return func(r *Resolver) {
+		r.now = now
+	}
+}
+
+// Resolver reads the events of external calendars and assigns them to sequences.
+type Resolver struct {
+	httpClient    *http.Client
+	googleOptions []option.ClientOption
+	lookback      time.Duration
+	lookahead     time.Duration
+	now           func() time.Time
+}
+
+// NewResolver creates a new Resolver.
+func NewResolver(opts ...Option) *Resolver {
+	r := &Resolver{
+		httpClient: rufhttp.NewClient(),
+		lookback:   DefaultLookback,
+		lookahead:  DefaultLookahead,
+		now:        time.Now,
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// entry is an occurrence of an event in an external calendar.
+type entry struct {
+	title      string
+	start      time.Time
+	end        time.Time
+	properties map[string]string
+}
+
+// property returns the value of a property of the entry, ignoring the case of its name.
+func (e entry) property(name string) (string, bool) {
+	for k, v := range e.properties {
+		if strings.EqualFold(k, name) {
+			return v, true
+		}
+	}
+	return "", false
+}
+
+// Events returns the events of the source that fall in the window around the current time, with
+// one event for every sequence an occurrence matches.
+func (r *Resolver) Events(ctx context.Context, src model.EventSource) ([]model.Event, error) {
+	matchers, err := compile(src.Match)
+	if err != nil {
+		return nil, err
+	}
+
+	now := r.now()
+	from, to := now.Add(-r.lookback), now.Add(r.lookahead)
+
+	var entries []entry
+	switch {
+	case src.ICal != "" && src.GoogleCalendar != "":
+		return nil, fmt.Errorf("%w: only one of ical and google_calendar may be set", ErrInvalidSource)
+	case src.ICal != "":
+		entries, err = r.ical(ctx, src.ICal, from, to)
+	case src.GoogleCalendar != "":
+		entries, err = r.google(ctx, src.GoogleCalendar, from, to)
+	default:
+		return nil, fmt.Errorf("%w: one of ical or google_calendar must be set", ErrInvalidSource)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var events []model.Event
+	for _, e := range entries {
+		for _, m := range matchers {
+			if m.matches(e) {
+				events = append(events, model.Event{Sequence: m.sequence, StartTime: e.start, EndTime: e.end})
+			}
+		}
+	}
+	return events, nil
+}
+
+type matcher struct {
+	sequence string
+	title    *regexp.Regexp
+	property string
+}
+
+func compile(matches []model.EventMatch) ([]matcher, error) {
+	matchers := make([]matcher, 0, len(matches))
+	for _, m := range matches {
+		if m.Sequence == "" {
+			return nil, fmt.Errorf("%w: every match needs a sequence", ErrInvalidSource)
+		}
+		if m.Title == "" && m.Property == "" {
+			return nil, fmt.Errorf("%w: the match for sequence '%s' needs a title or a property", ErrInvalidSource, m.Sequence)
+		}
+		compiled := matcher{sequence: m.Sequence, property: m.Property}
+		if m.Title != "" {
+			title, err := regexp.Compile(m.Title)
+			if err != nil {
+				return nil, fmt.Errorf("%w: invalid title pattern for sequence '%s': %w", ErrInvalidSource, m.Sequence, err)
+			}
+			compiled.title = title
+		}
+		matchers = append(matchers, compiled)
+	}
+	return matchers, nil
+}
+
+func (m matcher) matches(e entry) bool {
+	if m.title != nil && m.title.MatchString(e.title) {
+		return true
+	}
+	if m.property != "" {
+		if v, ok := e.property(m.property); ok && v == m.sequence {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,132 @@
+package eventsource_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/andrewhowdencom/ruf/internal/eventsource"
+	"github.com/andrewhowdencom/ruf/internal/model"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/api/option"
+)
+
+const feed = "BEGIN:VCALENDAR\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:review@example.com\r\n" +
+	"SUMMARY:Incident review\\, weekly\r\n" +
+	"DTSTART;TZID=Europe/Berlin:20250303T140000\r\n" +
+	"DURATION:PT1H30M\r\n" +
+	"RRULE:FREQ=WEEKLY;COUNT=4\r\n" +
+	"EXDATE;TZID=Europe/Berlin:20250317T140000\r\n" +
+	"BEGIN:VALARM\r\n" +
+	"SUMMARY:Reminder\r\n" +
+	"DURATION:PT15M\r\n" +
+	"END:VALARM\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:review@example.com\r\n" +
+	"RECURRENCE-ID;TZID=Europe/Berlin:20250310T140000\r\n" +
+	"SUMMARY:Incident review\r\n" +
+	"DTSTART;TZID=Europe/Berlin:20250311T090000\r\n" +
+	"DTEND;TZID=Europe/Berlin:20250311T100000\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:retro@example.com\r\n" +
+	"SUMMARY:Sprint retro\r\n" +
+	"X-RUF-SEQUENCE:retro\r\n" +
+	"DTSTART:20250305T160000Z\r\n" +
+	"DTEND:20250305T170000Z\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:cancelled@example.com\r\n" +
+	"SUMMARY:Incident review\r\n" +
+	"STATUS:CANCELLED\r\n" +
+	"DTSTART:20250306T160000Z\r\n" +
+	"END:VEVENT\r\n" +
+	"END:VCALENDAR\r\n"
+
+func TestResolver_ICal(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(feed))
+	}))
+	defer server.Close()
+
+	now := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	resolver := eventsource.NewResolver(eventsource.WithClock(func() time.Time { return now }))
+
+	events, err := resolver.Events(context.Background(), model.EventSource{
+		ICal: server.URL,
+		Match: []model.EventMatch{
+			{Sequence: "incident-review", Title: "^Incident review"},
+			{Sequence: "retro", Property: "x-ruf-sequence"},
+		},
+	})
+	assert.NoError(t, err)
+
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	assert.NoError(t, err)
+	assert.Equal(t, []model.Event{
+		// The occurrence on the 10th was moved to the 11th, and the one on the 17th was cancelled.
+		{Sequence: "incident-review", StartTime: time.Date(2025, 3, 3, 14, 0, 0, 0, berlin), EndTime: time.Date(2025, 3, 3, 15, 30, 0, 0, berlin)},
+		{Sequence: "incident-review", StartTime: time.Date(2025, 3, 24, 14, 0, 0, 0, berlin), EndTime: time.Date(2025, 3, 24, 15, 30, 0, 0, berlin)},
+		{Sequence: "incident-review", StartTime: time.Date(2025, 3, 11, 9, 0, 0, 0, berlin), EndTime: time.Date(2025, 3, 11, 10, 0, 0, 0, berlin)},
+		{Sequence: "retro", StartTime: time.Date(2025, 3, 5, 16, 0, 0, 0, time.UTC), EndTime: time.Date(2025, 3, 5, 17, 0, 0, 0, time.UTC)},
+	}, events)
+}
+
+func TestResolver_Google(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/calendars/team@example.com/events", r.URL.Path)
+		assert.Equal(t, "true", r.URL.Query().Get("singleEvents"))
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"items": []map[string]interface{}{
+				{
+					"summary": "Retro",
+					"start":   map[string]string{"dateTime": "2025-03-05T16:00:00Z"},
+					"end":     map[string]string{"dateTime": "2025-03-05T17:00:00Z"},
+					"extendedProperties": map[string]interface{}{
+						"private": map[string]string{"ruf_sequence": "retro"},
+					},
+				},
+				{
+					"summary": "Lunch",
+					"start":   map[string]string{"dateTime": "2025-03-05T12:00:00Z"},
+					"end":     map[string]string{"dateTime": "2025-03-05T13:00:00Z"},
+				},
+			},
+		})
+	}))
+	defer server.Close()
+
+	resolver := eventsource.NewResolver(eventsource.WithGoogleOptions(
+		option.WithEndpoint(server.URL),
+		option.WithoutAuthentication(),
+	))
+	events, err := resolver.Events(context.Background(), model.EventSource{
+		GoogleCalendar: "team@example.com",
+		Match:          []model.EventMatch{{Sequence: "retro", Property: "ruf_sequence"}},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []model.Event{{
+		Sequence:  "retro",
+		StartTime: time.Date(2025, 3, 5, 16, 0, 0, 0, time.UTC),
+		EndTime:   time.Date(2025, 3, 5, 17, 0, 0, 0, time.UTC),
+	}}, events)
+}
+
+func TestResolver_InvalidSource(t *testing.T) {
+	resolver := eventsource.NewResolver()
+	for _, src := range []model.EventSource{
+		{Match: []model.EventMatch{{Sequence: "retro", Title: "Retro"}}},
+		{ICal: "https://example.com/a.ics", GoogleCalendar: "team@example.com", Match: []model.EventMatch{{Sequence: "retro", Title: "Retro"}}},
+		{ICal: "https://example.com/a.ics", Match: []model.EventMatch{{Sequence: "retro"}}},
+		{ICal: "https://example.com/a.ics", Match: []model.EventMatch{{Sequence: "retro", Title: "("}}},
+	} {
+		_, err := resolver.Events(context.Background(), src)
+		assert.ErrorIs(t, err, eventsource.ErrInvalidSource)
+	}
+}
@@ -0,0 +1,71 @@
+package eventsource
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"google.golang.org/api/calendar/v3"
+	"google.golang.org/api/option"
+)
+
+// google returns the occurrences of the events of a Google Calendar between from and to. Recurring
+// events are expanded by the API.
+func (r *Resolver) google(ctx context.Context, calendarID string, from, to time.Time) ([]entry, error) {
+	opts := append([]option.ClientOption{option.WithScopes(calendar.CalendarReadonlyScope)}, r.googleOptions...)
+	service, err := calendar.NewService(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to create google calendar client: %w", ErrFetchFailed, err)
+	}
+
+	var entries []entry
+	call := service.Events.List(calendarID).
+		SingleEvents(true).
+		OrderBy("startTime").
+		TimeMin(from.Format(time.RFC3339)).
+		TimeMax(to.Format(time.RFC3339))
+	err = call.Pages(ctx, func(page *calendar.Events) error {
+		for _, item := range page.Items {
+			if item.Status == "cancelled" {
+				continue
+			}
+			start, err := googleTime(item.Start)
+			if err != nil {
+				return fmt.Errorf("event '%s': %w", item.Summary, err)
+			}
+			end, err := googleTime(item.End)
+			if err != nil {
+				return fmt.Errorf("event '%s': %w", item.Summary, err)
+			}
+
+			properties := make(map[string]string)
+			if item.ExtendedProperties != nil {
+				for k, v := range item.ExtendedProperties.Shared {
+					properties[k] = v
+				}
+				for k, v := range item.ExtendedProperties.Private {
+					properties[k] = v
+				}
+			}
+			entries = append(entries, entry{title: item.Summary, start: start, end: end, properties: properties})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: google calendar '%s': %w", ErrFetchFailed, calendarID, err)
+	}
+	return entries, nil
+}
+
+// googleTime returns the time of an event's start or end, which is a date for all-day events.
+func googleTime(t *calendar.EventDateTime) (time.Time, error) {
+	switch {
+	case t == nil:
+		return time.Time{}, nil
+	case t.DateTime != "":
+		return time.Parse(time.RFC3339, t.DateTime)
+	case t.Date != "":
+		return time.Parse(time.DateOnly, t.Date)
+	}
+	return time.Time{}, nil
+}
@@ -0,0 +1,270 @@
+package eventsource
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/teambition/rrule-go"
+)
+
+// ical fetches an iCalendar feed and returns the occurrences of its events between from and to,
+// expanding recurring events.
+func (r *Resolver) ical(ctx context.Context, url string, from, to time.Time) ([]entry, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrFetchFailed, err)
+	}
+	resp, err := r.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrFetchFailed, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: %s: status code %d", ErrFetchFailed, url, resp.StatusCode)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrFetchFailed, err)
+	}
+
+	vevents, err := parseICal(body)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %w", ErrInvalidSource, url, err)
+	}
+	return expand(vevents, from, to)
+}
+
+// vevent is an event of an iCalendar feed.
+type vevent struct {
+	uid          string
+	summary      string
+	status       string
+	start        time.Time
+	end          time.Time
+	duration     time.Duration
+	rrule        string
+	exdates      []time.Time
+	recurrenceID time.Time
+	properties   map[string]string
+}
+
+// parseICal reads the events of an iCalendar document. Properties of components nested in an
+// event, such as alarms, are ignored.
+func parseICal(data []byte) ([]*vevent, error) {
+	var (
+		events  []*vevent
+		current *vevent
+		nested  int
+	)
+
+	scanner := bufio.NewScanner(bytes.NewReader(unfold(data)))
+	scanner.Buffer(nil, 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		nameAndParams, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		name, params := parseParams(nameAndParams)
+
+		switch {
+		case name == "BEGIN" && value == "VEVENT":
+			current = &vevent{properties: make(map[string]string)}
+			continue
+		case name == "BEGIN" && current != nil:
+			nested++
+			continue
+		case name == "END" && nested > 0:
+			nested--
+			continue
+		case name == "END" && value == "VEVENT" && current != nil:
+			if current.start.IsZero() {
+				return nil, fmt.Errorf("event '%s' has no start", current.summary)
+			}
+			events = append(events, current)
+			current = nil
+			continue
+		}
+		if current == nil || nested > 0 {
+			continue
+		}
+
+		var err error
+		switch name {
+		case "UID":
+			current.uid = value
+		case "SUMMARY":
+			current.summary = unescape(value)
+		case "STATUS":
+			current.status = strings.ToUpper(value)
+		case "DTSTART":
+			current.start, err = parseTime(params, value)
+		case "DTEND":
+			current.end, err = parseTime(params, value)
+		case "DURATION":
+			current.duration, err = parseDuration(value)
+		case "RRULE":
+			current.rrule = value
+		case "EXDATE":
+			for _, v := range strings.Split(value, ",") {
+				exdate, err := parseTime(params, v)
+				if err != nil {
+					return nil, fmt.Errorf("event '%s': %w", current.summary, err)
+				}
+				current.exdates = append(current.exdates, exdate)
+			}
+		case "RECURRENCE-ID":
+			current.recurrenceID, err = parseTime(params, value)
+		default:
+			if strings.HasPrefix(name, "X-") {
+				current.properties[name] = unescape(value)
+			}
+		}
+		if err != nil {
+			return nil, fmt.Errorf("event '%s': %w", current.summary, err)
+		}
+	}
+	return events, scanner.Err()
+}
+
+// expand returns the occurrences of the events that start between from and to. Occurrences of a
+// recurring event that have been moved or cancelled are replaced by the events that override them.
+func expand(events []*vevent, from, to time.Time) ([]entry, error) {
+	overridden := make(map[string]bool)
+	for _, e := range events {
+		if !e.recurrenceID.IsZero() {
+			overridden[e.uid+"@"+e.recurrenceID.UTC().Format(time.RFC3339)] = true
+		}
+	}
+
+	var entries []entry
+	for _, e := range events {
+		if e.status == "CANCELLED" {
+			continue
+		}
+		length := e.duration
+		if !e.end.IsZero() {
+			length = e.end.Sub(e.start)
+		}
+
+		starts := []time.Time{e.start}
+		if e.rrule != "" {
+			opt, err := rrule.StrToROption(e.rrule)
+			if err != nil {
+				return nil, fmt.Errorf("%w: event '%s': invalid rrule: %w", ErrInvalidSource, e.summary, err)
+			}
+			opt.Dtstart = e.start
+			rule, err := rrule.NewRRule(*opt)
+			if err != nil {
+				return nil, fmt.Errorf("%w: event '%s': invalid rrule: %w", ErrInvalidSource, e.summary, err)
+			}
+			starts = rule.Between(from, to, true)
+		}
+
+		for _, start := range starts {
+			if start.Before(from) || start.After(to) || excluded(e, start) {
+				continue
+			}
+			if e.recurrenceID.IsZero() && overridden[e.uid+"@"+start.UTC().Format(time.RFC3339)] {
+				continue
+			}
+			var end time.Time
+			if length > 0 {
+				end = start.Add(length)
+			}
+			entries = append(entries, entry{title: e.summary, start: start, end: end, properties: e.properties})
+		}
+	}
+	return entries, nil
+}
+
+func excluded(e *vevent, start time.Time) bool {
+	for _, exdate := range e.exdates {
+		if exdate.Equal(start) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseParams splits a content line name, such as "DTSTART;TZID=Europe/Berlin", into the name and
+// its parameters.
+func parseParams(s string) (string, map[string]string) {
+	parts := strings.Split(s, ";")
+	params := make(map[string]string, len(parts)-1)
+	for _, p := range parts[1:] {
+		if k, v, ok := strings.Cut(p, "="); ok {
+			params[strings.ToUpper(k)] = strings.Trim(v, `"`)
+		}
+	}
+	return strings.ToUpper(parts[0]), params
+}
+
+// parseTime parses a DATE or DATE-TIME value. Floating times, and times in an unknown timezone,
+// are read as UTC; dates are midnight UTC.
+func parseTime(params map[string]string, value string) (time.Time, error) {
+	if params["VALUE"] == "DATE" || len(value) == 8 {
+		return time.Parse("20060102", value)
+	}
+	if strings.HasSuffix(value, "Z") {
+		return time.Parse("20060102T150405Z", value)
+	}
+	loc := time.UTC
+	if tzid := params["TZID"]; tzid != "" {
+		l, err := time.LoadLocation(tzid)
+		if err != nil {
+			slog.Warn("unknown timezone in iCalendar feed, using UTC", "tzid", tzid)
+		} else {
+			loc = l
+		}
+	}
+	return time.ParseInLocation("20060102T150405", value, loc)
+}
+
+var durationPattern = regexp.MustCompile(`^([+-])?P(?:(\d+)W)?(?:(\d+)D)?(?:T(?:(\d+)H)?(?:(\d+)M)?(?:(\d+)S)?)?$`)
+
+// parseDuration parses an iCalendar duration, such as "PT1H30M" or "P1D".
+func parseDuration(value string) (time.Duration, error) {
+	m := durationPattern.FindStringSubmatch(value)
+	if m == nil || value == "P" || strings.HasSuffix(value, "T") {
+		return 0, fmt.Errorf("invalid duration '%s'", value)
+	}
+	var d time.Duration
+	for i, unit := range []time.Duration{7 * 24 * time.Hour, 24 * time.Hour, time.Hour, time.Minute, time.Second} {
+		if m[i+2] == "" {
+			continue
+		}
+		n, err := strconv.Atoi(m[i+2])
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration '%s': %w", value, err)
+		}
+		d += time.Duration(n) * unit
+	}
+	if m[1] == "-" {
+		d = -d
+	}
+	return d, nil
+}
+
+// unfold joins content lines that have been folded onto continuation lines.
+func unfold(data []byte) []byte {
+	data = bytes.ReplaceAll(data, []byte("\r\n "), nil)
+	data = bytes.ReplaceAll(data, []byte("\r\n\t"), nil)
+	data = bytes.ReplaceAll(data, []byte("\n "), nil)
+	return bytes.ReplaceAll(data, []byte("\n\t"), nil)
+}
+
+// unescape reverses the escaping of TEXT values.
+func unescape(s string) string {
+	return strings.NewReplacer(`\,`, ",", `\;`, ";", `\n`, "\n", `\N`, "\n", `\\`, `\`).Replace(s)
+}
@@ -0,0 +1,193 @@
+// Package export reconstructs the payloads of sent messages, as they were delivered, for archival and
+// legal requests.
+package export
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/andrewhowdencom/ruf/internal/clients/email"
+	"github.com/andrewhowdencom/ruf/internal/clients/slack"
+	"github.com/andrewhowdencom/ruf/internal/kv"
+	"github.com/andrewhowdencom/ruf/internal/model"
+	"github.com/andrewhowdencom/ruf/internal/processor"
+	"gopkg.in/yaml.v3"
+)
+
+// Err* are common errors returned when exporting messages.
+var (
+	ErrUnsupportedFormat = errors.New("unsupported export format")
+	ErrNoSnapshot        = errors.New("message has no content snapshot")
+	ErrRenderFailed      = errors.New("failed to render message")
+)
+
+// Export formats.
+const (
+	FormatEML      = "eml"
+	FormatBlocks   = "blocks"
+	FormatMarkdown = "markdown"
+)
+
+// Option configures an export.
+type Option func(*options)
+
+type options struct {
+	from string
+}
+
+// WithFrom sets the address emails without an author were sent from.
+func WithFrom(from string) Option {
+	return func(o *options) {
+		o.from = from
+	}
+}
+
+// Message exports a sent message in the given format from its content snapshot.
+func Message(sm *kv.SentMessage, format string, opts ...Option) ([]byte, error) {
+	if sm.Snapshot == nil || sm.Snapshot.Content == "" {
+		return nil, fmt.Errorf("%w: %s", ErrNoSnapshot, sm.ID)
+	}
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	switch format {
+	case FormatEML:
+		return eml(sm, o)
+	case FormatBlocks:
+		return blocks(sm)
+	case FormatMarkdown:
+		return markdown(sm)
+	default:
+		return nil, fmt.Errorf("%w: %s: must be one of %s, %s or %s", ErrUnsupportedFormat, format, FormatEML, FormatBlocks, FormatMarkdown)
+	}
+}
+
+// Render renders the content of a message again from its call definition, for messages whose content
+// was not recorded. The templates are executed with the data recorded with the message, if any, and
+// the data of the definition otherwise.
+func Render(call *model.Call, sm *kv.SentMessage) (*kv.Snapshot, error) {
+	snapshot := &kv.Snapshot{Author: call.Author, Data: call.Data}
+	if sm.Snapshot != nil {
+		snapshot.Author = sm.Snapshot.Author
+		if sm.Snapshot.Data != nil {
+			snapshot.Data = sm.Snapshot.Data
+		}
+	}
+
+	data := make(map[string]interface{}, len(snapshot.Data)+1)
+	for k, v := range snapshot.Data {
+		data[k] = v
+	}
+	data["ScheduledAt"] = sm.ScheduledAt
+
+	var err error
+	p := processor.NewTemplateProcessor()
+	if snapshot.Subject, err = p.Process(call.Subject, data); err != nil {
+		return nil, fmt.Errorf("%w: subject: %w", ErrRenderFailed, err)
+	}
+	if snapshot.Content, err = p.Process(call.Content, data); err != nil {
+		return nil, fmt.Errorf("%w: content: %w", ErrRenderFailed, err)
+	}
+	return snapshot, nil
+}
+
+// eml exports the message as the email the client sends, with the time it was sent as its date.
+func eml(sm *kv.SentMessage, o *options) ([]byte, error) {
+	body, err := processor.NewMarkdownToHTMLProcessor().Process(sm.Snapshot.Content, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrRenderFailed, err)
+	}
+
+	headers := map[string]string{
+		"To":      sm.Destination,
+		"Subject": email.Subject(sm.Snapshot.Subject, model.Campaign{Name: sm.CampaignName}),
+		"From":    o.from,
+		"Date":    SentAt(sm).Format(time.RFC1123Z),
+	}
+	if sm.MessageID != "" {
+		headers["Message-ID"] = sm.MessageID
+	}
+	if author := sm.Snapshot.Author; author != "" {
+		headers["From"] = author
+		headers["Reply-To"] = author
+	}
+	return []byte(email.Message(headers, body)), nil
+}
+
+// blocks exports the message as a Slack chat.postMessage payload, with the text the client sends as a
+// Block Kit section.
+func blocks(sm *kv.SentMessage) ([]byte, error) {
+	text, err := processor.NewMarkdownToSlackProcessor().Process(sm.Snapshot.Content, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrRenderFailed, err)
+	}
+	text = slack.Text(sm.Snapshot.Subject, text)
+
+	payload := map[string]interface{}{
+		"channel": sm.Destination,
+		"text":    text,
+		"blocks": []interface{}{
+			map[string]interface{}{
+				"type": "section",
+				"text": map[string]interface{}{"type": "mrkdwn", "text": text},
+			},
+		},
+	}
+	if sm.Snapshot.Author == "" && sm.CampaignName != "" {
+		payload["username"] = sm.CampaignName
+	}
+	if sm.Timestamp != "" {
+		payload["ts"] = sm.Timestamp
+	}
+
+	b, err := json.MarshalIndent(payload, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrRenderFailed, err)
+	}
+	return append(b, '\n'), nil
+}
+
+// markdown exports the message as it was written, after its templates were rendered, with the details
+// of its delivery as front matter.
+func markdown(sm *kv.SentMessage) ([]byte, error) {
+	meta := map[string]interface{}{
+		"id":          sm.ID,
+		"campaign":    sm.CampaignName,
+		"type":        sm.Type,
+		"destination": sm.Destination,
+		"status":      string(sm.Status),
+		"sent_at":     SentAt(sm).Format(time.RFC3339),
+	}
+	if sm.Snapshot.Author != "" {
+		meta["author"] = sm.Snapshot.Author
+	}
+	if sm.Snapshot.Subject != "" {
+		meta["subject"] = sm.Snapshot.Subject
+	}
+	front, err := yaml.Marshal(meta)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrRenderFailed, err)
+	}
+
+	var b strings.Builder
+	b.WriteString("---\n")
+	b.Write(front)
+	b.WriteString("---\n\n")
+	b.WriteString(strings.TrimRight(sm.Snapshot.Content, "\n"))
+	b.WriteString("\n")
+	return []byte(b.String()), nil
+}
+
+// SentAt returns when the message was sent. Messages recorded before the time they were sent was kept
+// are taken as sent on schedule.
+func SentAt(sm *kv.SentMessage) time.Time {
+	if sm.SentAt.IsZero() {
+		return sm.ScheduledAt
+	}
+	return sm.SentAt
+}
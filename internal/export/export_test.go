@@ -0,0 +1,115 @@
+package export_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/andrewhowdencom/ruf/internal/export"
+	"github.com/andrewhowdencom/ruf/internal/kv"
+	"github.com/andrewhowdencom/ruf/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func sentMessage(destType, to string) *kv.SentMessage {
+	return &kv.SentMessage{
+		ID:           "abc123",
+		SourceID:     "release:scheduled_at:2025-03-04T09:00:00Z:" + destType + ":" + to,
+		ScheduledAt:  time.Date(2025, 3, 4, 9, 0, 0, 0, time.UTC),
+		SentAt:       time.Date(2025, 3, 4, 9, 0, 5, 0, time.UTC),
+		Destination:  to,
+		Type:         destType,
+		Status:       kv.StatusSent,
+		CampaignName: "Releases",
+		MessageID:    "<id@example.com>",
+		Snapshot: &kv.Snapshot{
+			Subject: "Release 1.2",
+			Content: "Release 1.2 is **out**.",
+		},
+	}
+}
+
+func TestMessage_EML(t *testing.T) {
+	out, err := export.Message(sentMessage("email", "team@example.com"), export.FormatEML, export.WithFrom("ruf@example.com"))
+	require.NoError(t, err)
+
+	headers, body, ok := strings.Cut(string(out), "\r\n\r\n")
+	require.True(t, ok)
+	assert.Equal(t, strings.Join([]string{
+		"Date: Tue, 04 Mar 2025 09:00:05 +0000",
+		"From: ruf@example.com",
+		"Message-ID: <id@example.com>",
+		"Subject: [Releases] Release 1.2",
+		"To: team@example.com",
+	}, "\r\n")+"\r\n", headers+"\r\n")
+	assert.Contains(t, body, "<strong>out</strong>")
+}
+
+func TestMessage_Blocks(t *testing.T) {
+	out, err := export.Message(sentMessage("slack", "#general"), export.FormatBlocks)
+	require.NoError(t, err)
+
+	var payload struct {
+		Channel  string `json:"channel"`
+		Text     string `json:"text"`
+		Username string `json:"username"`
+		Blocks   []struct {
+			Type string `json:"type"`
+			Text struct {
+				Type string `json:"type"`
+				Text string `json:"text"`
+			} `json:"text"`
+		} `json:"blocks"`
+	}
+	require.NoError(t, json.Unmarshal(out, &payload))
+	assert.Equal(t, "#general", payload.Channel)
+	assert.Equal(t, "Releases", payload.Username)
+	assert.Equal(t, "*Release 1.2*\nRelease 1.2 is *out*.", strings.TrimSpace(payload.Text))
+	require.Len(t, payload.Blocks, 1)
+	assert.Equal(t, "mrkdwn", payload.Blocks[0].Text.Type)
+	assert.Equal(t, payload.Text, payload.Blocks[0].Text.Text)
+}
+
+func TestMessage_Markdown(t *testing.T) {
+	out, err := export.Message(sentMessage("slack", "#general"), export.FormatMarkdown)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(out), "---\n"))
+	assert.Contains(t, string(out), "subject: Release 1.2\n")
+	assert.Contains(t, string(out), "sent_at: \"2025-03-04T09:00:05Z\"\n")
+	assert.True(t, strings.HasSuffix(string(out), "---\n\nRelease 1.2 is **out**.\n"))
+}
+
+func TestMessage_Errors(t *testing.T) {
+	_, err := export.Message(sentMessage("slack", "#general"), "pdf")
+	assert.ErrorIs(t, err, export.ErrUnsupportedFormat)
+
+	sm := sentMessage("slack", "#general")
+	sm.Snapshot = nil
+	_, err = export.Message(sm, export.FormatMarkdown)
+	assert.ErrorIs(t, err, export.ErrNoSnapshot)
+}
+
+func TestRender(t *testing.T) {
+	call := &model.Call{
+		Author:  "author@example.com",
+		Subject: "Release {{ .Version }}",
+		Content: "Release {{ .Version }} is out, as of {{ .ScheduledAt.Format \"2006-01-02\" }}.",
+		Data:    map[string]interface{}{"Version": "2.0"},
+	}
+
+	// The data recorded with the message is preferred to the current data of the call.
+	sm := sentMessage("slack", "#general")
+	sm.Snapshot = &kv.Snapshot{Data: map[string]interface{}{"Version": "1.2"}}
+	snapshot, err := export.Render(call, sm)
+	require.NoError(t, err)
+	assert.Equal(t, "Release 1.2", snapshot.Subject)
+	assert.Equal(t, "Release 1.2 is out, as of 2025-03-04.", snapshot.Content)
+
+	sm.Snapshot = nil
+	snapshot, err = export.Render(call, sm)
+	require.NoError(t, err)
+	assert.Equal(t, "Release 2.0", snapshot.Subject)
+	assert.Equal(t, "author@example.com", snapshot.Author)
+}
@@ -0,0 +1,308 @@
+// Package feed publishes the announcements sent for a campaign as an RSS, Atom or JSON feed, so that
+// intranet portals and aggregators can syndicate them.
+package feed
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/andrewhowdencom/ruf/internal/export"
+	"github.com/andrewhowdencom/ruf/internal/kv"
+	"github.com/andrewhowdencom/ruf/internal/processor"
+)
+
+// Err* are common errors returned when building feeds.
+var (
+	ErrUnsupportedFormat = errors.New("unsupported feed format")
+)
+
+// Feed formats.
+const (
+	FormatRSS  = "rss"
+	FormatAtom = "atom"
+	FormatJSON = "json"
+)
+
+// ContentTypes are the media types feeds are served with, by format.
+var ContentTypes = map[string]string{
+	FormatRSS:  "application/rss+xml; charset=utf-8",
+	FormatAtom: "application/atom+xml; charset=utf-8",
+	FormatJSON: "application/feed+json; charset=utf-8",
+}
+
+// Feed is the announcements sent for a campaign, most recent first.
+type Feed struct {
+	CampaignID string
+	Title      string
+	// Link is where the feed itself is served.
+	Link    string
+	Updated time.Time
+	Items   []Item
+}
+
+// Item is an announcement. A call sent to several destinations is a single item.
+type Item struct {
+	ID     string
+	Title  string
+	Author string
+	Link   string
+	HTML   string
+	Sent   time.Time
+}
+
+// New builds the feed of a campaign from its sent messages, keeping at most limit announcements. A
+// limit of zero or less keeps all of them. Only messages that were delivered are published.
+func New(campaignID, link string, messages []*kv.SentMessage, limit int) (*Feed, error) {
+	f := &Feed{CampaignID: campaignID, Title: campaignID, Link: link}
+
+	byOccurrence := make(map[string]*Item)
+	for _, sm := range messages {
+		if sm.Status != kv.StatusSent {
+			continue
+		}
+		if sm.CampaignName != "" {
+			f.Title = sm.CampaignName
+		}
+
+		// The copies of an occurrence sent to each destination only differ in the suffix of their ID.
+		occurrence := strings.TrimSuffix(sm.SourceID, ":"+sm.Type+":"+sm.Destination)
+		item, ok := byOccurrence[occurrence]
+		if !ok {
+			var err error
+			if item, err = newItem(occurrence, sm); err != nil {
+				return nil, err
+			}
+			byOccurrence[occurrence] = item
+		}
+		if sent := export.SentAt(sm); sent.Before(item.Sent) {
+			item.Sent = sent
+		}
+		if item.Link == "" {
+			item.Link = sm.Permalink
+		}
+	}
+
+	for _, item := range byOccurrence {
+		f.Items = append(f.Items, *item)
+	}
+	sort.Slice(f.Items, func(i, j int) bool {
+		if !f.Items[i].Sent.Equal(f.Items[j].Sent) {
+			return f.Items[i].Sent.After(f.Items[j].Sent)
+		}
+		return f.Items[i].ID < f.Items[j].ID
+	})
+	if limit > 0 && len(f.Items) > limit {
+		f.Items = f.Items[:limit]
+	}
+	if len(f.Items) > 0 {
+		f.Updated = f.Items[0].Sent
+	}
+	return f, nil
+}
+
+func newItem(occurrence string, sm *kv.SentMessage) (*Item, error) {
+	hash := sha256.Sum256([]byte(occurrence))
+	item := &Item{ID: "urn:ruf:" + hex.EncodeToString(hash[:]), Title: sm.CampaignName, Sent: export.SentAt(sm)}
+	if sm.Snapshot == nil {
+		return item, nil
+	}
+
+	item.Author = sm.Snapshot.Author
+	if sm.Snapshot.Subject != "" {
+		item.Title = sm.Snapshot.Subject
+	}
+	if sm.Snapshot.Content != "" {
+		html, err := processor.NewMarkdownToHTMLProcessor().Process(sm.Snapshot.Content, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to render '%s': %w", sm.ID, err)
+		}
+		item.HTML = html
+	}
+	return item, nil
+}
+
+// Encode encodes the feed in the given format.
+func (f *Feed) Encode(format string) ([]byte, error) {
+	switch format {
+	case FormatRSS:
+		return f.rss()
+	case FormatAtom:
+		return f.atom()
+	case FormatJSON:
+		return f.json()
+	default:
+		return nil, fmt.Errorf("%w: %s: must be one of %s, %s or %s", ErrUnsupportedFormat, format, FormatRSS, FormatAtom, FormatJSON)
+	}
+}
+
+type rssFeed struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	LastBuildDate string    `xml:"lastBuildDate,omitempty"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title       string  `xml:"title"`
+	Link        string  `xml:"link,omitempty"`
+	Description string  `xml:"description,omitempty"`
+	Author      string  `xml:"author,omitempty"`
+	GUID        rssGUID `xml:"guid"`
+	PubDate     string  `xml:"pubDate"`
+}
+
+type rssGUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+func (f *Feed) rss() ([]byte, error) {
+	channel := rssChannel{
+		Title:       f.Title,
+		Link:        f.Link,
+		Description: fmt.Sprintf("Announcements sent for %s.", f.Title),
+	}
+	if !f.Updated.IsZero() {
+		channel.LastBuildDate = f.Updated.Format(time.RFC1123Z)
+	}
+	for _, item := range f.Items {
+		channel.Items = append(channel.Items, rssItem{
+			Title:       item.Title,
+			Link:        item.Link,
+			Description: item.HTML,
+			Author:      item.Author,
+			GUID:        rssGUID{Value: item.ID},
+			PubDate:     item.Sent.Format(time.RFC1123Z),
+		})
+	}
+	return encodeXML(rssFeed{Version: "2.0", Channel: channel})
+}
+
+type atomFeed struct {
+	XMLName xml.Name `xml:"http://www.w3.org/2005/Atom feed"`
+	ID      string   `xml:"id"`
+	Title   string   `xml:"title"`
+	Updated string   `xml:"updated"`
+	Link    atomLink `xml:"link"`
+	// Author is required of a feed whose entries may have none, so it is the campaign.
+	Author  atomAuthor  `xml:"author"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+}
+
+type atomEntry struct {
+	ID      string       `xml:"id"`
+	Title   string       `xml:"title"`
+	Updated string       `xml:"updated"`
+	Link    *atomLink    `xml:"link,omitempty"`
+	Author  *atomAuthor  `xml:"author,omitempty"`
+	Content *atomContent `xml:"content,omitempty"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+}
+
+type atomContent struct {
+	Type  string `xml:"type,attr"`
+	Value string `xml:",chardata"`
+}
+
+func (f *Feed) atom() ([]byte, error) {
+	hash := sha256.Sum256([]byte(f.CampaignID))
+	feed := atomFeed{
+		ID:      "urn:ruf:campaign:" + hex.EncodeToString(hash[:]),
+		Title:   f.Title,
+		Updated: f.Updated.UTC().Format(time.RFC3339),
+		Link:    atomLink{Href: f.Link, Rel: "self"},
+		Author:  atomAuthor{Name: f.Title},
+	}
+	for _, item := range f.Items {
+		entry := atomEntry{ID: item.ID, Title: item.Title, Updated: item.Sent.UTC().Format(time.RFC3339)}
+		if item.Link != "" {
+			entry.Link = &atomLink{Href: item.Link}
+		}
+		if item.Author != "" {
+			entry.Author = &atomAuthor{Name: item.Author}
+		}
+		if item.HTML != "" {
+			entry.Content = &atomContent{Type: "html", Value: item.HTML}
+		}
+		feed.Entries = append(feed.Entries, entry)
+	}
+	return encodeXML(feed)
+}
+
+type jsonFeed struct {
+	Version string     `json:"version"`
+	Title   string     `json:"title"`
+	FeedURL string     `json:"feed_url,omitempty"`
+	Items   []jsonItem `json:"items"`
+}
+
+type jsonItem struct {
+	ID            string       `json:"id"`
+	URL           string       `json:"url,omitempty"`
+	Title         string       `json:"title,omitempty"`
+	ContentHTML   string       `json:"content_html,omitempty"`
+	ContentText   string       `json:"content_text,omitempty"`
+	DatePublished string       `json:"date_published"`
+	Authors       []jsonAuthor `json:"authors,omitempty"`
+}
+
+type jsonAuthor struct {
+	Name string `json:"name"`
+}
+
+func (f *Feed) json() ([]byte, error) {
+	feed := jsonFeed{Version: "https://jsonfeed.org/version/1.1", Title: f.Title, FeedURL: f.Link, Items: []jsonItem{}}
+	for _, item := range f.Items {
+		ji := jsonItem{
+			ID:            item.ID,
+			URL:           item.Link,
+			Title:         item.Title,
+			ContentHTML:   item.HTML,
+			DatePublished: item.Sent.UTC().Format(time.RFC3339),
+		}
+		// A JSON feed item must have content, so an announcement whose content was not recorded has
+		// its title as its text.
+		if ji.ContentHTML == "" {
+			ji.ContentText = item.Title
+		}
+		if item.Author != "" {
+			ji.Authors = []jsonAuthor{{Name: item.Author}}
+		}
+		feed.Items = append(feed.Items, ji)
+	}
+	b, err := json.MarshalIndent(feed, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(b, '\n'), nil
+}
+
+func encodeXML(v any) ([]byte, error) {
+	b, err := xml.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), append(b, '\n')...), nil
+}
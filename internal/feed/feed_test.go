@@ -0,0 +1,136 @@
+package feed_test
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/andrewhowdencom/ruf/internal/datastore"
+	"github.com/andrewhowdencom/ruf/internal/feed"
+	"github.com/andrewhowdencom/ruf/internal/kv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func sent(occurrence, destType, to string, at time.Time, status kv.Status) *kv.SentMessage {
+	return &kv.SentMessage{
+		SourceID:     occurrence + ":" + destType + ":" + to,
+		ScheduledAt:  at,
+		SentAt:       at.Add(time.Second),
+		Type:         destType,
+		Destination:  to,
+		Status:       status,
+		CampaignName: "Releases",
+		Snapshot:     &kv.Snapshot{Subject: "Release " + at.Format("Jan 2"), Content: "Release is **out**.", Author: "jane@example.com"},
+	}
+}
+
+func TestNew(t *testing.T) {
+	march := time.Date(2025, 3, 4, 9, 0, 0, 0, time.UTC)
+	april := time.Date(2025, 4, 1, 9, 0, 0, 0, time.UTC)
+	slackCopy := sent("release:cron:0 9 * * *:2025-03-04T09:00:00Z", "slack", "#general", march, kv.StatusSent)
+	slackCopy.Permalink = "https://example.slack.com/archives/C1/p1"
+	messages := []*kv.SentMessage{
+		sent("release:cron:0 9 * * *:2025-03-04T09:00:00Z", "email", "team@example.com", march, kv.StatusSent),
+		slackCopy,
+		sent("release:cron:0 9 * * *:2025-04-01T09:00:00Z", "email", "team@example.com", april, kv.StatusSent),
+		sent("release:cron:0 9 * * *:2025-05-06T09:00:00Z", "email", "team@example.com", april.AddDate(0, 1, 5), kv.StatusFailed),
+	}
+
+	f, err := feed.New("releases", "https://ruf.example.com/feeds/releases.atom", messages, 0)
+	require.NoError(t, err)
+	assert.Equal(t, "Releases", f.Title)
+	require.Len(t, f.Items, 2, "copies of an occurrence are one item, and failed messages are left out")
+	assert.Equal(t, "Release Apr 1", f.Items[0].Title)
+	assert.Equal(t, "Release Mar 4", f.Items[1].Title)
+	assert.Equal(t, "https://example.slack.com/archives/C1/p1", f.Items[1].Link)
+	assert.Contains(t, f.Items[1].HTML, "<strong>out</strong>")
+	assert.Equal(t, april.Add(time.Second), f.Updated)
+
+	limited, err := feed.New("releases", "", messages, 1)
+	require.NoError(t, err)
+	assert.Len(t, limited.Items, 1)
+}
+
+func TestEncode(t *testing.T) {
+	at := time.Date(2025, 3, 4, 9, 0, 0, 0, time.UTC)
+	f, err := feed.New("releases", "https://ruf.example.com/feeds/releases", []*kv.SentMessage{
+		sent("release:scheduled_at:2025-03-04T09:00:00Z", "email", "team@example.com", at, kv.StatusSent),
+	}, 0)
+	require.NoError(t, err)
+
+	t.Run("rss", func(t *testing.T) {
+		out, err := f.Encode(feed.FormatRSS)
+		require.NoError(t, err)
+		var rss struct {
+			Channel struct {
+				Title string `xml:"title"`
+				Items []struct {
+					Title       string `xml:"title"`
+					Description string `xml:"description"`
+					PubDate     string `xml:"pubDate"`
+				} `xml:"item"`
+			} `xml:"channel"`
+		}
+		require.NoError(t, xml.Unmarshal(out, &rss))
+		assert.Equal(t, "Releases", rss.Channel.Title)
+		require.Len(t, rss.Channel.Items, 1)
+		assert.Equal(t, "Release Mar 4", rss.Channel.Items[0].Title)
+		assert.Contains(t, rss.Channel.Items[0].Description, "<strong>out</strong>")
+		assert.Equal(t, "Tue, 04 Mar 2025 09:00:01 +0000", rss.Channel.Items[0].PubDate)
+	})
+
+	t.Run("atom", func(t *testing.T) {
+		out, err := f.Encode(feed.FormatAtom)
+		require.NoError(t, err)
+		assert.Contains(t, string(out), `<feed xmlns="http://www.w3.org/2005/Atom">`)
+		assert.Contains(t, string(out), `<updated>2025-03-04T09:00:01Z</updated>`)
+		assert.Contains(t, string(out), `<name>jane@example.com</name>`)
+	})
+
+	t.Run("json", func(t *testing.T) {
+		out, err := f.Encode(feed.FormatJSON)
+		require.NoError(t, err)
+		var jf struct {
+			Version string `json:"version"`
+			Items   []struct {
+				ID          string `json:"id"`
+				ContentHTML string `json:"content_html"`
+			} `json:"items"`
+		}
+		require.NoError(t, json.Unmarshal(out, &jf))
+		assert.Equal(t, "https://jsonfeed.org/version/1.1", jf.Version)
+		require.Len(t, jf.Items, 1)
+		assert.Contains(t, jf.Items[0].ID, "urn:ruf:")
+	})
+
+	_, err = f.Encode("csv")
+	assert.ErrorIs(t, err, feed.ErrUnsupportedFormat)
+}
+
+func TestHandler(t *testing.T) {
+	store := datastore.NewMockStore()
+	at := time.Date(2025, 3, 4, 9, 0, 0, 0, time.UTC)
+	sm := sent("release:scheduled_at:2025-03-04T09:00:00Z", "email", "team@example.com", at, kv.StatusSent)
+	require.NoError(t, store.AddSentMessage("releases", "release:scheduled_at:2025-03-04T09:00:00Z:email:team@example.com", sm))
+
+	handler := feed.NewHandler(store, feed.WithBaseURL("https://ruf.example.com/"), feed.WithCampaigns("releases"))
+	get := func(path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec
+	}
+
+	rec := get("/feeds/releases.atom")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/atom+xml; charset=utf-8", rec.Header().Get("Content-Type"))
+	assert.Equal(t, "Tue, 04 Mar 2025 09:00:01 GMT", rec.Header().Get("Last-Modified"))
+	assert.Contains(t, rec.Body.String(), `href="https://ruf.example.com/feeds/releases.atom"`)
+
+	assert.Equal(t, http.StatusOK, get("/feeds/releases.json").Code)
+	assert.Equal(t, http.StatusNotFound, get("/feeds/releases.csv").Code)
+	assert.Equal(t, http.StatusNotFound, get("/feeds/other.rss").Code)
+}
@@ -0,0 +1,98 @@
+package feed
+
+import (
+	"log/slog"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/andrewhowdencom/ruf/internal/kv"
+)
+
+// Option configures the feed handler.
+type Option func(*options)
+
+type options struct {
+	baseURL   string
+	limit     int
+	campaigns map[string]bool
+}
+
+// WithBaseURL sets the URL the handler is reached at, such as https://ruf.example.com, which feeds use
+// to link to themselves.
+func WithBaseURL(baseURL string) Option {
+	return func(o *options) {
+		o.baseURL = strings.TrimSuffix(baseURL, "/")
+	}
+}
+
+// WithLimit sets the number of announcements in each feed. Zero publishes all of them.
+func WithLimit(limit int) Option {
+	return func(o *options) {
+		o.limit = limit
+	}
+}
+
+// WithCampaigns only publishes the feeds of the given campaigns.
+func WithCampaigns(ids ...string) Option {
+	return func(o *options) {
+		if len(ids) == 0 {
+			return
+		}
+		o.campaigns = make(map[string]bool, len(ids))
+		for _, id := range ids {
+			o.campaigns[id] = true
+		}
+	}
+}
+
+// NewHandler returns the handler serving the feed of each campaign at /feeds/{campaign}.{format}, such
+// as /feeds/releases.atom.
+func NewHandler(store kv.Storer, opts ...Option) http.Handler {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /feeds/{file}", func(w http.ResponseWriter, r *http.Request) {
+		file := r.PathValue("file")
+		ext := path.Ext(file)
+		campaignID, format := strings.TrimSuffix(file, ext), strings.TrimPrefix(ext, ".")
+		contentType, ok := ContentTypes[format]
+		if !ok || campaignID == "" {
+			http.NotFound(w, r)
+			return
+		}
+		if o.campaigns != nil && !o.campaigns[campaignID] {
+			http.NotFound(w, r)
+			return
+		}
+
+		messages, err := store.ListSentMessagesByCampaign(campaignID)
+		if err != nil {
+			slog.Error("failed to list sent messages for feed", "campaign_id", campaignID, "error", err)
+			http.Error(w, "failed to list sent messages", http.StatusInternalServerError)
+			return
+		}
+		f, err := New(campaignID, o.baseURL+r.URL.Path, messages, o.limit)
+		if err != nil {
+			slog.Error("failed to build feed", "campaign_id", campaignID, "error", err)
+			http.Error(w, "failed to build feed", http.StatusInternalServerError)
+			return
+		}
+		body, err := f.Encode(format)
+		if err != nil {
+			slog.Error("failed to encode feed", "campaign_id", campaignID, "format", format, "error", err)
+			http.Error(w, "failed to encode feed", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", contentType)
+		if !f.Updated.IsZero() {
+			w.Header().Set("Last-Modified", f.Updated.UTC().Format(http.TimeFormat))
+		}
+		w.Write(body)
+	})
+	return mux
+}
@@ -0,0 +1,253 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/andrewhowdencom/ruf/internal/kv"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// Err* are common errors returned when checking the health of the scheduler.
+var (
+	ErrDegraded = errors.New("degraded")
+)
+
+// Snapshot is the state of the schedule at a point in time.
+type Snapshot struct {
+	// ScheduledCalls is the number of calls waiting to be sent.
+	ScheduledCalls int
+	// OldestOverdue is how long the oldest call that should already have been sent has been waiting.
+	OldestOverdue time.Duration
+	// SlotsRemaining is the number of default time slots left this week that no call has been scheduled in.
+	SlotsRemaining int
+	// RefreshAge is how long ago the schedule was last refreshed. It is zero if it has never been refreshed.
+	RefreshAge time.Duration
+	// TickAge is how long ago the leading worker last processed the schedule, as recorded in the status it
+	// writes to the datastore. Until a leading worker has, it is measured from when monitoring started.
+	TickAge time.Duration
+}
+
+// Thresholds are the limits beyond which the scheduler is considered degraded. Zero values disable a threshold.
+type Thresholds struct {
+	MinScheduledCalls int
+	MaxOldestOverdue  time.Duration
+	MinSlotsRemaining int
+	MaxRefreshAge     time.Duration
+	MaxTickAge        time.Duration
+}
+
+// Breaches returns a description of each threshold the snapshot breaches.
+func (t Thresholds) Breaches(s Snapshot) []string {
+	var breaches []string
+	if t.MinScheduledCalls > 0 && s.ScheduledCalls < t.MinScheduledCalls {
+		breaches = append(breaches, fmt.Sprintf("%d scheduled calls, expected at least %d", s.ScheduledCalls, t.MinScheduledCalls))
+	}
+	if t.MaxOldestOverdue > 0 && s.OldestOverdue > t.MaxOldestOverdue {
+		breaches = append(breaches, fmt.Sprintf("oldest overdue call is %s old, expected at most %s", s.OldestOverdue, t.MaxOldestOverdue))
+	}
+	if t.MinSlotsRemaining > 0 && s.SlotsRemaining < t.MinSlotsRemaining {
+		breaches = append(breaches, fmt.Sprintf("%d slots remaining this week, expected at least %d", s.SlotsRemaining, t.MinSlotsRemaining))
+	}
+	if t.MaxRefreshAge > 0 && s.RefreshAge > t.MaxRefreshAge {
+		breaches = append(breaches, fmt.Sprintf("schedule was last refreshed %s ago, expected at most %s", s.RefreshAge, t.MaxRefreshAge))
+	}
+	if t.MaxTickAge > 0 && s.TickAge > t.MaxTickAge {
+		breaches = append(breaches, fmt.Sprintf("schedule was last processed %s ago, expected at most %s", s.TickAge, t.MaxTickAge))
+	}
+	return breaches
+}
+
+// Monitor observes the schedule held in the datastore.
+type Monitor struct {
+	store      kv.Storer
+	thresholds Thresholds
+	slots      map[string][]string
+	location   *time.Location
+	now        func() time.Time
+
+	mu          sync.Mutex
+	lastRefresh time.Time
+	started     time.Time
+}
+
+// Option configures the Monitor.
+type Option func(*Monitor)
+
+// WithThresholds sets the thresholds beyond which the scheduler is considered degraded.
+func WithThresholds(thresholds Thresholds) Option {
+	return func(m *Monitor) {
+		m.thresholds = thresholds
+	}
+}
+
+// WithSlots sets the default time slots, by lowercase day of the week, and the location they are in.
+func WithSlots(slots map[string][]string, location *time.Location) Option {
+	return func(m *Monitor) {
+		m.slots = slots
+		m.location = location
+	}
+}
+
+// WithClock sets the function used to determine the current time.
+func WithClock(now func() time.Time) Option {
+	return func(m *Monitor) {
+		m.now = now
+	}
+}
+
+// NewMonitor creates a new Monitor for the schedule held in the store.
+func NewMonitor(store kv.Storer, opts ...Option) *Monitor {
+	m := &Monitor{
+		store:    store,
+		location: time.UTC,
+		now:      time.Now,
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	m.started = m.now()
+	return m
+}
+
+// RecordRefresh records that the schedule was refreshed at the given time.
+func (m *Monitor) RecordRefresh(at time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.lastRefresh = at
+}
+
+// Snapshot reads the current state of the schedule.
+func (m *Monitor) Snapshot() (Snapshot, error) {
+	calls, err := m.store.ListScheduledCalls()
+	if err != nil {
+		return Snapshot{}, fmt.Errorf("failed to list scheduled calls: %w", err)
+	}
+
+	now := m.now()
+	s := Snapshot{ScheduledCalls: len(calls)}
+
+	occupied := make(map[time.Time]bool)
+	for _, call := range calls {
+		if call.ScheduledAt.IsZero() {
+			// Calls waiting for another call to be sent are not due yet.
+			continue
+		}
+		if call.ScheduledAt.Before(now) {
+			if overdue := now.Sub(call.ScheduledAt); overdue > s.OldestOverdue {
+				s.OldestOverdue = overdue
+			}
+		}
+		occupied[call.ScheduledAt.UTC()] = true
+	}
+
+	s.SlotsRemaining = m.slotsRemaining(now, occupied)
+
+	m.mu.Lock()
+	lastRefresh := m.lastRefresh
+	if lastRefresh.IsZero() {
+		// Until the first refresh completes, the age is measured from when monitoring started.
+		lastRefresh = m.started
+	}
+	m.mu.Unlock()
+	s.RefreshAge = now.Sub(lastRefresh)
+
+	statuses, err := m.store.ListWorkerStatuses()
+	if err != nil {
+		return Snapshot{}, fmt.Errorf("failed to list worker statuses: %w", err)
+	}
+	var lastTick time.Time
+	for _, ws := range statuses {
+		// A worker that has stopped, or is standing by, is not processing the schedule.
+		if ws.Leader && ws.StoppedAt.IsZero() && ws.LastTick.After(lastTick) {
+			lastTick = ws.LastTick
+		}
+	}
+	if lastTick.IsZero() {
+		lastTick = m.started
+	}
+	s.TickAge = now.Sub(lastTick)
+
+	return s, nil
+}
+
+// slotsRemaining counts the slots between now and the end of the week (Sunday) that are not occupied.
+func (m *Monitor) slotsRemaining(now time.Time, occupied map[time.Time]bool) int {
+	local := now.In(m.location)
+	daysLeft := (7 - int(local.Weekday())) % 7
+
+	remaining := 0
+	for i := 0; i <= daysLeft; i++ {
+		day := local.AddDate(0, 0, i)
+		for _, slot := range m.slots[strings.ToLower(day.Weekday().String())] {
+			t, err := time.Parse("15:04", slot)
+			if err != nil {
+				continue
+			}
+			at := time.Date(day.Year(), day.Month(), day.Day(), t.Hour(), t.Minute(), 0, 0, m.location)
+			if at.After(now) && !occupied[at.UTC()] {
+				remaining++
+			}
+		}
+	}
+	return remaining
+}
+
+// Check returns an error wrapping ErrDegraded if any threshold is breached.
+func (m *Monitor) Check() error {
+	s, err := m.Snapshot()
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrDegraded, err)
+	}
+	if breaches := m.thresholds.Breaches(s); len(breaches) > 0 {
+		return fmt.Errorf("%w: %s", ErrDegraded, strings.Join(breaches, "; "))
+	}
+	return nil
+}
+
+// RegisterMetrics registers gauges describing the schedule with the meter.
+func (m *Monitor) RegisterMetrics(meter metric.Meter) error {
+	scheduledCalls, err := meter.Int64ObservableGauge("ruf.schedule.calls",
+		metric.WithDescription("The number of calls waiting to be sent."))
+	if err != nil {
+		return err
+	}
+	oldestOverdue, err := meter.Float64ObservableGauge("ruf.schedule.oldest_overdue",
+		metric.WithDescription("How long the oldest overdue call has been waiting."), metric.WithUnit("s"))
+	if err != nil {
+		return err
+	}
+	slotsRemaining, err := meter.Int64ObservableGauge("ruf.schedule.slots_remaining",
+		metric.WithDescription("The number of default time slots left this week that no call has been scheduled in."))
+	if err != nil {
+		return err
+	}
+	refreshAge, err := meter.Float64ObservableGauge("ruf.schedule.refresh_age",
+		metric.WithDescription("How long ago the schedule was last refreshed."), metric.WithUnit("s"))
+	if err != nil {
+		return err
+	}
+	tickAge, err := meter.Float64ObservableGauge("ruf.worker.tick_age",
+		metric.WithDescription("How long ago the leading worker last processed the schedule."), metric.WithUnit("s"))
+	if err != nil {
+		return err
+	}
+
+	_, err = meter.RegisterCallback(func(_ context.Context, o metric.Observer) error {
+		s, err := m.Snapshot()
+		if err != nil {
+			return err
+		}
+		o.ObserveInt64(scheduledCalls, int64(s.ScheduledCalls))
+		o.ObserveFloat64(oldestOverdue, s.OldestOverdue.Seconds())
+		o.ObserveInt64(slotsRemaining, int64(s.SlotsRemaining))
+		o.ObserveFloat64(refreshAge, s.RefreshAge.Seconds())
+		o.ObserveFloat64(tickAge, s.TickAge.Seconds())
+		return nil
+	}, scheduledCalls, oldestOverdue, slotsRemaining, refreshAge, tickAge)
+	return err
+}
@@ -0,0 +1,82 @@
+package health_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/andrewhowdencom/ruf/internal/datastore"
+	"github.com/andrewhowdencom/ruf/internal/health"
+	"github.com/andrewhowdencom/ruf/internal/kv"
+	"github.com/andrewhowdencom/ruf/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMonitor_Snapshot(t *testing.T) {
+	// 2025-01-03 is a Friday.
+	now := time.Date(2025, 1, 3, 12, 0, 0, 0, time.UTC)
+
+	store := datastore.NewMockStore()
+	assert.NoError(t, store.AddScheduledCalls([]*kv.ScheduledCall{
+		{Call: model.Call{ID: "overdue"}, ScheduledAt: now.Add(-30 * time.Minute)},
+		{Call: model.Call{ID: "slotted"}, ScheduledAt: time.Date(2025, 1, 3, 14, 0, 0, 0, time.UTC)},
+	}))
+
+	monitor := health.NewMonitor(store,
+		health.WithClock(func() time.Time { return now }),
+		health.WithSlots(map[string][]string{
+			"friday":   {"09:00", "14:00", "16:00"},
+			"saturday": {"10:00"},
+			"monday":   {"09:00"},
+		}, time.UTC),
+	)
+	monitor.RecordRefresh(now.Add(-5 * time.Minute))
+
+	// Only the running leader processes the schedule.
+	assert.NoError(t, store.SetWorkerStatus(&kv.WorkerStatus{Holder: "leader", Leader: true, LastTick: now.Add(-2 * time.Minute)}))
+	assert.NoError(t, store.SetWorkerStatus(&kv.WorkerStatus{Holder: "stopped", Leader: true, LastTick: now.Add(-time.Minute), StoppedAt: now.Add(-time.Minute)}))
+	assert.NoError(t, store.SetWorkerStatus(&kv.WorkerStatus{Holder: "standby", LastTick: now}))
+
+	s, err := monitor.Snapshot()
+	assert.NoError(t, err)
+	assert.Equal(t, health.Snapshot{
+		ScheduledCalls: 2,
+		OldestOverdue:  30 * time.Minute,
+		SlotsRemaining: 2, // Friday 16:00 and Saturday 10:00
+		RefreshAge:     5 * time.Minute,
+		TickAge:        2 * time.Minute,
+	}, s)
+}
+
+func TestMonitor_Check(t *testing.T) {
+	now := time.Date(2025, 1, 3, 12, 0, 0, 0, time.UTC)
+	store := datastore.NewMockStore()
+	assert.NoError(t, store.AddScheduledCall(&kv.ScheduledCall{Call: model.Call{ID: "overdue"}, ScheduledAt: now.Add(-2 * time.Hour)}))
+
+	monitor := health.NewMonitor(store, health.WithClock(func() time.Time { return now }))
+	assert.NoError(t, monitor.Check())
+
+	monitor = health.NewMonitor(store,
+		health.WithClock(func() time.Time { return now }),
+		health.WithThresholds(health.Thresholds{MaxOldestOverdue: time.Hour}),
+	)
+	err := monitor.Check()
+	assert.ErrorIs(t, err, health.ErrDegraded)
+	assert.ErrorContains(t, err, "oldest overdue call is 2h0m0s old")
+}
+
+func TestMonitor_CheckTickAge(t *testing.T) {
+	now := time.Date(2025, 1, 3, 12, 0, 0, 0, time.UTC)
+	store := datastore.NewMockStore()
+	assert.NoError(t, store.SetWorkerStatus(&kv.WorkerStatus{Holder: "leader", Leader: true, LastTick: now.Add(-10 * time.Minute)}))
+
+	monitor := health.NewMonitor(store,
+		health.WithClock(func() time.Time { return now }),
+		health.WithThresholds(health.Thresholds{MaxTickAge: 5 * time.Minute}),
+	)
+	err := monitor.Check()
+	assert.ErrorIs(t, err, health.ErrDegraded)
+	assert.ErrorContains(t, err, "schedule was last processed 10m0s ago")
+
+	assert.NoError(t, store.SetWorkerStatus(&kv.WorkerStatus{Holder: "leader", Leader: true, LastTick: now.Add(-time.Minute)}))
+	assert.NoError(t, monitor.Check())
+}
@@ -6,17 +6,62 @@ import (
 	"net/http"
 )
 
+// Option configures the healthcheck server.
+type Option func(*server)
+
+type server struct {
+	check    func() error
+	handlers map[string]http.Handler
+}
+
+// WithHealthCheck sets a check that reports the server as degraded when it returns an error.
+func WithHealthCheck(check func() error) Option {
+	return func(s *server) {
+		s.check = check
+	}
+}
+
+// WithHandler serves the handler for the pattern, alongside the healthcheck endpoints.
+func WithHandler(pattern string, handler http.Handler) Option {
+	return func(s *server) {
+		if s.handlers == nil {
+			s.handlers = make(map[string]http.Handler)
+		}
+		s.handlers[pattern] = handler
+	}
+}
+
 // Start starts the healthcheck server on the given port.
-func Start(port int) {
+func Start(port int, opts ...Option) {
+	addr := fmt.Sprintf(":%d", port)
+	slog.Info("starting healthcheck server", "addr", addr)
+	if err := http.ListenAndServe(addr, NewHandler(opts...)); err != nil {
+		slog.Error("healthcheck server failed", "err", err)
+	}
+}
+
+// NewHandler returns the handler serving the healthcheck endpoints.
+func NewHandler(opts ...Option) http.Handler {
+	s := &server{}
+	for _, opt := range opts {
+		opt(s)
+	}
+
 	mux := http.NewServeMux()
 	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		if s.check != nil {
+			if err := s.check(); err != nil {
+				slog.Warn("healthcheck failed", "error", err)
+				w.WriteHeader(http.StatusServiceUnavailable)
+				fmt.Fprintf(w, "DEGRADED: %s", err)
+				return
+			}
+		}
 		w.WriteHeader(http.StatusOK)
 		fmt.Fprint(w, "OK")
 	})
-
-	addr := fmt.Sprintf(":%d", port)
-	slog.Info("starting healthcheck server", "addr", addr)
-	if err := http.ListenAndServe(addr, mux); err != nil {
-		slog.Error("healthcheck server failed", "err", err)
+	for pattern, handler := range s.handlers {
+		mux.Handle(pattern, handler)
 	}
+	return mux
 }
@@ -0,0 +1,42 @@
+package http_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	rufhttp "github.com/andrewhowdencom/ruf/internal/http"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHealthz(t *testing.T) {
+	t.Run("healthy", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		rufhttp.NewHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "OK", rec.Body.String())
+	})
+
+	t.Run("degraded", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		handler := rufhttp.NewHandler(rufhttp.WithHealthCheck(func() error { return errors.New("schedule is stale") }))
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+		assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+		assert.Equal(t, "DEGRADED: schedule is stale", rec.Body.String())
+	})
+}
+
+func TestWithHandler(t *testing.T) {
+	handler := rufhttp.NewHandler(rufhttp.WithHandler("/checklist/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	})))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/checklist/abc", nil))
+	assert.Equal(t, http.StatusTeapot, rec.Code)
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
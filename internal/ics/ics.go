@@ -0,0 +1,186 @@
+// Package ics publishes the schedule of calls as an iCalendar (RFC 5545) file, so that teams can
+// subscribe to the announcements they are about to be sent in their calendars.
+package ics
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/andrewhowdencom/ruf/internal/kv"
+	"github.com/andrewhowdencom/ruf/internal/model"
+)
+
+// dateTimeFormat is the format of times in UTC in iCalendar.
+const dateTimeFormat = "20060102T150405Z"
+
+// maxLineLength is the longest a line of content is, in octets, before it is folded.
+const maxLineLength = 75
+
+// Calendar is the calls scheduled to be sent, soonest first, and in the order of their UID when they
+// are sent at the same time.
+type Calendar struct {
+	Name string
+	// Stamp is when the calendar was made.
+	Stamp  time.Time
+	Events []Event
+}
+
+// Event is an occurrence of a call. The copies of a call sent to each of its destinations at the same
+// time are a single event.
+type Event struct {
+	// UID stays the same for an occurrence across exports while it is not moved, so that subscribed
+	// calendars update it rather than add it again.
+	UID         string
+	Summary     string
+	Description string
+	// Location lists the destinations the call is sent to.
+	Location string
+	Campaign string
+	Start    time.Time
+}
+
+// New builds the calendar of the scheduled calls. Calls that wait for another call to be sent are left
+// out, as they have no time to be shown at yet.
+func New(name string, calls []*kv.ScheduledCall, stamp time.Time) *Calendar {
+	c := &Calendar{Name: name, Stamp: stamp}
+	var (
+		keys    []string
+		byKey   = make(map[string]*Event)
+		destsOf = make(map[string][]model.Destination)
+	)
+	for _, call := range calls {
+		if call.ScheduledAt.IsZero() {
+			continue
+		}
+		// The copies of an occurrence sent to each destination only differ in the suffix of their ID.
+		occurrence := call.ID
+		if len(call.Destinations) == 1 && len(call.Destinations[0].To) > 0 {
+			occurrence = strings.TrimSuffix(occurrence, ":"+call.Destinations[0].Type+":"+call.Destinations[0].To[0])
+		}
+		key := call.Campaign.ID + "/" + occurrence + "@" + call.ScheduledAt.UTC().Format(time.RFC3339)
+		if _, ok := byKey[key]; !ok {
+			hash := sha256.Sum256([]byte(key))
+			event := &Event{
+				UID:         hex.EncodeToString(hash[:16]) + "@ruf",
+				Summary:     call.Subject,
+				Description: call.Content,
+				Campaign:    call.Campaign.Name,
+				Start:       call.ScheduledAt,
+			}
+			if event.Summary == "" {
+				event.Summary = call.ID
+			}
+			if event.Campaign == "" {
+				event.Campaign = call.Campaign.ID
+			}
+			keys = append(keys, key)
+			byKey[key] = event
+		}
+		destsOf[key] = append(destsOf[key], call.Destinations...)
+	}
+
+	for _, key := range keys {
+		event := byKey[key]
+		event.Location = location(destsOf[key])
+		c.Events = append(c.Events, *event)
+	}
+	sort.Slice(c.Events, func(i, j int) bool {
+		if !c.Events[i].Start.Equal(c.Events[j].Start) {
+			return c.Events[i].Start.Before(c.Events[j].Start)
+		}
+		return c.Events[i].UID < c.Events[j].UID
+	})
+	return c
+}
+
+// location lists the destinations, with those of the same type together, sorted so that the location
+// does not depend on the order the copies of the call were listed in.
+func location(dests []model.Destination) string {
+	var types []string
+	byType := make(map[string][]string)
+	for _, d := range dests {
+		if _, ok := byType[d.Type]; !ok {
+			types = append(types, d.Type)
+		}
+		for _, to := range d.To {
+			if !slices.Contains(byType[d.Type], to) {
+				byType[d.Type] = append(byType[d.Type], to)
+			}
+		}
+	}
+	sort.Strings(types)
+	parts := make([]string, 0, len(types))
+	for _, t := range types {
+		sort.Strings(byType[t])
+		parts = append(parts, fmt.Sprintf("%s: %s", t, strings.Join(byType[t], ", ")))
+	}
+	return strings.Join(parts, "; ")
+}
+
+// Encode encodes the calendar as an iCalendar file. Events have no end, so that they are shown as the
+// moment the call is sent.
+func (c *Calendar) Encode() []byte {
+	var buf bytes.Buffer
+	write := func(name, value string) {
+		fold(&buf, name+":"+value)
+	}
+
+	write("BEGIN", "VCALENDAR")
+	write("VERSION", "2.0")
+	write("PRODID", "-//ruf//ruf//EN")
+	write("CALSCALE", "GREGORIAN")
+	write("METHOD", "PUBLISH")
+	if c.Name != "" {
+		write("X-WR-CALNAME", escape(c.Name))
+	}
+	for _, e := range c.Events {
+		write("BEGIN", "VEVENT")
+		write("UID", e.UID)
+		write("DTSTAMP", c.Stamp.UTC().Format(dateTimeFormat))
+		write("DTSTART", e.Start.UTC().Format(dateTimeFormat))
+		write("SUMMARY", escape(e.Summary))
+		if e.Description != "" {
+			write("DESCRIPTION", escape(e.Description))
+		}
+		if e.Location != "" {
+			write("LOCATION", escape(e.Location))
+		}
+		if e.Campaign != "" {
+			write("CATEGORIES", escape(e.Campaign))
+		}
+		write("END", "VEVENT")
+	}
+	write("END", "VCALENDAR")
+	return buf.Bytes()
+}
+
+// escape escapes text for the value of a property.
+func escape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`, "\r", `\n`).Replace(s)
+}
+
+// fold writes a line of content, folded so that no line is longer than maxLineLength octets, without
+// splitting a character across lines.
+func fold(buf *bytes.Buffer, line string) {
+	limit := maxLineLength
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		buf.WriteString(line[:cut])
+		buf.WriteString("\r\n ")
+		line = line[cut:]
+		// Continuation lines start with a space, which counts towards their length.
+		limit = maxLineLength - 1
+	}
+	buf.WriteString(line)
+	buf.WriteString("\r\n")
+}
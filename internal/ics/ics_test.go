@@ -0,0 +1,73 @@
+package ics_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/andrewhowdencom/ruf/internal/ics"
+	"github.com/andrewhowdencom/ruf/internal/kv"
+	"github.com/andrewhowdencom/ruf/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	campaign := model.Campaign{ID: "releases", Name: "Releases"}
+	later := time.Date(2025, 3, 5, 9, 0, 0, 0, time.UTC)
+	sooner := time.Date(2025, 3, 4, 9, 0, 0, 0, time.UTC)
+	scheduled := func(id, destType, to string, at time.Time) *kv.ScheduledCall {
+		return &kv.ScheduledCall{Call: model.Call{
+			ID:           id + ":" + destType + ":" + to,
+			Subject:      "Release",
+			Campaign:     campaign,
+			Destinations: []model.Destination{{Type: destType, To: []string{to}}},
+		}, ScheduledAt: at}
+	}
+	calls := []*kv.ScheduledCall{
+		scheduled("release:cron:0 9 * * *:2025-03-05T09:00:00Z", "slack", "#general", later),
+		scheduled("release:cron:0 9 * * *:2025-03-05T09:00:00Z", "email", "team@example.com", later),
+		scheduled("release:cron:0 9 * * *:2025-03-05T09:00:00Z", "slack", "#eng", later),
+		{Call: model.Call{ID: "release:0", Campaign: model.Campaign{ID: "ops"}}, ScheduledAt: sooner},
+		{Call: model.Call{ID: "follow-up", Campaign: campaign, DependsOn: &model.Dependency{CallID: "release"}}},
+	}
+
+	c := ics.New("ruf", calls, sooner)
+	require.Len(t, c.Events, 2, "copies of an occurrence are one event, and calls waiting for another call are left out")
+	assert.Equal(t, sooner, c.Events[0].Start)
+	assert.Equal(t, "release:0", c.Events[0].Summary, "calls without a subject are summarised by their ID")
+	assert.Equal(t, "ops", c.Events[0].Campaign)
+	assert.Equal(t, "email: team@example.com; slack: #eng, #general", c.Events[1].Location)
+	assert.Equal(t, "Release", c.Events[1].Summary)
+	assert.Equal(t, "Releases", c.Events[1].Campaign)
+	assert.NotEqual(t, c.Events[0].UID, c.Events[1].UID)
+	assert.Equal(t, c.Events[1].UID, ics.New("ruf", calls[2:3], later).Events[0].UID, "the UID of an occurrence is stable")
+}
+
+func TestEncode(t *testing.T) {
+	at := time.Date(2025, 3, 4, 9, 0, 0, 0, time.FixedZone("CET", 3600))
+	c := &ics.Calendar{Name: "Team, announcements", Stamp: at, Events: []ics.Event{{
+		UID:         "abc@ruf",
+		Summary:     "Release; today",
+		Description: "Line one\nLine two, with a \\ backslash. " + strings.Repeat("é", 40),
+		Location:    "slack: #general",
+		Campaign:    "Releases",
+		Start:       at,
+	}}}
+
+	out := string(c.Encode())
+	assert.True(t, strings.HasPrefix(out, "BEGIN:VCALENDAR\r\nVERSION:2.0\r\n"))
+	assert.True(t, strings.HasSuffix(out, "END:VEVENT\r\nEND:VCALENDAR\r\n"))
+	assert.Contains(t, out, "X-WR-CALNAME:Team\\, announcements\r\n")
+	assert.Contains(t, out, "DTSTART:20250304T080000Z\r\n")
+	assert.Contains(t, out, "DTSTAMP:20250304T080000Z\r\n")
+	assert.Contains(t, out, "SUMMARY:Release\\; today\r\n")
+	assert.Contains(t, out, "CATEGORIES:Releases\r\n")
+	assert.Contains(t, out, "DESCRIPTION:Line one\\nLine two\\, with a \\\\ backslash. é")
+
+	for _, line := range strings.Split(strings.TrimSuffix(out, "\r\n"), "\r\n") {
+		assert.LessOrEqual(t, len(line), 75, line)
+	}
+	unfolded := strings.ReplaceAll(out, "\r\n ", "")
+	assert.Contains(t, unfolded, strings.Repeat("é", 40)+"\r\n", "folding does not split characters")
+}
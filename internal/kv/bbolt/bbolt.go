@@ -1,8 +1,10 @@
 package bbolt
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"sort"
 	"strings"
 	"time"
 
@@ -12,10 +14,23 @@ import (
 )
 
 var (
-	sentMessagesBucket   = []byte("sent_messages")
-	scheduledCallsBucket = []byte("scheduled_calls")
-	slotsBucket          = []byte("slots")
-	metaBucket           = []byte("meta")
+	sentMessagesBucket     = []byte("sent_messages")
+	scheduledCallsBucket   = []byte("scheduled_calls")
+	slotsBucket            = []byte("slots")
+	metaBucket             = []byte("meta")
+	triggerOverridesBucket = []byte("trigger_overrides")
+	seriesOverridesBucket  = []byte("series_overrides")
+	// sentTimelineBucket indexes sent messages by destination and the time they were scheduled for.
+	sentTimelineBucket  = []byte("sent_timeline")
+	leasesBucket        = []byte("leases")
+	signoffsBucket      = []byte("signoffs")
+	cancellationsBucket = []byte("cancellations")
+	reschedulesBucket   = []byte("reschedules")
+	approvalsBucket     = []byte("approvals")
+	confirmationsBucket = []byte("confirmations")
+	deadLettersBucket   = []byte("dead_letters")
+	workersBucket       = []byte("workers")
+	sourcesBucket       = []byte("sources")
 )
 
 // Store manages the persistence of calls.
@@ -71,6 +86,42 @@ func newStore(dbPath string, readOnly bool) (kv.Storer, error) {
 			if _, err := tx.CreateBucketIfNotExists(metaBucket); err != nil {
 				return fmt.Errorf("%w: failed to create bucket '%s': %w", kv.ErrDBOperationFailed, metaBucket, err)
 			}
+			if _, err := tx.CreateBucketIfNotExists(triggerOverridesBucket); err != nil {
+				return fmt.Errorf("%w: failed to create bucket '%s': %w", kv.ErrDBOperationFailed, triggerOverridesBucket, err)
+			}
+			if _, err := tx.CreateBucketIfNotExists(seriesOverridesBucket); err != nil {
+				return fmt.Errorf("%w: failed to create bucket '%s': %w", kv.ErrDBOperationFailed, seriesOverridesBucket, err)
+			}
+			if _, err := tx.CreateBucketIfNotExists(leasesBucket); err != nil {
+				return fmt.Errorf("%w: failed to create bucket '%s': %w", kv.ErrDBOperationFailed, leasesBucket, err)
+			}
+			if _, err := tx.CreateBucketIfNotExists(signoffsBucket); err != nil {
+				return fmt.Errorf("%w: failed to create bucket '%s': %w", kv.ErrDBOperationFailed, signoffsBucket, err)
+			}
+			if _, err := tx.CreateBucketIfNotExists(cancellationsBucket); err != nil {
+				return fmt.Errorf("%w: failed to create bucket '%s': %w", kv.ErrDBOperationFailed, cancellationsBucket, err)
+			}
+			if _, err := tx.CreateBucketIfNotExists(reschedulesBucket); err != nil {
+				return fmt.Errorf("%w: failed to create bucket '%s': %w", kv.ErrDBOperationFailed, reschedulesBucket, err)
+			}
+			if _, err := tx.CreateBucketIfNotExists(approvalsBucket); err != nil {
+				return fmt.Errorf("%w: failed to create bucket '%s': %w", kv.ErrDBOperationFailed, approvalsBucket, err)
+			}
+			if _, err := tx.CreateBucketIfNotExists(confirmationsBucket); err != nil {
+				return fmt.Errorf("%w: failed to create bucket '%s': %w", kv.ErrDBOperationFailed, confirmationsBucket, err)
+			}
+			if _, err := tx.CreateBucketIfNotExists(deadLettersBucket); err != nil {
+				return fmt.Errorf("%w: failed to create bucket '%s': %w", kv.ErrDBOperationFailed, deadLettersBucket, err)
+			}
+			if _, err := tx.CreateBucketIfNotExists(workersBucket); err != nil {
+				return fmt.Errorf("%w: failed to create bucket '%s': %w", kv.ErrDBOperationFailed, workersBucket, err)
+			}
+			if _, err := tx.CreateBucketIfNotExists(sourcesBucket); err != nil {
+				return fmt.Errorf("%w: failed to create bucket '%s': %w", kv.ErrDBOperationFailed, sourcesBucket, err)
+			}
+			if tx.Bucket(sentTimelineBucket) == nil {
+				return buildTimeline(tx)
+			}
 			return nil
 		})
 		if err != nil {
@@ -89,19 +140,10 @@ func (s *Store) Close() error {
 // AddSentMessage adds a new sent message to the store.
 func (s *Store) AddSentMessage(campaignID, callID string, sm *kv.SentMessage) error {
 	err := s.db.Update(func(tx *bbolt.Tx) error {
-		b := tx.Bucket(sentMessagesBucket)
-		sm.ID = s.generateID(campaignID, callID, sm.Type, sm.Destination)
+		sm.ID = kv.GenerateID(campaignID, callID, sm.OccurredAt, sm.Type, sm.Destination)
 		sm.ShortID = kv.GenerateShortID(sm.ID)
-
-		buf, err := json.Marshal(sm)
-		if err != nil {
-			return fmt.Errorf("%w: failed to marshal sent message: %w", kv.ErrSerializationFailed, err)
-		}
-
-		if err := b.Put([]byte(sm.ID), buf); err != nil {
-			return fmt.Errorf("%w: failed to put sent message: %w", kv.ErrDBOperationFailed, err)
-		}
-		return nil
+		sm.CampaignID = campaignID
+		return putSentMessage(tx, sm)
 	})
 	return err
 }
@@ -109,13 +151,64 @@ func (s *Store) AddSentMessage(campaignID, callID string, sm *kv.SentMessage) er
 // UpdateSentMessage updates an existing sent message in the store.
 func (s *Store) UpdateSentMessage(sm *kv.SentMessage) error {
 	return s.db.Update(func(tx *bbolt.Tx) error {
-		b := tx.Bucket(sentMessagesBucket)
-		buf, err := json.Marshal(sm)
-		if err != nil {
-			return fmt.Errorf("%w: failed to marshal sent message: %w", kv.ErrSerializationFailed, err)
+		return putSentMessage(tx, sm)
+	})
+}
+
+// putSentMessage stores a sent message and moves its entry in the timeline to match it.
+func putSentMessage(tx *bbolt.Tx, sm *kv.SentMessage) error {
+	b := tx.Bucket(sentMessagesBucket)
+	timeline := tx.Bucket(sentTimelineBucket)
+
+	var previous *kv.SentMessage
+	if v := b.Get([]byte(sm.ID)); v != nil {
+		previous = &kv.SentMessage{}
+		if err := json.Unmarshal(v, previous); err != nil {
+			return fmt.Errorf("%w: failed to unmarshal sent message: %w", kv.ErrSerializationFailed, err)
 		}
-		if err := b.Put([]byte(sm.ID), buf); err != nil {
-			return fmt.Errorf("%w: failed to put sent message: %w", kv.ErrDBOperationFailed, err)
+		if err := timeline.Delete(timelineKey(previous)); err != nil {
+			return fmt.Errorf("%w: failed to delete timeline entry: %w", kv.ErrDBOperationFailed, err)
+		}
+	}
+	sm.Supersede(previous, time.Now())
+
+	buf, err := json.Marshal(sm)
+	if err != nil {
+		return fmt.Errorf("%w: failed to marshal sent message: %w", kv.ErrSerializationFailed, err)
+	}
+	if err := b.Put([]byte(sm.ID), buf); err != nil {
+		return fmt.Errorf("%w: failed to put sent message: %w", kv.ErrDBOperationFailed, err)
+	}
+	if err := timeline.Put(timelineKey(sm), []byte(sm.ID)); err != nil {
+		return fmt.Errorf("%w: failed to put timeline entry: %w", kv.ErrDBOperationFailed, err)
+	}
+	return nil
+}
+
+// timelineKey returns the key of a sent message in the timeline. Keys sort by destination, then
+// by the time the message was scheduled for.
+func timelineKey(sm *kv.SentMessage) []byte {
+	return []byte(timelinePrefix(sm.Destination) + sm.ScheduledAt.UTC().Format("2006-01-02T15:04:05.000000000Z") + "\x00" + sm.ID)
+}
+
+// timelinePrefix returns the prefix shared by the timeline keys of every message sent to a destination.
+func timelinePrefix(destination string) string {
+	return destination + "\x00"
+}
+
+// buildTimeline creates the timeline, indexing the messages sent before it existed.
+func buildTimeline(tx *bbolt.Tx) error {
+	timeline, err := tx.CreateBucket(sentTimelineBucket)
+	if err != nil {
+		return fmt.Errorf("%w: failed to create bucket '%s': %w", kv.ErrDBOperationFailed, sentTimelineBucket, err)
+	}
+	return tx.Bucket(sentMessagesBucket).ForEach(func(k, v []byte) error {
+		var sm kv.SentMessage
+		if err := json.Unmarshal(v, &sm); err != nil {
+			return fmt.Errorf("%w: failed to unmarshal sent message: %w", kv.ErrSerializationFailed, err)
+		}
+		if err := timeline.Put(timelineKey(&sm), k); err != nil {
+			return fmt.Errorf("%w: failed to put timeline entry: %w", kv.ErrDBOperationFailed, err)
 		}
 		return nil
 	})
@@ -136,6 +229,23 @@ func (s *Store) AddScheduledCall(call *kv.ScheduledCall) error {
 	})
 }
 
+// AddScheduledCalls adds a batch of scheduled calls to the store in a single transaction.
+func (s *Store) AddScheduledCalls(calls []*kv.ScheduledCall) error {
+	return s.db.Update(func(tx *bbolt.Tx) error {
+		b := tx.Bucket(scheduledCallsBucket)
+		for _, call := range calls {
+			buf, err := json.Marshal(call)
+			if err != nil {
+				return fmt.Errorf("%w: failed to marshal scheduled call: %w", kv.ErrSerializationFailed, err)
+			}
+			if err := b.Put([]byte(call.ID), buf); err != nil {
+				return fmt.Errorf("%w: failed to put scheduled call: %w", kv.ErrDBOperationFailed, err)
+			}
+		}
+		return nil
+	})
+}
+
 func (s *Store) GetScheduledCall(id string) (*kv.ScheduledCall, error) {
 	var call kv.ScheduledCall
 	err := s.db.View(func(tx *bbolt.Tx) error {
@@ -200,6 +310,555 @@ func (s *Store) ClearScheduledCalls() error {
 	})
 }
 
+// ReplaceSchedule replaces all scheduled calls and slot reservations in a single transaction.
+func (s *Store) ReplaceSchedule(calls []*kv.ScheduledCall, slots map[time.Time]string) error {
+	return s.db.Update(func(tx *bbolt.Tx) error {
+		for _, bucket := range [][]byte{scheduledCallsBucket, slotsBucket} {
+			if err := tx.DeleteBucket(bucket); err != nil {
+				return fmt.Errorf("%w: failed to delete bucket '%s': %w", kv.ErrDBOperationFailed, bucket, err)
+			}
+			if _, err := tx.CreateBucket(bucket); err != nil {
+				return fmt.Errorf("%w: failed to create bucket '%s': %w", kv.ErrDBOperationFailed, bucket, err)
+			}
+		}
+
+		b := tx.Bucket(scheduledCallsBucket)
+		for _, call := range calls {
+			buf, err := json.Marshal(call)
+			if err != nil {
+				return fmt.Errorf("%w: failed to marshal scheduled call: %w", kv.ErrSerializationFailed, err)
+			}
+			if err := b.Put([]byte(call.ID), buf); err != nil {
+				return fmt.Errorf("%w: failed to put scheduled call: %w", kv.ErrDBOperationFailed, err)
+			}
+		}
+
+		b = tx.Bucket(slotsBucket)
+		for slot, callID := range slots {
+			if err := b.Put([]byte(slot.Format(time.RFC3339)), []byte(callID)); err != nil {
+				return fmt.Errorf("%w: failed to reserve slot: %w", kv.ErrDBOperationFailed, err)
+			}
+		}
+		return nil
+	})
+}
+
+// SetTriggerOverride adds or replaces the override for a trigger.
+func (s *Store) SetTriggerOverride(o *kv.TriggerOverride) error {
+	return s.db.Update(func(tx *bbolt.Tx) error {
+		buf, err := json.Marshal(o)
+		if err != nil {
+			return fmt.Errorf("%w: failed to marshal trigger override: %w", kv.ErrSerializationFailed, err)
+		}
+		if err := tx.Bucket(triggerOverridesBucket).Put([]byte(o.Key()), buf); err != nil {
+			return fmt.Errorf("%w: failed to put trigger override: %w", kv.ErrDBOperationFailed, err)
+		}
+		return nil
+	})
+}
+
+// ListTriggerOverrides retrieves all trigger overrides from the store.
+func (s *Store) ListTriggerOverrides() ([]*kv.TriggerOverride, error) {
+	var overrides []*kv.TriggerOverride
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		b := tx.Bucket(triggerOverridesBucket)
+		if b == nil {
+			// A read-only store opened before the bucket was created has no overrides.
+			return nil
+		}
+		return b.ForEach(func(k, v []byte) error {
+			var o kv.TriggerOverride
+			if err := json.Unmarshal(v, &o); err != nil {
+				return fmt.Errorf("%w: failed to unmarshal trigger override: %w", kv.ErrSerializationFailed, err)
+			}
+			overrides = append(overrides, &o)
+			return nil
+		})
+	})
+	return overrides, err
+}
+
+// DeleteTriggerOverride removes the override for a trigger.
+func (s *Store) DeleteTriggerOverride(callID string, index int) error {
+	key := (&kv.TriggerOverride{CallID: callID, Index: index}).Key()
+	return s.db.Update(func(tx *bbolt.Tx) error {
+		b := tx.Bucket(triggerOverridesBucket)
+		if b.Get([]byte(key)) == nil {
+			return fmt.Errorf("%w: trigger override '%s'", kv.ErrNotFound, key)
+		}
+		if err := b.Delete([]byte(key)); err != nil {
+			return fmt.Errorf("%w: failed to delete trigger override: %w", kv.ErrDBOperationFailed, err)
+		}
+		return nil
+	})
+}
+
+// SetSeriesOverride adds or replaces the override for a series.
+func (s *Store) SetSeriesOverride(o *kv.SeriesOverride) error {
+	return s.db.Update(func(tx *bbolt.Tx) error {
+		buf, err := json.Marshal(o)
+		if err != nil {
+			return fmt.Errorf("%w: failed to marshal series override: %w", kv.ErrSerializationFailed, err)
+		}
+		if err := tx.Bucket(seriesOverridesBucket).Put([]byte(o.SeriesID), buf); err != nil {
+			return fmt.Errorf("%w: failed to put series override: %w", kv.ErrDBOperationFailed, err)
+		}
+		return nil
+	})
+}
+
+// ListSeriesOverrides retrieves all series overrides from the store.
+func (s *Store) ListSeriesOverrides() ([]*kv.SeriesOverride, error) {
+	var overrides []*kv.SeriesOverride
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		b := tx.Bucket(seriesOverridesBucket)
+		if b == nil {
+			// A read-only store opened before the bucket was created has no overrides.
+			return nil
+		}
+		return b.ForEach(func(k, v []byte) error {
+			var o kv.SeriesOverride
+			if err := json.Unmarshal(v, &o); err != nil {
+				return fmt.Errorf("%w: failed to unmarshal series override: %w", kv.ErrSerializationFailed, err)
+			}
+			overrides = append(overrides, &o)
+			return nil
+		})
+	})
+	return overrides, err
+}
+
+// DeleteSeriesOverride removes the override for a series.
+func (s *Store) DeleteSeriesOverride(seriesID string) error {
+	return s.db.Update(func(tx *bbolt.Tx) error {
+		b := tx.Bucket(seriesOverridesBucket)
+		if b.Get([]byte(seriesID)) == nil {
+			return fmt.Errorf("%w: series override '%s'", kv.ErrNotFound, seriesID)
+		}
+		if err := b.Delete([]byte(seriesID)); err != nil {
+			return fmt.Errorf("%w: failed to delete series override: %w", kv.ErrDBOperationFailed, err)
+		}
+		return nil
+	})
+}
+
+// signoffPrefix returns the prefix shared by the keys of the sign-offs of a scheduled call.
+func signoffPrefix(campaignID, callID string) string {
+	return campaignID + "\x00" + callID + "\x00"
+}
+
+// AddSignoff adds or replaces the sign-off of a checklist item.
+func (s *Store) AddSignoff(so *kv.Signoff) error {
+	return s.db.Update(func(tx *bbolt.Tx) error {
+		buf, err := json.Marshal(so)
+		if err != nil {
+			return fmt.Errorf("%w: failed to marshal sign-off: %w", kv.ErrSerializationFailed, err)
+		}
+		key := signoffPrefix(so.CampaignID, so.CallID) + so.Item
+		if err := tx.Bucket(signoffsBucket).Put([]byte(key), buf); err != nil {
+			return fmt.Errorf("%w: failed to put sign-off: %w", kv.ErrDBOperationFailed, err)
+		}
+		return nil
+	})
+}
+
+// ListSignoffs returns the sign-offs of the checklist items of a scheduled call.
+func (s *Store) ListSignoffs(campaignID, callID string) ([]*kv.Signoff, error) {
+	var signoffs []*kv.Signoff
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		b := tx.Bucket(signoffsBucket)
+		if b == nil {
+			// A read-only store opened before the bucket was created has no sign-offs.
+			return nil
+		}
+		prefix := []byte(signoffPrefix(campaignID, callID))
+		c := b.Cursor()
+		for k, v := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, v = c.Next() {
+			var so kv.Signoff
+			if err := json.Unmarshal(v, &so); err != nil {
+				return fmt.Errorf("%w: failed to unmarshal sign-off: %w", kv.ErrSerializationFailed, err)
+			}
+			signoffs = append(signoffs, &so)
+		}
+		return nil
+	})
+	return signoffs, err
+}
+
+// CancelCall adds or replaces the cancellation of a scheduled call.
+func (s *Store) CancelCall(c *kv.Cancellation) error {
+	return s.db.Update(func(tx *bbolt.Tx) error {
+		buf, err := json.Marshal(c)
+		if err != nil {
+			return fmt.Errorf("%w: failed to marshal cancellation: %w", kv.ErrSerializationFailed, err)
+		}
+		if err := tx.Bucket(cancellationsBucket).Put([]byte(c.CallID), buf); err != nil {
+			return fmt.Errorf("%w: failed to put cancellation: %w", kv.ErrDBOperationFailed, err)
+		}
+		return nil
+	})
+}
+
+// GetCancellation returns the cancellation of a scheduled call.
+func (s *Store) GetCancellation(callID string) (*kv.Cancellation, error) {
+	var c kv.Cancellation
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		b := tx.Bucket(cancellationsBucket)
+		if b == nil {
+			// A read-only store opened before the bucket was created has no cancellations.
+			return fmt.Errorf("%w: cancellation of '%s'", kv.ErrNotFound, callID)
+		}
+		v := b.Get([]byte(callID))
+		if v == nil {
+			return fmt.Errorf("%w: cancellation of '%s'", kv.ErrNotFound, callID)
+		}
+		if err := json.Unmarshal(v, &c); err != nil {
+			return fmt.Errorf("%w: failed to unmarshal cancellation: %w", kv.ErrSerializationFailed, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// DeleteCancellation removes the cancellation of a scheduled call.
+func (s *Store) DeleteCancellation(callID string) error {
+	return s.db.Update(func(tx *bbolt.Tx) error {
+		b := tx.Bucket(cancellationsBucket)
+		if b.Get([]byte(callID)) == nil {
+			return fmt.Errorf("%w: cancellation of '%s'", kv.ErrNotFound, callID)
+		}
+		if err := b.Delete([]byte(callID)); err != nil {
+			return fmt.Errorf("%w: failed to delete cancellation: %w", kv.ErrDBOperationFailed, err)
+		}
+		return nil
+	})
+}
+
+// SetReschedule adds or replaces the move of a scheduled call.
+func (s *Store) SetReschedule(r *kv.Reschedule) error {
+	return s.db.Update(func(tx *bbolt.Tx) error {
+		buf, err := json.Marshal(r)
+		if err != nil {
+			return fmt.Errorf("%w: failed to marshal reschedule: %w", kv.ErrSerializationFailed, err)
+		}
+		if err := tx.Bucket(reschedulesBucket).Put([]byte(r.CallID), buf); err != nil {
+			return fmt.Errorf("%w: failed to put reschedule: %w", kv.ErrDBOperationFailed, err)
+		}
+		return nil
+	})
+}
+
+// ListReschedules returns the moves of every scheduled call.
+func (s *Store) ListReschedules() ([]*kv.Reschedule, error) {
+	var reschedules []*kv.Reschedule
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		b := tx.Bucket(reschedulesBucket)
+		if b == nil {
+			// A read-only store opened before the bucket was created has no reschedules.
+			return nil
+		}
+		return b.ForEach(func(k, v []byte) error {
+			var r kv.Reschedule
+			if err := json.Unmarshal(v, &r); err != nil {
+				return fmt.Errorf("%w: failed to unmarshal reschedule: %w", kv.ErrSerializationFailed, err)
+			}
+			reschedules = append(reschedules, &r)
+			return nil
+		})
+	})
+	return reschedules, err
+}
+
+// DeleteReschedule removes the move of a scheduled call.
+func (s *Store) DeleteReschedule(callID string) error {
+	return s.db.Update(func(tx *bbolt.Tx) error {
+		b := tx.Bucket(reschedulesBucket)
+		if b.Get([]byte(callID)) == nil {
+			return fmt.Errorf("%w: reschedule of '%s'", kv.ErrNotFound, callID)
+		}
+		if err := b.Delete([]byte(callID)); err != nil {
+			return fmt.Errorf("%w: failed to delete reschedule: %w", kv.ErrDBOperationFailed, err)
+		}
+		return nil
+	})
+}
+
+// SetApproval adds or replaces the approval of a scheduled call.
+func (s *Store) SetApproval(a *kv.Approval) error {
+	return s.db.Update(func(tx *bbolt.Tx) error {
+		buf, err := json.Marshal(a)
+		if err != nil {
+			return fmt.Errorf("%w: failed to marshal approval: %w", kv.ErrSerializationFailed, err)
+		}
+		if err := tx.Bucket(approvalsBucket).Put([]byte(a.CallID), buf); err != nil {
+			return fmt.Errorf("%w: failed to put approval: %w", kv.ErrDBOperationFailed, err)
+		}
+		return nil
+	})
+}
+
+// GetApproval returns the approval of a scheduled call.
+func (s *Store) GetApproval(callID string) (*kv.Approval, error) {
+	var a kv.Approval
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		b := tx.Bucket(approvalsBucket)
+		if b == nil {
+			// A read-only store opened before the bucket was created has no approvals.
+			return fmt.Errorf("%w: approval of '%s'", kv.ErrNotFound, callID)
+		}
+		v := b.Get([]byte(callID))
+		if v == nil {
+			return fmt.Errorf("%w: approval of '%s'", kv.ErrNotFound, callID)
+		}
+		if err := json.Unmarshal(v, &a); err != nil {
+			return fmt.Errorf("%w: failed to unmarshal approval: %w", kv.ErrSerializationFailed, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+// ListApprovals returns the approvals of every scheduled call.
+func (s *Store) ListApprovals() ([]*kv.Approval, error) {
+	var approvals []*kv.Approval
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		b := tx.Bucket(approvalsBucket)
+		if b == nil {
+			// A read-only store opened before the bucket was created has no approvals.
+			return nil
+		}
+		return b.ForEach(func(k, v []byte) error {
+			var a kv.Approval
+			if err := json.Unmarshal(v, &a); err != nil {
+				return fmt.Errorf("%w: failed to unmarshal approval: %w", kv.ErrSerializationFailed, err)
+			}
+			approvals = append(approvals, &a)
+			return nil
+		})
+	})
+	return approvals, err
+}
+
+// SetConfirmation adds or replaces the confirmation of a scheduled call.
+func (s *Store) SetConfirmation(c *kv.Confirmation) error {
+	return s.db.Update(func(tx *bbolt.Tx) error {
+		buf, err := json.Marshal(c)
+		if err != nil {
+			return fmt.Errorf("%w: failed to marshal confirmation: %w", kv.ErrSerializationFailed, err)
+		}
+		if err := tx.Bucket(confirmationsBucket).Put([]byte(c.CallID), buf); err != nil {
+			return fmt.Errorf("%w: failed to put confirmation: %w", kv.ErrDBOperationFailed, err)
+		}
+		return nil
+	})
+}
+
+// GetConfirmation returns the confirmation of a scheduled call.
+func (s *Store) GetConfirmation(callID string) (*kv.Confirmation, error) {
+	var c kv.Confirmation
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		b := tx.Bucket(confirmationsBucket)
+		if b == nil {
+			// A read-only store opened before the bucket was created has no confirmations.
+			return fmt.Errorf("%w: confirmation of '%s'", kv.ErrNotFound, callID)
+		}
+		v := b.Get([]byte(callID))
+		if v == nil {
+			return fmt.Errorf("%w: confirmation of '%s'", kv.ErrNotFound, callID)
+		}
+		if err := json.Unmarshal(v, &c); err != nil {
+			return fmt.Errorf("%w: failed to unmarshal confirmation: %w", kv.ErrSerializationFailed, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// AddDeadLetter adds or replaces the dead letter of a message that failed to send.
+func (s *Store) AddDeadLetter(d *kv.DeadLetter) error {
+	return s.db.Update(func(tx *bbolt.Tx) error {
+		buf, err := json.Marshal(d)
+		if err != nil {
+			return fmt.Errorf("%w: failed to marshal dead letter: %w", kv.ErrSerializationFailed, err)
+		}
+		if err := tx.Bucket(deadLettersBucket).Put([]byte(d.ID), buf); err != nil {
+			return fmt.Errorf("%w: failed to put dead letter: %w", kv.ErrDBOperationFailed, err)
+		}
+		return nil
+	})
+}
+
+// GetDeadLetter returns the dead letter of a sent message.
+func (s *Store) GetDeadLetter(id string) (*kv.DeadLetter, error) {
+	var d kv.DeadLetter
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		b := tx.Bucket(deadLettersBucket)
+		if b == nil {
+			// A read-only store opened before the bucket was created has no dead letters.
+			return fmt.Errorf("%w: dead letter '%s'", kv.ErrNotFound, id)
+		}
+		v := b.Get([]byte(id))
+		if v == nil {
+			return fmt.Errorf("%w: dead letter '%s'", kv.ErrNotFound, id)
+		}
+		if err := json.Unmarshal(v, &d); err != nil {
+			return fmt.Errorf("%w: failed to unmarshal dead letter: %w", kv.ErrSerializationFailed, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+// ListDeadLetters returns every dead letter.
+func (s *Store) ListDeadLetters() ([]*kv.DeadLetter, error) {
+	var letters []*kv.DeadLetter
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		b := tx.Bucket(deadLettersBucket)
+		if b == nil {
+			// A read-only store opened before the bucket was created has no dead letters.
+			return nil
+		}
+		return b.ForEach(func(k, v []byte) error {
+			var d kv.DeadLetter
+			if err := json.Unmarshal(v, &d); err != nil {
+				return fmt.Errorf("%w: failed to unmarshal dead letter: %w", kv.ErrSerializationFailed, err)
+			}
+			letters = append(letters, &d)
+			return nil
+		})
+	})
+	return letters, err
+}
+
+// DeleteDeadLetter removes the dead letter of a sent message.
+func (s *Store) DeleteDeadLetter(id string) error {
+	return s.db.Update(func(tx *bbolt.Tx) error {
+		b := tx.Bucket(deadLettersBucket)
+		if b.Get([]byte(id)) == nil {
+			return fmt.Errorf("%w: dead letter '%s'", kv.ErrNotFound, id)
+		}
+		if err := b.Delete([]byte(id)); err != nil {
+			return fmt.Errorf("%w: failed to delete dead letter: %w", kv.ErrDBOperationFailed, err)
+		}
+		return nil
+	})
+}
+
+// SetWorkerStatus adds or replaces the status of a worker.
+func (s *Store) SetWorkerStatus(ws *kv.WorkerStatus) error {
+	return s.db.Update(func(tx *bbolt.Tx) error {
+		buf, err := json.Marshal(ws)
+		if err != nil {
+			return fmt.Errorf("%w: failed to marshal worker status: %w", kv.ErrSerializationFailed, err)
+		}
+		if err := tx.Bucket(workersBucket).Put([]byte(ws.Holder), buf); err != nil {
+			return fmt.Errorf("%w: failed to put worker status: %w", kv.ErrDBOperationFailed, err)
+		}
+		return nil
+	})
+}
+
+// ListWorkerStatuses returns the status of every worker that has written one.
+func (s *Store) ListWorkerStatuses() ([]*kv.WorkerStatus, error) {
+	var statuses []*kv.WorkerStatus
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		b := tx.Bucket(workersBucket)
+		if b == nil {
+			// A read-only store opened before the bucket was created has no worker statuses.
+			return nil
+		}
+		return b.ForEach(func(k, v []byte) error {
+			var ws kv.WorkerStatus
+			if err := json.Unmarshal(v, &ws); err != nil {
+				return fmt.Errorf("%w: failed to unmarshal worker status: %w", kv.ErrSerializationFailed, err)
+			}
+			statuses = append(statuses, &ws)
+			return nil
+		})
+	})
+	return statuses, err
+}
+
+// SetCachedSource adds or replaces the cached content of a source.
+func (s *Store) SetCachedSource(c *kv.CachedSource) error {
+	return s.db.Update(func(tx *bbolt.Tx) error {
+		buf, err := json.Marshal(c)
+		if err != nil {
+			return fmt.Errorf("%w: failed to marshal cached source: %w", kv.ErrSerializationFailed, err)
+		}
+		if err := tx.Bucket(sourcesBucket).Put([]byte(c.URL), buf); err != nil {
+			return fmt.Errorf("%w: failed to put cached source: %w", kv.ErrDBOperationFailed, err)
+		}
+		return nil
+	})
+}
+
+// GetCachedSource returns the cached content of a source.
+func (s *Store) GetCachedSource(url string) (*kv.CachedSource, error) {
+	var c kv.CachedSource
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		b := tx.Bucket(sourcesBucket)
+		if b == nil {
+			// A read-only store opened before the bucket was created has no cached sources.
+			return fmt.Errorf("%w: cached source '%s'", kv.ErrNotFound, url)
+		}
+		v := b.Get([]byte(url))
+		if v == nil {
+			return fmt.Errorf("%w: cached source '%s'", kv.ErrNotFound, url)
+		}
+		if err := json.Unmarshal(v, &c); err != nil {
+			return fmt.Errorf("%w: failed to unmarshal cached source: %w", kv.ErrSerializationFailed, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// AcquireLease takes or renews the named lease for the holder, unless another holder has a lease that
+// has not expired.
+func (s *Store) AcquireLease(name, holder string, now time.Time, ttl time.Duration) (*kv.Lease, error) {
+	var lease *kv.Lease
+	err := s.db.Update(func(tx *bbolt.Tx) error {
+		b := tx.Bucket(leasesBucket)
+		if v := b.Get([]byte(name)); v != nil {
+			var current kv.Lease
+			if err := json.Unmarshal(v, &current); err != nil {
+				return fmt.Errorf("%w: failed to unmarshal lease: %w", kv.ErrSerializationFailed, err)
+			}
+			if current.Holder != holder && now.Before(current.Expires) {
+				lease = &current
+				return fmt.Errorf("%w: '%s' is held by '%s'", kv.ErrLeaseHeld, name, current.Holder)
+			}
+		}
+
+		lease = &kv.Lease{Name: name, Holder: holder, Expires: now.Add(ttl)}
+		buf, err := json.Marshal(lease)
+		if err != nil {
+			return fmt.Errorf("%w: failed to marshal lease: %w", kv.ErrSerializationFailed, err)
+		}
+		if err := b.Put([]byte(name), buf); err != nil {
+			return fmt.Errorf("%w: failed to put lease: %w", kv.ErrDBOperationFailed, err)
+		}
+		return nil
+	})
+	return lease, err
+}
+
 // GetSchemaVersion retrieves the current schema version from the store.
 func (s *Store) GetSchemaVersion() (int, error) {
 	var version int
@@ -235,21 +894,24 @@ func (s *Store) SetSchemaVersion(version int) error {
 	})
 }
 
-// HasBeenSent checks if a message with the given sourceID and scheduledAt time has a 'sent' or 'deleted' status.
-// It returns false for messages that have a 'failed' status, or do not exist.
-func (s *Store) HasBeenSent(campaignID, callID, destType, destination string) (bool, error) {
+// HasBeenSent checks if the message for an occurrence of a call has a 'sent', 'deleted' or 'cancelled'
+// status. It returns false for messages that have a 'failed' status, or do not exist.
+func (s *Store) HasBeenSent(campaignID, callID string, occurredAt time.Time, destType, destination string) (bool, error) {
 	var sent bool
 	err := s.db.View(func(tx *bbolt.Tx) error {
 		b := tx.Bucket(sentMessagesBucket)
-		id := s.generateID(campaignID, callID, destType, destination)
-		v := b.Get([]byte(id))
-		if v != nil {
+		for _, id := range kv.SentIDs(campaignID, callID, occurredAt, destType, destination) {
+			v := b.Get([]byte(id))
+			if v == nil {
+				continue
+			}
 			var sm kv.SentMessage
 			if err := json.Unmarshal(v, &sm); err != nil {
 				return fmt.Errorf("%w: failed to unmarshal sent message: %w", kv.ErrSerializationFailed, err)
 			}
-			if sm.Status == kv.StatusSent || sm.Status == kv.StatusDeleted {
+			if sm.Settles(occurredAt) {
 				sent = true
+				return nil
 			}
 		}
 		return nil
@@ -260,17 +922,6 @@ func (s *Store) HasBeenSent(campaignID, callID, destType, destination string) (b
 	return sent, nil
 }
 
-func (s *Store) generateID(campaignID, callID, destType, destination string) string {
-	parts := []string{
-		campaignID,
-		callID,
-		destType,
-		destination,
-	}
-	return strings.Join(parts, "@")
-}
-
-
 // ListSentMessages retrieves all sent messages from the store.
 func (s *Store) ListSentMessages() ([]*kv.SentMessage, error) {
 	var sentMessages []*kv.SentMessage
@@ -295,6 +946,106 @@ func (s *Store) ListSentMessages() ([]*kv.SentMessage, error) {
 	return sentMessages, nil
 }
 
+// ListSentMessagesByDestination retrieves the messages sent to a destination, most recently
+// scheduled first. It walks the timeline backwards from the end of the destination's entries, so it
+// reads only the messages it returns.
+func (s *Store) ListSentMessagesByDestination(destination string, limit int) ([]*kv.SentMessage, error) {
+	var sentMessages []*kv.SentMessage
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		b := tx.Bucket(sentMessagesBucket)
+		timeline := tx.Bucket(sentTimelineBucket)
+		if timeline == nil {
+			// The database has not been opened for writing since the timeline was introduced, so
+			// fall back to scanning every message.
+			var err error
+			sentMessages, err = scanSentMessages(b, func(sm *kv.SentMessage) bool {
+				return sm.Destination == destination
+			}, limit)
+			return err
+		}
+
+		prefix := []byte(timelinePrefix(destination))
+		c := timeline.Cursor()
+		// Position the cursor on the last entry of the destination: the one before the first key past its prefix.
+		k, v := c.Seek([]byte(destination + "\x01"))
+		if k == nil {
+			k, v = c.Last()
+		} else {
+			k, v = c.Prev()
+		}
+		for ; k != nil && bytes.HasPrefix(k, prefix); k, v = c.Prev() {
+			if limit > 0 && len(sentMessages) >= limit {
+				break
+			}
+			data := b.Get(v)
+			if data == nil {
+				return fmt.Errorf("%w: timeline entry for missing message '%s'", kv.ErrNotFound, v)
+			}
+			var sm kv.SentMessage
+			if err := json.Unmarshal(data, &sm); err != nil {
+				return fmt.Errorf("%w: failed to unmarshal sent message: %w", kv.ErrSerializationFailed, err)
+			}
+			sentMessages = append(sentMessages, &sm)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return sentMessages, nil
+}
+
+// ListSentMessagesByCampaign retrieves the messages sent for a campaign, most recently scheduled first.
+func (s *Store) ListSentMessagesByCampaign(campaignID string) ([]*kv.SentMessage, error) {
+	var sentMessages []*kv.SentMessage
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		var err error
+		sentMessages, err = scanSentMessages(tx.Bucket(sentMessagesBucket), func(sm *kv.SentMessage) bool {
+			return sm.CampaignID == campaignID
+		}, 0)
+		return err
+	})
+	return sentMessages, err
+}
+
+// ListExpiringMessages retrieves the sent messages that are due to be deleted at or before a time.
+func (s *Store) ListExpiringMessages(before time.Time) ([]*kv.SentMessage, error) {
+	var sentMessages []*kv.SentMessage
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		var err error
+		sentMessages, err = scanSentMessages(tx.Bucket(sentMessagesBucket), func(sm *kv.SentMessage) bool {
+			return sm.Status == kv.StatusSent && !sm.DeleteAt.IsZero() && !sm.DeleteAt.After(before)
+		}, 0)
+		return err
+	})
+	return sentMessages, err
+}
+
+// scanSentMessages reads every sent message to find those that match, most recently scheduled first.
+func scanSentMessages(b *bbolt.Bucket, match func(*kv.SentMessage) bool, limit int) ([]*kv.SentMessage, error) {
+	var sentMessages []*kv.SentMessage
+	err := b.ForEach(func(k, v []byte) error {
+		var sm kv.SentMessage
+		if err := json.Unmarshal(v, &sm); err != nil {
+			return fmt.Errorf("%w: failed to unmarshal sent message: %w", kv.ErrSerializationFailed, err)
+		}
+		if match(&sm) {
+			sentMessages = append(sentMessages, &sm)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to iterate over sent messages: %w", kv.ErrDBOperationFailed, err)
+	}
+	sort.Slice(sentMessages, func(i, j int) bool {
+		return sentMessages[i].ScheduledAt.After(sentMessages[j].ScheduledAt)
+	})
+	if limit > 0 && len(sentMessages) > limit {
+		sentMessages = sentMessages[:limit]
+	}
+	return sentMessages, nil
+}
+
 // GetSentMessage retrieves a single sent message from the store.
 func (s *Store) GetSentMessage(id string) (*kv.SentMessage, error) {
 	var sm kv.SentMessage
@@ -373,6 +1124,7 @@ func (s *Store) DeleteSentMessage(id string) error {
 	return s.db.Update(func(tx *bbolt.Tx) error {
 		b := tx.Bucket(sentMessagesBucket)
 		sm.Status = kv.StatusDeleted
+		sm.Supersede(sm, time.Now())
 
 		buf, err := json.Marshal(sm)
 		if err != nil {
@@ -407,6 +1159,24 @@ func (s *Store) ReserveSlot(slot time.Time, callID string) (bool, error) {
 	return reserved, nil
 }
 
+func (s *Store) ListSlots() (map[time.Time]string, error) {
+	slots := make(map[time.Time]string)
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		return tx.Bucket(slotsBucket).ForEach(func(k, v []byte) error {
+			slot, err := time.Parse(time.RFC3339, string(k))
+			if err != nil {
+				return fmt.Errorf("%w: failed to parse slot '%s': %w", kv.ErrSerializationFailed, k, err)
+			}
+			slots[slot.UTC()] = string(v)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return slots, nil
+}
+
 func (s *Store) ClearAllSlots() error {
 	return s.db.Update(func(tx *bbolt.Tx) error {
 		if err := tx.DeleteBucket(slotsBucket); err != nil {
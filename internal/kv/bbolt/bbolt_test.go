@@ -1,13 +1,16 @@
 package bbolt_test
 
 import (
+	"fmt"
 	"os"
 	"testing"
 	"time"
 
 	"github.com/andrewhowdencom/ruf/internal/kv"
 	"github.com/andrewhowdencom/ruf/internal/kv/bbolt"
+	"github.com/andrewhowdencom/ruf/internal/model"
 	"github.com/stretchr/testify/assert"
+	bolt "go.etcd.io/bbolt"
 )
 
 func TestStore_AddAndGetSentMessage(t *testing.T) {
@@ -51,11 +54,60 @@ func TestStore_HasBeenSent(t *testing.T) {
 	err = store.AddSentMessage("test-campaign", "test-call", sm)
 	assert.NoError(t, err)
 
-	sent, err := store.HasBeenSent("test-campaign", "test-call", "slack", "test-channel")
+	sent, err := store.HasBeenSent("test-campaign", "test-call", time.Time{}, "slack", "test-channel")
 	assert.NoError(t, err)
 	assert.True(t, sent)
 
-	sent, err = store.HasBeenSent("test-campaign", "test-call", "slack", "other-channel")
+	sent, err = store.HasBeenSent("test-campaign", "test-call", time.Time{}, "slack", "other-channel")
+	assert.NoError(t, err)
+	assert.False(t, sent)
+}
+
+func TestStore_HasBeenSent_Occurrences(t *testing.T) {
+	dbPath := "test.db"
+	defer os.Remove(dbPath)
+
+	store, err := bbolt.NewTestStore(dbPath)
+	assert.NoError(t, err)
+	defer store.Close()
+
+	first := time.Date(2025, 1, 6, 9, 0, 0, 0, time.UTC)
+	second := first.Add(7 * 24 * time.Hour)
+
+	err = store.AddSentMessage("test-campaign", "test-call", &kv.SentMessage{
+		SourceID:    "test-call",
+		ScheduledAt: first.Add(time.Hour),
+		OccurredAt:  first,
+		Status:      kv.StatusSent,
+		Type:        "slack",
+		Destination: "test-channel",
+	})
+	assert.NoError(t, err)
+
+	// Each occurrence of the call is sent once, however far it was moved.
+	sent, err := store.HasBeenSent("test-campaign", "test-call", first, "slack", "test-channel")
+	assert.NoError(t, err)
+	assert.True(t, sent)
+	sent, err = store.HasBeenSent("test-campaign", "test-call", second, "slack", "test-channel")
+	assert.NoError(t, err)
+	assert.False(t, sent)
+
+	// Messages recorded before occurrences were kept settle the occurrence they were migrated to, or
+	// every occurrence if it could not be found.
+	err = store.AddSentMessage("test-campaign", "legacy-call", &kv.SentMessage{SourceID: "legacy-call", Status: kv.StatusSent, Type: "slack", Destination: "test-channel"})
+	assert.NoError(t, err)
+	sent, err = store.HasBeenSent("test-campaign", "legacy-call", second, "slack", "test-channel")
+	assert.NoError(t, err)
+	assert.True(t, sent)
+
+	legacy, err := store.GetSentMessage(kv.GenerateID("test-campaign", "legacy-call", time.Time{}, "slack", "test-channel"))
+	assert.NoError(t, err)
+	legacy.OccurredAt = first
+	assert.NoError(t, store.UpdateSentMessage(legacy))
+	sent, err = store.HasBeenSent("test-campaign", "legacy-call", first, "slack", "test-channel")
+	assert.NoError(t, err)
+	assert.True(t, sent)
+	sent, err = store.HasBeenSent("test-campaign", "legacy-call", second, "slack", "test-channel")
 	assert.NoError(t, err)
 	assert.False(t, sent)
 }
@@ -83,4 +135,356 @@ func TestStore_DeleteSentMessage(t *testing.T) {
 	retrieved, err := store.GetSentMessage(sm.ID)
 	assert.NoError(t, err)
 	assert.Equal(t, kv.StatusDeleted, retrieved.Status)
+
+	// Each status the message has had is kept with it.
+	if assert.Len(t, retrieved.History, 2) {
+		assert.Equal(t, kv.StatusSent, retrieved.History[0].Status)
+		assert.Equal(t, kv.StatusDeleted, retrieved.History[1].Status)
+		assert.False(t, retrieved.History[1].At.Before(retrieved.History[0].At))
+	}
+}
+
+func TestStore_AddBatches(t *testing.T) {
+	dbPath := "test.db"
+	defer os.Remove(dbPath)
+
+	store, err := bbolt.NewTestStore(dbPath)
+	assert.NoError(t, err)
+	defer store.Close()
+
+	scheduledAt := time.Now().UTC().Truncate(time.Second)
+	err = store.AddScheduledCalls([]*kv.ScheduledCall{
+		{Call: model.Call{ID: "call-1"}, ScheduledAt: scheduledAt},
+		{Call: model.Call{ID: "call-2"}, ScheduledAt: scheduledAt},
+	})
+	assert.NoError(t, err)
+
+	calls, err := store.ListScheduledCalls()
+	assert.NoError(t, err)
+	assert.Len(t, calls, 2)
+}
+
+func TestStore_ReplaceSchedule(t *testing.T) {
+	dbPath := "test.db"
+	defer os.Remove(dbPath)
+
+	store, err := bbolt.NewTestStore(dbPath)
+	assert.NoError(t, err)
+	defer store.Close()
+
+	scheduledAt := time.Now().UTC().Truncate(time.Second)
+	slot := scheduledAt.Add(time.Hour)
+	assert.NoError(t, store.AddScheduledCall(&kv.ScheduledCall{Call: model.Call{ID: "stale"}, ScheduledAt: scheduledAt}))
+	_, err = store.ReserveSlot(slot, "slack:#stale")
+	assert.NoError(t, err)
+
+	err = store.ReplaceSchedule([]*kv.ScheduledCall{
+		{Call: model.Call{ID: "call-1"}, ScheduledAt: scheduledAt},
+		{Call: model.Call{ID: "call-2"}, ScheduledAt: scheduledAt},
+	}, map[time.Time]string{slot: "slack:#general"})
+	assert.NoError(t, err)
+
+	calls, err := store.ListScheduledCalls()
+	assert.NoError(t, err)
+	assert.Len(t, calls, 2)
+	_, err = store.GetScheduledCall("stale")
+	assert.ErrorIs(t, err, kv.ErrNotFound)
+
+	slots, err := store.ListSlots()
+	assert.NoError(t, err)
+	assert.Equal(t, map[time.Time]string{slot: "slack:#general"}, slots)
+
+	// The slot was replaced along with the schedule, so it is still taken.
+	reserved, err := store.ReserveSlot(slot, "slack:#other")
+	assert.NoError(t, err)
+	assert.False(t, reserved)
+}
+
+func TestStore_TriggerOverrides(t *testing.T) {
+	dbPath := "test.db"
+	defer os.Remove(dbPath)
+
+	store, err := bbolt.NewTestStore(dbPath)
+	assert.NoError(t, err)
+	defer store.Close()
+
+	until := time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC)
+	assert.NoError(t, store.SetTriggerOverride(&kv.TriggerOverride{CallID: "call-1", Index: 1, Until: until}))
+	assert.NoError(t, store.SetTriggerOverride(&kv.TriggerOverride{CallID: "call-1", Index: 1}))
+
+	overrides, err := store.ListTriggerOverrides()
+	assert.NoError(t, err)
+	assert.Equal(t, []*kv.TriggerOverride{{CallID: "call-1", Index: 1}}, overrides)
+
+	assert.NoError(t, store.DeleteTriggerOverride("call-1", 1))
+	assert.ErrorIs(t, store.DeleteTriggerOverride("call-1", 1), kv.ErrNotFound)
+}
+
+func TestStore_Cancellations(t *testing.T) {
+	dbPath := "test.db"
+	defer os.Remove(dbPath)
+
+	store, err := bbolt.NewTestStore(dbPath)
+	assert.NoError(t, err)
+	defer store.Close()
+
+	_, err = store.GetCancellation("call-1")
+	assert.ErrorIs(t, err, kv.ErrNotFound)
+
+	at := time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC)
+	assert.NoError(t, store.CancelCall(&kv.Cancellation{CallID: "call-1", By: "jane", Reason: "wrong date", At: at}))
+	c, err := store.GetCancellation("call-1")
+	assert.NoError(t, err)
+	assert.Equal(t, &kv.Cancellation{CallID: "call-1", By: "jane", Reason: "wrong date", At: at}, c)
+
+	assert.NoError(t, store.DeleteCancellation("call-1"))
+	assert.ErrorIs(t, store.DeleteCancellation("call-1"), kv.ErrNotFound)
+}
+
+func TestStore_Approvals(t *testing.T) {
+	dbPath := "test.db"
+	defer os.Remove(dbPath)
+
+	store, err := bbolt.NewTestStore(dbPath)
+	assert.NoError(t, err)
+	defer store.Close()
+
+	_, err = store.GetApproval("call-1")
+	assert.ErrorIs(t, err, kv.ErrNotFound)
+
+	at := time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC)
+	assert.NoError(t, store.SetApproval(&kv.Approval{CampaignID: "launch", CallID: "call-1", Status: kv.ApprovalPending, RequestedAt: at}))
+	approved := &kv.Approval{CampaignID: "launch", CallID: "call-1", Status: kv.ApprovalApproved, RequestedAt: at, By: "legal", At: at.Add(time.Hour)}
+	assert.NoError(t, store.SetApproval(approved))
+	a, err := store.GetApproval("call-1")
+	assert.NoError(t, err)
+	assert.Equal(t, approved, a)
+
+	approvals, err := store.ListApprovals()
+	assert.NoError(t, err)
+	assert.Equal(t, []*kv.Approval{approved}, approvals)
+}
+
+func TestStore_Confirmations(t *testing.T) {
+	dbPath := "test.db"
+	defer os.Remove(dbPath)
+
+	store, err := bbolt.NewTestStore(dbPath)
+	assert.NoError(t, err)
+	defer store.Close()
+
+	_, err = store.GetConfirmation("call-1")
+	assert.ErrorIs(t, err, kv.ErrNotFound)
+
+	at := time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC)
+	assert.NoError(t, store.SetConfirmation(&kv.Confirmation{CampaignID: "launch", CallID: "call-1", Status: kv.ConfirmationPending, RequestedAt: at}))
+	confirmed := &kv.Confirmation{CampaignID: "launch", CallID: "call-1", Status: kv.ConfirmationConfirmed, RequestedAt: at, By: "jane", At: at.Add(time.Minute)}
+	assert.NoError(t, store.SetConfirmation(confirmed))
+	c, err := store.GetConfirmation("call-1")
+	assert.NoError(t, err)
+	assert.Equal(t, confirmed, c)
+}
+
+func TestStore_DeadLetters(t *testing.T) {
+	dbPath := "test.db"
+	defer os.Remove(dbPath)
+
+	store, err := bbolt.NewTestStore(dbPath)
+	assert.NoError(t, err)
+	defer store.Close()
+
+	_, err = store.GetDeadLetter("message-1")
+	assert.ErrorIs(t, err, kv.ErrNotFound)
+
+	letter := &kv.DeadLetter{
+		ID:          "message-1",
+		CampaignID:  "launch",
+		CallID:      "call-1",
+		Type:        "slack",
+		Destination: "#general",
+		Call:        []byte(`{"id":"call-1"}`),
+		Error:       "not_in_channel",
+		Attempts:    1,
+		FailedAt:    time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC),
+	}
+	assert.NoError(t, store.AddDeadLetter(letter))
+	d, err := store.GetDeadLetter("message-1")
+	assert.NoError(t, err)
+	assert.Equal(t, letter, d)
+
+	letters, err := store.ListDeadLetters()
+	assert.NoError(t, err)
+	assert.Equal(t, []*kv.DeadLetter{letter}, letters)
+
+	assert.NoError(t, store.DeleteDeadLetter("message-1"))
+	assert.ErrorIs(t, store.DeleteDeadLetter("message-1"), kv.ErrNotFound)
+}
+
+func TestStore_WorkerStatuses(t *testing.T) {
+	dbPath := "test.db"
+	defer os.Remove(dbPath)
+
+	store, err := bbolt.NewTestStore(dbPath)
+	assert.NoError(t, err)
+	defer store.Close()
+
+	statuses, err := store.ListWorkerStatuses()
+	assert.NoError(t, err)
+	assert.Empty(t, statuses)
+
+	status := &kv.WorkerStatus{
+		Holder:    "host/1",
+		Leader:    true,
+		StartedAt: time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC),
+		UpdatedAt: time.Date(2025, 12, 1, 0, 1, 0, 0, time.UTC),
+		LastTick:  time.Date(2025, 12, 1, 0, 1, 0, 0, time.UTC),
+		Scheduled: 3,
+		Processed: 1,
+	}
+	assert.NoError(t, store.SetWorkerStatus(status))
+
+	// A worker writing its status again replaces it.
+	status.Processed = 2
+	assert.NoError(t, store.SetWorkerStatus(status))
+	statuses, err = store.ListWorkerStatuses()
+	assert.NoError(t, err)
+	assert.Equal(t, []*kv.WorkerStatus{status}, statuses)
+}
+
+func TestStore_CachedSources(t *testing.T) {
+	dbPath := "test.db"
+	defer os.Remove(dbPath)
+
+	store, err := bbolt.NewTestStore(dbPath)
+	assert.NoError(t, err)
+	defer store.Close()
+
+	url := "https://example.com/calls.yaml"
+	_, err = store.GetCachedSource(url)
+	assert.ErrorIs(t, err, kv.ErrNotFound)
+
+	at := time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC)
+	assert.NoError(t, store.SetCachedSource(&kv.CachedSource{URL: url, State: "v1", Source: []byte(`{"calls":[]}`), FetchedAt: at}))
+	assert.NoError(t, store.SetCachedSource(&kv.CachedSource{URL: url, State: "v2", Source: []byte(`{"calls":[]}`), FetchedAt: at}))
+	c, err := store.GetCachedSource(url)
+	assert.NoError(t, err)
+	assert.Equal(t, &kv.CachedSource{URL: url, State: "v2", Source: []byte(`{"calls":[]}`), FetchedAt: at}, c)
+}
+
+func TestStore_ListSentMessagesByDestination(t *testing.T) {
+	dbPath := "test.db"
+	defer os.Remove(dbPath)
+
+	store, err := bbolt.NewTestStore(dbPath)
+	assert.NoError(t, err)
+	defer store.Close()
+
+	start := time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC)
+	var sent []*kv.SentMessage
+	for i := 0; i < 5; i++ {
+		for _, destination := range []string{"#general", "#general-2", "#random"} {
+			sm := &kv.SentMessage{Type: "slack", Destination: destination, ScheduledAt: start.Add(time.Duration(i) * time.Hour), Status: kv.StatusSent}
+			assert.NoError(t, store.AddSentMessage("campaign", fmt.Sprintf("call-%d", i), sm))
+			sent = append(sent, sm)
+		}
+	}
+
+	messages, err := store.ListSentMessagesByDestination("#general", 2)
+	assert.NoError(t, err)
+	assert.Len(t, messages, 2)
+	assert.Equal(t, start.Add(4*time.Hour), messages[0].ScheduledAt)
+	assert.Equal(t, start.Add(3*time.Hour), messages[1].ScheduledAt)
+	for _, m := range messages {
+		assert.Equal(t, "#general", m.Destination)
+	}
+
+	// Updating a message moves its entry in the timeline, rather than adding another.
+	first := sent[0]
+	first.ScheduledAt = start.Add(10 * time.Hour)
+	assert.NoError(t, store.UpdateSentMessage(first))
+
+	messages, err = store.ListSentMessagesByDestination("#general", 0)
+	assert.NoError(t, err)
+	assert.Len(t, messages, 5)
+	assert.Equal(t, first.ID, messages[0].ID)
+
+	messages, err = store.ListSentMessagesByDestination("#unknown", 10)
+	assert.NoError(t, err)
+	assert.Empty(t, messages)
+}
+
+func TestStore_ListSentMessagesByDestination_BuildsTimeline(t *testing.T) {
+	dbPath := "test.db"
+	defer os.Remove(dbPath)
+
+	store, err := bbolt.NewTestStore(dbPath)
+	assert.NoError(t, err)
+	assert.NoError(t, store.AddSentMessage("campaign", "call", &kv.SentMessage{Type: "slack", Destination: "#general", Status: kv.StatusSent}))
+	assert.NoError(t, store.Close())
+
+	// Simulate a database written before the timeline existed.
+	db, err := bolt.Open(dbPath, 0600, nil)
+	assert.NoError(t, err)
+	assert.NoError(t, db.Update(func(tx *bolt.Tx) error {
+		return tx.DeleteBucket([]byte("sent_timeline"))
+	}))
+	assert.NoError(t, db.Close())
+
+	store, err = bbolt.NewTestStore(dbPath)
+	assert.NoError(t, err)
+	defer store.Close()
+
+	messages, err := store.ListSentMessagesByDestination("#general", 10)
+	assert.NoError(t, err)
+	assert.Len(t, messages, 1)
+}
+
+func TestStore_AcquireLease(t *testing.T) {
+	dbPath := "test.db"
+	defer os.Remove(dbPath)
+
+	store, err := bbolt.NewTestStore(dbPath)
+	assert.NoError(t, err)
+	defer store.Close()
+
+	now := time.Date(2025, 3, 4, 9, 0, 0, 0, time.UTC)
+	lease, err := store.AcquireLease("worker", "a", now, time.Minute)
+	assert.NoError(t, err)
+	assert.Equal(t, &kv.Lease{Name: "worker", Holder: "a", Expires: now.Add(time.Minute)}, lease)
+
+	// Another holder cannot take the lease until it expires.
+	lease, err = store.AcquireLease("worker", "b", now.Add(30*time.Second), time.Minute)
+	assert.ErrorIs(t, err, kv.ErrLeaseHeld)
+	assert.Equal(t, "a", lease.Holder)
+
+	// The holder renews it.
+	lease, err = store.AcquireLease("worker", "a", now.Add(50*time.Second), time.Minute)
+	assert.NoError(t, err)
+	assert.Equal(t, now.Add(110*time.Second), lease.Expires)
+
+	lease, err = store.AcquireLease("worker", "b", now.Add(2*time.Minute), time.Minute)
+	assert.NoError(t, err)
+	assert.Equal(t, "b", lease.Holder)
+}
+
+func TestStore_ListSentMessagesByCampaign(t *testing.T) {
+	dbPath := "test.db"
+	defer os.Remove(dbPath)
+
+	store, err := bbolt.NewTestStore(dbPath)
+	assert.NoError(t, err)
+	defer store.Close()
+
+	start := time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC)
+	assert.NoError(t, store.AddSentMessage("launch", "a", &kv.SentMessage{Type: "slack", Destination: "#general", ScheduledAt: start}))
+	assert.NoError(t, store.AddSentMessage("launch", "b", &kv.SentMessage{Type: "slack", Destination: "#general", ScheduledAt: start.Add(time.Hour)}))
+	assert.NoError(t, store.AddSentMessage("other", "a", &kv.SentMessage{Type: "slack", Destination: "#general", ScheduledAt: start}))
+
+	messages, err := store.ListSentMessagesByCampaign("launch")
+	assert.NoError(t, err)
+	if assert.Len(t, messages, 2) {
+		assert.Equal(t, "launch@b@slack@#general", messages[0].ID)
+		assert.Equal(t, "launch@a@slack@#general", messages[1].ID)
+		assert.Equal(t, "launch", messages[0].CampaignID)
+	}
 }
@@ -0,0 +1,209 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/andrewhowdencom/ruf/internal/kv"
+)
+
+// DefaultTTL is the default amount of time an entry is served from the cache.
+const DefaultTTL = 1 * time.Minute
+
+// Store is a read-through caching decorator around a kv.Storer. It caches the
+// point reads issued on every worker tick (HasBeenSent and GetScheduledCall)
+// and invalidates them whenever a write goes through this Store.
+//
+// Writes made by other processes sharing the same backend are not observed
+// until the cached entry expires, so the TTL bounds how stale a read can be.
+//
+// Scheduled calls are cached encoded, and every hit decodes its own copy, so a
+// caller that changes the call it was given does not change it for the others.
+type Store struct {
+	kv.Storer
+
+	ttl time.Duration
+	now func() time.Time
+
+	// The entries are shared with the stores bound to a context, made by WithContext.
+	mu             *sync.Mutex
+	sent           map[string]entry[bool]
+	scheduledCalls map[string]entry[[]byte]
+}
+
+type entry[T any] struct {
+	value   T
+	expires time.Time
+}
+
+// Option configures the Store.
+type Option func(*Store)
+
+// WithTTL sets how long an entry is served from the cache before it is read again.
+func WithTTL(ttl time.Duration) Option {
+	return func(s *Store) {
+		s.ttl = ttl
+	}
+}
+
+// WithClock sets the function used to determine the current time.
+func WithClock(now func() time.Time) Option {
+	return func(s *Store) {
+		s.now = now
+	}
+}
+
+// New creates a new caching Store wrapping the given storer.
+func New(storer kv.Storer, opts ...Option) *Store {
+	s := &Store{
+		Storer:         storer,
+		ttl:            DefaultTTL,
+		now:            time.Now,
+		mu:             &sync.Mutex{},
+		sent:           make(map[string]entry[bool]),
+		scheduledCalls: make(map[string]entry[[]byte]),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// WithContext returns the store with the operations of the store it wraps bound to the context. The
+// returned store shares the entries of the cache.
+func (s *Store) WithContext(ctx context.Context) kv.Storer {
+	bound := *s
+	bound.Storer = kv.WithContext(ctx, s.Storer)
+	return &bound
+}
+
+// HasBeenSent checks if a message has been sent, serving the answer from the cache if possible.
+func (s *Store) HasBeenSent(campaignID, callID string, occurredAt time.Time, destType, destination string) (bool, error) {
+	key := kv.GenerateID(campaignID, callID, occurredAt, destType, destination)
+
+	s.mu.Lock()
+	if e, ok := s.sent[key]; ok && s.now().Before(e.expires) {
+		s.mu.Unlock()
+		return e.value, nil
+	}
+	s.mu.Unlock()
+
+	sent, err := s.Storer.HasBeenSent(campaignID, callID, occurredAt, destType, destination)
+	if err != nil {
+		return false, err
+	}
+
+	s.mu.Lock()
+	s.sent[key] = entry[bool]{value: sent, expires: s.now().Add(s.ttl)}
+	s.mu.Unlock()
+
+	return sent, nil
+}
+
+// GetScheduledCall retrieves a scheduled call, serving a copy of it from the cache if possible.
+func (s *Store) GetScheduledCall(id string) (*kv.ScheduledCall, error) {
+	s.mu.Lock()
+	e, ok := s.scheduledCalls[id]
+	s.mu.Unlock()
+	if ok && s.now().Before(e.expires) {
+		var call kv.ScheduledCall
+		if err := json.Unmarshal(e.value, &call); err == nil {
+			return &call, nil
+		}
+	}
+
+	call, err := s.Storer.GetScheduledCall(id)
+	if err != nil {
+		return nil, err
+	}
+
+	// A call that cannot be encoded is read from the store every time.
+	if b, err := json.Marshal(call); err == nil {
+		s.mu.Lock()
+		s.scheduledCalls[id] = entry[[]byte]{value: b, expires: s.now().Add(s.ttl)}
+		s.mu.Unlock()
+	}
+
+	return call, nil
+}
+
+// AddSentMessage adds a sent message and invalidates the cached status for it.
+func (s *Store) AddSentMessage(campaignID, callID string, sm *kv.SentMessage) error {
+	err := s.Storer.AddSentMessage(campaignID, callID, sm)
+	s.invalidateSent(kv.GenerateID(campaignID, callID, sm.OccurredAt, sm.Type, sm.Destination))
+	return err
+}
+
+// UpdateSentMessage updates a sent message. A message recorded before occurrences were kept answers
+// for every occurrence of its call, so all cached statuses are dropped.
+func (s *Store) UpdateSentMessage(sm *kv.SentMessage) error {
+	err := s.Storer.UpdateSentMessage(sm)
+	s.mu.Lock()
+	s.sent = make(map[string]entry[bool])
+	s.mu.Unlock()
+	return err
+}
+
+// DeleteSentMessage deletes a sent message. As the ID may be a short ID, all cached statuses are dropped.
+func (s *Store) DeleteSentMessage(id string) error {
+	err := s.Storer.DeleteSentMessage(id)
+	s.mu.Lock()
+	s.sent = make(map[string]entry[bool])
+	s.mu.Unlock()
+	return err
+}
+
+// AddScheduledCall adds a scheduled call and invalidates the cached copy of it.
+func (s *Store) AddScheduledCall(call *kv.ScheduledCall) error {
+	err := s.Storer.AddScheduledCall(call)
+	s.invalidateScheduledCall(call.ID)
+	return err
+}
+
+// AddScheduledCalls adds a batch of scheduled calls and invalidates the cached copies of them.
+func (s *Store) AddScheduledCalls(calls []*kv.ScheduledCall) error {
+	err := s.Storer.AddScheduledCalls(calls)
+	for _, call := range calls {
+		s.invalidateScheduledCall(call.ID)
+	}
+	return err
+}
+
+// DeleteScheduledCall deletes a scheduled call and invalidates the cached copy of it.
+func (s *Store) DeleteScheduledCall(id string) error {
+	err := s.Storer.DeleteScheduledCall(id)
+	s.invalidateScheduledCall(id)
+	return err
+}
+
+// ClearScheduledCalls clears all scheduled calls and the cached copies of them.
+func (s *Store) ClearScheduledCalls() error {
+	err := s.Storer.ClearScheduledCalls()
+	s.mu.Lock()
+	s.scheduledCalls = make(map[string]entry[[]byte])
+	s.mu.Unlock()
+	return err
+}
+
+// ReplaceSchedule replaces the schedule and drops all cached scheduled calls.
+func (s *Store) ReplaceSchedule(calls []*kv.ScheduledCall, slots map[time.Time]string) error {
+	err := s.Storer.ReplaceSchedule(calls, slots)
+	s.mu.Lock()
+	s.scheduledCalls = make(map[string]entry[[]byte])
+	s.mu.Unlock()
+	return err
+}
+
+func (s *Store) invalidateSent(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.sent, key)
+}
+
+func (s *Store) invalidateScheduledCall(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.scheduledCalls, id)
+}
@@ -0,0 +1,112 @@
+package cache_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/andrewhowdencom/ruf/internal/datastore"
+	"github.com/andrewhowdencom/ruf/internal/kv"
+	"github.com/andrewhowdencom/ruf/internal/kv/cache"
+	"github.com/andrewhowdencom/ruf/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+// countingStore records the number of reads that reach the underlying store.
+type countingStore struct {
+	*datastore.MockStore
+	hasBeenSentCalls      int
+	getScheduledCallCalls int
+}
+
+func (c *countingStore) HasBeenSent(campaignID, callID string, occurredAt time.Time, destType, destination string) (bool, error) {
+	c.hasBeenSentCalls++
+	return c.MockStore.HasBeenSent(campaignID, callID, occurredAt, destType, destination)
+}
+
+func (c *countingStore) GetScheduledCall(id string) (*kv.ScheduledCall, error) {
+	c.getScheduledCallCalls++
+	return c.MockStore.GetScheduledCall(id)
+}
+
+func TestStore_HasBeenSent(t *testing.T) {
+	now := time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC)
+	backend := &countingStore{MockStore: datastore.NewMockStore()}
+	store := cache.New(backend, cache.WithTTL(time.Minute), cache.WithClock(func() time.Time { return now }))
+
+	sent, err := store.HasBeenSent("campaign", "call", time.Time{}, "slack", "#general")
+	assert.NoError(t, err)
+	assert.False(t, sent)
+
+	// A second read within the TTL is served from the cache.
+	_, err = store.HasBeenSent("campaign", "call", time.Time{}, "slack", "#general")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, backend.hasBeenSentCalls)
+
+	// A local write invalidates the cached entry.
+	err = store.AddSentMessage("campaign", "call", &kv.SentMessage{Type: "slack", Destination: "#general", Status: kv.StatusSent})
+	assert.NoError(t, err)
+	sent, err = store.HasBeenSent("campaign", "call", time.Time{}, "slack", "#general")
+	assert.NoError(t, err)
+	assert.True(t, sent)
+	assert.Equal(t, 2, backend.hasBeenSentCalls)
+
+	// Entries are read again once the TTL has expired.
+	now = now.Add(2 * time.Minute)
+	_, err = store.HasBeenSent("campaign", "call", time.Time{}, "slack", "#general")
+	assert.NoError(t, err)
+	assert.Equal(t, 3, backend.hasBeenSentCalls)
+}
+
+func TestStore_GetScheduledCall(t *testing.T) {
+	now := time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC)
+	backend := &countingStore{MockStore: datastore.NewMockStore()}
+	store := cache.New(backend, cache.WithClock(func() time.Time { return now }))
+
+	err := store.AddScheduledCall(&kv.ScheduledCall{Call: model.Call{ID: "call-1", Subject: "First"}})
+	assert.NoError(t, err)
+
+	call, err := store.GetScheduledCall("call-1")
+	assert.NoError(t, err)
+	assert.Equal(t, "First", call.Subject)
+	_, err = store.GetScheduledCall("call-1")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, backend.getScheduledCallCalls)
+
+	err = store.AddScheduledCall(&kv.ScheduledCall{Call: model.Call{ID: "call-1", Subject: "Second"}})
+	assert.NoError(t, err)
+	call, err = store.GetScheduledCall("call-1")
+	assert.NoError(t, err)
+	assert.Equal(t, "Second", call.Subject)
+
+	err = store.DeleteScheduledCall("call-1")
+	assert.NoError(t, err)
+	_, err = store.GetScheduledCall("call-1")
+	assert.ErrorIs(t, err, kv.ErrNotFound)
+	assert.Equal(t, 3, backend.getScheduledCallCalls)
+}
+
+func TestStore_GetScheduledCallCopies(t *testing.T) {
+	backend := &countingStore{MockStore: datastore.NewMockStore()}
+	store := cache.New(backend)
+	err := backend.AddScheduledCall(&kv.ScheduledCall{Call: model.Call{
+		ID:           "call-1",
+		Subject:      "First",
+		Destinations: []model.Destination{{Type: "slack", To: []string{"#general"}}},
+	}})
+	assert.NoError(t, err)
+
+	// The first read fills the cache, and the second is served from it.
+	for range 2 {
+		call, err := store.GetScheduledCall("call-1")
+		assert.NoError(t, err)
+		call.Subject = "Changed"
+		call.Destinations[0].To[0] = "#random"
+	}
+	assert.Equal(t, 1, backend.getScheduledCallCalls)
+
+	// Changes made by the callers do not reach the cached call.
+	call, err := store.GetScheduledCall("call-1")
+	assert.NoError(t, err)
+	assert.Equal(t, "First", call.Subject)
+	assert.Equal(t, []string{"#general"}, call.Destinations[0].To)
+}
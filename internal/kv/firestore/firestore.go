@@ -2,83 +2,812 @@ package firestore
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
-	"strings"
+	"sort"
 	"time"
 
 	"cloud.google.com/go/firestore"
 	"github.com/andrewhowdencom/ruf/internal/kv"
+	"google.golang.org/api/option"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 )
 
-// Store manages the persistence of calls in Firestore.
+// Store manages the persistence of calls in Firestore. Requests that fail with a transient error are
+// retried with backoff, and each attempt has a deadline.
 type Store struct {
-	client *firestore.Client
+	client     *firestore.Client
+	clientOpts []option.ClientOption
+	timeout    time.Duration
+	retry      retryPolicy
+	// ctx is the context the operations of the store are bound to, if any.
+	ctx context.Context
 }
 
 // NewStore creates a new Store and initializes the Firestore client.
-func NewStore(projectID string) (kv.Storer, error) {
-	ctx := context.Background()
-	client, err := firestore.NewClient(ctx, projectID)
+func NewStore(projectID string, opts ...Option) (kv.Storer, error) {
+	s := &Store{
+		timeout: defaultTimeout,
+		retry:   retryPolicy{attempts: defaultAttempts, initial: defaultInitialBackoff, max: defaultMaxBackoff},
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	client, err := firestore.NewClient(context.Background(), projectID, s.clientOpts...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create firestore client: %w", err)
 	}
-	return &Store{client: client}, nil
+	s.client = client
+	return s, nil
 }
 
-// Close closes the Firestore client connection.
-func (s *Store) Close() error {
-	return s.client.Close()
+// WithContext returns the store with its operations bound to the context, sharing its client.
+func (s *Store) WithContext(ctx context.Context) kv.Storer {
+	bound := *s
+	bound.ctx = ctx
+	return &bound
 }
 
-func (s *Store) generateID(campaignID, callID, destType, destination string) string {
-	parts := []string{
-		campaignID,
-		callID,
-		destType,
-		destination,
+// context returns the context the operations of the store are bound to.
+func (s *Store) context() context.Context {
+	if s.ctx == nil {
+		return context.Background()
 	}
-	return strings.Join(parts, "@")
+	return s.ctx
+}
+
+// Close closes the Firestore client connection.
+func (s *Store) Close() error {
+	return s.client.Close()
 }
 
 // AddSentMessage adds a new sent message to the store.
 func (s *Store) AddSentMessage(campaignID, callID string, sm *kv.SentMessage) error {
-	ctx := context.Background()
-	sm.ID = s.generateID(campaignID, callID, sm.Type, sm.Destination)
+	ctx := s.context()
+	sm.ID = kv.GenerateID(campaignID, callID, sm.OccurredAt, sm.Type, sm.Destination)
 	sm.ShortID = kv.GenerateShortID(sm.ID)
-	_, err := s.client.Collection("sent_messages").Doc(sm.ID).Set(ctx, sm)
-	if err != nil {
+	sm.CampaignID = campaignID
+	if err := s.putSentMessage(ctx, sm); err != nil {
 		return fmt.Errorf("%w: failed to add sent message: %w", kv.ErrDBOperationFailed, err)
 	}
 	return nil
 }
 
-// Scheduled call management
+// putSentMessage stores a sent message in a transaction with the record it replaces, so that the history
+// of the previous record is kept with it.
+func (s *Store) putSentMessage(ctx context.Context, sm *kv.SentMessage) error {
+	ref := s.client.Collection("sent_messages").Doc(sm.ID)
+	return s.transaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		doc, err := tx.Get(ref)
+		if err != nil && status.Code(err) != codes.NotFound {
+			return err
+		}
+		var previous *kv.SentMessage
+		if doc != nil && doc.Exists() {
+			previous = &kv.SentMessage{}
+			if err := doc.DataTo(previous); err != nil {
+				return fmt.Errorf("%w: failed to unmarshal sent message: %w", kv.ErrSerializationFailed, err)
+			}
+		}
+		sm.Supersede(previous, time.Now())
+		return tx.Set(ref, sm)
+	})
+}
+
+// maxBatchSize is the maximum number of writes Firestore accepts in a single batch.
+const maxBatchSize = 500
+
+// AddScheduledCall adds a new scheduled call to the store.
 func (s *Store) AddScheduledCall(call *kv.ScheduledCall) error {
-	return fmt.Errorf("not implemented")
+	ctx := s.context()
+	ref, err := s.scheduledCalls(ctx)
+	if err != nil {
+		return err
+	}
+	if err := s.set(ctx, ref.Doc(call.ID), call); err != nil {
+		return fmt.Errorf("%w: failed to add scheduled call: %w", kv.ErrDBOperationFailed, err)
+	}
+	return nil
+}
+
+// AddScheduledCalls adds a batch of scheduled calls to the store, committing them in as few batches as possible.
+func (s *Store) AddScheduledCalls(calls []*kv.ScheduledCall) error {
+	ctx := s.context()
+	ref, err := s.scheduledCalls(ctx)
+	if err != nil {
+		return err
+	}
+	for start := 0; start < len(calls); start += maxBatchSize {
+		end := min(start+maxBatchSize, len(calls))
+		batch := s.client.Batch()
+		for _, call := range calls[start:end] {
+			batch.Set(ref.Doc(call.ID), call)
+		}
+		if err := s.commit(ctx, batch); err != nil {
+			return fmt.Errorf("%w: failed to commit batch of scheduled calls: %w", kv.ErrDBOperationFailed, err)
+		}
+	}
+	return nil
 }
 
+// GetScheduledCall retrieves a single scheduled call from the store.
 func (s *Store) GetScheduledCall(id string) (*kv.ScheduledCall, error) {
-	return nil, fmt.Errorf("not implemented")
+	ctx := s.context()
+	ref, err := s.scheduledCalls(ctx)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := s.get(ctx, ref.Doc(id))
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return nil, fmt.Errorf("%w: scheduled call with id '%s'", kv.ErrNotFound, id)
+		}
+		return nil, fmt.Errorf("%w: failed to get scheduled call: %w", kv.ErrDBOperationFailed, err)
+	}
+
+	var call kv.ScheduledCall
+	if err := doc.DataTo(&call); err != nil {
+		return nil, fmt.Errorf("%w: failed to unmarshal scheduled call: %w", kv.ErrSerializationFailed, err)
+	}
+	return &call, nil
 }
 
+// ListScheduledCalls retrieves all scheduled calls from the store.
 func (s *Store) ListScheduledCalls() ([]*kv.ScheduledCall, error) {
-	return nil, fmt.Errorf("not implemented")
+	ctx := s.context()
+	ref, err := s.scheduledCalls(ctx)
+	if err != nil {
+		return nil, err
+	}
+	docs, err := s.getAll(ctx, ref)
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to list scheduled calls: %w", kv.ErrDBOperationFailed, err)
+	}
+
+	calls := make([]*kv.ScheduledCall, 0, len(docs))
+	for _, doc := range docs {
+		var call kv.ScheduledCall
+		if err := doc.DataTo(&call); err != nil {
+			return nil, fmt.Errorf("%w: failed to unmarshal scheduled call: %w", kv.ErrSerializationFailed, err)
+		}
+		calls = append(calls, &call)
+	}
+	return calls, nil
 }
 
+// DeleteScheduledCall removes a scheduled call from the store.
 func (s *Store) DeleteScheduledCall(id string) error {
-	return fmt.Errorf("not implemented")
+	ctx := s.context()
+	ref, err := s.scheduledCalls(ctx)
+	if err != nil {
+		return err
+	}
+	if err := s.delete(ctx, ref.Doc(id)); err != nil {
+		return fmt.Errorf("%w: failed to delete scheduled call: %w", kv.ErrDBOperationFailed, err)
+	}
+	return nil
 }
 
+// ClearScheduledCalls removes all scheduled calls from the store.
 func (s *Store) ClearScheduledCalls() error {
-	return fmt.Errorf("not implemented")
+	ctx := s.context()
+	ref, err := s.scheduledCalls(ctx)
+	if err != nil {
+		return err
+	}
+	return s.deleteAll(ctx, ref)
+}
+
+// ReplaceSchedule replaces all scheduled calls and slot reservations.
+//
+// A Firestore batch is limited to 500 writes, so the new schedule is written
+// to a fresh generation and made current by a single write to the schedule
+// pointer. Readers see either the previous schedule or the new one in full; a
+// crash before the pointer is updated leaves the previous schedule in place.
+func (s *Store) ReplaceSchedule(calls []*kv.ScheduledCall, slots map[time.Time]string) error {
+	ctx := s.context()
+	previous, err := s.generation(ctx)
+	if err != nil {
+		return err
+	}
+
+	generation := fmt.Sprintf("%d", time.Now().UnixNano())
+	callsRef, slotsRef := s.generationRefs(generation)
+
+	batch, n := s.client.Batch(), 0
+	commit := func() error {
+		if n == 0 {
+			return nil
+		}
+		if err := s.commit(ctx, batch); err != nil {
+			return fmt.Errorf("%w: failed to commit batch of schedule writes: %w", kv.ErrDBOperationFailed, err)
+		}
+		batch, n = s.client.Batch(), 0
+		return nil
+	}
+	for _, call := range calls {
+		batch.Set(callsRef.Doc(call.ID), call)
+		if n++; n == maxBatchSize {
+			if err := commit(); err != nil {
+				return err
+			}
+		}
+	}
+	for slot, callID := range slots {
+		batch.Set(slotsRef.Doc(slot.Format(time.RFC3339)), map[string]string{"callId": callID})
+		if n++; n == maxBatchSize {
+			if err := commit(); err != nil {
+				return err
+			}
+		}
+	}
+	if err := commit(); err != nil {
+		return err
+	}
+
+	if err := s.set(ctx, s.client.Collection("meta").Doc("schedule"), map[string]interface{}{
+		"generation": generation,
+	}); err != nil {
+		return fmt.Errorf("%w: failed to switch schedule generation: %w", kv.ErrDBOperationFailed, err)
+	}
+
+	// The previous generation is no longer visible, so removing it is best
+	// effort: a failure leaves unreferenced documents behind but does not
+	// affect the schedule.
+	oldCalls, oldSlots := s.generationRefs(previous)
+	_ = s.deleteAll(ctx, oldCalls)
+	_ = s.deleteAll(ctx, oldSlots)
+	return nil
+}
+
+// generation returns the current schedule generation, or an empty string if the
+// schedule has never been replaced.
+func (s *Store) generation(ctx context.Context) (string, error) {
+	doc, err := s.get(ctx, s.client.Collection("meta").Doc("schedule"))
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return "", nil
+		}
+		return "", fmt.Errorf("%w: failed to get schedule generation: %w", kv.ErrDBOperationFailed, err)
+	}
+	generation, _ := doc.Data()["generation"].(string)
+	return generation, nil
+}
+
+// generationRefs returns the scheduled call and slot collections for a generation.
+func (s *Store) generationRefs(generation string) (*firestore.CollectionRef, *firestore.CollectionRef) {
+	if generation == "" {
+		return s.client.Collection("scheduled_calls"), s.client.Collection("slots")
+	}
+	doc := s.client.Collection("schedules").Doc(generation)
+	return doc.Collection("calls"), doc.Collection("slots")
+}
+
+func (s *Store) scheduledCalls(ctx context.Context) (*firestore.CollectionRef, error) {
+	generation, err := s.generation(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ref, _ := s.generationRefs(generation)
+	return ref, nil
+}
+
+func (s *Store) slots(ctx context.Context) (*firestore.CollectionRef, error) {
+	generation, err := s.generation(ctx)
+	if err != nil {
+		return nil, err
+	}
+	_, ref := s.generationRefs(generation)
+	return ref, nil
+}
+
+// deleteAll removes every document in a collection.
+func (s *Store) deleteAll(ctx context.Context, ref *firestore.CollectionRef) error {
+	for {
+		docs, err := s.getAll(ctx, ref.Limit(maxBatchSize))
+		if err != nil {
+			return fmt.Errorf("%w: failed to iterate documents: %w", kv.ErrDBOperationFailed, err)
+		}
+		if len(docs) == 0 {
+			return nil
+		}
+
+		batch := s.client.Batch()
+		for _, doc := range docs {
+			batch.Delete(doc.Ref)
+		}
+		if err := s.commit(ctx, batch); err != nil {
+			return fmt.Errorf("%w: failed to commit batch delete: %w", kv.ErrDBOperationFailed, err)
+		}
+	}
+}
+
+// SetTriggerOverride adds or replaces the override for a trigger.
+func (s *Store) SetTriggerOverride(o *kv.TriggerOverride) error {
+	ctx := s.context()
+	if err := s.set(ctx, s.client.Collection("trigger_overrides").Doc(o.Key()), o); err != nil {
+		return fmt.Errorf("%w: failed to set trigger override: %w", kv.ErrDBOperationFailed, err)
+	}
+	return nil
+}
+
+// ListTriggerOverrides retrieves all trigger overrides from the store.
+func (s *Store) ListTriggerOverrides() ([]*kv.TriggerOverride, error) {
+	ctx := s.context()
+	docs, err := s.getAll(ctx, s.client.Collection("trigger_overrides"))
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to list trigger overrides: %w", kv.ErrDBOperationFailed, err)
+	}
+
+	overrides := make([]*kv.TriggerOverride, 0, len(docs))
+	for _, doc := range docs {
+		var o kv.TriggerOverride
+		if err := doc.DataTo(&o); err != nil {
+			return nil, fmt.Errorf("%w: failed to unmarshal trigger override: %w", kv.ErrSerializationFailed, err)
+		}
+		overrides = append(overrides, &o)
+	}
+	return overrides, nil
+}
+
+// DeleteTriggerOverride removes the override for a trigger.
+func (s *Store) DeleteTriggerOverride(callID string, index int) error {
+	ctx := s.context()
+	ref := s.client.Collection("trigger_overrides").Doc((&kv.TriggerOverride{CallID: callID, Index: index}).Key())
+	if _, err := s.get(ctx, ref); err != nil {
+		if status.Code(err) == codes.NotFound {
+			return fmt.Errorf("%w: trigger override '%s'", kv.ErrNotFound, ref.ID)
+		}
+		return fmt.Errorf("%w: failed to get trigger override: %w", kv.ErrDBOperationFailed, err)
+	}
+	if err := s.delete(ctx, ref); err != nil {
+		return fmt.Errorf("%w: failed to delete trigger override: %w", kv.ErrDBOperationFailed, err)
+	}
+	return nil
+}
+
+// SetSeriesOverride adds or replaces the override for a series.
+func (s *Store) SetSeriesOverride(o *kv.SeriesOverride) error {
+	ctx := s.context()
+	if err := s.set(ctx, s.client.Collection("series_overrides").Doc(o.SeriesID), o); err != nil {
+		return fmt.Errorf("%w: failed to set series override: %w", kv.ErrDBOperationFailed, err)
+	}
+	return nil
+}
+
+// ListSeriesOverrides retrieves all series overrides from the store.
+func (s *Store) ListSeriesOverrides() ([]*kv.SeriesOverride, error) {
+	ctx := s.context()
+	docs, err := s.getAll(ctx, s.client.Collection("series_overrides"))
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to list series overrides: %w", kv.ErrDBOperationFailed, err)
+	}
+
+	overrides := make([]*kv.SeriesOverride, 0, len(docs))
+	for _, doc := range docs {
+		var o kv.SeriesOverride
+		if err := doc.DataTo(&o); err != nil {
+			return nil, fmt.Errorf("%w: failed to unmarshal series override: %w", kv.ErrSerializationFailed, err)
+		}
+		overrides = append(overrides, &o)
+	}
+	return overrides, nil
+}
+
+// DeleteSeriesOverride removes the override for a series.
+func (s *Store) DeleteSeriesOverride(seriesID string) error {
+	ctx := s.context()
+	ref := s.client.Collection("series_overrides").Doc(seriesID)
+	if _, err := s.get(ctx, ref); err != nil {
+		if status.Code(err) == codes.NotFound {
+			return fmt.Errorf("%w: series override '%s'", kv.ErrNotFound, ref.ID)
+		}
+		return fmt.Errorf("%w: failed to get series override: %w", kv.ErrDBOperationFailed, err)
+	}
+	if err := s.delete(ctx, ref); err != nil {
+		return fmt.Errorf("%w: failed to delete series override: %w", kv.ErrDBOperationFailed, err)
+	}
+	return nil
+}
+
+// AddSignoff adds or replaces the sign-off of a checklist item. The document is named after a hash of
+// the campaign, call and item, as call IDs may contain characters that document IDs may not.
+func (s *Store) AddSignoff(so *kv.Signoff) error {
+	ctx := s.context()
+	hash := sha256.Sum256([]byte(so.CampaignID + "\x00" + so.CallID + "\x00" + so.Item))
+	if err := s.set(ctx, s.client.Collection("signoffs").Doc(hex.EncodeToString(hash[:])), so); err != nil {
+		return fmt.Errorf("%w: failed to add sign-off: %w", kv.ErrDBOperationFailed, err)
+	}
+	return nil
+}
+
+// ListSignoffs returns the sign-offs of the checklist items of a scheduled call.
+func (s *Store) ListSignoffs(campaignID, callID string) ([]*kv.Signoff, error) {
+	ctx := s.context()
+	docs, err := s.getAll(ctx, s.client.Collection("signoffs").Where("CampaignID", "==", campaignID).Where("CallID", "==", callID))
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to list sign-offs: %w", kv.ErrDBOperationFailed, err)
+	}
+
+	signoffs := make([]*kv.Signoff, 0, len(docs))
+	for _, doc := range docs {
+		var so kv.Signoff
+		if err := doc.DataTo(&so); err != nil {
+			return nil, fmt.Errorf("%w: failed to unmarshal sign-off: %w", kv.ErrSerializationFailed, err)
+		}
+		signoffs = append(signoffs, &so)
+	}
+	return signoffs, nil
+}
+
+// cancellation returns the document of the cancellation of a scheduled call. The document is named
+// after a hash of the call ID, as call IDs may contain characters that document IDs may not.
+func (s *Store) cancellation(callID string) *firestore.DocumentRef {
+	hash := sha256.Sum256([]byte(callID))
+	return s.client.Collection("cancellations").Doc(hex.EncodeToString(hash[:]))
+}
+
+// CancelCall adds or replaces the cancellation of a scheduled call.
+func (s *Store) CancelCall(c *kv.Cancellation) error {
+	ctx := s.context()
+	if err := s.set(ctx, s.cancellation(c.CallID), c); err != nil {
+		return fmt.Errorf("%w: failed to set cancellation: %w", kv.ErrDBOperationFailed, err)
+	}
+	return nil
+}
+
+// GetCancellation returns the cancellation of a scheduled call.
+func (s *Store) GetCancellation(callID string) (*kv.Cancellation, error) {
+	ctx := s.context()
+	doc, err := s.get(ctx, s.cancellation(callID))
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return nil, fmt.Errorf("%w: cancellation of '%s'", kv.ErrNotFound, callID)
+		}
+		return nil, fmt.Errorf("%w: failed to get cancellation: %w", kv.ErrDBOperationFailed, err)
+	}
+
+	var c kv.Cancellation
+	if err := doc.DataTo(&c); err != nil {
+		return nil, fmt.Errorf("%w: failed to unmarshal cancellation: %w", kv.ErrSerializationFailed, err)
+	}
+	return &c, nil
+}
+
+// DeleteCancellation removes the cancellation of a scheduled call.
+func (s *Store) DeleteCancellation(callID string) error {
+	ctx := s.context()
+	ref := s.cancellation(callID)
+	if _, err := s.get(ctx, ref); err != nil {
+		if status.Code(err) == codes.NotFound {
+			return fmt.Errorf("%w: cancellation of '%s'", kv.ErrNotFound, callID)
+		}
+		return fmt.Errorf("%w: failed to get cancellation: %w", kv.ErrDBOperationFailed, err)
+	}
+	if err := s.delete(ctx, ref); err != nil {
+		return fmt.Errorf("%w: failed to delete cancellation: %w", kv.ErrDBOperationFailed, err)
+	}
+	return nil
+}
+
+// reschedule returns the document of the move of a scheduled call, named after a hash of the call ID as
+// its cancellation is.
+func (s *Store) reschedule(callID string) *firestore.DocumentRef {
+	hash := sha256.Sum256([]byte(callID))
+	return s.client.Collection("reschedules").Doc(hex.EncodeToString(hash[:]))
+}
+
+// SetReschedule adds or replaces the move of a scheduled call.
+func (s *Store) SetReschedule(r *kv.Reschedule) error {
+	ctx := s.context()
+	if err := s.set(ctx, s.reschedule(r.CallID), r); err != nil {
+		return fmt.Errorf("%w: failed to set reschedule: %w", kv.ErrDBOperationFailed, err)
+	}
+	return nil
+}
+
+// ListReschedules returns the moves of every scheduled call.
+func (s *Store) ListReschedules() ([]*kv.Reschedule, error) {
+	ctx := s.context()
+	docs, err := s.getAll(ctx, s.client.Collection("reschedules"))
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to list reschedules: %w", kv.ErrDBOperationFailed, err)
+	}
+
+	reschedules := make([]*kv.Reschedule, 0, len(docs))
+	for _, doc := range docs {
+		var r kv.Reschedule
+		if err := doc.DataTo(&r); err != nil {
+			return nil, fmt.Errorf("%w: failed to unmarshal reschedule: %w", kv.ErrSerializationFailed, err)
+		}
+		reschedules = append(reschedules, &r)
+	}
+	return reschedules, nil
+}
+
+// DeleteReschedule removes the move of a scheduled call.
+func (s *Store) DeleteReschedule(callID string) error {
+	ctx := s.context()
+	ref := s.reschedule(callID)
+	if _, err := s.get(ctx, ref); err != nil {
+		if status.Code(err) == codes.NotFound {
+			return fmt.Errorf("%w: reschedule of '%s'", kv.ErrNotFound, callID)
+		}
+		return fmt.Errorf("%w: failed to get reschedule: %w", kv.ErrDBOperationFailed, err)
+	}
+	if err := s.delete(ctx, ref); err != nil {
+		return fmt.Errorf("%w: failed to delete reschedule: %w", kv.ErrDBOperationFailed, err)
+	}
+	return nil
+}
+
+// approval returns the document of the approval of a scheduled call, named after a hash of the call
+// ID as cancellations are.
+func (s *Store) approval(callID string) *firestore.DocumentRef {
+	hash := sha256.Sum256([]byte(callID))
+	return s.client.Collection("approvals").Doc(hex.EncodeToString(hash[:]))
+}
+
+// SetApproval adds or replaces the approval of a scheduled call.
+func (s *Store) SetApproval(a *kv.Approval) error {
+	ctx := s.context()
+	if err := s.set(ctx, s.approval(a.CallID), a); err != nil {
+		return fmt.Errorf("%w: failed to set approval: %w", kv.ErrDBOperationFailed, err)
+	}
+	return nil
+}
+
+// GetApproval returns the approval of a scheduled call.
+func (s *Store) GetApproval(callID string) (*kv.Approval, error) {
+	ctx := s.context()
+	doc, err := s.get(ctx, s.approval(callID))
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return nil, fmt.Errorf("%w: approval of '%s'", kv.ErrNotFound, callID)
+		}
+		return nil, fmt.Errorf("%w: failed to get approval: %w", kv.ErrDBOperationFailed, err)
+	}
+
+	var a kv.Approval
+	if err := doc.DataTo(&a); err != nil {
+		return nil, fmt.Errorf("%w: failed to unmarshal approval: %w", kv.ErrSerializationFailed, err)
+	}
+	return &a, nil
+}
+
+// ListApprovals returns the approvals of every scheduled call.
+func (s *Store) ListApprovals() ([]*kv.Approval, error) {
+	ctx := s.context()
+	docs, err := s.getAll(ctx, s.client.Collection("approvals"))
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to list approvals: %w", kv.ErrDBOperationFailed, err)
+	}
+
+	approvals := make([]*kv.Approval, 0, len(docs))
+	for _, doc := range docs {
+		var a kv.Approval
+		if err := doc.DataTo(&a); err != nil {
+			return nil, fmt.Errorf("%w: failed to unmarshal approval: %w", kv.ErrSerializationFailed, err)
+		}
+		approvals = append(approvals, &a)
+	}
+	return approvals, nil
+}
+
+// confirmation returns the document of the confirmation of a scheduled call, named after a hash of the
+// call ID as approvals are.
+func (s *Store) confirmation(callID string) *firestore.DocumentRef {
+	hash := sha256.Sum256([]byte(callID))
+	return s.client.Collection("confirmations").Doc(hex.EncodeToString(hash[:]))
+}
+
+// SetConfirmation adds or replaces the confirmation of a scheduled call.
+func (s *Store) SetConfirmation(c *kv.Confirmation) error {
+	ctx := s.context()
+	if err := s.set(ctx, s.confirmation(c.CallID), c); err != nil {
+		return fmt.Errorf("%w: failed to set confirmation: %w", kv.ErrDBOperationFailed, err)
+	}
+	return nil
+}
+
+// GetConfirmation returns the confirmation of a scheduled call.
+func (s *Store) GetConfirmation(callID string) (*kv.Confirmation, error) {
+	ctx := s.context()
+	doc, err := s.get(ctx, s.confirmation(callID))
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return nil, fmt.Errorf("%w: confirmation of '%s'", kv.ErrNotFound, callID)
+		}
+		return nil, fmt.Errorf("%w: failed to get confirmation: %w", kv.ErrDBOperationFailed, err)
+	}
+
+	var c kv.Confirmation
+	if err := doc.DataTo(&c); err != nil {
+		return nil, fmt.Errorf("%w: failed to unmarshal confirmation: %w", kv.ErrSerializationFailed, err)
+	}
+	return &c, nil
+}
+
+// deadLetter returns the document of the dead letter of a sent message, named after a hash of its ID as
+// sent messages are.
+func (s *Store) deadLetter(id string) *firestore.DocumentRef {
+	hash := sha256.Sum256([]byte(id))
+	return s.client.Collection("dead_letters").Doc(hex.EncodeToString(hash[:]))
+}
+
+// AddDeadLetter adds or replaces the dead letter of a message that failed to send.
+func (s *Store) AddDeadLetter(d *kv.DeadLetter) error {
+	ctx := s.context()
+	if err := s.set(ctx, s.deadLetter(d.ID), d); err != nil {
+		return fmt.Errorf("%w: failed to set dead letter: %w", kv.ErrDBOperationFailed, err)
+	}
+	return nil
+}
+
+// GetDeadLetter returns the dead letter of a sent message.
+func (s *Store) GetDeadLetter(id string) (*kv.DeadLetter, error) {
+	ctx := s.context()
+	doc, err := s.get(ctx, s.deadLetter(id))
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return nil, fmt.Errorf("%w: dead letter '%s'", kv.ErrNotFound, id)
+		}
+		return nil, fmt.Errorf("%w: failed to get dead letter: %w", kv.ErrDBOperationFailed, err)
+	}
+
+	var d kv.DeadLetter
+	if err := doc.DataTo(&d); err != nil {
+		return nil, fmt.Errorf("%w: failed to unmarshal dead letter: %w", kv.ErrSerializationFailed, err)
+	}
+	return &d, nil
+}
+
+// ListDeadLetters returns every dead letter.
+func (s *Store) ListDeadLetters() ([]*kv.DeadLetter, error) {
+	ctx := s.context()
+	docs, err := s.getAll(ctx, s.client.Collection("dead_letters"))
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to list dead letters: %w", kv.ErrDBOperationFailed, err)
+	}
+
+	letters := make([]*kv.DeadLetter, 0, len(docs))
+	for _, doc := range docs {
+		var d kv.DeadLetter
+		if err := doc.DataTo(&d); err != nil {
+			return nil, fmt.Errorf("%w: failed to unmarshal dead letter: %w", kv.ErrSerializationFailed, err)
+		}
+		letters = append(letters, &d)
+	}
+	return letters, nil
+}
+
+// DeleteDeadLetter removes the dead letter of a sent message.
+func (s *Store) DeleteDeadLetter(id string) error {
+	ctx := s.context()
+	ref := s.deadLetter(id)
+	if _, err := s.get(ctx, ref); err != nil {
+		if status.Code(err) == codes.NotFound {
+			return fmt.Errorf("%w: dead letter '%s'", kv.ErrNotFound, id)
+		}
+		return fmt.Errorf("%w: failed to get dead letter: %w", kv.ErrDBOperationFailed, err)
+	}
+	if err := s.delete(ctx, ref); err != nil {
+		return fmt.Errorf("%w: failed to delete dead letter: %w", kv.ErrDBOperationFailed, err)
+	}
+	return nil
+}
+
+// workerStatus returns the document of the status of a worker, named after a hash of its holder, as
+// holders contain characters that document IDs may not.
+func (s *Store) workerStatus(holder string) *firestore.DocumentRef {
+	hash := sha256.Sum256([]byte(holder))
+	return s.client.Collection("workers").Doc(hex.EncodeToString(hash[:]))
+}
+
+// SetWorkerStatus adds or replaces the status of a worker.
+func (s *Store) SetWorkerStatus(ws *kv.WorkerStatus) error {
+	ctx := s.context()
+	if err := s.set(ctx, s.workerStatus(ws.Holder), ws); err != nil {
+		return fmt.Errorf("%w: failed to set worker status: %w", kv.ErrDBOperationFailed, err)
+	}
+	return nil
+}
+
+// ListWorkerStatuses returns the status of every worker that has written one.
+func (s *Store) ListWorkerStatuses() ([]*kv.WorkerStatus, error) {
+	ctx := s.context()
+	docs, err := s.getAll(ctx, s.client.Collection("workers"))
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to list worker statuses: %w", kv.ErrDBOperationFailed, err)
+	}
+
+	statuses := make([]*kv.WorkerStatus, 0, len(docs))
+	for _, doc := range docs {
+		var ws kv.WorkerStatus
+		if err := doc.DataTo(&ws); err != nil {
+			return nil, fmt.Errorf("%w: failed to unmarshal worker status: %w", kv.ErrSerializationFailed, err)
+		}
+		statuses = append(statuses, &ws)
+	}
+	return statuses, nil
+}
+
+// cachedSource returns the document of the cached content of a source. The document is named after a
+// hash of the URL, as URLs contain characters that document IDs may not.
+func (s *Store) cachedSource(url string) *firestore.DocumentRef {
+	hash := sha256.Sum256([]byte(url))
+	return s.client.Collection("sources").Doc(hex.EncodeToString(hash[:]))
+}
+
+// SetCachedSource adds or replaces the cached content of a source.
+func (s *Store) SetCachedSource(c *kv.CachedSource) error {
+	ctx := s.context()
+	if err := s.set(ctx, s.cachedSource(c.URL), c); err != nil {
+		return fmt.Errorf("%w: failed to set cached source: %w", kv.ErrDBOperationFailed, err)
+	}
+	return nil
+}
+
+// GetCachedSource returns the cached content of a source.
+func (s *Store) GetCachedSource(url string) (*kv.CachedSource, error) {
+	ctx := s.context()
+	doc, err := s.get(ctx, s.cachedSource(url))
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return nil, fmt.Errorf("%w: cached source '%s'", kv.ErrNotFound, url)
+		}
+		return nil, fmt.Errorf("%w: failed to get cached source: %w", kv.ErrDBOperationFailed, err)
+	}
+
+	var c kv.CachedSource
+	if err := doc.DataTo(&c); err != nil {
+		return nil, fmt.Errorf("%w: failed to unmarshal cached source: %w", kv.ErrSerializationFailed, err)
+	}
+	return &c, nil
+}
+
+// AcquireLease takes or renews the named lease for the holder in a transaction, unless another holder
+// has a lease that has not expired.
+func (s *Store) AcquireLease(name, holder string, now time.Time, ttl time.Duration) (*kv.Lease, error) {
+	ctx := s.context()
+	ref := s.client.Collection("leases").Doc(name)
+
+	var lease *kv.Lease
+	err := s.transaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		doc, err := tx.Get(ref)
+		if err != nil && status.Code(err) != codes.NotFound {
+			return err
+		}
+		if doc.Exists() {
+			var current kv.Lease
+			if err := doc.DataTo(&current); err != nil {
+				return fmt.Errorf("%w: failed to unmarshal lease: %w", kv.ErrSerializationFailed, err)
+			}
+			if current.Holder != holder && now.Before(current.Expires) {
+				lease = &current
+				return fmt.Errorf("%w: '%s' is held by '%s'", kv.ErrLeaseHeld, name, current.Holder)
+			}
+		}
+		lease = &kv.Lease{Name: name, Holder: holder, Expires: now.Add(ttl)}
+		return tx.Set(ref, lease)
+	})
+	if err != nil {
+		if errors.Is(err, kv.ErrLeaseHeld) || errors.Is(err, kv.ErrSerializationFailed) {
+			return lease, err
+		}
+		return nil, fmt.Errorf("%w: failed to acquire lease: %w", kv.ErrDBOperationFailed, err)
+	}
+	return lease, nil
 }
 
 // GetSchemaVersion retrieves the current schema version from the store.
 func (s *Store) GetSchemaVersion() (int, error) {
-	ctx := context.Background()
-	doc, err := s.client.Collection("meta").Doc("schema_version").Get(ctx)
+	ctx := s.context()
+	doc, err := s.get(ctx, s.client.Collection("meta").Doc("schema_version"))
 	if err != nil {
 		if status.Code(err) == codes.NotFound {
 			return 0, nil
@@ -96,8 +825,8 @@ func (s *Store) GetSchemaVersion() (int, error) {
 
 // SetSchemaVersion sets the current schema version in the store.
 func (s *Store) SetSchemaVersion(version int) error {
-	ctx := context.Background()
-	_, err := s.client.Collection("meta").Doc("schema_version").Set(ctx, map[string]interface{}{
+	ctx := s.context()
+	err := s.set(ctx, s.client.Collection("meta").Doc("schema_version"), map[string]interface{}{
 		"version": version,
 	})
 	if err != nil {
@@ -108,20 +837,23 @@ func (s *Store) SetSchemaVersion(version int) error {
 
 // UpdateSentMessage updates an existing sent message in the store.
 func (s *Store) UpdateSentMessage(sm *kv.SentMessage) error {
-	ctx := context.Background()
-	_, err := s.client.Collection("sent_messages").Doc(sm.ID).Set(ctx, sm)
-	if err != nil {
+	ctx := s.context()
+	if err := s.putSentMessage(ctx, sm); err != nil {
 		return fmt.Errorf("%w: failed to update sent message: %w", kv.ErrDBOperationFailed, err)
 	}
 	return nil
 }
 
 func (s *Store) ReserveSlot(slot time.Time, callID string) (bool, error) {
-	ctx := context.Background()
+	ctx := s.context()
 	key := slot.Format(time.RFC3339)
-	docRef := s.client.Collection("slots").Doc(key)
+	ref, err := s.slots(ctx)
+	if err != nil {
+		return false, err
+	}
+	docRef := ref.Doc(key)
 
-	err := s.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+	err = s.transaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
 		doc, err := tx.Get(docRef)
 		if err != nil && status.Code(err) != codes.NotFound {
 			return err
@@ -142,73 +874,157 @@ func (s *Store) ReserveSlot(slot time.Time, callID string) (bool, error) {
 	return true, nil
 }
 
-func (s *Store) ClearAllSlots() error {
-	ctx := context.Background()
-	ref := s.client.Collection("slots")
-	for {
-		iter := ref.Limit(100).Documents(ctx)
-		numDeleted, err := iter.GetAll()
+func (s *Store) ListSlots() (map[time.Time]string, error) {
+	ctx := s.context()
+	ref, err := s.slots(ctx)
+	if err != nil {
+		return nil, err
+	}
+	docs, err := s.getAll(ctx, ref)
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to list slots: %w", kv.ErrDBOperationFailed, err)
+	}
+
+	slots := make(map[time.Time]string, len(docs))
+	for _, doc := range docs {
+		slot, err := time.Parse(time.RFC3339, doc.Ref.ID)
 		if err != nil {
-			return fmt.Errorf("%w: failed to iterate documents: %w", kv.ErrDBOperationFailed, err)
+			return nil, fmt.Errorf("%w: failed to parse slot '%s': %w", kv.ErrSerializationFailed, doc.Ref.ID, err)
 		}
-		if len(numDeleted) == 0 {
-			return nil
+		callID, _ := doc.Data()["callId"].(string)
+		slots[slot.UTC()] = callID
+	}
+	return slots, nil
+}
+
+func (s *Store) ClearAllSlots() error {
+	ctx := s.context()
+	ref, err := s.slots(ctx)
+	if err != nil {
+		return err
+	}
+	return s.deleteAll(ctx, ref)
+}
+
+// HasBeenSent checks if the message for an occurrence of a call has a 'sent', 'deleted' or 'cancelled'
+// status.
+func (s *Store) HasBeenSent(campaignID, callID string, occurredAt time.Time, destType, destination string) (bool, error) {
+	ctx := s.context()
+	for _, id := range kv.SentIDs(campaignID, callID, occurredAt, destType, destination) {
+		doc, err := s.get(ctx, s.client.Collection("sent_messages").Doc(id))
+		if err != nil {
+			if status.Code(err) == codes.NotFound {
+				continue
+			}
+			return false, fmt.Errorf("%w: failed to get sent message: %w", kv.ErrDBOperationFailed, err)
 		}
 
-		batch := s.client.Batch()
-		for _, doc := range numDeleted {
-			batch.Delete(doc.Ref)
+		var sm kv.SentMessage
+		if err := doc.DataTo(&sm); err != nil {
+			return false, fmt.Errorf("%w: failed to unmarshal sent message: %w", kv.ErrSerializationFailed, err)
 		}
-		_, err = batch.Commit(ctx)
-		if err != nil {
-			return fmt.Errorf("%w: failed to commit batch delete: %w", kv.ErrDBOperationFailed, err)
+		if sm.Settles(occurredAt) {
+			return true, nil
 		}
 	}
+	return false, nil
 }
 
-// HasBeenSent checks if a message with the given sourceID and scheduledAt time has a 'sent' or 'deleted' status.
-func (s *Store) HasBeenSent(campaignID, callID, destType, destination string) (bool, error) {
-	ctx := context.Background()
-	id := s.generateID(campaignID, callID, destType, destination)
-	doc, err := s.client.Collection("sent_messages").Doc(id).Get(ctx)
+// ListSentMessages retrieves all sent messages from the store.
+func (s *Store) ListSentMessages() ([]*kv.SentMessage, error) {
+	ctx := s.context()
+	docs, err := s.getAll(ctx, s.client.Collection("sent_messages"))
 	if err != nil {
-		if status.Code(err) == codes.NotFound {
-			return false, nil
+		return nil, fmt.Errorf("%w: failed to list sent messages: %w", kv.ErrDBOperationFailed, err)
+	}
+
+	messages := make([]*kv.SentMessage, 0, len(docs))
+	for _, doc := range docs {
+		var sm kv.SentMessage
+		if err := doc.DataTo(&sm); err != nil {
+			return nil, fmt.Errorf("%w: failed to unmarshal sent message: %w", kv.ErrSerializationFailed, err)
 		}
-		return false, fmt.Errorf("%w: failed to get sent message: %w", kv.ErrDBOperationFailed, err)
+		messages = append(messages, &sm)
 	}
+	return messages, nil
+}
 
-	var sm kv.SentMessage
-	if err := doc.DataTo(&sm); err != nil {
-		return false, fmt.Errorf("%w: failed to unmarshal sent message: %w", kv.ErrSerializationFailed, err)
+// ListSentMessagesByDestination retrieves the messages sent to a destination, most recently scheduled first.
+// The query is served by a composite index on Destination and ScheduledAt (descending), which must
+// exist in the Firestore database.
+func (s *Store) ListSentMessagesByDestination(destination string, limit int) ([]*kv.SentMessage, error) {
+	ctx := s.context()
+	query := s.client.Collection("sent_messages").Where("Destination", "==", destination).OrderBy("ScheduledAt", firestore.Desc)
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	docs, err := s.getAll(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to list sent messages for destination '%s': %w", kv.ErrDBOperationFailed, destination, err)
+	}
+
+	messages := make([]*kv.SentMessage, 0, len(docs))
+	for _, doc := range docs {
+		var sm kv.SentMessage
+		if err := doc.DataTo(&sm); err != nil {
+			return nil, fmt.Errorf("%w: failed to unmarshal sent message: %w", kv.ErrSerializationFailed, err)
+		}
+		messages = append(messages, &sm)
+	}
+	return messages, nil
+}
+
+// ListSentMessagesByCampaign retrieves the messages sent for a campaign, most recently scheduled first.
+// They are sorted after they are read, so that the query needs no composite index.
+func (s *Store) ListSentMessagesByCampaign(campaignID string) ([]*kv.SentMessage, error) {
+	ctx := s.context()
+	docs, err := s.getAll(ctx, s.client.Collection("sent_messages").Where("CampaignID", "==", campaignID))
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to list sent messages for campaign '%s': %w", kv.ErrDBOperationFailed, campaignID, err)
 	}
 
-	return sm.Status == kv.StatusSent || sm.Status == kv.StatusDeleted, nil
+	messages := make([]*kv.SentMessage, 0, len(docs))
+	for _, doc := range docs {
+		var sm kv.SentMessage
+		if err := doc.DataTo(&sm); err != nil {
+			return nil, fmt.Errorf("%w: failed to unmarshal sent message: %w", kv.ErrSerializationFailed, err)
+		}
+		messages = append(messages, &sm)
+	}
+	sort.Slice(messages, func(i, j int) bool {
+		return messages[i].ScheduledAt.After(messages[j].ScheduledAt)
+	})
+	return messages, nil
 }
 
-// ListSentMessages retrieves all sent messages from the store.
-func (s *Store) ListSentMessages() ([]*kv.SentMessage, error) {
-	ctx := context.Background()
+// ListExpiringMessages retrieves the sent messages that are due to be deleted at or before a time.
+func (s *Store) ListExpiringMessages(before time.Time) ([]*kv.SentMessage, error) {
+	ctx := s.context()
+	// Messages that are kept have a zero DeleteAt, which is long before the epoch. Filtering on a single
+	// field needs no composite index.
+	query := s.client.Collection("sent_messages").Where("DeleteAt", ">", time.Unix(0, 0)).Where("DeleteAt", "<=", before)
+	docs, err := s.getAll(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to list expiring sent messages: %w", kv.ErrDBOperationFailed, err)
+	}
+
 	var messages []*kv.SentMessage
-	iter := s.client.Collection("sent_messages").Documents(ctx)
-	for {
-		doc, err := iter.Next()
-		if err != nil {
-			break
-		}
+	for _, doc := range docs {
 		var sm kv.SentMessage
 		if err := doc.DataTo(&sm); err != nil {
 			return nil, fmt.Errorf("%w: failed to unmarshal sent message: %w", kv.ErrSerializationFailed, err)
 		}
-		messages = append(messages, &sm)
+		if sm.Status == kv.StatusSent {
+			messages = append(messages, &sm)
+		}
 	}
 	return messages, nil
 }
 
 // GetSentMessage retrieves a single sent message from the store.
 func (s *Store) GetSentMessage(id string) (*kv.SentMessage, error) {
-	ctx := context.Background()
-	doc, err := s.client.Collection("sent_messages").Doc(id).Get(ctx)
+	ctx := s.context()
+	doc, err := s.get(ctx, s.client.Collection("sent_messages").Doc(id))
 	if err != nil {
 		if status.Code(err) == codes.NotFound {
 			// If the full ID isn't found, try to find it by short ID.
@@ -226,10 +1042,9 @@ func (s *Store) GetSentMessage(id string) (*kv.SentMessage, error) {
 
 // GetSentMessageByShortID retrieves a single sent message from the store by its short ID.
 func (s *Store) GetSentMessageByShortID(shortID string) (*kv.SentMessage, error) {
-	ctx := context.Background()
+	ctx := s.context()
 	end := shortID + "~"
-	iter := s.client.Collection("sent_messages").Where("ShortID", ">=", shortID).Where("ShortID", "<", end).Documents(ctx)
-	docs, err := iter.GetAll()
+	docs, err := s.getAll(ctx, s.client.Collection("sent_messages").Where("ShortID", ">=", shortID).Where("ShortID", "<", end))
 	if err != nil {
 		return nil, fmt.Errorf("%w: failed to get sent message by short id: %w", kv.ErrDBOperationFailed, err)
 	}
@@ -255,9 +1070,12 @@ func (s *Store) DeleteSentMessage(id string) error {
 		return err
 	}
 
-	ctx := context.Background()
-	_, err = s.client.Collection("sent_messages").Doc(sm.ID).Update(ctx, []firestore.Update{
+	ctx := s.context()
+	sm.Status = kv.StatusDeleted
+	sm.Supersede(sm, time.Now())
+	err = s.update(ctx, s.client.Collection("sent_messages").Doc(sm.ID), []firestore.Update{
 		{Path: "Status", Value: kv.StatusDeleted},
+		{Path: "History", Value: sm.History},
 	})
 	if err != nil {
 		if status.Code(err) == codes.NotFound {
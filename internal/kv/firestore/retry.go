@@ -0,0 +1,187 @@
+package firestore
+
+import (
+	"context"
+	"log/slog"
+	"math/rand/v2"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/api/option"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Defaults of the options of the store.
+const (
+	defaultTimeout        = 30 * time.Second
+	defaultAttempts       = 5
+	defaultInitialBackoff = 100 * time.Millisecond
+	defaultMaxBackoff     = 5 * time.Second
+)
+
+// Option configures the store.
+type Option func(*Store)
+
+// WithTimeout sets the deadline of each attempt at a request to Firestore.
+func WithTimeout(timeout time.Duration) Option {
+	return func(s *Store) {
+		s.timeout = timeout
+	}
+}
+
+// WithRetry sets how many times a request that failed with a transient error is attempted, and the
+// backoff between attempts, which doubles from initial up to max.
+func WithRetry(attempts int, initial, max time.Duration) Option {
+	return func(s *Store) {
+		s.retry = retryPolicy{attempts: attempts, initial: initial, max: max}
+	}
+}
+
+// WithConnectionPool sets the number of gRPC connections requests to Firestore are spread over. The
+// connections are kept open and reused for the life of the store.
+func WithConnectionPool(size int) Option {
+	return func(s *Store) {
+		if size > 0 {
+			s.clientOpts = append(s.clientOpts, option.WithGRPCConnectionPool(size))
+		}
+	}
+}
+
+// retryPolicy retries requests that failed with a transient error, backing off exponentially with
+// jitter between attempts.
+type retryPolicy struct {
+	attempts int
+	initial  time.Duration
+	max      time.Duration
+	// sleep waits for the duration, or until the context is done. It is replaced in tests.
+	sleep func(ctx context.Context, d time.Duration) error
+}
+
+// transient reports whether a request that failed with the error may succeed if it is attempted
+// again. Firestore returns ABORTED on contention, UNAVAILABLE when it cannot be reached, and
+// RESOURCE_EXHAUSTED when it is shedding load; DEADLINE_EXCEEDED is returned when an attempt runs out
+// of time. Every write of the store is idempotent, so all of them can be attempted again.
+func transient(err error) bool {
+	switch status.Code(err) {
+	case codes.Aborted, codes.Unavailable, codes.ResourceExhausted, codes.DeadlineExceeded:
+		return true
+	}
+	return false
+}
+
+// do runs fn until it succeeds, fails with an error that is not transient, or has been attempted as
+// many times as the policy allows. Each attempt has its own deadline of timeout.
+func (p retryPolicy) do(ctx context.Context, timeout time.Duration, fn func(ctx context.Context) error) error {
+	sleep := p.sleep
+	if sleep == nil {
+		sleep = sleepContext
+	}
+
+	backoff := p.initial
+	for attempt := 1; ; attempt++ {
+		err := attemptWithTimeout(ctx, timeout, fn)
+		if err == nil || !transient(err) || attempt >= p.attempts {
+			return err
+		}
+
+		// Full jitter spreads out the retries of processes that failed together.
+		wait := time.Duration(rand.Int64N(int64(backoff) + 1))
+		slog.Debug("retrying firestore request", "attempt", attempt, "backoff", wait, "error", err)
+		if err := sleep(ctx, wait); err != nil {
+			return err
+		}
+		backoff = min(2*backoff, p.max)
+	}
+}
+
+func attemptWithTimeout(ctx context.Context, timeout time.Duration, fn func(ctx context.Context) error) error {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	return fn(ctx)
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// do runs fn with the retry policy and deadline of the store.
+func (s *Store) do(ctx context.Context, fn func(ctx context.Context) error) error {
+	return s.retry.do(ctx, s.timeout, fn)
+}
+
+// get reads a document.
+func (s *Store) get(ctx context.Context, ref *firestore.DocumentRef) (*firestore.DocumentSnapshot, error) {
+	var doc *firestore.DocumentSnapshot
+	err := s.do(ctx, func(ctx context.Context) (err error) {
+		doc, err = ref.Get(ctx)
+		return err
+	})
+	return doc, err
+}
+
+// documents is a collection or a query.
+type documents interface {
+	Documents(ctx context.Context) *firestore.DocumentIterator
+}
+
+// getAll reads every document of a collection or query.
+func (s *Store) getAll(ctx context.Context, q documents) ([]*firestore.DocumentSnapshot, error) {
+	var docs []*firestore.DocumentSnapshot
+	err := s.do(ctx, func(ctx context.Context) (err error) {
+		docs, err = q.Documents(ctx).GetAll()
+		return err
+	})
+	return docs, err
+}
+
+// set writes a document.
+func (s *Store) set(ctx context.Context, ref *firestore.DocumentRef, data any) error {
+	return s.do(ctx, func(ctx context.Context) error {
+		_, err := ref.Set(ctx, data)
+		return err
+	})
+}
+
+// delete removes a document.
+func (s *Store) delete(ctx context.Context, ref *firestore.DocumentRef) error {
+	return s.do(ctx, func(ctx context.Context) error {
+		_, err := ref.Delete(ctx)
+		return err
+	})
+}
+
+// update changes fields of a document.
+func (s *Store) update(ctx context.Context, ref *firestore.DocumentRef, updates []firestore.Update) error {
+	return s.do(ctx, func(ctx context.Context) error {
+		_, err := ref.Update(ctx, updates)
+		return err
+	})
+}
+
+// commit commits a batch of writes. A batch is applied atomically, so committing it again after a
+// transient error cannot apply part of it twice.
+func (s *Store) commit(ctx context.Context, batch *firestore.WriteBatch) error {
+	return s.do(ctx, func(ctx context.Context) error {
+		_, err := batch.Commit(ctx)
+		return err
+	})
+}
+
+// transaction runs fn in a transaction. The client retries a transaction that is aborted by
+// contention itself; the store retries it on the other transient errors too.
+func (s *Store) transaction(ctx context.Context, fn func(ctx context.Context, tx *firestore.Transaction) error) error {
+	return s.do(ctx, func(ctx context.Context) error {
+		return s.client.RunTransaction(ctx, fn)
+	})
+}
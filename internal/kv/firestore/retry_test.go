@@ -0,0 +1,84 @@
+package firestore
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestRetryPolicy(t *testing.T) {
+	var waits []time.Duration
+	p := retryPolicy{
+		attempts: 4,
+		initial:  100 * time.Millisecond,
+		max:      250 * time.Millisecond,
+		sleep: func(ctx context.Context, d time.Duration) error {
+			waits = append(waits, d)
+			return nil
+		},
+	}
+
+	t.Run("transient errors are retried", func(t *testing.T) {
+		waits = nil
+		calls := 0
+		err := p.do(context.Background(), time.Second, func(ctx context.Context) error {
+			calls++
+			_, ok := ctx.Deadline()
+			assert.True(t, ok, "each attempt has a deadline")
+			if calls < 3 {
+				return status.Error(codes.Unavailable, "unavailable")
+			}
+			return nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, 3, calls)
+		if assert.Len(t, waits, 2) {
+			assert.LessOrEqual(t, waits[0], 100*time.Millisecond)
+			assert.LessOrEqual(t, waits[1], 200*time.Millisecond)
+		}
+	})
+
+	t.Run("attempts are bounded", func(t *testing.T) {
+		waits = nil
+		calls := 0
+		err := p.do(context.Background(), 0, func(ctx context.Context) error {
+			calls++
+			return status.Error(codes.Aborted, "contention")
+		})
+		assert.Equal(t, codes.Aborted, status.Code(err))
+		assert.Equal(t, 4, calls)
+		if assert.Len(t, waits, 3) {
+			assert.LessOrEqual(t, waits[2], 250*time.Millisecond)
+		}
+	})
+
+	t.Run("other errors are not retried", func(t *testing.T) {
+		calls := 0
+		err := p.do(context.Background(), 0, func(ctx context.Context) error {
+			calls++
+			return status.Error(codes.NotFound, "missing")
+		})
+		assert.Equal(t, codes.NotFound, status.Code(err))
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("a cancelled context stops retrying", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		p := p
+		p.sleep = sleepContext
+		p.initial = time.Hour
+		calls := 0
+		err := p.do(ctx, 0, func(ctx context.Context) error {
+			calls++
+			cancel()
+			return status.Error(codes.ResourceExhausted, "slow down")
+		})
+		assert.True(t, errors.Is(err, context.Canceled))
+		assert.Equal(t, 1, calls)
+	})
+}
@@ -1,9 +1,13 @@
 package kv
 
 import (
+	"context"
 	"crypto/sha256"
 	"encoding/hex"
 	"errors"
+	"fmt"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/andrewhowdencom/ruf/internal/model"
@@ -15,6 +19,7 @@ var (
 	ErrDBOperationFailed   = errors.New("db operation failed")
 	ErrSerializationFailed = errors.New("serialization failed")
 	ErrAmbiguousID         = errors.New("ambiguous ID")
+	ErrLeaseHeld           = errors.New("lease held by another holder")
 )
 
 // Status represents the status of a call.
@@ -27,6 +32,31 @@ const (
 	StatusFailed Status = "failed"
 	// StatusDeleted means the call has been deleted.
 	StatusDeleted Status = "deleted"
+	// StatusCancelled means the call was cancelled before it was sent.
+	StatusCancelled Status = "cancelled"
+	// StatusExpired means the call was sent, and its message was deleted once it was no longer needed.
+	StatusExpired Status = "expired"
+	// StatusHeld means the call could not be rendered with its data, and is held until it can be.
+	StatusHeld Status = "held"
+	// StatusTrial means the call was kept from the destination while its campaign was on trial: it was
+	// logged, or sent to the canary destination of the campaign instead.
+	StatusTrial Status = "trial"
+	// StatusInvalidDestination means the call was not sent as its destination could not be sent to, such as
+	// a Slack channel that has been archived or an email address that does not parse.
+	StatusInvalidDestination Status = "invalid_destination"
+	// StatusSending means the worker has started to send the call and not yet recorded how it went. It is
+	// recorded before the destination is called, so that a worker that stops part way does not send the
+	// call again.
+	StatusSending Status = "sending"
+	// StatusInterrupted means the worker stopped while it was sending the call, so it is not known whether
+	// the call reached its destination. It is not sent again without being told to.
+	StatusInterrupted Status = "interrupted"
+	// StatusDeferred means the worker started to send the call, but its destination asked it to wait, so it
+	// is sent on a later tick.
+	StatusDeferred Status = "deferred"
+	// StatusPending means the call is due, and is held for the undo window of the worker before it is
+	// sent, during which it can still be cancelled.
+	StatusPending Status = "pending"
 )
 
 // SentMessage represents a message that has been sent.
@@ -40,6 +70,88 @@ type SentMessage struct {
 	Type         string    `json:"type"`
 	Status       Status    `json:"status"`
 	CampaignName string    `json:"campaign_name"`
+	// CampaignID is the campaign the message was sent for. It is kept with the message so that the
+	// history of a campaign can be read after it has been removed from its source.
+	CampaignID string `json:"campaign_id,omitempty"`
+
+	// OccurredAt is when the trigger of the call fired, before the call was moved, so that each
+	// occurrence of a recurring call is sent once. It is empty for messages recorded before it was kept.
+	OccurredAt time.Time `json:"occurred_at,omitzero"`
+
+	// SentAt is when the message was sent, which may be later than it was scheduled.
+	SentAt time.Time `json:"sent_at,omitempty"`
+
+	// AttemptedAt is when the worker started to send the message, while it is being sent.
+	AttemptedAt time.Time `json:"attempted_at,omitzero"`
+
+	// Delivery metadata reported by the provider.
+	MessageID string        `json:"message_id,omitempty"`
+	Permalink string        `json:"permalink,omitempty"`
+	Error     string        `json:"error,omitempty"`
+	Retries   int           `json:"retries,omitempty"`
+	Latency   time.Duration `json:"latency,omitempty"`
+
+	// DeleteAt is when the message is deleted from its destination, for calls whose messages are only
+	// needed for a while. It is zero for messages that are kept.
+	DeleteAt time.Time `json:"delete_at,omitzero"`
+
+	// ThreadID is the Message-ID of the first email of an update stream call, which the emails of its
+	// later occurrences are sent in the thread of.
+	ThreadID string `json:"thread_id,omitempty"`
+
+	// Snapshot is what was sent, kept so that the message can be exported as it was delivered.
+	Snapshot *Snapshot `json:"snapshot,omitempty"`
+
+	// History is each status the message has had, oldest first, as it was recorded by the store.
+	History []StatusChange `json:"history,omitempty"`
+}
+
+// StatusChange is a status a sent message was recorded with, and when.
+type StatusChange struct {
+	Status Status    `json:"status"`
+	At     time.Time `json:"at"`
+	Error  string    `json:"error,omitempty"`
+}
+
+// Supersede makes the message the record that replaces previous, which is nil if there was none: it keeps
+// the history of the previous record, and adds its own status when it differs from the last one. Stores
+// call it as they write the message.
+func (sm *SentMessage) Supersede(previous *SentMessage, at time.Time) {
+	history := sm.History
+	if previous != nil {
+		history = previous.History
+		if len(history) == 0 && previous.Status != "" {
+			// The previous record was written before the history was kept.
+			history = []StatusChange{{Status: previous.Status, At: previous.changedAt(), Error: previous.Error}}
+		}
+	}
+	if n := len(history); n == 0 || history[n-1].Status != sm.Status {
+		history = append(slices.Clip(history), StatusChange{Status: sm.Status, At: at.UTC(), Error: sm.Error})
+	}
+	sm.History = history
+}
+
+// changedAt returns when the message last changed status, as far as its record tells, for a record
+// written before its history was kept.
+func (sm *SentMessage) changedAt() time.Time {
+	switch {
+	case !sm.SentAt.IsZero():
+		return sm.SentAt
+	case !sm.AttemptedAt.IsZero():
+		return sm.AttemptedAt
+	}
+	return time.Time{}
+}
+
+// Snapshot is the content of a sent message.
+type Snapshot struct {
+	Author  string `json:"author,omitempty"`
+	Subject string `json:"subject,omitempty"`
+	// Content is the body after its template was rendered, before it was converted for the
+	// destination type. It is empty if the worker does not record content.
+	Content string `json:"content,omitempty"`
+	// Data is the data the templates were rendered with.
+	Data map[string]interface{} `json:"data,omitempty"`
 }
 
 // ScheduledCall is a call that has been expanded and is ready to be scheduled.
@@ -49,12 +161,200 @@ type ScheduledCall struct {
 	ScheduledAt time.Time
 }
 
+// TriggeredAt returns the time the trigger of the call fired at, before the call was moved.
+func (c *ScheduledCall) TriggeredAt() time.Time {
+	if len(c.Shifts) > 0 {
+		return c.Shifts[0].From
+	}
+	return c.ScheduledAt
+}
+
+// TriggerOverride pauses a single trigger of a call without editing its source.
+type TriggerOverride struct {
+	CallID string `json:"call_id"`
+	// Index is the zero-based position of the trigger in the call's list of triggers.
+	Index int `json:"index"`
+	// Until is when the trigger resumes. The trigger is paused indefinitely if it is zero.
+	Until time.Time `json:"until,omitempty"`
+}
+
+// Key returns the key the override is stored under.
+func (o *TriggerOverride) Key() string {
+	return fmt.Sprintf("%s#%d", o.CallID, o.Index)
+}
+
+// SeriesOverride changes how the steps of a series are scheduled, together, without editing its source.
+type SeriesOverride struct {
+	SeriesID string `json:"series_id"`
+	// Shift moves the anchor of the series, and with it every step that has not been sent.
+	Shift time.Duration `json:"shift,omitempty"`
+	// Paused stops the steps of the series from being scheduled until it is resumed.
+	Paused bool `json:"paused,omitempty"`
+	// Cancelled stops the steps of the series from being scheduled for good.
+	Cancelled bool `json:"cancelled,omitempty"`
+}
+
+// Signoff marks an item of a campaign's checklist as done for a single scheduled call.
+type Signoff struct {
+	CampaignID string `json:"campaign_id"`
+	CallID     string `json:"call_id"`
+	Item       string `json:"item"`
+	// By is who signed the item off.
+	By string    `json:"by"`
+	At time.Time `json:"at"`
+}
+
+// Cancellation stops a scheduled call from being sent to the recipients it has not been sent to yet.
+type Cancellation struct {
+	CallID string `json:"call_id"`
+	// By is who cancelled the call.
+	By     string    `json:"by,omitempty"`
+	Reason string    `json:"reason,omitempty"`
+	At     time.Time `json:"at"`
+}
+
+// Reschedule moves a single scheduled call to another time without editing its source. It is kept for the
+// occurrence of the call, so that refreshing the schedule moves it again rather than putting it back.
+type Reschedule struct {
+	CallID string    `json:"call_id"`
+	To     time.Time `json:"to"`
+	// Slot is whether the call was moved into a time slot, which it then holds.
+	Slot bool `json:"slot,omitempty"`
+	// By is who moved the call.
+	By string    `json:"by,omitempty"`
+	At time.Time `json:"at"`
+}
+
+// ApprovalStatus is where a call that requires approval is in being approved.
+type ApprovalStatus string
+
+const (
+	// ApprovalPending means the call is due, or will be, and waits for an approver to decide on it.
+	ApprovalPending ApprovalStatus = "pending_approval"
+	// ApprovalApproved means the call has been approved, and is sent when it is due.
+	ApprovalApproved ApprovalStatus = "approved"
+	// ApprovalRejected means the call has been rejected, and is cancelled rather than sent.
+	ApprovalRejected ApprovalStatus = "rejected"
+)
+
+// Approval is the decision of an approver on a scheduled call that requires approval.
+type Approval struct {
+	CampaignID string         `json:"campaign_id"`
+	CallID     string         `json:"call_id"`
+	Status     ApprovalStatus `json:"status"`
+	// RequestedAt is when the worker first found the call due and waiting for approval.
+	RequestedAt time.Time `json:"requested_at,omitzero"`
+	// By is who approved or rejected the call.
+	By     string    `json:"by,omitempty"`
+	Reason string    `json:"reason,omitempty"`
+	At     time.Time `json:"at,omitzero"`
+}
+
+// ConfirmationStatus is whether the author of a call has confirmed an occurrence of it.
+type ConfirmationStatus string
+
+const (
+	// ConfirmationPending means the author has been asked to confirm the call, and has not answered.
+	ConfirmationPending ConfirmationStatus = "pending_confirmation"
+	// ConfirmationConfirmed means the author has confirmed the call, and it is sent when it is due.
+	ConfirmationConfirmed ConfirmationStatus = "confirmed"
+	// ConfirmationCancelled means the call has been cancelled, by its author or because they did not
+	// answer in time.
+	ConfirmationCancelled ConfirmationStatus = "cancelled"
+)
+
+// Confirmation is the answer of the author of a scheduled call to the preview they were sent of it.
+type Confirmation struct {
+	CampaignID string             `json:"campaign_id"`
+	CallID     string             `json:"call_id"`
+	Status     ConfirmationStatus `json:"status"`
+	// RequestedAt is when the author was sent the preview.
+	RequestedAt time.Time `json:"requested_at,omitzero"`
+	// By is who confirmed or cancelled the call. It is empty when it was cancelled as no one answered.
+	By string    `json:"by,omitempty"`
+	At time.Time `json:"at,omitzero"`
+}
+
+// DeadLetter is a message that failed to send, kept with the call it was rendered from so that it can
+// be replayed once the cause of the failure has been fixed.
+type DeadLetter struct {
+	// ID is the ID of the sent message that failed.
+	ID          string `json:"id"`
+	CampaignID  string `json:"campaign_id"`
+	CallID      string `json:"call_id"`
+	Type        string `json:"type"`
+	Destination string `json:"destination"`
+	// Call is the call, as a ScheduledCall in JSON, with the data and times it was rendered with.
+	Call  []byte `json:"call"`
+	Error string `json:"error"`
+	// Attempts is how many times the message has failed to send.
+	Attempts int       `json:"attempts"`
+	FailedAt time.Time `json:"failed_at"`
+}
+
+// CachedSource is the last content of a source that was fetched and parsed, kept so that the source
+// can be fallen back on while it cannot be fetched.
+type CachedSource struct {
+	URL string `json:"url"`
+	// State is the state the source was fetched at.
+	State string `json:"state"`
+	// Source is the parsed source, as JSON.
+	Source    []byte    `json:"source"`
+	FetchedAt time.Time `json:"fetched_at"`
+}
+
+// Lease grants one of several processes sharing a datastore the right to act alone, until it expires.
+type Lease struct {
+	Name   string `json:"name"`
+	Holder string `json:"holder"`
+	// Expires is when the lease lapses unless the holder renews it.
+	Expires time.Time `json:"expires"`
+}
+
+// WorkerStatus is the heartbeat a worker writes to the datastore after each refresh and tick, so that
+// whether it is alive and current can be told from another machine sharing the datastore.
+type WorkerStatus struct {
+	// Holder identifies the worker, by the name it holds the lease under.
+	Holder string `json:"holder"`
+	// Leader is whether the worker held the lease, and so sent calls, when it last wrote its status.
+	Leader    bool      `json:"leader"`
+	DryRun    bool      `json:"dry_run,omitempty"`
+	StartedAt time.Time `json:"started_at"`
+	// UpdatedAt is when the worker last wrote its status.
+	UpdatedAt time.Time `json:"updated_at"`
+	// StoppedAt is when the worker shut down. It is zero while the worker runs.
+	StoppedAt time.Time `json:"stopped_at,omitzero"`
+
+	LastRefresh  time.Time `json:"last_refresh,omitzero"`
+	RefreshError string    `json:"refresh_error,omitempty"`
+	LastTick     time.Time `json:"last_tick,omitzero"`
+	TickError    string    `json:"tick_error,omitempty"`
+
+	// The counts of the last tick: the calls scheduled, those due, those processed and removed from the
+	// schedule, those kept for a later tick, and those that could not be processed.
+	Scheduled int `json:"scheduled"`
+	Due       int `json:"due"`
+	Processed int `json:"processed"`
+	Kept      int `json:"kept"`
+	Errors    int `json:"errors"`
+}
+
 // Storer is an interface that defines the methods for interacting with the datastore.
 type Storer interface {
 	AddSentMessage(campaignID, callID string, sm *SentMessage) error
 	UpdateSentMessage(sm *SentMessage) error
-	HasBeenSent(campaignID, callID, destType, destination string) (bool, error)
+	// HasBeenSent reports whether the occurrence of a call that fired at occurredAt was sent, deleted or
+	// cancelled for a destination.
+	HasBeenSent(campaignID, callID string, occurredAt time.Time, destType, destination string) (bool, error)
 	ListSentMessages() ([]*SentMessage, error)
+	// ListSentMessagesByDestination returns the messages sent to a destination, most recently
+	// scheduled first. A limit of zero or less returns all of them.
+	ListSentMessagesByDestination(destination string, limit int) ([]*SentMessage, error)
+	// ListSentMessagesByCampaign returns the messages sent for a campaign, most recently scheduled
+	// first.
+	ListSentMessagesByCampaign(campaignID string) ([]*SentMessage, error)
+	// ListExpiringMessages returns the sent messages that are due to be deleted at or before a time.
+	ListExpiringMessages(before time.Time) ([]*SentMessage, error)
 	GetSentMessage(id string) (*SentMessage, error)
 	GetSentMessageByShortID(shortID string) (*SentMessage, error)
 	DeleteSentMessage(id string) error
@@ -63,21 +363,187 @@ type Storer interface {
 	// Slot management
 	ReserveSlot(slot time.Time, callID string) (bool, error)
 	ClearAllSlots() error
+	// ListSlots returns the slot reservations, keyed by slot time, with the ID of the call holding each.
+	ListSlots() (map[time.Time]string, error)
 
 	// Scheduled call management
 	AddScheduledCall(call *ScheduledCall) error
+	AddScheduledCalls(calls []*ScheduledCall) error
 	GetScheduledCall(id string) (*ScheduledCall, error)
 	ListScheduledCalls() ([]*ScheduledCall, error)
 	DeleteScheduledCall(id string) error
 	ClearScheduledCalls() error
+	// ReplaceSchedule atomically replaces all scheduled calls and slot reservations.
+	ReplaceSchedule(calls []*ScheduledCall, slots map[time.Time]string) error
+
+	// Trigger override management
+	SetTriggerOverride(o *TriggerOverride) error
+	ListTriggerOverrides() ([]*TriggerOverride, error)
+	DeleteTriggerOverride(callID string, index int) error
+
+	// Series override management
+	SetSeriesOverride(o *SeriesOverride) error
+	ListSeriesOverrides() ([]*SeriesOverride, error)
+	DeleteSeriesOverride(seriesID string) error
+
+	// Checklist sign-off management
+	// AddSignoff adds or replaces the sign-off of a checklist item.
+	AddSignoff(s *Signoff) error
+	// ListSignoffs returns the sign-offs of the checklist items of a scheduled call.
+	ListSignoffs(campaignID, callID string) ([]*Signoff, error)
+
+	// Cancellation management
+	// CancelCall adds or replaces the cancellation of a scheduled call.
+	CancelCall(c *Cancellation) error
+	// GetCancellation returns the cancellation of a scheduled call, or an error wrapping ErrNotFound.
+	GetCancellation(callID string) (*Cancellation, error)
+	// DeleteCancellation removes the cancellation of a scheduled call.
+	DeleteCancellation(callID string) error
+
+	// Reschedule management
+	// SetReschedule adds or replaces the move of a scheduled call.
+	SetReschedule(r *Reschedule) error
+	// ListReschedules returns the moves of every scheduled call.
+	ListReschedules() ([]*Reschedule, error)
+	// DeleteReschedule removes the move of a scheduled call.
+	DeleteReschedule(callID string) error
+
+	// Approval management
+	// SetApproval adds or replaces the approval of a scheduled call.
+	SetApproval(a *Approval) error
+	// GetApproval returns the approval of a scheduled call, or an error wrapping ErrNotFound.
+	GetApproval(callID string) (*Approval, error)
+	// ListApprovals returns the approvals of every scheduled call.
+	ListApprovals() ([]*Approval, error)
+
+	// Confirmation management
+	// SetConfirmation adds or replaces the confirmation of a scheduled call.
+	SetConfirmation(c *Confirmation) error
+	// GetConfirmation returns the confirmation of a scheduled call, or an error wrapping ErrNotFound.
+	GetConfirmation(callID string) (*Confirmation, error)
+
+	// Dead letter management
+	// AddDeadLetter adds or replaces the dead letter of a message that failed to send.
+	AddDeadLetter(d *DeadLetter) error
+	// GetDeadLetter returns the dead letter of a sent message, or an error wrapping ErrNotFound.
+	GetDeadLetter(id string) (*DeadLetter, error)
+	// ListDeadLetters returns every dead letter.
+	ListDeadLetters() ([]*DeadLetter, error)
+	// DeleteDeadLetter removes the dead letter of a sent message.
+	DeleteDeadLetter(id string) error
+
+	// Source cache management
+	// SetCachedSource adds or replaces the cached content of a source.
+	SetCachedSource(c *CachedSource) error
+	// GetCachedSource returns the cached content of a source, or an error wrapping ErrNotFound.
+	GetCachedSource(url string) (*CachedSource, error)
+
+	// Lease management
+	// AcquireLease takes or renews the named lease for the holder until now+ttl. If another holder has a
+	// lease that has not expired, it returns that lease along with an error wrapping ErrLeaseHeld.
+	AcquireLease(name, holder string, now time.Time, ttl time.Duration) (*Lease, error)
+
+	// Worker status management
+	// SetWorkerStatus adds or replaces the status of a worker.
+	SetWorkerStatus(ws *WorkerStatus) error
+	// ListWorkerStatuses returns the status of every worker that has written one.
+	ListWorkerStatuses() ([]*WorkerStatus, error)
 
 	// Schema version management
 	GetSchemaVersion() (int, error)
 	SetSchemaVersion(version int) error
 }
 
+// ContextStorer is a Storer whose operations can be bound to a context, so that they give up once it
+// is done, such as when a call has taken longer to send than it is allowed.
+type ContextStorer interface {
+	Storer
+	// WithContext returns the store with its operations bound to the context. The returned store shares
+	// the state of the store it was made from.
+	WithContext(ctx context.Context) Storer
+}
+
+// WithContext returns the store with its operations bound to the context, if it can be bound to one,
+// or the store as it is otherwise.
+func WithContext(ctx context.Context, store Storer) Storer {
+	if cs, ok := store.(ContextStorer); ok {
+		return cs.WithContext(ctx)
+	}
+	return store
+}
+
+// GenerateID generates the ID of a sent message for an occurrence of a call delivered to a
+// destination. The occurrence is the time the trigger of the call fired; messages recorded before it
+// was kept have none.
+func GenerateID(campaignID, callID string, occurredAt time.Time, destType, destination string) string {
+	parts := []string{campaignID, callID, destType, destination}
+	if !occurredAt.IsZero() {
+		parts = append(parts, occurredAt.UTC().Format(time.RFC3339))
+	}
+	return strings.Join(parts, "@")
+}
+
+// SentIDs returns the IDs the message sent for an occurrence of a call may be recorded under: its own,
+// then the ID of the call alone that messages were recorded under before occurrences were kept.
+func SentIDs(campaignID, callID string, occurredAt time.Time, destType, destination string) []string {
+	ids := []string{GenerateID(campaignID, callID, occurredAt, destType, destination)}
+	if !occurredAt.IsZero() {
+		ids = append(ids, GenerateID(campaignID, callID, time.Time{}, destType, destination))
+	}
+	return ids
+}
+
+// Settles reports whether the message means the occurrence of its call that fired at occurredAt must
+// not be sent again: it was sent, deleted, cancelled, expired or kept back on trial, or is being or may
+// have been sent, for that occurrence. A message that does not know its occurrence settles every
+// occurrence, as it did before occurrences were kept.
+func (sm *SentMessage) Settles(occurredAt time.Time) bool {
+	switch sm.Status {
+	case StatusSent, StatusDeleted, StatusCancelled, StatusExpired, StatusTrial, StatusSending, StatusInterrupted:
+	default:
+		return false
+	}
+	return sm.OccurredAt.IsZero() || occurredAt.IsZero() || sm.OccurredAt.Equal(occurredAt)
+}
+
+// Failed reports whether the message could not be sent, whether because sending it failed or because its
+// destination could not be sent to.
+func (sm *SentMessage) Failed() bool {
+	return sm.Status == StatusFailed || sm.Status == StatusInvalidDestination
+}
+
 // GenerateShortID generates a short ID for a given ID.
 func GenerateShortID(id string) string {
 	hash := sha256.Sum256([]byte(id))
 	return hex.EncodeToString(hash[:])[:8]
 }
+
+// FindScheduledCall returns the scheduled call with the given ID, or the short ID generated from it.
+func FindScheduledCall(store Storer, id string) (*ScheduledCall, error) {
+	call, err := store.GetScheduledCall(id)
+	if err == nil {
+		return call, nil
+	}
+	if !errors.Is(err, ErrNotFound) {
+		return nil, err
+	}
+
+	calls, err := store.ListScheduledCalls()
+	if err != nil {
+		return nil, err
+	}
+	var found *ScheduledCall
+	for _, c := range calls {
+		if GenerateShortID(c.ID) != id {
+			continue
+		}
+		if found != nil {
+			return nil, fmt.Errorf("%w: '%s' matches more than one scheduled call", ErrAmbiguousID, id)
+		}
+		found = c
+	}
+	if found == nil {
+		return nil, fmt.Errorf("%w: scheduled call '%s'", ErrNotFound, id)
+	}
+	return found, nil
+}
@@ -0,0 +1,87 @@
+package kv
+
+import (
+	"slices"
+	"sort"
+	"time"
+)
+
+// SentQuery selects sent messages by what they were sent for, where and when. Its zero value selects
+// every message.
+type SentQuery struct {
+	CampaignID  string
+	Destination string
+	Type        string
+	// Statuses are the statuses a message may have, any of them; none selects every status.
+	Statuses []Status
+	// Since and Until bound when the messages were scheduled, from Since up to but not including Until.
+	// Either may be zero to leave that end open.
+	Since time.Time
+	Until time.Time
+	// Limit keeps only the most recently scheduled messages, up to this many, when it is above zero.
+	Limit int
+}
+
+// Matches reports whether the query selects the message, before its limit is applied.
+func (q SentQuery) Matches(sm *SentMessage) bool {
+	switch {
+	case q.CampaignID != "" && sm.CampaignID != q.CampaignID:
+		return false
+	case q.Destination != "" && sm.Destination != q.Destination:
+		return false
+	case q.Type != "" && sm.Type != q.Type:
+		return false
+	case len(q.Statuses) > 0 && !slices.Contains(q.Statuses, sm.Status):
+		return false
+	case !q.Since.IsZero() && sm.ScheduledAt.Before(q.Since):
+		return false
+	case !q.Until.IsZero() && !sm.ScheduledAt.Before(q.Until):
+		return false
+	}
+	return true
+}
+
+// QuerySentMessages returns the sent messages the query selects, most recently scheduled first. The
+// messages are read from the index of the destination or the campaign of the query when it has one, so
+// that they are found quickly no matter how many messages have been sent elsewhere.
+func QuerySentMessages(store Storer, q SentQuery) ([]*SentMessage, error) {
+	var (
+		messages []*SentMessage
+		err      error
+	)
+	switch {
+	case q.Destination != "":
+		limit := 0
+		if q.onlyDestination() {
+			// The index is ordered by when the messages were scheduled, so it reads only those it returns.
+			limit = q.Limit
+		}
+		messages, err = store.ListSentMessagesByDestination(q.Destination, limit)
+	case q.CampaignID != "":
+		messages, err = store.ListSentMessagesByCampaign(q.CampaignID)
+	default:
+		messages, err = store.ListSentMessages()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	selected := make([]*SentMessage, 0, len(messages))
+	for _, sm := range messages {
+		if q.Matches(sm) {
+			selected = append(selected, sm)
+		}
+	}
+	sort.SliceStable(selected, func(i, j int) bool {
+		return selected[i].ScheduledAt.After(selected[j].ScheduledAt)
+	})
+	if q.Limit > 0 {
+		selected = selected[:min(q.Limit, len(selected))]
+	}
+	return selected, nil
+}
+
+// onlyDestination reports whether the query selects messages by their destination alone, before its limit.
+func (q SentQuery) onlyDestination() bool {
+	return q.CampaignID == "" && q.Type == "" && len(q.Statuses) == 0 && q.Since.IsZero() && q.Until.IsZero()
+}
@@ -0,0 +1,147 @@
+// Package limits caps how often calls are sent to a destination, and keeps them from being sent in
+// its quiet hours, so that channels are not flooded with announcements.
+package limits
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ErrInvalidLimit is returned when a limit cannot be parsed.
+var ErrInvalidLimit = errors.New("invalid limit")
+
+// Default is the name that sets the limit of every destination of a type, or of every type.
+const Default = "default"
+
+// maxSteps bounds the search for a time a call may be sent, so that limits that never allow a call,
+// such as quiet hours that cover the whole day, do not stall it.
+const maxSteps = 1000
+
+// Window is a period of the day, as offsets from midnight. A window that ends before it starts spans
+// midnight.
+type Window struct {
+	Start time.Duration
+	End   time.Duration
+}
+
+// ParseWindow parses a window from its start and end, written as "15:04".
+func ParseWindow(start, end string) (Window, error) {
+	s, err := parseClock(start)
+	if err != nil {
+		return Window{}, err
+	}
+	e, err := parseClock(end)
+	if err != nil {
+		return Window{}, err
+	}
+	if s == e {
+		return Window{}, fmt.Errorf("%w: quiet hours from %s to %s are empty", ErrInvalidLimit, start, end)
+	}
+	return Window{Start: s, End: e}, nil
+}
+
+func parseClock(s string) (time.Duration, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("%w: '%s' is not a time of day: %w", ErrInvalidLimit, s, err)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// Rule is the limit of a destination.
+type Rule struct {
+	// MaxPerDay is the number of calls that may be sent on a day. Zero means no limit.
+	MaxPerDay int
+	// QuietHours are the periods of the day in which no calls are sent.
+	QuietHours []Window
+	// Location is the timezone that days and quiet hours are read in. It defaults to UTC.
+	Location *time.Location
+}
+
+func (r Rule) location() *time.Location {
+	if r.Location == nil {
+		return time.UTC
+	}
+	return r.Location
+}
+
+// Day returns the start of the day that t falls on.
+func (r Rule) Day(t time.Time) time.Time {
+	y, m, d := t.In(r.location()).Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, r.location())
+}
+
+// quietUntil returns the end of the quiet hours that t falls in, and false if it falls in none.
+func (r Rule) quietUntil(t time.Time) (time.Time, bool) {
+	local := t.In(r.location())
+	day := r.Day(t)
+	offset := time.Duration(local.Hour())*time.Hour + time.Duration(local.Minute())*time.Minute + time.Duration(local.Second())*time.Second
+	for _, w := range r.QuietHours {
+		switch {
+		case w.Start < w.End && offset >= w.Start && offset < w.End:
+			return day.Add(w.End), true
+		case w.Start > w.End && offset >= w.Start:
+			return day.AddDate(0, 0, 1).Add(w.End), true
+		case w.Start > w.End && offset < w.End:
+			return day.Add(w.End), true
+		}
+	}
+	return time.Time{}, false
+}
+
+// Next returns the earliest time at or after t that a call may be sent, given the number of calls
+// sent on the day starting at a time. It returns false if the rule does not allow a call at all.
+func (r Rule) Next(t time.Time, sent func(day time.Time) int) (time.Time, bool) {
+	for i := 0; i < maxSteps; i++ {
+		if until, ok := r.quietUntil(t); ok {
+			t = until
+			continue
+		}
+		if day := r.Day(t); r.MaxPerDay > 0 && sent(day) >= r.MaxPerDay {
+			t = day.AddDate(0, 0, 1)
+			continue
+		}
+		return t, true
+	}
+	return time.Time{}, false
+}
+
+// Limits are the rules of the destinations, by type.
+type Limits struct {
+	rules map[string]Rule
+}
+
+// New creates an empty set of limits.
+func New() *Limits {
+	return &Limits{rules: make(map[string]Rule)}
+}
+
+// Set sets the rule of a destination. A destination of Default sets the rule of every destination of
+// the type without one, and a type of Default that of every type without one.
+func (l *Limits) Set(destType, to string, rule Rule) {
+	l.rules[key(destType, to)] = rule
+}
+
+// For returns the rule of a destination, falling back to the defaults of its type, then of every
+// type. It returns false if no rule applies.
+func (l *Limits) For(destType, to string) (Rule, bool) {
+	if l == nil {
+		return Rule{}, false
+	}
+	for _, k := range []string{key(destType, to), key(destType, Default), key(Default, "")} {
+		if rule, ok := l.rules[k]; ok {
+			return rule, true
+		}
+	}
+	return Rule{}, false
+}
+
+// key identifies a destination. Names are compared without case, as configuration keys are.
+func key(destType, to string) string {
+	if destType == Default {
+		return Default
+	}
+	return strings.ToLower(destType) + "/" + strings.ToLower(to)
+}
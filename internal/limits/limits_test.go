@@ -0,0 +1,85 @@
+package limits_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/andrewhowdencom/ruf/internal/limits"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseWindow(t *testing.T) {
+	w, err := limits.ParseWindow("18:30", "09:00")
+	assert.NoError(t, err)
+	assert.Equal(t, limits.Window{Start: 18*time.Hour + 30*time.Minute, End: 9 * time.Hour}, w)
+
+	_, err = limits.ParseWindow("25:00", "09:00")
+	assert.ErrorIs(t, err, limits.ErrInvalidLimit)
+
+	_, err = limits.ParseWindow("09:00", "09:00")
+	assert.ErrorIs(t, err, limits.ErrInvalidLimit)
+}
+
+func TestRule_Next(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	assert.NoError(t, err)
+	overnight, err := limits.ParseWindow("18:00", "09:00")
+	assert.NoError(t, err)
+	lunch, err := limits.ParseWindow("12:00", "13:00")
+	assert.NoError(t, err)
+	rule := limits.Rule{MaxPerDay: 1, QuietHours: []limits.Window{overnight, lunch}, Location: berlin}
+
+	none := func(time.Time) int { return 0 }
+	at := func(day, hour, minute int) time.Time { return time.Date(2025, 3, day, hour, minute, 0, 0, berlin) }
+
+	for _, tc := range []struct {
+		name string
+		t    time.Time
+		sent func(time.Time) int
+		want time.Time
+	}{
+		{"allowed", at(4, 10, 0), none, at(4, 10, 0)},
+		{"in quiet hours", at(4, 12, 30), none, at(4, 13, 0)},
+		{"in quiet hours before midnight", at(4, 22, 0), none, at(5, 9, 0)},
+		{"in quiet hours after midnight", at(5, 2, 0), none, at(5, 9, 0)},
+		{"over the daily cap", at(4, 10, 0), func(day time.Time) int {
+			if day.Equal(at(4, 0, 0)) {
+				return 1
+			}
+			return 0
+		}, at(5, 9, 0)},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, ok := rule.Next(tc.t, tc.sent)
+			assert.True(t, ok)
+			assert.True(t, tc.want.Equal(got), "want %s, got %s", tc.want, got)
+		})
+	}
+
+	// A cap that is always reached never allows a call.
+	_, ok := rule.Next(at(4, 10, 0), func(time.Time) int { return 1 })
+	assert.False(t, ok)
+}
+
+func TestLimits_For(t *testing.T) {
+	l := limits.New()
+	l.Set("slack", "#general", limits.Rule{MaxPerDay: 3})
+	l.Set("slack", limits.Default, limits.Rule{MaxPerDay: 5})
+	l.Set(limits.Default, "", limits.Rule{MaxPerDay: 10})
+
+	rule, ok := l.For("slack", "#General")
+	assert.True(t, ok)
+	assert.Equal(t, 3, rule.MaxPerDay)
+
+	rule, ok = l.For("slack", "#random")
+	assert.True(t, ok)
+	assert.Equal(t, 5, rule.MaxPerDay)
+
+	rule, ok = l.For("email", "team@example.com")
+	assert.True(t, ok)
+	assert.Equal(t, 10, rule.MaxPerDay)
+
+	var unset *limits.Limits
+	_, ok = unset.For("slack", "#general")
+	assert.False(t, ok)
+}
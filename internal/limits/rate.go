@@ -0,0 +1,120 @@
+package limits
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// Rate is how fast calls may be sent to the destinations of a type, as a token bucket: Burst calls may
+// be sent at once, and the bucket refills at PerSecond calls a second. A PerSecond of zero means no
+// limit.
+type Rate struct {
+	PerSecond float64
+	Burst     int
+}
+
+// Buckets pace the calls sent to each destination type, which is served by a single workspace or
+// account, so that bulk sends stay under the rate limits of the provider rather than failing. They are
+// safe for concurrent use.
+type Buckets struct {
+	mu      sync.Mutex
+	rates   map[string]Rate
+	buckets map[string]*bucket
+}
+
+type bucket struct {
+	rate   Rate
+	tokens float64
+	// last is when the tokens were last refilled. It is in the future while the bucket is paused.
+	last time.Time
+}
+
+// NewBuckets creates a set of buckets without rates, which only pace the destination types that are
+// paused.
+func NewBuckets() *Buckets {
+	return &Buckets{rates: make(map[string]Rate), buckets: make(map[string]*bucket)}
+}
+
+// Set sets the rate of a destination type. A type of Default sets the rate of every type without one.
+func (b *Buckets) Set(destType string, rate Rate) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.rates[strings.ToLower(destType)] = rate
+}
+
+// Reserve takes a token for a call to a destination type at now, and returns how long to wait before
+// sending it. A call that is not sent after all should give its token back with Release.
+func (b *Buckets) Reserve(destType string, now time.Time) time.Duration {
+	if b == nil {
+		return 0
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	bk := b.bucket(destType, now)
+	if now.After(bk.last) {
+		if bk.rate.PerSecond > 0 {
+			bk.tokens = min(float64(bk.rate.Burst), bk.tokens+now.Sub(bk.last).Seconds()*bk.rate.PerSecond)
+		}
+		bk.last = now
+	}
+
+	// A paused bucket holds every call until it resumes.
+	wait := bk.last.Sub(now)
+	if bk.rate.PerSecond <= 0 {
+		return wait
+	}
+	bk.tokens--
+	if bk.tokens < 0 {
+		wait += time.Duration(-bk.tokens / bk.rate.PerSecond * float64(time.Second))
+	}
+	return wait
+}
+
+// Release gives back the token of a call that was reserved but not sent.
+func (b *Buckets) Release(destType string) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if bk, ok := b.buckets[strings.ToLower(destType)]; ok && bk.rate.PerSecond > 0 {
+		bk.tokens = min(float64(bk.rate.Burst), bk.tokens+1)
+	}
+}
+
+// Pause holds the calls to a destination type until a time, emptying its bucket, as when the provider
+// has asked the client to retry after it.
+func (b *Buckets) Pause(destType string, until time.Time) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	bk := b.bucket(destType, until)
+	if until.After(bk.last) {
+		bk.last = until
+	}
+	bk.tokens = min(bk.tokens, 0)
+}
+
+// bucket returns the bucket of a destination type, creating it full.
+func (b *Buckets) bucket(destType string, now time.Time) *bucket {
+	destType = strings.ToLower(destType)
+	if bk, ok := b.buckets[destType]; ok {
+		return bk
+	}
+	rate, ok := b.rates[destType]
+	if !ok {
+		rate = b.rates[Default]
+	}
+	if rate.Burst < 1 {
+		rate.Burst = 1
+	}
+	bk := &bucket{rate: rate, tokens: float64(rate.Burst), last: now}
+	b.buckets[destType] = bk
+	return bk
+}
@@ -0,0 +1,57 @@
+package limits_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/andrewhowdencom/ruf/internal/limits"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuckets(t *testing.T) {
+	b := limits.NewBuckets()
+	b.Set("slack", limits.Rate{PerSecond: 2, Burst: 2})
+	b.Set(limits.Default, limits.Rate{PerSecond: 1, Burst: 1})
+	now := time.Date(2025, 6, 2, 9, 0, 0, 0, time.UTC)
+
+	// The burst is sent at once, and the rest are paced at the rate.
+	assert.Equal(t, time.Duration(0), b.Reserve("slack", now))
+	assert.Equal(t, time.Duration(0), b.Reserve("slack", now))
+	assert.Equal(t, 500*time.Millisecond, b.Reserve("slack", now))
+	assert.Equal(t, time.Second, b.Reserve("slack", now))
+
+	// A call that is not sent gives its token back.
+	b.Release("slack")
+	assert.Equal(t, time.Second, b.Reserve("slack", now))
+
+	// The bucket refills over time, up to its burst.
+	assert.Equal(t, time.Duration(0), b.Reserve("SLACK", now.Add(time.Hour)))
+	assert.Equal(t, time.Duration(0), b.Reserve("slack", now.Add(time.Hour)))
+	assert.Equal(t, 500*time.Millisecond, b.Reserve("slack", now.Add(time.Hour)))
+
+	// Other types take the default rate.
+	assert.Equal(t, time.Duration(0), b.Reserve("email", now))
+	assert.Equal(t, time.Second, b.Reserve("email", now))
+}
+
+func TestBuckets_Pause(t *testing.T) {
+	b := limits.NewBuckets()
+	now := time.Date(2025, 6, 2, 9, 0, 0, 0, time.UTC)
+
+	// A type without a rate is not paced, until it is paused.
+	assert.Equal(t, time.Duration(0), b.Reserve("slack", now))
+	b.Pause("slack", now.Add(30*time.Second))
+	assert.Equal(t, 20*time.Second, b.Reserve("slack", now.Add(10*time.Second)))
+	assert.Equal(t, time.Duration(0), b.Reserve("slack", now.Add(time.Minute)))
+
+	// A paused type with a rate resumes with an empty bucket.
+	b.Set("chatwork", limits.Rate{PerSecond: 1, Burst: 5})
+	b.Pause("chatwork", now.Add(30*time.Second))
+	assert.Equal(t, 31*time.Second, b.Reserve("chatwork", now))
+
+	// Buckets that are not configured never pace calls.
+	var none *limits.Buckets
+	assert.Equal(t, time.Duration(0), none.Reserve("slack", now))
+	none.Pause("slack", now.Add(time.Minute))
+	none.Release("slack")
+}
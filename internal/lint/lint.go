@@ -0,0 +1,235 @@
+// Package lint checks sources of calls for mistakes that are valid by their schema, but are likely not
+// what their authors meant, such as calls that will never be sent again or templates that refer to data
+// the call does not have.
+package lint
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/andrewhowdencom/ruf/internal/model"
+	"github.com/andrewhowdencom/ruf/internal/processor"
+	"github.com/andrewhowdencom/ruf/internal/scheduler"
+	"github.com/andrewhowdencom/ruf/internal/sourcer"
+	"github.com/andrewhowdencom/ruf/internal/validator"
+)
+
+// The rules that calls are linted against.
+const (
+	// RuleInvalid is a call that fails validation, or whose templates do not parse.
+	RuleInvalid = "invalid"
+	// RuleDuplicateID is a call with the ID of another call of its campaign, in any source.
+	RuleDuplicateID = "duplicate-id"
+	// RuleEmptyDestination is a destination without recipients, or with an empty one.
+	RuleEmptyDestination = "empty-destination"
+	// RuleUnknownVariable is a template that refers to a value the data of its call does not have.
+	RuleUnknownVariable = "unknown-variable"
+	// RuleNoOccurrences is a call none of whose triggers fires again.
+	RuleNoOccurrences = "no-occurrences"
+	// RuleBeyondHorizon is a trigger that does not fire within the horizon calls are scheduled over.
+	RuleBeyondHorizon = "beyond-horizon"
+)
+
+// Severity is how serious an issue is.
+type Severity string
+
+const (
+	// SeverityError is an issue that stops the call from being sent as it was meant to be.
+	SeverityError Severity = "error"
+	// SeverityWarning is an issue that may be meant, or that depends on when the sources are linted.
+	SeverityWarning Severity = "warning"
+)
+
+// scheduledAtField is the value the worker adds to the data of every call it renders.
+const scheduledAtField = "ScheduledAt"
+
+// Issue is a problem found with a call.
+type Issue struct {
+	Source     string
+	CampaignID string
+	CallID     string
+	Rule       string
+	Severity   Severity
+	Message    string
+}
+
+// Option configures the linter.
+type Option func(*Linter)
+
+// WithTypes accepts destinations of the given types, defined in configuration, alongside the built-in
+// ones.
+func WithTypes(types ...string) Option {
+	return func(l *Linter) {
+		l.types = append(l.types, types...)
+	}
+}
+
+// WithHorizon sets how far after now calls are scheduled, for calls and campaigns without their own
+// horizon.
+func WithHorizon(after time.Duration) Option {
+	return func(l *Linter) {
+		l.after = after
+	}
+}
+
+// Linter lints sources of calls.
+type Linter struct {
+	sched *scheduler.Scheduler
+	types []string
+	after time.Duration
+	// templates parses the templates of calls, with stand-ins for the functions that read assets.
+	templates *processor.TemplateProcessor
+}
+
+// New creates a linter that finds when triggers fire with the scheduler.
+func New(sched *scheduler.Scheduler, opts ...Option) *Linter {
+	l := &Linter{
+		sched: sched,
+		after: 7 * 24 * time.Hour,
+		templates: processor.NewTemplateProcessor(processor.WithFuncs(template.FuncMap{
+			"include": func(string) (string, error) { return "", nil },
+		})),
+	}
+	for _, opt := range opts {
+		opt(l)
+	}
+	return l
+}
+
+// Lint lints the sources, each read from the URL at the same index of urls, as of now. The issues are
+// returned in the order of the sources and their calls.
+func (l *Linter) Lint(urls []string, sources []*sourcer.Source, now time.Time) []Issue {
+	var issues []Issue
+	defined := make(map[string]string)
+	for i, source := range sources {
+		if source == nil {
+			continue
+		}
+		calls := make([]*model.Call, len(source.Calls))
+		for j := range source.Calls {
+			calls[j] = &source.Calls[j]
+		}
+		invalid := validator.ValidateEach(calls, validator.WithTypes(l.types...))
+
+		for j, call := range calls {
+			report := func(rule string, severity Severity, format string, args ...any) {
+				issues = append(issues, Issue{
+					Source: urls[i], CampaignID: call.Campaign.ID, CallID: call.ID,
+					Rule: rule, Severity: severity, Message: fmt.Sprintf(format, args...),
+				})
+			}
+			if err := invalid[j]; err != nil {
+				report(RuleInvalid, SeverityError, "%s", err)
+			}
+
+			key := call.Campaign.ID + "/" + call.ID
+			if first, ok := defined[key]; ok {
+				report(RuleDuplicateID, SeverityError, "call '%s' of campaign '%s' is also defined in %s", call.ID, call.Campaign.ID, first)
+			} else {
+				defined[key] = urls[i]
+			}
+
+			for _, msg := range emptyDestinations(call) {
+				report(RuleEmptyDestination, SeverityError, "%s", msg)
+			}
+			for _, issue := range l.unknownVariables(call) {
+				report(issue.rule, SeverityError, "%s", issue.message)
+			}
+			for _, issue := range l.occurrences(call, source.Events, now) {
+				report(issue.rule, SeverityWarning, "%s", issue.message)
+			}
+		}
+	}
+	return issues
+}
+
+// finding is an issue found with a call, before it is reported with where the call is from.
+type finding struct {
+	rule    string
+	message string
+}
+
+// emptyDestinations describes the destinations of the call and its triggers that have no recipients, or
+// an empty one.
+func emptyDestinations(call *model.Call) []string {
+	var msgs []string
+	check := func(where string, dests []model.Destination) {
+		for _, dest := range dests {
+			switch {
+			case len(dest.To) == 0:
+				msgs = append(msgs, fmt.Sprintf("%s %s destination has no recipients in 'to'", where, dest.Type))
+			case slices.ContainsFunc(dest.To, func(to string) bool { return strings.TrimSpace(to) == "" }):
+				msgs = append(msgs, fmt.Sprintf("%s %s destination has an empty recipient in 'to'", where, dest.Type))
+			}
+		}
+	}
+	check("the", call.Destinations)
+	for i, trigger := range call.Triggers {
+		check(fmt.Sprintf("trigger %d's", i+1), trigger.Destinations)
+	}
+	return msgs
+}
+
+// unknownVariables finds the values the subject and content of the call refer to that its data does not
+// have. The data of a call that reads it from elsewhere is not known, so it is not checked.
+func (l *Linter) unknownVariables(call *model.Call) []finding {
+	var findings []finding
+	for _, field := range []struct {
+		name     string
+		template string
+	}{{"subject", call.Subject}, {"content", call.Content}} {
+		names, err := l.templates.Fields(field.template)
+		if err != nil {
+			findings = append(findings, finding{RuleInvalid, fmt.Sprintf("the %s is not a valid template: %s", field.name, err)})
+			continue
+		}
+		if call.DataFrom != "" {
+			continue
+		}
+		for _, name := range names {
+			if _, ok := call.Data[name]; !ok && name != scheduledAtField {
+				findings = append(findings, finding{RuleUnknownVariable, fmt.Sprintf("the %s refers to .%s, which is not in the data of the call", field.name, name)})
+			}
+		}
+	}
+	return findings
+}
+
+// occurrences finds the triggers of the call that do not fire within its horizon, or the call itself if
+// none of its triggers fires again. Triggers that follow another call fire once it is sent, so they are
+// left out.
+func (l *Linter) occurrences(call *model.Call, events []model.Event, now time.Time) []finding {
+	horizon := scheduler.HorizonAfter(*call, l.after)
+	var (
+		findings []finding
+		timed    int
+		firing   int
+	)
+	for i, trigger := range call.Triggers {
+		if trigger.After != "" {
+			continue
+		}
+		timed++
+		if !trigger.IsEnabled() {
+			continue
+		}
+		next, err := l.sched.Preview(trigger, 1, now, events...)
+		switch {
+		case err != nil:
+			findings = append(findings, finding{RuleNoOccurrences, fmt.Sprintf("trigger %d cannot be expanded: %s", i+1, err)})
+		case len(next) == 0:
+		case next[0].At.After(now.Add(horizon)):
+			firing++
+			findings = append(findings, finding{RuleBeyondHorizon, fmt.Sprintf("trigger %d next fires at %s, beyond the horizon of %s", i+1, next[0].At.Format(time.RFC3339), horizon)})
+		default:
+			firing++
+		}
+	}
+	if timed > 0 && firing == 0 {
+		findings = append(findings, finding{RuleNoOccurrences, "the call has no future occurrences, so it will not be sent again"})
+	}
+	return findings
+}
@@ -0,0 +1,61 @@
+package lint
+
+import (
+	"testing"
+	"time"
+
+	"github.com/andrewhowdencom/ruf/internal/datastore"
+	"github.com/andrewhowdencom/ruf/internal/model"
+	"github.com/andrewhowdencom/ruf/internal/scheduler"
+	"github.com/andrewhowdencom/ruf/internal/sourcer"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLint(t *testing.T) {
+	now := time.Date(2025, 6, 2, 12, 0, 0, 0, time.UTC)
+	campaign := model.Campaign{ID: "team", Name: "Team"}
+	slack := []model.Destination{{Type: "slack", To: []string{"#general"}}}
+	call := func(id, content string, triggers ...model.Trigger) model.Call {
+		return model.Call{ID: id, Subject: "Subject", Content: content, Campaign: campaign, Destinations: slack, Triggers: triggers}
+	}
+	cron := model.Trigger{Cron: "0 9 * * 1-5"}
+
+	standup := call("standup", "Hello {{ .Name }} at {{ .ScheduledAt }}", cron)
+	standup.Data = map[string]interface{}{"Name": "team"}
+	past := call("launch", "Launched!", model.Trigger{ScheduledAt: now.Add(-time.Hour)})
+	yearly := call("review", "Review", model.Trigger{Cron: "0 9 1 1 *"}, cron)
+	unknown := call("report", "{{ .Owner }} reports {{ range .Items }}{{ .Title }}{{ end }}", cron)
+	empty := call("empty", "Empty", cron)
+	empty.Destinations = []model.Destination{{Type: "email"}, {Type: "slack", To: []string{" "}}}
+	remote := call("remote", "{{ .Anything }}", cron)
+	remote.DataFrom = "https://example.com/data.json"
+
+	first := &sourcer.Source{Calls: []model.Call{standup, past, yearly, unknown}}
+	second := &sourcer.Source{Calls: []model.Call{empty, remote, call("standup", "Again", cron)}}
+
+	l := New(scheduler.New(datastore.NewMockStore()), WithHorizon(72*time.Hour))
+	issues := l.Lint([]string{"file://first.yaml", "file://second.yaml"}, []*sourcer.Source{first, second}, now)
+
+	type found struct{ source, call, rule string }
+	var got []found
+	for _, issue := range issues {
+		got = append(got, found{issue.Source, issue.CallID, issue.Rule})
+	}
+	assert.Equal(t, []found{
+		{"file://first.yaml", "launch", RuleNoOccurrences},
+		{"file://first.yaml", "review", RuleBeyondHorizon},
+		{"file://first.yaml", "report", RuleUnknownVariable},
+		{"file://first.yaml", "report", RuleUnknownVariable},
+		{"file://second.yaml", "empty", RuleEmptyDestination},
+		{"file://second.yaml", "empty", RuleEmptyDestination},
+		{"file://second.yaml", "standup", RuleDuplicateID},
+	}, got)
+
+	assert.Equal(t, SeverityWarning, issues[0].Severity)
+	assert.Contains(t, issues[1].Message, "trigger 1 next fires at 2026-01-01T09:00:00Z")
+	assert.Equal(t, "the content refers to .Owner, which is not in the data of the call", issues[2].Message)
+	// The data has no items to range over, but the fields of each item are not looked for in it.
+	assert.Equal(t, "the content refers to .Items, which is not in the data of the call", issues[3].Message)
+	assert.Equal(t, SeverityError, issues[6].Severity)
+	assert.Contains(t, issues[6].Message, "also defined in file://first.yaml")
+}
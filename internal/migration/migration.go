@@ -22,6 +22,16 @@ func Register(m Migration) {
 	migrations = append(migrations, m)
 }
 
+// Latest returns the version of the newest migration, which a datastore is at once every migration
+// has been applied to it.
+func Latest() int {
+	latest := 0
+	for _, m := range migrations {
+		latest = max(latest, m.Version())
+	}
+	return latest
+}
+
 // Apply runs all pending migrations against the datastore.
 func Apply(store kv.Storer) error {
 	slog.Info("applying database migrations")
@@ -0,0 +1,53 @@
+package migration
+
+import (
+	"log/slog"
+	"strings"
+
+	"github.com/andrewhowdencom/ruf/internal/kv"
+)
+
+func init() {
+	Register(&CampaignIDMigration{})
+}
+
+// CampaignIDMigration backfills the CampaignID field for sent messages recorded before it was kept.
+// The campaign is read from the ID of the message, which starts with it.
+type CampaignIDMigration struct{}
+
+// Version returns the migration version.
+func (m *CampaignIDMigration) Version() int {
+	return 2
+}
+
+// Description returns the migration description.
+func (m *CampaignIDMigration) Description() string {
+	return "Backfill CampaignID for sent messages"
+}
+
+// Up runs the migration.
+func (m *CampaignIDMigration) Up(store kv.Storer) error {
+	slog.Info("listing sent messages to backfill campaign IDs")
+	messages, err := store.ListSentMessages()
+	if err != nil {
+		return err
+	}
+
+	for _, msg := range messages {
+		if msg.CampaignID != "" {
+			continue
+		}
+		campaignID, _, ok := strings.Cut(msg.ID, "@")
+		if !ok {
+			slog.Warn("cannot find the campaign of message", "id", msg.ID)
+			continue
+		}
+		msg.CampaignID = campaignID
+		if err := store.UpdateSentMessage(msg); err != nil {
+			slog.Error("failed to update message", "id", msg.ID, "error", err)
+			continue
+		}
+	}
+
+	return nil
+}
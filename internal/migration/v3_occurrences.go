@@ -0,0 +1,82 @@
+package migration
+
+import (
+	"log/slog"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/andrewhowdencom/ruf/internal/kv"
+)
+
+func init() {
+	Register(&OccurrenceMigration{})
+}
+
+// OccurrenceMigration backfills the OccurredAt field for sent messages recorded before it was kept,
+// so that they settle only the occurrence of their call they were sent for. Messages keep their IDs,
+// which may be referred to elsewhere, and are found under them when an occurrence is checked.
+//
+// The occurrence is read from the ID of the call, which carries the time its trigger fired for every
+// trigger but those that follow an event or another call. Those messages are left without one, and
+// settle every occurrence of their call as they did before.
+type OccurrenceMigration struct{}
+
+// firedKinds are the kinds of trigger whose expanded calls carry the time they fired in their ID.
+var firedKinds = map[string]bool{
+	"scheduled_at": true, "cron": true, "rrule": true, "business_day": true, "nth_weekday": true,
+	"hijri": true, "solar_hijri": true, "hebrew": true, "chinese": true,
+}
+
+var timestampPattern = regexp.MustCompile(`\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}(?:Z|[+-]\d{2}:\d{2})`)
+
+// Version returns the migration version.
+func (m *OccurrenceMigration) Version() int {
+	return 3
+}
+
+// Description returns the migration description.
+func (m *OccurrenceMigration) Description() string {
+	return "Backfill OccurredAt for sent messages"
+}
+
+// Up runs the migration.
+func (m *OccurrenceMigration) Up(store kv.Storer) error {
+	slog.Info("listing sent messages to backfill occurrences")
+	messages, err := store.ListSentMessages()
+	if err != nil {
+		return err
+	}
+
+	for _, msg := range messages {
+		if !msg.OccurredAt.IsZero() {
+			continue
+		}
+		occurredAt, ok := firedAt(msg.SourceID)
+		if !ok {
+			slog.Debug("cannot find the occurrence of message", "id", msg.ID)
+			continue
+		}
+		msg.OccurredAt = occurredAt
+		if err := store.UpdateSentMessage(msg); err != nil {
+			slog.Error("failed to update message", "id", msg.ID, "error", err)
+			continue
+		}
+	}
+
+	return nil
+}
+
+// firedAt returns the time the trigger of an expanded call fired, read from its ID, such as
+// "standup:cron:0 9 * * 1-5:2025-01-06T09:00:00Z:slack:#team".
+func firedAt(callID string) (time.Time, bool) {
+	parts := strings.SplitN(callID, ":", 3)
+	if len(parts) < 3 || !firedKinds[parts[1]] {
+		return time.Time{}, false
+	}
+	t, err := time.Parse(time.RFC3339, timestampPattern.FindString(parts[2]))
+	if err != nil {
+		return time.Time{}, false
+	}
+	return t.UTC(), true
+}
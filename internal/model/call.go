@@ -1,11 +1,18 @@
 package model
 
-import "time"
+import (
+	"fmt"
+	"time"
+)
 
 // Destination represents a destination to send a call to.
 type Destination struct {
 	Type string   `json:"type" yaml:"type"`
 	To   []string `json:"to,omitempty" yaml:"to,omitempty"`
+
+	// LocalTime sends each recipient their copy at this time of day, such as "09:00", in the timezone
+	// of the recipient, on the day the trigger fires.
+	LocalTime string `json:"local_time,omitempty" yaml:"local_time,omitempty"`
 }
 
 // Trigger represents a scheduling mechanism for a call.
@@ -18,8 +25,85 @@ type Trigger struct {
 	Sequence    string    `json:"sequence,omitempty" yaml:"sequence,omitempty"`
 	Hijri       string    `json:"hijri,omitempty" yaml:"hijri,omitempty"`
 	Time        string    `json:"time,omitempty" yaml:"time,omitempty"`
+	Timezone    string    `json:"timezone,omitempty" yaml:"timezone,omitempty"`
+
+	// Until is the last time a cron trigger may fire.
+	Until time.Time `json:"until,omitzero" yaml:"until,omitempty"`
+	// Count is the number of times a cron trigger fires, counted from DStart.
+	Count int `json:"count,omitempty" yaml:"count,omitempty"`
+
+	// Hebrew fires on a date in the Hebrew calendar, such as "1 tishrei", at Time.
+	Hebrew string `json:"hebrew,omitempty" yaml:"hebrew,omitempty"`
+	// Chinese fires on a date in the Chinese calendar, as a day and month number, such as "15 8"
+	// or "1 leap 4", at Time.
+	Chinese string `json:"chinese,omitempty" yaml:"chinese,omitempty"`
+	// SolarHijri fires on a date in the Solar Hijri calendar, such as "1 farvardin", at Time.
+	SolarHijri string `json:"solar_hijri,omitempty" yaml:"solar_hijri,omitempty"`
+
+	// DeltaFrom is the point of a matching event that Delta is measured from: its start (the
+	// default) or its end.
+	DeltaFrom string `json:"delta_from,omitempty" yaml:"delta_from,omitempty"`
+
+	// Enabled, if set to false, stops the trigger from scheduling any calls.
+	Enabled *bool `json:"enabled,omitempty" yaml:"enabled,omitempty"`
+
+	// BusinessDay fires on the first or last business day of every month, at Time.
+	BusinessDay string `json:"business_day,omitempty" yaml:"business_day,omitempty"`
+	// NthWeekday fires on a weekday of every month, such as "2nd tuesday" or "last friday", at Time.
+	NthWeekday string `json:"nth_weekday,omitempty" yaml:"nth_weekday,omitempty"`
+
+	// ExDates are dates ("2006-01-02") or times (RFC 3339) on which the trigger is skipped.
+	ExDates []string `json:"exdates,omitempty" yaml:"exdates,omitempty"`
+	// ExceptRRule is an RRule matching the days on which the trigger is skipped.
+	ExceptRRule string `json:"except_rrule,omitempty" yaml:"except_rrule,omitempty"`
+
+	// SkipHolidays skips occurrences that fall on a holiday. It is shorthand for IfHoliday: skip.
+	SkipHolidays bool `json:"skip_holidays,omitempty" yaml:"skip_holidays,omitempty"`
+	// IfHoliday is what to do with occurrences that fall on a holiday.
+	IfHoliday string `json:"if_holiday,omitempty" yaml:"if_holiday,omitempty"`
+
+	// After schedules the call once the call with this ID, in the same campaign, has been sent, Delta
+	// after it was sent.
+	After string `json:"after,omitempty" yaml:"after,omitempty"`
+
+	// Destinations, if set, replace the destinations of the call for this trigger.
+	Destinations []Destination `json:"destinations,omitempty" yaml:"destinations,omitempty"`
+
+	// CallID, if set, replaces the ID of the call in the IDs of the calls the trigger schedules. It is
+	// set on triggers that were moved from another call, so that what was sent for them is still found.
+	CallID string `json:"call_id,omitempty" yaml:"call_id,omitempty"`
+
+	// Jitter delays each occurrence by a random offset up to this duration, such as "10m". The
+	// offset is derived from the occurrence, so it is the same every time the schedule is refreshed.
+	Jitter string `json:"jitter,omitempty" yaml:"jitter,omitempty"`
+	// Spread staggers the copies of each occurrence sent to different recipients evenly over this
+	// duration, such as "30m", rather than sending them all at once.
+	Spread string `json:"spread,omitempty" yaml:"spread,omitempty"`
+}
+
+// IsEnabled reports whether the trigger schedules calls. Triggers are enabled unless disabled explicitly.
+func (t Trigger) IsEnabled() bool {
+	return t.Enabled == nil || *t.Enabled
 }
 
+// Points of an event that the delta of a sequence trigger is measured from.
+const (
+	DeltaFromStart = "start"
+	DeltaFromEnd   = "end"
+)
+
+// Days of the month a business_day trigger fires on.
+const (
+	BusinessDayFirst = "first"
+	BusinessDayLast  = "last"
+)
+
+// Policies for occurrences of a trigger that fall on a holiday.
+const (
+	IfHolidaySkip            = "skip"
+	IfHolidayNextBusinessDay = "next_business_day"
+)
+
 // Call represents a message to be sent to a destination.
 type Call struct {
 	ID           string        `json:"id" yaml:"id"`
@@ -30,10 +114,130 @@ type Call struct {
 	Triggers     []Trigger              `json:"triggers" yaml:"triggers"`
 	Data         map[string]interface{} `json:"data,omitempty" yaml:"data,omitempty"`
 
+	// DataFrom is the URL of a JSON or YAML document whose fields the content is rendered with, under
+	// those of Data.
+	DataFrom string `json:"data_from,omitempty" yaml:"data_from,omitempty"`
+
+	// Mode is how the occurrences of the call are sent: each as a new message (ModePost, the default),
+	// or by editing the message the first occurrence sent (ModeUpdateStream).
+	Mode string `json:"mode,omitempty" yaml:"mode,omitempty"`
+
+	// AutoDeleteAfter, if set, is how long after they are sent the Slack messages of the call are
+	// deleted, as a duration such as "24h", for reminders that should not stay in the channel.
+	AutoDeleteAfter string `json:"auto_delete_after,omitempty" yaml:"auto_delete_after,omitempty"`
+
+	// Slots, if set, is the name of the profile of time slots, under slots.profiles, that the call is
+	// placed in, rather than the slots of its destinations.
+	Slots string `json:"slots,omitempty" yaml:"slots,omitempty"`
+
+	// Processors, if set, changes how the content of the call is processed before it is sent.
+	Processors *ProcessorOptions `json:"processors,omitempty" yaml:"processors,omitempty"`
+
+	// Priority orders the calls that are due together when the worker cannot send them all in one
+	// tick. Higher priorities are sent first.
+	Priority int `json:"priority,omitempty" yaml:"priority,omitempty"`
+
+	// RequiresApproval holds each scheduled call, once it is due, until an approver has approved it.
+	RequiresApproval bool `json:"requires_approval,omitempty" yaml:"requires_approval,omitempty"`
+
+	// Confirm, if set, sends the author of the call a preview of each occurrence before it is due, to
+	// confirm or cancel it.
+	Confirm *Confirm `json:"confirm,omitempty" yaml:"confirm,omitempty"`
+
+	// ExpiresAt, if set, stops every trigger of the call from scheduling it at or after this time.
+	ExpiresAt time.Time `json:"expires_at,omitzero" yaml:"expires_at,omitempty"`
+
+	// Horizon, if set, overrides how far around now the triggers of the call are expanded, and the
+	// horizon of its campaign.
+	Horizon *Horizon `json:"horizon,omitempty" yaml:"horizon,omitempty"`
+
 	Campaign Campaign `json:"campaign,omitempty" yaml:"campaign,omitempty"`
 
 	// Fields for expanded calls, not to be set in YAML
 	ScheduledAt time.Time `json:"-" yaml:"-"`
+	// Series is the ID of the series the call is a step of, if it is one.
+	Series string `json:"series,omitempty" yaml:"-"`
+	// DependsOn is set on calls that wait for another call to be sent before they are scheduled.
+	DependsOn *Dependency `json:"depends_on,omitempty" yaml:"-"`
+	// Shifts records every time the call was moved from the time its trigger fired, in order.
+	Shifts []Shift `json:"shifts,omitempty" yaml:"-"`
+	// Assets pins the remote content the call renders, by URL, to the SHA-256 of the content fetched
+	// when its source was refreshed.
+	Assets map[string]string `json:"assets,omitempty" yaml:"-"`
+}
+
+// Modes of sending the occurrences of a call.
+const (
+	ModePost = "post"
+	// ModeUpdateStream edits the Slack message the first occurrence of a call posted, and sends each
+	// email in the thread of the first, so that a recurring call stays a single evolving post.
+	ModeUpdateStream = "update_stream"
+)
+
+// OccurredAt returns the time the trigger of an expanded call fired, before the call was first moved,
+// such as into a time slot or off a holiday. It identifies the occurrence however the call is moved.
+func (c *Call) OccurredAt() time.Time {
+	if len(c.Shifts) > 0 {
+		return c.Shifts[0].From
+	}
+	return c.ScheduledAt
+}
+
+// Confirm is when the author of a call is asked to confirm an occurrence, and what happens if they do
+// not answer. Either may be left empty to use the configured default.
+type Confirm struct {
+	// Before is how long before the occurrence is due the author is asked, as a duration such as "30m".
+	Before string `json:"before,omitempty" yaml:"before,omitempty"`
+	// Default is what happens to an occurrence the author has not answered by the time it is due:
+	// ConfirmSend or ConfirmCancel.
+	Default string `json:"default,omitempty" yaml:"default,omitempty"`
+}
+
+// What happens to an occurrence the author has not confirmed or cancelled by the time it is due.
+const (
+	ConfirmSend   = "send"
+	ConfirmCancel = "cancel"
+)
+
+// Horizon is how far before and after now the triggers of a call are expanded, as durations such as
+// "2160h". Either may be left empty to use the configured window.
+type Horizon struct {
+	Before string `json:"before,omitempty" yaml:"before,omitempty"`
+	After  string `json:"after,omitempty" yaml:"after,omitempty"`
+}
+
+// Shift is a move of an expanded call to another time, such as into a time slot or off a holiday.
+type Shift struct {
+	Reason string    `json:"reason"`
+	Detail string    `json:"detail,omitempty"`
+	From   time.Time `json:"from"`
+	To     time.Time `json:"to"`
+}
+
+// The reasons an expanded call is moved from the time its trigger fired.
+const (
+	ShiftSlot      = "slot"
+	ShiftHoliday   = "holiday"
+	ShiftLimits    = "limits"
+	ShiftJitter    = "jitter"
+	ShiftSpread    = "spread"
+	ShiftLocalTime = "local_time"
+	ShiftMoved     = "moved"
+)
+
+// String returns the reason for the shift, followed by its detail, such as "holiday: Christmas Day".
+func (s Shift) String() string {
+	if s.Detail == "" {
+		return s.Reason
+	}
+	return s.Reason + ": " + s.Detail
+}
+
+// Dependency is a call of the same campaign that an expanded call waits for. Once it has been sent,
+// the call is scheduled Delay after it.
+type Dependency struct {
+	CallID string        `json:"call_id"`
+	Delay  time.Duration `json:"delay"`
 }
 
 // Event represents an event invocation.
@@ -41,6 +245,47 @@ type Event struct {
 	Destinations []Destination `json:"destinations,omitempty" yaml:"destinations,omitempty"`
 	Sequence     string        `json:"sequence" yaml:"sequence"`
 	StartTime    time.Time     `json:"start_time" yaml:"start_time"`
+
+	// EndTime is when the event ends. Either it or Duration may be set.
+	EndTime time.Time `json:"end_time,omitempty" yaml:"end_time,omitempty"`
+	// Duration is how long the event lasts, such as "1h30m".
+	Duration string `json:"duration,omitempty" yaml:"duration,omitempty"`
+}
+
+// End returns when the event ends, from its end time or its duration.
+func (e Event) End() (time.Time, error) {
+	if !e.EndTime.IsZero() {
+		return e.EndTime, nil
+	}
+	if e.Duration == "" {
+		return time.Time{}, fmt.Errorf("event '%s' starting at %s has neither an end_time nor a duration", e.Sequence, e.StartTime.Format(time.RFC3339))
+	}
+	duration, err := time.ParseDuration(e.Duration)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("event '%s' has an invalid duration: %w", e.Sequence, err)
+	}
+	return e.StartTime.Add(duration), nil
+}
+
+// EventSource is an external calendar whose events schedule the calls with a matching sequence
+// trigger. Exactly one of ICal and GoogleCalendar is set.
+type EventSource struct {
+	// ICal is the URL of an iCalendar feed.
+	ICal string `json:"ical,omitempty" yaml:"ical,omitempty"`
+	// GoogleCalendar is the ID of a Google Calendar, read with the application default credentials.
+	GoogleCalendar string `json:"google_calendar,omitempty" yaml:"google_calendar,omitempty"`
+	// Match assigns the events of the calendar to sequences.
+	Match []EventMatch `json:"match" yaml:"match"`
+}
+
+// EventMatch assigns the events of a calendar to a sequence, by their title or by a property.
+type EventMatch struct {
+	Sequence string `json:"sequence" yaml:"sequence"`
+	// Title is a regular expression matched against the title of each event.
+	Title string `json:"title,omitempty" yaml:"title,omitempty"`
+	// Property is the name of a property of each event, an "X-" property in iCalendar or an
+	// extended property in Google Calendar, whose value must be the sequence.
+	Property string `json:"property,omitempty" yaml:"property,omitempty"`
 }
 
 // Campaign represents a campaign.
@@ -48,4 +293,39 @@ type Campaign struct {
 	ID      string `json:"id" yaml:"id"`
 	Name    string `json:"name" yaml:"name"`
 	IconURL string `json:"icon_url,omitempty" yaml:"icon_url,omitempty"`
+
+	// Owners are the email addresses of the people responsible for the campaign, who are told when
+	// its calls fail or wait for sign-off.
+	Owners []string `json:"owners,omitempty" yaml:"owners,omitempty"`
+
+	// Blackouts are windows during which no calls in the campaign are sent.
+	Blackouts []Blackout `json:"blackouts,omitempty" yaml:"blackouts,omitempty"`
+
+	// Checklist are the items that must be signed off for each scheduled call of the campaign before it
+	// is sent.
+	Checklist []string `json:"checklist,omitempty" yaml:"checklist,omitempty"`
+
+	// Horizon, if set, overrides how far around now the triggers of the calls of the campaign are
+	// expanded.
+	Horizon *Horizon `json:"horizon,omitempty" yaml:"horizon,omitempty"`
+
+	// DryRun logs the calls of the campaign rather than sending them, as the --dry-run flag does for
+	// every campaign. It takes precedence over the canary destination.
+	DryRun bool `json:"dry_run,omitempty" yaml:"dry_run,omitempty"`
+	// CanaryDestination, if set, is sent the calls of the campaign in place of their own destinations.
+	CanaryDestination *Destination `json:"canary_destination,omitempty" yaml:"canary_destination,omitempty"`
+	// TrialOccurrences is how many occurrences of each call of the campaign are logged, or sent to the
+	// canary destination, before the call goes live. Zero keeps every occurrence on trial.
+	TrialOccurrences int `json:"trial_occurrences,omitempty" yaml:"trial_occurrences,omitempty"`
+
+	// Defaults are the fields the calls of the campaign take unless they set their own. They are applied
+	// when the source is parsed, and are not kept with each call.
+	Defaults *CallDefaults `json:"defaults,omitempty" yaml:"defaults,omitempty"`
+}
+
+// Blackout is a window of time, such as a holiday or change freeze, during which calls are skipped.
+type Blackout struct {
+	Start  time.Time `json:"start" yaml:"start"`
+	End    time.Time `json:"end" yaml:"end"`
+	Reason string    `json:"reason,omitempty" yaml:"reason,omitempty"`
 }
@@ -0,0 +1,93 @@
+package model
+
+import "maps"
+
+// CallDefaults are the fields that the calls of a campaign take unless they set their own, so that the
+// author and destinations shared by every call of a source are written once.
+type CallDefaults struct {
+	Author       string        `json:"author,omitempty" yaml:"author,omitempty"`
+	Destinations []Destination `json:"destinations,omitempty" yaml:"destinations,omitempty"`
+	// Data is merged under the data of each call.
+	Data       map[string]interface{} `json:"data,omitempty" yaml:"data,omitempty"`
+	Slots      string                 `json:"slots,omitempty" yaml:"slots,omitempty"`
+	Processors *ProcessorOptions      `json:"processors,omitempty" yaml:"processors,omitempty"`
+}
+
+// ProcessorOptions change how the content of a call is processed before it is sent. Options that are
+// not set are left to the configuration of the worker.
+type ProcessorOptions struct {
+	// StrictTemplates holds the call, rather than sending it, when its templates refer to values its
+	// data does not have, as worker.strict_templates does for every call.
+	StrictTemplates *bool `json:"strict_templates,omitempty" yaml:"strict_templates,omitempty"`
+	// Markdown, if false, sends the content as it is written, rather than converting it from Markdown to
+	// the format of each destination.
+	Markdown *bool `json:"markdown,omitempty" yaml:"markdown,omitempty"`
+	// Content, if set for a destination type, lists the processors its content is converted with, by
+	// name and in order, in place of those configured for the type. An empty list sends it as written.
+	Content map[string][]string `json:"content,omitempty" yaml:"content,omitempty"`
+}
+
+// Strict reports whether the templates of the call are strict, given whether the worker makes them so.
+func (o *ProcessorOptions) Strict(worker bool) bool {
+	if o == nil || o.StrictTemplates == nil {
+		return worker
+	}
+	return *o.StrictTemplates
+}
+
+// ConvertsMarkdown reports whether the content of the call is converted from Markdown.
+func (o *ProcessorOptions) ConvertsMarkdown() bool {
+	return o == nil || o.Markdown == nil || *o.Markdown
+}
+
+// ContentProcessors returns the names of the processors the content of the call is converted with for a
+// destination type, and whether the call sets them.
+func (o *ProcessorOptions) ContentProcessors(destType string) ([]string, bool) {
+	if o == nil {
+		return nil, false
+	}
+	names, ok := o.Content[destType]
+	return names, ok
+}
+
+// Apply sets the fields of a call that it does not set itself to the defaults. The data of the call is
+// merged over the default data, and each processor option it does not set is taken from the defaults,
+// as are the content processors of each destination type it does not set.
+func (d *CallDefaults) Apply(call *Call) {
+	if d == nil {
+		return
+	}
+	if call.Author == "" {
+		call.Author = d.Author
+	}
+	if len(call.Destinations) == 0 && len(d.Destinations) > 0 {
+		call.Destinations = append([]Destination(nil), d.Destinations...)
+	}
+	if len(d.Data) > 0 {
+		data := maps.Clone(d.Data)
+		maps.Copy(data, call.Data)
+		call.Data = data
+	}
+	if call.Slots == "" {
+		call.Slots = d.Slots
+	}
+	if d.Processors != nil {
+		processors := *d.Processors
+		if call.Processors != nil {
+			if call.Processors.StrictTemplates != nil {
+				processors.StrictTemplates = call.Processors.StrictTemplates
+			}
+			if call.Processors.Markdown != nil {
+				processors.Markdown = call.Processors.Markdown
+			}
+			if len(call.Processors.Content) > 0 {
+				processors.Content = maps.Clone(processors.Content)
+				if processors.Content == nil {
+					processors.Content = make(map[string][]string)
+				}
+				maps.Copy(processors.Content, call.Processors.Content)
+			}
+		}
+		call.Processors = &processors
+	}
+}
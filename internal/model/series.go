@@ -0,0 +1,102 @@
+package model
+
+import (
+	"fmt"
+	"maps"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Series is an ordered set of related calls, such as the kickoff, reminder, last call and wrap-up of a
+// program, each sent at an offset from a single anchor date. The calls of its steps are scheduled,
+// paused, cancelled and shifted together.
+type Series struct {
+	ID string `json:"id" yaml:"id"`
+	// Anchor is the time the offsets of the steps are measured from, such as the start of a program.
+	Anchor time.Time `json:"anchor" yaml:"anchor"`
+
+	// Author, Destinations and Data are those of the steps that do not set their own. The data of a
+	// step is merged over that of the series.
+	Author       string                 `json:"author,omitempty" yaml:"author,omitempty"`
+	Destinations []Destination          `json:"destinations,omitempty" yaml:"destinations,omitempty"`
+	Data         map[string]interface{} `json:"data,omitempty" yaml:"data,omitempty"`
+
+	Steps []SeriesStep `json:"steps" yaml:"steps"`
+
+	Campaign Campaign `json:"campaign,omitempty" yaml:"campaign,omitempty"`
+}
+
+// SeriesStep is a single call of a series.
+type SeriesStep struct {
+	ID string `json:"id" yaml:"id"`
+	// Offset is how long after the anchor the step is sent, or before it if it is negative, as a
+	// duration such as "-72h" or a number of days such as "-14d".
+	Offset string `json:"offset" yaml:"offset"`
+
+	Author       string                 `json:"author,omitempty" yaml:"author,omitempty"`
+	Subject      string                 `json:"subject,omitempty" yaml:"subject,omitempty"`
+	Content      string                 `json:"content" yaml:"content"`
+	Destinations []Destination          `json:"destinations,omitempty" yaml:"destinations,omitempty"`
+	Data         map[string]interface{} `json:"data,omitempty" yaml:"data,omitempty"`
+	DataFrom     string                 `json:"data_from,omitempty" yaml:"data_from,omitempty"`
+}
+
+// StepID returns the ID of the call of a step of a series.
+func StepID(seriesID, stepID string) string {
+	return seriesID + "." + stepID
+}
+
+// ParseOffset parses the offset of a step of a series: a duration, such as "-72h", or a number of
+// days, such as "-14d".
+func ParseOffset(offset string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(offset, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, fmt.Errorf("invalid offset '%s': must be a duration or a number of days", offset)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	d, err := time.ParseDuration(offset)
+	if err != nil {
+		return 0, fmt.Errorf("invalid offset '%s': must be a duration or a number of days", offset)
+	}
+	return d, nil
+}
+
+// Calls returns the calls of the steps of the series, in order, each triggered once at its offset from
+// the anchor.
+func (s Series) Calls() ([]Call, error) {
+	calls := make([]Call, 0, len(s.Steps))
+	for _, step := range s.Steps {
+		offset, err := ParseOffset(step.Offset)
+		if err != nil {
+			return nil, fmt.Errorf("step '%s': %w", step.ID, err)
+		}
+
+		call := Call{
+			ID:           StepID(s.ID, step.ID),
+			Author:       step.Author,
+			Subject:      step.Subject,
+			Content:      step.Content,
+			Destinations: step.Destinations,
+			DataFrom:     step.DataFrom,
+			Triggers:     []Trigger{{ScheduledAt: s.Anchor.Add(offset)}},
+			Series:       s.ID,
+			Campaign:     s.Campaign,
+		}
+		if call.Author == "" {
+			call.Author = s.Author
+		}
+		if len(call.Destinations) == 0 {
+			call.Destinations = s.Destinations
+		}
+		if len(s.Data) > 0 || len(step.Data) > 0 {
+			call.Data = make(map[string]interface{}, len(s.Data)+len(step.Data))
+			maps.Copy(call.Data, s.Data)
+			maps.Copy(call.Data, step.Data)
+		}
+		calls = append(calls, call)
+	}
+	return calls, nil
+}
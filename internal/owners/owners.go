@@ -0,0 +1,57 @@
+// Package owners finds the people responsible for a campaign, so that the failures, validation
+// errors and sign-offs of its calls reach them rather than a central team that routes them by hand.
+package owners
+
+import (
+	"errors"
+	"fmt"
+	"path"
+
+	"github.com/andrewhowdencom/ruf/internal/model"
+)
+
+// ErrInvalidRule is returned when a rule has no pattern, an invalid pattern or no owners.
+var ErrInvalidRule = errors.New("invalid owners rule")
+
+// Rule assigns owners to the campaigns whose ID matches a glob pattern, such as "team-a-*", as a line
+// of a CODEOWNERS file assigns owners to paths.
+type Rule struct {
+	Match  string   `mapstructure:"match"`
+	Owners []string `mapstructure:"owners"`
+}
+
+// Router finds the owners of campaigns from their own owners and a list of rules.
+type Router struct {
+	rules []Rule
+}
+
+// New creates a router of the rules. As in CODEOWNERS, the last rule that matches a campaign wins.
+func New(rules []Rule) (*Router, error) {
+	for i, rule := range rules {
+		if rule.Match == "" || len(rule.Owners) == 0 {
+			return nil, fmt.Errorf("%w: rule %d: both match and owners are required", ErrInvalidRule, i)
+		}
+		if _, err := path.Match(rule.Match, ""); err != nil {
+			return nil, fmt.Errorf("%w: rule %d: %s: %w", ErrInvalidRule, i, rule.Match, err)
+		}
+	}
+	return &Router{rules: rules}, nil
+}
+
+// Owners returns the owners of a campaign: those it names itself, or else those of the last rule that
+// matches its ID. The returned source says where they came from: "campaign", the pattern of the rule,
+// or "" when the campaign has no owners.
+func (r *Router) Owners(campaign model.Campaign) (owners []string, source string) {
+	if len(campaign.Owners) > 0 {
+		return campaign.Owners, "campaign"
+	}
+	if r == nil {
+		return nil, ""
+	}
+	for i := len(r.rules) - 1; i >= 0; i-- {
+		if ok, _ := path.Match(r.rules[i].Match, campaign.ID); ok {
+			return r.rules[i].Owners, r.rules[i].Match
+		}
+	}
+	return nil, ""
+}
@@ -0,0 +1,43 @@
+package owners
+
+import (
+	"testing"
+
+	"github.com/andrewhowdencom/ruf/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRouter_Owners(t *testing.T) {
+	router, err := New([]Rule{
+		{Match: "*", Owners: []string{"comms@example.com"}},
+		{Match: "team-a-*", Owners: []string{"team-a@example.com"}},
+	})
+	require.NoError(t, err)
+
+	owners, source := router.Owners(model.Campaign{ID: "team-a-standup"})
+	assert.Equal(t, []string{"team-a@example.com"}, owners)
+	assert.Equal(t, "team-a-*", source)
+
+	owners, source = router.Owners(model.Campaign{ID: "launch"})
+	assert.Equal(t, []string{"comms@example.com"}, owners)
+	assert.Equal(t, "*", source)
+
+	owners, source = router.Owners(model.Campaign{ID: "team-a-standup", Owners: []string{"jane@example.com"}})
+	assert.Equal(t, []string{"jane@example.com"}, owners)
+	assert.Equal(t, "campaign", source)
+
+	// A router without rules only finds the owners campaigns name themselves.
+	var none *Router
+	owners, source = none.Owners(model.Campaign{ID: "launch"})
+	assert.Nil(t, owners)
+	assert.Empty(t, source)
+}
+
+func TestNew_InvalidRule(t *testing.T) {
+	_, err := New([]Rule{{Match: "team-a-*"}})
+	assert.ErrorIs(t, err, ErrInvalidRule)
+
+	_, err = New([]Rule{{Match: "[", Owners: []string{"team-a@example.com"}}})
+	assert.ErrorIs(t, err, ErrInvalidRule)
+}
@@ -0,0 +1,262 @@
+// Package policy evaluates operator-supplied Starlark scripts that decide whether a call may be
+// scheduled, and whether it may be sent to a destination, so that organisation-specific rules do not
+// have to be written in Go.
+//
+// A policy script defines either or both of these functions, which return nothing when the call is
+// allowed, and a message or list of messages describing each violation when it is not:
+//
+//	def schedule(call): ...
+//	def dispatch(call, destination): ...
+package policy
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/andrewhowdencom/ruf/internal/model"
+	"go.starlark.net/starlark"
+	"go.starlark.net/starlarkstruct"
+)
+
+// Err* are common errors returned when evaluating policies.
+var (
+	ErrInvalidPolicy = errors.New("invalid policy")
+	ErrViolation     = errors.New("policy violation")
+)
+
+// Hooks that policies are evaluated at.
+const (
+	HookSchedule = "schedule"
+	HookDispatch = "dispatch"
+)
+
+// maxSteps bounds the work a single evaluation may do, so that a policy cannot stall the worker.
+const maxSteps = 1_000_000
+
+// script is a loaded policy file, and the hooks it defines.
+type script struct {
+	name  string
+	hooks map[string]*starlark.Function
+}
+
+// Engine evaluates a set of policy scripts.
+type Engine struct {
+	scripts []script
+}
+
+// Load reads and executes the policy scripts at the given paths.
+func Load(paths ...string) (*Engine, error) {
+	e := &Engine{}
+	for _, path := range paths {
+		src, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("%w: failed to read '%s': %w", ErrInvalidPolicy, path, err)
+		}
+		s, err := compile(filepath.Base(path), src)
+		if err != nil {
+			return nil, err
+		}
+		e.scripts = append(e.scripts, s)
+	}
+	return e, nil
+}
+
+// compile executes the source of a policy script and collects the hooks it defines.
+func compile(name string, src []byte) (script, error) {
+	globals, err := starlark.ExecFile(newThread(name), name, src, starlark.StringDict{
+		"struct": starlark.NewBuiltin("struct", starlarkstruct.Make),
+	})
+	if err != nil {
+		return script{}, fmt.Errorf("%w: %s: %w", ErrInvalidPolicy, name, err)
+	}
+
+	s := script{name: name, hooks: make(map[string]*starlark.Function)}
+	for _, hook := range []struct {
+		name   string
+		params int
+	}{
+		{HookSchedule, 1},
+		{HookDispatch, 2},
+	} {
+		v, ok := globals[hook.name]
+		if !ok {
+			continue
+		}
+		fn, ok := v.(*starlark.Function)
+		if !ok || fn.NumParams() != hook.params {
+			return script{}, fmt.Errorf("%w: %s: %s must be a function of %d arguments", ErrInvalidPolicy, name, hook.name, hook.params)
+		}
+		s.hooks[hook.name] = fn
+	}
+	if len(s.hooks) == 0 {
+		return script{}, fmt.Errorf("%w: %s: defines neither %s nor %s", ErrInvalidPolicy, name, HookSchedule, HookDispatch)
+	}
+	return s, nil
+}
+
+// Schedule evaluates the schedule hooks for an expanded call. It returns an error wrapping
+// ErrViolation that describes every violation.
+func (e *Engine) Schedule(call *model.Call) error {
+	return e.evaluate(HookSchedule, callValue(call))
+}
+
+// Dispatch evaluates the dispatch hooks for a call about to be sent to a single recipient, which is
+// passed to the hooks as a struct of its type and address. It returns an error wrapping ErrViolation
+// that describes every violation.
+func (e *Engine) Dispatch(call *model.Call, destType, to string) error {
+	recipient := starlarkstruct.FromStringDict(starlarkstruct.Default, starlark.StringDict{
+		"type": starlark.String(destType),
+		"to":   starlark.String(to),
+	})
+	return e.evaluate(HookDispatch, callValue(call), recipient)
+}
+
+// evaluate calls the hook of every script that defines it. A script that fails is treated as a
+// violation, so that a broken policy blocks calls rather than letting them through.
+func (e *Engine) evaluate(hook string, args ...starlark.Value) error {
+	if e == nil {
+		return nil
+	}
+
+	var violations []string
+	for _, s := range e.scripts {
+		fn, ok := s.hooks[hook]
+		if !ok {
+			continue
+		}
+		result, err := starlark.Call(newThread(s.name), fn, args, nil)
+		if err != nil {
+			violations = append(violations, fmt.Sprintf("%s: failed to evaluate %s: %s", s.name, hook, err))
+			continue
+		}
+		messages, err := messagesOf(result)
+		if err != nil {
+			violations = append(violations, fmt.Sprintf("%s: %s: %s", s.name, hook, err))
+			continue
+		}
+		for _, m := range messages {
+			violations = append(violations, fmt.Sprintf("%s: %s", s.name, m))
+		}
+	}
+	if len(violations) > 0 {
+		return fmt.Errorf("%w: %s", ErrViolation, strings.Join(violations, "; "))
+	}
+	return nil
+}
+
+// messagesOf reads the violations returned by a hook: None, a string, or a list of strings.
+func messagesOf(v starlark.Value) ([]string, error) {
+	switch v := v.(type) {
+	case starlark.NoneType:
+		return nil, nil
+	case starlark.String:
+		if v == "" {
+			return nil, nil
+		}
+		return []string{string(v)}, nil
+	case *starlark.List:
+		var messages []string
+		for i := 0; i < v.Len(); i++ {
+			s, ok := starlark.AsString(v.Index(i))
+			if !ok {
+				return nil, fmt.Errorf("violations must be strings, got %s", v.Index(i).Type())
+			}
+			messages = append(messages, s)
+		}
+		return messages, nil
+	}
+	return nil, fmt.Errorf("must return None, a string or a list of strings, got %s", v.Type())
+}
+
+func newThread(name string) *starlark.Thread {
+	thread := &starlark.Thread{
+		Name: name,
+		Print: func(_ *starlark.Thread, msg string) {
+			slog.Info("policy", "policy", name, "message", msg)
+		},
+	}
+	thread.SetMaxExecutionSteps(maxSteps)
+	return thread
+}
+
+// callValue exposes a call to a policy as a struct.
+func callValue(call *model.Call) starlark.Value {
+	destinations := make([]starlark.Value, 0, len(call.Destinations))
+	for _, d := range call.Destinations {
+		destinations = append(destinations, destinationValue(d.Type, d.To))
+	}
+	scheduledAt := ""
+	if !call.ScheduledAt.IsZero() {
+		scheduledAt = call.ScheduledAt.UTC().Format(time.RFC3339)
+	}
+
+	return starlarkstruct.FromStringDict(starlarkstruct.Default, starlark.StringDict{
+		"id":      starlark.String(call.ID),
+		"author":  starlark.String(call.Author),
+		"subject": starlark.String(call.Subject),
+		"content": starlark.String(call.Content),
+		"campaign": starlarkstruct.FromStringDict(starlarkstruct.Default, starlark.StringDict{
+			"id":   starlark.String(call.Campaign.ID),
+			"name": starlark.String(call.Campaign.Name),
+		}),
+		"destinations": starlark.NewList(destinations),
+		"scheduled_at": starlark.String(scheduledAt),
+		"data":         toValue(call.Data),
+	})
+}
+
+// destinationValue exposes a destination to a policy as a struct.
+func destinationValue(destType string, to []string) starlark.Value {
+	recipients := make([]starlark.Value, 0, len(to))
+	for _, t := range to {
+		recipients = append(recipients, starlark.String(t))
+	}
+	return starlarkstruct.FromStringDict(starlarkstruct.Default, starlark.StringDict{
+		"type": starlark.String(destType),
+		"to":   starlark.NewList(recipients),
+	})
+}
+
+// toValue converts the data of a call, as decoded from YAML, into Starlark values.
+func toValue(v interface{}) starlark.Value {
+	switch v := v.(type) {
+	case nil:
+		return starlark.None
+	case string:
+		return starlark.String(v)
+	case bool:
+		return starlark.Bool(v)
+	case int:
+		return starlark.MakeInt(v)
+	case int64:
+		return starlark.MakeInt64(v)
+	case uint64:
+		return starlark.MakeUint64(v)
+	case float64:
+		return starlark.Float(v)
+	case []interface{}:
+		elems := make([]starlark.Value, 0, len(v))
+		for _, e := range v {
+			elems = append(elems, toValue(e))
+		}
+		return starlark.NewList(elems)
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		d := starlark.NewDict(len(v))
+		for _, k := range keys {
+			_ = d.SetKey(starlark.String(k), toValue(v[k]))
+		}
+		return d
+	}
+	return starlark.String(fmt.Sprint(v))
+}
@@ -0,0 +1,76 @@
+package policy_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/andrewhowdencom/ruf/internal/model"
+	"github.com/andrewhowdencom/ruf/internal/policy"
+	"github.com/stretchr/testify/assert"
+)
+
+func writePolicy(t *testing.T, name, src string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	assert.NoError(t, os.WriteFile(path, []byte(src), 0644))
+	return path
+}
+
+func TestEngine(t *testing.T) {
+	engine, err := policy.Load(
+		writePolicy(t, "channels.star", `
+def schedule(call):
+    if call.campaign.name != "marketing":
+        return None
+    return ["marketing may not target " + to for d in call.destinations for to in d.to if to == "#engineering"]
+`),
+		writePolicy(t, "email.star", `
+def dispatch(call, destination):
+    if destination.type == "email" and not destination.to.endswith("@example.com"):
+        return "external emails require approval"
+`),
+	)
+	assert.NoError(t, err)
+
+	call := &model.Call{
+		ID:           "launch",
+		Campaign:     model.Campaign{ID: "marketing", Name: "marketing"},
+		Destinations: []model.Destination{{Type: "slack", To: []string{"#general", "#engineering"}}},
+		ScheduledAt:  time.Date(2025, 3, 4, 9, 0, 0, 0, time.UTC),
+	}
+	err = engine.Schedule(call)
+	assert.ErrorIs(t, err, policy.ErrViolation)
+	assert.ErrorContains(t, err, "channels.star: marketing may not target #engineering")
+
+	call.Campaign.Name = "announcements"
+	assert.NoError(t, engine.Schedule(call))
+
+	assert.NoError(t, engine.Dispatch(call, "email", "team@example.com"))
+	err = engine.Dispatch(call, "email", "someone@elsewhere.com")
+	assert.ErrorIs(t, err, policy.ErrViolation)
+	assert.ErrorContains(t, err, "external emails require approval")
+}
+
+func TestEngine_FailsClosed(t *testing.T) {
+	engine, err := policy.Load(writePolicy(t, "broken.star", `
+def schedule(call):
+    return call.missing
+`))
+	assert.NoError(t, err)
+	assert.ErrorIs(t, engine.Schedule(&model.Call{ID: "1"}), policy.ErrViolation)
+}
+
+func TestLoad_Invalid(t *testing.T) {
+	for name, src := range map[string]string{
+		"syntax.star":    "def schedule(call)\n",
+		"no-hooks.star":  "x = 1\n",
+		"arguments.star": "def dispatch(call):\n    return None\n",
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := policy.Load(writePolicy(t, name, src))
+			assert.ErrorIs(t, err, policy.ErrInvalidPolicy)
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package poller
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/andrewhowdencom/ruf/internal/sourcer"
+)
+
+// maxPushSize is the largest source that can be pushed, in bytes.
+const maxPushSize = 10 << 20
+
+// NewHandler returns the handler serving the status of the last poll of each source, as JSON.
+func NewHandler(p *Poller) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(p.Status()); err != nil {
+			slog.Error("failed to write source status", "error", err)
+		}
+	})
+}
+
+// NewPushHandler returns the handler of the source push API, which requires the token as a bearer
+// token:
+//
+//	POST /v1/sources/refresh  refreshes the sources now, rather than at the next refresh interval.
+//	POST /v1/sources/{name}   replaces the content of the source push://{name} with the body of the
+//	                          request, and refreshes the sources.
+//
+// The sources are refreshed by calling refresh, which must not wait for the refresh to finish, and
+// the handler responds with 202 Accepted once it has been asked for.
+func NewPushHandler(push *sourcer.PushFetcher, token string, refresh func()) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /v1/sources/refresh", func(w http.ResponseWriter, r *http.Request) {
+		slog.Info("source refresh requested")
+		refresh()
+		w.WriteHeader(http.StatusAccepted)
+	})
+	mux.HandleFunc("POST /v1/sources/{name}", func(w http.ResponseWriter, r *http.Request) {
+		data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxPushSize))
+		if err != nil {
+			http.Error(w, "invalid request body: "+err.Error(), http.StatusRequestEntityTooLarge)
+			return
+		}
+		name := r.PathValue("name")
+		if err := push.Push(name, data); err != nil {
+			code := http.StatusInternalServerError
+			if errors.Is(err, sourcer.ErrInvalidPushName) {
+				code = http.StatusBadRequest
+			}
+			http.Error(w, err.Error(), code)
+			return
+		}
+		slog.Info("source pushed", "name", name, "size", len(data))
+		refresh()
+		w.WriteHeader(http.StatusAccepted)
+	})
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		given, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(given), []byte(token)) != 1 {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		mux.ServeHTTP(w, r)
+	})
+}
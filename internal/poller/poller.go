@@ -1,10 +1,19 @@
 package poller
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"log/slog"
+	"sort"
+	"sync"
 	"time"
 
+	"github.com/andrewhowdencom/ruf/internal/kv"
 	"github.com/andrewhowdencom/ruf/internal/sourcer"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
 )
 
 // Poller periodically checks for updates in a list of sources.
@@ -12,15 +21,104 @@ type Poller struct {
 	sourcer    sourcer.Sourcer
 	interval   time.Duration
 	knownState map[string]string
+	cache      kv.Storer
+
+	mu     sync.Mutex
+	status map[string]Status
+	// fallbacks is the number of polls that fell back on the cached content of a source.
+	fallbacks int64
+}
+
+// Option configures a Poller.
+type Option func(*Poller)
+
+// WithCache keeps the last content of each source that was fetched and parsed in the store, and falls
+// back on it while the source cannot be fetched, so that an outage of the server of a source does not
+// empty the schedule of its calls.
+func WithCache(store kv.Storer) Option {
+	return func(p *Poller) {
+		p.cache = store
+	}
+}
+
+// Status is the outcome of the last poll of a source.
+type Status struct {
+	URL       string    `json:"url"`
+	CheckedAt time.Time `json:"checked_at"`
+	// Skipped is set when the whole source was skipped, as it is not valid.
+	Skipped bool `json:"skipped"`
+	// Invalid are the documents of the source that are not valid, with the problems of each. When the
+	// source was not skipped, they are the files or rows that were left out of it.
+	Invalid []*sourcer.ValidationError `json:"invalid,omitempty"`
+	// Error is why the source could not be read, if it could not.
+	Error string `json:"error,omitempty"`
+	// CachedAt is when the content that was fallen back on was fetched, if the source could not be read
+	// and the poller fell back on its cached content.
+	CachedAt time.Time `json:"cached_at,omitempty"`
+	// Duration is how long the source took to fetch and parse.
+	Duration time.Duration `json:"duration"`
+	// State is the state of the content of the source that was last read, such as its ETag, if any.
+	State string `json:"state,omitempty"`
+	// Calls is the number of calls read from the source.
+	Calls int `json:"calls"`
+	// LastSuccessAt is when the source was last read, whether or not it had changed.
+	LastSuccessAt time.Time `json:"last_success_at,omitempty"`
+}
+
+// The health of a source, as reported by Status.Health.
+const (
+	HealthOK      = "ok"
+	HealthPartial = "partly skipped"
+	HealthSkipped = "skipped"
+	HealthStale   = "stale"
+	HealthFailing = "failing"
+)
+
+// Stale reports whether the poll fell back on the cached content of the source.
+func (s Status) Stale() bool {
+	return !s.CachedAt.IsZero()
+}
+
+// Health summarises the outcome of the poll: whether the source was read, had parts that are not valid
+// left out, was skipped as it is not valid, fell back on its cached content, or could not be read.
+func (s Status) Health() string {
+	switch {
+	case s.Skipped:
+		return HealthSkipped
+	case s.Stale():
+		return HealthStale
+	case s.Error != "":
+		return HealthFailing
+	case len(s.Invalid) > 0:
+		return HealthPartial
+	}
+	return HealthOK
 }
 
 // New creates a new Poller.
-func New(sourcer sourcer.Sourcer, interval time.Duration) *Poller {
-	return &Poller{
+func New(sourcer sourcer.Sourcer, interval time.Duration, opts ...Option) *Poller {
+	p := &Poller{
 		sourcer:    sourcer,
 		interval:   interval,
 		knownState: make(map[string]string),
+		status:     make(map[string]Status),
+	}
+	for _, opt := range opts {
+		opt(p)
 	}
+	return p
+}
+
+// Status returns the outcome of the last poll of each source, by URL.
+func (p *Poller) Status() []Status {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	statuses := make([]Status, 0, len(p.status))
+	for _, status := range p.status {
+		statuses = append(statuses, status)
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].URL < statuses[j].URL })
+	return statuses
 }
 
 // Poll checks for updates in the sources and returns the calls from the changed URLs.
@@ -48,16 +146,206 @@ func (p *Poller) Poll(urls []string) ([]*sourcer.Source, error) {
 	return allSources, nil
 }
 
+// Reset forgets the state of every source, so the next poll returns all of them.
+func (p *Poller) Reset() {
+	p.knownState = make(map[string]string)
+}
+
 func (p *Poller) pollURL(url string) (*sourcer.Source, error) {
-	source, state, err := p.sourcer.Source(url)
+	start := time.Now()
+	source, state, err := p.source(url)
+	duration := time.Since(start)
+	if errors.Is(err, sourcer.ErrNotModified) {
+		p.record(Status{URL: url, Duration: duration, State: state}, true)
+		return nil, nil // No change
+	}
 	if err != nil {
+		status := Status{URL: url, Duration: duration, State: p.knownState[url], Error: err.Error(), Invalid: validationErrors(err)}
+		if len(status.Invalid) > 0 {
+			// The source is read again once it is fixed, whatever it was before.
+			status.Skipped = true
+			status.State = ""
+			delete(p.knownState, url)
+			slog.Warn("skipping source that is not valid", "url", url, "error", err)
+		} else if cached, ok := p.cached(url); ok {
+			return p.fallBack(status, cached, err)
+		}
+		p.record(status, false)
 		return nil, err
 	}
 
+	status := Status{URL: url, Duration: duration, State: state}
+	if source != nil {
+		status.Calls = len(source.Calls)
+		status.Invalid = source.Invalid
+		for _, verr := range source.Invalid {
+			slog.Warn("skipping part of source that is not valid", "url", url, "error", verr)
+		}
+	}
+	p.record(status, false)
+
 	if p.knownState[url] == state {
 		return nil, nil // No change
 	}
 
 	p.knownState[url] = state
+	p.store(url, state, source)
 	return source, nil
 }
+
+// store keeps the content of a source in the cache, if there is one.
+func (p *Poller) store(url, state string, source *sourcer.Source) {
+	if p.cache == nil || source == nil {
+		return
+	}
+	data, err := json.Marshal(source)
+	if err == nil {
+		err = p.cache.SetCachedSource(&kv.CachedSource{URL: url, State: state, Source: data, FetchedAt: time.Now().UTC()})
+	}
+	if err != nil {
+		slog.Warn("failed to cache source", "url", url, "error", err)
+	}
+}
+
+// cached returns the cached content of a source, if there is a cache and it has the source.
+func (p *Poller) cached(url string) (*kv.CachedSource, bool) {
+	if p.cache == nil {
+		return nil, false
+	}
+	cached, err := p.cache.GetCachedSource(url)
+	if err != nil {
+		if !errors.Is(err, kv.ErrNotFound) {
+			slog.Warn("failed to read cached source", "url", url, "error", err)
+		}
+		return nil, false
+	}
+	return cached, true
+}
+
+// fallBack returns the cached content of a source that could not be fetched, as though it had been
+// read, unless it is what was last returned.
+func (p *Poller) fallBack(status Status, cached *kv.CachedSource, err error) (*sourcer.Source, error) {
+	var source sourcer.Source
+	if uerr := json.Unmarshal(cached.Source, &source); uerr != nil {
+		slog.Warn("failed to read cached source", "url", status.URL, "error", uerr)
+		p.record(status, false)
+		return nil, err
+	}
+	slog.Warn("failed to fetch source, falling back on its cached content", "url", status.URL, "cached_at", cached.FetchedAt, "error", err)
+	status.CachedAt = cached.FetchedAt
+	status.State = cached.State
+	status.Calls = len(source.Calls)
+	p.mu.Lock()
+	p.fallbacks++
+	p.mu.Unlock()
+	p.record(status, false)
+
+	if p.knownState[status.URL] == cached.State {
+		return nil, nil // No change
+	}
+	p.knownState[status.URL] = cached.State
+	return &source, nil
+}
+
+// RegisterMetrics registers instruments describing the polls with the meter.
+func (p *Poller) RegisterMetrics(meter metric.Meter) error {
+	fallbacks, err := meter.Int64ObservableCounter("ruf.sources.fallbacks",
+		metric.WithDescription("The number of polls that fell back on the cached content of a source, as it could not be fetched."))
+	if err != nil {
+		return err
+	}
+	stale, err := meter.Int64ObservableGauge("ruf.sources.stale",
+		metric.WithDescription("The number of sources whose last poll fell back on their cached content."))
+	if err != nil {
+		return err
+	}
+	duration, err := meter.Float64ObservableGauge("ruf.sources.fetch_duration",
+		metric.WithDescription("How long the last poll of a source took to fetch and parse it."), metric.WithUnit("s"))
+	if err != nil {
+		return err
+	}
+	successAge, err := meter.Float64ObservableGauge("ruf.sources.success_age",
+		metric.WithDescription("How long ago a source was last read."), metric.WithUnit("s"))
+	if err != nil {
+		return err
+	}
+	calls, err := meter.Int64ObservableGauge("ruf.sources.calls",
+		metric.WithDescription("The number of calls read from a source."))
+	if err != nil {
+		return err
+	}
+	healthy, err := meter.Int64ObservableGauge("ruf.sources.healthy",
+		metric.WithDescription("Whether the last poll of a source read all of it: 1 if it did, 0 otherwise."))
+	if err != nil {
+		return err
+	}
+
+	_, err = meter.RegisterCallback(func(_ context.Context, o metric.Observer) error {
+		var count int64
+		now := time.Now()
+		for _, status := range p.Status() {
+			if status.Stale() {
+				count++
+			}
+			attrs := metric.WithAttributes(attribute.String("url", status.URL))
+			o.ObserveFloat64(duration, status.Duration.Seconds(), attrs)
+			o.ObserveInt64(calls, int64(status.Calls), attrs)
+			var ok int64
+			if status.Health() == HealthOK {
+				ok = 1
+			}
+			o.ObserveInt64(healthy, ok, attrs)
+			if !status.LastSuccessAt.IsZero() {
+				o.ObserveFloat64(successAge, now.Sub(status.LastSuccessAt).Seconds(), attrs)
+			}
+		}
+		p.mu.Lock()
+		o.ObserveInt64(fallbacks, p.fallbacks)
+		p.mu.Unlock()
+		o.ObserveInt64(stale, count)
+		return nil
+	}, fallbacks, stale, duration, successAge, calls, healthy)
+	return err
+}
+
+// record keeps the status of a poll. A source that has not changed keeps the problems found and the
+// calls read when it was last read, and one that could not be read keeps when it last was.
+func (p *Poller) record(status Status, unchanged bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	previous := p.status[status.URL]
+	if unchanged {
+		status.Invalid = previous.Invalid
+		status.Calls = previous.Calls
+	}
+	status.CheckedAt = time.Now().UTC()
+	status.LastSuccessAt = previous.LastSuccessAt
+	if status.Error == "" {
+		status.LastSuccessAt = status.CheckedAt
+	}
+	p.status[status.URL] = status
+}
+
+// validationErrors returns the validation errors an error is made of.
+func validationErrors(err error) []*sourcer.ValidationError {
+	var verr *sourcer.ValidationError
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		var errs []*sourcer.ValidationError
+		for _, err := range joined.Unwrap() {
+			errs = append(errs, validationErrors(err)...)
+		}
+		return errs
+	}
+	if errors.As(err, &verr) {
+		return []*sourcer.ValidationError{verr}
+	}
+	return nil
+}
+
+// source reads a source, asking only for its changes since the known state if the sourcer supports it.
+func (p *Poller) source(url string) (*sourcer.Source, string, error) {
+	if cs, ok := p.sourcer.(sourcer.ConditionalSourcer); ok && p.knownState[url] != "" {
+		return cs.SourceIfModified(url, p.knownState[url])
+	}
+	return p.sourcer.Source(url)
+}
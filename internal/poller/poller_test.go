@@ -2,9 +2,14 @@ package poller
 
 import (
 	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/andrewhowdencom/ruf/internal/datastore"
+	"github.com/andrewhowdencom/ruf/internal/model"
 	"github.com/andrewhowdencom/ruf/internal/sourcer"
 )
 
@@ -49,3 +54,179 @@ func TestPoller_Poll_AllSourcesFail(t *testing.T) {
 		t.Errorf("expected nil sources, but got %v", sources)
 	}
 }
+
+// conditionalSourcer is a mockSourcer that reports sources as not modified while their state holds.
+type conditionalSourcer struct {
+	mockSourcer
+	calls int
+}
+
+func (m *conditionalSourcer) SourceIfModified(url, state string) (*sourcer.Source, string, error) {
+	m.calls++
+	if state != "" && state == m.states[url] {
+		return nil, state, sourcer.ErrNotModified
+	}
+	return m.Source(url)
+}
+
+func TestPoller_Poll_NotModified(t *testing.T) {
+	url := "http://example.com/source1.yaml"
+	mockSourcer := &conditionalSourcer{mockSourcer: mockSourcer{
+		sources: map[string]*sourcer.Source{url: {}},
+		states:  map[string]string{url: `"v1"`},
+	}}
+	poller := New(mockSourcer, 1*time.Minute)
+
+	sources, err := poller.Poll([]string{url})
+	if err != nil || len(sources) != 1 {
+		t.Fatalf("expected the source on the first poll, got %v, %v", sources, err)
+	}
+
+	sources, err = poller.Poll([]string{url})
+	if err != nil {
+		t.Fatalf("expected no error for an unmodified source, got %v", err)
+	}
+	if len(sources) != 0 {
+		t.Errorf("expected no sources for an unmodified source, got %v", sources)
+	}
+	if mockSourcer.calls != 1 {
+		t.Errorf("expected the second poll to be conditional, got %d conditional calls", mockSourcer.calls)
+	}
+}
+
+func TestPoller_Poll_FallsBackOnCache(t *testing.T) {
+	url := "http://example.com/source1.yaml"
+	store := datastore.NewMockStore()
+	mockSourcer := &mockSourcer{
+		sources: map[string]*sourcer.Source{url: {Calls: []model.Call{{
+			ID:           "standup",
+			Content:      "Time for stand up!",
+			Destinations: []model.Destination{{Type: "slack", To: []string{"#general"}}},
+			Triggers:     []model.Trigger{{Cron: "0 9 * * 1-5"}},
+		}}}},
+		states: map[string]string{url: "v1"},
+	}
+
+	sources, err := New(mockSourcer, 1*time.Minute, WithCache(store)).Poll([]string{url})
+	if err != nil || len(sources) != 1 {
+		t.Fatalf("expected the source on the first poll, got %v, %v", sources, err)
+	}
+
+	// A poller started while the source cannot be fetched falls back on the content the last one read.
+	mockSourcer.err = errors.New("service unavailable")
+	poller := New(mockSourcer, 1*time.Minute, WithCache(store))
+	sources, err = poller.Poll([]string{url})
+	if err != nil || len(sources) != 1 {
+		t.Fatalf("expected the cached source, got %v, %v", sources, err)
+	}
+	if len(sources[0].Calls) != 1 || sources[0].Calls[0].ID != "standup" || sources[0].Calls[0].Triggers[0].Cron != "0 9 * * 1-5" {
+		t.Errorf("expected the calls of the cached source, got %+v", sources[0].Calls)
+	}
+	status := poller.Status()
+	if len(status) != 1 || !status[0].Stale() || status[0].Error != "service unavailable" {
+		t.Errorf("expected the source to be recorded as stale, got %+v", status)
+	}
+
+	// The cached content is only returned again once it changes.
+	sources, err = poller.Poll([]string{url})
+	if err != nil || len(sources) != 0 {
+		t.Errorf("expected no change on the next poll, got %v, %v", sources, err)
+	}
+
+	// Without a cache, the source is left out.
+	if _, err := New(mockSourcer, 1*time.Minute).Poll([]string{url}); err == nil {
+		t.Error("expected an error without a cache, but got nil")
+	}
+}
+
+func TestPoller_Status(t *testing.T) {
+	valid, invalid := "http://example.com/valid.yaml", "http://example.com/invalid.yaml"
+	verr := &sourcer.ValidationError{URL: invalid, Fields: []sourcer.FieldError{{Field: "calls.0", Description: "content is required"}}}
+	mockSourcer := &validatingSourcer{
+		mockSourcer: mockSourcer{
+			sources: map[string]*sourcer.Source{valid: {}},
+			states:  map[string]string{valid: "v1"},
+		},
+		invalid: map[string]error{invalid: verr},
+	}
+	poller := New(mockSourcer, 1*time.Minute)
+
+	sources, err := poller.Poll([]string{valid, invalid})
+	if err != nil || len(sources) != 1 {
+		t.Fatalf("expected the valid source, got %v, %v", sources, err)
+	}
+
+	status := poller.Status()
+	if len(status) != 2 {
+		t.Fatalf("expected the status of both sources, got %v", status)
+	}
+	if status[0].URL != invalid || !status[0].Skipped || len(status[0].Invalid) != 1 || status[0].Invalid[0] != verr {
+		t.Errorf("expected the invalid source to be recorded as skipped, got %+v", status[0])
+	}
+	if status[1].URL != valid || status[1].Skipped || status[1].Error != "" || status[1].CheckedAt.IsZero() {
+		t.Errorf("expected the valid source to be recorded as read, got %+v", status[1])
+	}
+	if status[0].Health() != HealthSkipped || !status[0].LastSuccessAt.IsZero() {
+		t.Errorf("expected the invalid source never to have been read, got %+v", status[0])
+	}
+	if status[1].Health() != HealthOK || status[1].State != "v1" || status[1].LastSuccessAt.IsZero() {
+		t.Errorf("expected the valid source to be healthy, got %+v", status[1])
+	}
+
+	// A source that later fails keeps when it was last read.
+	lastSuccess := status[1].LastSuccessAt
+	mockSourcer.invalid[valid] = errors.New("service unavailable")
+	poller.Poll([]string{valid})
+	status = poller.Status()
+	if status[1].Health() != HealthFailing || !status[1].LastSuccessAt.Equal(lastSuccess) || status[1].State != "v1" {
+		t.Errorf("expected the failing source to keep when it was last read, got %+v", status[1])
+	}
+}
+
+// validatingSourcer is a mockSourcer that fails to read some sources.
+type validatingSourcer struct {
+	mockSourcer
+	invalid map[string]error
+}
+
+func (m *validatingSourcer) Source(url string) (*sourcer.Source, string, error) {
+	if err, ok := m.invalid[url]; ok {
+		return nil, "", err
+	}
+	return m.mockSourcer.Source(url)
+}
+
+func TestPushHandler(t *testing.T) {
+	push := sourcer.NewPushFetcher(t.TempDir())
+	refreshed := 0
+	handler := NewPushHandler(push, "secret", func() { refreshed++ })
+
+	request := func(path, token, body string) int {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	if code := request("/v1/sources/refresh", "wrong", ""); code != http.StatusUnauthorized {
+		t.Errorf("expected a request with the wrong token to be unauthorized, got %d", code)
+	}
+	if code := request("/v1/sources/refresh", "secret", ""); code != http.StatusAccepted || refreshed != 1 {
+		t.Errorf("expected a refresh, got %d and %d refreshes", code, refreshed)
+	}
+
+	if code := request("/v1/sources/team-calls", "secret", "calls: []\n"); code != http.StatusAccepted || refreshed != 2 {
+		t.Errorf("expected the push to be accepted and refresh, got %d and %d refreshes", code, refreshed)
+	}
+	data, _, err := push.Fetch("push://team-calls")
+	if err != nil || string(data) != "calls: []\n" {
+		t.Errorf("expected the pushed content, got %q, %v", data, err)
+	}
+
+	if code := request("/v1/sources/.hidden", "secret", ""); code != http.StatusBadRequest || refreshed != 2 {
+		t.Errorf("expected a push with an invalid name to be rejected, got %d and %d refreshes", code, refreshed)
+	}
+}
@@ -0,0 +1,107 @@
+package preview
+
+import (
+	"context"
+	"html/template"
+	"strings"
+
+	"github.com/chromedp/cdproto/page"
+	"github.com/chromedp/chromedp"
+)
+
+// setContent replaces the document of the current page, so that previews are rendered without a
+// server or a temporary file.
+func setContent(html []byte) chromedp.Action {
+	return chromedp.ActionFunc(func(ctx context.Context) error {
+		tree, err := page.GetFrameTree().Do(ctx)
+		if err != nil {
+			return err
+		}
+		return page.SetDocumentContent(tree.Frame.ID, string(html)).Do(ctx)
+	})
+}
+
+var funcs = template.FuncMap{
+	// initial returns the first letter of a name, for use as a placeholder avatar.
+	"initial": func(name string) string {
+		for _, r := range name {
+			return strings.ToUpper(string(r))
+		}
+		return ""
+	},
+}
+
+// pages are the templates that imitate each destination type. The element with the ID "message" is
+// the part of the page that is captured.
+var pages = map[string]*template.Template{
+	TypeSlack: template.Must(template.New(TypeSlack).Funcs(funcs).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<style>
+  body { margin: 0; background: #ffffff; font-family: "Slack-Lato", Lato, "Helvetica Neue", Helvetica, Arial, sans-serif; font-size: 15px; line-height: 1.47; color: #1d1c1d; }
+  #message { display: flex; gap: 8px; padding: 16px 20px; }
+  .avatar { flex: none; width: 36px; height: 36px; border-radius: 4px; background: #4a154b; color: #ffffff; font-weight: 700; display: flex; align-items: center; justify-content: center; }
+  .header { display: flex; align-items: baseline; gap: 8px; }
+  .sender { font-weight: 900; }
+  .time { color: #616061; font-size: 12px; }
+  .body p { margin: 0 0 4px; }
+  .body a { color: #1264a3; text-decoration: none; }
+  .body code { font-family: Monaco, Menlo, Consolas, monospace; font-size: 12px; color: #e01e5a; background: #f6f6f6; border: 1px solid #dddddd; border-radius: 3px; padding: 2px 3px; }
+  .body pre { font-family: Monaco, Menlo, Consolas, monospace; font-size: 12px; background: #f8f8f8; border: 1px solid #dddddd; border-radius: 4px; padding: 8px; white-space: pre-wrap; }
+  .body blockquote { margin: 0; padding-left: 12px; border-left: 4px solid #dddddd; }
+  .body h1, .body h2, .body h3, .body h4, .body h5, .body h6 { font-size: 15px; margin: 0 0 4px; }
+  .attribution { color: #616061; margin-top: 8px; }
+</style>
+</head>
+<body>
+<div id="message">
+  <div class="avatar">{{ initial .Sender }}</div>
+  <div>
+    <div class="header">
+      <span class="sender">{{ .Sender }}</span>
+      {{- if not .ScheduledAt.IsZero }}
+      <span class="time">{{ .ScheduledAt.Format "3:04 PM" }}</span>
+      {{- end }}
+    </div>
+    {{- if .Subject }}
+    <div class="subject"><strong>{{ .Subject }}</strong></div>
+    {{- end }}
+    <div class="body">{{ .Body }}</div>
+    {{- if .Author }}
+    <div class="attribution">Thx: {{ .Author }}</div>
+    {{- end }}
+  </div>
+</div>
+</body>
+</html>
+`)),
+	TypeEmail: template.Must(template.New(TypeEmail).Funcs(funcs).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<style>
+  body { margin: 0; background: #f1f3f4; font-family: Roboto, "Helvetica Neue", Helvetica, Arial, sans-serif; font-size: 14px; color: #202124; }
+  #message { margin: 16px; background: #ffffff; border: 1px solid #dadce0; border-radius: 8px; }
+  .headers { padding: 16px 20px; border-bottom: 1px solid #dadce0; }
+  .subject { font-size: 20px; margin: 0 0 8px; }
+  .field { color: #5f6368; }
+  .body { padding: 16px 20px; line-height: 1.5; }
+  .body a { color: #1a73e8; }
+</style>
+</head>
+<body>
+<div id="message">
+  <div class="headers">
+    <h1 class="subject">{{ if .Campaign }}[{{ .Campaign }}] {{ end }}{{ .Subject }}</h1>
+    <div class="field">From: {{ if .Author }}{{ .Author }}{{ else }}{{ .Sender }}{{ end }}</div>
+    {{- if not .ScheduledAt.IsZero }}
+    <div class="field">Date: {{ .ScheduledAt.Format "Mon, 02 Jan 2006 15:04 MST" }}</div>
+    {{- end }}
+  </div>
+  <div class="body">{{ .Body }}</div>
+</div>
+</body>
+</html>
+`)),
+}
@@ -0,0 +1,176 @@
+// Package preview renders calls as they would appear to their recipients, and captures them as
+// images with a headless browser, so that changes to announcements can be reviewed visually.
+package preview
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"html/template"
+	"time"
+
+	"github.com/andrewhowdencom/ruf/internal/model"
+	"github.com/andrewhowdencom/ruf/internal/processor"
+	"github.com/chromedp/cdproto/emulation"
+	"github.com/chromedp/chromedp"
+)
+
+// Err* are common errors returned when rendering previews.
+var (
+	ErrUnsupportedType = errors.New("unsupported destination type")
+	ErrRenderFailed    = errors.New("failed to render preview")
+)
+
+// Supported destination types.
+const (
+	TypeSlack = "slack"
+	TypeEmail = "email"
+)
+
+// Defaults for the browser window that previews are captured in.
+const (
+	DefaultWidth = 720
+	DefaultScale = 2
+)
+
+// Option configures a Renderer.
+type Option func(*Renderer)
+
+// WithExecPath sets the path of the browser that is used to capture images. By default, a Chrome or
+// Chromium installation is searched for.
+func WithExecPath(path string) Option {
+	return func(r *Renderer) {
+		r.execPath = path
+	}
+}
+
+// WithWidth sets the width of the browser window, in CSS pixels.
+func WithWidth(width int) Option {
+	return func(r *Renderer) {
+		r.width = width
+	}
+}
+
+// WithScale sets the device scale factor the image is captured at.
+func WithScale(scale float64) Option {
+	return func(r *Renderer) {
+		r.scale = scale
+	}
+}
+
+// WithTimeout bounds how long capturing an image may take.
+func WithTimeout(timeout time.Duration) Option {
+	return func(r *Renderer) {
+		r.timeout = timeout
+	}
+}
+
+// Renderer renders calls to HTML and images.
+type Renderer struct {
+	execPath string
+	width    int
+	scale    float64
+	timeout  time.Duration
+}
+
+// NewRenderer creates a new Renderer.
+func NewRenderer(opts ...Option) *Renderer {
+	r := &Renderer{
+		width:   DefaultWidth,
+		scale:   DefaultScale,
+		timeout: 30 * time.Second,
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// message is the data the preview templates are executed with.
+type message struct {
+	Sender      string
+	Author      string
+	Campaign    string
+	Subject     string
+	Body        template.HTML
+	ScheduledAt time.Time
+}
+
+// HTML renders the call as a standalone page that imitates the way the destination type displays
+// it. Templates in the subject and content are executed with the call's data, as the worker does.
+func (r *Renderer) HTML(call *model.Call, destType string, scheduledAt time.Time) ([]byte, error) {
+	page, ok := pages[destType]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedType, destType)
+	}
+
+	data := make(map[string]interface{}, len(call.Data)+1)
+	for k, v := range call.Data {
+		data[k] = v
+	}
+	data["ScheduledAt"] = scheduledAt
+
+	subject, err := processor.NewTemplateProcessor().Process(call.Subject, data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: subject: %w", ErrRenderFailed, err)
+	}
+	content, err := processor.ProcessorStack{
+		processor.NewTemplateProcessor(),
+		processor.NewMarkdownToHTMLProcessor(),
+	}.Process(call.Content, data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: content: %w", ErrRenderFailed, err)
+	}
+
+	msg := message{
+		Sender:      call.Campaign.Name,
+		Author:      call.Author,
+		Campaign:    call.Campaign.Name,
+		Subject:     subject,
+		Body:        template.HTML(content),
+		ScheduledAt: scheduledAt,
+	}
+	if msg.Sender == "" {
+		msg.Sender = "ruf"
+	}
+
+	var buf bytes.Buffer
+	if err := page.Execute(&buf, msg); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrRenderFailed, err)
+	}
+	return buf.Bytes(), nil
+}
+
+// Screenshot renders the call and captures it as a PNG image with a headless browser.
+func (r *Renderer) Screenshot(ctx context.Context, call *model.Call, destType string, scheduledAt time.Time) ([]byte, error) {
+	page, err := r.HTML(call, destType, scheduledAt)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := append(chromedp.DefaultExecAllocatorOptions[:], chromedp.WindowSize(r.width, 600))
+	if r.execPath != "" {
+		opts = append(opts, chromedp.ExecPath(r.execPath))
+	}
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+	ctx, cancel = chromedp.NewExecAllocator(ctx, opts...)
+	defer cancel()
+	ctx, cancel = chromedp.NewContext(ctx)
+	defer cancel()
+
+	var image []byte
+	err = chromedp.Run(ctx,
+		chromedp.EmulateViewport(int64(r.width), 600, chromedp.EmulateScale(r.scale)),
+		// Content is rendered as sent, so scripts in it are not run.
+		emulation.SetScriptExecutionDisabled(true),
+		chromedp.Navigate("about:blank"),
+		setContent(page),
+		chromedp.Screenshot("#message", &image, chromedp.NodeVisible, chromedp.ByQuery),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrRenderFailed, err)
+	}
+	return image, nil
+}
@@ -0,0 +1,73 @@
+package preview_test
+
+import (
+	"bytes"
+	"context"
+	"image/png"
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/andrewhowdencom/ruf/internal/model"
+	"github.com/andrewhowdencom/ruf/internal/preview"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testCall() *model.Call {
+	return &model.Call{
+		ID:       "launch",
+		Author:   "jane@example.com",
+		Subject:  "Launch on {{ .Day }}",
+		Content:  "The **new** dashboard ships on {{ .Day }}.",
+		Campaign: model.Campaign{ID: "launches", Name: "Launches"},
+		Data:     map[string]interface{}{"Day": "Tuesday"},
+	}
+}
+
+func TestRenderer_HTML(t *testing.T) {
+	r := preview.NewRenderer()
+	at := time.Date(2025, 3, 4, 14, 30, 0, 0, time.UTC)
+
+	page, err := r.HTML(testCall(), preview.TypeSlack, at)
+	require.NoError(t, err)
+	assert.Contains(t, string(page), `<div class="avatar">L</div>`)
+	assert.Contains(t, string(page), `<strong>Launch on Tuesday</strong>`)
+	assert.Contains(t, string(page), `The <strong>new</strong> dashboard ships on Tuesday.`)
+	assert.Contains(t, string(page), "Thx: jane@example.com")
+	assert.Contains(t, string(page), "2:30 PM")
+
+	page, err = r.HTML(testCall(), preview.TypeEmail, at)
+	require.NoError(t, err)
+	assert.Contains(t, string(page), "[Launches] Launch on Tuesday")
+	assert.Contains(t, string(page), "From: jane@example.com")
+
+	_, err = r.HTML(testCall(), "line", at)
+	assert.ErrorIs(t, err, preview.ErrUnsupportedType)
+
+	broken := testCall()
+	broken.Content = "{{ .Day"
+	_, err = r.HTML(broken, preview.TypeSlack, at)
+	assert.ErrorIs(t, err, preview.ErrRenderFailed)
+}
+
+func TestRenderer_Screenshot(t *testing.T) {
+	var browser string
+	for _, name := range []string{"headless-shell", "chromium", "chromium-browser", "google-chrome"} {
+		if path, err := exec.LookPath(name); err == nil {
+			browser = path
+			break
+		}
+	}
+	if browser == "" {
+		t.Skip("no headless browser is installed")
+	}
+
+	r := preview.NewRenderer(preview.WithExecPath(browser), preview.WithWidth(400), preview.WithScale(1))
+	data, err := r.Screenshot(context.Background(), testCall(), preview.TypeSlack, time.Now())
+	require.NoError(t, err)
+
+	img, err := png.Decode(bytes.NewReader(data))
+	require.NoError(t, err)
+	assert.LessOrEqual(t, img.Bounds().Dx(), 400)
+}
@@ -0,0 +1,291 @@
+package processor
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"golang.org/x/net/html"
+)
+
+// ErrInaccessible is returned by the AccessibilityProcessor when HTML has accessibility problems.
+var ErrInaccessible = errors.New("content is not accessible")
+
+// The rules that accessibility issues are reported under.
+const (
+	RuleImageAlt = "image-alt"
+	RuleContrast = "contrast"
+	RuleAllCaps  = "all-caps"
+	RuleLinkText = "link-text"
+)
+
+// MinContrast is the lowest contrast ratio between text and its background that is accepted, as
+// required of normal text by WCAG 2 level AA.
+const MinContrast = 4.5
+
+// minShoutingWords is the number of words in a row written in capitals that reads as shouting.
+const minShoutingWords = 3
+
+// vagueLinkTexts are link texts that do not say where a link goes when read on their own, as screen
+// readers list them.
+var vagueLinkTexts = map[string]bool{
+	"here": true, "click here": true, "click": true, "this": true, "this link": true, "link": true,
+	"more": true, "read more": true, "learn more": true, "go": true,
+}
+
+// namedColors are the CSS color keywords that are recognized in inline styles.
+var namedColors = map[string][3]float64{
+	"black": {0, 0, 0}, "white": {255, 255, 255}, "gray": {128, 128, 128}, "grey": {128, 128, 128},
+	"silver": {192, 192, 192}, "lightgray": {211, 211, 211}, "lightgrey": {211, 211, 211},
+	"darkgray": {169, 169, 169}, "darkgrey": {169, 169, 169}, "red": {255, 0, 0}, "green": {0, 128, 0},
+	"blue": {0, 0, 255}, "yellow": {255, 255, 0}, "orange": {255, 165, 0}, "navy": {0, 0, 128},
+	"maroon": {128, 0, 0}, "purple": {128, 0, 128}, "teal": {0, 128, 128}, "lime": {0, 255, 0},
+	"aqua": {0, 255, 255}, "cyan": {0, 255, 255}, "fuchsia": {255, 0, 255}, "magenta": {255, 0, 255},
+	"pink": {255, 192, 203}, "beige": {245, 245, 220}, "ivory": {255, 255, 240}, "whitesmoke": {245, 245, 245},
+}
+
+// Issue is an accessibility problem found in HTML.
+type Issue struct {
+	Rule    string
+	Message string
+}
+
+func (i Issue) String() string {
+	return i.Rule + ": " + i.Message
+}
+
+// AccessibilityProcessor checks HTML, such as that of an email, for accessibility problems. It returns
+// the content unchanged, along with an error wrapping ErrInaccessible that lists the problems found.
+type AccessibilityProcessor struct{}
+
+// NewAccessibilityProcessor creates a new AccessibilityProcessor.
+func NewAccessibilityProcessor() *AccessibilityProcessor {
+	return &AccessibilityProcessor{}
+}
+
+// Process checks the HTML for accessibility problems.
+func (p *AccessibilityProcessor) Process(content string, _ map[string]interface{}) (string, error) {
+	issues, err := CheckAccessibility(content)
+	if err != nil {
+		return content, err
+	}
+	if len(issues) > 0 {
+		messages := make([]string, len(issues))
+		for i, issue := range issues {
+			messages[i] = issue.String()
+		}
+		return content, fmt.Errorf("%w: %s", ErrInaccessible, strings.Join(messages, "; "))
+	}
+	return content, nil
+}
+
+// CheckAccessibility returns the accessibility problems of HTML: images without alt text, text whose
+// inline colors contrast too little with its background, text shouted in capitals, and links whose
+// text does not say where they go. Text without a background color is taken to be on white.
+func CheckAccessibility(content string) ([]Issue, error) {
+	doc, err := html.Parse(strings.NewReader(content))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse html: %w", err)
+	}
+
+	var issues []Issue
+	var walk func(n *html.Node, fg, bg [3]float64)
+	walk = func(n *html.Node, fg, bg [3]float64) {
+		switch n.Type {
+		case html.TextNode:
+			if strings.TrimSpace(n.Data) == "" {
+				return
+			}
+			if ratio := contrast(fg, bg); ratio < MinContrast {
+				issues = append(issues, Issue{RuleContrast, fmt.Sprintf("text %q has a contrast ratio of %.1f:1, below %.1f:1", summary(n.Data), ratio, MinContrast)})
+			}
+			if shouting(n.Data) {
+				issues = append(issues, Issue{RuleAllCaps, fmt.Sprintf("text %q is written in capitals", summary(n.Data))})
+			}
+			return
+		case html.ElementNode:
+			style := styles(attr(n, "style"))
+			if c, ok := parseColor(style["color"]); ok {
+				fg = c
+			}
+			if c, ok := parseColor(style["background-color"]); ok {
+				bg = c
+			} else if c, ok := parseColor(style["background"]); ok {
+				bg = c
+			}
+
+			switch n.Data {
+			case "img":
+				if alt, ok := attrOK(n, "alt"); !ok || strings.TrimSpace(alt) == "" {
+					issues = append(issues, Issue{RuleImageAlt, fmt.Sprintf("image %q has no alt text", attr(n, "src"))})
+				}
+			case "a":
+				text := strings.Join(strings.Fields(linkText(n)), " ")
+				name := strings.ToLower(strings.TrimRight(text, ".!:…"))
+				if text == "" {
+					issues = append(issues, Issue{RuleLinkText, fmt.Sprintf("link to %q has no text", attr(n, "href"))})
+				} else if vagueLinkTexts[name] {
+					issues = append(issues, Issue{RuleLinkText, fmt.Sprintf("link to %q has the text %q, which does not say where it goes", attr(n, "href"), text)})
+				}
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c, fg, bg)
+		}
+	}
+	walk(doc, namedColors["black"], namedColors["white"])
+	return issues, nil
+}
+
+// linkText returns the text of a link as a screen reader reads it, including the alt text of images.
+func linkText(n *html.Node) string {
+	if label := attr(n, "aria-label"); label != "" {
+		return label
+	}
+	var text strings.Builder
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		switch {
+		case n.Type == html.TextNode:
+			text.WriteString(n.Data)
+		case n.Type == html.ElementNode && n.Data == "img":
+			text.WriteString(" " + attr(n, "alt") + " ")
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(n)
+	return text.String()
+}
+
+// shouting reports whether text has minShoutingWords or more words in a row written in capitals, which
+// screen readers may spell out and readers take as shouting. Shorter runs are taken to be acronyms.
+func shouting(text string) bool {
+	run := 0
+	for _, word := range strings.Fields(text) {
+		letters, upper := 0, true
+		for _, r := range word {
+			if unicode.IsLetter(r) {
+				letters++
+				upper = upper && unicode.IsUpper(r)
+			}
+		}
+		if letters < 2 {
+			continue
+		}
+		if !upper {
+			run = 0
+			continue
+		}
+		if run++; run >= minShoutingWords {
+			return true
+		}
+	}
+	return false
+}
+
+// summary shortens text for an issue.
+func summary(text string) string {
+	text = strings.Join(strings.Fields(text), " ")
+	if r := []rune(text); len(r) > 40 {
+		return string(r[:40]) + "…"
+	}
+	return text
+}
+
+func attr(n *html.Node, key string) string {
+	v, _ := attrOK(n, key)
+	return v
+}
+
+func attrOK(n *html.Node, key string) (string, bool) {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val, true
+		}
+	}
+	return "", false
+}
+
+// styles parses the declarations of an inline style.
+func styles(style string) map[string]string {
+	declarations := make(map[string]string)
+	for _, declaration := range strings.Split(style, ";") {
+		name, value, ok := strings.Cut(declaration, ":")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(value), "!important"))
+		declarations[strings.ToLower(strings.TrimSpace(name))] = strings.ToLower(value)
+	}
+	return declarations
+}
+
+// parseColor parses a color given as a keyword, a hex color or rgb(). Colors it does not recognize,
+// such as those of a background image, are left to the element they are inherited from.
+func parseColor(value string) ([3]float64, bool) {
+	value = strings.TrimSpace(value)
+	if c, ok := namedColors[value]; ok {
+		return c, true
+	}
+	if hex, ok := strings.CutPrefix(value, "#"); ok {
+		if len(hex) == 3 {
+			hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+		}
+		if len(hex) != 6 {
+			return [3]float64{}, false
+		}
+		var c [3]float64
+		for i := range c {
+			v, err := strconv.ParseUint(hex[2*i:2*i+2], 16, 8)
+			if err != nil {
+				return [3]float64{}, false
+			}
+			c[i] = float64(v)
+		}
+		return c, true
+	}
+	if args, ok := strings.CutPrefix(value, "rgb("); ok {
+		parts := strings.Split(strings.TrimSuffix(args, ")"), ",")
+		if len(parts) != 3 {
+			return [3]float64{}, false
+		}
+		var c [3]float64
+		for i, part := range parts {
+			v, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+			if err != nil {
+				return [3]float64{}, false
+			}
+			c[i] = v
+		}
+		return c, true
+	}
+	return [3]float64{}, false
+}
+
+// contrast returns the WCAG 2 contrast ratio of two colors.
+func contrast(a, b [3]float64) float64 {
+	la, lb := luminance(a), luminance(b)
+	if la < lb {
+		la, lb = lb, la
+	}
+	return (la + 0.05) / (lb + 0.05)
+}
+
+// luminance returns the relative luminance of a color, as defined by WCAG 2.
+func luminance(c [3]float64) float64 {
+	var channels [3]float64
+	for i, v := range c {
+		v /= 255
+		if v <= 0.03928 {
+			channels[i] = v / 12.92
+		} else {
+			channels[i] = math.Pow((v+0.055)/1.055, 2.4)
+		}
+	}
+	return 0.2126*channels[0] + 0.7152*channels[1] + 0.0722*channels[2]
+}
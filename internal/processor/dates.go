@@ -0,0 +1,79 @@
+package processor
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/andrewhowdencom/ruf/internal/calendar"
+)
+
+// ErrUnknownLocale is returned when a date is rendered in a locale whose names are not known.
+var ErrUnknownLocale = errors.New("unknown locale")
+
+// weekdays are the names of the days of the week, from Sunday, by language.
+var weekdays = map[string][7]string{
+	"ar": {"الأحد", "الاثنين", "الثلاثاء", "الأربعاء", "الخميس", "الجمعة", "السبت"},
+	"da": {"søndag", "mandag", "tirsdag", "onsdag", "torsdag", "fredag", "lørdag"},
+	"de": {"Sonntag", "Montag", "Dienstag", "Mittwoch", "Donnerstag", "Freitag", "Samstag"},
+	"en": {"Sunday", "Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday"},
+	"es": {"domingo", "lunes", "martes", "miércoles", "jueves", "viernes", "sábado"},
+	"fa": {"یکشنبه", "دوشنبه", "سه‌شنبه", "چهارشنبه", "پنجشنبه", "جمعه", "شنبه"},
+	"fi": {"sunnuntai", "maanantai", "tiistai", "keskiviikko", "torstai", "perjantai", "lauantai"},
+	"fr": {"dimanche", "lundi", "mardi", "mercredi", "jeudi", "vendredi", "samedi"},
+	"he": {"יום ראשון", "יום שני", "יום שלישי", "יום רביעי", "יום חמישי", "יום שישי", "שבת"},
+	"id": {"Minggu", "Senin", "Selasa", "Rabu", "Kamis", "Jumat", "Sabtu"},
+	"it": {"domenica", "lunedì", "martedì", "mercoledì", "giovedì", "venerdì", "sabato"},
+	"ja": {"日曜日", "月曜日", "火曜日", "水曜日", "木曜日", "金曜日", "土曜日"},
+	"ko": {"일요일", "월요일", "화요일", "수요일", "목요일", "금요일", "토요일"},
+	"ms": {"Ahad", "Isnin", "Selasa", "Rabu", "Khamis", "Jumaat", "Sabtu"},
+	"nb": {"søndag", "mandag", "tirsdag", "onsdag", "torsdag", "fredag", "lørdag"},
+	"nl": {"zondag", "maandag", "dinsdag", "woensdag", "donderdag", "vrijdag", "zaterdag"},
+	"pl": {"niedziela", "poniedziałek", "wtorek", "środa", "czwartek", "piątek", "sobota"},
+	"pt": {"domingo", "segunda-feira", "terça-feira", "quarta-feira", "quinta-feira", "sexta-feira", "sábado"},
+	"ru": {"воскресенье", "понедельник", "вторник", "среда", "четверг", "пятница", "суббота"},
+	"sv": {"söndag", "måndag", "tisdag", "onsdag", "torsdag", "fredag", "lördag"},
+	"tr": {"Pazar", "Pazartesi", "Salı", "Çarşamba", "Perşembe", "Cuma", "Cumartesi"},
+	"uk": {"неділя", "понеділок", "вівторок", "середа", "четвер", "пʼятниця", "субота"},
+	"zh": {"星期日", "星期一", "星期二", "星期三", "星期四", "星期五", "星期六"},
+}
+
+// DateFuncs returns the functions that render dates in other calendars and locales, which every
+// template can use:
+//
+//   - hijri: the day of a time in the Hijri calendar, such as "1 Ramadan 1447".
+//   - calendar: the day of a time in a named calendar system, such as {{ calendar "hebrew" .ScheduledAt }}.
+//   - weekday: the day of the week of a time in a locale, such as {{ weekday .ScheduledAt "de" }}.
+//
+// Days are taken in the location of the time they are given.
+func DateFuncs() template.FuncMap {
+	return template.FuncMap{
+		"hijri": func(t time.Time) (string, error) {
+			return calendar.Format(calendar.Hijri{}, t)
+		},
+		"calendar": func(name string, t time.Time) (string, error) {
+			system, ok := calendar.Systems()[strings.ToLower(name)]
+			if !ok {
+				return "", fmt.Errorf("unknown calendar system '%s': must be hijri, hebrew, chinese or solar_hijri", name)
+			}
+			return calendar.Format(system, t)
+		},
+		"weekday": Weekday,
+	}
+}
+
+// Weekday returns the name of the day of the week of t in a locale, such as "de" or "pt-BR". Regional
+// locales fall back to their language.
+func Weekday(t time.Time, locale string) (string, error) {
+	language, _, _ := strings.Cut(strings.ToLower(strings.ReplaceAll(locale, "_", "-")), "-")
+	if language == "no" {
+		language = "nb"
+	}
+	names, ok := weekdays[language]
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrUnknownLocale, locale)
+	}
+	return names[t.Weekday()], nil
+}
@@ -1,7 +1,9 @@
 package processor
 
 import (
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -17,6 +19,32 @@ func TestTemplateProcessor(t *testing.T) {
 	assert.Equal(t, "Hello, World", processedContent)
 }
 
+func TestTemplateProcessor_Strict(t *testing.T) {
+	data := map[string]interface{}{
+		"version": "1.2",
+		"release": map[string]interface{}{"owner": nil},
+	}
+
+	// Without strict mode, missing values render as "<no value>".
+	content, err := NewTemplateProcessor().Process("{{ .version }} {{ .status }}", data)
+	assert.NoError(t, err)
+	assert.Equal(t, "1.2 <no value>", content)
+
+	p := NewTemplateProcessor(WithStrict())
+	content, err = p.Process("{{ .version }} {{ index . \"status\" | default \"pending\" }}", data)
+	assert.NoError(t, err)
+	assert.Equal(t, "1.2 pending", content)
+
+	_, err = p.Process("{{ .version }} {{ .status }}", data)
+	assert.ErrorIs(t, err, ErrMissingValue)
+	assert.ErrorContains(t, err, "missing template value .status")
+
+	// Values that are nil are missing too.
+	_, err = p.Process("{{ .release.owner }}", data)
+	assert.ErrorIs(t, err, ErrMissingValue)
+	assert.ErrorContains(t, err, ".release.owner")
+}
+
 func TestMarkdownToHTMLProcessor(t *testing.T) {
 	p := NewMarkdownToHTMLProcessor()
 	markdown := "**Hello, World!**"
@@ -49,3 +77,84 @@ func TestProcessorStack(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, expectedHTML, processedContent)
 }
+
+func TestCheckAccessibility(t *testing.T) {
+	content := `<p>Release notes are <a href="https://example.com/notes">here</a>.</p>
+<p><img src="chart.png" alt=""><img src="logo.png" alt="Company logo"></p>
+<p style="color: #aaa">Faint text</p>
+<div style="background-color: #000"><p style="color: #fff">Readable text</p></div>
+<p>PLEASE READ THIS NOW, the NASA API is down.</p>
+<p><a href="https://example.com/status">The status page</a></p>`
+
+	issues, err := CheckAccessibility(content)
+	assert.NoError(t, err)
+	var rules []string
+	for _, issue := range issues {
+		rules = append(rules, issue.Rule)
+	}
+	assert.Equal(t, []string{RuleLinkText, RuleImageAlt, RuleContrast, RuleAllCaps}, rules)
+
+	_, err = NewAccessibilityProcessor().Process(content, nil)
+	assert.ErrorIs(t, err, ErrInaccessible)
+
+	html, err := NewMarkdownToHTMLProcessor().Process("Read [the release notes](https://example.com/notes).", nil)
+	assert.NoError(t, err)
+	_, err = NewAccessibilityProcessor().Process(html, nil)
+	assert.NoError(t, err)
+}
+
+func TestTemplateProcessor_DateFuncs(t *testing.T) {
+	p := NewTemplateProcessor()
+	data := map[string]interface{}{"ScheduledAt": time.Date(2025, 3, 3, 9, 0, 0, 0, time.UTC)}
+
+	content, err := p.Process(`{{ weekday .ScheduledAt "de" }}, {{ hijri .ScheduledAt }} ({{ calendar "hebrew" .ScheduledAt }}, {{ weekday .ScheduledAt "pt-BR" }})`, data)
+	assert.NoError(t, err)
+	assert.Equal(t, "Montag, 3 Ramadan 1446 (3 Adar 5785, segunda-feira)", content)
+
+	_, err = p.Process(`{{ weekday .ScheduledAt "xx" }}`, data)
+	assert.ErrorIs(t, err, ErrUnknownLocale)
+
+	_, err = p.Process(`{{ calendar "mayan" .ScheduledAt }}`, data)
+	assert.ErrorContains(t, err, "unknown calendar system 'mayan'")
+}
+
+func TestStack(t *testing.T) {
+	stack, err := Stack("markdown_to_html", "accessibility")
+	assert.NoError(t, err)
+	assert.Len(t, stack, 2)
+
+	stack, err = Stack()
+	assert.NoError(t, err)
+	out, err := stack.Process("**as written**", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "**as written**", out)
+
+	_, err = Stack("markdown_to_pdf")
+	assert.ErrorIs(t, err, ErrUnknownProcessor)
+
+	Register("shout", func() Processor { return shout{} })
+	stack, err = Stack("shout")
+	assert.NoError(t, err)
+	out, err = stack.Process("hello", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "HELLO", out)
+	assert.Contains(t, Names(), "shout")
+}
+
+type shout struct{}
+
+func (shout) Process(content string, _ map[string]interface{}) (string, error) {
+	return strings.ToUpper(content), nil
+}
+
+func TestTemplateProcessor_Fields(t *testing.T) {
+	fields, err := NewTemplateProcessor().Fields(`Hello {{ .Name }}, {{ .Release.Version | upper }}
+{{ if .Late }}{{ .Name }} is late{{ end }}
+{{ range .Items }}{{ .Title }} for {{ $.Owner }}{{ else }}{{ .Empty }}{{ end }}
+{{ with .Meta }}{{ .Ignored }}{{ end }}{{ printf "%s" (.Chained).Field }}`)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Name", "Release", "Late", "Items", "Owner", "Empty", "Meta", "Chained"}, fields)
+
+	_, err = NewTemplateProcessor().Fields("{{ .Name ")
+	assert.Error(t, err)
+}
@@ -0,0 +1,62 @@
+package processor
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ErrUnknownProcessor is returned when a processor is named that has not been registered.
+var ErrUnknownProcessor = errors.New("unknown processor")
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[string]func() Processor{
+		"markdown_to_slack": func() Processor { return NewMarkdownToSlackProcessor() },
+		"markdown_to_html":  func() Processor { return NewMarkdownToHTMLProcessor() },
+		"accessibility":     func() Processor { return NewAccessibilityProcessor() },
+	}
+)
+
+// Register makes a processor available by name, so that configuration and calls can add it to the
+// processors of a destination type. It replaces a processor registered with the same name.
+func Register(name string, factory func() Processor) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry[name] = factory
+}
+
+// Names returns the names of the registered processors, in order.
+func Names() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	return namesLocked()
+}
+
+// Stack returns the stack of the processors with the names, in order. An empty list returns an empty
+// stack, which leaves the content as it is.
+func Stack(names ...string) (ProcessorStack, error) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	stack := make(ProcessorStack, 0, len(names))
+	for _, name := range names {
+		factory, ok := registry[name]
+		if !ok {
+			return nil, fmt.Errorf("%w '%s': must be one of %s", ErrUnknownProcessor, name, strings.Join(namesLocked(), ", "))
+		}
+		stack = append(stack, factory())
+	}
+	return stack, nil
+}
+
+// namesLocked returns the names of the registered processors, in order, while the registry is locked.
+func namesLocked() []string {
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
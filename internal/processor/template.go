@@ -2,30 +2,179 @@ package processor
 
 import (
 	"bytes"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
 	"text/template"
+	"text/template/parse"
 
 	"github.com/Masterminds/sprig/v3"
 )
 
+// ErrMissingValue is returned by a strict TemplateProcessor when a template refers to a value that the
+// data does not have.
+var ErrMissingValue = errors.New("missing template value")
+
+// missingValueAt finds the expression that failed in the error of a template.
+var missingValueAt = regexp.MustCompile(`at <([^>]*)>`)
+
 // TemplateProcessor renders a Go template string.
-type TemplateProcessor struct{}
+type TemplateProcessor struct {
+	funcs  template.FuncMap
+	strict bool
+}
+
+// TemplateOption configures a TemplateProcessor.
+type TemplateOption func(*TemplateProcessor)
+
+// WithFuncs makes functions available to the template, in addition to those of sprig and DateFuncs.
+func WithFuncs(funcs template.FuncMap) TemplateOption {
+	return func(p *TemplateProcessor) {
+		p.funcs = funcs
+	}
+}
+
+// WithStrict fails a template that refers to a value the data does not have, or has as nil, with an
+// error wrapping ErrMissingValue that names it, rather than rendering "<no value>".
+func WithStrict() TemplateOption {
+	return func(p *TemplateProcessor) {
+		p.strict = true
+	}
+}
 
 // NewTemplateProcessor creates a new TemplateProcessor.
-func NewTemplateProcessor() *TemplateProcessor {
-	return &TemplateProcessor{}
+func NewTemplateProcessor(opts ...TemplateOption) *TemplateProcessor {
+	p := &TemplateProcessor{}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
 }
 
 // Process renders a template string.
 func (p *TemplateProcessor) Process(content string, data map[string]interface{}) (string, error) {
-	t, err := template.New("").Funcs(sprig.TxtFuncMap()).Parse(content)
+	t, err := template.New("").Funcs(sprig.TxtFuncMap()).Funcs(DateFuncs()).Funcs(p.funcs).Parse(content)
 	if err != nil {
 		return "", err
 	}
 
+	var input interface{} = data
+	if p.strict {
+		// Values that are nil would still render as "<no value>", so they are left out to be missing.
+		t.Option("missingkey=error")
+		input = withoutNil(data)
+	}
+
 	var buf bytes.Buffer
-	if err := t.Execute(&buf, data); err != nil {
+	if err := t.Execute(&buf, input); err != nil {
+		if p.strict && missing(err) {
+			name := "?"
+			if m := missingValueAt.FindStringSubmatch(err.Error()); m != nil {
+				name = m[1]
+			}
+			return "", fmt.Errorf("%w %s: %w", ErrMissingValue, name, err)
+		}
 		return "", err
 	}
 
 	return buf.String(), nil
 }
+
+// Fields returns the names of the values of the data that a template string refers to, such as Name for
+// {{ .Name }}, in the order they first appear. Only the data itself is looked into: values referred to
+// inside range and with, where dot is something else, are left out unless they are read from $.
+func (p *TemplateProcessor) Fields(content string) ([]string, error) {
+	t, err := template.New("").Funcs(sprig.TxtFuncMap()).Funcs(DateFuncs()).Funcs(p.funcs).Parse(content)
+	if err != nil {
+		return nil, err
+	}
+	var fields []string
+	if t.Tree != nil {
+		walkFields(t.Tree.Root, true, &fields)
+	}
+	return fields, nil
+}
+
+// walkFields collects the fields of the data a node of a template refers to. root is whether dot is the
+// data at the node.
+func walkFields(node parse.Node, root bool, fields *[]string) {
+	add := func(name string) {
+		if !slices.Contains(*fields, name) {
+			*fields = append(*fields, name)
+		}
+	}
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, child := range n.Nodes {
+			walkFields(child, root, fields)
+		}
+	case *parse.ActionNode:
+		walkFields(n.Pipe, root, fields)
+	case *parse.TemplateNode:
+		walkFields(n.Pipe, root, fields)
+	case *parse.PipeNode:
+		if n == nil {
+			return
+		}
+		for _, cmd := range n.Cmds {
+			for _, arg := range cmd.Args {
+				walkFields(arg, root, fields)
+			}
+		}
+	case *parse.ChainNode:
+		walkFields(n.Node, root, fields)
+	case *parse.FieldNode:
+		if root {
+			add(n.Ident[0])
+		}
+	case *parse.VariableNode:
+		if n.Ident[0] == "$" && len(n.Ident) > 1 {
+			add(n.Ident[1])
+		}
+	case *parse.IfNode:
+		walkFields(n.Pipe, root, fields)
+		walkFields(n.List, root, fields)
+		walkFields(n.ElseList, root, fields)
+	case *parse.RangeNode:
+		walkFields(n.Pipe, root, fields)
+		walkFields(n.List, false, fields)
+		walkFields(n.ElseList, root, fields)
+	case *parse.WithNode:
+		walkFields(n.Pipe, root, fields)
+		walkFields(n.List, false, fields)
+		walkFields(n.ElseList, root, fields)
+	}
+}
+
+// missing reports whether a template failed as it referred to a value that the data does not have.
+func missing(err error) bool {
+	msg := err.Error()
+	return strings.Contains(msg, "map has no entry for key") || strings.Contains(msg, "nil pointer evaluating")
+}
+
+// withoutNil returns a copy of the data without its nil values, in nested maps and lists too.
+func withoutNil(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for key, value := range v {
+			if value != nil {
+				m[key] = withoutNil(value)
+			}
+		}
+		return m
+	case []interface{}:
+		l := make([]interface{}, len(v))
+		for i, value := range v {
+			l[i] = withoutNil(value)
+		}
+		return l
+	default:
+		return v
+	}
+}
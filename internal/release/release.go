@@ -0,0 +1,320 @@
+package release
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+// DefaultEndpoint is the API endpoint describing the latest published release.
+const DefaultEndpoint = "https://api.github.com/repos/andrewhowdencom/ruf/releases/latest"
+
+// Err* are common errors returned when updating the binary.
+var (
+	ErrFetchFailed        = errors.New("failed to fetch release")
+	ErrAssetNotFound      = errors.New("release asset not found")
+	ErrChecksumMismatch   = errors.New("checksum mismatch")
+	ErrInvalidSignature   = errors.New("invalid signature")
+	ErrNoPublicKey        = errors.New("no public key to verify the release with")
+	ErrInvalidVersion     = errors.New("invalid version")
+	ErrInstallationFailed = errors.New("failed to install binary")
+)
+
+// Asset is a file attached to a release.
+type Asset struct {
+	Name string `json:"name"`
+	URL  string `json:"browser_download_url"`
+}
+
+// Release is a published version of the application.
+type Release struct {
+	Tag    string  `json:"tag_name"`
+	Assets []Asset `json:"assets"`
+}
+
+// Version returns the version of the release without the "v" prefix.
+func (r *Release) Version() string {
+	return strings.TrimPrefix(r.Tag, "v")
+}
+
+// Asset returns the asset with the given name.
+func (r *Release) Asset(name string) (*Asset, error) {
+	for i := range r.Assets {
+		if r.Assets[i].Name == name {
+			return &r.Assets[i], nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", ErrAssetNotFound, name)
+}
+
+// Updater checks for and installs new releases.
+type Updater struct {
+	client    *http.Client
+	endpoint  string
+	publicKey ed25519.PublicKey
+	unsigned  bool
+	goos      string
+	goarch    string
+}
+
+// Option configures the Updater.
+type Option func(*Updater)
+
+// WithEndpoint sets the endpoint describing the latest release.
+func WithEndpoint(endpoint string) Option {
+	return func(u *Updater) {
+		u.endpoint = endpoint
+	}
+}
+
+// WithPublicKey requires the checksums file to be signed by the given ed25519 key.
+func WithPublicKey(key ed25519.PublicKey) Option {
+	return func(u *Updater) {
+		u.publicKey = key
+	}
+}
+
+// WithoutSignature accepts releases without verifying the signature of their checksums. The archive is
+// then only checked against checksums published alongside it, which does not protect against a release
+// or mirror that was tampered with.
+func WithoutSignature() Option {
+	return func(u *Updater) {
+		u.unsigned = true
+	}
+}
+
+// WithPlatform overrides the operating system and architecture to fetch binaries for.
+func WithPlatform(goos, goarch string) Option {
+	return func(u *Updater) {
+		u.goos = goos
+		u.goarch = goarch
+	}
+}
+
+// NewUpdater creates a new Updater.
+func NewUpdater(client *http.Client, opts ...Option) *Updater {
+	u := &Updater{
+		client:   client,
+		endpoint: DefaultEndpoint,
+		goos:     runtime.GOOS,
+		goarch:   runtime.GOARCH,
+	}
+	for _, opt := range opts {
+		opt(u)
+	}
+	return u
+}
+
+// ParsePublicKey parses a base64 encoded ed25519 public key.
+func ParsePublicKey(encoded string) (ed25519.PublicKey, error) {
+	key, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode public key: %w", err)
+	}
+	if len(key) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("public key must be %d bytes, got %d", ed25519.PublicKeySize, len(key))
+	}
+	return ed25519.PublicKey(key), nil
+}
+
+// Latest returns the latest published release.
+func (u *Updater) Latest(ctx context.Context) (*Release, error) {
+	body, err := u.get(ctx, u.endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	var r Release
+	if err := json.Unmarshal(body, &r); err != nil {
+		return nil, fmt.Errorf("%w: failed to decode release: %w", ErrFetchFailed, err)
+	}
+	return &r, nil
+}
+
+// IsNewer reports whether the release is newer than the current version.
+// Development builds are never considered up to date.
+func IsNewer(r *Release, current string) (bool, error) {
+	latest, err := semver.NewVersion(r.Version())
+	if err != nil {
+		return false, fmt.Errorf("%w: %s: %w", ErrInvalidVersion, r.Tag, err)
+	}
+	installed, err := semver.NewVersion(strings.TrimPrefix(current, "v"))
+	if err != nil {
+		return true, nil
+	}
+	return latest.GreaterThan(installed), nil
+}
+
+// ArchiveName returns the name of the archive containing the binary for the platform.
+func (u *Updater) ArchiveName(version string) string {
+	return fmt.Sprintf("ruf_%s_%s_%s.tar.gz", version, u.goos, u.goarch)
+}
+
+// ChecksumsName returns the name of the file listing the checksums of all archives.
+func (u *Updater) ChecksumsName(version string) string {
+	return fmt.Sprintf("ruf_%s_checksums.txt", version)
+}
+
+// Download fetches the binary for the release, verifying the signature of the published checksums with
+// the public key, and the archive against the checksums, before returning it. Without a public key, the
+// release is refused unless WithoutSignature is given.
+func (u *Updater) Download(ctx context.Context, r *Release) ([]byte, error) {
+	version := r.Version()
+
+	if u.publicKey == nil && !u.unsigned {
+		return nil, ErrNoPublicKey
+	}
+
+	checksumsAsset, err := r.Asset(u.ChecksumsName(version))
+	if err != nil {
+		return nil, err
+	}
+	checksums, err := u.get(ctx, checksumsAsset.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	if !u.unsigned {
+		sigAsset, err := r.Asset(u.ChecksumsName(version) + ".sig")
+		if err != nil {
+			return nil, err
+		}
+		sig, err := u.get(ctx, sigAsset.URL)
+		if err != nil {
+			return nil, err
+		}
+		decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(sig)))
+		if err != nil {
+			return nil, fmt.Errorf("%w: failed to decode signature: %w", ErrInvalidSignature, err)
+		}
+		if !ed25519.Verify(u.publicKey, checksums, decoded) {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidSignature, sigAsset.Name)
+		}
+	}
+
+	archiveName := u.ArchiveName(version)
+	expected, err := findChecksum(checksums, archiveName)
+	if err != nil {
+		return nil, err
+	}
+
+	archiveAsset, err := r.Asset(archiveName)
+	if err != nil {
+		return nil, err
+	}
+	archive, err := u.get(ctx, archiveAsset.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	sum := sha256.Sum256(archive)
+	if actual := hex.EncodeToString(sum[:]); actual != expected {
+		return nil, fmt.Errorf("%w: %s: expected %s, got %s", ErrChecksumMismatch, archiveName, expected, actual)
+	}
+
+	return extractBinary(archive, "ruf")
+}
+
+// Install atomically replaces the binary at path with the given content.
+func Install(path string, binary []byte) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInstallationFailed, err)
+	}
+
+	// The temporary file is created in the same directory so the rename is atomic.
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".ruf-update-*")
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInstallationFailed, err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(binary); err != nil {
+		tmp.Close()
+		return fmt.Errorf("%w: %w", ErrInstallationFailed, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("%w: %w", ErrInstallationFailed, err)
+	}
+	if err := os.Chmod(tmp.Name(), info.Mode().Perm()); err != nil {
+		return fmt.Errorf("%w: %w", ErrInstallationFailed, err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("%w: %w", ErrInstallationFailed, err)
+	}
+	return nil
+}
+
+func (u *Updater) get(ctx context.Context, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrFetchFailed, err)
+	}
+	resp, err := u.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrFetchFailed, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: %s: status code %d", ErrFetchFailed, url, resp.StatusCode)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrFetchFailed, err)
+	}
+	return body, nil
+}
+
+// findChecksum finds the checksum for a file in a sha256sum formatted list.
+func findChecksum(checksums []byte, name string) (string, error) {
+	scanner := bufio.NewScanner(bytes.NewReader(checksums))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 2 && fields[1] == name {
+			return fields[0], nil
+		}
+	}
+	return "", fmt.Errorf("%w: no checksum listed for %s", ErrChecksumMismatch, name)
+}
+
+// extractBinary returns the content of the named file from a gzipped tarball.
+func extractBinary(archive []byte, name string) ([]byte, error) {
+	gz, err := gzip.NewReader(bytes.NewReader(archive))
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to open archive: %w", ErrInstallationFailed, err)
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: failed to read archive: %w", ErrInstallationFailed, err)
+		}
+		if hdr.Typeflag == tar.TypeReg && filepath.Base(hdr.Name) == name {
+			return io.ReadAll(tr)
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", ErrAssetNotFound, name)
+}
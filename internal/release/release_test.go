@@ -0,0 +1,157 @@
+package release_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/andrewhowdencom/ruf/internal/release"
+	"github.com/stretchr/testify/assert"
+)
+
+func buildArchive(t *testing.T, content []byte) []byte {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	assert.NoError(t, tw.WriteHeader(&tar.Header{Name: "ruf", Mode: 0755, Size: int64(len(content)), Typeflag: tar.TypeReg}))
+	_, err := tw.Write(content)
+	assert.NoError(t, err)
+	assert.NoError(t, tw.Close())
+	assert.NoError(t, gz.Close())
+	return buf.Bytes()
+}
+
+// newReleaseServer serves a release with an archive, checksums, and (optionally) a signature.
+func newReleaseServer(t *testing.T, archive []byte, checksum string, key ed25519.PrivateKey) *httptest.Server {
+	checksums := []byte(fmt.Sprintf("%s  ruf_1.2.0_linux_amd64.tar.gz\n", checksum))
+
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	mux.HandleFunc("/latest", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(release.Release{
+			Tag: "v1.2.0",
+			Assets: []release.Asset{
+				{Name: "ruf_1.2.0_linux_amd64.tar.gz", URL: server.URL + "/archive"},
+				{Name: "ruf_1.2.0_checksums.txt", URL: server.URL + "/checksums"},
+				{Name: "ruf_1.2.0_checksums.txt.sig", URL: server.URL + "/signature"},
+			},
+		})
+	})
+	mux.HandleFunc("/archive", func(w http.ResponseWriter, r *http.Request) { w.Write(archive) })
+	mux.HandleFunc("/checksums", func(w http.ResponseWriter, r *http.Request) { w.Write(checksums) })
+	mux.HandleFunc("/signature", func(w http.ResponseWriter, r *http.Request) {
+		if key == nil {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, base64.StdEncoding.EncodeToString(ed25519.Sign(key, checksums)))
+	})
+	return server
+}
+
+func TestUpdater_Download(t *testing.T) {
+	binary := []byte("#!/bin/sh\necho new\n")
+	archive := buildArchive(t, binary)
+	sum := sha256.Sum256(archive)
+
+	t.Run("verifies the checksum", func(t *testing.T) {
+		server := newReleaseServer(t, archive, hex.EncodeToString(sum[:]), nil)
+		u := release.NewUpdater(server.Client(), release.WithEndpoint(server.URL+"/latest"), release.WithPlatform("linux", "amd64"), release.WithoutSignature())
+
+		r, err := u.Latest(context.Background())
+		assert.NoError(t, err)
+		newer, err := release.IsNewer(r, "1.1.0")
+		assert.NoError(t, err)
+		assert.True(t, newer)
+
+		got, err := u.Download(context.Background(), r)
+		assert.NoError(t, err)
+		assert.Equal(t, binary, got)
+	})
+
+	t.Run("rejects a mismatched checksum", func(t *testing.T) {
+		server := newReleaseServer(t, archive, hex.EncodeToString(make([]byte, 32)), nil)
+		u := release.NewUpdater(server.Client(), release.WithEndpoint(server.URL+"/latest"), release.WithPlatform("linux", "amd64"), release.WithoutSignature())
+
+		r, err := u.Latest(context.Background())
+		assert.NoError(t, err)
+		_, err = u.Download(context.Background(), r)
+		assert.ErrorIs(t, err, release.ErrChecksumMismatch)
+	})
+
+	t.Run("refuses a release without a key to verify it with", func(t *testing.T) {
+		server := newReleaseServer(t, archive, hex.EncodeToString(sum[:]), nil)
+		u := release.NewUpdater(server.Client(), release.WithEndpoint(server.URL+"/latest"), release.WithPlatform("linux", "amd64"))
+
+		r, err := u.Latest(context.Background())
+		assert.NoError(t, err)
+		_, err = u.Download(context.Background(), r)
+		assert.ErrorIs(t, err, release.ErrNoPublicKey)
+	})
+
+	t.Run("verifies the signature", func(t *testing.T) {
+		pub, priv, err := ed25519.GenerateKey(nil)
+		assert.NoError(t, err)
+		otherPub, _, err := ed25519.GenerateKey(nil)
+		assert.NoError(t, err)
+
+		server := newReleaseServer(t, archive, hex.EncodeToString(sum[:]), priv)
+		r, err := release.NewUpdater(server.Client(), release.WithEndpoint(server.URL+"/latest")).Latest(context.Background())
+		assert.NoError(t, err)
+
+		u := release.NewUpdater(server.Client(), release.WithPlatform("linux", "amd64"), release.WithPublicKey(pub))
+		_, err = u.Download(context.Background(), r)
+		assert.NoError(t, err)
+
+		u = release.NewUpdater(server.Client(), release.WithPlatform("linux", "amd64"), release.WithPublicKey(otherPub))
+		_, err = u.Download(context.Background(), r)
+		assert.ErrorIs(t, err, release.ErrInvalidSignature)
+
+		unsigned := newReleaseServer(t, archive, hex.EncodeToString(sum[:]), nil)
+		r, err = release.NewUpdater(unsigned.Client(), release.WithEndpoint(unsigned.URL+"/latest")).Latest(context.Background())
+		assert.NoError(t, err)
+		u = release.NewUpdater(unsigned.Client(), release.WithPlatform("linux", "amd64"), release.WithPublicKey(pub))
+		_, err = u.Download(context.Background(), r)
+		assert.ErrorIs(t, err, release.ErrFetchFailed, "a release without a signature is refused")
+	})
+}
+
+func TestIsNewer(t *testing.T) {
+	r := &release.Release{Tag: "v1.2.0"}
+
+	newer, err := release.IsNewer(r, "1.2.0")
+	assert.NoError(t, err)
+	assert.False(t, newer)
+
+	newer, err = release.IsNewer(r, "dev")
+	assert.NoError(t, err)
+	assert.True(t, newer)
+}
+
+func TestInstall(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ruf")
+	assert.NoError(t, os.WriteFile(path, []byte("old"), 0755))
+
+	assert.NoError(t, release.Install(path, []byte("new")))
+
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, "new", string(data))
+	info, err := os.Stat(path)
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0755), info.Mode().Perm())
+}
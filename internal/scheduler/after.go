@@ -0,0 +1,76 @@
+package scheduler
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/andrewhowdencom/ruf/internal/kv"
+)
+
+// SentAt returns when the call with the given ID, of the given campaign, was first sent to any of its
+// recipients. It returns false if it has not been sent.
+func SentAt(storer kv.Storer, campaignID, callID string) (time.Time, bool, error) {
+	messages, err := storer.ListSentMessagesByCampaign(campaignID)
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("failed to list sent messages of campaign '%s': %w", campaignID, err)
+	}
+	at, ok := firstSent(messages)[callID]
+	return at, ok, nil
+}
+
+// firstSent returns when each call was first sent, by the ID of the call definition. Expanded calls
+// are matched by the ID of the call they were expanded from, which comes before the first colon of
+// their ID.
+func firstSent(messages []*kv.SentMessage) map[string]time.Time {
+	sent := make(map[string]time.Time)
+	for _, sm := range messages {
+		// A message that was deleted once it was no longer needed was still sent.
+		if sm.Status != kv.StatusSent && sm.Status != kv.StatusExpired {
+			continue
+		}
+		id, _, _ := strings.Cut(sm.SourceID, ":")
+		// Messages recorded before the time they were sent was kept are taken as sent on schedule.
+		at := sm.SentAt
+		if at.IsZero() {
+			at = sm.ScheduledAt
+		}
+		if first, ok := sent[id]; !ok || at.Before(first) {
+			sent[id] = at
+		}
+	}
+	return sent
+}
+
+// sentCalls finds when the calls of a campaign were sent, for triggers that follow them. The messages
+// of each campaign are read once per expansion.
+type sentCalls struct {
+	storer    kv.Storer
+	mu        sync.Mutex
+	campaigns map[string]map[string]time.Time
+}
+
+func newSentCalls(storer kv.Storer) *sentCalls {
+	return &sentCalls{storer: storer, campaigns: make(map[string]map[string]time.Time)}
+}
+
+// sentAt returns when the call with the given ID was first sent.
+func (c *sentCalls) sentAt(campaignID, callID string) (time.Time, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	sent, ok := c.campaigns[campaignID]
+	if !ok {
+		messages, err := c.storer.ListSentMessagesByCampaign(campaignID)
+		if err != nil {
+			slog.Error("failed to list sent messages of campaign", "campaign_id", campaignID, "error", err)
+			return time.Time{}, false
+		}
+		sent = firstSent(messages)
+		c.campaigns[campaignID] = sent
+	}
+
+	at, ok := sent[callID]
+	return at, ok
+}
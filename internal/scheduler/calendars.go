@@ -0,0 +1,65 @@
+package scheduler
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/andrewhowdencom/ruf/internal/calendar"
+	"github.com/andrewhowdencom/ruf/internal/model"
+)
+
+// calendarDate is a date in a calendar system that a trigger fires on, such as "1 tishrei".
+type calendarDate struct {
+	system calendar.System
+	spec   string
+}
+
+// calendarDates returns the dates in other calendar systems that a trigger fires on.
+func calendarDates(trigger model.Trigger) []calendarDate {
+	var dates []calendarDate
+	for _, d := range []calendarDate{
+		{calendar.Hijri{}, trigger.Hijri},
+		{calendar.Hebrew{}, trigger.Hebrew},
+		{calendar.Chinese{}, trigger.Chinese},
+		{calendar.SolarHijri{}, trigger.SolarHijri},
+	} {
+		if d.spec != "" {
+			dates = append(dates, d)
+		}
+	}
+	return dates
+}
+
+// calendarOccurrence returns the next time after now that a date in a calendar system falls on,
+// at the time of day of the trigger. The time of day is in loc unless it carries its own offset.
+func calendarOccurrence(d calendarDate, timeOfDay string, loc *time.Location, now time.Time) (time.Time, error) {
+	date, err := d.system.ParseDate(d.spec)
+	if err != nil {
+		return time.Time{}, err
+	}
+	day, ok := calendar.NextOccurrence(d.system, date, now)
+	if !ok {
+		return time.Time{}, fmt.Errorf("could not find a future gregorian date for the %s date '%s'", d.system.Name(), d.spec)
+	}
+	if timeOfDay == "" {
+		// Default to midnight in the timezone of the trigger
+		return time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, loc), nil
+	}
+
+	// Check for timezone offset
+	if strings.Contains(timeOfDay, "Z") || strings.Contains(timeOfDay, "+") || strings.Contains(timeOfDay, "-") {
+		parsed, err := time.Parse(time.RFC3339, fmt.Sprintf("2006-01-02T%s", timeOfDay))
+		if err != nil {
+			return time.Time{}, fmt.Errorf("failed to parse time with timezone '%s': %w", timeOfDay, err)
+		}
+		loc = parsed.Location()
+		timeOfDay = parsed.Format("15:04:05")
+	}
+
+	clock, err := parseTimeOfDay(timeOfDay)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, loc).Add(clock), nil
+}
@@ -0,0 +1,137 @@
+package scheduler
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/andrewhowdencom/ruf/internal/model"
+	"github.com/andrewhowdencom/ruf/internal/sourcer"
+)
+
+// The kinds of change between the occurrences of two revisions of the sources.
+const (
+	ChangeAdded   = "added"
+	ChangeRemoved = "removed"
+	ChangeMoved   = "moved"
+)
+
+// Change is an occurrence of a call that one revision of the sources schedules differently from another.
+type Change struct {
+	Kind string
+	// CampaignID and CallID are those of the call definition the occurrence was expanded from.
+	CampaignID  string
+	CallID      string
+	Subject     string
+	Destination model.Destination
+	// From is when the previous revision sends the occurrence, and To when the next one does. From is zero
+	// for an added occurrence, and To for a removed one.
+	From time.Time
+	To   time.Time
+}
+
+// Diff simulates the window from from up to to for two revisions of the sources, as Simulate does, and
+// returns how the occurrences of the next revision differ from those of the previous one, in the order they are
+// sent. The occurrences of a call to a destination that both revisions send at the same time are the
+// same; those left are paired in order as moved, and any left over are added or removed.
+func (s *Scheduler) Diff(previous, next []*sourcer.Source, from, to time.Time) []Change {
+	before, after := s.occurrencesByCall(previous, from, to), s.occurrencesByCall(next, from, to)
+	keys := make(map[string]bool, len(before)+len(after))
+	for key := range before {
+		keys[key] = true
+	}
+	for key := range after {
+		keys[key] = true
+	}
+
+	var changes []Change
+	for key := range keys {
+		removed, added := withoutCommon(before[key], after[key])
+		for i := range max(len(removed), len(added)) {
+			var change Change
+			switch {
+			case i >= len(added):
+				change = newChange(ChangeRemoved, removed[i])
+				change.From = removed[i].call.ScheduledAt
+			case i >= len(removed):
+				change = newChange(ChangeAdded, added[i])
+				change.To = added[i].call.ScheduledAt
+			default:
+				change = newChange(ChangeMoved, added[i])
+				change.From, change.To = removed[i].call.ScheduledAt, added[i].call.ScheduledAt
+			}
+			changes = append(changes, change)
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		a, b := changes[i].at(), changes[j].at()
+		if !a.Equal(b) {
+			return a.Before(b)
+		}
+		return changes[i].CampaignID+"/"+changes[i].CallID < changes[j].CampaignID+"/"+changes[j].CallID
+	})
+	return changes
+}
+
+// at is when the change takes effect: when the previous revision sends the occurrence, or the next one
+// does if it is added.
+func (c Change) at() time.Time {
+	if c.From.IsZero() {
+		return c.To
+	}
+	return c.From
+}
+
+// simulated is an occurrence of a simulation, and the call definition it was expanded from.
+type simulated struct {
+	call               *model.Call
+	campaignID, callID string
+}
+
+func newChange(kind string, o simulated) Change {
+	return Change{Kind: kind, CampaignID: o.campaignID, CallID: o.callID, Subject: o.call.Subject, Destination: o.call.Destinations[0]}
+}
+
+// occurrencesByCall simulates the window, and groups the occurrences by their call definition and
+// destination, each in the order they are sent.
+func (s *Scheduler) occurrencesByCall(sources []*sourcer.Source, from, to time.Time) map[string][]simulated {
+	tr := newTracer()
+	occurrences := make(map[string][]simulated)
+	for _, call := range s.simulate(sources, from, to, tr) {
+		o := simulated{call: call, campaignID: call.Campaign.ID}
+		if trace, ok := tr.calls[call]; ok {
+			o.callID = trace.trace.CallID
+		} else {
+			o.callID, _, _ = strings.Cut(call.ID, ":")
+		}
+		dest := call.Destinations[0]
+		key := o.campaignID + "/" + o.callID + "/" + dest.Type + ":" + strings.Join(dest.To, ",")
+		occurrences[key] = append(occurrences[key], o)
+	}
+	return occurrences
+}
+
+// withoutCommon returns the occurrences of each revision that the other does not send at the same time.
+func withoutCommon(previous, next []simulated) ([]simulated, []simulated) {
+	common := make(map[time.Time]int)
+	for _, o := range previous {
+		common[o.call.ScheduledAt]++
+	}
+	var added []simulated
+	for _, o := range next {
+		if common[o.call.ScheduledAt] > 0 {
+			common[o.call.ScheduledAt]--
+			continue
+		}
+		added = append(added, o)
+	}
+	var removed []simulated
+	for _, o := range previous {
+		if common[o.call.ScheduledAt] > 0 {
+			// Not every previous occurrence at this time is sent by the next revision.
+			common[o.call.ScheduledAt]--
+			removed = append(removed, o)
+		}
+	}
+	return removed, added
+}
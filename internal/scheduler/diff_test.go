@@ -0,0 +1,53 @@
+package scheduler_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/andrewhowdencom/ruf/internal/datastore"
+	"github.com/andrewhowdencom/ruf/internal/model"
+	"github.com/andrewhowdencom/ruf/internal/scheduler"
+	"github.com/andrewhowdencom/ruf/internal/sourcer"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSchedulerDiff(t *testing.T) {
+	s := scheduler.New(datastore.NewMockStore())
+	slack := model.Destination{Type: "slack", To: []string{"#team"}}
+	source := func(retro, standup string, reminder bool) []*sourcer.Source {
+		calls := []model.Call{
+			{ID: "retro", Subject: "Retro", Triggers: []model.Trigger{{Cron: retro}}, Destinations: []model.Destination{slack}},
+			{ID: "standup", Subject: "Standup", Triggers: []model.Trigger{{Cron: standup}}, Destinations: []model.Destination{slack}},
+		}
+		if reminder {
+			calls = append(calls, model.Call{ID: "reminder", Subject: "Reminder", Triggers: []model.Trigger{{Cron: "0 12 * * 5"}}, Destinations: []model.Destination{slack}})
+		}
+		for i := range calls {
+			calls[i].Campaign = model.Campaign{ID: "team"}
+		}
+		return []*sourcer.Source{{Campaign: model.Campaign{ID: "team"}, Calls: calls}}
+	}
+	from := time.Date(2025, 6, 2, 0, 0, 0, 0, time.UTC) // A Monday
+	to := from.AddDate(0, 0, 7)
+
+	changes := s.Diff(source("0 15 * * 2", "0 9 * * 1-5", true), source("0 15 * * 4", "0 9 * * 1-5", false), from, to)
+	assert.Equal(t, []scheduler.Change{
+		{
+			Kind: scheduler.ChangeMoved, CampaignID: "team", CallID: "retro", Subject: "Retro", Destination: slack,
+			From: time.Date(2025, 6, 3, 15, 0, 0, 0, time.UTC), To: time.Date(2025, 6, 5, 15, 0, 0, 0, time.UTC),
+		},
+		{
+			Kind: scheduler.ChangeRemoved, CampaignID: "team", CallID: "reminder", Subject: "Reminder", Destination: slack,
+			From: time.Date(2025, 6, 6, 12, 0, 0, 0, time.UTC),
+		},
+	}, changes)
+
+	assert.Empty(t, s.Diff(source("0 15 * * 2", "0 9 * * 1-5", false), source("0 15 * * 2", "0 9 * * 1-5", false), from, to))
+
+	// An occurrence fewer is removed, and the rest are unchanged.
+	changes = s.Diff(source("0 15 * * 2", "0 9 * * 1-5", false), source("0 15 * * 2", "0 9 * * 1-4", false), from, to)
+	assert.Equal(t, []scheduler.Change{{
+		Kind: scheduler.ChangeRemoved, CampaignID: "team", CallID: "standup", Subject: "Standup", Destination: slack,
+		From: time.Date(2025, 6, 6, 9, 0, 0, 0, time.UTC),
+	}}, changes)
+}
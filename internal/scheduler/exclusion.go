@@ -0,0 +1,97 @@
+package scheduler
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/andrewhowdencom/ruf/internal/model"
+	"github.com/teambition/rrule-go"
+)
+
+// filterExcluded removes the calls that fall on an excluded date of the trigger, in a blackout
+// window of their campaign or after the call expires. The calls are filtered in place.
+func filterExcluded(calls []*model.Call, trigger model.Trigger, loc *time.Location, tr *tracer) []*model.Call {
+	kept := calls[:0]
+	for _, call := range calls {
+		if reason := exclusionReason(call, trigger, loc); reason != "" {
+			slog.Debug("skipping excluded occurrence", "call_id", call.ID, "scheduled_at", call.ScheduledAt, "reason", reason)
+			tr.skip(call, "%s", reason)
+			continue
+		}
+		kept = append(kept, call)
+	}
+	return kept
+}
+
+// exclusionReason returns why a call is excluded, or an empty string if it is not.
+func exclusionReason(call *model.Call, trigger model.Trigger, loc *time.Location) string {
+	at := call.ScheduledAt
+
+	if !call.ExpiresAt.IsZero() && !at.Before(call.ExpiresAt) {
+		return fmt.Sprintf("expired at %s", call.ExpiresAt.Format(time.RFC3339))
+	}
+
+	for _, exdate := range trigger.ExDates {
+		excluded, err := matchesExDate(exdate, at, loc)
+		if err != nil {
+			slog.Error("failed to parse exdate", "error", err, "exdate", exdate)
+			continue
+		}
+		if excluded {
+			return fmt.Sprintf("exdate %s", exdate)
+		}
+	}
+
+	if trigger.ExceptRRule != "" {
+		excluded, err := matchesExceptRRule(trigger.ExceptRRule, at, loc)
+		if err != nil {
+			slog.Error("failed to parse except_rrule", "error", err, "except_rrule", trigger.ExceptRRule)
+		} else if excluded {
+			return fmt.Sprintf("except_rrule %s", trigger.ExceptRRule)
+		}
+	}
+
+	for _, blackout := range call.Campaign.Blackouts {
+		if !at.Before(blackout.Start) && at.Before(blackout.End) {
+			return fmt.Sprintf("blackout %s", blackout.Reason)
+		}
+	}
+
+	return ""
+}
+
+// matchesExDate reports whether t matches an exdate. A date excludes the whole day in the given
+// location, while a time excludes only that instant.
+func matchesExDate(exdate string, t time.Time, loc *time.Location) (bool, error) {
+	if day, err := time.ParseInLocation(time.DateOnly, exdate, loc); err == nil {
+		y1, m1, d1 := day.Date()
+		y2, m2, d2 := t.In(loc).Date()
+		return y1 == y2 && m1 == m2 && d1 == d2, nil
+	}
+
+	instant, err := time.Parse(time.RFC3339, exdate)
+	if err != nil {
+		return false, fmt.Errorf("exdate must be a date (YYYY-MM-DD) or an RFC 3339 time: %w", err)
+	}
+	return instant.Equal(t), nil
+}
+
+// matchesExceptRRule reports whether the rule has an occurrence on the day of t in the given location.
+func matchesExceptRRule(rule string, t time.Time, loc *time.Location) (bool, error) {
+	option, err := rrule.StrToROption(rule)
+	if err != nil {
+		return false, err
+	}
+
+	year, month, day := t.In(loc).Date()
+	dayStart := time.Date(year, month, day, 0, 0, 0, 0, loc)
+	option.Dtstart = dayStart
+
+	r, err := rrule.NewRRule(*option)
+	if err != nil {
+		return false, err
+	}
+	dayEnd := dayStart.AddDate(0, 0, 1).Add(-time.Second)
+	return len(r.Between(dayStart, dayEnd, true)) > 0, nil
+}
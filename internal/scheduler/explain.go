@@ -0,0 +1,170 @@
+package scheduler
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/andrewhowdencom/ruf/internal/model"
+	"github.com/andrewhowdencom/ruf/internal/sourcer"
+)
+
+// Trace is how a call definition was expanded: what its triggers fired, why occurrences were
+// skipped or moved, and when the rest are scheduled.
+type Trace struct {
+	CampaignID string
+	CallID     string
+	// Triggers describes each trigger of the call, by index.
+	Triggers []string
+	Steps    []Step
+}
+
+// Step is a single decision made while expanding a call definition.
+type Step struct {
+	// Trigger is the index of the trigger the step belongs to.
+	Trigger int
+	// CallID is the ID of the expanded call the step is about, if it is about a single occurrence.
+	CallID  string
+	Message string
+}
+
+// Explain expands the call definitions like Expand, and returns a trace for each of them, in the order
+// they appear in the sources. Nothing is stored.
+func (s *Scheduler) Explain(sources []*sourcer.Source, now time.Time, before, after time.Duration) []*Trace {
+	tr := newTracer()
+	for _, call := range s.expand(sources, now, before, after, s.previousSlots(), tr) {
+		tr.scheduled(call)
+	}
+	return tr.traces
+}
+
+// occurrence is an expanded call, and the trace and trigger it came from.
+type occurrence struct {
+	trace   *Trace
+	trigger int
+}
+
+// tracer records the steps of an expansion. A nil tracer records nothing, so expansion only pays for
+// tracing when it is explained.
+type tracer struct {
+	traces      []*Trace
+	definitions map[string]*Trace
+	calls       map[*model.Call]occurrence
+	// failures are the steps at which a trigger could not be expanded.
+	failures []string
+}
+
+func newTracer() *tracer {
+	return &tracer{
+		definitions: make(map[string]*Trace),
+		calls:       make(map[*model.Call]occurrence),
+	}
+}
+
+// begin starts the trace of a call definition.
+func (t *tracer) begin(def *model.Call) {
+	if t == nil {
+		return
+	}
+	trace := &Trace{CampaignID: def.Campaign.ID, CallID: def.ID}
+	for _, trigger := range def.Triggers {
+		trace.Triggers = append(trace.Triggers, describeTrigger(trigger))
+	}
+	t.traces = append(t.traces, trace)
+	t.definitions[def.Campaign.ID+"/"+def.ID] = trace
+}
+
+// note records a step of a trigger of a call definition.
+func (t *tracer) note(def *model.Call, trigger int, format string, args ...any) {
+	if t == nil {
+		return
+	}
+	trace := t.definitions[def.Campaign.ID+"/"+def.ID]
+	trace.Steps = append(trace.Steps, Step{Trigger: trigger, Message: fmt.Sprintf(format, args...)})
+}
+
+// fail records a step at which a trigger of a call definition could not be expanded.
+func (t *tracer) fail(def *model.Call, trigger int, format string, args ...any) {
+	if t == nil {
+		return
+	}
+	t.note(def, trigger, format, args...)
+	t.failures = append(t.failures, fmt.Sprintf(format, args...))
+}
+
+// fired records the occurrences a trigger fired, so that what becomes of them can be traced.
+func (t *tracer) fired(def *model.Call, trigger int, calls []*model.Call) {
+	if t == nil {
+		return
+	}
+	trace := t.definitions[def.Campaign.ID+"/"+def.ID]
+	for _, call := range calls {
+		t.calls[call] = occurrence{trace: trace, trigger: trigger}
+		message := fmt.Sprintf("fired at %s", call.OccurredAt().Format(time.RFC3339))
+		if call.DependsOn != nil && call.ScheduledAt.IsZero() {
+			message = fmt.Sprintf("waits for '%s' to be sent", call.DependsOn.CallID)
+		}
+		trace.Steps = append(trace.Steps, Step{Trigger: trigger, CallID: call.ID, Message: message})
+	}
+}
+
+// skip records why an occurrence was dropped.
+func (t *tracer) skip(call *model.Call, format string, args ...any) {
+	t.step(call, "skipped: "+format, args...)
+}
+
+// scheduled records when an occurrence is scheduled, and each time it was moved on the way.
+func (t *tracer) scheduled(call *model.Call) {
+	for _, shift := range call.Shifts {
+		t.step(call, "moved from %s to %s (%s)", shift.From.Format(time.RFC3339), shift.To.Format(time.RFC3339), shift)
+	}
+	if call.DependsOn != nil && call.ScheduledAt.IsZero() {
+		t.step(call, "scheduled %s after '%s' is sent", call.DependsOn.Delay, call.DependsOn.CallID)
+		return
+	}
+	t.step(call, "scheduled at %s", call.ScheduledAt.Format(time.RFC3339))
+}
+
+func (t *tracer) step(call *model.Call, format string, args ...any) {
+	if t == nil {
+		return
+	}
+	o, ok := t.calls[call]
+	if !ok {
+		return
+	}
+	o.trace.Steps = append(o.trace.Steps, Step{Trigger: o.trigger, CallID: call.ID, Message: fmt.Sprintf(format, args...)})
+}
+
+// describeTrigger summarises what fires a trigger, such as "cron 0 9 * * 1-5".
+func describeTrigger(trigger model.Trigger) string {
+	var parts []string
+	if !trigger.ScheduledAt.IsZero() {
+		parts = append(parts, "scheduled_at "+trigger.ScheduledAt.Format(time.RFC3339))
+	}
+	if trigger.Cron != "" {
+		parts = append(parts, "cron "+trigger.Cron)
+	}
+	if trigger.RRule != "" {
+		parts = append(parts, "rrule "+trigger.RRule)
+	}
+	if trigger.BusinessDay != "" {
+		parts = append(parts, "business_day "+trigger.BusinessDay)
+	}
+	if trigger.NthWeekday != "" {
+		parts = append(parts, "nth_weekday "+trigger.NthWeekday)
+	}
+	for _, date := range calendarDates(trigger) {
+		parts = append(parts, date.system.Name()+" "+date.spec)
+	}
+	if trigger.Sequence != "" {
+		parts = append(parts, fmt.Sprintf("sequence %s, delta %s", trigger.Sequence, trigger.Delta))
+	}
+	if trigger.After != "" {
+		parts = append(parts, "after "+trigger.After)
+	}
+	if len(parts) == 0 {
+		return "nothing"
+	}
+	return strings.Join(parts, ", ")
+}
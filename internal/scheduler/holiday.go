@@ -0,0 +1,65 @@
+package scheduler
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/andrewhowdencom/ruf/internal/calendar"
+	"github.com/andrewhowdencom/ruf/internal/model"
+)
+
+// loadHolidays loads the holidays for the window being expanded. Calls may be moved forward to the
+// next business day, so the window is widened to include the following year. If the holidays
+// cannot be loaded, calls are scheduled as if there were none.
+func (s *Scheduler) loadHolidays(from, to time.Time) *calendar.Calendar {
+	if len(s.holidays) == 0 {
+		return nil
+	}
+	cal, err := calendar.Load(context.Background(), from, to.AddDate(1, 0, 0), s.holidays...)
+	if err != nil {
+		slog.Error("failed to load holidays", "error", err)
+		return nil
+	}
+	return cal
+}
+
+// applyHolidays skips or moves the calls that fall on a holiday, according to the trigger's
+// holiday policy. The calls are filtered in place.
+func applyHolidays(calls []*model.Call, trigger model.Trigger, loc *time.Location, cal *calendar.Calendar, tr *tracer) []*model.Call {
+	policy := trigger.IfHoliday
+	if policy == "" && trigger.SkipHolidays {
+		policy = model.IfHolidaySkip
+	}
+	if policy == "" {
+		return calls
+	}
+
+	kept := calls[:0]
+	for _, call := range calls {
+		local := call.ScheduledAt.In(loc)
+		name, ok := cal.Holiday(local)
+		if !ok {
+			kept = append(kept, call)
+			continue
+		}
+
+		switch policy {
+		case model.IfHolidaySkip:
+			slog.Debug("skipping occurrence on holiday", "call_id", call.ID, "scheduled_at", call.ScheduledAt, "holiday", name)
+			tr.skip(call, "holiday %s", name)
+			continue
+		case model.IfHolidayNextBusinessDay:
+			moved, err := cal.NextBusinessDay(local)
+			if err != nil {
+				slog.Error("failed to find next business day", "error", err, "call_id", call.ID)
+				tr.skip(call, "failed to find next business day: %s", err)
+				continue
+			}
+			slog.Debug("moving occurrence off holiday", "call_id", call.ID, "scheduled_at", call.ScheduledAt, "holiday", name, "moved_to", moved)
+			moveCall(call, moved, model.ShiftHoliday, name)
+		}
+		kept = append(kept, call)
+	}
+	return kept
+}
@@ -0,0 +1,83 @@
+package scheduler
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/andrewhowdencom/ruf/internal/model"
+	"github.com/andrewhowdencom/ruf/internal/sourcer"
+)
+
+// ErrInconsistentWindow is returned when the window triggers are expanded in and how late the worker
+// still sends a call disagree.
+var ErrInconsistentWindow = errors.New("scheduling window is inconsistent")
+
+// CheckWindow checks the window triggers are expanded in, before and after now, against the lookback
+// within which the worker still sends a call. A lookback shorter than the window before now expands
+// calls that can only ever be recorded as missed, so it is an error. A longer lookback is allowed, but
+// calls missed for longer than before are never expanded, so it cannot reach them.
+func CheckWindow(before, after, lookback time.Duration) error {
+	for _, d := range []struct {
+		name  string
+		value time.Duration
+	}{{"before", before}, {"after", after}, {"lookback", lookback}} {
+		if d.value < 0 {
+			return fmt.Errorf("%w: %s is negative: %s", ErrInconsistentWindow, d.name, d.value)
+		}
+	}
+	if lookback < before {
+		return fmt.Errorf("%w: calls are expanded from %s before now, but are missed after %s; set scheduling.horizon to keep them in step", ErrInconsistentWindow, before, lookback)
+	}
+	if lookback > before {
+		slog.Warn("calls missed for longer than the window before now are not expanded, so are not sent", "before", before, "lookback", lookback)
+	}
+	return nil
+}
+
+// horizon returns how far before and after now the triggers of a call are expanded. Each bound is
+// taken from the horizon of the call, then the horizon of its campaign, then the given window.
+func horizon(call model.Call, before, after time.Duration) (time.Duration, time.Duration) {
+	for _, h := range []*model.Horizon{call.Campaign.Horizon, call.Horizon} {
+		if h == nil {
+			continue
+		}
+		before = horizonBound(call, "before", h.Before, before)
+		after = horizonBound(call, "after", h.After, after)
+	}
+	return before, after
+}
+
+// HorizonAfter returns how far after now the triggers of a call are expanded: the horizon of the call,
+// then of its campaign, or else the given window.
+func HorizonAfter(call model.Call, after time.Duration) time.Duration {
+	_, after = horizon(call, 0, after)
+	return after
+}
+
+// horizonBound parses a bound of a horizon, keeping the fallback if it is empty or invalid.
+func horizonBound(call model.Call, name, value string, fallback time.Duration) time.Duration {
+	if value == "" {
+		return fallback
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d < 0 {
+		slog.Error("ignoring invalid horizon", "call_id", call.ID, "bound", name, "value", value, "error", err)
+		return fallback
+	}
+	return d
+}
+
+// widestHorizon returns the widest window any call of the sources is expanded in, so that what is
+// loaded for the window, such as holidays, covers every call.
+func widestHorizon(sources []*sourcer.Source, before, after time.Duration) (time.Duration, time.Duration) {
+	widestBefore, widestAfter := before, after
+	for _, source := range sources {
+		for _, call := range source.Calls {
+			b, a := horizon(call, before, after)
+			widestBefore, widestAfter = max(widestBefore, b), max(widestAfter, a)
+		}
+	}
+	return widestBefore, widestAfter
+}
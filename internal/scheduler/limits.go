@@ -0,0 +1,95 @@
+package scheduler
+
+import (
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/andrewhowdencom/ruf/internal/model"
+)
+
+// applyLimits defers the calls that would be sent in the quiet hours of a destination, or that would
+// exceed its daily cap, to the next time they are allowed. Calls are considered in the order they are
+// scheduled, so that the overflow of a day is the calls scheduled last. IDs are kept, so that a
+// deferred call is still recognised as sent.
+func (s *Scheduler) applyLimits(calls []*model.Call, tr *tracer) []*model.Call {
+	if s.limits == nil {
+		return calls
+	}
+
+	order := make([]int, len(calls))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return calls[order[a]].ScheduledAt.Before(calls[order[b]].ScheduledAt)
+	})
+
+	counts := make(map[string]int)
+	count := func(destType, to string) func(time.Time) int {
+		return func(day time.Time) int {
+			return counts[destType+"/"+to+"/"+day.UTC().Format(time.RFC3339)]
+		}
+	}
+
+	dropped := make(map[int]bool)
+	for _, i := range order {
+		call := calls[i]
+		dest := call.Destinations[0]
+		at, ok := s.allowedAt(call.ScheduledAt, dest, count)
+		if !ok {
+			slog.Warn("dropping call that its destination limits never allow", "call_id", call.ID)
+			tr.skip(call, "the limits of %s %s never allow it", dest.Type, strings.Join(dest.To, ", "))
+			dropped[i] = true
+			continue
+		}
+		if !at.Equal(call.ScheduledAt) {
+			slog.Info("deferring call to respect destination limits", "call_id", call.ID, "scheduled_at", call.ScheduledAt, "deferred_to", at)
+			moveCall(call, at, model.ShiftLimits, fmt.Sprintf("%s %s", dest.Type, strings.Join(dest.To, ", ")))
+		}
+		for _, to := range dest.To {
+			if rule, ok := s.limits.For(dest.Type, to); ok {
+				counts[dest.Type+"/"+to+"/"+rule.Day(at).UTC().Format(time.RFC3339)]++
+			}
+		}
+	}
+
+	if len(dropped) == 0 {
+		return calls
+	}
+	kept := calls[:0]
+	for i, call := range calls {
+		if !dropped[i] {
+			kept = append(kept, call)
+		}
+	}
+	return kept
+}
+
+// allowedAt returns the earliest time at or after t that the limits of every recipient of the
+// destination allow. Recipients whose limits never agree, such as quiet hours that together cover the
+// day, allow no time at all.
+func (s *Scheduler) allowedAt(t time.Time, dest model.Destination, count func(destType, to string) func(time.Time) int) (time.Time, bool) {
+	for round := 0; round < 100; round++ {
+		changed := false
+		for _, to := range dest.To {
+			rule, ok := s.limits.For(dest.Type, to)
+			if !ok {
+				continue
+			}
+			next, ok := rule.Next(t, count(dest.Type, to))
+			if !ok {
+				return time.Time{}, false
+			}
+			if !next.Equal(t) {
+				t, changed = next, true
+			}
+		}
+		if !changed {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,71 @@
+package scheduler
+
+import (
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/andrewhowdencom/ruf/internal/model"
+)
+
+var errNoTimezones = errors.New("no recipient timezones are configured")
+
+// splitLocalTime returns a destination for every recipient of the destinations sent at a local time,
+// as each recipient may be in a different timezone. Other destinations are kept as they are.
+func splitLocalTime(destinations []model.Destination) []model.Destination {
+	var split []model.Destination
+	for _, d := range destinations {
+		if d.LocalTime == "" || len(d.To) < 2 {
+			split = append(split, d)
+			continue
+		}
+		for _, to := range d.To {
+			recipient := d
+			recipient.To = []string{to}
+			split = append(split, recipient)
+		}
+	}
+	return split
+}
+
+// localize moves the calls of a destination sent at a local time to that time of day in the timezone
+// of its recipient, on the day each call fires in the timezone of the trigger. A recipient whose
+// timezone cannot be resolved is sent the call at that time of day in the timezone of the trigger.
+func (s *Scheduler) localize(calls []*model.Call, destination model.Destination, triggerLoc *time.Location, tr *tracer) {
+	if destination.LocalTime == "" || len(calls) == 0 {
+		return
+	}
+	clock, err := parseTimeOfDay(destination.LocalTime)
+	if err != nil {
+		slog.Error("failed to parse local time", "error", err, "local_time", destination.LocalTime)
+		return
+	}
+
+	loc, err := s.recipientLocation(destination)
+	if err != nil {
+		slog.Warn("failed to resolve the timezone of the recipient, using the timezone of the trigger", "error", err, "destination", destination.To[0], "timezone", triggerLoc)
+		for _, call := range calls {
+			tr.step(call, "could not resolve the timezone of %s, using %s: %s", destination.To[0], triggerLoc, err)
+		}
+		loc = triggerLoc
+	}
+
+	for _, call := range calls {
+		if call.ScheduledAt.IsZero() {
+			// Calls that wait for another call are not due on any day yet.
+			continue
+		}
+		// The time is built from its clock, so that it is not shifted on the days DST starts or ends.
+		year, month, day := call.ScheduledAt.In(triggerLoc).Date()
+		local := time.Date(year, month, day, int(clock/time.Hour), int(clock%time.Hour/time.Minute), int(clock%time.Minute/time.Second), 0, loc)
+		moveCall(call, local, model.ShiftLocalTime, destination.LocalTime+" "+loc.String())
+	}
+}
+
+// recipientLocation resolves the timezone of the recipient of a destination.
+func (s *Scheduler) recipientLocation(destination model.Destination) (*time.Location, error) {
+	if s.timezones == nil {
+		return nil, errNoTimezones
+	}
+	return s.timezones.Timezone(destination.Type, destination.To[0])
+}
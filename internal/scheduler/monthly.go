@@ -0,0 +1,72 @@
+package scheduler
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/andrewhowdencom/ruf/internal/calendar"
+	"github.com/andrewhowdencom/ruf/internal/model"
+)
+
+// monthlyOccurrences returns the occurrences of a business_day or nth_weekday trigger between from
+// and to, inclusive. The day is found in loc and the trigger's time of day is applied to it, so an
+// occurrence without a time is at midnight.
+func monthlyOccurrences(trigger model.Trigger, loc *time.Location, cal *calendar.Calendar, from, to time.Time) ([]time.Time, error) {
+	day, err := monthlyDayFunc(trigger, cal)
+	if err != nil {
+		return nil, err
+	}
+	clock, err := parseTimeOfDay(trigger.Time)
+	if err != nil {
+		return nil, err
+	}
+
+	var occurrences []time.Time
+	start := from.In(loc)
+	for month := time.Date(start.Year(), start.Month(), 1, 0, 0, 0, 0, loc); !month.After(to); month = month.AddDate(0, 1, 0) {
+		d, ok := day(month.Year(), month.Month(), loc)
+		if !ok {
+			continue
+		}
+		occurrence := time.Date(d.Year(), d.Month(), d.Day(), 0, 0, 0, 0, loc).Add(clock)
+		if occurrence.Before(from) || occurrence.After(to) {
+			continue
+		}
+		occurrences = append(occurrences, occurrence)
+	}
+	return occurrences, nil
+}
+
+// monthlyDayFunc returns the function picking the day of each month a trigger fires on.
+func monthlyDayFunc(trigger model.Trigger, cal *calendar.Calendar) (func(int, time.Month, *time.Location) (time.Time, bool), error) {
+	switch {
+	case trigger.BusinessDay == model.BusinessDayFirst:
+		return cal.FirstBusinessDay, nil
+	case trigger.BusinessDay == model.BusinessDayLast:
+		return cal.LastBusinessDay, nil
+	case trigger.BusinessDay != "":
+		return nil, fmt.Errorf("invalid business_day '%s': must be %s or %s", trigger.BusinessDay, model.BusinessDayFirst, model.BusinessDayLast)
+	}
+
+	n, weekday, err := calendar.ParseNthWeekday(trigger.NthWeekday)
+	if err != nil {
+		return nil, err
+	}
+	return func(year int, month time.Month, loc *time.Location) (time.Time, bool) {
+		return calendar.NthWeekday(year, month, n, weekday, loc)
+	}, nil
+}
+
+// parseTimeOfDay parses a "15:04" or "15:04:05" time into the duration since midnight.
+func parseTimeOfDay(s string) (time.Duration, error) {
+	if s == "" {
+		return 0, nil
+	}
+	t, err := time.Parse("15:04:05", s)
+	if err != nil {
+		if t, err = time.Parse("15:04", s); err != nil {
+			return 0, fmt.Errorf("invalid time '%s': must be HH:MM or HH:MM:SS", s)
+		}
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute + time.Duration(t.Second())*time.Second, nil
+}
@@ -0,0 +1,67 @@
+package scheduler
+
+import (
+	"hash/fnv"
+	"log/slog"
+	"time"
+
+	"github.com/andrewhowdencom/ruf/internal/model"
+)
+
+// splitRecipients returns a destination for every recipient of the destinations, so that copies
+// of a call spread over a window can each be sent at their own time.
+func splitRecipients(destinations []model.Destination) []model.Destination {
+	var split []model.Destination
+	for _, d := range destinations {
+		for _, to := range d.To {
+			recipient := d
+			recipient.To = []string{to}
+			split = append(split, recipient)
+		}
+	}
+	return split
+}
+
+// applyOffsets delays the calls of a trigger by its jitter and spread. The calls are the copies
+// for the index'th of count recipients. The jitter is derived from the ID of each call, so a call
+// keeps its time, and its place in the datastore, when the schedule is refreshed.
+func applyOffsets(calls []*model.Call, trigger model.Trigger, index, count int) {
+	jitter, err := parseOffset(trigger.Jitter)
+	if err != nil {
+		slog.Error("failed to parse jitter", "error", err, "jitter", trigger.Jitter)
+	}
+	spread, err := parseOffset(trigger.Spread)
+	if err != nil {
+		slog.Error("failed to parse spread", "error", err, "spread", trigger.Spread)
+	}
+	if jitter <= 0 && spread <= 0 {
+		return
+	}
+
+	var stagger time.Duration
+	if spread > 0 && count > 1 {
+		stagger = spread * time.Duration(index) / time.Duration(count)
+	}
+	for _, call := range calls {
+		moveCall(call, call.ScheduledAt.Add(stagger), model.ShiftSpread, trigger.Spread)
+		moveCall(call, call.ScheduledAt.Add(jitterFor(call.ID, jitter)), model.ShiftJitter, trigger.Jitter)
+	}
+}
+
+// jitterFor returns a pseudo-random offset in [0, jitter) derived from the ID, in whole seconds.
+func jitterFor(id string, jitter time.Duration) time.Duration {
+	seconds := uint64(jitter / time.Second)
+	if seconds == 0 {
+		return 0
+	}
+	h := fnv.New64a()
+	h.Write([]byte(id))
+	return time.Duration(h.Sum64()%seconds) * time.Second
+}
+
+func parseOffset(s string) (time.Duration, error) {
+	if s == "" {
+		return 0, nil
+	}
+	return time.ParseDuration(s)
+}
@@ -0,0 +1,42 @@
+package scheduler
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/andrewhowdencom/ruf/internal/kv"
+	"github.com/andrewhowdencom/ruf/internal/model"
+)
+
+// loadOverrides loads the trigger overrides, keyed by kv.TriggerOverride.Key. If they cannot be
+// loaded, the error is logged and every trigger is expanded.
+func (s *Scheduler) loadOverrides() map[string]*kv.TriggerOverride {
+	list, err := s.storer.ListTriggerOverrides()
+	if err != nil {
+		slog.Error("failed to load trigger overrides", "error", err)
+		return nil
+	}
+	overrides := make(map[string]*kv.TriggerOverride, len(list))
+	for _, o := range list {
+		overrides[o.Key()] = o
+	}
+	return overrides
+}
+
+// filterPaused removes the calls scheduled before the trigger is resumed by its override. The
+// calls are filtered in place.
+func filterPaused(calls []*model.Call, override *kv.TriggerOverride, tr *tracer) []*model.Call {
+	if override == nil {
+		return calls
+	}
+	kept := calls[:0]
+	for _, call := range calls {
+		if call.ScheduledAt.Before(override.Until) {
+			slog.Debug("skipping paused occurrence", "call_id", call.ID, "scheduled_at", call.ScheduledAt, "until", override.Until)
+			tr.skip(call, "the trigger is paused until %s", override.Until.Format(time.RFC3339))
+			continue
+		}
+		kept = append(kept, call)
+	}
+	return kept
+}
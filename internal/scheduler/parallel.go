@@ -0,0 +1,89 @@
+package scheduler
+
+import (
+	"sync"
+	"time"
+
+	"github.com/andrewhowdencom/ruf/internal/calendar"
+	"github.com/andrewhowdencom/ruf/internal/kv"
+	"github.com/andrewhowdencom/ruf/internal/model"
+)
+
+// expansion is what the call definitions of a single expansion share. It is read by every call
+// definition expanded in parallel; sent guards itself.
+type expansion struct {
+	now           time.Time
+	before, after time.Duration
+	holidays      *calendar.Calendar
+	overrides     map[string]*kv.TriggerOverride
+	series        map[string]*kv.SeriesOverride
+	sent          *sentCalls
+	tr            *tracer
+}
+
+// definition is a call definition, and the events of the source it was read from.
+type definition struct {
+	call   model.Call
+	events map[string][]model.Event
+}
+
+// expanded is what a call definition was expanded into.
+type expanded struct {
+	calls   []*model.Call
+	pending []*model.Call
+}
+
+// slotTurns makes the call definitions expanded in parallel reserve slots in the order they appear in
+// the sources, as they would if they were expanded one at a time, so that which call gets a contended
+// slot does not depend on which was expanded first.
+type slotTurns struct {
+	mu       sync.Mutex
+	cond     *sync.Cond
+	finished []bool
+	// frontier is the number of definitions, from the first, that have all been expanded.
+	frontier int
+}
+
+func newSlotTurns(n int) *slotTurns {
+	t := &slotTurns{finished: make([]bool, n)}
+	t.cond = sync.NewCond(&t.mu)
+	return t
+}
+
+// wait blocks until every definition before the i'th has been expanded.
+func (t *slotTurns) wait(i int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for t.frontier < i {
+		t.cond.Wait()
+	}
+}
+
+// finish marks the i'th definition as expanded.
+func (t *slotTurns) finish(i int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.finished[i] = true
+	for t.frontier < len(t.finished) && t.finished[t.frontier] {
+		t.frontier++
+	}
+	t.cond.Broadcast()
+}
+
+// slotTurn is the access of a single call definition to the staged slots. The definition waits for
+// its turn the first time it needs a slot, so definitions that need none are never held up.
+type slotTurn struct {
+	slots *stagedSlots
+	turns *slotTurns
+	index int
+	taken bool
+}
+
+// take waits for the turn of the definition, and returns the staged slots.
+func (t *slotTurn) take() *stagedSlots {
+	if !t.taken {
+		t.turns.wait(t.index)
+		t.taken = true
+	}
+	return t.slots
+}
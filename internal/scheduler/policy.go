@@ -0,0 +1,25 @@
+package scheduler
+
+import (
+	"log/slog"
+
+	"github.com/andrewhowdencom/ruf/internal/model"
+)
+
+// filterViolations removes the calls that violate a schedule policy, reporting each violation. The
+// calls are filtered in place.
+func (s *Scheduler) filterViolations(calls []*model.Call, tr *tracer) []*model.Call {
+	if s.policy == nil {
+		return calls
+	}
+	kept := calls[:0]
+	for _, call := range calls {
+		if err := s.policy.Schedule(call); err != nil {
+			slog.Warn("skipping call that violates policy", "call_id", call.ID, "error", err)
+			tr.skip(call, "violates policy: %s", err)
+			continue
+		}
+		kept = append(kept, call)
+	}
+	return kept
+}
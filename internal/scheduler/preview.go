@@ -0,0 +1,96 @@
+package scheduler
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/andrewhowdencom/ruf/internal/model"
+	"github.com/andrewhowdencom/ruf/internal/sourcer"
+)
+
+// ErrInvalidTrigger is returned when a trigger cannot be previewed.
+var ErrInvalidTrigger = errors.New("invalid trigger")
+
+const (
+	// previewWindow is the first window a trigger is previewed over. It doubles each time nothing
+	// fires in it.
+	previewWindow = 7 * 24 * time.Hour
+	// previewHorizon is how far ahead a trigger is previewed before giving up on finding more.
+	previewHorizon = 10 * 365 * 24 * time.Hour
+)
+
+// previewCallID is the ID of the call definition a trigger is previewed in.
+const previewCallID = "preview"
+
+// Occurrence is a time a trigger fires.
+type Occurrence struct {
+	// At is when the call is sent.
+	At time.Time
+	// Fired is when the trigger fired, before the call was moved by Shifts.
+	Fired  time.Time
+	Shifts []model.Shift
+}
+
+// Preview returns the first n occurrences of a trigger from the given time, as they would be
+// scheduled: moved into slots, skipped or moved on holidays, and skipped on excluded dates. A
+// sequence trigger fires for the given events. Policies and limits are not applied, and nothing is
+// stored. Fewer than n occurrences are returned if the trigger stops firing.
+func (s *Scheduler) Preview(trigger model.Trigger, n int, from time.Time, events ...model.Event) ([]Occurrence, error) {
+	if trigger.After != "" {
+		return nil, fmt.Errorf("%w: a trigger that follows another call only fires once that call is sent", ErrInvalidTrigger)
+	}
+
+	preview := *s
+	preview.policy, preview.limits, preview.concurrency = nil, nil, 1
+	sources := []*sourcer.Source{{
+		Calls: []model.Call{{
+			ID:           previewCallID,
+			Triggers:     []model.Trigger{trigger},
+			Destinations: []model.Destination{{Type: previewCallID, To: []string{previewCallID}}},
+		}},
+		Events: events,
+	}}
+	// Slots are reserved afresh, so the preview does not depend on what other calls hold.
+	slots := newStagedSlots(nil)
+
+	from = from.UTC()
+	seen := make(map[string]bool)
+	var occurrences []Occurrence
+	for cursor, window := from, previewWindow; len(occurrences) < n && cursor.Before(from.Add(previewHorizon)); {
+		tr := newTracer()
+		calls := preview.expand(sources, cursor, 0, window, slots, tr)
+		if len(tr.failures) > 0 {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidTrigger, strings.Join(tr.failures, "; "))
+		}
+
+		next, found := cursor.Add(window), false
+		for _, call := range calls {
+			if seen[call.ID] || call.OccurredAt().Before(from) {
+				continue
+			}
+			seen[call.ID] = true
+			found = true
+			occurrences = append(occurrences, Occurrence{At: call.ScheduledAt, Fired: call.OccurredAt(), Shifts: call.Shifts})
+			// Triggers on dates in other calendars fire once, however far ahead, so the next window
+			// starts after the last occurrence.
+			if fired := call.OccurredAt().Add(time.Second); fired.After(next) {
+				next = fired
+			}
+		}
+		if !found {
+			window *= 2
+		}
+		cursor = next
+	}
+
+	sort.SliceStable(occurrences, func(i, j int) bool {
+		return occurrences[i].Fired.Before(occurrences[j].Fired)
+	})
+	if len(occurrences) > n {
+		occurrences = occurrences[:n]
+	}
+	return occurrences, nil
+}
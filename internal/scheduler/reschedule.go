@@ -0,0 +1,113 @@
+package scheduler
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/andrewhowdencom/ruf/internal/kv"
+	"github.com/andrewhowdencom/ruf/internal/model"
+)
+
+// ErrNoSlots is returned when a call is moved into the next slot, but no slots are configured for its
+// destination.
+var ErrNoSlots = errors.New("no slots are configured for the destination of the call")
+
+// loadReschedules loads the moves of scheduled calls, by the ID of the call. If they cannot be loaded,
+// the error is logged and every call is scheduled when it is expanded to.
+func (s *Scheduler) loadReschedules() map[string]*kv.Reschedule {
+	list, err := s.storer.ListReschedules()
+	if err != nil {
+		slog.Error("failed to load reschedules", "error", err)
+		return nil
+	}
+	reschedules := make(map[string]*kv.Reschedule, len(list))
+	for _, r := range list {
+		reschedules[r.CallID] = r
+	}
+	return reschedules
+}
+
+// applyReschedules moves the calls that have been moved by hand to the time they were moved to. A call
+// moved into a slot holds it, and a call moved anywhere else gives up the slot it held.
+func applyReschedules(calls []*model.Call, reschedules map[string]*kv.Reschedule, slots *stagedSlots) {
+	if len(reschedules) == 0 {
+		return
+	}
+	for _, call := range calls {
+		r := reschedules[call.ID]
+		if r == nil || call.ScheduledAt.IsZero() {
+			continue
+		}
+		if !r.Slot {
+			slots.free(call.ID)
+		} else if !slots.ReserveSlot(r.To, call.ID) {
+			slog.Warn("slot of moved call is held by another call", "call_id", call.ID, "slot", r.To)
+		}
+		moveCall(call, r.To, model.ShiftMoved, movedBy(r))
+	}
+}
+
+// movedBy describes who moved a call, for its shift.
+func movedBy(r *kv.Reschedule) string {
+	if r.By == "" {
+		return ""
+	}
+	return "by " + r.By
+}
+
+// Move moves a scheduled call to another time, or into the next free slot after the time it is
+// scheduled at if to is zero, and returns the call as it is then scheduled. The move is kept, so that
+// refreshing the schedule moves the call again rather than putting it back.
+func (s *Scheduler) Move(callID string, to time.Time, by string, now time.Time) (*kv.ScheduledCall, error) {
+	calls, err := s.storer.ListScheduledCalls()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list scheduled calls: %w", err)
+	}
+	var call *kv.ScheduledCall
+	for _, c := range calls {
+		if c.Call.ID == callID {
+			call = c
+			break
+		}
+	}
+	switch {
+	case call == nil:
+		return nil, fmt.Errorf("%w: scheduled call '%s'", kv.ErrNotFound, callID)
+	case call.ScheduledAt.IsZero():
+		return nil, fmt.Errorf("call '%s' waits for another call to be sent, and cannot be moved", callID)
+	}
+
+	slots := s.previousSlots()
+	slots.free(call.Call.ID)
+	r := &kv.Reschedule{CallID: call.Call.ID, To: to.UTC(), By: by, At: now.UTC()}
+	if to.IsZero() {
+		turn := &slotTurn{slots: slots, turns: newSlotTurns(1)}
+		from := call.ScheduledAt.Add(time.Second)
+		if from.Before(now) {
+			from = now
+		}
+		slot, err := s.findNextAvailableSlot(turn, &call.Call, call.Destinations[0], call.ScheduledAt, from)
+		if err != nil {
+			return nil, err
+		}
+		if slot.Equal(call.ScheduledAt) {
+			// Without slots, the call is left where it is.
+			return nil, fmt.Errorf("%w: %s", ErrNoSlots, callID)
+		}
+		r.To, r.Slot = slot.UTC(), true
+	}
+
+	if err := s.storer.SetReschedule(r); err != nil {
+		return nil, fmt.Errorf("failed to record the move of '%s': %w", callID, err)
+	}
+	// The time of the call itself is not stored with it.
+	call.Call.ScheduledAt = call.ScheduledAt
+	moveCall(&call.Call, r.To, model.ShiftMoved, movedBy(r))
+	call.ScheduledAt = call.Call.ScheduledAt
+	if err := s.storer.ReplaceSchedule(calls, slots.reserved); err != nil {
+		return nil, fmt.Errorf("failed to replace schedule: %w", err)
+	}
+	return call, nil
+}
@@ -0,0 +1,72 @@
+package scheduler_test
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/andrewhowdencom/ruf/internal/kv/bbolt"
+	"github.com/andrewhowdencom/ruf/internal/model"
+	"github.com/andrewhowdencom/ruf/internal/scheduler"
+	"github.com/andrewhowdencom/ruf/internal/sourcer"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchedulerMove(t *testing.T) {
+	store, err := bbolt.NewTestStore(filepath.Join(t.TempDir(), "test.db"))
+	require.NoError(t, err)
+	defer store.Close()
+
+	viper.Set("slots.timezone", "UTC")
+	viper.Set("slots.default", map[string][]string{
+		"sunday": {"10:00", "16:00"},
+	})
+	defer viper.Set("slots.default", nil)
+
+	now := time.Date(2023, 1, 1, 8, 0, 0, 0, time.UTC) // A Sunday
+	sources := []*sourcer.Source{{
+		Calls: []model.Call{{
+			ID:           "call-1",
+			Triggers:     []model.Trigger{{ScheduledAt: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)}},
+			Destinations: []model.Destination{{Type: "email", To: []string{"test@example.com"}}},
+		}},
+	}}
+	id := "call-1:scheduled_at:2023-01-01T00:00:00Z:email:test@example.com"
+	s := scheduler.New(store)
+	require.NoError(t, s.RefreshSchedule(sources, now, time.Hour, 24*time.Hour))
+
+	// Into the next slot.
+	moved, err := s.Move(id, time.Time{}, "jane", now)
+	require.NoError(t, err)
+	at := time.Date(2023, 1, 1, 16, 0, 0, 0, time.UTC)
+	assert.Equal(t, at, moved.ScheduledAt)
+	assert.Equal(t, model.Shift{Reason: model.ShiftMoved, Detail: "by jane", From: time.Date(2023, 1, 1, 10, 0, 0, 0, time.UTC), To: at}, moved.Shifts[len(moved.Shifts)-1])
+	slots, err := store.ListSlots()
+	require.NoError(t, err)
+	assert.Equal(t, map[time.Time]string{at: id}, slots)
+
+	// Refreshing the schedule keeps the move.
+	require.NoError(t, s.RefreshSchedule(sources, now, time.Hour, 24*time.Hour))
+	call, err := store.GetScheduledCall(id)
+	require.NoError(t, err)
+	assert.Equal(t, at, call.ScheduledAt)
+
+	// The next free slot is the following week.
+	moved, err = s.Move(id, time.Time{}, "jane", now)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2023, 1, 8, 10, 0, 0, 0, time.UTC), moved.ScheduledAt)
+
+	// Out of the slots, giving up the one it held.
+	at = time.Date(2023, 1, 1, 12, 30, 0, 0, time.UTC)
+	_, err = s.Move(id, at, "jane", now)
+	require.NoError(t, err)
+	require.NoError(t, s.RefreshSchedule(sources, now, time.Hour, 24*time.Hour))
+	call, err = store.GetScheduledCall(id)
+	require.NoError(t, err)
+	assert.Equal(t, at, call.ScheduledAt)
+	slots, err = store.ListSlots()
+	require.NoError(t, err)
+	assert.Empty(t, slots)
+}
@@ -3,14 +3,18 @@ package scheduler
 import (
 	"fmt"
 	"log/slog"
-	"strconv"
+	"runtime"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/andrewhowdencom/ruf/internal/calendar"
 	"github.com/andrewhowdencom/ruf/internal/kv"
+	"github.com/andrewhowdencom/ruf/internal/limits"
 	"github.com/andrewhowdencom/ruf/internal/model"
+	"github.com/andrewhowdencom/ruf/internal/policy"
 	"github.com/andrewhowdencom/ruf/internal/sourcer"
-	"github.com/hablullah/go-hijri"
+	"github.com/andrewhowdencom/ruf/internal/timezone"
 	"github.com/robfig/cron/v3"
 	"github.com/spf13/viper"
 	"github.com/teambition/rrule-go"
@@ -18,56 +22,207 @@ import (
 
 // Scheduler is responsible for expanding call definitions into a flat list of concrete, scheduled calls.
 type Scheduler struct {
-	storer kv.Storer
+	storer   kv.Storer
+	holidays []calendar.Provider
+	policy   *policy.Engine
+	limits   *limits.Limits
+	// timezones resolves the timezones of recipients sent calls at their local time.
+	timezones timezone.Resolver
+	// concurrency is the number of call definitions expanded at once.
+	concurrency int
+}
+
+// Option configures the Scheduler.
+type Option func(*Scheduler)
+
+// WithHolidays sets the providers consulted for triggers that skip or move calls on holidays.
+func WithHolidays(providers ...calendar.Provider) Option {
+	return func(s *Scheduler) {
+		s.holidays = append(s.holidays, providers...)
+	}
+}
+
+// WithPolicy drops the expanded calls that violate the schedule hooks of the policy engine.
+func WithPolicy(engine *policy.Engine) Option {
+	return func(s *Scheduler) {
+		s.policy = engine
+	}
+}
+
+// WithLimits defers the expanded calls that would be sent in the quiet hours of their destination, or
+// that would exceed its daily cap.
+func WithLimits(l *limits.Limits) Option {
+	return func(s *Scheduler) {
+		s.limits = l
+	}
+}
+
+// WithTimezones resolves the timezones of the recipients of destinations that are sent calls at a
+// local time.
+func WithTimezones(r timezone.Resolver) Option {
+	return func(s *Scheduler) {
+		s.timezones = r
+	}
+}
+
+// WithConcurrency sets the number of call definitions expanded at once. It defaults to the number of
+// CPUs that can run Go code at once.
+func WithConcurrency(n int) Option {
+	return func(s *Scheduler) {
+		if n > 0 {
+			s.concurrency = n
+		}
+	}
 }
 
 // New creates a new scheduler.
-func New(storer kv.Storer) *Scheduler {
-	return &Scheduler{
-		storer: storer,
+func New(storer kv.Storer, opts ...Option) *Scheduler {
+	s := &Scheduler{
+		storer:      storer,
+		concurrency: runtime.GOMAXPROCS(0),
 	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
 }
 
-// RefreshSchedule expands the call definitions and stores them in the datastore.
-func (s *Scheduler) RefreshSchedule(sources []*sourcer.Source, now time.Time, before, after time.Duration) error {
-	slog.Debug("clearing all scheduled calls")
-	if err := s.storer.ClearScheduledCalls(); err != nil {
-		return fmt.Errorf("failed to clear scheduled calls: %w", err)
+// stagedSlots holds slot reservations in memory, keyed by UTC time, so that
+// they can be written together with the schedule they belong to.
+//
+// It starts from the reservations of the previous schedule, so that an expanded
+// call keeps the slot it was given rather than every call being assigned a slot
+// afresh: adding a call does not move the calls that were already announced.
+type stagedSlots struct {
+	mu       sync.Mutex
+	reserved map[time.Time]string
+	held     map[string]time.Time
+	claimed  map[string]bool
+}
+
+func newStagedSlots(previous map[time.Time]string) *stagedSlots {
+	s := &stagedSlots{
+		reserved: make(map[time.Time]string, len(previous)),
+		held:     make(map[string]time.Time, len(previous)),
+		claimed:  make(map[string]bool),
+	}
+	for slot, callID := range previous {
+		s.reserved[slot.UTC()] = callID
+		s.held[callID] = slot.UTC()
+	}
+	return s
+}
+
+// Held returns the slot the call holds, if any.
+func (s *stagedSlots) Held(callID string) (time.Time, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	slot, ok := s.held[callID]
+	return slot, ok
+}
+
+// ReserveSlot reserves the slot for the call if no other call holds it. A call
+// holds a single slot, so a slot it held before is released.
+func (s *stagedSlots) ReserveSlot(slot time.Time, callID string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	slot = slot.UTC()
+	if holder, ok := s.reserved[slot]; ok && holder != callID {
+		return false
+	}
+	if previous, ok := s.held[callID]; ok && !previous.Equal(slot) {
+		delete(s.reserved, previous)
+	}
+	s.reserved[slot] = callID
+	s.held[callID] = slot
+	s.claimed[callID] = true
+	return true
+}
+
+// free drops the reservation of the slot the call holds, if any.
+func (s *stagedSlots) free(callID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if slot, ok := s.held[callID]; ok {
+		delete(s.reserved, slot)
+		delete(s.held, callID)
+	}
+}
+
+// release drops the reservations of the calls that did not reserve a slot
+// since the reservations were staged, such as calls that have been removed.
+func (s *stagedSlots) release() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for slot, callID := range s.reserved {
+		if !s.claimed[callID] {
+			delete(s.reserved, slot)
+			delete(s.held, callID)
+		}
+	}
+}
+
+// previousSlots stages the slot reservations of the current schedule.
+func (s *Scheduler) previousSlots() *stagedSlots {
+	previous, err := s.storer.ListSlots()
+	if err != nil {
+		slog.Error("failed to list slots, assigning every slot afresh", "error", err)
 	}
-	slog.Debug("successfully cleared all scheduled calls")
+	return newStagedSlots(previous)
+}
 
+// RefreshSchedule expands the call definitions and stores them in the datastore.
+// The previous schedule and its slot reservations are replaced in a single
+// write, so a failure part way through leaves the previous schedule intact.
+func (s *Scheduler) RefreshSchedule(sources []*sourcer.Source, now time.Time, before, after time.Duration) error {
 	slog.Debug("expanding call definitions into scheduled calls")
-	expandedCalls := s.Expand(sources, now, before, after)
+	slots := s.previousSlots()
+	expandedCalls := s.expand(sources, now, before, after, slots, nil)
+	slots.release()
 	slog.Debug("call expansion complete", "count", len(expandedCalls))
 
-	slog.Debug("adding expanded calls to the datastore")
+	slog.Debug("replacing the schedule in the datastore")
+	scheduledCalls := make([]*kv.ScheduledCall, 0, len(expandedCalls))
 	for _, call := range expandedCalls {
-		scheduledCall := &kv.ScheduledCall{
+		scheduledCalls = append(scheduledCalls, &kv.ScheduledCall{
 			Call:        *call,
 			ScheduledAt: call.ScheduledAt,
-		}
-		if err := s.storer.AddScheduledCall(scheduledCall); err != nil {
-			slog.Error("failed to add scheduled call", "error", err, "call_id", call.ID)
-		} else {
-			slog.Debug("added scheduled call", "call_id", call.ID, "scheduled_at", call.ScheduledAt)
-		}
+		})
+	}
+	if err := s.storer.ReplaceSchedule(scheduledCalls, slots.reserved); err != nil {
+		return fmt.Errorf("failed to replace schedule: %w", err)
 	}
-	slog.Debug("finished adding expanded calls to the datastore")
+	slog.Debug("finished replacing the schedule in the datastore")
 
 	return nil
 }
 
 // Expand takes a list of sources and expands the call definitions within them
-// into a flat list of concrete, scheduled calls based on their triggers.
+// into a flat list of concrete, scheduled calls based on their triggers. The
+// slots of the current schedule are respected, but no reservation is stored.
 func (s *Scheduler) Expand(sources []*sourcer.Source, now time.Time, before, after time.Duration) []*model.Call {
-	if err := s.storer.ClearAllSlots(); err != nil {
-		slog.Error("failed to clear all slots", "error", err)
-		return nil
-	}
+	return s.expand(sources, now, before, after, s.previousSlots(), nil)
+}
 
+// expand expands the call definitions, reserving slots in the given staged slots. Call definitions
+// are expanded in parallel, by up to the concurrency of the scheduler at once, and their calls are
+// returned in the order the definitions appear in the sources.
+func (s *Scheduler) expand(sources []*sourcer.Source, now time.Time, before, after time.Duration, slots *stagedSlots, tr *tracer) []*model.Call {
 	now = now.UTC() // Ensure 'now' is in UTC for consistent calculations.
-	var expandedCalls []*model.Call
+	widestBefore, widestAfter := widestHorizon(sources, before, after)
+	x := &expansion{
+		now:       now,
+		before:    before,
+		after:     after,
+		holidays:  s.loadHolidays(now.Add(-widestBefore), now.Add(widestAfter)),
+		overrides: s.loadOverrides(),
+		series:    s.loadSeriesOverrides(),
+		sent:      newSentCalls(s.storer),
+		tr:        tr,
+	}
+	reschedules := s.loadReschedules()
 
+	var defs []definition
 	for i, source := range sources {
 		slog.Debug("processing source", "index", i, "calls", len(source.Calls), "events", len(source.Events))
 		// Build an event map for the current source to allow for efficient lookups.
@@ -75,289 +230,446 @@ func (s *Scheduler) Expand(sources []*sourcer.Source, now time.Time, before, aft
 		for _, event := range source.Events {
 			eventsBySequence[event.Sequence] = append(eventsBySequence[event.Sequence], event)
 		}
-
 		for _, callDef := range source.Calls {
-			slog.Debug("processing call definition", "call_id", callDef.ID)
-			for _, trigger := range callDef.Triggers {
-				for _, destination := range callDef.Destinations {
-					// Handle direct schedule triggers
-					if !trigger.ScheduledAt.IsZero() {
-						slog.Debug("processing 'scheduled_at' trigger", "call_id", callDef.ID, "scheduled_at", trigger.ScheduledAt)
-						newCall := createCallFromDefinition(callDef)
-						newCall.ScheduledAt = trigger.ScheduledAt
-						newCall.ID = fmt.Sprintf("%s:scheduled_at:%s:%s:%s", callDef.ID, trigger.ScheduledAt.Format(time.RFC3339), destination.Type, destination.To[0])
-						if newCall.ScheduledAt.Hour() == 0 && newCall.ScheduledAt.Minute() == 0 && newCall.ScheduledAt.Second() == 0 {
-							slot, err := s.findNextAvailableSlot(newCall, destination, newCall.ScheduledAt, now)
-							if err != nil {
-								slog.Error("failed to find next available slot", "error", err, "call_id", newCall.ID)
-								continue
-							}
-							newCall.ScheduledAt = slot
-						}
-						newCall.Destinations = []model.Destination{destination}
-						expandedCalls = append(expandedCalls, newCall)
-					}
+			defs = append(defs, definition{call: callDef, events: eventsBySequence})
+		}
+	}
 
-					// Handle cron triggers
-					if trigger.Cron != "" {
-						slog.Debug("processing 'cron' trigger", "call_id", callDef.ID, "cron", trigger.Cron)
-						parser := cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow)
-						schedule, err := parser.Parse(trigger.Cron)
-						if err != nil {
-							slog.Error("failed to parse cron", "error", err, "cron", trigger.Cron)
-							continue
-						}
+	workers := s.concurrency
+	if tr != nil {
+		// Traces are recorded in the order the definitions are expanded.
+		workers = 1
+	}
+	results := make([]expanded, len(defs))
+	turns := newSlotTurns(len(defs))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for range min(workers, len(defs)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				turn := &slotTurn{slots: slots, turns: turns, index: i}
+				results[i].calls, results[i].pending = s.expandCall(defs[i].call, defs[i].events, x, turn)
+				turns.finish(i)
+			}
+		}()
+	}
+	// Definitions are handed out in order, so one waiting for its turn to reserve slots only ever
+	// waits for definitions that are already being expanded.
+	for i := range defs {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
 
-						// Calculate occurrences within the window [now - before, now + after]
-						startTime := now.Add(-before)
-						endTime := now.Add(after)
-
-						// Start checking from the beginning of the window.
-						// We subtract a second to make sure that if the startTime itself is a valid
-						// cron time, it is included.
-						for t := schedule.Next(startTime.Add(-1 * time.Second)); !t.IsZero() && !t.After(endTime); t = schedule.Next(t) {
-							effectiveScheduledAt := t.Truncate(time.Minute)
-
-							newCall := createCallFromDefinition(callDef)
-							newCall.ScheduledAt = effectiveScheduledAt
-							if newCall.ScheduledAt.Hour() == 0 && newCall.ScheduledAt.Minute() == 0 && newCall.ScheduledAt.Second() == 0 {
-								slot, err := s.findNextAvailableSlot(newCall, destination, newCall.ScheduledAt, now)
-								if err != nil {
-									slog.Error("failed to find next available slot", "error", err, "call_id", newCall.ID)
-									continue
-								}
-								newCall.ScheduledAt = slot
-							}
-							newCall.ID = fmt.Sprintf("%s:cron:%s:%s:%s:%s", callDef.ID, trigger.Cron, newCall.ScheduledAt.Format(time.RFC3339), destination.Type, destination.To[0])
-							newCall.Destinations = []model.Destination{destination}
-							expandedCalls = append(expandedCalls, newCall)
-						}
+	var expandedCalls, pending []*model.Call
+	for _, r := range results {
+		expandedCalls = append(expandedCalls, r.calls...)
+		pending = append(pending, r.pending...)
+	}
+	calls := dedupe(append(s.applyLimits(expandedCalls, tr), pending...), tr)
+	// A call moved by hand is sent when it was moved to, whatever its trigger and limits say.
+	applyReschedules(calls, reschedules, slots)
+	return calls
+}
+
+// expandCall expands a call definition into its scheduled calls, and the calls that wait for another
+// call to be sent.
+func (s *Scheduler) expandCall(callDef model.Call, eventsBySequence map[string][]model.Event, x *expansion, slots *slotTurn) (expandedCalls, pending []*model.Call) {
+	now, holidays, overrides, sent, tr := x.now, x.holidays, x.overrides, x.sent, x.tr
+
+	slog.Debug("processing call definition", "call_id", callDef.ID)
+	if callDef.Series != "" {
+		if reason := applySeries(&callDef, x); reason != "" {
+			slog.Debug("skipping step of series", "call_id", callDef.ID, "series", callDef.Series, "reason", reason)
+			tr.begin(&callDef)
+			tr.note(&callDef, 0, "skipped: %s", reason)
+			return nil, nil
+		}
+	}
+	tr.begin(&callDef)
+	// The window is shadowed by the horizon of the call for the rest of its expansion.
+	before, after := horizon(callDef, x.before, x.after)
+	for index, trigger := range callDef.Triggers {
+		if !trigger.IsEnabled() {
+			slog.Debug("skipping disabled trigger", "call_id", callDef.ID, "index", index)
+			tr.note(&callDef, index, "skipped: the trigger is disabled")
+			continue
+		}
+		override := overrides[(&kv.TriggerOverride{CallID: callDef.ID, Index: index}).Key()]
+		if override != nil && override.Until.IsZero() {
+			slog.Debug("skipping paused trigger", "call_id", callDef.ID, "index", index)
+			tr.note(&callDef, index, "skipped: the trigger is paused")
+			continue
+		}
+
+		triggerLoc, err := triggerLocation(trigger)
+		if err != nil {
+			slog.Error("failed to load trigger timezone", "error", err, "call_id", callDef.ID, "timezone", trigger.Timezone)
+			tr.fail(&callDef, index, "failed to load trigger timezone: %s", err)
+			continue
+		}
+
+		// The calls the trigger schedules are identified by the call it came from.
+		callID := callDef.ID
+		if trigger.CallID != "" {
+			callID = trigger.CallID
+		}
+		destinations := destinationsFor(callDef, trigger)
+		if trigger.Spread != "" {
+			destinations = splitRecipients(destinations)
+		}
+		destinations = splitLocalTime(destinations)
+		for d, destination := range destinations {
+			start := len(expandedCalls)
+
+			// Handle direct schedule triggers
+			if !trigger.ScheduledAt.IsZero() {
+				slog.Debug("processing 'scheduled_at' trigger", "call_id", callDef.ID, "scheduled_at", trigger.ScheduledAt)
+				newCall := createCallFromDefinition(callDef)
+				newCall.ScheduledAt = trigger.ScheduledAt
+				if trigger.Timezone != "" {
+					// The wall clock time is read in the timezone of the trigger.
+					at := trigger.ScheduledAt
+					newCall.ScheduledAt = time.Date(at.Year(), at.Month(), at.Day(), at.Hour(), at.Minute(), at.Second(), 0, triggerLoc)
+				}
+				newCall.ID = fmt.Sprintf("%s:scheduled_at:%s:%s:%s", callID, newCall.ScheduledAt.Format(time.RFC3339), destination.Type, destination.To[0])
+				if newCall.ScheduledAt.Hour() == 0 && newCall.ScheduledAt.Minute() == 0 && newCall.ScheduledAt.Second() == 0 {
+					slot, err := s.findNextAvailableSlot(slots, newCall, destination, newCall.ScheduledAt, now)
+					if err != nil {
+						slog.Error("failed to find next available slot", "error", err, "call_id", newCall.ID)
+						tr.fail(&callDef, index, "failed to find next available slot for %s: %s", newCall.ID, err)
+						continue
 					}
+					moveCall(newCall, slot, model.ShiftSlot, "")
+				}
+				newCall.ScheduledAt = newCall.ScheduledAt.UTC()
+				newCall.Destinations = []model.Destination{destination}
+				expandedCalls = append(expandedCalls, newCall)
+			}
 
-					// Handle RRule triggers
-					if trigger.RRule != "" {
-						slog.Debug("processing 'rrule' trigger", "call_id", callDef.ID, "rrule", trigger.RRule, "dstart", trigger.DStart)
-						rOption, err := rrule.StrToROption(trigger.RRule)
-						if err != nil {
-							slog.Error("failed to parse rrule", "error", err, "rrule", trigger.RRule)
-							continue
-						}
+			// Handle cron triggers
+			if trigger.Cron != "" {
+				slog.Debug("processing 'cron' trigger", "call_id", callDef.ID, "cron", trigger.Cron)
+				parser := cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow)
+				schedule, err := parser.Parse(trigger.Cron)
+				if err != nil {
+					slog.Error("failed to parse cron", "error", err, "cron", trigger.Cron)
+					tr.fail(&callDef, index, "failed to parse cron: %s", err)
+					continue
+				}
 
-						if trigger.DStart != "" {
-							loc := time.UTC // Default to UTC
-							dateTimePart := trigger.DStart
-
-							// Check if a timezone is specified
-							if strings.Contains(trigger.DStart, ":") {
-								parts := strings.SplitN(trigger.DStart, ":", 2)
-								if strings.HasPrefix(parts[0], "TZID=") {
-									tzid := strings.TrimPrefix(parts[0], "TZID=")
-									// Attempt to load the location, but fall back to UTC on error
-									if loadedLoc, err := time.LoadLocation(tzid); err == nil {
-										loc = loadedLoc
-									}
-									dateTimePart = parts[1]
-								}
-							}
+				// Calculate occurrences within the window [now - before, now + after]
+				startTime := now.Add(-before)
+				endTime := now.Add(after)
+
+				// Start checking from the beginning of the window, or from dstart if the trigger
+				// starts later or its occurrences are counted.
+				from := startTime
+				if trigger.DStart != "" {
+					dtstart, err := parseDStart(trigger.DStart, triggerLoc)
+					if err != nil {
+						slog.Error("failed to parse dstart as datetime or date", "error", err, "dstart", trigger.DStart)
+						tr.fail(&callDef, index, "failed to parse dstart as datetime or date: %s", err)
+						continue
+					}
+					if trigger.Count > 0 || dtstart.After(from) {
+						from = dtstart
+					}
+				}
 
-							// Try to parse as a full datetime first
-							dtstart, err := time.ParseInLocation("20060102T150405", dateTimePart, loc)
-							if err != nil {
-								// If that fails, try to parse as a date-only string.
-								// This will result in a time of 00:00:00 in the specified location.
-								dtstart, err = time.ParseInLocation("20060102", dateTimePart, loc)
-								if err != nil {
-									slog.Error("failed to parse dstart as datetime or date", "error", err, "dstart", trigger.DStart)
-									continue
-								}
-							}
-							rOption.Dtstart = dtstart.UTC()
-						} else {
-							// If the RRule itself contains a time, use 'now' as the DTStart to ensure
-							// the next occurrence is calculated correctly relative to the current time.
-							if strings.Contains(trigger.RRule, "BYHOUR") || strings.Contains(trigger.RRule, "BYMINUTE") || strings.Contains(trigger.RRule, "BYSECOND") {
-								rOption.Dtstart = now
-							} else {
-								// If no DStart and no time in the RRule, default to midnight UTC of the current day.
-								year, month, day := now.Date()
-								rOption.Dtstart = time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
-							}
+				// We subtract a second to make sure that if the start itself is a valid
+				// cron time, it is included.
+				// Occurrences are calculated in the timezone of the trigger, so they follow its DST transitions.
+				fired := 0
+				for t := schedule.Next(from.In(triggerLoc).Add(-1 * time.Second)); !t.IsZero() && !t.After(endTime); t = schedule.Next(t) {
+					if !trigger.Until.IsZero() && t.After(trigger.Until) {
+						break
+					}
+					if trigger.Count > 0 {
+						if fired == trigger.Count {
+							break
 						}
+						fired++
+					}
+					if t.Before(startTime) {
+						continue
+					}
+					effectiveScheduledAt := t.Truncate(time.Minute)
 
-						rule, err := rrule.NewRRule(*rOption)
+					newCall := createCallFromDefinition(callDef)
+					newCall.ScheduledAt = effectiveScheduledAt.UTC()
+					newCall.ID = fmt.Sprintf("%s:cron:%s:%s:%s:%s", callID, trigger.Cron, newCall.ScheduledAt.Format(time.RFC3339), destination.Type, destination.To[0])
+					if effectiveScheduledAt.Hour() == 0 && effectiveScheduledAt.Minute() == 0 && effectiveScheduledAt.Second() == 0 {
+						slot, err := s.findNextAvailableSlot(slots, newCall, destination, effectiveScheduledAt, now)
 						if err != nil {
-							slog.Error("failed to create rrule", "error", err, "rrule", trigger.RRule)
+							slog.Error("failed to find next available slot", "error", err, "call_id", newCall.ID)
+							tr.fail(&callDef, index, "failed to find next available slot for %s: %s", newCall.ID, err)
 							continue
 						}
+						moveCall(newCall, slot, model.ShiftSlot, "")
+					}
+					newCall.Destinations = []model.Destination{destination}
+					expandedCalls = append(expandedCalls, newCall)
+				}
+			}
 
-						// Use UTC for the 'between' calculation to ensure occurrences are consistent.
-						startTime := now.Add(-before)
-						endTime := now.Add(after)
-						for _, occurrence := range rule.Between(startTime, endTime, true) {
-							newCall := createCallFromDefinition(callDef)
-							newCall.ScheduledAt = occurrence
-							if newCall.ScheduledAt.Hour() == 0 && newCall.ScheduledAt.Minute() == 0 && newCall.ScheduledAt.Second() == 0 {
-								slot, err := s.findNextAvailableSlot(newCall, destination, newCall.ScheduledAt, now)
-								if err != nil {
-									slog.Error("failed to find next available slot", "error", err, "call_id", newCall.ID)
-									continue
-								}
-								newCall.ScheduledAt = slot
-							}
-							newCall.ID = fmt.Sprintf("%s:rrule:%s:%s:%s:%s", callDef.ID, trigger.RRule, occurrence.Format(time.RFC3339), destination.Type, destination.To[0])
-							newCall.Destinations = []model.Destination{destination}
-							expandedCalls = append(expandedCalls, newCall)
-						}
-					} else if trigger.DStart != "" {
-						slog.Error("dstart specified without rrule", "dstart", trigger.DStart)
+			// Handle RRule triggers
+			if trigger.RRule != "" {
+				slog.Debug("processing 'rrule' trigger", "call_id", callDef.ID, "rrule", trigger.RRule, "dstart", trigger.DStart)
+				rOption, err := rrule.StrToROption(trigger.RRule)
+				if err != nil {
+					slog.Error("failed to parse rrule", "error", err, "rrule", trigger.RRule)
+					tr.fail(&callDef, index, "failed to parse rrule: %s", err)
+					continue
+				}
+
+				if trigger.DStart != "" {
+					dtstart, err := parseDStart(trigger.DStart, triggerLoc)
+					if err != nil {
+						slog.Error("failed to parse dstart as datetime or date", "error", err, "dstart", trigger.DStart)
+						tr.fail(&callDef, index, "failed to parse dstart as datetime or date: %s", err)
 						continue
 					}
+					// The start keeps its location so occurrences follow its DST transitions.
+					rOption.Dtstart = dtstart
+				} else {
+					// If the RRule itself contains a time, use 'now' as the DTStart to ensure
+					// the next occurrence is calculated correctly relative to the current time.
+					if strings.Contains(trigger.RRule, "BYHOUR") || strings.Contains(trigger.RRule, "BYMINUTE") || strings.Contains(trigger.RRule, "BYSECOND") {
+						rOption.Dtstart = now.In(triggerLoc)
+					} else {
+						// If no DStart and no time in the RRule, default to midnight of the current day
+						// in the timezone of the trigger.
+						year, month, day := now.In(triggerLoc).Date()
+						rOption.Dtstart = time.Date(year, month, day, 0, 0, 0, 0, triggerLoc)
+					}
+				}
 
-					// Handle Hijri calendar triggers
-					if trigger.Hijri != "" {
-						slog.Debug("processing 'hijri' trigger", "call_id", callDef.ID, "hijri", trigger.Hijri)
-						parts := strings.Split(trigger.Hijri, " ")
-						if len(parts) < 2 {
-							slog.Error("invalid hijri date format, expected 'day month'", "hijri", trigger.Hijri)
-							continue
-						}
-						day, err := strconv.Atoi(parts[0])
+				rule, err := rrule.NewRRule(*rOption)
+				if err != nil {
+					slog.Error("failed to create rrule", "error", err, "rrule", trigger.RRule)
+					tr.fail(&callDef, index, "failed to create rrule: %s", err)
+					continue
+				}
+
+				// Use UTC for the 'between' calculation to ensure occurrences are consistent.
+				startTime := now.Add(-before)
+				endTime := now.Add(after)
+				for _, occurrence := range rule.Between(startTime, endTime, true) {
+					newCall := createCallFromDefinition(callDef)
+					newCall.ScheduledAt = occurrence.UTC()
+					newCall.ID = fmt.Sprintf("%s:rrule:%s:%s:%s:%s", callID, trigger.RRule, occurrence.UTC().Format(time.RFC3339), destination.Type, destination.To[0])
+					if occurrence.Hour() == 0 && occurrence.Minute() == 0 && occurrence.Second() == 0 {
+						slot, err := s.findNextAvailableSlot(slots, newCall, destination, occurrence, now)
 						if err != nil {
-							slog.Error("invalid day in hijri date", "error", err, "hijri", trigger.Hijri)
+							slog.Error("failed to find next available slot", "error", err, "call_id", newCall.ID)
+							tr.fail(&callDef, index, "failed to find next available slot for %s: %s", newCall.ID, err)
 							continue
 						}
+						moveCall(newCall, slot, model.ShiftSlot, "")
+					}
+					newCall.Destinations = []model.Destination{destination}
+					expandedCalls = append(expandedCalls, newCall)
+				}
+			} else if trigger.DStart != "" && trigger.Cron == "" {
+				slog.Error("dstart specified without rrule or cron", "dstart", trigger.DStart)
+				tr.fail(&callDef, index, "dstart specified without rrule or cron")
+				continue
+			}
 
-						monthStr := strings.ToLower(strings.Join(parts[1:], " "))
-						var month int64
-						switch monthStr {
-						case "muharram":
-							month = 1
-						case "safar":
-							month = 2
-						case "rabi' al-awwal", "rabi al-awwal", "rabi'ul-awwal", "rabi'ul awwal":
-							month = 3
-						case "rabi' al-thani", "rabi al-thani", "rabi'ul-athir", "rabi'ul athir":
-							month = 4
-						case "jumada al-ula", "jumada al-awwal":
-							month = 5
-						case "jumada al-thani", "jumada al-akhirah":
-							month = 6
-						case "rajab":
-							month = 7
-						case "sha'ban", "shaban":
-							month = 8
-						case "ramadan":
-							month = 9
-						case "shawwal":
-							month = 10
-						case "dhu al-qi'dah", "dhu al-qid'ah":
-							month = 11
-						case "dhu al-hijjah":
-							month = 12
-						default:
-							slog.Error("invalid month in hijri date", "month", monthStr)
-							continue
-						}
+			// Handle triggers on dates in other calendar systems, such as hijri or hebrew
+			for _, date := range calendarDates(trigger) {
+				slog.Debug("processing calendar trigger", "call_id", callDef.ID, "calendar", date.system.Name(), "date", date.spec)
+				scheduledAt, err := calendarOccurrence(date, trigger.Time, triggerLoc, now)
+				if err != nil {
+					slog.Error("failed to expand calendar trigger", "error", err, "call_id", callDef.ID, "calendar", date.system.Name())
+					tr.fail(&callDef, index, "failed to expand calendar trigger: %s", err)
+					continue
+				}
 
-						// We need to find the Gregorian year that corresponds to the Hijri year for the given date.
-						// We'll start with the current Gregorian year and check if the date has already passed.
-						// If it has, we'll check the next Gregorian year.
-						var gregorianDate time.Time
-						for i := 0; i < 2; i++ {
-							currentHijriYear, _ := hijri.CreateHijriDate(now.AddDate(i, 0, 0), hijri.Default)
-							hDate := hijri.HijriDate{Year: currentHijriYear.Year, Month: month, Day: int64(day)}
-							gDate := hDate.ToGregorian()
-							if gDate.After(now) {
-								gregorianDate = gDate
-								break
-							}
-						}
+				newCall := createCallFromDefinition(callDef)
+				newCall.ScheduledAt = scheduledAt
+				newCall.ID = fmt.Sprintf("%s:%s:%s:%s:%s:%s", callID, date.system.Name(), date.spec, scheduledAt.Format(time.RFC3339), destination.Type, destination.To[0])
 
-						if gregorianDate.IsZero() {
-							slog.Error("could not find a future gregorian date for the given hijri date", "hijri", trigger.Hijri)
-							continue
-						}
+				if newCall.ScheduledAt.Hour() == 0 && newCall.ScheduledAt.Minute() == 0 && newCall.ScheduledAt.Second() == 0 {
+					slot, err := s.findNextAvailableSlot(slots, newCall, destination, newCall.ScheduledAt, now)
+					if err != nil {
+						slog.Error("failed to find next available slot", "error", err, "call_id", newCall.ID)
+						tr.fail(&callDef, index, "failed to find next available slot for %s: %s", newCall.ID, err)
+						continue
+					}
+					moveCall(newCall, slot, model.ShiftSlot, "")
+				}
 
-						scheduledAt := gregorianDate
-						loc := time.UTC // Default to UTC for time parsing
-						timeStr := trigger.Time
-						if trigger.Time != "" {
-							// Check for timezone offset
-							if strings.Contains(trigger.Time, "Z") || strings.Contains(trigger.Time, "+") || strings.Contains(trigger.Time, "-") {
-								// Parse with timezone
-								parsedTime, err := time.Parse(time.RFC3339, fmt.Sprintf("2006-01-02T%s", trigger.Time))
-								if err == nil {
-									loc = parsedTime.Location()
-									timeStr = parsedTime.Format("15:04:05")
-								} else {
-									slog.Error("failed to parse time with timezone", "error", err, "time", trigger.Time)
-									continue
-								}
-							}
+				newCall.Destinations = []model.Destination{destination}
+				expandedCalls = append(expandedCalls, newCall)
+			}
 
-							// Parse the time part
-							t, err := time.Parse("15:04:05", timeStr)
-							if err != nil {
-								t, err = time.Parse("15:04", timeStr)
-								if err != nil {
-									slog.Error("failed to parse time", "error", err, "time", timeStr)
-									continue
-								}
-							}
-							scheduledAt = time.Date(gregorianDate.Year(), gregorianDate.Month(), gregorianDate.Day(), t.Hour(), t.Minute(), t.Second(), 0, loc)
-						} else {
-							// Default to midnight UTC
-							scheduledAt = time.Date(gregorianDate.Year(), gregorianDate.Month(), gregorianDate.Day(), 0, 0, 0, 0, time.UTC)
+			// Handle business day and nth weekday triggers
+			if trigger.BusinessDay != "" || trigger.NthWeekday != "" {
+				slog.Debug("processing monthly trigger", "call_id", callDef.ID, "business_day", trigger.BusinessDay, "nth_weekday", trigger.NthWeekday)
+				occurrences, err := monthlyOccurrences(trigger, triggerLoc, holidays, now.Add(-before), now.Add(after))
+				if err != nil {
+					slog.Error("failed to expand monthly trigger", "error", err, "call_id", callDef.ID)
+					tr.fail(&callDef, index, "failed to expand monthly trigger: %s", err)
+					continue
+				}
+				kind, rule := "business_day", trigger.BusinessDay
+				if trigger.NthWeekday != "" {
+					kind, rule = "nth_weekday", trigger.NthWeekday
+				}
+
+				for _, occurrence := range occurrences {
+					newCall := createCallFromDefinition(callDef)
+					newCall.ScheduledAt = occurrence.UTC()
+					newCall.ID = fmt.Sprintf("%s:%s:%s:%s:%s:%s", callID, kind, rule, occurrence.UTC().Format(time.RFC3339), destination.Type, destination.To[0])
+					if occurrence.Hour() == 0 && occurrence.Minute() == 0 && occurrence.Second() == 0 {
+						slot, err := s.findNextAvailableSlot(slots, newCall, destination, occurrence, now)
+						if err != nil {
+							slog.Error("failed to find next available slot", "error", err, "call_id", newCall.ID)
+							tr.fail(&callDef, index, "failed to find next available slot for %s: %s", newCall.ID, err)
+							continue
 						}
+						moveCall(newCall, slot, model.ShiftSlot, "")
+					}
+					newCall.Destinations = []model.Destination{destination}
+					expandedCalls = append(expandedCalls, newCall)
+				}
+			}
 
-						newCall := createCallFromDefinition(callDef)
-						newCall.ScheduledAt = scheduledAt
-						newCall.ID = fmt.Sprintf("%s:hijri:%s:%s:%s:%s", callDef.ID, trigger.Hijri, scheduledAt.Format(time.RFC3339), destination.Type, destination.To[0])
+			// Handle event sequence triggers
+			if trigger.Sequence != "" && trigger.Delta != "" {
+				slog.Debug("processing 'sequence' trigger", "call_id", callDef.ID, "sequence", trigger.Sequence, "delta", trigger.Delta)
+				if matchingEvents, ok := eventsBySequence[trigger.Sequence]; ok {
+					for _, event := range matchingEvents {
+						slog.Debug("found matching event for sequence", "call_id", callDef.ID, "event_sequence", event.Sequence, "event_start_time", event.StartTime)
+						delta, err := time.ParseDuration(trigger.Delta)
+						if err != nil {
+							slog.Error("failed to parse delta", "error", err, "delta", trigger.Delta)
+							tr.fail(&callDef, index, "failed to parse delta: %s", err)
+							continue
+						}
 
-						if newCall.ScheduledAt.Hour() == 0 && newCall.ScheduledAt.Minute() == 0 && newCall.ScheduledAt.Second() == 0 {
-							slot, err := s.findNextAvailableSlot(newCall, destination, newCall.ScheduledAt, now)
+						// The delta is measured from the start of the event unless the trigger asks for its end.
+						kind, anchor := "sequence", event.StartTime
+						if trigger.DeltaFrom == model.DeltaFromEnd {
+							end, err := event.End()
 							if err != nil {
-								slog.Error("failed to find next available slot", "error", err, "call_id", newCall.ID)
+								slog.Error("failed to find the end of the event", "error", err, "call_id", callDef.ID)
+								tr.fail(&callDef, index, "failed to find the end of the event: %s", err)
 								continue
 							}
-							newCall.ScheduledAt = slot
+							kind, anchor = "sequence_end", end
 						}
 
+						newCall := createCallFromDefinition(callDef)
+						newCall.ScheduledAt = anchor.Add(delta)
+						newCall.Destinations = append(newCall.Destinations, event.Destinations...)
+						newCall.ID = fmt.Sprintf("%s:%s:%s:%s:%s:%s", callID, kind, trigger.Sequence, event.StartTime.Format(time.RFC3339), destination.Type, destination.To[0])
 						newCall.Destinations = []model.Destination{destination}
 						expandedCalls = append(expandedCalls, newCall)
 					}
+				}
+			}
 
-					// Handle event sequence triggers
-					if trigger.Sequence != "" && trigger.Delta != "" {
-						slog.Debug("processing 'sequence' trigger", "call_id", callDef.ID, "sequence", trigger.Sequence, "delta", trigger.Delta)
-						if matchingEvents, ok := eventsBySequence[trigger.Sequence]; ok {
-							for _, event := range matchingEvents {
-								slog.Debug("found matching event for sequence", "call_id", callDef.ID, "event_sequence", event.Sequence, "event_start_time", event.StartTime)
-								delta, err := time.ParseDuration(trigger.Delta)
-								if err != nil {
-									slog.Error("failed to parse delta", "error", err, "delta", trigger.Delta)
-									continue
-								}
-
-								newCall := createCallFromDefinition(callDef)
-								newCall.ScheduledAt = event.StartTime.Add(delta)
-								newCall.Destinations = append(newCall.Destinations, event.Destinations...)
-								newCall.ID = fmt.Sprintf("%s:sequence:%s:%s:%s:%s", callDef.ID, trigger.Sequence, event.StartTime.Format(time.RFC3339), destination.Type, destination.To[0])
-								newCall.Destinations = []model.Destination{destination}
-								expandedCalls = append(expandedCalls, newCall)
-							}
-						}
+			// Handle triggers that follow another call of the campaign. Until that call has been
+			// sent, the call waits in the schedule for the worker to resolve the time it is due.
+			if trigger.After != "" {
+				slog.Debug("processing 'after' trigger", "call_id", callDef.ID, "after", trigger.After, "delta", trigger.Delta)
+				var delta time.Duration
+				if trigger.Delta != "" {
+					delta, err = time.ParseDuration(trigger.Delta)
+					if err != nil {
+						slog.Error("failed to parse delta", "error", err, "delta", trigger.Delta)
+						tr.fail(&callDef, index, "failed to parse delta: %s", err)
+						continue
 					}
 				}
+
+				newCall := createCallFromDefinition(callDef)
+				newCall.ID = fmt.Sprintf("%s:after:%s:%s:%s", callID, trigger.After, destination.Type, destination.To[0])
+				newCall.Destinations = []model.Destination{destination}
+				newCall.DependsOn = &model.Dependency{CallID: trigger.After, Delay: delta}
+				if sentAt, ok := sent.sentAt(callDef.Campaign.ID, trigger.After); ok {
+					newCall.ScheduledAt = sentAt.Add(delta).UTC()
+					expandedCalls = append(expandedCalls, newCall)
+				} else {
+					tr.fired(&callDef, index, []*model.Call{newCall})
+					pending = append(pending, s.filterViolations([]*model.Call{newCall}, tr)...)
+				}
+			}
+
+			tr.fired(&callDef, index, expandedCalls[start:])
+			if len(expandedCalls) == start && trigger.After == "" {
+				tr.note(&callDef, index, "nothing fired for %s %s between %s and %s", destination.Type, destination.To[0], now.Add(-before).Format(time.RFC3339), now.Add(after).Format(time.RFC3339))
+			}
+			s.localize(expandedCalls[start:], destination, triggerLoc, tr)
+			applyOffsets(expandedCalls[start:], trigger, d, len(destinations))
+
+			// Drop the occurrences of this trigger that fall on an excluded date or in a blackout.
+			kept := applyHolidays(filterExcluded(expandedCalls[start:], trigger, triggerLoc, tr), trigger, triggerLoc, holidays, tr)
+			expandedCalls = append(expandedCalls[:start], s.filterViolations(filterPaused(kept, override, tr), tr)...)
+		}
+	}
+	return expandedCalls, pending
+}
+
+// dedupe drops the calls with the ID of an earlier call. Calls are stored by ID, so a duplicate would
+// otherwise silently replace the earlier call. Every occurrence of a trigger has its own ID, so only
+// triggers that fire the same occurrence twice, such as the same cron listed twice, are deduplicated.
+func dedupe(calls []*model.Call, tr *tracer) []*model.Call {
+	seen := make(map[string]bool, len(calls))
+	kept := calls[:0]
+	for _, call := range calls {
+		if seen[call.ID] {
+			slog.Warn("skipping call with the same ID as another call", "call_id", call.ID)
+			tr.skip(call, "duplicate of another occurrence with the same ID")
+			continue
+		}
+		seen[call.ID] = true
+		kept = append(kept, call)
+	}
+	return kept
+}
+
+// parseDStart parses the start of a recurrence, such as "TZID=Europe/Berlin:20250106T090000" or
+// "20250106". A start without a timezone is read in the given location.
+func parseDStart(dstart string, loc *time.Location) (time.Time, error) {
+	dateTimePart := dstart
+
+	// Check if a timezone is specified
+	if strings.Contains(dstart, ":") {
+		parts := strings.SplitN(dstart, ":", 2)
+		if strings.HasPrefix(parts[0], "TZID=") {
+			tzid := strings.TrimPrefix(parts[0], "TZID=")
+			// Attempt to load the location, but fall back to the given one on error
+			if loadedLoc, err := time.LoadLocation(tzid); err == nil {
+				loc = loadedLoc
 			}
+			dateTimePart = parts[1]
 		}
 	}
-	return expandedCalls
+
+	// Try to parse as a full datetime first
+	dtstart, err := time.ParseInLocation("20060102T150405", dateTimePart, loc)
+	if err != nil {
+		// If that fails, try to parse as a date-only string.
+		// This will result in a time of 00:00:00 in the specified location.
+		return time.ParseInLocation("20060102", dateTimePart, loc)
+	}
+	return dtstart, nil
 }
 
 // createCallFromDefinition creates a new call instance from a call definition,
 // ensuring that mutable fields like Destinations are deep-copied.
-func (s *Scheduler) findNextAvailableSlot(call *model.Call, destination model.Destination, scheduledAt time.Time, now time.Time) (time.Time, error) {
+func (s *Scheduler) findNextAvailableSlot(turn *slotTurn, call *model.Call, destination model.Destination, scheduledAt time.Time, now time.Time) (time.Time, error) {
+	if destination.LocalTime != "" {
+		// The call is sent at the local time of its recipient instead.
+		return scheduledAt, nil
+	}
+	slots := turn.take()
 	slog.Debug("finding next available slot", "call_id", call.ID, "destination", destination.To[0], "scheduled_at", scheduledAt)
 	loc, err := time.LoadLocation(viper.GetString("slots.timezone"))
 	if err != nil {
@@ -374,6 +686,14 @@ func (s *Scheduler) findNextAvailableSlot(call *model.Call, destination model.De
 		fmt.Sprintf("slots.%s.default", destination.Type),
 		"slots.default",
 	}
+	// A call with a profile of slots is placed in the slots of the profile alone.
+	if call.Slots != "" {
+		key := "slots.profiles." + call.Slots
+		if !viper.IsSet(key) {
+			return time.Time{}, fmt.Errorf("unknown slots profile '%s': configure it under slots.profiles", call.Slots)
+		}
+		keys = []string{key}
+	}
 	var slotsByDay map[string][]string
 	for _, key := range keys {
 		if viper.IsSet(key) {
@@ -388,37 +708,28 @@ func (s *Scheduler) findNextAvailableSlot(call *model.Call, destination model.De
 		return scheduledAt, nil
 	}
 
+	// The call keeps the slot it was given by the previous schedule, as long as the slot is still
+	// configured, even once it has passed.
+	if held, ok := slots.Held(call.ID); ok {
+		for _, slotTime := range slotTimes(slotsByDay, held.In(loc), loc) {
+			if slotTime.Equal(held) && slots.ReserveSlot(slotTime, call.ID) {
+				slog.Debug("kept slot", "slot", slotTime, "call_id", call.ID)
+				return slotTime, nil
+			}
+		}
+	}
+
 	// Start searching from the scheduled day
 	for i := 0; i < 365; i++ { // Limit to 1 year of searching
-		currentDay := scheduledAt.AddDate(0, 0, i)
-		dayOfWeek := strings.ToLower(currentDay.Weekday().String())
-
-		if slots, ok := slotsByDay[dayOfWeek]; ok {
-			for _, slot := range slots {
-				parts := strings.Split(slot, ":")
-				if len(parts) != 2 {
-					slog.Warn("invalid slot format", "slot", slot)
-					continue
-				}
-				hour, _ := time.ParseDuration(parts[0] + "h")
-				minute, _ := time.ParseDuration(parts[1] + "m")
-				slotTime := time.Date(currentDay.Year(), currentDay.Month(), currentDay.Day(), 0, 0, 0, 0, loc).Add(hour).Add(minute)
-
-				// Only consider slots in the future
-				if slotTime.Before(now) {
-					continue
-				}
+		for _, slotTime := range slotTimes(slotsByDay, scheduledAt.AddDate(0, 0, i), loc) {
+			// Only consider slots in the future
+			if slotTime.Before(now) {
+				continue
+			}
 
-				// The key for the reservation should be unique for the destination.
-				key := fmt.Sprintf("%s:%s", destination.Type, destination.To[0])
-				reserved, err := s.storer.ReserveSlot(slotTime, key)
-				if err != nil {
-					return time.Time{}, fmt.Errorf("failed to reserve slot: %w", err)
-				}
-				if reserved {
-					slog.Debug("reserved slot", "slot", slotTime, "key", key)
-					return slotTime, nil
-				}
+			if slots.ReserveSlot(slotTime, call.ID) {
+				slog.Debug("reserved slot", "slot", slotTime, "call_id", call.ID)
+				return slotTime, nil
 			}
 		}
 	}
@@ -426,6 +737,48 @@ func (s *Scheduler) findNextAvailableSlot(call *model.Call, destination model.De
 	return time.Time{}, fmt.Errorf("no available slots found for call %s, destination %s", call.ID, destination.To[0])
 }
 
+// slotTimes returns the configured slots on the day, in the location of the slots.
+func slotTimes(slotsByDay map[string][]string, day time.Time, loc *time.Location) []time.Time {
+	var times []time.Time
+	for _, slot := range slotsByDay[strings.ToLower(day.Weekday().String())] {
+		parts := strings.Split(slot, ":")
+		if len(parts) != 2 {
+			slog.Warn("invalid slot format", "slot", slot)
+			continue
+		}
+		hour, _ := time.ParseDuration(parts[0] + "h")
+		minute, _ := time.ParseDuration(parts[1] + "m")
+		times = append(times, time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, loc).Add(hour).Add(minute))
+	}
+	return times
+}
+
+// moveCall moves the call to another time, recording the time it was at and why it was moved.
+func moveCall(call *model.Call, to time.Time, reason, detail string) {
+	if to.Equal(call.ScheduledAt) {
+		return
+	}
+	call.Shifts = append(call.Shifts, model.Shift{Reason: reason, Detail: detail, From: call.ScheduledAt.UTC(), To: to.UTC()})
+	call.ScheduledAt = to.UTC()
+}
+
+// triggerLocation returns the location the trigger is evaluated in, defaulting to UTC.
+func triggerLocation(trigger model.Trigger) (*time.Location, error) {
+	if trigger.Timezone == "" {
+		return time.UTC, nil
+	}
+	return time.LoadLocation(trigger.Timezone)
+}
+
+// destinationsFor returns the destinations a trigger sends to. Destinations set on the trigger
+// take precedence over those set on the call.
+func destinationsFor(call model.Call, trigger model.Trigger) []model.Destination {
+	if len(trigger.Destinations) > 0 {
+		return trigger.Destinations
+	}
+	return call.Destinations
+}
+
 func createCallFromDefinition(def model.Call) *model.Call {
 	slog.Debug("creating new call from definition", "call_id", def.ID)
 	newCall := def // Start with a shallow copy
@@ -61,8 +61,54 @@ func TestSchedulerExpandWithSlots(t *testing.T) {
 	// Test email destination (should use default slots)
 	assert.Equal(t, "call-1:scheduled_at:2023-01-01T00:00:00Z:email:test@example.com", expandedCalls[0].ID)
 	assert.Equal(t, time.Date(2023, 1, 1, 10, 0, 0, 0, time.UTC), expandedCalls[0].ScheduledAt)
+	assert.Equal(t, []model.Shift{{
+		Reason: model.ShiftSlot,
+		From:   time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC),
+		To:     time.Date(2023, 1, 1, 10, 0, 0, 0, time.UTC),
+	}}, expandedCalls[0].Shifts)
 
 	// Test slack destination (should use slack default slots)
 	assert.Equal(t, "call-1:scheduled_at:2023-01-01T00:00:00Z:slack:#general", expandedCalls[1].ID)
 	assert.Equal(t, time.Date(2023, 1, 1, 11, 0, 0, 0, time.UTC), expandedCalls[1].ScheduledAt)
 }
+
+func TestSchedulerExpandWithSlotsProfile(t *testing.T) {
+	dbPath := "test.db"
+	defer os.Remove(dbPath)
+
+	store, err := bbolt.NewTestStore(dbPath)
+	assert.NoError(t, err)
+	defer store.Close()
+
+	s := scheduler.New(store)
+
+	viper.Set("slots.timezone", "UTC")
+	viper.Set("slots.default", map[string][]string{"sunday": {"10:00"}})
+	viper.Set("slots.profiles.announcements", map[string][]string{"sunday": {"15:00"}})
+	defer viper.Set("slots.profiles", nil)
+
+	now := time.Date(2023, 1, 1, 8, 0, 0, 0, time.UTC) // A Sunday
+	call := func(id, slots string) model.Call {
+		return model.Call{
+			ID:           id,
+			Slots:        slots,
+			Triggers:     []model.Trigger{{ScheduledAt: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)}},
+			Destinations: []model.Destination{{Type: "email", To: []string{"test@example.com"}}},
+		}
+	}
+	sources := []*sourcer.Source{{Calls: []model.Call{
+		call("call-1", "announcements"),
+		call("call-2", ""),
+		call("call-3", "unknown"),
+	}}}
+
+	expandedCalls := s.Expand(sources, now, 1*time.Hour, 24*time.Hour)
+	sort.Slice(expandedCalls, func(i, j int) bool {
+		return expandedCalls[i].ID < expandedCalls[j].ID
+	})
+	// A call with an unknown profile is not scheduled.
+	if assert.Len(t, expandedCalls, 2) {
+		assert.Equal(t, time.Date(2023, 1, 1, 15, 0, 0, 0, time.UTC), expandedCalls[0].ScheduledAt)
+		assert.Equal(t, time.Date(2023, 1, 1, 10, 0, 0, 0, time.UTC), expandedCalls[1].ScheduledAt)
+	}
+}
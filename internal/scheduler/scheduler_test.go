@@ -1,14 +1,20 @@
 package scheduler_test
 
 import (
+	"fmt"
 	"sort"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/spf13/viper"
+	"github.com/andrewhowdencom/ruf/internal/calendar"
+	"github.com/andrewhowdencom/ruf/internal/kv"
+	"github.com/andrewhowdencom/ruf/internal/limits"
 	"github.com/andrewhowdencom/ruf/internal/model"
 	"github.com/andrewhowdencom/ruf/internal/scheduler"
 	"github.com/andrewhowdencom/ruf/internal/sourcer"
+	"github.com/andrewhowdencom/ruf/internal/timezone"
 	"github.com/stretchr/testify/assert"
 	"github.com/andrewhowdencom/ruf/internal/kv/bbolt"
 	"os"
@@ -178,3 +184,979 @@ func TestSchedulerExpand_Hijri(t *testing.T) {
 	assert.Len(t, expandedCalls[2].Destinations, 1)
 	assert.Equal(t, "slack", expandedCalls[2].Destinations[0].Type)
 }
+
+func TestSchedulerExpand_CalendarSystems(t *testing.T) {
+	dbPath := "test_calendar_systems.db"
+	defer os.Remove(dbPath)
+
+	store, err := bbolt.NewTestStore(dbPath)
+	assert.NoError(t, err)
+
+	s := scheduler.New(store)
+
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	sources := []*sourcer.Source{
+		{
+			Calls: []model.Call{
+				{
+					ID: "call-calendars",
+					Triggers: []model.Trigger{
+						{Hebrew: "1 tishrei", Time: "09:00"},
+						{Chinese: "15 8", Time: "09:00", Timezone: "Asia/Shanghai"},
+						{SolarHijri: "1 farvardin", Time: "09:00:00+03:30"},
+					},
+					Destinations: []model.Destination{
+						{Type: "slack", To: []string{"#general"}},
+					},
+				},
+			},
+		},
+	}
+
+	expandedCalls := s.Expand(sources, now, 1*time.Hour, 365*24*time.Hour)
+	assert.Len(t, expandedCalls, 3)
+
+	scheduled := make(map[string]time.Time)
+	for _, call := range expandedCalls {
+		scheduled[strings.SplitN(call.ID, ":", 3)[1]] = call.ScheduledAt.UTC()
+	}
+	assert.Equal(t, time.Date(2025, 9, 23, 9, 0, 0, 0, time.UTC), scheduled["hebrew"])
+	assert.Equal(t, time.Date(2025, 10, 6, 1, 0, 0, 0, time.UTC), scheduled["chinese"])
+	assert.Equal(t, time.Date(2025, 3, 21, 5, 30, 0, 0, time.UTC), scheduled["solar_hijri"])
+}
+
+func TestSchedulerExpand_TriggerDestinations(t *testing.T) {
+	dbPath := "test.db"
+	defer os.Remove(dbPath)
+
+	store, err := bbolt.NewTestStore(dbPath)
+	assert.NoError(t, err)
+	defer store.Close()
+
+	s := scheduler.New(store)
+	viper.Set("slots.default", nil)
+
+	now := time.Date(2023, 1, 1, 8, 0, 0, 0, time.UTC)
+	sources := []*sourcer.Source{
+		{
+			Calls: []model.Call{
+				{
+					ID: "call-1",
+					Destinations: []model.Destination{
+						{Type: "slack", To: []string{"#general"}},
+					},
+					Triggers: []model.Trigger{
+						{ScheduledAt: now.Add(1 * time.Hour)},
+						{
+							ScheduledAt: now.Add(2 * time.Hour),
+							Destinations: []model.Destination{
+								{Type: "email", To: []string{"team@example.com"}},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	expandedCalls := s.Expand(sources, now, 1*time.Hour, 24*time.Hour)
+	assert.Len(t, expandedCalls, 2)
+
+	assert.Equal(t, "call-1:scheduled_at:2023-01-01T09:00:00Z:slack:#general", expandedCalls[0].ID)
+	assert.Equal(t, []model.Destination{{Type: "slack", To: []string{"#general"}}}, expandedCalls[0].Destinations)
+	assert.Equal(t, "call-1:scheduled_at:2023-01-01T10:00:00Z:email:team@example.com", expandedCalls[1].ID)
+	assert.Equal(t, []model.Destination{{Type: "email", To: []string{"team@example.com"}}}, expandedCalls[1].Destinations)
+}
+
+func TestSchedulerExpand_Timezone(t *testing.T) {
+	dbPath := "test.db"
+	defer os.Remove(dbPath)
+
+	store, err := bbolt.NewTestStore(dbPath)
+	assert.NoError(t, err)
+	defer store.Close()
+
+	s := scheduler.New(store)
+	viper.Set("slots.default", nil)
+
+	// Europe/Berlin moves from CET (UTC+1) to CEST (UTC+2) on 2025-03-30.
+	now := time.Date(2025, 3, 20, 0, 0, 0, 0, time.UTC)
+	sources := []*sourcer.Source{
+		{
+			Calls: []model.Call{
+				{
+					ID:           "monday",
+					Destinations: []model.Destination{{Type: "slack", To: []string{"#general"}}},
+					Triggers:     []model.Trigger{{Cron: "0 9 * * 1", Timezone: "Europe/Berlin"}},
+				},
+				{
+					ID:           "once",
+					Destinations: []model.Destination{{Type: "slack", To: []string{"#general"}}},
+					Triggers: []model.Trigger{
+						{ScheduledAt: time.Date(2025, 4, 1, 9, 0, 0, 0, time.UTC), Timezone: "Europe/Berlin"},
+					},
+				},
+			},
+		},
+	}
+
+	expandedCalls := s.Expand(sources, now, 0, 14*24*time.Hour)
+	sort.Slice(expandedCalls, func(i, j int) bool {
+		return expandedCalls[i].ScheduledAt.Before(expandedCalls[j].ScheduledAt)
+	})
+
+	var scheduledAt []time.Time
+	for _, call := range expandedCalls {
+		scheduledAt = append(scheduledAt, call.ScheduledAt)
+	}
+	assert.Equal(t, []time.Time{
+		time.Date(2025, 3, 24, 8, 0, 0, 0, time.UTC), // Monday 09:00 CET
+		time.Date(2025, 3, 31, 7, 0, 0, 0, time.UTC), // Monday 09:00 CEST
+		time.Date(2025, 4, 1, 7, 0, 0, 0, time.UTC),  // 09:00 CEST
+	}, scheduledAt)
+}
+
+func TestSchedulerExpand_Exclusions(t *testing.T) {
+	dbPath := "test.db"
+	defer os.Remove(dbPath)
+
+	store, err := bbolt.NewTestStore(dbPath)
+	assert.NoError(t, err)
+	defer store.Close()
+
+	s := scheduler.New(store)
+	viper.Set("slots.default", nil)
+
+	// 2025-12-22 is a Monday.
+	now := time.Date(2025, 12, 22, 0, 0, 0, 0, time.UTC)
+	call := func(trigger model.Trigger, blackouts ...model.Blackout) model.Call {
+		return model.Call{
+			ID:           "daily",
+			Destinations: []model.Destination{{Type: "slack", To: []string{"#general"}}},
+			Triggers:     []model.Trigger{trigger},
+			Campaign:     model.Campaign{ID: "campaign", Name: "Campaign", Blackouts: blackouts},
+		}
+	}
+	days := func(calls []*model.Call) []int {
+		var days []int
+		for _, c := range calls {
+			days = append(days, c.ScheduledAt.Day())
+		}
+		sort.Ints(days)
+		return days
+	}
+
+	t.Run("exdates", func(t *testing.T) {
+		sources := []*sourcer.Source{{Calls: []model.Call{
+			call(model.Trigger{Cron: "0 9 * * *", ExDates: []string{"2025-12-25", "2025-12-26T09:00:00Z"}}),
+		}}}
+		assert.Equal(t, []int{22, 23, 24, 27, 28}, days(s.Expand(sources, now, 0, 7*24*time.Hour)))
+	})
+
+	t.Run("except_rrule", func(t *testing.T) {
+		sources := []*sourcer.Source{{Calls: []model.Call{
+			call(model.Trigger{Cron: "0 9 * * *", ExceptRRule: "FREQ=WEEKLY;BYDAY=SA,SU"}),
+		}}}
+		assert.Equal(t, []int{22, 23, 24, 25, 26}, days(s.Expand(sources, now, 0, 7*24*time.Hour)))
+	})
+
+	t.Run("campaign blackouts", func(t *testing.T) {
+		sources := []*sourcer.Source{{Calls: []model.Call{
+			call(model.Trigger{Cron: "0 9 * * *"}, model.Blackout{
+				Start:  time.Date(2025, 12, 24, 0, 0, 0, 0, time.UTC),
+				End:    time.Date(2025, 12, 27, 0, 0, 0, 0, time.UTC),
+				Reason: "holidays",
+			}),
+		}}}
+		assert.Equal(t, []int{22, 23, 27, 28}, days(s.Expand(sources, now, 0, 7*24*time.Hour)))
+	})
+}
+
+func TestSchedulerExpand_Holidays(t *testing.T) {
+	dbPath := "test.db"
+	defer os.Remove(dbPath)
+
+	store, err := bbolt.NewTestStore(dbPath)
+	assert.NoError(t, err)
+	defer store.Close()
+
+	s := scheduler.New(store, scheduler.WithHolidays(calendar.NewStatic(
+		calendar.Holiday{Date: time.Date(2025, 12, 25, 0, 0, 0, 0, time.UTC), Name: "Christmas Day"},
+		calendar.Holiday{Date: time.Date(2025, 12, 26, 0, 0, 0, 0, time.UTC), Name: "Boxing Day"},
+	)))
+	viper.Set("slots.default", nil)
+
+	// 2025-12-22 is a Monday.
+	now := time.Date(2025, 12, 22, 0, 0, 0, 0, time.UTC)
+	expand := func(trigger model.Trigger) []time.Time {
+		sources := []*sourcer.Source{{Calls: []model.Call{{
+			ID:           "weekday",
+			Destinations: []model.Destination{{Type: "slack", To: []string{"#general"}}},
+			Triggers:     []model.Trigger{trigger},
+			Campaign:     model.Campaign{ID: "campaign", Name: "Campaign"},
+		}}}}
+		var times []time.Time
+		for _, c := range s.Expand(sources, now, 0, 7*24*time.Hour) {
+			times = append(times, c.ScheduledAt)
+		}
+		sort.Slice(times, func(i, j int) bool { return times[i].Before(times[j]) })
+		return times
+	}
+	at := func(day int) time.Time { return time.Date(2025, 12, day, 9, 0, 0, 0, time.UTC) }
+
+	t.Run("skip_holidays", func(t *testing.T) {
+		assert.Equal(t, []time.Time{at(22), at(23), at(24)}, expand(model.Trigger{Cron: "0 9 * * 1-5", SkipHolidays: true}))
+	})
+
+	t.Run("next_business_day", func(t *testing.T) {
+		// Both holidays move past the weekend to Monday the 29th.
+		assert.Equal(t, []time.Time{at(22), at(23), at(24), at(29), at(29)},
+			expand(model.Trigger{Cron: "0 9 * * 1-5", IfHoliday: model.IfHolidayNextBusinessDay}))
+	})
+
+	t.Run("no policy", func(t *testing.T) {
+		assert.Len(t, expand(model.Trigger{Cron: "0 9 * * 1-5"}), 5)
+	})
+
+	t.Run("moves are recorded", func(t *testing.T) {
+		sources := []*sourcer.Source{{Calls: []model.Call{{
+			ID:           "weekday",
+			Destinations: []model.Destination{{Type: "slack", To: []string{"#general"}}},
+			Triggers:     []model.Trigger{{ScheduledAt: at(25), IfHoliday: model.IfHolidayNextBusinessDay}},
+			Campaign:     model.Campaign{ID: "campaign", Name: "Campaign"},
+		}}}}
+		calls := s.Expand(sources, now, 0, 7*24*time.Hour)
+		if assert.Len(t, calls, 1) {
+			assert.Equal(t, []model.Shift{{Reason: model.ShiftHoliday, Detail: "Christmas Day", From: at(25), To: at(29)}}, calls[0].Shifts)
+		}
+	})
+}
+
+func TestSchedulerExpand_MonthlyTriggers(t *testing.T) {
+	dbPath := "test.db"
+	defer os.Remove(dbPath)
+
+	store, err := bbolt.NewTestStore(dbPath)
+	assert.NoError(t, err)
+	defer store.Close()
+
+	s := scheduler.New(store)
+	viper.Set("slots.default", nil)
+
+	now := time.Date(2025, 9, 15, 0, 0, 0, 0, time.UTC)
+	expand := func(trigger model.Trigger) []time.Time {
+		sources := []*sourcer.Source{{Calls: []model.Call{{
+			ID:           "monthly",
+			Destinations: []model.Destination{{Type: "slack", To: []string{"#general"}}},
+			Triggers:     []model.Trigger{trigger},
+			Campaign:     model.Campaign{ID: "campaign", Name: "Campaign"},
+		}}}}
+		var times []time.Time
+		for _, c := range s.Expand(sources, now, 0, 90*24*time.Hour) {
+			times = append(times, c.ScheduledAt)
+		}
+		sort.Slice(times, func(i, j int) bool { return times[i].Before(times[j]) })
+		return times
+	}
+
+	t.Run("first business day", func(t *testing.T) {
+		// 2025-11-01 is a Saturday.
+		assert.Equal(t, []time.Time{
+			time.Date(2025, 10, 1, 9, 0, 0, 0, time.UTC),
+			time.Date(2025, 11, 3, 9, 0, 0, 0, time.UTC),
+			time.Date(2025, 12, 1, 9, 0, 0, 0, time.UTC),
+		}, expand(model.Trigger{BusinessDay: model.BusinessDayFirst, Time: "09:00"}))
+	})
+
+	t.Run("last business day", func(t *testing.T) {
+		// 2025-11-30 is a Sunday.
+		assert.Equal(t, []time.Time{
+			time.Date(2025, 9, 30, 17, 0, 0, 0, time.UTC),
+			time.Date(2025, 10, 31, 17, 0, 0, 0, time.UTC),
+			time.Date(2025, 11, 28, 17, 0, 0, 0, time.UTC),
+		}, expand(model.Trigger{BusinessDay: model.BusinessDayLast, Time: "17:00"}))
+	})
+
+	t.Run("nth weekday in a timezone", func(t *testing.T) {
+		assert.Equal(t, []time.Time{
+			time.Date(2025, 10, 14, 8, 0, 0, 0, time.UTC),
+			time.Date(2025, 11, 11, 9, 0, 0, 0, time.UTC),
+			time.Date(2025, 12, 9, 9, 0, 0, 0, time.UTC),
+		}, expand(model.Trigger{NthWeekday: "2nd tuesday", Time: "10:00", Timezone: "Europe/Berlin"}))
+	})
+}
+
+func TestSchedulerExpand_DisabledTriggers(t *testing.T) {
+	dbPath := "test.db"
+	defer os.Remove(dbPath)
+
+	store, err := bbolt.NewTestStore(dbPath)
+	assert.NoError(t, err)
+	defer store.Close()
+
+	s := scheduler.New(store)
+	viper.Set("slots.default", nil)
+
+	disabled := false
+	now := time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC)
+	sources := []*sourcer.Source{{Calls: []model.Call{{
+		ID:           "standup",
+		Destinations: []model.Destination{{Type: "slack", To: []string{"#general"}}},
+		Triggers: []model.Trigger{
+			{Cron: "0 9 * * *"},
+			{Cron: "0 12 * * *"},
+			{Cron: "0 17 * * *", Enabled: &disabled},
+		},
+		Campaign: model.Campaign{ID: "campaign", Name: "Campaign"},
+	}}}}
+	hours := func() map[int]int {
+		hours := make(map[int]int)
+		for _, c := range s.Expand(sources, now, 0, 7*24*time.Hour) {
+			hours[c.ScheduledAt.Hour()]++
+		}
+		return hours
+	}
+
+	assert.Equal(t, map[int]int{9: 7, 12: 7}, hours())
+
+	assert.NoError(t, store.SetTriggerOverride(&kv.TriggerOverride{CallID: "standup", Index: 1, Until: time.Date(2025, 12, 4, 0, 0, 0, 0, time.UTC)}))
+	assert.Equal(t, map[int]int{9: 7, 12: 4}, hours())
+
+	assert.NoError(t, store.SetTriggerOverride(&kv.TriggerOverride{CallID: "standup", Index: 0}))
+	assert.Equal(t, map[int]int{12: 4}, hours())
+}
+
+func TestSchedulerExpand_SeriesOverrides(t *testing.T) {
+	dbPath := "test.db"
+	defer os.Remove(dbPath)
+
+	store, err := bbolt.NewTestStore(dbPath)
+	assert.NoError(t, err)
+	defer store.Close()
+
+	s := scheduler.New(store)
+	viper.Set("slots.default", nil)
+
+	anchor := time.Date(2025, 12, 10, 9, 0, 0, 0, time.UTC)
+	calls, err := model.Series{
+		ID:           "beta",
+		Anchor:       anchor,
+		Destinations: []model.Destination{{Type: "slack", To: []string{"#general"}}},
+		Steps: []model.SeriesStep{
+			{ID: "kickoff", Offset: "-7d", Content: "Soon"},
+			{ID: "launch", Offset: "0s", Content: "Now"},
+		},
+		Campaign: model.Campaign{ID: "campaign", Name: "Campaign"},
+	}.Calls()
+	assert.NoError(t, err)
+	sources := []*sourcer.Source{{Calls: calls}}
+	now := time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC)
+	scheduled := func() []time.Time {
+		var times []time.Time
+		for _, c := range s.Expand(sources, now, 0, 30*24*time.Hour) {
+			times = append(times, c.ScheduledAt)
+		}
+		return times
+	}
+
+	assert.Equal(t, []time.Time{anchor.AddDate(0, 0, -7), anchor}, scheduled())
+
+	assert.NoError(t, store.SetSeriesOverride(&kv.SeriesOverride{SeriesID: "beta", Shift: 48 * time.Hour}))
+	assert.Equal(t, []time.Time{anchor.AddDate(0, 0, -5), anchor.AddDate(0, 0, 2)}, scheduled())
+
+	assert.NoError(t, store.SetSeriesOverride(&kv.SeriesOverride{SeriesID: "beta", Paused: true}))
+	assert.Empty(t, scheduled())
+
+	assert.NoError(t, store.SetSeriesOverride(&kv.SeriesOverride{SeriesID: "beta", Cancelled: true}))
+	assert.Empty(t, scheduled())
+
+	assert.NoError(t, store.DeleteSeriesOverride("beta"))
+	assert.Len(t, scheduled(), 2)
+}
+
+func TestSchedulerExpand_DeltaFromEnd(t *testing.T) {
+	dbPath := "test.db"
+	defer os.Remove(dbPath)
+
+	store, err := bbolt.NewTestStore(dbPath)
+	assert.NoError(t, err)
+	defer store.Close()
+
+	s := scheduler.New(store)
+
+	start := time.Date(2025, 3, 4, 14, 0, 0, 0, time.UTC)
+	sources := []*sourcer.Source{{
+		Calls: []model.Call{{
+			ID:           "retro",
+			Destinations: []model.Destination{{Type: "slack", To: []string{"#incidents"}}},
+			Triggers: []model.Trigger{
+				{Sequence: "review", Delta: "-15m"},
+				{Sequence: "review", Delta: "1h", DeltaFrom: model.DeltaFromEnd},
+			},
+		}},
+		Events: []model.Event{
+			{Sequence: "review", StartTime: start, Duration: "90m"},
+			{Sequence: "review", StartTime: start.AddDate(0, 0, 7), EndTime: start.AddDate(0, 0, 7).Add(2 * time.Hour)},
+			{Sequence: "review", StartTime: start.AddDate(0, 0, 14)}, // No end, so only the start trigger fires.
+		},
+	}}
+
+	expandedCalls := s.Expand(sources, start, 24*time.Hour, 30*24*time.Hour)
+	var scheduled []time.Time
+	for _, call := range expandedCalls {
+		scheduled = append(scheduled, call.ScheduledAt)
+	}
+	sort.Slice(scheduled, func(i, j int) bool { return scheduled[i].Before(scheduled[j]) })
+
+	assert.Equal(t, []time.Time{
+		start.Add(-15 * time.Minute),
+		start.Add(150 * time.Minute),
+		start.AddDate(0, 0, 7).Add(-15 * time.Minute),
+		start.AddDate(0, 0, 7).Add(3 * time.Hour),
+		start.AddDate(0, 0, 14).Add(-15 * time.Minute),
+	}, scheduled)
+}
+
+func TestSchedulerExpand_JitterAndSpread(t *testing.T) {
+	dbPath := "test.db"
+	defer os.Remove(dbPath)
+
+	store, err := bbolt.NewTestStore(dbPath)
+	assert.NoError(t, err)
+	defer store.Close()
+
+	s := scheduler.New(store)
+
+	at := time.Date(2025, 3, 4, 9, 0, 0, 0, time.UTC)
+	sources := []*sourcer.Source{{
+		Calls: []model.Call{{
+			ID:           "all-hands",
+			Destinations: []model.Destination{{Type: "slack", To: []string{"#a", "#b", "#c"}}},
+			Triggers: []model.Trigger{
+				{ScheduledAt: at.Add(time.Minute), Spread: "30m"},
+				{ScheduledAt: at.Add(time.Hour), Jitter: "10m"},
+			},
+		}},
+	}}
+
+	expandedCalls := s.Expand(sources, at, 24*time.Hour, 24*time.Hour)
+	assert.Len(t, expandedCalls, 4)
+
+	// The spread trigger sends one call per recipient, staggered over the window.
+	spread := make(map[string]time.Time)
+	for _, call := range expandedCalls[:3] {
+		spread[call.Destinations[0].To[0]] = call.ScheduledAt
+		assert.Equal(t, fmt.Sprintf("all-hands:scheduled_at:%s:slack:%s", at.Add(time.Minute).Format(time.RFC3339), call.Destinations[0].To[0]), call.ID)
+	}
+	assert.Equal(t, map[string]time.Time{
+		"#a": at.Add(time.Minute),
+		"#b": at.Add(11 * time.Minute),
+		"#c": at.Add(21 * time.Minute),
+	}, spread)
+
+	// The jitter trigger keeps its destination whole, and delays it by less than the jitter.
+	jittered := expandedCalls[3]
+	assert.Equal(t, []string{"#a", "#b", "#c"}, jittered.Destinations[0].To)
+	assert.False(t, jittered.ScheduledAt.Before(at.Add(time.Hour)))
+	assert.True(t, jittered.ScheduledAt.Before(at.Add(70*time.Minute)))
+
+	// The jitter is the same when the schedule is expanded again.
+	again := s.Expand(sources, at, 24*time.Hour, 24*time.Hour)
+	assert.Equal(t, jittered.ScheduledAt, again[3].ScheduledAt)
+}
+
+func TestSchedulerExpand_Limits(t *testing.T) {
+	dbPath := "test.db"
+	defer os.Remove(dbPath)
+
+	store, err := bbolt.NewTestStore(dbPath)
+	assert.NoError(t, err)
+	defer store.Close()
+
+	quiet, err := limits.ParseWindow("18:00", "09:00")
+	assert.NoError(t, err)
+	l := limits.New()
+	l.Set("slack", "#general", limits.Rule{MaxPerDay: 2, QuietHours: []limits.Window{quiet}})
+	s := scheduler.New(store, scheduler.WithLimits(l))
+
+	day := time.Date(2025, 3, 4, 0, 0, 0, 0, time.UTC)
+	call := func(id string, at time.Time, to string) model.Call {
+		return model.Call{
+			ID:           id,
+			Destinations: []model.Destination{{Type: "slack", To: []string{to}}},
+			Triggers:     []model.Trigger{{ScheduledAt: at}},
+		}
+	}
+	sources := []*sourcer.Source{{
+		Calls: []model.Call{
+			call("late", day.Add(20*time.Hour), "#general"),
+			call("first", day.Add(10*time.Hour), "#general"),
+			call("second", day.Add(11*time.Hour), "#general"),
+			call("third", day.Add(12*time.Hour), "#general"),
+			call("elsewhere", day.Add(20*time.Hour), "#random"),
+		},
+	}}
+
+	scheduled := make(map[string]time.Time)
+	for _, c := range s.Expand(sources, day, 24*time.Hour, 48*time.Hour) {
+		scheduled[strings.SplitN(c.ID, ":", 2)[0]] = c.ScheduledAt
+	}
+	assert.Equal(t, map[string]time.Time{
+		"first":  day.Add(10 * time.Hour),
+		"second": day.Add(11 * time.Hour),
+		// The overflow of the day, and the call in the quiet hours, move to the next morning.
+		"third":     day.Add(33 * time.Hour),
+		"late":      day.Add(33 * time.Hour),
+		"elsewhere": day.Add(20 * time.Hour),
+	}, scheduled)
+}
+
+func TestSchedulerExpand_After(t *testing.T) {
+	dbPath := "test.db"
+	defer os.Remove(dbPath)
+
+	store, err := bbolt.NewTestStore(dbPath)
+	assert.NoError(t, err)
+	defer store.Close()
+
+	s := scheduler.New(store)
+	now := time.Date(2025, 3, 4, 12, 0, 0, 0, time.UTC)
+	campaign := model.Campaign{ID: "onboarding", Name: "Onboarding"}
+	dest := []model.Destination{{Type: "slack", To: []string{"#general"}}}
+	sources := []*sourcer.Source{{
+		Calls: []model.Call{
+			{ID: "day-1", Campaign: campaign, Destinations: dest, Triggers: []model.Trigger{{ScheduledAt: now.Add(-2 * time.Hour)}}},
+			{ID: "day-2", Campaign: campaign, Destinations: dest, Triggers: []model.Trigger{{After: "day-1", Delta: "24h"}}},
+		},
+	}}
+
+	find := func(calls []*model.Call, id string) *model.Call {
+		for _, c := range calls {
+			if c.ID == id {
+				return c
+			}
+		}
+		return nil
+	}
+
+	// Until the first call is sent, the second waits for it.
+	pending := find(s.Expand(sources, now, 24*time.Hour, 48*time.Hour), "day-2:after:day-1:slack:#general")
+	if assert.NotNil(t, pending) {
+		assert.True(t, pending.ScheduledAt.IsZero())
+		assert.Equal(t, &model.Dependency{CallID: "day-1", Delay: 24 * time.Hour}, pending.DependsOn)
+	}
+
+	sentAt := now.Add(-time.Hour)
+	assert.NoError(t, store.AddSentMessage("onboarding", "day-1", &kv.SentMessage{
+		SourceID:    "day-1:scheduled_at:2025-03-04T10:00:00Z:slack:#general",
+		ScheduledAt: now.Add(-2 * time.Hour),
+		SentAt:      sentAt,
+		Status:      kv.StatusSent,
+		Type:        "slack",
+		Destination: "#general",
+	}))
+
+	// Once it has been sent, the second is scheduled the delay after it, under the same ID.
+	resolved := find(s.Expand(sources, now, 24*time.Hour, 48*time.Hour), "day-2:after:day-1:slack:#general")
+	if assert.NotNil(t, resolved) {
+		assert.Equal(t, sentAt.Add(24*time.Hour), resolved.ScheduledAt)
+	}
+}
+
+func TestSchedulerExpand_Expiry(t *testing.T) {
+	dbPath := "test.db"
+	defer os.Remove(dbPath)
+
+	store, err := bbolt.NewTestStore(dbPath)
+	assert.NoError(t, err)
+	defer store.Close()
+
+	s := scheduler.New(store)
+	now := time.Date(2025, 3, 4, 0, 0, 0, 0, time.UTC)
+	dest := []model.Destination{{Type: "slack", To: []string{"#general"}}}
+
+	testCases := []struct {
+		name     string
+		call     model.Call
+		expected []time.Time
+	}{
+		{
+			name: "until",
+			call: model.Call{ID: "until", Destinations: dest, Triggers: []model.Trigger{
+				{Cron: "0 9 * * *", Until: now.Add(57 * time.Hour)},
+			}},
+			expected: []time.Time{now.Add(9 * time.Hour), now.Add(33 * time.Hour), now.Add(57 * time.Hour)},
+		},
+		{
+			name: "count from dstart",
+			call: model.Call{ID: "count", Destinations: dest, Triggers: []model.Trigger{
+				// Two of the four occurrences were before the window.
+				{Cron: "0 9 * * *", DStart: "20250302", Count: 4},
+			}},
+			expected: []time.Time{now.Add(9 * time.Hour), now.Add(33 * time.Hour)},
+		},
+		{
+			name: "dstart in the future",
+			call: model.Call{ID: "dstart", Destinations: dest, Triggers: []model.Trigger{
+				{Cron: "0 9 * * *", DStart: "20250305"},
+			}},
+			expected: []time.Time{now.Add(33 * time.Hour), now.Add(57 * time.Hour), now.Add(81 * time.Hour)},
+		},
+		{
+			name: "expires_at",
+			call: model.Call{ID: "expires", ExpiresAt: now.Add(33 * time.Hour), Destinations: dest, Triggers: []model.Trigger{
+				{Cron: "0 9 * * *"},
+				{ScheduledAt: now.Add(40 * time.Hour)},
+			}},
+			expected: []time.Time{now.Add(9 * time.Hour)},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var scheduled []time.Time
+			for _, c := range s.Expand([]*sourcer.Source{{Calls: []model.Call{tc.call}}}, now, 0, 4*24*time.Hour) {
+				scheduled = append(scheduled, c.ScheduledAt)
+			}
+			assert.Equal(t, tc.expected, scheduled)
+		})
+	}
+}
+
+func TestSchedulerRefreshSchedule_StableSlots(t *testing.T) {
+	dbPath := "test_stable_slots.db"
+	defer os.Remove(dbPath)
+
+	store, err := bbolt.NewTestStore(dbPath)
+	assert.NoError(t, err)
+	defer store.Close()
+
+	s := scheduler.New(store)
+
+	viper.Set("slots.timezone", "UTC")
+	viper.Set("slots.default", map[string][]string{
+		"monday": {"10:00", "16:00"},
+	})
+	t.Cleanup(func() { viper.Set("slots.default", nil) })
+
+	now := time.Date(2023, 1, 2, 8, 0, 0, 0, time.UTC) // A Monday
+	midnight := time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC)
+	call := func(id string) model.Call {
+		return model.Call{
+			ID:           id,
+			Triggers:     []model.Trigger{{ScheduledAt: midnight}},
+			Destinations: []model.Destination{{Type: "email", To: []string{"team@example.com"}}},
+		}
+	}
+	scheduled := func() map[string]time.Time {
+		calls, err := store.ListScheduledCalls()
+		assert.NoError(t, err)
+		times := make(map[string]time.Time, len(calls))
+		for _, c := range calls {
+			times[strings.SplitN(c.ID, ":", 2)[0]] = c.ScheduledAt
+		}
+		return times
+	}
+
+	assert.NoError(t, s.RefreshSchedule([]*sourcer.Source{{Calls: []model.Call{call("announced")}}}, now, time.Hour, 24*time.Hour))
+	assert.Equal(t, map[string]time.Time{"announced": midnight.Add(10 * time.Hour)}, scheduled())
+
+	// A call added ahead of it takes the next free slot, rather than the slot already given out.
+	assert.NoError(t, s.RefreshSchedule([]*sourcer.Source{{Calls: []model.Call{call("added"), call("announced")}}}, now, time.Hour, 24*time.Hour))
+	assert.Equal(t, map[string]time.Time{
+		"added":     midnight.Add(16 * time.Hour),
+		"announced": midnight.Add(10 * time.Hour),
+	}, scheduled())
+
+	// Once the first call is removed, its slot is released, but the other call is not moved into it.
+	assert.NoError(t, s.RefreshSchedule([]*sourcer.Source{{Calls: []model.Call{call("added")}}}, now, time.Hour, 24*time.Hour))
+	assert.Equal(t, map[string]time.Time{"added": midnight.Add(16 * time.Hour)}, scheduled())
+
+	slots, err := store.ListSlots()
+	assert.NoError(t, err)
+	assert.Equal(t, map[time.Time]string{
+		midnight.Add(16 * time.Hour): "added:scheduled_at:2023-01-02T00:00:00Z:email:team@example.com",
+	}, slots)
+
+	// Expanding without refreshing respects the reservations, without storing any.
+	expanded := s.Expand([]*sourcer.Source{{Calls: []model.Call{call("preview"), call("added")}}}, now, time.Hour, 24*time.Hour)
+	assert.Len(t, expanded, 2)
+	for _, c := range expanded {
+		if strings.HasPrefix(c.ID, "added:") {
+			assert.Equal(t, midnight.Add(16*time.Hour), c.ScheduledAt)
+		} else {
+			assert.Equal(t, midnight.Add(10*time.Hour), c.ScheduledAt)
+		}
+	}
+	after, err := store.ListSlots()
+	assert.NoError(t, err)
+	assert.Equal(t, slots, after)
+}
+
+func TestSchedulerExplain(t *testing.T) {
+	dbPath := "test_explain.db"
+	defer os.Remove(dbPath)
+
+	store, err := bbolt.NewTestStore(dbPath)
+	assert.NoError(t, err)
+	defer store.Close()
+
+	s := scheduler.New(store)
+	viper.Set("slots.default", nil)
+
+	now := time.Date(2025, 3, 3, 8, 0, 0, 0, time.UTC) // A Monday
+	enabled := false
+	sources := []*sourcer.Source{{Calls: []model.Call{{
+		ID:           "standup",
+		Destinations: []model.Destination{{Type: "slack", To: []string{"#general"}}},
+		Triggers: []model.Trigger{
+			{Cron: "0 9 * * *", ExDates: []string{"2025-03-04"}},
+			{ScheduledAt: now.Add(time.Hour), Enabled: &enabled},
+			{Sequence: "launch", Delta: "1h"},
+		},
+		Campaign: model.Campaign{ID: "campaign", Name: "Campaign"},
+	}}}}
+
+	traces := s.Explain(sources, now, 0, 48*time.Hour)
+	if !assert.Len(t, traces, 1) {
+		return
+	}
+	trace := traces[0]
+	assert.Equal(t, "standup", trace.CallID)
+	assert.Equal(t, []string{"cron 0 9 * * *", "scheduled_at 2025-03-03T09:00:00Z", "sequence launch, delta 1h"}, trace.Triggers)
+
+	var messages []string
+	for _, step := range trace.Steps {
+		messages = append(messages, fmt.Sprintf("%d %s", step.Trigger, step.Message))
+	}
+	assert.Equal(t, []string{
+		"0 fired at 2025-03-03T09:00:00Z",
+		"0 fired at 2025-03-04T09:00:00Z",
+		"0 skipped: exdate 2025-03-04",
+		"1 skipped: the trigger is disabled",
+		"2 nothing fired for slack #general between 2025-03-03T08:00:00Z and 2025-03-05T08:00:00Z",
+		"0 scheduled at 2025-03-03T09:00:00Z",
+	}, messages)
+
+	// Explaining stores nothing.
+	calls, err := store.ListScheduledCalls()
+	assert.NoError(t, err)
+	assert.Empty(t, calls)
+}
+
+func TestSchedulerRefreshSchedule_CronOccurrences(t *testing.T) {
+	dbPath := "test_cron_occurrences.db"
+	defer os.Remove(dbPath)
+
+	store, err := bbolt.NewTestStore(dbPath)
+	assert.NoError(t, err)
+	defer store.Close()
+
+	s := scheduler.New(store)
+
+	now := time.Date(2023, 1, 2, 8, 0, 0, 0, time.UTC)
+	sources := []*sourcer.Source{{Calls: []model.Call{{
+		ID: "digest",
+		// The same cron listed twice fires each occurrence once.
+		Triggers:     []model.Trigger{{Cron: "0 9,15 * * *"}, {Cron: "0 9,15 * * *"}},
+		Destinations: []model.Destination{{Type: "email", To: []string{"team@example.com"}}},
+	}}}}
+	assert.NoError(t, s.RefreshSchedule(sources, now, time.Hour, 72*time.Hour))
+
+	// Every occurrence in the window is kept, rather than the last replacing the others.
+	calls, err := store.ListScheduledCalls()
+	assert.NoError(t, err)
+	var times []time.Time
+	for _, c := range calls {
+		assert.Contains(t, c.ID, c.ScheduledAt.Format(time.RFC3339))
+		times = append(times, c.ScheduledAt)
+	}
+	sort.Slice(times, func(i, j int) bool { return times[i].Before(times[j]) })
+	assert.Equal(t, []time.Time{
+		time.Date(2023, 1, 2, 9, 0, 0, 0, time.UTC),
+		time.Date(2023, 1, 2, 15, 0, 0, 0, time.UTC),
+		time.Date(2023, 1, 3, 9, 0, 0, 0, time.UTC),
+		time.Date(2023, 1, 3, 15, 0, 0, 0, time.UTC),
+		time.Date(2023, 1, 4, 9, 0, 0, 0, time.UTC),
+		time.Date(2023, 1, 4, 15, 0, 0, 0, time.UTC),
+	}, times)
+}
+
+func TestSchedulerExpand_Horizon(t *testing.T) {
+	dbPath := "test_horizon.db"
+	defer os.Remove(dbPath)
+
+	store, err := bbolt.NewTestStore(dbPath)
+	assert.NoError(t, err)
+	defer store.Close()
+
+	s := scheduler.New(store)
+
+	now := time.Date(2023, 1, 2, 8, 0, 0, 0, time.UTC)
+	call := func(id string, horizon *model.Horizon, campaign model.Campaign) model.Call {
+		return model.Call{
+			ID:           id,
+			Horizon:      horizon,
+			Campaign:     campaign,
+			Triggers:     []model.Trigger{{Cron: "0 9 1 3 *"}},
+			Destinations: []model.Destination{{Type: "email", To: []string{"team@example.com"}}},
+		}
+	}
+	longLead := model.Campaign{ID: "events", Horizon: &model.Horizon{After: "2160h"}}
+	sources := []*sourcer.Source{{Calls: []model.Call{
+		// Without a horizon, the 1st of March is outside the window.
+		call("default", nil, model.Campaign{ID: "general"}),
+		call("campaign", nil, longLead),
+		// The horizon of the call overrides that of its campaign.
+		call("call", &model.Horizon{After: "24h"}, longLead),
+		call("own", &model.Horizon{After: "1500h"}, model.Campaign{ID: "general"}),
+	}}}
+
+	var ids []string
+	for _, c := range s.Expand(sources, now, time.Hour, 24*time.Hour) {
+		ids = append(ids, strings.SplitN(c.ID, ":", 2)[0])
+		assert.Equal(t, time.Date(2023, 3, 1, 9, 0, 0, 0, time.UTC), c.ScheduledAt)
+	}
+	assert.ElementsMatch(t, []string{"campaign", "own"}, ids)
+}
+
+func TestSchedulerExpand_LocalTime(t *testing.T) {
+	dbPath := "test_local_time.db"
+	defer os.Remove(dbPath)
+
+	store, err := bbolt.NewTestStore(dbPath)
+	assert.NoError(t, err)
+	defer store.Close()
+
+	zones, err := timezone.NewStatic(map[string]map[string]string{
+		"email": {"Tokyo@example.com": "Asia/Tokyo", "sf@example.com": "America/Los_Angeles"},
+	})
+	assert.NoError(t, err)
+	s := scheduler.New(store, scheduler.WithTimezones(zones))
+
+	now := time.Date(2023, 3, 1, 0, 0, 0, 0, time.UTC)
+	sources := []*sourcer.Source{{Calls: []model.Call{{
+		ID:       "standup",
+		Triggers: []model.Trigger{{Cron: "0 12 2 3 *", Timezone: "Europe/Berlin"}},
+		Destinations: []model.Destination{{
+			Type:      "email",
+			To:        []string{"tokyo@example.com", "sf@example.com", "unknown@example.com"},
+			LocalTime: "09:00",
+		}},
+	}}}}
+
+	at := make(map[string]time.Time)
+	for _, c := range s.Expand(sources, now, time.Hour, 48*time.Hour) {
+		if assert.Len(t, c.Destinations, 1) && assert.Len(t, c.Shifts, 1) {
+			at[c.Destinations[0].To[0]] = c.ScheduledAt
+			assert.Equal(t, model.ShiftLocalTime, c.Shifts[0].Reason)
+		}
+	}
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]time.Time{
+		"tokyo@example.com": time.Date(2023, 3, 2, 0, 0, 0, 0, time.UTC),
+		"sf@example.com":    time.Date(2023, 3, 2, 17, 0, 0, 0, time.UTC),
+		// A recipient without a timezone is sent their copy in the timezone of the trigger.
+		"unknown@example.com": time.Date(2023, 3, 2, 9, 0, 0, 0, berlin).UTC(),
+	}, at)
+}
+
+func TestSchedulerExpand_Concurrency(t *testing.T) {
+	dbPath := "test_concurrency.db"
+	defer os.Remove(dbPath)
+
+	store, err := bbolt.NewTestStore(dbPath)
+	assert.NoError(t, err)
+	defer store.Close()
+
+	viper.Set("slots.timezone", "UTC")
+	viper.Set("slots.default", map[string][]string{
+		"monday":  {"09:00", "10:00", "11:00", "12:00"},
+		"tuesday": {"09:00", "10:00", "11:00", "12:00"},
+	})
+	t.Cleanup(func() { viper.Set("slots.default", nil) })
+
+	now := time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC) // A Monday
+	var calls []model.Call
+	for i := range 64 {
+		calls = append(calls, model.Call{
+			ID:           fmt.Sprintf("call-%02d", i),
+			Triggers:     []model.Trigger{{ScheduledAt: now}, {Cron: "0 8 * * *"}},
+			Destinations: []model.Destination{{Type: "email", To: []string{"team@example.com"}}},
+		})
+	}
+	sources := []*sourcer.Source{{Calls: calls[:32]}, {Calls: calls[32:]}}
+
+	// The calls contend for slots, which are given out in the order the calls appear whatever the
+	// number of definitions expanded at once.
+	serial := scheduler.New(store, scheduler.WithConcurrency(1)).Expand(sources, now, time.Hour, 48*time.Hour)
+	assert.NotEmpty(t, serial)
+	for range 5 {
+		parallel := scheduler.New(store, scheduler.WithConcurrency(8)).Expand(sources, now, time.Hour, 48*time.Hour)
+		assert.Equal(t, serial, parallel)
+	}
+}
+
+func TestSchedulerPreview(t *testing.T) {
+	dbPath := "test_preview.db"
+	defer os.Remove(dbPath)
+
+	store, err := bbolt.NewTestStore(dbPath)
+	assert.NoError(t, err)
+	defer store.Close()
+
+	s := scheduler.New(store)
+	viper.Set("slots.default", nil)
+	from := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC) // A Wednesday
+
+	at := func(occurrences []scheduler.Occurrence) []time.Time {
+		var times []time.Time
+		for _, o := range occurrences {
+			times = append(times, o.At)
+		}
+		return times
+	}
+
+	t.Run("cron", func(t *testing.T) {
+		occurrences, err := s.Preview(model.Trigger{Cron: "0 9 * * 1"}, 3, from)
+		assert.NoError(t, err)
+		assert.Equal(t, []time.Time{
+			time.Date(2025, 1, 6, 9, 0, 0, 0, time.UTC),
+			time.Date(2025, 1, 13, 9, 0, 0, 0, time.UTC),
+			time.Date(2025, 1, 20, 9, 0, 0, 0, time.UTC),
+		}, at(occurrences))
+	})
+
+	t.Run("rrule beyond the first window", func(t *testing.T) {
+		occurrences, err := s.Preview(model.Trigger{RRule: "FREQ=MONTHLY;BYMONTHDAY=15;BYHOUR=10", DStart: "20250101"}, 2, from)
+		assert.NoError(t, err)
+		assert.Equal(t, []time.Time{
+			time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC),
+			time.Date(2025, 2, 15, 10, 0, 0, 0, time.UTC),
+		}, at(occurrences))
+	})
+
+	t.Run("hijri fires once a year", func(t *testing.T) {
+		occurrences, err := s.Preview(model.Trigger{Hijri: "1 Ramadan", Time: "09:00"}, 3, from)
+		assert.NoError(t, err)
+		if assert.Len(t, occurrences, 3) {
+			assert.True(t, occurrences[0].At.After(from))
+			for i := 1; i < len(occurrences); i++ {
+				gap := occurrences[i].At.Sub(occurrences[i-1].At)
+				assert.InDelta(t, 354, gap.Hours()/24, 1)
+			}
+		}
+	})
+
+	t.Run("stops when the trigger does", func(t *testing.T) {
+		occurrences, err := s.Preview(model.Trigger{Cron: "0 9 * * *", DStart: "20250101", Count: 2}, 5, from)
+		assert.NoError(t, err)
+		assert.Len(t, occurrences, 2)
+	})
+
+	t.Run("invalid trigger", func(t *testing.T) {
+		_, err := s.Preview(model.Trigger{Cron: "0 9 * *"}, 3, from)
+		assert.ErrorIs(t, err, scheduler.ErrInvalidTrigger)
+	})
+}
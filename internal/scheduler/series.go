@@ -0,0 +1,54 @@
+package scheduler
+
+import (
+	"log/slog"
+	"slices"
+
+	"github.com/andrewhowdencom/ruf/internal/kv"
+	"github.com/andrewhowdencom/ruf/internal/model"
+)
+
+// loadSeriesOverrides loads the series overrides, by the ID of their series. If they cannot be loaded,
+// the error is logged and every series is expanded as it is defined.
+func (s *Scheduler) loadSeriesOverrides() map[string]*kv.SeriesOverride {
+	list, err := s.storer.ListSeriesOverrides()
+	if err != nil {
+		slog.Error("failed to load series overrides", "error", err)
+		return nil
+	}
+	overrides := make(map[string]*kv.SeriesOverride, len(list))
+	for _, o := range list {
+		overrides[o.SeriesID] = o
+	}
+	return overrides
+}
+
+// applySeries applies the override of the series a step belongs to, so that the steps of a series are
+// scheduled together. It returns why the step is not scheduled, if it is not: the series is paused or
+// cancelled, or has been shifted after the step was sent. Otherwise, the trigger of the step is moved
+// by the shift of the series.
+func applySeries(callDef *model.Call, x *expansion) string {
+	override := x.series[callDef.Series]
+	switch {
+	case override == nil:
+		return ""
+	case override.Cancelled:
+		return "the series is cancelled"
+	case override.Paused:
+		return "the series is paused"
+	case override.Shift == 0:
+		return ""
+	}
+
+	// A step that was sent before the series was shifted is not sent again at its new time.
+	if _, ok := x.sent.sentAt(callDef.Campaign.ID, callDef.ID); ok {
+		return "the step was sent before the series was shifted"
+	}
+	callDef.Triggers = slices.Clone(callDef.Triggers)
+	for i := range callDef.Triggers {
+		if !callDef.Triggers[i].ScheduledAt.IsZero() {
+			callDef.Triggers[i].ScheduledAt = callDef.Triggers[i].ScheduledAt.Add(override.Shift)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,47 @@
+package scheduler
+
+import (
+	"sort"
+	"time"
+
+	"github.com/andrewhowdencom/ruf/internal/model"
+	"github.com/andrewhowdencom/ruf/internal/sourcer"
+)
+
+// Simulate expands the call definitions over the window from from up to to, as a refresh of the
+// schedule at from would, and returns the calls that would be sent in the window, in the order they
+// would be sent. Slots are assigned starting from those the current schedule holds, but nothing is
+// stored. The horizons of calls and campaigns are ignored, so that every call is expanded over the
+// whole window.
+func (s *Scheduler) Simulate(sources []*sourcer.Source, from, to time.Time) []*model.Call {
+	return s.simulate(sources, from, to, nil)
+}
+
+// simulate simulates the window like Simulate, recording the expansion in the tracer.
+func (s *Scheduler) simulate(sources []*sourcer.Source, from, to time.Time, tr *tracer) []*model.Call {
+	from, to = from.UTC(), to.UTC()
+	simulated := make([]*sourcer.Source, 0, len(sources))
+	for _, source := range sources {
+		copied := *source
+		copied.Calls = make([]model.Call, len(source.Calls))
+		for i, call := range source.Calls {
+			call.Horizon = nil
+			call.Campaign.Horizon = nil
+			copied.Calls[i] = call
+		}
+		simulated = append(simulated, &copied)
+	}
+
+	var calls []*model.Call
+	for _, call := range s.expand(simulated, from, 0, to.Sub(from), s.previousSlots(), tr) {
+		// Calls that wait for another call are only scheduled once it has been sent.
+		if call.ScheduledAt.IsZero() || call.ScheduledAt.Before(from) || !call.ScheduledAt.Before(to) {
+			continue
+		}
+		calls = append(calls, call)
+	}
+	sort.SliceStable(calls, func(i, j int) bool {
+		return calls[i].ScheduledAt.Before(calls[j].ScheduledAt)
+	})
+	return calls
+}
@@ -0,0 +1,324 @@
+// Package selftest runs a canned source through the whole pipeline of ruf (refresh, schedule and
+// dispatch) against in-process stand-ins for Slack, an SMTP server and a source server, so that an
+// installation can be checked without sending anything to the outside world.
+package selftest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/andrewhowdencom/ruf/internal/clients/email"
+	"github.com/andrewhowdencom/ruf/internal/clients/slack"
+	"github.com/andrewhowdencom/ruf/internal/kv"
+	"github.com/andrewhowdencom/ruf/internal/kv/bbolt"
+	"github.com/andrewhowdencom/ruf/internal/poller"
+	"github.com/andrewhowdencom/ruf/internal/scheduler"
+	"github.com/andrewhowdencom/ruf/internal/sourcer"
+	"github.com/andrewhowdencom/ruf/internal/worker"
+)
+
+var (
+	// ErrFailed is returned when a check of the self test fails.
+	ErrFailed = errors.New("self test failed")
+)
+
+const (
+	campaignID     = "selftest"
+	slackChannel   = "selftest"
+	emailRecipient = "selftest@example.com"
+)
+
+// source is the canned source sent by the self test. Both of its calls are due when the test runs.
+const source = `campaign:
+  id: ` + campaignID + `
+  name: Self Test
+calls:
+  - id: slack
+    subject: Self test
+    content: "Hello from **{{ .Name }}**, through Slack."
+    data:
+      Name: ruf
+    destinations:
+      - type: slack
+        to: ["#` + slackChannel + `"]
+    triggers:
+      - scheduled_at: "%[1]s"
+  - id: email
+    subject: Self test
+    content: "Hello from **{{ .Name }}**, through email."
+    data:
+      Name: ruf
+    destinations:
+      - type: email
+        to: ["` + emailRecipient + `"]
+    triggers:
+      - scheduled_at: "%[1]s"
+`
+
+// Check is the outcome of a single stage of the self test.
+type Check struct {
+	Name   string
+	Detail string
+	Err    error
+}
+
+// Report lists the checks made by the self test, in the order they were made.
+type Report struct {
+	Checks []Check
+}
+
+// Passed reports whether every check passed.
+func (r *Report) Passed() bool {
+	for _, c := range r.Checks {
+		if c.Err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// Write writes the report, one check per line, followed by the overall result.
+func (r *Report) Write(w io.Writer) {
+	for _, c := range r.Checks {
+		if c.Err != nil {
+			fmt.Fprintf(w, "FAIL  %s: %s\n", c.Name, c.Err)
+			continue
+		}
+		fmt.Fprintf(w, "PASS  %s: %s\n", c.Name, c.Detail)
+	}
+	if r.Passed() {
+		fmt.Fprintln(w, "Self test passed.")
+	} else {
+		fmt.Fprintln(w, "Self test failed.")
+	}
+}
+
+// Err returns ErrFailed, wrapped with the first failed check, if any check failed.
+func (r *Report) Err() error {
+	for _, c := range r.Checks {
+		if c.Err != nil {
+			return fmt.Errorf("%w: %s: %w", ErrFailed, c.Name, c.Err)
+		}
+	}
+	return nil
+}
+
+// Run runs the self test, validating the source against the embedded schema. The checks stop at
+// the first that fails, as the later ones depend on it. An error is only returned if the stand-ins
+// cannot be started.
+func Run() (*Report, error) {
+	dir, err := os.MkdirTemp("", "ruf-selftest-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create a temporary directory: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	store, err := bbolt.NewTestStore(filepath.Join(dir, "ruf.db"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the datastore: %w", err)
+	}
+	defer store.Close()
+
+	slackServer := newSlackServer(slackChannel)
+	defer slackServer.Close()
+
+	smtp, err := newSMTPServer()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start the SMTP server: %w", err)
+	}
+	defer smtp.Close()
+
+	// The calls are due a minute ago, which is within any lookback, and away from midnight, which would
+	// move them into a slot.
+	scheduledAt := time.Now().UTC().Add(-time.Minute).Truncate(time.Minute).Add(30 * time.Second)
+	sourceServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/yaml")
+		fmt.Fprintf(w, source, scheduledAt.Format(time.RFC3339))
+	}))
+	defer sourceServer.Close()
+
+	fetcher := sourcer.NewCompositeFetcher()
+	fetcher.AddFetcher("http", sourcer.NewHTTPFetcher(sourceServer.Client()))
+	parser, err := sourcer.NewYAMLParser()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create parser: %w", err)
+	}
+	p := poller.New(sourcer.NewSourcer(fetcher, parser), 0)
+	sched := scheduler.New(store)
+
+	slackClient := slack.NewClient("xoxb-selftest", slack.WithEndpoint(slackServer.URL))
+	emailClient := email.NewClient("127.0.0.1", smtp.Port(), "selftest", "selftest", "ruf@example.com")
+	w, err := worker.New(store, slackClient, emailClient, p, sched, 0, false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create worker: %w", err)
+	}
+
+	t := &test{
+		store:       store,
+		slack:       slackServer,
+		smtp:        smtp,
+		poller:      p,
+		scheduler:   sched,
+		worker:      w,
+		url:         sourceServer.URL + "/selftest.yaml",
+		scheduledAt: scheduledAt,
+	}
+	return t.run(), nil
+}
+
+// test holds the pipeline under test, and the stand-ins it talks to.
+type test struct {
+	store       kv.Storer
+	slack       *slackServer
+	smtp        *smtpServer
+	poller      *poller.Poller
+	scheduler   *scheduler.Scheduler
+	worker      *worker.Worker
+	url         string
+	scheduledAt time.Time
+
+	sources []*sourcer.Source
+}
+
+func (t *test) run() *Report {
+	report := &Report{}
+	for _, stage := range []struct {
+		name string
+		fn   func() (string, error)
+	}{
+		{"refresh", t.refresh},
+		{"schedule", t.schedule},
+		{"dispatch", t.dispatch},
+		{"slack", t.checkSlack},
+		{"email", t.checkEmail},
+		{"datastore", t.checkDatastore},
+		{"deduplication", t.checkDeduplication},
+	} {
+		detail, err := stage.fn()
+		report.Checks = append(report.Checks, Check{Name: stage.name, Detail: detail, Err: err})
+		if err != nil {
+			break
+		}
+	}
+	return report
+}
+
+// refresh fetches and parses the source.
+func (t *test) refresh() (string, error) {
+	sources, err := t.poller.Poll([]string{t.url})
+	if err != nil {
+		return "", err
+	}
+	if len(sources) != 1 {
+		return "", fmt.Errorf("expected 1 source, got %d", len(sources))
+	}
+	if len(sources[0].Calls) != 2 {
+		return "", fmt.Errorf("expected 2 calls in the source, got %d", len(sources[0].Calls))
+	}
+	t.sources = sources
+	return fmt.Sprintf("fetched and parsed %d calls from %s", len(sources[0].Calls), t.url), nil
+}
+
+// schedule expands the source into the scheduled calls of the datastore.
+func (t *test) schedule() (string, error) {
+	if err := t.scheduler.RefreshSchedule(t.sources, time.Now(), 24*time.Hour, 24*time.Hour); err != nil {
+		return "", err
+	}
+	calls, err := t.store.ListScheduledCalls()
+	if err != nil {
+		return "", err
+	}
+	if len(calls) != 2 {
+		return "", fmt.Errorf("expected 2 scheduled calls, got %d", len(calls))
+	}
+	for _, call := range calls {
+		if !call.ScheduledAt.Equal(t.scheduledAt) {
+			return "", fmt.Errorf("call '%s' is scheduled at %s, expected %s", call.ID, call.ScheduledAt, t.scheduledAt)
+		}
+	}
+	return fmt.Sprintf("scheduled %d calls at %s", len(calls), t.scheduledAt.Format(time.RFC3339)), nil
+}
+
+// dispatch sends the calls that are due.
+func (t *test) dispatch() (string, error) {
+	if err := t.worker.ProcessMessages(context.Background()); err != nil {
+		return "", err
+	}
+	calls, err := t.store.ListScheduledCalls()
+	if err != nil {
+		return "", err
+	}
+	if len(calls) != 0 {
+		return "", fmt.Errorf("expected no calls left to send, got %d", len(calls))
+	}
+	return "sent every call that was due", nil
+}
+
+// checkSlack checks the message received by the Slack stand-in.
+func (t *test) checkSlack() (string, error) {
+	posts := t.slack.Posts()
+	if len(posts) != 1 {
+		return "", fmt.Errorf("expected 1 message, got %d", len(posts))
+	}
+	if posts[0].Channel != slackChannelID {
+		return "", fmt.Errorf("expected the message in channel %s, got %s", slackChannelID, posts[0].Channel)
+	}
+	if want := "Hello from *ruf*, through Slack."; !strings.Contains(posts[0].Text, want) {
+		return "", fmt.Errorf("expected the message to contain %q, got %q", want, posts[0].Text)
+	}
+	return fmt.Sprintf("received the message in #%s", slackChannel), nil
+}
+
+// checkEmail checks the message received by the SMTP stand-in.
+func (t *test) checkEmail() (string, error) {
+	messages := t.smtp.Messages()
+	if len(messages) != 1 {
+		return "", fmt.Errorf("expected 1 message, got %d", len(messages))
+	}
+	if len(messages[0].To) != 1 || messages[0].To[0] != emailRecipient {
+		return "", fmt.Errorf("expected the message to be sent to %s, got %v", emailRecipient, messages[0].To)
+	}
+	if want := "<strong>ruf</strong>, through email."; !strings.Contains(messages[0].Data, want) {
+		return "", fmt.Errorf("expected the message to contain %q", want)
+	}
+	return fmt.Sprintf("received the message for %s", emailRecipient), nil
+}
+
+// checkDatastore checks that both calls were recorded as sent.
+func (t *test) checkDatastore() (string, error) {
+	messages, err := t.store.ListSentMessagesByCampaign(campaignID)
+	if err != nil {
+		return "", err
+	}
+	if len(messages) != 2 {
+		return "", fmt.Errorf("expected 2 sent messages, got %d", len(messages))
+	}
+	for _, sm := range messages {
+		if sm.Status != kv.StatusSent {
+			return "", fmt.Errorf("expected message '%s' to be %s, got %s: %s", sm.ID, kv.StatusSent, sm.Status, sm.Error)
+		}
+	}
+	return fmt.Sprintf("recorded %d messages as sent", len(messages)), nil
+}
+
+// checkDeduplication checks that refreshing the schedule again does not send the calls twice.
+func (t *test) checkDeduplication() (string, error) {
+	if _, err := t.schedule(); err != nil {
+		return "", err
+	}
+	if err := t.worker.ProcessMessages(context.Background()); err != nil {
+		return "", err
+	}
+	if posts, messages := len(t.slack.Posts()), len(t.smtp.Messages()); posts != 1 || messages != 1 {
+		return "", fmt.Errorf("expected no more messages, got %d in Slack and %d by email", posts, messages)
+	}
+	return "did not send the calls again", nil
+}
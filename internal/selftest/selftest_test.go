@@ -0,0 +1,28 @@
+package selftest_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/andrewhowdencom/ruf/internal/selftest"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRun(t *testing.T) {
+	t.Cleanup(viper.Reset)
+	viper.Set("worker.missed_lookback", "24h")
+	viper.Set("worker.calculation.before", "24h")
+	viper.Set("worker.calculation.after", "168h")
+
+	report, err := selftest.Run()
+	assert.NoError(t, err)
+
+	var out bytes.Buffer
+	report.Write(&out)
+	assert.True(t, report.Passed(), out.String())
+	assert.NoError(t, report.Err())
+	assert.Len(t, report.Checks, 7)
+	assert.Contains(t, out.String(), "PASS  slack: received the message in #selftest")
+	assert.Contains(t, out.String(), "Self test passed.")
+}
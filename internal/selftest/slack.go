@@ -0,0 +1,70 @@
+package selftest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+)
+
+// slackChannelID is the ID of the only channel of the Slack server, named after the channel the calls
+// of the source are sent to.
+const slackChannelID = "C0SELFTEST"
+
+// post is a message posted to the Slack server.
+type post struct {
+	Channel string
+	Text    string
+}
+
+// slackServer stands in for the parts of the Slack Web API the client uses to post a message to a
+// channel.
+type slackServer struct {
+	*httptest.Server
+
+	mu    sync.Mutex
+	posts []post
+}
+
+func newSlackServer(channel string) *slackServer {
+	s := &slackServer{}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/conversations.list", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, map[string]any{
+			"ok":                true,
+			"channels":          []map[string]any{{"id": slackChannelID, "name": channel, "is_member": true}},
+			"response_metadata": map[string]any{"next_cursor": ""},
+		})
+	})
+	mux.HandleFunc("/chat.postMessage", func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		s.posts = append(s.posts, post{Channel: r.FormValue("channel"), Text: r.FormValue("text")})
+		s.mu.Unlock()
+		writeJSON(w, map[string]any{"ok": true, "channel": r.FormValue("channel"), "ts": "1700000000.000100"})
+	})
+	mux.HandleFunc("/chat.getPermalink", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, map[string]any{
+			"ok":        true,
+			"channel":   r.FormValue("channel"),
+			"permalink": "https://selftest.slack.com/archives/" + r.FormValue("channel") + "/p1700000000000100",
+		})
+	})
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, map[string]any{"ok": false, "error": "unknown_method"})
+	})
+	s.Server = httptest.NewServer(mux)
+	return s
+}
+
+// Posts returns the messages posted so far.
+func (s *slackServer) Posts() []post {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]post(nil), s.posts...)
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
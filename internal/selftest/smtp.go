@@ -0,0 +1,129 @@
+package selftest
+
+import (
+	"net"
+	"net/textproto"
+	"strings"
+	"sync"
+)
+
+// mail is a message received by the SMTP server.
+type mail struct {
+	From string
+	To   []string
+	Data string
+}
+
+// smtpServer is a minimal SMTP server that accepts every message it is sent, and keeps it to be
+// inspected.
+type smtpServer struct {
+	listener net.Listener
+	wg       sync.WaitGroup
+
+	mu       sync.Mutex
+	messages []mail
+}
+
+// newSMTPServer starts an SMTP server listening on a random port of the loopback interface.
+func newSMTPServer() (*smtpServer, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+
+	s := &smtpServer{listener: listener}
+	s.wg.Add(1)
+	go s.serve()
+	return s, nil
+}
+
+// Port returns the port the server listens on.
+func (s *smtpServer) Port() int {
+	return s.listener.Addr().(*net.TCPAddr).Port
+}
+
+// Messages returns the messages received so far.
+func (s *smtpServer) Messages() []mail {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]mail(nil), s.messages...)
+}
+
+// Close stops the server, and waits for the open connections to finish.
+func (s *smtpServer) Close() {
+	s.listener.Close()
+	s.wg.Wait()
+}
+
+func (s *smtpServer) serve() {
+	defer s.wg.Done()
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			s.handle(textproto.NewConn(conn))
+		}()
+	}
+}
+
+func (s *smtpServer) handle(conn *textproto.Conn) {
+	defer conn.Close()
+
+	// AUTH is advertised, but not STARTTLS: the client only authenticates in plain text with a server
+	// on localhost.
+	conn.PrintfLine("220 localhost ruf selftest")
+	var m mail
+	for {
+		line, err := conn.ReadLine()
+		if err != nil {
+			return
+		}
+		verb, arg, _ := strings.Cut(line, " ")
+		switch strings.ToUpper(verb) {
+		case "EHLO":
+			conn.PrintfLine("250-localhost")
+			conn.PrintfLine("250 AUTH PLAIN")
+		case "HELO", "NOOP":
+			conn.PrintfLine("250 OK")
+		case "RSET":
+			m = mail{}
+			conn.PrintfLine("250 OK")
+		case "AUTH":
+			conn.PrintfLine("235 Authentication successful")
+		case "MAIL":
+			m = mail{From: address(arg)}
+			conn.PrintfLine("250 OK")
+		case "RCPT":
+			m.To = append(m.To, address(arg))
+			conn.PrintfLine("250 OK")
+		case "DATA":
+			conn.PrintfLine("354 End data with <CR><LF>.<CR><LF>")
+			data, err := conn.ReadDotBytes()
+			if err != nil {
+				return
+			}
+			m.Data = string(data)
+			s.mu.Lock()
+			s.messages = append(s.messages, m)
+			s.mu.Unlock()
+			m = mail{}
+			conn.PrintfLine("250 OK")
+		case "QUIT":
+			conn.PrintfLine("221 Bye")
+			return
+		default:
+			conn.PrintfLine("502 Command not implemented")
+		}
+	}
+}
+
+// address returns the address of a MAIL FROM or RCPT TO argument, such as FROM:<jane@example.com>.
+func address(arg string) string {
+	_, addr, _ := strings.Cut(arg, ":")
+	addr, _, _ = strings.Cut(strings.TrimSpace(addr), " ")
+	return strings.Trim(addr, "<>")
+}
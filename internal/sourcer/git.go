@@ -1,127 +1,379 @@
 package sourcer
 
 import (
+	"crypto/sha256"
+	"errors"
 	"fmt"
 	"io"
-	"io/ioutil"
 	"net/url"
 	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
 	"strings"
+	"sync"
 
 	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
 	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/transport"
 	"github.com/go-git/go-git/v5/plumbing/transport/http"
 	"github.com/spf13/viper"
 )
 
-// GitFetcher is an implementation of Fetcher that fetches content from a git repository.
-type GitFetcher struct{}
+// ErrIsDirectory is returned when a single file is fetched from a URL that selects a directory.
+var ErrIsDirectory = errors.New("url selects a directory")
+
+// defaultGitUsername is the username a token is sent with when none is configured for the host. It is
+// what GitHub expects of an access token; other hosts ignore it.
+const defaultGitUsername = "x-access-token"
+
+// commitHash matches a full commit hash, which is checked out rather than looked up as a branch or tag.
+var commitHash = regexp.MustCompile(`^[0-9a-f]{40}$`)
+
+// GitFetcher is an implementation of Fetcher that fetches content from a git repository. It accepts:
+//
+//	git://<host>/<owner>/<repo>/tree/<ref>/<file>
+//	git://<host>/<owner>/<repo>?ref=<ref>&path=<file or directory>
+//	git+https://<host>/<path/to/repo>.git?ref=<ref>&path=<file or directory>
+//
+// as well as git+http and git+file. Without a ref, the default branch of the repository is used, and
+// without a path, the whole repository is selected. The state of a fetch is the hash of the commit it
+// was read from.
+type GitFetcher struct {
+	cacheDir string
+
+	// mu serialises fetches, so that a clone is never updated by two of them at once.
+	mu sync.Mutex
+}
+
+// GitOption configures a GitFetcher.
+type GitOption func(*GitFetcher)
+
+// WithCacheDir keeps a clone of each repository in the directory, which is updated with only what
+// changed on each fetch. Without one, every fetch clones the repository afresh.
+func WithCacheDir(dir string) GitOption {
+	return func(f *GitFetcher) {
+		f.cacheDir = dir
+	}
+}
 
 // NewGitFetcher creates a new GitFetcher.
-func NewGitFetcher() *GitFetcher {
-	return &GitFetcher{}
+func NewGitFetcher(opts ...GitOption) *GitFetcher {
+	f := &GitFetcher{}
+	for _, opt := range opts {
+		opt(f)
+	}
+	return f
 }
 
-// Fetch fetches the content of a URL and returns it as a byte slice.
-func (f *GitFetcher) Fetch(rawURL string) ([]byte, string, error) {
+// gitSource is what a git URL selects: a path at a ref of a repository.
+type gitSource struct {
+	cloneURL string
+	host     string
+	ref      string
+	path     string
+}
+
+// parseGitURL reads the repository, ref and path a git URL selects.
+func parseGitURL(rawURL string) (gitSource, error) {
 	u, err := url.Parse(rawURL)
 	if err != nil {
-		return nil, "", fmt.Errorf("failed to parse url %s: %w", rawURL, err)
+		return gitSource{}, fmt.Errorf("failed to parse url %s: %w", rawURL, err)
 	}
+	src := gitSource{host: u.Host, ref: u.Query().Get("ref"), path: strings.Trim(u.Query().Get("path"), "/")}
 
-	// The path is in the format /<user>/<repo>/tree/<ref>/<path/to/file>
-	path := strings.TrimPrefix(u.Path, "/")
-	pathParts := strings.SplitN(path, "/", 5)
-	if len(pathParts) < 5 {
-		return nil, "", fmt.Errorf("invalid git url path: %s. Expected /<user>/<repo>/tree/<ref>/<file>", u.Path)
+	switch {
+	case u.Scheme == "git" && u.RawQuery == "" && strings.Contains(u.Path, "/tree/"):
+		// The path is in the format /<user>/<repo>/tree/<ref>/<path/to/file>
+		pathParts := strings.SplitN(strings.TrimPrefix(u.Path, "/"), "/", 5)
+		if len(pathParts) < 5 || pathParts[2] != "tree" {
+			return gitSource{}, fmt.Errorf("invalid git url path: %s. Expected /<user>/<repo>/tree/<ref>/<file>", u.Path)
+		}
+		src.cloneURL = fmt.Sprintf("https://%s/%s/%s.git", u.Host, pathParts[0], pathParts[1])
+		src.ref, src.path = pathParts[3], pathParts[4]
+	case u.Scheme == "git":
+		repo := strings.Trim(u.Path, "/")
+		if strings.Count(repo, "/") < 1 {
+			return gitSource{}, fmt.Errorf("invalid git url path: %s. Expected /<owner>/<repo>", u.Path)
+		}
+		src.cloneURL = fmt.Sprintf("https://%s/%s", u.Host, repo)
+		if !strings.HasSuffix(repo, ".git") {
+			src.cloneURL += ".git"
+		}
+	case strings.HasPrefix(u.Scheme, "git+"):
+		clone := *u
+		clone.Scheme = strings.TrimPrefix(u.Scheme, "git+")
+		clone.RawQuery = ""
+		clone.Fragment = ""
+		src.cloneURL = clone.String()
+	default:
+		return gitSource{}, fmt.Errorf("unsupported git url scheme: %s", u.Scheme)
 	}
-	user := pathParts[0]
-	repo := pathParts[1]
-	// pathParts[2] is "tree"
-	ref := pathParts[3]
-	filePath := pathParts[4]
+	return src, nil
+}
 
-	cloneURL := fmt.Sprintf("https://%s/%s/%s.git", u.Host, user, repo)
+// GitURLAtRef returns a git URL that selects the same path of the same repository as the given git URL,
+// at another ref.
+func GitURLAtRef(rawURL, ref string) (string, error) {
+	src, err := parseGitURL(rawURL)
+	if err != nil {
+		return "", err
+	}
+	query := url.Values{"ref": {ref}}
+	if src.path != "" {
+		query.Set("path", src.path)
+	}
+	return "git+" + src.cloneURL + "?" + query.Encode(), nil
+}
 
-	// Create a temporary directory
-	dir, err := ioutil.TempDir("", "ruf-git-sourcer")
+// auth returns the credentials for the host: the token under git.tokens, or the username and token
+// under git.auth.
+func (src gitSource) auth() transport.AuthMethod {
+	username := viper.GetString(fmt.Sprintf("git.auth.%s.username", src.host))
+	token := viper.GetString(fmt.Sprintf("git.auth.%s.token", src.host))
+	if t, ok := viper.GetStringMapString("git.tokens")[strings.ToLower(src.host)]; ok && token == "" {
+		token = t
+	}
+	if token == "" {
+		return nil
+	}
+	if username == "" {
+		username = defaultGitUsername
+	}
+	return &http.BasicAuth{Username: username, Password: token}
+}
+
+// Fetch fetches the file a URL selects, returning the hash of the commit it was read from as its state.
+func (f *GitFetcher) Fetch(rawURL string) ([]byte, string, error) {
+	files, state, err := f.fetch(rawURL, false)
 	if err != nil {
-		return nil, "", fmt.Errorf("failed to create temporary directory: %w", err)
+		return nil, "", err
 	}
-	defer os.RemoveAll(dir)
+	return files[0].Data, state, nil
+}
 
-	cloneOptions := &git.CloneOptions{
-		URL:          cloneURL,
-		SingleBranch: true,
-		Depth:        1,
+// FetchTree fetches the file a URL selects, or every YAML file under the directory it selects,
+// returning the hash of the commit they were read from as their state.
+func (f *GitFetcher) FetchTree(rawURL string) ([]File, string, error) {
+	return f.fetch(rawURL, true)
+}
+
+func (f *GitFetcher) fetch(rawURL string, directories bool) ([]File, string, error) {
+	src, err := parseGitURL(rawURL)
+	if err != nil {
+		return nil, "", err
 	}
 
-	username := viper.GetString(fmt.Sprintf("git.auth.%s.username", u.Host))
-	token := viper.GetString(fmt.Sprintf("git.auth.%s.token", u.Host))
-	if token != "" {
-		cloneOptions.Auth = &http.BasicAuth{
-			Username: username,
-			Password: token,
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	dir := ""
+	if f.cacheDir != "" {
+		hash := sha256.Sum256([]byte(src.cloneURL))
+		dir = filepath.Join(f.cacheDir, fmt.Sprintf("%x", hash[:8]))
+	} else {
+		if dir, err = os.MkdirTemp("", "ruf-git-sourcer"); err != nil {
+			return nil, "", fmt.Errorf("failed to create temporary directory: %w", err)
 		}
+		defer os.RemoveAll(dir)
 	}
 
-	// Determine if the ref is a branch, tag, or commit hash
-	if len(ref) == 40 {
-		// This is a commit hash
-		cloneOptions.ReferenceName = plumbing.HEAD
-	} else {
-		// Try as a branch first
-		cloneOptions.ReferenceName = plumbing.NewBranchReferenceName(ref)
+	r, err := openRepository(dir, src.cloneURL)
+	if err != nil {
+		return nil, "", err
+	}
+	commit, err := resolveCommit(r, src)
+	if err != nil {
+		return nil, "", err
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read tree of commit %s in repo %s: %w", commit.Hash, src.cloneURL, err)
 	}
 
-	r, err := git.PlainClone(dir, false, cloneOptions)
+	files, err := readTree(tree, src, rawURL, directories)
 	if err != nil {
-		// If it failed, try as a tag (and it wasn't a commit hash)
-		if len(ref) != 40 {
-			cloneOptions.ReferenceName = plumbing.NewTagReferenceName(ref)
-			r, err = git.PlainClone(dir, false, cloneOptions)
-			if err != nil {
-				return nil, "", fmt.Errorf("failed to clone repo %s with ref %s (tried as branch and tag): %w", cloneURL, ref, err)
-			}
-		} else {
-			return nil, "", fmt.Errorf("failed to clone repo %s with commit %s: %w", cloneURL, ref, err)
-		}
+		return nil, "", err
 	}
+	return files, commit.Hash.String(), nil
+}
 
-	if len(ref) == 40 {
-		w, err := r.Worktree()
-		if err != nil {
-			return nil, "", fmt.Errorf("failed to get worktree: %w", err)
+// openRepository opens the bare clone in dir, or starts one if there is none.
+func openRepository(dir, cloneURL string) (*git.Repository, error) {
+	r, err := git.PlainOpen(dir)
+	if err == nil {
+		return r, nil
+	}
+	if !errors.Is(err, git.ErrRepositoryNotExists) {
+		return nil, fmt.Errorf("failed to open clone of repo %s: %w", cloneURL, err)
+	}
+	if r, err = git.PlainInit(dir, true); err != nil {
+		return nil, fmt.Errorf("failed to create clone of repo %s: %w", cloneURL, err)
+	}
+	if _, err := r.CreateRemote(&config.RemoteConfig{Name: git.DefaultRemoteName, URLs: []string{cloneURL}}); err != nil {
+		return nil, fmt.Errorf("failed to create clone of repo %s: %w", cloneURL, err)
+	}
+	return r, nil
+}
+
+// resolveCommit fetches what the clone is missing of the ref, and returns the commit it points to. A
+// branch or tag is looked up on the remote, so that the clone follows it as it moves.
+func resolveCommit(r *git.Repository, src gitSource) (*object.Commit, error) {
+	remote, err := r.Remote(git.DefaultRemoteName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find remote of repo %s: %w", src.cloneURL, err)
+	}
+	auth := src.auth()
+
+	if commitHash.MatchString(src.ref) {
+		hash := plumbing.NewHash(src.ref)
+		if _, err := r.CommitObject(hash); err != nil {
+			// Arbitrary commits cannot be fetched on their own, so every branch is fetched in full.
+			err := remote.Fetch(&git.FetchOptions{
+				RefSpecs: []config.RefSpec{"+refs/heads/*:refs/remotes/origin/*"},
+				Auth:     auth,
+				Force:    true,
+			})
+			if err != nil && !errors.Is(err, git.NoErrAlreadyUpToDate) {
+				return nil, fmt.Errorf("failed to fetch repo %s: %w", src.cloneURL, err)
+			}
 		}
-		err = w.Checkout(&git.CheckoutOptions{
-			Hash: plumbing.NewHash(ref),
-		})
+		commit, err := r.CommitObject(hash)
 		if err != nil {
-			return nil, "", fmt.Errorf("failed to checkout commit %s: %w", ref, err)
+			return nil, fmt.Errorf("failed to find commit %s in repo %s: %w", src.ref, src.cloneURL, err)
 		}
+		return commit, nil
 	}
 
-	head, err := r.Head()
+	refs, err := remote.List(&git.ListOptions{Auth: auth})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list refs of repo %s: %w", src.cloneURL, err)
+	}
+	name, err := findRef(refs, src.ref)
 	if err != nil {
-		return nil, "", fmt.Errorf("failed to get head for repo %s: %w", cloneURL, err)
+		return nil, fmt.Errorf("repo %s: %w", src.cloneURL, err)
 	}
-	hash := head.Hash().String()
 
-	w, err := r.Worktree()
+	local := plumbing.ReferenceName("refs/remotes/origin/" + name.Short())
+	if name.IsTag() {
+		local = name
+	}
+	err = remote.Fetch(&git.FetchOptions{
+		RefSpecs: []config.RefSpec{config.RefSpec(fmt.Sprintf("+%s:%s", name, local))},
+		Depth:    1,
+		Auth:     auth,
+		Tags:     git.NoTags,
+		Force:    true,
+	})
+	if err != nil && !errors.Is(err, git.NoErrAlreadyUpToDate) {
+		return nil, fmt.Errorf("failed to fetch %s of repo %s: %w", name.Short(), src.cloneURL, err)
+	}
+
+	ref, err := r.Reference(local, true)
 	if err != nil {
-		return nil, "", fmt.Errorf("failed to get worktree for repo %s: %w", cloneURL, err)
+		return nil, fmt.Errorf("failed to find %s in repo %s: %w", name.Short(), src.cloneURL, err)
+	}
+	hash := ref.Hash()
+	if tag, err := r.TagObject(hash); err == nil {
+		// An annotated tag points to the commit through the tag object.
+		commit, err := tag.Commit()
+		if err != nil {
+			return nil, fmt.Errorf("failed to find commit of tag %s in repo %s: %w", name.Short(), src.cloneURL, err)
+		}
+		return commit, nil
+	}
+	commit, err := r.CommitObject(hash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find commit of %s in repo %s: %w", name.Short(), src.cloneURL, err)
+	}
+	return commit, nil
+}
+
+// findRef returns the full name of a ref on the remote: a branch, then a tag, of the given name, or
+// the default branch if the name is empty.
+func findRef(refs []*plumbing.Reference, ref string) (plumbing.ReferenceName, error) {
+	byName := make(map[plumbing.ReferenceName]*plumbing.Reference, len(refs))
+	for _, r := range refs {
+		byName[r.Name()] = r
+	}
+	if ref == "" {
+		if head, ok := byName[plumbing.HEAD]; ok && head.Type() == plumbing.SymbolicReference {
+			return head.Target(), nil
+		}
+		return "", fmt.Errorf("could not find the default branch; set a ref")
+	}
+	for _, name := range []plumbing.ReferenceName{plumbing.NewBranchReferenceName(ref), plumbing.NewTagReferenceName(ref)} {
+		if _, ok := byName[name]; ok {
+			return name, nil
+		}
+	}
+	return "", fmt.Errorf("could not find a branch or tag named %s", ref)
+}
+
+// readTree reads the file the source selects from the tree of a commit, or, if directories are
+// allowed, every YAML file under the directory it selects, in order of their path.
+func readTree(tree *object.Tree, src gitSource, rawURL string, directories bool) ([]File, error) {
+	if src.path != "" {
+		if file, err := tree.File(src.path); err == nil {
+			data, err := readFile(file)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read file '%s' in repo %s: %w", src.path, src.cloneURL, err)
+			}
+			return []File{{URL: rawURL, Data: data}}, nil
+		}
+	}
+
+	dir := tree
+	if src.path != "" {
+		var err error
+		if dir, err = tree.Tree(src.path); err != nil {
+			return nil, fmt.Errorf("failed to open file '%s' in repo %s: %w", src.path, src.cloneURL, err)
+		}
+	}
+	if !directories {
+		return nil, fmt.Errorf("%w: '%s' in repo %s", ErrIsDirectory, src.path, src.cloneURL)
 	}
 
-	file, err := w.Filesystem.Open(filePath)
+	var files []File
+	err := dir.Files().ForEach(func(file *object.File) error {
+		if ext := path.Ext(file.Name); ext != ".yaml" && ext != ".yml" {
+			return nil
+		}
+		data, err := readFile(file)
+		if err != nil {
+			return fmt.Errorf("failed to read file '%s' in repo %s: %w", path.Join(src.path, file.Name), src.cloneURL, err)
+		}
+		files = append(files, File{URL: fileURL(rawURL, path.Join(src.path, file.Name)), Data: data})
+		return nil
+	})
 	if err != nil {
-		return nil, "", fmt.Errorf("failed to open file '%s' in repo %s: %w", filePath, cloneURL, err)
+		return nil, err
 	}
-	defer file.Close()
+	sort.Slice(files, func(i, j int) bool { return files[i].URL < files[j].URL })
+	return files, nil
+}
 
-	data, err := io.ReadAll(file)
+func readFile(file *object.File) ([]byte, error) {
+	reader, err := file.Reader()
 	if err != nil {
-		return nil, "", fmt.Errorf("failed to read file '%s' in repo %s: %w", filePath, cloneURL, err)
+		return nil, err
 	}
+	defer reader.Close()
+	return io.ReadAll(reader)
+}
 
-	return data, hash, nil
+// fileURL returns the URL of a file under the directory a URL selects, so that each file read from
+// the directory is named, and its campaign derived, as if it had been selected on its own.
+func fileURL(rawURL, file string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	q := u.Query()
+	q.Set("path", file)
+	u.RawQuery = q.Encode()
+	return u.String()
 }
@@ -1,10 +1,18 @@
 package sourcer
 
 import (
-	"github.com/spf13/viper"
+	"os"
+	"os/exec"
+	"path/filepath"
 	"testing"
+	"time"
 
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/spf13/viper"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestGitFetcher(t *testing.T) {
@@ -36,3 +44,133 @@ func TestGitFetcher(t *testing.T) {
 		assert.Contains(t, err.Error(), "authentication required")
 	})
 }
+
+// commitFiles writes the files to the worktree of the repository and commits them.
+func commitFiles(t *testing.T, r *git.Repository, dir string, files map[string]string) plumbing.Hash {
+	t.Helper()
+	w, err := r.Worktree()
+	require.NoError(t, err)
+	for name, content := range files {
+		require.NoError(t, os.MkdirAll(filepath.Join(dir, filepath.Dir(name)), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0644))
+		_, err := w.Add(name)
+		require.NoError(t, err)
+	}
+	hash, err := w.Commit("update", &git.CommitOptions{Author: &object.Signature{Name: "ruf", Email: "ruf@example.com", When: time.Now()}})
+	require.NoError(t, err)
+	return hash
+}
+
+func TestGitFetcher_Local(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("fetching from a local repository requires git")
+	}
+
+	repoDir := t.TempDir()
+	r, err := git.PlainInitWithOptions(repoDir, &git.PlainInitOptions{
+		InitOptions: git.InitOptions{DefaultBranch: plumbing.NewBranchReferenceName("main")},
+	})
+	require.NoError(t, err)
+	first := commitFiles(t, r, repoDir, map[string]string{
+		"calls/standup.yaml":   "calls: []\n",
+		"calls/team/sync.yaml": "calls: []\n",
+		"calls/README.md":      "Not a source.\n",
+		"other.yaml":           "calls: []\n",
+	})
+	_, err = r.CreateTag("v1", first, nil)
+	require.NoError(t, err)
+
+	base := "git+file://" + repoDir
+	fetcher := NewGitFetcher(WithCacheDir(t.TempDir()))
+
+	t.Run("file on the default branch", func(t *testing.T) {
+		data, state, err := fetcher.Fetch(base + "?path=other.yaml")
+		assert.NoError(t, err)
+		assert.Equal(t, "calls: []\n", string(data))
+		assert.Equal(t, first.String(), state)
+	})
+
+	t.Run("directory", func(t *testing.T) {
+		files, state, err := fetcher.FetchTree(base + "?ref=main&path=calls/")
+		assert.NoError(t, err)
+		assert.Equal(t, first.String(), state)
+		var urls []string
+		for _, f := range files {
+			urls = append(urls, f.URL)
+		}
+		assert.Equal(t, []string{
+			base + "?path=calls%2Fstandup.yaml&ref=main",
+			base + "?path=calls%2Fteam%2Fsync.yaml&ref=main",
+		}, urls)
+
+		_, _, err = fetcher.Fetch(base + "?path=calls")
+		assert.ErrorIs(t, err, ErrIsDirectory)
+	})
+
+	t.Run("follows the branch as it moves", func(t *testing.T) {
+		second := commitFiles(t, r, repoDir, map[string]string{"other.yaml": "calls: [] # changed\n"})
+		data, state, err := fetcher.Fetch(base + "?ref=main&path=other.yaml")
+		assert.NoError(t, err)
+		assert.Equal(t, "calls: [] # changed\n", string(data))
+		assert.Equal(t, second.String(), state)
+
+		// A tag or commit stays where it was.
+		data, state, err = fetcher.Fetch(base + "?ref=v1&path=other.yaml")
+		assert.NoError(t, err)
+		assert.Equal(t, "calls: []\n", string(data))
+		assert.Equal(t, first.String(), state)
+
+		_, state, err = NewGitFetcher().Fetch(base + "?ref=" + first.String() + "&path=other.yaml")
+		assert.NoError(t, err)
+		assert.Equal(t, first.String(), state)
+	})
+
+	t.Run("unknown ref", func(t *testing.T) {
+		_, _, err := fetcher.Fetch(base + "?ref=missing&path=other.yaml")
+		assert.ErrorContains(t, err, "could not find a branch or tag named missing")
+	})
+}
+
+func TestParseGitURL(t *testing.T) {
+	for _, tt := range []struct {
+		url  string
+		want gitSource
+	}{
+		{
+			url:  "git://github.com/org/repo/tree/main/calls/standup.yaml",
+			want: gitSource{cloneURL: "https://github.com/org/repo.git", host: "github.com", ref: "main", path: "calls/standup.yaml"},
+		},
+		{
+			url:  "git://github.com/org/repo?ref=release&path=calls/",
+			want: gitSource{cloneURL: "https://github.com/org/repo.git", host: "github.com", ref: "release", path: "calls"},
+		},
+		{
+			url:  "git+https://gitlab.example.com/group/sub/repo.git?path=calls.yaml",
+			want: gitSource{cloneURL: "https://gitlab.example.com/group/sub/repo.git", host: "gitlab.example.com", path: "calls.yaml"},
+		},
+	} {
+		got, err := parseGitURL(tt.url)
+		assert.NoError(t, err, tt.url)
+		assert.Equal(t, tt.want, got, tt.url)
+	}
+}
+
+func TestGitURLAtRef(t *testing.T) {
+	for url, want := range map[string]string{
+		"git://github.com/org/repo/tree/main/calls/standup.yaml": "git+https://github.com/org/repo.git?path=calls%2Fstandup.yaml&ref=v2",
+		"git://github.com/org/repo?ref=release&path=calls/":      "git+https://github.com/org/repo.git?path=calls&ref=v2",
+		"git+file:///srv/repo.git":                               "git+file:///srv/repo.git?ref=v2",
+	} {
+		got, err := GitURLAtRef(url, "v2")
+		assert.NoError(t, err, url)
+		assert.Equal(t, want, got, url)
+
+		// The URL selects what it was given, at the ref.
+		src, err := parseGitURL(got)
+		assert.NoError(t, err, got)
+		assert.Equal(t, "v2", src.ref, got)
+	}
+
+	_, err := GitURLAtRef("https://example.com/calls.yaml", "v2")
+	assert.Error(t, err)
+}
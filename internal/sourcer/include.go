@@ -0,0 +1,206 @@
+package sourcer
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/ghodss/yaml"
+)
+
+// ErrIncludeCycle is returned when a source file includes itself, directly or through the files it
+// includes.
+var ErrIncludeCycle = errors.New("include cycle")
+
+// includeKey is the top-level key of a source file that lists the files it includes.
+const includeKey = "include"
+
+// includes resolves the include directive of a YAML source file, if it has one. Each included file is
+// fetched, relative to the file that includes it, and its YAML anchors can be referred to by the aliases
+// of the including file, so that destination lists, data and triggers can be shared between files:
+//
+//	include:
+//	  - shared/channels.yaml
+//	calls:
+//	  - id: "standup"
+//	    destinations: *all-channels
+//
+// Included files may include others. The returned document is the file with its aliases resolved and
+// without the directive, and the returned state folds in the state of each included file, so that it
+// never matches that of the file alone and the file is always read again. Each document of a stream of
+// them has its own directive.
+func (s *sourcer) includes(rawURL string, data []byte, state string) ([]byte, string, error) {
+	docs := splitDocuments(data)
+	if len(docs) <= 1 {
+		return s.includeDocument(rawURL, data, state)
+	}
+
+	resolved := make([]string, len(docs))
+	var states []string
+	for i, doc := range docs {
+		// The state of each document is folded in once all of them are resolved.
+		data, docState, err := s.includeDocument(rawURL, doc, "")
+		if err != nil {
+			return nil, "", err
+		}
+		resolved[i] = string(data)
+		if docState != "" {
+			states = append(states, docState)
+		}
+	}
+	if len(states) == 0 {
+		return data, state, nil
+	}
+	return []byte(strings.Join(resolved, "\n---\n")), fmt.Sprintf("%x", sha256.Sum256([]byte(state+strings.Join(states, "")))), nil
+}
+
+// includeDocument resolves the include directive of a single YAML document.
+func (s *sourcer) includeDocument(rawURL string, data []byte, state string) ([]byte, string, error) {
+	composed, states, err := s.compose(rawURL, data, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	if len(states) == 0 {
+		return data, state, nil
+	}
+
+	// Resolving the aliases of the composed document leaves the included files under the directive,
+	// which is then removed.
+	jsonData, err := yaml.YAMLToJSON(composed)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to resolve includes of %s: %w", rawURL, err)
+	}
+	var doc map[string]json.RawMessage
+	if err := json.Unmarshal(jsonData, &doc); err != nil {
+		return nil, "", fmt.Errorf("failed to resolve includes of %s: %w", rawURL, err)
+	}
+	delete(doc, includeKey)
+	resolved, err := json.Marshal(doc)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to resolve includes of %s: %w", rawURL, err)
+	}
+
+	return resolved, fmt.Sprintf("%x", sha256.Sum256([]byte(state+strings.Join(states, "")))), nil
+}
+
+// compose returns a YAML file with the files it includes placed under its include directive, ahead of
+// the rest of the file, so that their anchors are defined before the file refers to them. parents are
+// the files that include it, to detect cycles. The states of the included files are returned in order.
+func (s *sourcer) compose(rawURL string, data []byte, parents []string) ([]byte, []string, error) {
+	refs, rest, err := splitIncludes(data)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read includes of %s: %w", rawURL, err)
+	}
+	if len(refs) == 0 {
+		return data, nil, nil
+	}
+	parents = append(parents, rawURL)
+
+	var buf bytes.Buffer
+	var states []string
+	buf.WriteString(includeKey + ":\n")
+	for _, ref := range refs {
+		included, err := resolveInclude(rawURL, ref)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, parent := range parents {
+			if parent == included {
+				return nil, nil, fmt.Errorf("%w: %s -> %s", ErrIncludeCycle, strings.Join(parents, " -> "), included)
+			}
+		}
+
+		includedData, state, err := s.fetcher.Fetch(included)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to fetch include %s: %w", included, err)
+		}
+		includedData, nested, err := s.compose(included, includedData, parents)
+		if err != nil {
+			return nil, nil, err
+		}
+		states = append(append(states, state), nested...)
+
+		buf.WriteString("  -\n")
+		for _, line := range strings.Split(strings.TrimRight(string(includedData), "\n"), "\n") {
+			// A file may start with a document marker, which cannot be nested.
+			if strings.TrimSpace(line) == "---" {
+				continue
+			}
+			buf.WriteString("    " + line + "\n")
+		}
+	}
+	buf.Write(rest)
+	return buf.Bytes(), states, nil
+}
+
+// splitIncludes finds the top-level include directive of a YAML file, returning the files it lists and
+// the file without it. The directive is found by its lines, rather than by parsing the file, as the
+// file cannot be parsed until the anchors it refers to are defined.
+func splitIncludes(data []byte) ([]string, []byte, error) {
+	lines := strings.SplitAfter(string(data), "\n")
+	start := -1
+	for i, line := range lines {
+		if strings.HasPrefix(line, includeKey+":") {
+			start = i
+			break
+		}
+	}
+	if start < 0 {
+		return nil, data, nil
+	}
+
+	// The directive runs until the next line that starts another top-level key.
+	end := start + 1
+	for ; end < len(lines); end++ {
+		line := lines[end]
+		if strings.TrimSpace(line) != "" && !strings.HasPrefix(line, " ") && !strings.HasPrefix(line, "-") && !strings.HasPrefix(line, "#") {
+			break
+		}
+	}
+
+	var directive struct {
+		Include json.RawMessage `json:"include"`
+	}
+	if err := yaml.Unmarshal([]byte(strings.Join(lines[start:end], "")), &directive); err != nil {
+		return nil, nil, err
+	}
+	var refs []string
+	if err := json.Unmarshal(directive.Include, &refs); err != nil {
+		var ref string
+		if err := json.Unmarshal(directive.Include, &ref); err != nil {
+			return nil, nil, fmt.Errorf("include must be a URL or a list of URLs")
+		}
+		refs = []string{ref}
+	}
+
+	rest := strings.Join(lines[:start], "") + strings.Join(lines[end:], "")
+	return refs, []byte(rest), nil
+}
+
+// resolveInclude returns the URL of an included file, relative to the URL of the file that includes
+// it. For a URL that selects a file with a path parameter, as git URLs do, the path is resolved instead.
+func resolveInclude(base, ref string) (string, error) {
+	r, err := url.Parse(ref)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse include %s: %w", ref, err)
+	}
+	if r.IsAbs() {
+		return ref, nil
+	}
+	b, err := url.Parse(base)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse url %s: %w", base, err)
+	}
+	if p := b.Query().Get("path"); p != "" {
+		q := b.Query()
+		q.Set("path", strings.TrimPrefix(path.Join(path.Dir("/"+p), ref), "/"))
+		b.RawQuery = q.Encode()
+		return b.String(), nil
+	}
+	return b.ResolveReference(r).String(), nil
+}
@@ -0,0 +1,190 @@
+package sourcer
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// ErrInvalidObjectURL is returned when a URL does not name both a bucket and an object in it.
+var ErrInvalidObjectURL = errors.New("url does not name a bucket and an object")
+
+// objectStore addresses and authorizes the requests that read objects from a bucket.
+type objectStore interface {
+	// objectURL returns the URL that an object of a bucket is read from.
+	objectURL(bucket, key string) string
+	// authorize adds the credentials of the store to a request, once its headers are set.
+	authorize(req *http.Request) error
+}
+
+// ObjectFetcher is an implementation of Fetcher that fetches content from object storage, given as
+// <scheme>://<bucket>/<object>. The state of a fetch is the ETag of the object. The last copy of each
+// object is kept, and only downloaded again once its ETag changes.
+type ObjectFetcher struct {
+	client   *http.Client
+	endpoint string
+	store    objectStore
+
+	mu     sync.Mutex
+	cached map[string]cachedObject
+}
+
+type cachedObject struct {
+	etag string
+	data []byte
+}
+
+// ObjectOption configures an ObjectFetcher.
+type ObjectOption func(*ObjectFetcher)
+
+// WithEndpoint sends requests to the endpoint, such as that of a compatible store or an emulator,
+// rather than that of the provider. Objects are addressed by path under it.
+func WithEndpoint(endpoint string) ObjectOption {
+	return func(f *ObjectFetcher) {
+		f.endpoint = strings.TrimSuffix(endpoint, "/")
+	}
+}
+
+func newObjectFetcher(client *http.Client, opts []ObjectOption) *ObjectFetcher {
+	f := &ObjectFetcher{client: client, cached: make(map[string]cachedObject)}
+	for _, opt := range opts {
+		opt(f)
+	}
+	return f
+}
+
+// Fetch fetches the content of an object and returns it as a byte slice.
+func (f *ObjectFetcher) Fetch(rawURL string) ([]byte, string, error) {
+	f.mu.Lock()
+	cached, ok := f.cached[rawURL]
+	f.mu.Unlock()
+
+	data, etag, err := f.FetchIfModified(rawURL, cached.etag)
+	if ok && errors.Is(err, ErrNotModified) {
+		return cached.data, cached.etag, nil
+	}
+	return data, etag, err
+}
+
+// FetchIfModified fetches the content of an object unless its ETag is still the previous state.
+func (f *ObjectFetcher) FetchIfModified(rawURL, previous string) ([]byte, string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to parse url %s: %w", rawURL, err)
+	}
+	bucket, key := u.Host, strings.TrimPrefix(u.Path, "/")
+	if bucket == "" || key == "" || strings.HasSuffix(key, "/") {
+		return nil, "", fmt.Errorf("%w: %s", ErrInvalidObjectURL, rawURL)
+	}
+
+	req, err := http.NewRequest(http.MethodGet, f.store.objectURL(bucket, key), nil)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to fetch url %s: %w", rawURL, err)
+	}
+	if previous != "" {
+		req.Header.Set("If-None-Match", previous)
+	}
+	if err := f.store.authorize(req); err != nil {
+		return nil, "", fmt.Errorf("failed to authorize request for %s: %w", rawURL, err)
+	}
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to fetch url %s: %w", rawURL, err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case previous != "" && resp.StatusCode == http.StatusNotModified:
+		return nil, previous, fmt.Errorf("%w: %s", ErrNotModified, rawURL)
+	case resp.StatusCode != http.StatusOK:
+		return nil, "", fmt.Errorf("failed to fetch url %s: status code %d", rawURL, resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, "", err
+	}
+	etag := resp.Header.Get("ETag")
+	if etag == "" {
+		return body, fmt.Sprintf("%x", sha256.Sum256(body)), nil
+	}
+
+	f.mu.Lock()
+	f.cached[rawURL] = cachedObject{etag: etag, data: body}
+	f.mu.Unlock()
+	return body, etag, nil
+}
+
+// escapeKey escapes each segment of the name of an object, leaving the slashes between them.
+func escapeKey(key string) string {
+	segments := strings.Split(key, "/")
+	for i, s := range segments {
+		segments[i] = url.PathEscape(s)
+	}
+	return strings.Join(segments, "/")
+}
+
+// NewGCSFetcher creates a fetcher of gs://<bucket>/<object> URLs from Google Cloud Storage. Requests
+// are sent with the client, which is expected to add credentials to them for buckets that are not
+// public.
+func NewGCSFetcher(client *http.Client, opts ...ObjectOption) *ObjectFetcher {
+	f := newObjectFetcher(client, opts)
+	endpoint := f.endpoint
+	if endpoint == "" {
+		endpoint = "https://storage.googleapis.com"
+	}
+	f.store = gcsStore{endpoint: endpoint}
+	return f
+}
+
+type gcsStore struct {
+	endpoint string
+}
+
+func (s gcsStore) objectURL(bucket, key string) string {
+	return s.endpoint + "/" + url.PathEscape(bucket) + "/" + escapeKey(key)
+}
+
+func (s gcsStore) authorize(req *http.Request) error {
+	return nil
+}
+
+// azureBlobVersion is the version of the Blob Storage REST API requests are made with.
+const azureBlobVersion = "2021-08-06"
+
+// NewAzureBlobFetcher creates a fetcher of azblob://<container>/<blob> URLs from an Azure Blob Storage
+// account. Requests are authorized with the shared access signature, if any; without one, only public
+// containers can be read.
+func NewAzureBlobFetcher(client *http.Client, account, sasToken string, opts ...ObjectOption) *ObjectFetcher {
+	f := newObjectFetcher(client, opts)
+	endpoint := f.endpoint
+	if endpoint == "" {
+		endpoint = "https://" + account + ".blob.core.windows.net"
+	}
+	f.store = azureBlobStore{endpoint: endpoint, sasToken: strings.TrimPrefix(sasToken, "?")}
+	return f
+}
+
+type azureBlobStore struct {
+	endpoint string
+	sasToken string
+}
+
+func (s azureBlobStore) objectURL(bucket, key string) string {
+	u := s.endpoint + "/" + url.PathEscape(bucket) + "/" + escapeKey(key)
+	if s.sasToken != "" {
+		u += "?" + s.sasToken
+	}
+	return u
+}
+
+func (s azureBlobStore) authorize(req *http.Request) error {
+	req.Header.Set("x-ms-version", azureBlobVersion)
+	return nil
+}
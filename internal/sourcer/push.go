@@ -0,0 +1,91 @@
+package sourcer
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+)
+
+// Err* are the errors of pushed sources.
+var (
+	ErrInvalidPushName = errors.New("invalid push source name")
+	ErrNotPushed       = errors.New("no content has been pushed")
+)
+
+// pushNamePattern is the pattern the names of pushed sources must match, so that they are safe to
+// use as file names.
+var pushNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// PushFetcher is an implementation of Fetcher that reads sources whose content is pushed to ruf, such
+// as by a CI pipeline, rather than fetched from where it is kept. A source pushed under a name is read
+// as push://<name>. Pushed content is kept as files in a directory, so that it outlives restarts and
+// is shared by the commands run on the same host. The state of a fetch is the hash of its content.
+type PushFetcher struct {
+	dir string
+}
+
+// NewPushFetcher creates a fetcher of the sources pushed to the directory.
+func NewPushFetcher(dir string) *PushFetcher {
+	return &PushFetcher{dir: dir}
+}
+
+// Fetch reads the content last pushed under the name of the URL.
+func (f *PushFetcher) Fetch(rawURL string) ([]byte, string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to parse url %s: %w", rawURL, err)
+	}
+	path, err := f.path(u.Host)
+	if err != nil {
+		return nil, "", err
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, "", fmt.Errorf("%w: %s", ErrNotPushed, rawURL)
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read pushed source %s: %w", rawURL, err)
+	}
+	return data, fmt.Sprintf("%x", sha256.Sum256(data)), nil
+}
+
+// Push replaces the content of the source pushed under a name.
+func (f *PushFetcher) Push(name string, data []byte) error {
+	path, err := f.path(name)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(f.dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create push directory: %w", err)
+	}
+
+	// The content is replaced in one step, so that a poll never reads it half written.
+	tmp, err := os.CreateTemp(f.dir, "."+name+".*")
+	if err != nil {
+		return fmt.Errorf("failed to push source %s: %w", name, err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to push source %s: %w", name, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to push source %s: %w", name, err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to push source %s: %w", name, err)
+	}
+	return nil
+}
+
+func (f *PushFetcher) path(name string) (string, error) {
+	if !pushNamePattern.MatchString(name) {
+		return "", fmt.Errorf("%w: '%s': use letters, digits, '.', '_' and '-'", ErrInvalidPushName, name)
+	}
+	return filepath.Join(f.dir, name), nil
+}
@@ -0,0 +1,30 @@
+package sourcer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPushFetcher(t *testing.T) {
+	fetcher := NewPushFetcher(t.TempDir())
+
+	_, _, err := fetcher.Fetch("push://team-calls")
+	assert.ErrorIs(t, err, ErrNotPushed)
+
+	require.NoError(t, fetcher.Push("team-calls", []byte("calls: []\n")))
+	data, state, err := fetcher.Fetch("push://team-calls")
+	require.NoError(t, err)
+	assert.Equal(t, "calls: []\n", string(data))
+
+	// Pushing new content changes the state, so that the source is read again.
+	require.NoError(t, fetcher.Push("team-calls", []byte("calls: [{}]\n")))
+	_, next, err := fetcher.Fetch("push://team-calls")
+	require.NoError(t, err)
+	assert.NotEqual(t, state, next)
+
+	assert.ErrorIs(t, fetcher.Push("../escape", nil), ErrInvalidPushName)
+	_, _, err = fetcher.Fetch("push://")
+	assert.ErrorIs(t, err, ErrInvalidPushName)
+}
@@ -0,0 +1,254 @@
+package sourcer
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/andrewhowdencom/ruf/internal/model"
+	"github.com/teambition/rrule-go"
+	"google.golang.org/api/option"
+	"google.golang.org/api/sheets/v4"
+)
+
+// ErrInvalidSheetURL is returned when a URL does not name a spreadsheet.
+var ErrInvalidSheetURL = errors.New("url does not name a spreadsheet")
+
+// sheetColumns are the columns a sheet of calls must have, by their header.
+var sheetColumns = []string{"id", "content", "destination", "trigger"}
+
+// sheetTimeLayouts are the layouts a trigger may be given as a time in, in UTC unless it has an offset.
+var sheetTimeLayouts = []string{time.RFC3339, "2006-01-02 15:04:05", "2006-01-02 15:04"}
+
+// SheetsFetcher is an implementation of Fetcher that reads a sheet of a Google Sheets spreadsheet,
+// given as gsheets://<spreadsheet-id>/<sheet>, as CSV. Without a sheet, the first visible sheet of the
+// spreadsheet is read. The state of a fetch is the hash of its content.
+type SheetsFetcher struct {
+	opts []option.ClientOption
+}
+
+// NewSheetsFetcher creates a fetcher of Google Sheets, which reads them with the application default
+// credentials unless the options say otherwise.
+func NewSheetsFetcher(opts ...option.ClientOption) *SheetsFetcher {
+	return &SheetsFetcher{opts: opts}
+}
+
+// Fetch reads the values of a sheet, as they are displayed, and returns them as CSV.
+func (f *SheetsFetcher) Fetch(rawURL string) ([]byte, string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to parse url %s: %w", rawURL, err)
+	}
+	if u.Host == "" {
+		return nil, "", fmt.Errorf("%w: %s", ErrInvalidSheetURL, rawURL)
+	}
+	// A range without a sheet is read from the first visible sheet.
+	readRange := "A:Z"
+	if sheet := strings.TrimPrefix(u.Path, "/"); sheet != "" {
+		readRange = "'" + strings.ReplaceAll(sheet, "'", "''") + "'"
+	}
+
+	ctx := context.Background()
+	opts := append([]option.ClientOption{option.WithScopes(sheets.SpreadsheetsReadonlyScope)}, f.opts...)
+	service, err := sheets.NewService(ctx, opts...)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to create google sheets client: %w", err)
+	}
+	values, err := service.Spreadsheets.Values.Get(u.Host, readRange).Context(ctx).Do()
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to fetch url %s: %w", rawURL, err)
+	}
+
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	for _, row := range values.Values {
+		record := make([]string, len(row))
+		for i, cell := range row {
+			record[i] = fmt.Sprint(cell)
+		}
+		if err := w.Write(record); err != nil {
+			return nil, "", err
+		}
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return nil, "", err
+	}
+	return buf.Bytes(), fmt.Sprintf("%x", sha256.Sum256(buf.Bytes())), nil
+}
+
+// SheetParser is an implementation of Parser that parses calls from the rows of a sheet, as CSV with a
+// header row. Each row is a call, with the columns:
+//
+//   - id: the ID of the call; rows without one are skipped.
+//   - subject: the subject of the call, which may be left out.
+//   - content: the content of the call.
+//   - destination: one or more of "<type>:<address>", such as "slack:#general", on separate lines or
+//     separated by ";".
+//   - trigger: one or more, on separate lines, of a time, such as "2025-01-06 09:00" (in UTC) or in
+//     RFC 3339, a cron expression, optionally prefixed with "cron:", or an RRule prefixed with "rrule:".
+//
+// The campaign of the calls is given by the campaign and name query parameters of the URL, and defaults
+// to the spreadsheet and its sheet. Rows that are not valid are skipped, and recorded on the source.
+type SheetParser struct{}
+
+// NewSheetParser creates a new SheetParser.
+func NewSheetParser() *SheetParser {
+	return &SheetParser{}
+}
+
+// Parse parses the rows of a sheet into calls.
+func (p *SheetParser) Parse(rawURL string, data []byte) (*Source, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse url %s: %w", rawURL, err)
+	}
+
+	r := csv.NewReader(bytes.NewReader(data))
+	// Sheets leaves out the empty cells at the end of each row.
+	r.FieldsPerRecord = -1
+	records, err := r.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read csv: %w", err)
+	}
+	if len(records) == 0 {
+		return &Source{Campaign: sheetCampaign(u)}, nil
+	}
+
+	columns := make(map[string]int)
+	for i, name := range records[0] {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	verr := &ValidationError{URL: rawURL}
+	for _, name := range sheetColumns {
+		if _, ok := columns[name]; !ok {
+			verr.Fields = append(verr.Fields, FieldError{Field: name, Description: "column is missing"})
+		}
+	}
+	if len(verr.Fields) > 0 {
+		return nil, verr
+	}
+
+	s := &Source{Campaign: sheetCampaign(u)}
+	for i, record := range records[1:] {
+		cell := func(name string) string {
+			if j, ok := columns[name]; ok && j < len(record) {
+				return strings.TrimSpace(record[j])
+			}
+			return ""
+		}
+		if cell("id") == "" {
+			continue
+		}
+
+		call := model.Call{
+			ID:       cell("id"),
+			Author:   cell("author"),
+			Subject:  cell("subject"),
+			Content:  cell("content"),
+			Campaign: s.Campaign,
+		}
+		if call.Destinations, err = sheetDestinations(cell("destination")); err == nil {
+			call.Triggers, err = sheetTriggers(cell("trigger"))
+		}
+		if err != nil {
+			// The header is the first row of the sheet, and the first call is on the second.
+			verr.Fields = append(verr.Fields, FieldError{Field: fmt.Sprintf("row %d", i+2), Description: err.Error()})
+			continue
+		}
+		s.Calls = append(s.Calls, call)
+	}
+	// Rows that are not valid are skipped, leaving the rest of the sheet.
+	if len(verr.Fields) > 0 {
+		s.Invalid = append(s.Invalid, verr)
+	}
+	return s, nil
+}
+
+// sheetCampaign returns the campaign of the calls of a sheet.
+func sheetCampaign(u *url.URL) model.Campaign {
+	campaign := model.Campaign{ID: u.Query().Get("campaign"), Name: u.Query().Get("name")}
+	if campaign.ID == "" {
+		campaign.ID = u.Host
+	}
+	if campaign.Name == "" {
+		campaign.Name = strings.TrimPrefix(u.Path, "/")
+	}
+	if campaign.Name == "" {
+		campaign.Name = u.Host
+	}
+	return campaign
+}
+
+// sheetEntries splits a cell into its entries, given on separate lines or separated by any of seps.
+func sheetEntries(cell, seps string) []string {
+	var entries []string
+	for _, entry := range strings.FieldsFunc(cell, func(r rune) bool { return r == '\n' || strings.ContainsRune(seps, r) }) {
+		if entry = strings.TrimSpace(entry); entry != "" {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+// sheetDestinations parses the destination cell of a row, grouping the addresses by type.
+func sheetDestinations(cell string) ([]model.Destination, error) {
+	var destinations []model.Destination
+	index := make(map[string]int)
+	for _, entry := range sheetEntries(cell, ";") {
+		destType, to, ok := strings.Cut(entry, ":")
+		destType, to = strings.TrimSpace(destType), strings.TrimSpace(to)
+		if !ok || destType == "" || to == "" {
+			return nil, fmt.Errorf("destination '%s' is not of the form <type>:<address>", entry)
+		}
+		if i, ok := index[destType]; ok {
+			destinations[i].To = append(destinations[i].To, to)
+			continue
+		}
+		index[destType] = len(destinations)
+		destinations = append(destinations, model.Destination{Type: destType, To: []string{to}})
+	}
+	if len(destinations) == 0 {
+		return nil, errors.New("no destination")
+	}
+	return destinations, nil
+}
+
+// sheetTriggers parses the trigger cell of a row.
+func sheetTriggers(cell string) ([]model.Trigger, error) {
+	var triggers []model.Trigger
+	for _, entry := range sheetEntries(cell, "") {
+		trigger, err := sheetTrigger(entry)
+		if err != nil {
+			return nil, err
+		}
+		triggers = append(triggers, trigger)
+	}
+	if len(triggers) == 0 {
+		return nil, errors.New("no trigger")
+	}
+	return triggers, nil
+}
+
+func sheetTrigger(entry string) (model.Trigger, error) {
+	for _, layout := range sheetTimeLayouts {
+		if t, err := time.Parse(layout, entry); err == nil {
+			return model.Trigger{ScheduledAt: t}, nil
+		}
+	}
+	if rule, ok := strings.CutPrefix(entry, "rrule:"); ok {
+		rule = strings.TrimSpace(rule)
+		if _, err := rrule.StrToRRule(rule); err != nil {
+			return model.Trigger{}, fmt.Errorf("invalid rrule: %w", err)
+		}
+		return model.Trigger{RRule: rule}, nil
+	}
+	cron, _ := strings.CutPrefix(entry, "cron:")
+	return model.Trigger{Cron: strings.TrimSpace(cron)}, nil
+}
@@ -0,0 +1,72 @@
+package sourcer
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/andrewhowdencom/ruf/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/api/option"
+)
+
+func TestSheetsFetcher(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v4/spreadsheets/sheet-id/values/'Team Calls'", r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"range": "'Team Calls'!A1:C2", "values": [["id", "content", "trigger"], ["standup", "Stand up, \"now\"", "0 9 * * 1-5"]]}`))
+	}))
+	defer server.Close()
+
+	fetcher := NewSheetsFetcher(option.WithEndpoint(server.URL), option.WithoutAuthentication())
+	data, state, err := fetcher.Fetch("gsheets://sheet-id/Team Calls")
+	require.NoError(t, err)
+	assert.Equal(t, "id,content,trigger\nstandup,\"Stand up, \"\"now\"\"\",0 9 * * 1-5\n", string(data))
+	assert.NotEmpty(t, state)
+
+	_, _, err = fetcher.Fetch("gsheets:///Team Calls")
+	assert.ErrorIs(t, err, ErrInvalidSheetURL)
+}
+
+func TestSheetParser(t *testing.T) {
+	data := []byte(`ID,Subject,Content,Destination,Trigger
+standup,,Time for stand up!,slack:#general,cron: 0 9 * * 1-5
+launch,Launch,We are live,"slack:#general
+email:team@example.com; slack:#announcements","2025-01-06 09:00
+rrule: FREQ=WEEKLY;COUNT=2"
+,,A row without an ID,slack:#general,0 9 * * *
+broken,,No destination,,0 9 * * *
+`)
+
+	source, err := NewSheetParser().Parse("gsheets://sheet-id/Team%20Calls?campaign=team", data)
+	require.NoError(t, err)
+	assert.Equal(t, model.Campaign{ID: "team", Name: "Team Calls"}, source.Campaign)
+	require.Len(t, source.Calls, 2)
+	// The rows that are not valid are skipped, and recorded on the source.
+	require.Len(t, source.Invalid, 1)
+	assert.Equal(t, []FieldError{{Field: "row 5", Description: "no destination"}}, source.Invalid[0].Fields)
+
+	assert.Equal(t, "standup", source.Calls[0].ID)
+	assert.Equal(t, []model.Trigger{{Cron: "0 9 * * 1-5"}}, source.Calls[0].Triggers)
+	assert.Equal(t, "team", source.Calls[0].Campaign.ID)
+
+	launch := source.Calls[1]
+	assert.Equal(t, "Launch", launch.Subject)
+	assert.Equal(t, []model.Destination{
+		{Type: "slack", To: []string{"#general", "#announcements"}},
+		{Type: "email", To: []string{"team@example.com"}},
+	}, launch.Destinations)
+	assert.Equal(t, []model.Trigger{
+		{ScheduledAt: time.Date(2025, 1, 6, 9, 0, 0, 0, time.UTC)},
+		{RRule: "FREQ=WEEKLY;COUNT=2"},
+	}, launch.Triggers)
+
+	// A sheet without the columns of a call is not valid.
+	source, err = NewSheetParser().Parse("gsheets://sheet-id", []byte("id,content\nstandup,Hi\n"))
+	var verr *ValidationError
+	require.ErrorAs(t, err, &verr)
+	assert.Equal(t, []FieldError{{Field: "destination", Description: "column is missing"}, {Field: "trigger", Description: "column is missing"}}, verr.Fields)
+	assert.Nil(t, source)
+}
@@ -1,16 +1,20 @@
 package sourcer
 
 import (
+	"context"
 	"crypto/sha256"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
-	"log"
 	"net/http"
 	"net/url"
 	"os"
+	"sort"
 	"strings"
 
 	"github.com/andrewhowdencom/ruf/internal/model"
+	"github.com/andrewhowdencom/ruf/schema"
 	"github.com/ghodss/yaml"
 	"github.com/teambition/rrule-go"
 	"github.com/xeipuuv/gojsonschema"
@@ -18,16 +22,83 @@ import (
 
 // Source represents a source file.
 type Source struct {
+	// APIVersion is the version of the format the source was written in.
+	APIVersion string `json:"apiVersion,omitempty" yaml:"apiVersion,omitempty"`
+
 	Campaign model.Campaign `json:"campaign" yaml:"campaign"`
 	Calls    []model.Call   `json:"calls" yaml:"calls"`
 	Events   []model.Event  `json:"events" yaml:"events"`
+
+	// Series are sets of related calls sent at offsets from an anchor date. The calls of their steps
+	// are added to Calls when the source is read.
+	Series []model.Series `json:"series,omitempty" yaml:"series,omitempty"`
+
+	// EventSources are external calendars whose events are added to Events when the source is read.
+	EventSources []model.EventSource `json:"event_sources,omitempty" yaml:"event_sources,omitempty"`
+
+	// Invalid are the documents, or rows of a sheet, that were skipped when the source was read as
+	// they are not valid, while the rest of the source was read.
+	Invalid []*ValidationError `json:"-" yaml:"-"`
+}
+
+// FieldError is a problem with a field of a document, such as "calls.0.content".
+type FieldError struct {
+	Field       string `json:"field"`
+	Description string `json:"description"`
+}
+
+func (e FieldError) String() string {
+	return e.Field + ": " + e.Description
+}
+
+// ValidationError is returned when a document is not valid, such as when it does not match the schema
+// of calls, with a FieldError for each of its problems.
+type ValidationError struct {
+	URL    string       `json:"url"`
+	Fields []FieldError `json:"fields"`
+}
+
+func (e *ValidationError) Error() string {
+	problems := make([]string, len(e.Fields))
+	for i, field := range e.Fields {
+		problems[i] = field.String()
+	}
+	return fmt.Sprintf("document '%s' is not valid: %s", e.URL, strings.Join(problems, "; "))
+}
+
+// EventResolver reads the events of an external calendar.
+type EventResolver interface {
+	Events(ctx context.Context, src model.EventSource) ([]model.Event, error)
 }
 
+// ErrNotModified is returned when content has not changed since the state it was last fetched at.
+var ErrNotModified = errors.New("not modified")
+
 // Fetcher defines the interface for fetching content from a URL.
 type Fetcher interface {
 	Fetch(url string) ([]byte, string, error)
 }
 
+// ConditionalFetcher is a Fetcher that can ask for content only if it changed since the state it was
+// last fetched at, returning ErrNotModified if it has not.
+type ConditionalFetcher interface {
+	Fetcher
+	FetchIfModified(url, state string) ([]byte, string, error)
+}
+
+// File is a file fetched from a URL.
+type File struct {
+	// URL selects the file on its own.
+	URL  string
+	Data []byte
+}
+
+// TreeFetcher is a Fetcher that can also fetch every source file under a URL that selects a directory.
+type TreeFetcher interface {
+	Fetcher
+	FetchTree(url string) ([]File, string, error)
+}
+
 // CompositeFetcher is a fetcher that can handle multiple schemes.
 type CompositeFetcher struct {
 	fetchers map[string]Fetcher
@@ -60,6 +131,48 @@ func (f *CompositeFetcher) Fetch(rawURL string) ([]byte, string, error) {
 	return fetcher.Fetch(rawURL)
 }
 
+// FetchTree fetches the files a URL selects, if the fetcher for its scheme can fetch directories,
+// or the single file it selects otherwise.
+func (f *CompositeFetcher) FetchTree(rawURL string) ([]File, string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to parse url %s: %w", rawURL, err)
+	}
+
+	fetcher, ok := f.fetchers[u.Scheme]
+	if !ok {
+		return nil, "", fmt.Errorf("unsupported scheme: %s", u.Scheme)
+	}
+	if tf, ok := fetcher.(TreeFetcher); ok {
+		return tf.FetchTree(rawURL)
+	}
+
+	data, state, err := fetcher.Fetch(rawURL)
+	if err != nil {
+		return nil, "", err
+	}
+	return []File{{URL: rawURL, Data: data}}, state, nil
+}
+
+// FetchTreeIfModified fetches the files a URL selects, as FetchTree does, unless the fetcher for its
+// scheme can tell that they have not changed since the previous state, returning ErrNotModified.
+func (f *CompositeFetcher) FetchTreeIfModified(rawURL, previous string) ([]File, string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to parse url %s: %w", rawURL, err)
+	}
+
+	cf, ok := f.fetchers[u.Scheme].(ConditionalFetcher)
+	if !ok || previous == "" {
+		return f.FetchTree(rawURL)
+	}
+	data, state, err := cf.FetchIfModified(rawURL, previous)
+	if err != nil {
+		return nil, "", err
+	}
+	return []File{{URL: rawURL, Data: data}}, state, nil
+}
+
 // HTTPFetcher is an implementation of Fetcher that fetches content over HTTP.
 type HTTPFetcher struct {
 	client *http.Client
@@ -74,12 +187,33 @@ func NewHTTPFetcher(client *http.Client) *HTTPFetcher {
 
 // Fetch fetches the content of a URL and returns it as a byte slice.
 func (f *HTTPFetcher) Fetch(url string) ([]byte, string, error) {
-	resp, err := f.client.Get(url)
+	return f.FetchIfModified(url, "")
+}
+
+// FetchIfModified fetches the content of a URL unless it has not changed since the previous state, which is
+// sent back as If-Modified-Since if it is a date, or as If-None-Match otherwise.
+func (f *HTTPFetcher) FetchIfModified(url, previous string) ([]byte, string, error) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to fetch url %s: %w", url, err)
+	}
+	if previous != "" {
+		if _, err := http.ParseTime(previous); err == nil {
+			req.Header.Set("If-Modified-Since", previous)
+		} else {
+			req.Header.Set("If-None-Match", previous)
+		}
+	}
+
+	resp, err := f.client.Do(req)
 	if err != nil {
 		return nil, "", fmt.Errorf("failed to fetch url %s: %w", url, err)
 	}
 	defer resp.Body.Close()
 
+	if previous != "" && resp.StatusCode == http.StatusNotModified {
+		return nil, previous, fmt.Errorf("%w: %s", ErrNotModified, url)
+	}
 	if resp.StatusCode != http.StatusOK {
 		return nil, "", fmt.Errorf("failed to fetch url %s: status code %d", url, resp.StatusCode)
 	}
@@ -130,45 +264,131 @@ type Parser interface {
 	Parse(url string, data []byte) (*Source, error)
 }
 
-// YAMLParser is an implementation of Parser that parses YAML content.
-type YAMLParser struct {
-	schemaLoader gojsonschema.JSONLoader
+// CompositeParser is a Parser that delegates to a parser by the scheme of the URL, or to a fallback
+// parser for the schemes it has none for.
+type CompositeParser struct {
+	fallback Parser
+	parsers  map[string]Parser
 }
 
-// NewYAMLParser creates a new YAMLParser.
-func NewYAMLParser(schemaPath string) (*YAMLParser, error) {
-	schemaLoader := gojsonschema.NewReferenceLoader(fmt.Sprintf("file://%s", schemaPath))
-	_, err := schemaLoader.LoadJSON()
+// NewCompositeParser creates a new CompositeParser, that parses with the fallback by default.
+func NewCompositeParser(fallback Parser) *CompositeParser {
+	return &CompositeParser{
+		fallback: fallback,
+		parsers:  make(map[string]Parser),
+	}
+}
+
+// AddParser adds a parser for a given scheme.
+func (p *CompositeParser) AddParser(scheme string, parser Parser) {
+	p.parsers[scheme] = parser
+}
+
+// Parse parses content with the parser for the scheme of the URL.
+func (p *CompositeParser) Parse(rawURL string, data []byte) (*Source, error) {
+	u, err := url.Parse(rawURL)
 	if err != nil {
-		return nil, fmt.Errorf("failed to load schema: %w", err)
+		return nil, fmt.Errorf("failed to parse url %s: %w", rawURL, err)
 	}
+	if parser, ok := p.parsers[u.Scheme]; ok {
+		return parser.Parse(rawURL, data)
+	}
+	return p.fallback.Parse(rawURL, data)
+}
 
-	return &YAMLParser{
-		schemaLoader: schemaLoader,
-	}, nil
+// YAMLParser is an implementation of Parser that parses YAML content. Each document is validated
+// against the schema of the version of the format given by its apiVersion, or of the latest version
+// if it does not give one.
+type YAMLParser struct {
+	schemas map[string]*gojsonschema.Schema
 }
 
-// Parse parses a YAML byte slice and returns a list of calls.
+// NewYAMLParser creates a new YAMLParser, compiling the embedded schemas of each version.
+func NewYAMLParser() (*YAMLParser, error) {
+	p := &YAMLParser{schemas: make(map[string]*gojsonschema.Schema)}
+	for version, data := range schema.Versions() {
+		// The schemas of older versions refer to the latest by its ID.
+		loader := gojsonschema.NewSchemaLoader()
+		if version != schema.Latest {
+			if err := loader.AddSchemas(gojsonschema.NewBytesLoader(schema.Calls)); err != nil {
+				return nil, fmt.Errorf("failed to load schema %s: %w", schema.Latest, err)
+			}
+		}
+		compiled, err := loader.Compile(gojsonschema.NewBytesLoader(data))
+		if err != nil {
+			return nil, fmt.Errorf("failed to load schema %s: %w", version, err)
+		}
+		p.schemas[version] = compiled
+	}
+	return p, nil
+}
+
+// Parse parses a YAML byte slice and returns a list of calls. A stream of documents separated by "---"
+// is read as a source of each document, with its own campaign, and the calls and events of each are
+// returned together; each call keeps the campaign of its own document. Documents that are not valid
+// are skipped, and recorded on the source, with their fields under "documents.<index>".
 func (p *YAMLParser) Parse(rawURL string, data []byte) (*Source, error) {
+	docs := splitDocuments(data)
+	if len(docs) <= 1 {
+		return p.parseDocument(rawURL, data)
+	}
+
+	var sources []*Source
+	var invalid []*ValidationError
+	for i, doc := range docs {
+		source, err := p.parseDocument(rawURL, doc)
+		var verr *ValidationError
+		if errors.As(err, &verr) {
+			for j := range verr.Fields {
+				verr.Fields[j].Field = fmt.Sprintf("documents.%d.%s", i, verr.Fields[j].Field)
+			}
+			invalid = append(invalid, verr)
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("document %d: %w", i, err)
+		}
+		sources = append(sources, source)
+	}
+	return mergeSources(sources, invalid)
+}
+
+// parseDocument parses a single YAML document.
+func (p *YAMLParser) parseDocument(rawURL string, data []byte) (*Source, error) {
 	// Convert YAML to JSON, as gojsonschema only works with JSON
 	jsonData, err := yaml.YAMLToJSON(data)
 	if err != nil {
 		return nil, fmt.Errorf("failed to convert yaml to json: %w", err)
 	}
 
-	documentLoader := gojsonschema.NewBytesLoader(jsonData)
+	// A document that is not an object has no version, and is left to the schema to reject.
+	var header struct {
+		APIVersion string `json:"apiVersion"`
+	}
+	_ = json.Unmarshal(jsonData, &header)
+	version := header.APIVersion
+	if version == "" {
+		version = schema.Latest
+	}
+	compiled, ok := p.schemas[version]
+	if !ok {
+		return nil, &ValidationError{URL: rawURL, Fields: []FieldError{{
+			Field:       "apiVersion",
+			Description: fmt.Sprintf("unknown version '%s': must be one of %s", version, strings.Join(p.versions(), ", ")),
+		}}}
+	}
 
-	result, err := gojsonschema.Validate(p.schemaLoader, documentLoader)
+	result, err := compiled.Validate(gojsonschema.NewBytesLoader(jsonData))
 	if err != nil {
 		return nil, fmt.Errorf("failed to validate document: %w", err)
 	}
 
 	if !result.Valid() {
-		log.Printf("document '%s' is not valid:", rawURL)
+		verr := &ValidationError{URL: rawURL}
 		for _, desc := range result.Errors() {
-			log.Printf("- %s", desc)
+			verr.Fields = append(verr.Fields, FieldError{Field: desc.Field(), Description: desc.Description()})
 		}
-		return nil, nil // Returning nil, nil to skip the file
+		return nil, verr
 	}
 
 	var s Source
@@ -178,26 +398,114 @@ func (p *YAMLParser) Parse(rawURL string, data []byte) (*Source, error) {
 
 	p.fillCampaign(rawURL, &s)
 
-	// Add the campaign to each call.
+	// Add the campaign to each call, without its defaults, which are applied to the calls instead.
+	campaign := s.Campaign
+	campaign.Defaults = nil
 	for i := range s.Calls {
-		s.Calls[i].Campaign = s.Campaign
+		s.Calls[i].Campaign = campaign
 	}
 
 	// Validate RRules
-	for _, call := range s.Calls {
-		for _, trigger := range call.Triggers {
+	verr := &ValidationError{URL: rawURL}
+	p.addSeries(&s, campaign, verr)
+	for i := range s.Calls {
+		s.Campaign.Defaults.Apply(&s.Calls[i])
+	}
+	for i, call := range s.Calls {
+		for j, trigger := range call.Triggers {
 			if trigger.RRule != "" {
 				if _, err := rrule.StrToRRule(trigger.RRule); err != nil {
-					log.Printf("document '%s' is not valid: invalid rrule: %s", rawURL, err)
-					return nil, nil // Returning nil, nil to skip the file
+					verr.Fields = append(verr.Fields, FieldError{Field: fmt.Sprintf("calls.%d.triggers.%d.rrule", i, j), Description: err.Error()})
+				}
+			}
+			if trigger.ExceptRRule != "" {
+				if _, err := rrule.StrToROption(trigger.ExceptRRule); err != nil {
+					verr.Fields = append(verr.Fields, FieldError{Field: fmt.Sprintf("calls.%d.triggers.%d.except_rrule", i, j), Description: err.Error()})
 				}
 			}
 		}
 	}
+	if len(verr.Fields) > 0 {
+		return nil, verr
+	}
 
 	return &s, nil
 }
 
+// splitDocuments splits a YAML stream into its documents, at the "---" markers that start each of
+// them, leaving out those with nothing but comments. Markers are only recognised at the start of a
+// line, where they cannot be part of a block of text.
+func splitDocuments(data []byte) [][]byte {
+	var docs [][]byte
+	var doc strings.Builder
+	empty := true
+	flush := func() {
+		if !empty {
+			docs = append(docs, []byte(doc.String()))
+		}
+		doc.Reset()
+		empty = true
+	}
+	for _, line := range strings.SplitAfter(string(data), "\n") {
+		trimmed := strings.TrimRight(line, " \t\r\n")
+		if trimmed == "---" || strings.HasPrefix(trimmed, "--- #") {
+			flush()
+			continue
+		}
+		doc.WriteString(line)
+		if t := strings.TrimSpace(line); t != "" && !strings.HasPrefix(t, "#") {
+			empty = false
+		}
+	}
+	flush()
+	return docs
+}
+
+// addSeries adds the calls of the steps of each series to the source, recording the problems of the
+// series that are not valid.
+func (p *YAMLParser) addSeries(s *Source, campaign model.Campaign, verr *ValidationError) {
+	for i := range s.Series {
+		series := &s.Series[i]
+		series.Campaign = campaign
+		valid := true
+		seen := make(map[string]bool, len(series.Steps))
+		for j, step := range series.Steps {
+			if _, err := model.ParseOffset(step.Offset); err != nil {
+				verr.Fields = append(verr.Fields, FieldError{Field: fmt.Sprintf("series.%d.steps.%d.offset", i, j), Description: err.Error()})
+				valid = false
+			}
+			if seen[step.ID] {
+				verr.Fields = append(verr.Fields, FieldError{Field: fmt.Sprintf("series.%d.steps.%d.id", i, j), Description: fmt.Sprintf("step '%s' is defined more than once", step.ID)})
+				valid = false
+			}
+			seen[step.ID] = true
+			if len(step.Destinations) == 0 && len(series.Destinations) == 0 && (s.Campaign.Defaults == nil || len(s.Campaign.Defaults.Destinations) == 0) {
+				verr.Fields = append(verr.Fields, FieldError{Field: fmt.Sprintf("series.%d.steps.%d.destinations", i, j), Description: "no destinations: set them on the step, the series or the defaults of the campaign"})
+				valid = false
+			}
+		}
+		if !valid {
+			continue
+		}
+		calls, err := series.Calls()
+		if err != nil {
+			verr.Fields = append(verr.Fields, FieldError{Field: fmt.Sprintf("series.%d", i), Description: err.Error()})
+			continue
+		}
+		s.Calls = append(s.Calls, calls...)
+	}
+}
+
+// versions returns the versions of the format the parser reads, in order.
+func (p *YAMLParser) versions() []string {
+	versions := make([]string, 0, len(p.schemas))
+	for version := range p.schemas {
+		versions = append(versions, version)
+	}
+	sort.Strings(versions)
+	return versions
+}
+
 func (p *YAMLParser) fillCampaign(rawURL string, s *Source) error {
 	// If the campaign isn't specified, we'll derive it from the filename.
 	if s.Campaign.ID == "" {
@@ -207,7 +515,12 @@ func (p *YAMLParser) fillCampaign(rawURL string, s *Source) error {
 		}
 
 		// my-campaign.yaml -> my-campaign-yaml
-		base := u.Path[strings.LastIndex(u.Path, "/")+1:]
+		p := selectedPath(u)
+		if p == "" {
+			// A URL without a path, such as stdin://, names the campaign after its scheme.
+			p = u.Scheme
+		}
+		base := p[strings.LastIndex(p, "/")+1:]
 		s.Campaign.ID = strings.ReplaceAll(
 			strings.TrimSuffix(base, ".yaml"),
 			".", "-",
@@ -218,46 +531,206 @@ func (p *YAMLParser) fillCampaign(rawURL string, s *Source) error {
 		if err != nil {
 			return fmt.Errorf("failed to parse url %s: %w", rawURL, err)
 		}
-		s.Campaign.Name = u.Path
+		s.Campaign.Name = selectedPath(u)
+		if s.Campaign.Name == "" {
+			s.Campaign.Name = u.Scheme
+		}
 	}
 	return nil
 }
 
+// selectedPath returns the path of the file a URL selects: its path selector, as in
+// git://github.com/org/repo?path=calls/standup.yaml, or its path otherwise.
+func selectedPath(u *url.URL) string {
+	if p := u.Query().Get("path"); p != "" {
+		return "/" + strings.TrimPrefix(p, "/")
+	}
+	return u.Path
+}
+
 // Sourcer is an interface that defines the methods for sourcing calls.
 type Sourcer interface {
 	Source(url string) (*Source, string, error)
 }
 
+// ConditionalSourcer is a Sourcer that can skip a source that has not changed since the state it was
+// last read at, returning ErrNotModified.
+type ConditionalSourcer interface {
+	Sourcer
+	SourceIfModified(url, state string) (*Source, string, error)
+}
+
 // sourcer is the concrete implementation of the Sourcer interface.
 type sourcer struct {
 	fetcher Fetcher
 	parser  Parser
+	events  EventResolver
+}
+
+// Option configures a Sourcer.
+type Option func(*sourcer)
+
+// WithEventResolver sets the resolver used to read the event sources of a source. Without one,
+// sources with event sources fail to load.
+func WithEventResolver(events EventResolver) Option {
+	return func(s *sourcer) {
+		s.events = events
+	}
 }
 
 // NewSourcer creates a new Sourcer.
-func NewSourcer(fetcher Fetcher, parser Parser) Sourcer {
-	return &sourcer{
+func NewSourcer(fetcher Fetcher, parser Parser, opts ...Option) Sourcer {
+	s := &sourcer{
 		fetcher: fetcher,
 		parser:  parser,
 	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
 }
 
-// Source fetches and parses calls from a URL.
+// Source fetches and parses calls from a URL. A URL that selects a directory, where the fetcher
+// supports it, is read as a single source of the calls and events of every file under it; each call
+// keeps the campaign of its own file.
 func (s *sourcer) Source(url string) (*Source, string, error) {
-	data, state, err := s.fetcher.Fetch(url)
-	if err != nil {
-		return nil, "", err
-	}
+	return s.SourceIfModified(url, "")
+}
 
-	source, err := s.parser.Parse(url, data)
+// SourceIfModified fetches and parses calls from a URL, unless the fetcher can tell that it has not
+// changed since the state, in which case it returns ErrNotModified. The state of a source with event
+// sources folds in its events, so it never matches that of the file alone, and the source is always
+// read again.
+func (s *sourcer) SourceIfModified(url, state string) (*Source, string, error) {
+	if url == "-" {
+		url = StdinURL
+	}
+	source, state, err := s.fetch(url, state)
 	if err != nil {
 		return nil, "", err
 	}
 
-	// If the source is nil, it means the document was invalid and should be skipped.
+	// A parser may skip a document that does not hold calls.
 	if source == nil {
 		return nil, "", nil
 	}
 
+	if len(source.EventSources) > 0 {
+		if state, err = s.resolveEvents(source, state); err != nil {
+			return nil, "", err
+		}
+	}
+
+	return source, state, nil
+}
+
+// fetch fetches and parses the files a URL selects, if they changed since the previous state.
+func (s *sourcer) fetch(url, previous string) (*Source, string, error) {
+	var files []File
+	var state string
+	var err error
+	switch f := s.fetcher.(type) {
+	case *CompositeFetcher:
+		files, state, err = f.FetchTreeIfModified(url, previous)
+	case TreeFetcher:
+		files, state, err = f.FetchTree(url)
+	default:
+		var data []byte
+		if cf, ok := f.(ConditionalFetcher); ok && previous != "" {
+			data, state, err = cf.FetchIfModified(url, previous)
+		} else {
+			data, state, err = f.Fetch(url)
+		}
+		if err != nil {
+			return nil, "", err
+		}
+		if data, state, err = s.includes(url, data, state); err != nil {
+			return nil, "", err
+		}
+		source, err := s.parser.Parse(url, data)
+		return source, state, err
+	}
+	if err != nil {
+		return nil, "", err
+	}
+	var sources []*Source
+	var invalid []*ValidationError
+	for _, file := range files {
+		data, fileState, err := s.includes(file.URL, file.Data, state)
+		if err != nil {
+			return nil, "", fmt.Errorf("%s: %w", file.URL, err)
+		}
+		state = fileState
+		source, err := s.parser.Parse(file.URL, data)
+		// Invalid files are skipped, leaving the rest of the directory, and recorded on the source.
+		var verr *ValidationError
+		if errors.As(err, &verr) {
+			invalid = append(invalid, verr)
+			continue
+		}
+		if err != nil {
+			return nil, "", fmt.Errorf("%s: %w", file.URL, err)
+		}
+		if source != nil {
+			sources = append(sources, source)
+		}
+	}
+
+	source, err := mergeSources(sources, invalid)
+	if err != nil {
+		return nil, "", err
+	}
 	return source, state, nil
 }
+
+// mergeSources returns the calls and events of several sources as one source, on which each call
+// keeps its own campaign, and the documents that were skipped as they are not valid. If none of the
+// documents are valid, their errors are returned instead.
+func mergeSources(sources []*Source, invalid []*ValidationError) (*Source, error) {
+	switch {
+	case len(sources) == 0 && len(invalid) == 1:
+		return nil, invalid[0]
+	case len(sources) == 0 && len(invalid) > 0:
+		errs := make([]error, len(invalid))
+		for i, verr := range invalid {
+			errs[i] = verr
+		}
+		return nil, errors.Join(errs...)
+	case len(sources) == 0:
+		return nil, nil
+	case len(sources) == 1:
+		sources[0].Invalid = append(sources[0].Invalid, invalid...)
+		return sources[0], nil
+	}
+	merged := &Source{Invalid: invalid}
+	for _, source := range sources {
+		merged.Calls = append(merged.Calls, source.Calls...)
+		merged.Events = append(merged.Events, source.Events...)
+		merged.Series = append(merged.Series, source.Series...)
+		merged.EventSources = append(merged.EventSources, source.EventSources...)
+		merged.Invalid = append(merged.Invalid, source.Invalid...)
+	}
+	return merged, nil
+}
+
+// resolveEvents adds the events of the source's event sources to it. As the calendars can change
+// without the source file changing, the events are folded into the state of the source.
+func (s *sourcer) resolveEvents(source *Source, state string) (string, error) {
+	if s.events == nil {
+		return "", fmt.Errorf("source has event sources, but no event resolver is configured")
+	}
+
+	for _, src := range source.EventSources {
+		events, err := s.events.Events(context.Background(), src)
+		if err != nil {
+			return "", fmt.Errorf("failed to read event source: %w", err)
+		}
+		source.Events = append(source.Events, events...)
+	}
+
+	data, err := json.Marshal(source.Events)
+	if err != nil {
+		return "", fmt.Errorf("failed to hash events: %w", err)
+	}
+	return fmt.Sprintf("%x", sha256.Sum256(append([]byte(state), data...))), nil
+}
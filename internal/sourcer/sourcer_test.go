@@ -1,14 +1,17 @@
 package sourcer
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	rufhttp "github.com/andrewhowdencom/ruf/internal/http"
+	"github.com/andrewhowdencom/ruf/internal/model"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -49,53 +52,80 @@ func TestCompositeFetcher(t *testing.T) {
 	assert.Error(t, err)
 }
 
-func TestYAMLParser(t *testing.T) {
-	schema := `{
-		"$schema": "http://json-schema.org/draft-07/schema#",
-		"title": "Ruf Call Configuration",
-		"type": "object",
-		"properties": {
-			"campaign": {
-				"$ref": "#/definitions/Campaign"
-			},
-			"calls": {
-				"type": "array",
-				"items": {
-					"$ref": "#/definitions/Call"
-				}
-			}
+func TestHTTPFetcher_FetchIfModified(t *testing.T) {
+	lastModified := time.Date(2025, 1, 6, 9, 0, 0, 0, time.UTC).Format(http.TimeFormat)
+	tests := []struct {
+		name   string
+		header func(w http.ResponseWriter)
+		state  string
+	}{
+		{
+			name:   "etag",
+			header: func(w http.ResponseWriter) { w.Header().Set("ETag", `"v1"`) },
+			state:  `"v1"`,
+		},
+		{
+			name:   "last modified",
+			header: func(w http.ResponseWriter) { w.Header().Set("Last-Modified", lastModified) },
+			state:  lastModified,
 		},
-		"definitions": {
-			"Campaign": {
-				"type": "object",
-				"properties": {
-					"id": { "type": "string" },
-					"name": { "type": "string" }
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Header.Get("If-None-Match") == tt.state || r.Header.Get("If-Modified-Since") == tt.state {
+					w.WriteHeader(http.StatusNotModified)
+					return
 				}
-			},
-			"Call": {
-				"type": "object",
-				"properties": {
-					"id": { "type": "string" },
-					"subject": { "type": "string" },
-					"content": { "type": "string" },
-					"destinations": { "type": "array" },
-					"triggers": { "type": "array" }
-				},
-				"required": ["id", "content", "destinations", "triggers"]
-			}
+				tt.header(w)
+				fmt.Fprint(w, "calls: []\n")
+			}))
+			defer server.Close()
+
+			fetcher := NewHTTPFetcher(rufhttp.NewClient())
+			data, state, err := fetcher.FetchIfModified(server.URL, "")
+			assert.NoError(t, err)
+			assert.Equal(t, "calls: []\n", string(data))
+			assert.Equal(t, tt.state, state)
+
+			data, state, err = fetcher.FetchIfModified(server.URL, state)
+			assert.ErrorIs(t, err, ErrNotModified)
+			assert.Nil(t, data)
+			assert.Equal(t, tt.state, state)
+		})
+	}
+}
+
+func TestSourcer_SourceIfModified(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
 		}
-	}`
+		w.Header().Set("ETag", `"v1"`)
+		fmt.Fprint(w, "campaign:\n  id: c1\n  name: C1\ncalls: []\n")
+	}))
+	defer server.Close()
 
-	tmpDir, err := os.MkdirTemp("", "test-schema")
+	parser, err := NewYAMLParser()
 	assert.NoError(t, err)
-	defer os.RemoveAll(tmpDir)
 
-	schemaPath := filepath.Join(tmpDir, "schema.json")
-	err = os.WriteFile(schemaPath, []byte(schema), 0644)
+	fetcher := NewCompositeFetcher()
+	fetcher.AddFetcher("http", NewHTTPFetcher(rufhttp.NewClient()))
+	s := NewSourcer(fetcher, parser).(ConditionalSourcer)
+
+	source, state, err := s.SourceIfModified(server.URL, "")
 	assert.NoError(t, err)
+	assert.Equal(t, "c1", source.Campaign.ID)
+	assert.Equal(t, `"v1"`, state)
+
+	_, _, err = s.SourceIfModified(server.URL, state)
+	assert.ErrorIs(t, err, ErrNotModified)
+}
 
-	parser, err := NewYAMLParser(schemaPath)
+func TestYAMLParser(t *testing.T) {
+	parser, err := NewYAMLParser()
 	assert.NoError(t, err)
 
 	// Test with campaign
@@ -107,8 +137,8 @@ calls:
   - id: "test-call"
     subject: "Test Subject"
     content: "Test Content"
-    destinations: []
-    triggers: []
+    destinations: [{type: slack, to: ["#general"]}]
+    triggers: [{cron: "0 9 * * 1"}]
 `
 	source, err := parser.Parse("file:///test.yaml", []byte(yamlWithCampaign))
 	assert.NoError(t, err)
@@ -123,8 +153,8 @@ calls:
   - id: "test-call"
     subject: "Test Subject"
     content: "Test Content"
-    destinations: []
-    triggers: []
+    destinations: [{type: slack, to: ["#general"]}]
+    triggers: [{cron: "0 9 * * 1"}]
 `
 	source, err = parser.Parse("file:///test.yaml", []byte(yamlWithoutCampaign))
 	assert.NoError(t, err)
@@ -137,10 +167,531 @@ calls:
 	invalidYAML := `
 calls:
   - id: "test-call"
-    destinations: []
-    triggers: []
+    destinations: [{type: slack, to: ["#general"]}]
+    triggers: [{cron: "0 9 * * 1"}]
 `
 	source, err = parser.Parse("file:///invalid.yaml", []byte(invalidYAML))
+	assert.Nil(t, source)
+	var verr *ValidationError
+	if assert.ErrorAs(t, err, &verr) {
+		assert.Equal(t, "file:///invalid.yaml", verr.URL)
+		assert.Contains(t, verr.Fields, FieldError{Field: "calls.0", Description: "content is required"})
+	}
+}
+
+func TestYAMLParser_TriggerDestinations(t *testing.T) {
+	parser, err := NewYAMLParser()
+	assert.NoError(t, err)
+
+	withTriggerDestinations := `
+calls:
+  - id: "test-call"
+    content: "Test Content"
+    triggers:
+      - cron: "0 9 * * *"
+        destinations:
+          - type: "slack"
+            to: ["#team"]
+`
+	source, err := parser.Parse("file:///test.yaml", []byte(withTriggerDestinations))
+	assert.NoError(t, err)
+	assert.NotNil(t, source)
+	assert.Equal(t, "#team", source.Calls[0].Triggers[0].Destinations[0].To[0])
+
+	withoutDestinations := `
+calls:
+  - id: "test-call"
+    content: "Test Content"
+    triggers:
+      - cron: "0 9 * * *"
+`
+	source, err = parser.Parse("file:///test.yaml", []byte(withoutDestinations))
+	var verr *ValidationError
+	assert.ErrorAs(t, err, &verr)
+	assert.Nil(t, source)
+}
+
+func TestYAMLParser_APIVersion(t *testing.T) {
+	parser, err := NewYAMLParser()
+	assert.NoError(t, err)
+
+	withTriggerDestinations := `
+calls:
+  - id: "test-call"
+    content: "Test Content"
+    triggers:
+      - cron: "0 9 * * *"
+        destinations:
+          - type: "slack"
+            to: ["#team"]
+`
+	// Files without a version are read as the latest.
+	source, err := parser.Parse("file:///test.yaml", []byte(withTriggerDestinations))
+	assert.NoError(t, err)
+	assert.NotNil(t, source)
+
+	source, err = parser.Parse("file:///test.yaml", []byte("apiVersion: v2\n"+withTriggerDestinations))
+	assert.NoError(t, err)
+	if assert.NotNil(t, source) {
+		assert.Equal(t, "v2", source.APIVersion)
+	}
+
+	// Triggers have no destinations of their own in v1.
+	source, err = parser.Parse("file:///test.yaml", []byte("apiVersion: v1\n"+withTriggerDestinations))
+	var verr *ValidationError
+	assert.ErrorAs(t, err, &verr)
+	assert.Nil(t, source)
+
+	source, err = parser.Parse("file:///test.yaml", []byte(`apiVersion: v1
+calls:
+  - id: "test-call"
+    content: "Test Content"
+    destinations:
+      - type: "slack"
+        to: ["#team"]
+    triggers:
+      - cron: "0 9 * * *"
+`))
 	assert.NoError(t, err)
+	assert.NotNil(t, source)
+
+	source, err = parser.Parse("file:///test.yaml", []byte("apiVersion: v9\n"+withTriggerDestinations))
+	if assert.ErrorAs(t, err, &verr) {
+		assert.Equal(t, []FieldError{{Field: "apiVersion", Description: "unknown version 'v9': must be one of v1, v2"}}, verr.Fields)
+	}
 	assert.Nil(t, source)
 }
+
+func TestYAMLParser_MonthlyTriggers(t *testing.T) {
+	parser, err := NewYAMLParser()
+	assert.NoError(t, err)
+
+	source := func(trigger string) string {
+		return `
+calls:
+  - id: "test-call"
+    content: "Test Content"
+    destinations:
+      - type: "slack"
+        to: ["#team"]
+    triggers:
+      - ` + trigger + `
+        time: "09:00"
+`
+	}
+
+	parsed, err := parser.Parse("file:///test.yaml", []byte(source(`nth_weekday: "2nd Tuesday"`)))
+	assert.NoError(t, err)
+	assert.NotNil(t, parsed)
+	assert.Equal(t, "2nd Tuesday", parsed.Calls[0].Triggers[0].NthWeekday)
+
+	parsed, err = parser.Parse("file:///test.yaml", []byte(source(`business_day: "last"`)))
+	assert.NoError(t, err)
+	assert.NotNil(t, parsed)
+
+	var verr *ValidationError
+	parsed, err = parser.Parse("file:///test.yaml", []byte(source(`nth_weekday: "every tuesday"`)))
+	assert.ErrorAs(t, err, &verr)
+	assert.Nil(t, parsed)
+
+	parsed, err = parser.Parse("file:///test.yaml", []byte(source(`business_day: "second"`)))
+	assert.ErrorAs(t, err, &verr)
+	assert.Nil(t, parsed)
+}
+
+func TestYAMLParser_EventEnd(t *testing.T) {
+	parser, err := NewYAMLParser()
+	assert.NoError(t, err)
+
+	source := func(end string) string {
+		return `
+calls:
+  - id: "test-call"
+    content: "Test Content"
+    destinations:
+      - type: "slack"
+        to: ["#team"]
+    triggers:
+      - sequence: "review"
+        delta: "1h"
+        delta_from: "end"
+events:
+  - sequence: "review"
+    start_time: "2025-03-04T14:00:00Z"
+` + end
+	}
+
+	parsed, err := parser.Parse("file:///test.yaml", []byte(source(`    duration: "90m"`)))
+	assert.NoError(t, err)
+	assert.NotNil(t, parsed)
+	end, err := parsed.Events[0].End()
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2025, 3, 4, 15, 30, 0, 0, time.UTC), end)
+	assert.Equal(t, "end", parsed.Calls[0].Triggers[0].DeltaFrom)
+
+	parsed, err = parser.Parse("file:///test.yaml", []byte(source("    duration: \"90m\"\n    end_time: \"2025-03-04T15:30:00Z\"")))
+	var verr *ValidationError
+	assert.ErrorAs(t, err, &verr, "end_time and duration cannot be combined")
+	assert.Nil(t, parsed)
+}
+
+func TestYAMLParser_Series(t *testing.T) {
+	parser, err := NewYAMLParser()
+	assert.NoError(t, err)
+
+	source := func(offset string) string {
+		return `
+campaign:
+  id: "launch"
+  name: "Launch"
+series:
+  - id: "beta"
+    anchor: "2025-03-10T09:00:00Z"
+    author: "product@example.com"
+    destinations:
+      - type: "slack"
+        to: ["#announcements"]
+    data:
+      program: "Beta"
+    steps:
+      - id: "kickoff"
+        offset: "` + offset + `"
+        content: "The {{ .program }} opens soon"
+      - id: "wrap-up"
+        offset: "1h"
+        content: "The {{ .program }} has opened"
+        data:
+          program: "Beta program"
+`
+	}
+
+	parsed, err := parser.Parse("file:///test.yaml", []byte(source("-14d")))
+	assert.NoError(t, err)
+	assert.NotNil(t, parsed)
+	assert.Len(t, parsed.Series, 1)
+	assert.Len(t, parsed.Calls, 2)
+
+	kickoff := parsed.Calls[0]
+	assert.Equal(t, "beta.kickoff", kickoff.ID)
+	assert.Equal(t, "beta", kickoff.Series)
+	assert.Equal(t, "product@example.com", kickoff.Author)
+	assert.Equal(t, "launch", kickoff.Campaign.ID)
+	assert.Equal(t, []model.Destination{{Type: "slack", To: []string{"#announcements"}}}, kickoff.Destinations)
+	assert.Equal(t, []model.Trigger{{ScheduledAt: time.Date(2025, 2, 24, 9, 0, 0, 0, time.UTC)}}, kickoff.Triggers)
+	assert.Equal(t, "Beta", kickoff.Data["program"])
+	assert.Equal(t, "Beta program", parsed.Calls[1].Data["program"])
+
+	var verr *ValidationError
+	parsed, err = parser.Parse("file:///test.yaml", []byte(source("two weeks")))
+	assert.ErrorAs(t, err, &verr)
+	assert.Nil(t, parsed)
+}
+
+func TestYAMLParser_CampaignDefaults(t *testing.T) {
+	parser, err := NewYAMLParser()
+	assert.NoError(t, err)
+
+	parsed, err := parser.Parse("file:///test.yaml", []byte(`
+campaign:
+  id: "team"
+  name: "Team"
+  defaults:
+    author: "team@example.com"
+    destinations:
+      - type: "slack"
+        to: ["#team"]
+    data:
+      team: "Platform"
+      sign_off: "Cheers"
+    slots: "announcements"
+    processors:
+      strict_templates: true
+calls:
+  - id: "standup"
+    content: "Stand up, {{ .team }}!"
+    triggers:
+      - cron: "0 9 * * 1-5"
+  - id: "release"
+    author: "release@example.com"
+    content: "Released"
+    destinations:
+      - type: "email"
+        to: ["all@example.com"]
+    data:
+      sign_off: "Thanks"
+    processors:
+      markdown: false
+    triggers:
+      - cron: "0 9 * * 1"
+`))
+	assert.NoError(t, err)
+	assert.NotNil(t, parsed)
+	assert.Len(t, parsed.Calls, 2)
+
+	standup := parsed.Calls[0]
+	assert.Equal(t, "team@example.com", standup.Author)
+	assert.Equal(t, []model.Destination{{Type: "slack", To: []string{"#team"}}}, standup.Destinations)
+	assert.Equal(t, map[string]interface{}{"team": "Platform", "sign_off": "Cheers"}, standup.Data)
+	assert.Equal(t, "announcements", standup.Slots)
+	assert.True(t, standup.Processors.Strict(false))
+	// The defaults are not kept with each call.
+	assert.Nil(t, standup.Campaign.Defaults)
+
+	release := parsed.Calls[1]
+	assert.Equal(t, "release@example.com", release.Author)
+	assert.Equal(t, []model.Destination{{Type: "email", To: []string{"all@example.com"}}}, release.Destinations)
+	assert.Equal(t, map[string]interface{}{"team": "Platform", "sign_off": "Thanks"}, release.Data)
+	assert.True(t, release.Processors.Strict(false))
+	assert.False(t, release.Processors.ConvertsMarkdown())
+}
+
+type fakeEventResolver struct {
+	events []model.Event
+}
+
+func (r *fakeEventResolver) Events(_ context.Context, src model.EventSource) ([]model.Event, error) {
+	return r.events, nil
+}
+
+func TestYAMLParser_MultiDocument(t *testing.T) {
+	parser, err := NewYAMLParser()
+	assert.NoError(t, err)
+
+	call := `
+calls:
+  - id: "standup"
+    content: "Hello"
+    destinations:
+      - type: "slack"
+        to: ["#team"]
+    triggers:
+      - cron: "0 9 * * 1"
+`
+	parsed, err := parser.Parse("file:///generated.yaml", []byte(`# Generated.
+---
+campaign:
+  id: "platform"
+  name: "Platform"
+`+call+`--- # The second campaign.
+campaign:
+  id: "security"
+  name: "Security"
+`+call+`---
+calls: 1
+---
+# Nothing but a comment.
+`))
+	assert.NoError(t, err)
+	if assert.Len(t, parsed.Calls, 2) {
+		// Each call keeps the campaign of its own document.
+		assert.Equal(t, model.Campaign{ID: "platform", Name: "Platform"}, parsed.Calls[0].Campaign)
+		assert.Equal(t, model.Campaign{ID: "security", Name: "Security"}, parsed.Calls[1].Campaign)
+	}
+	// The invalid document is skipped, and the empty one left out.
+	if assert.Len(t, parsed.Invalid, 1) {
+		assert.Equal(t, "documents.2.calls", parsed.Invalid[0].Fields[0].Field)
+	}
+
+	// A single document, with or without a marker, is read as before.
+	parsed, err = parser.Parse("file:///generated.yaml", []byte("---\n"+call))
+	assert.NoError(t, err)
+	assert.Equal(t, model.Campaign{ID: "generated", Name: "/generated.yaml"}, parsed.Campaign)
+}
+
+func TestSourcer_EventSources(t *testing.T) {
+	parser, err := NewYAMLParser()
+	assert.NoError(t, err)
+
+	file := filepath.Join(t.TempDir(), "calls.yaml")
+	assert.NoError(t, os.WriteFile(file, []byte(`
+calls:
+  - id: "retro-reminder"
+    content: "Add your notes to the retro."
+    destinations:
+      - type: "slack"
+        to: ["#team"]
+    triggers:
+      - sequence: "retro"
+        delta: "1h"
+        delta_from: "end"
+event_sources:
+  - ical: "https://calendar.example.com/team.ics"
+    match:
+      - sequence: "retro"
+        title: "^Retro"
+`), 0644))
+
+	fetcher := NewCompositeFetcher()
+	fetcher.AddFetcher("file", NewFileFetcher())
+
+	_, _, err = NewSourcer(fetcher, parser).Source("file://" + file)
+	assert.ErrorContains(t, err, "no event resolver")
+
+	start := time.Date(2025, 3, 5, 16, 0, 0, 0, time.UTC)
+	resolver := &fakeEventResolver{events: []model.Event{{Sequence: "retro", StartTime: start}}}
+	s := NewSourcer(fetcher, parser, WithEventResolver(resolver))
+
+	source, state, err := s.Source("file://" + file)
+	assert.NoError(t, err)
+	assert.Equal(t, resolver.events, source.Events)
+
+	// A change to the calendar changes the state of the source, even though the file is unchanged.
+	resolver.events = []model.Event{{Sequence: "retro", StartTime: start.AddDate(0, 0, 7)}}
+	_, changed, err := s.Source("file://" + file)
+	assert.NoError(t, err)
+	assert.NotEqual(t, state, changed)
+}
+
+// fakeTreeFetcher serves a fixed set of files for every URL.
+type fakeTreeFetcher struct {
+	files []File
+}
+
+func (f *fakeTreeFetcher) Fetch(url string) ([]byte, string, error) {
+	return f.files[0].Data, "state", nil
+}
+
+func (f *fakeTreeFetcher) FetchTree(url string) ([]File, string, error) {
+	return f.files, "state", nil
+}
+
+func TestSourcer_Directory(t *testing.T) {
+	parser, err := NewYAMLParser()
+	assert.NoError(t, err)
+
+	call := func(id string) string {
+		return fmt.Sprintf(`
+calls:
+  - id: %q
+    content: "Hello"
+    destinations:
+      - type: "slack"
+        to: ["#team"]
+    triggers:
+      - cron: "0 9 * * 1"
+`, id)
+	}
+	fetcher := NewCompositeFetcher()
+	fetcher.AddFetcher("git", &fakeTreeFetcher{files: []File{
+		{URL: "git://github.com/org/repo?path=calls%2Fstandup.yaml", Data: []byte(call("standup"))},
+		{URL: "git://github.com/org/repo?path=calls%2Finvalid.yaml", Data: []byte("calls: 1\n")},
+		{URL: "git://github.com/org/repo?path=calls%2Fretro.yaml", Data: []byte(call("retro"))},
+	}})
+
+	source, state, err := NewSourcer(fetcher, parser).Source("git://github.com/org/repo?path=calls")
+	assert.NoError(t, err)
+	assert.Equal(t, "state", state)
+	if assert.Len(t, source.Calls, 2) {
+		// Each call keeps the campaign of its own file; the invalid file is skipped.
+		assert.Equal(t, "standup", source.Calls[0].Campaign.ID)
+		assert.Equal(t, "/calls/standup.yaml", source.Calls[0].Campaign.Name)
+		assert.Equal(t, "retro", source.Calls[1].Campaign.ID)
+	}
+	if assert.Len(t, source.Invalid, 1) {
+		assert.Equal(t, "git://github.com/org/repo?path=calls%2Finvalid.yaml", source.Invalid[0].URL)
+	}
+}
+
+func TestSourcer_Includes(t *testing.T) {
+	parser, err := NewYAMLParser()
+	assert.NoError(t, err)
+
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		assert.NoError(t, os.WriteFile(path, []byte(content), 0644))
+		return "file://" + path
+	}
+	write("shared/channels.yaml", `---
+include: triggers.yaml
+channels: &all-channels
+  - type: "slack"
+    to: ["#general", "#announcements"]
+company: &company
+  name: "Example"
+`)
+	write("shared/triggers.yaml", `
+weekly: &weekly
+  cron: "0 9 * * 1"
+  timezone: "Europe/Berlin"
+`)
+	url := write("standup.yaml", `# Shared destinations.
+include:
+  - shared/channels.yaml
+calls:
+  - id: "standup"
+    content: "Hello {{ .name }}"
+    data: *company
+    destinations: *all-channels
+    triggers:
+      - *weekly
+`)
+
+	fetcher := NewCompositeFetcher()
+	fetcher.AddFetcher("file", NewFileFetcher())
+	s := NewSourcer(fetcher, parser)
+
+	source, state, err := s.Source(url)
+	assert.NoError(t, err)
+	if assert.Len(t, source.Calls, 1) {
+		call := source.Calls[0]
+		assert.Equal(t, "standup", call.Campaign.ID)
+		assert.Equal(t, []model.Destination{{Type: "slack", To: []string{"#general", "#announcements"}}}, call.Destinations)
+		assert.Equal(t, map[string]interface{}{"name": "Example"}, call.Data)
+		assert.Equal(t, []model.Trigger{{Cron: "0 9 * * 1", Timezone: "Europe/Berlin"}}, call.Triggers)
+	}
+	// The state folds in the included files, so the source is always read again.
+	_, fileState, err := fetcher.Fetch(url)
+	assert.NoError(t, err)
+	assert.NotEqual(t, fileState, state)
+
+	// Each document of a stream has its own includes.
+	url = write("streams.yaml", `campaign:
+  id: "first"
+  name: "First"
+calls:
+  - id: "standup"
+    content: "Hello"
+    destinations: [{type: "slack", to: ["#first"]}]
+    triggers: [{cron: "0 9 * * 1"}]
+---
+include: shared/channels.yaml
+campaign:
+  id: "second"
+  name: "Second"
+calls:
+  - id: "standup"
+    content: "Hello"
+    destinations: *all-channels
+    triggers:
+      - *weekly
+`)
+	source, _, err = s.Source(url)
+	assert.NoError(t, err)
+	if assert.Len(t, source.Calls, 2) {
+		assert.Equal(t, "first", source.Calls[0].Campaign.ID)
+		assert.Equal(t, "second", source.Calls[1].Campaign.ID)
+		assert.Equal(t, []model.Destination{{Type: "slack", To: []string{"#general", "#announcements"}}}, source.Calls[1].Destinations)
+	}
+
+	// A file that includes itself, through another, is refused.
+	write("shared/triggers.yaml", "include: ../standup.yaml\n")
+	_, _, err = s.Source(url)
+	assert.ErrorIs(t, err, ErrIncludeCycle)
+}
+
+func TestResolveInclude(t *testing.T) {
+	for _, tt := range []struct {
+		base, ref, want string
+	}{
+		{"https://example.com/calls/standup.yaml", "shared.yaml", "https://example.com/calls/shared.yaml"},
+		{"https://example.com/calls/standup.yaml", "/shared.yaml", "https://example.com/shared.yaml"},
+		{"file:///etc/ruf/calls.yaml", "https://example.com/shared.yaml", "https://example.com/shared.yaml"},
+		{"git://github.com/org/repo?path=calls%2Fstandup.yaml&ref=main", "../shared/channels.yaml", "git://github.com/org/repo?path=shared%2Fchannels.yaml&ref=main"},
+	} {
+		got, err := resolveInclude(tt.base, tt.ref)
+		assert.NoError(t, err)
+		assert.Equal(t, tt.want, got)
+	}
+}
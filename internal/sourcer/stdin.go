@@ -0,0 +1,40 @@
+package sourcer
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// StdinURL is the URL of the source read from standard input. "-" is read as it too.
+const StdinURL = "stdin://"
+
+// StdinFetcher is an implementation of Fetcher that reads a source from standard input, given as
+// stdin:// or "-", so that scripts can pipe a generated call definition into ruf. Standard input can
+// only be read once, so it is read in full by the first fetch, and every fetch returns what it read.
+// The state of a fetch is the hash of its content.
+type StdinFetcher struct {
+	r io.Reader
+
+	once sync.Once
+	data []byte
+	err  error
+}
+
+// NewStdinFetcher creates a fetcher of the source read from r, which is standard input outside of
+// tests.
+func NewStdinFetcher(r io.Reader) *StdinFetcher {
+	return &StdinFetcher{r: r}
+}
+
+// Fetch returns the content of standard input.
+func (f *StdinFetcher) Fetch(_ string) ([]byte, string, error) {
+	f.once.Do(func() {
+		f.data, f.err = io.ReadAll(f.r)
+	})
+	if f.err != nil {
+		return nil, "", fmt.Errorf("failed to read standard input: %w", f.err)
+	}
+	return f.data, fmt.Sprintf("%x", sha256.Sum256(f.data)), nil
+}
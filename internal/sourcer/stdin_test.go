@@ -0,0 +1,39 @@
+package sourcer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStdinFetcher(t *testing.T) {
+	fetcher := NewCompositeFetcher()
+	fetcher.AddFetcher("stdin", NewStdinFetcher(strings.NewReader(`
+calls:
+  - id: "generated"
+    content: "Generated"
+    destinations:
+      - type: "slack"
+        to: ["#team"]
+    triggers:
+      - cron: "0 9 * * 1"
+`)))
+	parser, err := NewYAMLParser()
+	require.NoError(t, err)
+	s := NewSourcer(fetcher, parser)
+
+	// "-" is read as stdin://, and the campaign is named after it.
+	source, state, err := s.Source("-")
+	require.NoError(t, err)
+	require.Len(t, source.Calls, 1)
+	assert.Equal(t, "generated", source.Calls[0].ID)
+	assert.Equal(t, "stdin", source.Campaign.ID)
+
+	// Standard input is read once, and the same content is returned by every fetch.
+	again, next, err := s.Source(StdinURL)
+	require.NoError(t, err)
+	assert.Equal(t, state, next)
+	assert.Len(t, again.Calls, 1)
+}
@@ -0,0 +1,128 @@
+// Package timezone resolves the timezones of the recipients of calls, so that a call can be sent at a
+// time of day local to each recipient.
+package timezone
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/andrewhowdencom/ruf/internal/clients/slack"
+)
+
+// Err* are common errors returned when resolving timezones.
+var (
+	ErrUnknown = errors.New("unknown timezone")
+)
+
+// Resolver finds the timezone of a recipient.
+type Resolver interface {
+	// Timezone returns the timezone of the recipient of a destination of the given type, or an error
+	// wrapping ErrUnknown if the resolver does not know it.
+	Timezone(destType, to string) (*time.Location, error)
+}
+
+// Static resolves timezones from a fixed map of recipients, such as one read from configuration.
+type Static struct {
+	zones map[string]*time.Location
+}
+
+// NewStatic creates a resolver from the timezones of recipients, by destination type and recipient.
+// Recipients are matched case-insensitively.
+func NewStatic(zones map[string]map[string]string) (*Static, error) {
+	s := &Static{zones: make(map[string]*time.Location)}
+	for destType, recipients := range zones {
+		for to, name := range recipients {
+			loc, err := time.LoadLocation(name)
+			if err != nil {
+				return nil, fmt.Errorf("invalid timezone for %s %s: %w", destType, to, err)
+			}
+			s.zones[key(destType, to)] = loc
+		}
+	}
+	return s, nil
+}
+
+// Timezone returns the timezone configured for the recipient.
+func (s *Static) Timezone(destType, to string) (*time.Location, error) {
+	if loc, ok := s.zones[key(destType, to)]; ok {
+		return loc, nil
+	}
+	return nil, fmt.Errorf("%w: %s %s is not configured", ErrUnknown, destType, to)
+}
+
+func key(destType, to string) string {
+	return destType + "\x00" + strings.ToLower(to)
+}
+
+// Slack resolves the timezones of Slack users from their profile. Only users, given by email or
+// handle, have a timezone; channels do not.
+type Slack struct {
+	client slack.Client
+	ttl    time.Duration
+	now    func() time.Time
+
+	mu    sync.Mutex
+	cache map[string]cached
+}
+
+type cached struct {
+	loc     *time.Location
+	err     error
+	expires time.Time
+}
+
+// NewSlack creates a resolver that looks timezones up with the Slack client. A lookup, or its failure,
+// is kept for the ttl, as the schedule is expanded for every occurrence of every call.
+func NewSlack(client slack.Client, ttl time.Duration) *Slack {
+	return &Slack{client: client, ttl: ttl, now: time.Now, cache: make(map[string]cached)}
+}
+
+// Timezone returns the timezone of the Slack user.
+func (s *Slack) Timezone(destType, to string) (*time.Location, error) {
+	if destType != "slack" {
+		return nil, fmt.Errorf("%w: %s destinations are not looked up in Slack", ErrUnknown, destType)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if c, ok := s.cache[to]; ok && s.now().Before(c.expires) {
+		return c.loc, c.err
+	}
+	loc, err := s.lookup(to)
+	s.cache[to] = cached{loc: loc, err: err, expires: s.now().Add(s.ttl)}
+	return loc, err
+}
+
+func (s *Slack) lookup(to string) (*time.Location, error) {
+	// Lookups are made while the schedule is expanded, which has no context of its own.
+	name, err := s.client.GetUserTimezone(context.Background(), to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up the timezone of %s: %w", to, err)
+	}
+	if name == "" {
+		return nil, fmt.Errorf("%w: %s has no timezone in Slack", ErrUnknown, to)
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timezone of %s in Slack: %w", to, err)
+	}
+	return loc, nil
+}
+
+// Chain resolves timezones with each of its resolvers in turn, until one knows the recipient.
+type Chain []Resolver
+
+// Timezone returns the timezone from the first resolver that knows the recipient.
+func (c Chain) Timezone(destType, to string) (*time.Location, error) {
+	for _, r := range c {
+		loc, err := r.Timezone(destType, to)
+		if !errors.Is(err, ErrUnknown) {
+			return loc, err
+		}
+	}
+	return nil, fmt.Errorf("%w: %s %s", ErrUnknown, destType, to)
+}
@@ -0,0 +1,83 @@
+package timezone_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/andrewhowdencom/ruf/internal/clients/slack"
+	"github.com/andrewhowdencom/ruf/internal/timezone"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatic(t *testing.T) {
+	s, err := timezone.NewStatic(map[string]map[string]string{
+		"email": {"Aiko@example.com": "Asia/Tokyo"},
+	})
+	require.NoError(t, err)
+
+	loc, err := s.Timezone("email", "aiko@example.com")
+	require.NoError(t, err)
+	assert.Equal(t, "Asia/Tokyo", loc.String())
+
+	_, err = s.Timezone("slack", "aiko@example.com")
+	assert.ErrorIs(t, err, timezone.ErrUnknown)
+
+	_, err = timezone.NewStatic(map[string]map[string]string{"email": {"sam@example.com": "Mars/Olympus"}})
+	assert.Error(t, err)
+}
+
+func TestSlack(t *testing.T) {
+	client := slack.NewMockClient()
+	lookups := 0
+	client.GetUserTimezoneFunc = func(_ context.Context, destination string) (string, error) {
+		lookups++
+		switch destination {
+		case "@aiko":
+			return "Asia/Tokyo", nil
+		case "@down":
+			return "", errors.New("slack is down")
+		}
+		return "", nil
+	}
+	s := timezone.NewSlack(client, time.Hour)
+
+	loc, err := s.Timezone("slack", "@aiko")
+	require.NoError(t, err)
+	assert.Equal(t, "Asia/Tokyo", loc.String())
+	_, err = s.Timezone("slack", "@aiko")
+	require.NoError(t, err)
+	assert.Equal(t, 1, lookups, "lookups are cached")
+
+	_, err = s.Timezone("slack", "#general")
+	assert.ErrorIs(t, err, timezone.ErrUnknown)
+	_, err = s.Timezone("email", "aiko@example.com")
+	assert.ErrorIs(t, err, timezone.ErrUnknown)
+
+	_, err = s.Timezone("slack", "@down")
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, timezone.ErrUnknown)
+}
+
+func TestChain(t *testing.T) {
+	static, err := timezone.NewStatic(map[string]map[string]string{"slack": {"@sam": "America/Los_Angeles"}})
+	require.NoError(t, err)
+	client := slack.NewMockClient()
+	client.GetUserTimezoneFunc = func(_ context.Context, destination string) (string, error) {
+		return "Asia/Tokyo", nil
+	}
+	chain := timezone.Chain{static, timezone.NewSlack(client, time.Hour)}
+
+	loc, err := chain.Timezone("slack", "@sam")
+	require.NoError(t, err)
+	assert.Equal(t, "America/Los_Angeles", loc.String(), "earlier resolvers win")
+
+	loc, err = chain.Timezone("slack", "@aiko")
+	require.NoError(t, err)
+	assert.Equal(t, "Asia/Tokyo", loc.String())
+
+	_, err = chain.Timezone("email", "aiko@example.com")
+	assert.ErrorIs(t, err, timezone.ErrUnknown)
+}
@@ -2,25 +2,69 @@ package validator
 
 import (
 	"fmt"
+	"maps"
+	"slices"
 	"strings"
 	"time"
 
+	"github.com/andrewhowdencom/ruf/internal/calendar"
+	"github.com/andrewhowdencom/ruf/internal/clients/homeassistant"
 	"github.com/andrewhowdencom/ruf/internal/model"
+	"github.com/andrewhowdencom/ruf/internal/processor"
 	"github.com/gorhill/cronexpr"
+	"github.com/teambition/rrule-go"
 )
 
+// Option configures validation.
+type Option func(*options)
+
+type options struct {
+	types map[string]bool
+}
+
+// WithTypes accepts destinations of the given types, defined in configuration, alongside the built-in
+// ones.
+func WithTypes(types ...string) Option {
+	return func(o *options) {
+		for _, t := range types {
+			o.types[t] = true
+		}
+	}
+}
+
 // Validate validates a list of calls and returns a list of errors.
-func Validate(calls []*model.Call) []error {
+func Validate(calls []*model.Call, opts ...Option) []error {
 	var errs []error
-	for _, call := range calls {
-		if err := validateCall(call); err != nil {
+	for _, err := range ValidateEach(calls, opts...) {
+		if err != nil {
 			errs = append(errs, err)
 		}
 	}
 	return errs
 }
 
-func validateCall(call *model.Call) error {
+// ValidateEach validates a list of calls and returns the error of each call, at its index, which is nil
+// for a call that is valid.
+func ValidateEach(calls []*model.Call, opts ...Option) []error {
+	o := &options{types: map[string]bool{"slack": true, "email": true, "chatwork": true, "line": true, "homeassistant": true, "slack_workflow": true}}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	// Calls are identified within their campaign, so that the calls a trigger follows can be found.
+	ids := make(map[string]bool, len(calls))
+	for _, call := range calls {
+		ids[call.Campaign.ID+"/"+call.ID] = true
+	}
+
+	errs := make([]error, len(calls))
+	for i, call := range calls {
+		errs[i] = validateCall(call, ids, o.types)
+	}
+	return errs
+}
+
+func validateCall(call *model.Call, ids map[string]bool, types map[string]bool) error {
 	var errs []string
 	if call.Subject == "" {
 		errs = append(errs, "subject is required")
@@ -28,7 +72,7 @@ func validateCall(call *model.Call) error {
 	if call.Content == "" {
 		errs = append(errs, "content is required")
 	}
-	if len(call.Destinations) == 0 {
+	if len(call.Destinations) == 0 && !allTriggersHaveDestinations(call.Triggers) {
 		errs = append(errs, "at least one destination is required")
 	}
 	if len(call.Triggers) == 0 {
@@ -39,20 +83,116 @@ func validateCall(call *model.Call) error {
 		if err := validateTrigger(trigger); err != nil {
 			errs = append(errs, err.Error())
 		}
+		switch {
+		case trigger.After == "":
+		case trigger.After == call.ID:
+			errs = append(errs, "a call cannot be sent after itself")
+		case !ids[call.Campaign.ID+"/"+trigger.After]:
+			errs = append(errs, fmt.Sprintf("after refers to '%s', which is not a call of the campaign", trigger.After))
+		}
+		for _, destination := range trigger.Destinations {
+			if err := validateDestination(destination, types); err != nil {
+				errs = append(errs, err.Error())
+			}
+		}
 	}
 
 	for _, destination := range call.Destinations {
-		if err := validateDestination(destination); err != nil {
+		if err := validateDestination(destination, types); err != nil {
 			errs = append(errs, err.Error())
 		}
 	}
 
+	for _, horizon := range []struct {
+		name  string
+		value *model.Horizon
+	}{
+		{"horizon", call.Horizon},
+		{"campaign horizon", call.Campaign.Horizon},
+	} {
+		if horizon.value == nil {
+			continue
+		}
+		for _, bound := range []struct {
+			name  string
+			value string
+		}{
+			{"before", horizon.value.Before},
+			{"after", horizon.value.After},
+		} {
+			if bound.value == "" {
+				continue
+			}
+			if d, err := time.ParseDuration(bound.value); err != nil {
+				errs = append(errs, fmt.Sprintf("invalid %s %s: %s", horizon.name, bound.name, err))
+			} else if d < 0 {
+				errs = append(errs, fmt.Sprintf("invalid %s %s '%s': must not be negative", horizon.name, bound.name, bound.value))
+			}
+		}
+	}
+
+	if call.AutoDeleteAfter != "" {
+		if d, err := time.ParseDuration(call.AutoDeleteAfter); err != nil {
+			errs = append(errs, fmt.Sprintf("invalid auto_delete_after: %s", err))
+		} else if d <= 0 {
+			errs = append(errs, fmt.Sprintf("invalid auto_delete_after '%s': must be positive", call.AutoDeleteAfter))
+		}
+		if call.Mode == model.ModeUpdateStream {
+			errs = append(errs, "auto_delete_after cannot be combined with the update_stream mode")
+		}
+	}
+
+	if call.Confirm != nil {
+		if call.Author == "" {
+			errs = append(errs, "confirm requires an author to ask")
+		}
+		if call.Confirm.Before != "" {
+			if d, err := time.ParseDuration(call.Confirm.Before); err != nil {
+				errs = append(errs, fmt.Sprintf("invalid confirm before: %s", err))
+			} else if d <= 0 {
+				errs = append(errs, fmt.Sprintf("invalid confirm before '%s': must be positive", call.Confirm.Before))
+			}
+		}
+		switch call.Confirm.Default {
+		case "", model.ConfirmSend, model.ConfirmCancel:
+		default:
+			errs = append(errs, fmt.Sprintf("invalid confirm default '%s': must be %s or %s", call.Confirm.Default, model.ConfirmSend, model.ConfirmCancel))
+		}
+	}
+
+	if call.Processors != nil {
+		for _, destType := range slices.Sorted(maps.Keys(call.Processors.Content)) {
+			if _, err := processor.Stack(call.Processors.Content[destType]...); err != nil {
+				errs = append(errs, fmt.Sprintf("invalid content processors for %s: %s", destType, err))
+			}
+		}
+	}
+
+	for _, blackout := range call.Campaign.Blackouts {
+		if !blackout.End.After(blackout.Start) {
+			errs = append(errs, fmt.Sprintf("blackout '%s' must end after it starts", blackout.Reason))
+		}
+	}
+
 	if len(errs) > 0 {
 		return fmt.Errorf("validation failed for call '%s': %s", call.Subject, strings.Join(errs, ", "))
 	}
 	return nil
 }
 
+// allTriggersHaveDestinations reports whether every trigger declares its own destinations.
+func allTriggersHaveDestinations(triggers []model.Trigger) bool {
+	if len(triggers) == 0 {
+		return false
+	}
+	for _, trigger := range triggers {
+		if len(trigger.Destinations) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
 func validateTrigger(trigger model.Trigger) error {
 	var errs []string
 	if trigger.ScheduledAt != (time.Time{}) {
@@ -63,23 +203,123 @@ func validateTrigger(trigger model.Trigger) error {
 			errs = append(errs, fmt.Sprintf("invalid cron expression: %s", err))
 		}
 	}
+	if (!trigger.Until.IsZero() || trigger.Count != 0) && trigger.Cron == "" {
+		errs = append(errs, "until and count require a cron expression")
+	}
+	if trigger.Count < 0 {
+		errs = append(errs, fmt.Sprintf("invalid count %d: must be positive", trigger.Count))
+	}
+	if trigger.Count > 0 && trigger.DStart == "" {
+		errs = append(errs, "count requires a dstart to count from")
+	}
+	if trigger.Timezone != "" {
+		if _, err := time.LoadLocation(trigger.Timezone); err != nil {
+			errs = append(errs, fmt.Sprintf("invalid timezone: %s", err))
+		}
+	}
+	for _, exdate := range trigger.ExDates {
+		if _, err := time.Parse(time.DateOnly, exdate); err == nil {
+			continue
+		}
+		if _, err := time.Parse(time.RFC3339, exdate); err != nil {
+			errs = append(errs, fmt.Sprintf("invalid exdate '%s': must be a date (YYYY-MM-DD) or an RFC 3339 time", exdate))
+		}
+	}
+	if trigger.ExceptRRule != "" {
+		if _, err := rrule.StrToROption(trigger.ExceptRRule); err != nil {
+			errs = append(errs, fmt.Sprintf("invalid except_rrule: %s", err))
+		}
+	}
+	switch trigger.BusinessDay {
+	case "", model.BusinessDayFirst, model.BusinessDayLast:
+		// Valid
+	default:
+		errs = append(errs, fmt.Sprintf("invalid business_day '%s': must be %s or %s", trigger.BusinessDay, model.BusinessDayFirst, model.BusinessDayLast))
+	}
+	if trigger.NthWeekday != "" {
+		if _, _, err := calendar.ParseNthWeekday(trigger.NthWeekday); err != nil {
+			errs = append(errs, fmt.Sprintf("invalid nth_weekday: %s", err))
+		}
+	}
+	for _, date := range []struct {
+		system calendar.System
+		spec   string
+	}{
+		{calendar.Hijri{}, trigger.Hijri},
+		{calendar.Hebrew{}, trigger.Hebrew},
+		{calendar.Chinese{}, trigger.Chinese},
+		{calendar.SolarHijri{}, trigger.SolarHijri},
+	} {
+		if date.spec == "" {
+			continue
+		}
+		if _, err := date.system.ParseDate(date.spec); err != nil {
+			errs = append(errs, fmt.Sprintf("invalid %s date: %s", date.system.Name(), err))
+		}
+	}
+	if trigger.BusinessDay != "" && trigger.NthWeekday != "" {
+		errs = append(errs, "business_day and nth_weekday cannot be combined")
+	}
+	switch trigger.IfHoliday {
+	case "", model.IfHolidaySkip, model.IfHolidayNextBusinessDay:
+		// Valid
+	default:
+		errs = append(errs, fmt.Sprintf("invalid if_holiday '%s': must be one of %s, %s", trigger.IfHoliday, model.IfHolidaySkip, model.IfHolidayNextBusinessDay))
+	}
 	if trigger.Delta != "" {
 		if _, err := time.ParseDuration(trigger.Delta); err != nil {
 			errs = append(errs, fmt.Sprintf("invalid delta: %s", err))
 		}
 	}
+	for _, offset := range []struct {
+		name  string
+		value string
+	}{
+		{"jitter", trigger.Jitter},
+		{"spread", trigger.Spread},
+	} {
+		if offset.value == "" {
+			continue
+		}
+		if d, err := time.ParseDuration(offset.value); err != nil {
+			errs = append(errs, fmt.Sprintf("invalid %s: %s", offset.name, err))
+		} else if d < 0 {
+			errs = append(errs, fmt.Sprintf("invalid %s '%s': must not be negative", offset.name, offset.value))
+		}
+	}
+	switch trigger.DeltaFrom {
+	case "", model.DeltaFromStart, model.DeltaFromEnd:
+		// Valid
+	default:
+		errs = append(errs, fmt.Sprintf("invalid delta_from '%s': must be %s or %s", trigger.DeltaFrom, model.DeltaFromStart, model.DeltaFromEnd))
+	}
+	if trigger.After != "" && trigger.Sequence != "" {
+		errs = append(errs, "after and sequence cannot be combined")
+	}
+	if trigger.DeltaFrom != "" && trigger.Sequence == "" {
+		errs = append(errs, "delta_from requires a sequence")
+	}
 	if len(errs) > 0 {
 		return fmt.Errorf("validation failed for trigger: %s", strings.Join(errs, ", "))
 	}
 	return nil
 }
 
-func validateDestination(destination model.Destination) error {
-	switch destination.Type {
-	case "slack", "email":
-		// Valid
-	default:
+func validateDestination(destination model.Destination, types map[string]bool) error {
+	if !types[destination.Type] {
 		return fmt.Errorf("invalid destination type: %s", destination.Type)
 	}
+	if destination.LocalTime != "" {
+		if _, err := time.Parse("15:04", destination.LocalTime); err != nil {
+			return fmt.Errorf("invalid local_time '%s': must be HH:MM", destination.LocalTime)
+		}
+	}
+	if destination.Type == "homeassistant" {
+		for _, to := range destination.To {
+			if _, _, err := homeassistant.ParseTarget(to); err != nil {
+				return err
+			}
+		}
+	}
 	return nil
 }
@@ -0,0 +1,31 @@
+package worker
+
+import (
+	"log/slog"
+
+	"github.com/andrewhowdencom/ruf/internal/kv"
+	"github.com/andrewhowdencom/ruf/internal/scheduler"
+)
+
+// resolveDependency schedules a call that waits for another call once that call has been sent, so
+// that it does not wait for the schedule to be refreshed. It returns false while the call is still
+// waiting.
+func resolveDependency(store kv.Storer, call *kv.ScheduledCall) (bool, error) {
+	if call.DependsOn == nil || !call.ScheduledAt.IsZero() {
+		return true, nil
+	}
+
+	sentAt, ok, err := scheduler.SentAt(store, call.Campaign.ID, call.DependsOn.CallID)
+	if err != nil || !ok {
+		return false, err
+	}
+	call.ScheduledAt = sentAt.Add(call.DependsOn.Delay).UTC()
+	if !call.ExpiresAt.IsZero() && !call.ScheduledAt.Before(call.ExpiresAt) {
+		slog.Info("dropping call that would be sent after it expires", "call_id", call.ID, "scheduled_at", call.ScheduledAt, "expires_at", call.ExpiresAt)
+		return false, store.DeleteScheduledCall(call.ID)
+	}
+	if err := store.AddScheduledCall(call); err != nil {
+		return false, err
+	}
+	return true, nil
+}
@@ -0,0 +1,55 @@
+package worker
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/andrewhowdencom/ruf/internal/kv"
+	"github.com/andrewhowdencom/ruf/internal/model"
+)
+
+// cancellation returns the cancellation of a scheduled call, or nil if it has not been cancelled.
+func cancellation(store kv.Storer, callID string) (*kv.Cancellation, error) {
+	c, err := store.GetCancellation(callID)
+	if errors.Is(err, kv.ErrNotFound) {
+		return nil, nil
+	}
+	return c, err
+}
+
+// cancelledMessage records that a recipient was not sent a call because it was cancelled.
+func cancelledMessage(call *model.Call, destType, to string, c *kv.Cancellation) *kv.SentMessage {
+	reason := "cancelled"
+	if c.By != "" {
+		reason = fmt.Sprintf("cancelled by %s", c.By)
+	}
+	if c.Reason != "" {
+		reason += ": " + c.Reason
+	}
+	return &kv.SentMessage{
+		SourceID:     call.ID,
+		ScheduledAt:  call.ScheduledAt,
+		Status:       kv.StatusCancelled,
+		Type:         destType,
+		Destination:  to,
+		CampaignName: call.Campaign.Name,
+		Error:        reason,
+	}
+}
+
+// clearCancellation removes the cancellation of a call once the call has been processed, so that it
+// does not outlive the call.
+func clearCancellation(store kv.Storer, callID string) {
+	c, err := cancellation(store, callID)
+	if err != nil {
+		slog.Error("failed to get the cancellation of the call", "call_id", callID, "error", err)
+		return
+	}
+	if c == nil {
+		return
+	}
+	if err := store.DeleteCancellation(callID); err != nil && !errors.Is(err, kv.ErrNotFound) {
+		slog.Error("failed to delete the cancellation of the call", "call_id", callID, "error", err)
+	}
+}
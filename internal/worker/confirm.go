@@ -0,0 +1,98 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/andrewhowdencom/ruf/internal/approval"
+	"github.com/andrewhowdencom/ruf/internal/clients/slack"
+	"github.com/andrewhowdencom/ruf/internal/kv"
+	"github.com/andrewhowdencom/ruf/internal/model"
+	"github.com/andrewhowdencom/ruf/internal/processor"
+)
+
+// defaultConfirmBefore is how long before a call is due its author is asked to confirm it, unless
+// configured otherwise.
+const defaultConfirmBefore = 30 * time.Minute
+
+// confirm sends the author of a call a preview of it, once, when it is within the time before it is due
+// that they are asked. Once the call is due, it is cancelled if the author has not answered and it is
+// not to be sent without their answer. A dry run asks no one.
+func (w *Worker) confirm(ctx context.Context, call *kv.ScheduledCall, now time.Time) {
+	if w.dryRun || call.Call.Author == "" || call.ScheduledAt.IsZero() {
+		return
+	}
+	o := newOptions(w.opts)
+	before, fallback := o.confirmationOf(&call.Call)
+	if now.Before(call.ScheduledAt.Add(-before)) {
+		return
+	}
+
+	c, requested, err := approval.AwaitConfirmation(w.store, call, now)
+	if err != nil {
+		slog.Error("failed to check the confirmation of the call", "call_id", call.Call.ID, "error", err)
+		return
+	}
+	if requested {
+		slog.Info("asking the author to confirm the call", "call_id", call.Call.ID, "author", call.Call.Author, "default", fallback)
+		w.requestConfirmation(ctx, o, call, fallback)
+		return
+	}
+
+	if c.Status == kv.ConfirmationPending && !now.Before(call.ScheduledAt) && fallback == model.ConfirmCancel {
+		slog.Info("cancelling call its author did not confirm", "call_id", call.Call.ID, "author", call.Call.Author)
+		if _, err := approval.Lapse(w.store, call, now); err != nil {
+			slog.Error("failed to cancel the call its author did not confirm", "call_id", call.Call.ID, "error", err)
+		}
+	}
+}
+
+// requestConfirmation sends the author of a call a preview of it, as it will be rendered, with buttons
+// to confirm or cancel it.
+func (w *Worker) requestConfirmation(ctx context.Context, o *options, call *kv.ScheduledCall, fallback string) {
+	if w.slackClient == nil {
+		slog.Warn("call asks its author to confirm it, but slack is not configured", "call_id", call.Call.ID)
+		return
+	}
+	preview := renderPreview(o, &call.Call)
+	err := w.slackClient.RequestConfirmation(ctx, call.Call.Author, call.ID, kv.GenerateShortID(call.ID), preview, fallback, call.ScheduledAt)
+	if err != nil {
+		slog.Error("failed to ask the author to confirm the call", "call_id", call.Call.ID, "author", call.Call.Author, "error", err)
+	}
+}
+
+// renderPreview renders the subject and content of a call as they will be sent, with the recipients they
+// will be sent to. Templates that cannot be rendered yet are shown as they are written.
+func renderPreview(o *options, call *model.Call) string {
+	var funcs []processor.TemplateOption
+	if o.assets != nil {
+		funcs = append(funcs, processor.WithFuncs(o.assets.Funcs(call)))
+	}
+	callData, err := renderData(o, call)
+	if err != nil {
+		slog.Warn("failed to render the data of the preview", "call_id", call.ID, "error", err)
+	}
+	data := make(map[string]interface{})
+	for k, v := range callData {
+		data[k] = v
+	}
+	data["ScheduledAt"] = call.ScheduledAt
+
+	subject, err := processor.NewTemplateProcessor(funcs...).Process(call.Subject, data)
+	if err != nil {
+		subject = call.Subject
+	}
+	content, err := processor.NewTemplateProcessor(funcs...).Process(call.Content, data)
+	if err != nil {
+		content = call.Content
+	}
+
+	var to []string
+	for _, dest := range call.Destinations {
+		to = append(to, fmt.Sprintf("%s (%s)", strings.Join(dest.To, ", "), dest.Type))
+	}
+	return fmt.Sprintf("To: %s\n%s", strings.Join(to, "; "), slack.Text(subject, content))
+}
@@ -0,0 +1,76 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/andrewhowdencom/ruf/internal/clients/email"
+	"github.com/andrewhowdencom/ruf/internal/clients/slack"
+	"github.com/andrewhowdencom/ruf/internal/kv"
+	"github.com/andrewhowdencom/ruf/internal/model"
+)
+
+// addDeadLetter keeps a message that failed to send, with the call it was rendered from, so that it can
+// be replayed. A message that has failed before is kept with one more attempt, and the attempts before
+// it are recorded as the retries of the message.
+func addDeadLetter(store kv.Storer, call *model.Call, sm *kv.SentMessage) error {
+	id := kv.GenerateID(call.Campaign.ID, call.ID, sm.OccurredAt, sm.Type, sm.Destination)
+	letter, err := store.GetDeadLetter(id)
+	if errors.Is(err, kv.ErrNotFound) {
+		letter, err = &kv.DeadLetter{ID: id, CampaignID: call.Campaign.ID, CallID: call.ID, Type: sm.Type, Destination: sm.Destination}, nil
+	}
+	if err != nil {
+		return err
+	}
+
+	// The scheduled time of a call is only kept with it as a scheduled call.
+	data, err := json.Marshal(&kv.ScheduledCall{Call: *call, ScheduledAt: call.ScheduledAt})
+	if err != nil {
+		return fmt.Errorf("%w: failed to marshal call: %w", kv.ErrSerializationFailed, err)
+	}
+	letter.Call, letter.Error, letter.FailedAt = data, sm.Error, time.Now().UTC()
+	sm.Retries = letter.Attempts
+	letter.Attempts++
+	return store.AddDeadLetter(letter)
+}
+
+// Replay sends a dead letter again, as the call it was rendered from, to its destination alone. The dead
+// letter is removed once the message is settled, such as by being sent, with its attempts recorded as
+// the retries of the message, and is kept with one more attempt if the message fails again. It returns the message as it was recorded, or nil in a dry run.
+func Replay(ctx context.Context, letter *kv.DeadLetter, store kv.Storer, slackClient slack.Client, emailClient email.Client, dryRun bool, opts ...Option) (*kv.SentMessage, error) {
+	var scheduled kv.ScheduledCall
+	if err := json.Unmarshal(letter.Call, &scheduled); err != nil {
+		return nil, fmt.Errorf("%w: failed to unmarshal the call of dead letter '%s': %w", kv.ErrSerializationFailed, letter.ID, err)
+	}
+	call := scheduled.Call
+	call.ScheduledAt = scheduled.ScheduledAt
+	call.Destinations = []model.Destination{{Type: letter.Type, To: []string{letter.Destination}}}
+
+	if err := ProcessCall(ctx, &call, store, slackClient, emailClient, dryRun, opts...); err != nil {
+		return nil, err
+	}
+	if dryRun {
+		return nil, nil
+	}
+
+	sm, err := store.GetSentMessage(letter.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the message of dead letter '%s': %w", letter.ID, err)
+	}
+	if sm.Settles(sm.OccurredAt) {
+		// The attempts that failed before the message was sent are its retries, as they are when it fails.
+		if sm.Retries != letter.Attempts {
+			sm.Retries = letter.Attempts
+			if err := store.UpdateSentMessage(sm); err != nil {
+				return nil, fmt.Errorf("failed to record the retries of dead letter '%s': %w", letter.ID, err)
+			}
+		}
+		if err := store.DeleteDeadLetter(letter.ID); err != nil && !errors.Is(err, kv.ErrNotFound) {
+			return nil, fmt.Errorf("failed to delete dead letter '%s': %w", letter.ID, err)
+		}
+	}
+	return sm, nil
+}
@@ -0,0 +1,74 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/andrewhowdencom/ruf/internal/clients/email"
+	"github.com/andrewhowdencom/ruf/internal/clients/slack"
+	"github.com/andrewhowdencom/ruf/internal/kv"
+)
+
+// ErrUncheckedDestination is returned when a recipient of a destination type that cannot be checked is
+// checked.
+var ErrUncheckedDestination = errors.New("recipients of this destination type cannot be checked")
+
+// validateDestination checks that a recipient can be sent to, just before a call is sent to it: that a
+// Slack channel exists, is not archived and has the app as a member, or that an email address parses.
+// It returns an error when the recipient cannot be sent to. A check that could not be made is logged and
+// the call is sent, so that the check failing does not keep calls from being sent.
+func validateDestination(ctx context.Context, slackClient slack.Client, destType, to string) error {
+	var err error
+	switch destType {
+	case "slack":
+		if slackClient == nil {
+			return nil
+		}
+		err = slackClient.ValidateDestination(ctx, to)
+	case "email":
+		err = email.ValidateAddress(to)
+	default:
+		return nil
+	}
+	if err == nil || invalidDestination(err) {
+		return err
+	}
+	slog.Warn("failed to validate destination, sending anyway", "destination", to, "type", destType, "error", err)
+	return nil
+}
+
+// CheckDestination checks a recipient as it is checked just before a call is sent to it, and resolves it
+// as the call would be sent: a Slack destination to the ID of its conversation, and an email address to
+// itself. Unlike the check before a call is sent, a check that could not be made is returned as an error.
+// Recipients of other destination types cannot be checked, and ErrUncheckedDestination is returned for them.
+func CheckDestination(ctx context.Context, slackClient slack.Client, destType, to string) (string, error) {
+	switch destType {
+	case "slack":
+		if err := slackClient.ValidateDestination(ctx, to); err != nil {
+			return "", err
+		}
+		return slackClient.GetChannelID(ctx, to)
+	case "email":
+		if err := email.ValidateAddress(to); err != nil {
+			return "", err
+		}
+		return to, nil
+	default:
+		return "", fmt.Errorf("%w: %s", ErrUncheckedDestination, destType)
+	}
+}
+
+// invalidDestination reports whether an error means the destination cannot be sent to.
+func invalidDestination(err error) bool {
+	return errors.Is(err, slack.ErrInvalidDestination) || errors.Is(err, email.ErrInvalidAddress)
+}
+
+// failedStatus returns the status of a message that could not be sent because of the error.
+func failedStatus(err error) kv.Status {
+	if invalidDestination(err) {
+		return kv.StatusInvalidDestination
+	}
+	return kv.StatusFailed
+}
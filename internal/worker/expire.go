@@ -0,0 +1,61 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/andrewhowdencom/ruf/internal/kv"
+	"github.com/andrewhowdencom/ruf/internal/model"
+	"github.com/spf13/viper"
+)
+
+// deleteAt returns when the Slack message of a call sent at sentAt is deleted, or zero if it is kept.
+func deleteAt(call *model.Call, sentAt time.Time) time.Time {
+	if call.AutoDeleteAfter == "" {
+		return time.Time{}
+	}
+	ttl, err := time.ParseDuration(call.AutoDeleteAfter)
+	if err != nil || ttl <= 0 {
+		slog.Error("ignoring invalid auto_delete_after, keeping the message", "call_id", call.ID, "value", call.AutoDeleteAfter, "error", err)
+		return time.Time{}
+	}
+	return sentAt.Add(ttl)
+}
+
+// expireMessages deletes the Slack messages that are due to be deleted, recording them as expired. A
+// message that cannot be deleted is tried again on the next tick, until worker.missed_lookback has
+// passed since it was due, when it is kept and the failure is recorded on it.
+func (w *Worker) expireMessages(ctx context.Context, now time.Time) {
+	messages, err := w.store.ListExpiringMessages(now)
+	if err != nil {
+		slog.Error("failed to list messages due to be deleted", "error", err)
+		return
+	}
+
+	lookback := viper.GetDuration("worker.missed_lookback")
+	for _, sm := range messages {
+		if w.dryRun {
+			slog.Info("dry run: would delete expired message", "id", sm.ID, "destination", sm.Destination)
+			continue
+		}
+
+		if err := w.slackClient.DeleteMessage(ctx, sm.Destination, sm.Timestamp); err != nil {
+			if now.Before(sm.DeleteAt.Add(lookback)) {
+				slog.Warn("failed to delete expired message, trying again", "id", sm.ID, "destination", sm.Destination, "error", err)
+				continue
+			}
+			slog.Error("failed to delete expired message, keeping it", "id", sm.ID, "destination", sm.Destination, "error", err)
+			sm.Error = fmt.Sprintf("failed to delete the message after it expired at %s: %v", sm.DeleteAt.Format(time.RFC3339), err)
+			sm.DeleteAt = time.Time{}
+		} else {
+			slog.Info("deleted expired message", "id", sm.ID, "destination", sm.Destination)
+			sm.Status = kv.StatusExpired
+		}
+
+		if err := w.store.UpdateSentMessage(sm); err != nil {
+			slog.Error("failed to update expired message", "id", sm.ID, "error", err)
+		}
+	}
+}
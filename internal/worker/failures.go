@@ -0,0 +1,129 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/andrewhowdencom/ruf/internal/clients/email"
+	"github.com/andrewhowdencom/ruf/internal/clients/slack"
+	"github.com/andrewhowdencom/ruf/internal/kv"
+	"github.com/andrewhowdencom/ruf/internal/model"
+	"github.com/andrewhowdencom/ruf/internal/poller"
+)
+
+// failureCallID is the ID of the call that summaries of failures are sent as.
+const failureCallID = "ruf-failure"
+
+// WithFailureChannel posts a summary to a destination whenever a call fails or is missed, or a source is
+// not valid, so that failures are seen by the team rather than only logged.
+func WithFailureChannel(dest model.Destination) Option {
+	return func(o *options) {
+		o.failureChannel = &dest
+	}
+}
+
+// reportFailure posts a summary of a message that failed to the failure channel, if there is one.
+func reportFailure(ctx context.Context, o *options, store kv.Storer, slackClient slack.Client, emailClient email.Client, call *model.Call, sm *kv.SentMessage) {
+	reason := sm.Error
+	if reason == "" {
+		reason = "unknown error"
+	}
+	name := call.Subject
+	if name == "" {
+		name = call.ID
+	}
+	subject := fmt.Sprintf("Failed to send '%s'", name)
+	if strings.HasPrefix(reason, "missed") {
+		subject = fmt.Sprintf("Missed '%s'", name)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "**%s** could not be sent to %s (%s).\n\n", name, sm.Destination, sm.Type)
+	if call.Campaign.Name != "" {
+		fmt.Fprintf(&b, "- Campaign: %s\n", call.Campaign.Name)
+	}
+	fmt.Fprintf(&b, "- Scheduled at: %s\n", sm.ScheduledAt.UTC().Format(time.RFC3339))
+	fmt.Fprintf(&b, "- Reason: %s\n", reason)
+	if sm.ShortID != "" {
+		fmt.Fprintf(&b, "\nTo try again, run `%s`.\n", retryCommand(sm))
+	}
+	postFailure(ctx, o, store, slackClient, emailClient, subject, b.String())
+}
+
+// reportInvalidSources posts a summary of each source that is not valid to the failure channel, if there
+// is one. A source is reported when its problems change, rather than on every poll, and again once it is
+// fixed and breaks once more. Only the leader reports them, so that they are reported once.
+func (w *Worker) reportInvalidSources(ctx context.Context) {
+	o := newOptions(w.opts)
+	if o.failureChannel == nil || !w.IsLeader() {
+		return
+	}
+	if w.invalidReported == nil {
+		w.invalidReported = make(map[string]string)
+	}
+
+	for _, status := range w.poller.Status() {
+		if len(status.Invalid) == 0 {
+			delete(w.invalidReported, status.URL)
+			continue
+		}
+		problems := make([]string, len(status.Invalid))
+		for i, verr := range status.Invalid {
+			problems[i] = verr.Error()
+		}
+		signature := strings.Join(problems, "\n")
+		if w.invalidReported[status.URL] == signature {
+			continue
+		}
+		w.invalidReported[status.URL] = signature
+
+		if w.dryRun {
+			slog.Info("dry run: would report source that is not valid", "url", status.URL)
+			continue
+		}
+		subject := fmt.Sprintf("Source '%s' is not valid", status.URL)
+		var b strings.Builder
+		if status.Health() == poller.HealthSkipped {
+			fmt.Fprintf(&b, "The source %s is not valid, so its calls are not scheduled until it is fixed.\n\n", status.URL)
+		} else {
+			fmt.Fprintf(&b, "Parts of the source %s are not valid, so they are left out of the schedule until they are fixed.\n\n", status.URL)
+		}
+		for _, problem := range problems {
+			fmt.Fprintf(&b, "- %s\n", problem)
+		}
+		postFailure(ctx, o, w.store, w.slackClient, w.emailClient, subject, b.String())
+	}
+}
+
+// postFailure posts a summary to each recipient of the failure channel. Summaries are not recorded as sent
+// messages, and one that cannot be posted is only logged, so that it cannot fail, or report, in turn.
+func postFailure(ctx context.Context, o *options, store kv.Storer, slackClient slack.Client, emailClient email.Client, subject, content string) {
+	dest := o.failureChannel
+	if dest == nil {
+		return
+	}
+	call := &model.Call{ID: failureCallID, Subject: subject, Content: content, ScheduledAt: time.Now().UTC()}
+	stack, err := contentProcessors(o, call, dest.Type)
+	if err == nil {
+		content, err = stack.Process(content, nil)
+	}
+	if err != nil {
+		slog.Error("failed to convert the summary for the failure channel", "type", dest.Type, "subject", subject, "error", err)
+		return
+	}
+
+	send := destination(o, store, slackClient, emailClient, dest.Type)
+	for _, to := range dest.To {
+		sm, err := send.Send(ctx, &Delivery{Call: call, Type: dest.Type, To: to, Subject: subject, Content: content})
+		if err == nil && sm.Failed() {
+			err = errors.New(sm.Error)
+		}
+		if err != nil {
+			slog.Error("failed to post to the failure channel", "type", dest.Type, "destination", to, "subject", subject, "error", err)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package worker
+
+import (
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/andrewhowdencom/ruf/internal/kv"
+)
+
+// leaseName is the name of the lease that elects the worker sending calls.
+const leaseName = "worker"
+
+// IsLeader reports whether the worker holds the lease, and so refreshes the schedule and sends calls.
+// A worker configured without a lease is always the leader.
+func (w *Worker) IsLeader() bool {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.leader
+}
+
+// Heartbeat renews the lease if the worker holds it, or takes it over if the leader has let it expire.
+// It reports whether the worker was promoted to leader. A worker that cannot reach the datastore
+// steps down, since it cannot tell whether another worker has taken over.
+func (w *Worker) Heartbeat() bool {
+	if w.lease == nil {
+		return false
+	}
+
+	lease, err := w.store.AcquireLease(leaseName, w.lease.holder, time.Now(), w.lease.ttl)
+	leader := err == nil
+	switch {
+	case errors.Is(err, kv.ErrLeaseHeld):
+		slog.Debug("lease held by leader", "leader", lease.Holder, "expires", lease.Expires)
+	case err != nil:
+		slog.Error("failed to renew lease", "error", err)
+	}
+
+	w.mu.Lock()
+	promoted := leader && !w.leader
+	demoted := !leader && w.leader
+	w.leader = leader
+	w.mu.Unlock()
+
+	switch {
+	case promoted:
+		slog.Info("promoted to leader", "holder", w.lease.holder)
+		// The schedule may have been written by the old leader from other sources, so the next refresh
+		// polls every source and replaces it.
+		w.poller.Reset()
+		w.lastSourcesHash = ""
+	case demoted:
+		slog.Warn("lost the lease, following", "holder", w.lease.holder)
+	}
+	return promoted
+}
+
+// releaseLease gives up the lease if the worker holds it, by letting it expire now, so that a standby
+// takes over on its next heartbeat rather than once the ttl has run out.
+func (w *Worker) releaseLease() {
+	if w.lease == nil || !w.IsLeader() {
+		return
+	}
+	if _, err := w.store.AcquireLease(leaseName, w.lease.holder, time.Now(), 0); err != nil {
+		slog.Error("failed to release lease", "error", err)
+		return
+	}
+
+	w.mu.Lock()
+	w.leader = false
+	w.mu.Unlock()
+	slog.Info("released the lease", "holder", w.lease.holder)
+}
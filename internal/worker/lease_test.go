@@ -0,0 +1,125 @@
+package worker_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/andrewhowdencom/ruf/internal/clients/email"
+	"github.com/andrewhowdencom/ruf/internal/clients/slack"
+	"github.com/andrewhowdencom/ruf/internal/datastore"
+	"github.com/andrewhowdencom/ruf/internal/model"
+	"github.com/andrewhowdencom/ruf/internal/poller"
+	"github.com/andrewhowdencom/ruf/internal/scheduler"
+	"github.com/andrewhowdencom/ruf/internal/sourcer"
+	"github.com/andrewhowdencom/ruf/internal/worker"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWorker_Lease(t *testing.T) {
+	store := datastore.NewMockStore()
+	s := &mockSourcer{
+		sourcesBySource: map[string]*sourcer.Source{
+			"mock://url": {
+				Calls: []model.Call{
+					{
+						ID:           "1",
+						Content:      "Hello, world!",
+						Destinations: []model.Destination{{Type: "slack", To: []string{"test-channel"}}},
+						Triggers:     []model.Trigger{{ScheduledAt: time.Now().Add(-1 * time.Minute)}},
+						Campaign:     model.Campaign{ID: "mock-campaign", Name: "Mock Campaign"},
+					},
+				},
+			},
+		},
+	}
+	viper.Set("source.urls", []string{"mock://url"})
+	viper.Set("worker.missed_lookback", "10m")
+	viper.Set("worker.calculation.before", "24h")
+	viper.Set("worker.calculation.after", "24h")
+
+	newWorker := func(holder string, slackClient slack.Client) *worker.Worker {
+		w, err := worker.New(store, slackClient, email.NewMockClient(), poller.New(s, time.Minute), scheduler.New(store), time.Minute, false,
+			worker.WithLease(holder, time.Hour))
+		assert.NoError(t, err)
+		return w
+	}
+
+	// Another instance already leads, so the standby follows.
+	_, err := store.AcquireLease("worker", "leader", time.Now(), 50*time.Millisecond)
+	assert.NoError(t, err)
+
+	standbySlack := slack.NewMockClient()
+	standby := newWorker("standby", standbySlack)
+	assert.False(t, standby.Heartbeat())
+	assert.False(t, standby.IsLeader())
+
+	assert.NoError(t, standby.RefreshSources())
+	assert.NoError(t, standby.ProcessMessages(context.Background()))
+	calls, err := store.ListScheduledCalls()
+	assert.NoError(t, err)
+	assert.Empty(t, calls, "a follower does not refresh the schedule")
+	assert.Empty(t, standbySlack.PostMessageCalls())
+
+	// Once the leader stops renewing the lease, the standby is promoted and takes over.
+	time.Sleep(60 * time.Millisecond)
+	assert.True(t, standby.Heartbeat())
+	assert.True(t, standby.IsLeader())
+	assert.False(t, standby.Heartbeat(), "renewing the lease is not a promotion")
+
+	assert.NoError(t, standby.RefreshSources())
+	assert.NoError(t, standby.ProcessMessages(context.Background()))
+	assert.Len(t, standbySlack.PostMessageCalls(), 1)
+
+	// The old leader now follows.
+	old := newWorker("leader", slack.NewMockClient())
+	assert.False(t, old.Heartbeat())
+	assert.False(t, old.IsLeader())
+}
+
+func TestWorker_Shutdown(t *testing.T) {
+	store := datastore.NewMockStore()
+	s := &mockSourcer{
+		sourcesBySource: map[string]*sourcer.Source{
+			"mock://url": {
+				Calls: []model.Call{
+					{
+						ID:           "1",
+						Content:      "Hello, world!",
+						Destinations: []model.Destination{{Type: "slack", To: []string{"test-channel"}}},
+						Triggers:     []model.Trigger{{ScheduledAt: time.Now().Add(-1 * time.Minute)}},
+						Campaign:     model.Campaign{ID: "mock-campaign", Name: "Mock Campaign"},
+					},
+				},
+			},
+		},
+	}
+	viper.Set("source.urls", []string{"mock://url"})
+	viper.Set("worker.missed_lookback", "10m")
+	viper.Set("worker.calculation.before", "24h")
+	viper.Set("worker.calculation.after", "24h")
+
+	// Slack stops responding once the worker is sending the call, and it is asked to shut down.
+	ctx, cancel := context.WithCancel(context.Background())
+	slackClient := slack.NewMockClient()
+	slackClient.PostMessageFunc = func(sendCtx context.Context, channel, author, subject, text string, campaign model.Campaign) (string, string, error) {
+		cancel()
+		<-sendCtx.Done()
+		return "", "", sendCtx.Err()
+	}
+	w, err := worker.New(store, slackClient, email.NewMockClient(), poller.New(s, time.Minute), scheduler.New(store), time.Minute, false,
+		worker.WithLease("leader", time.Hour), worker.WithShutdownTimeout(20*time.Millisecond))
+	assert.NoError(t, err)
+
+	// The send in flight is cancelled once the shutdown timeout runs out, and the worker returns.
+	start := time.Now()
+	assert.NoError(t, w.Run(ctx))
+	assert.Less(t, time.Since(start), time.Second)
+	assert.Len(t, slackClient.PostMessageCalls(), 1)
+
+	// The lease is released, so that a standby takes over without waiting for it to expire.
+	assert.False(t, w.IsLeader())
+	_, err = store.AcquireLease("worker", "standby", time.Now(), time.Hour)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,75 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/andrewhowdencom/ruf/internal/kv"
+	"github.com/andrewhowdencom/ruf/internal/limits"
+)
+
+// ErrDeferred is returned when a call is held back by the limits of its destination. The call should be
+// kept, and processed again later.
+var ErrDeferred = errors.New("deferred by destination limits")
+
+// ErrHeld is returned when a call cannot be rendered with its data in strict mode. The call should be
+// kept, and processed again once its data is available.
+var ErrHeld = errors.New("held as it cannot be rendered")
+
+// pace waits for the rate of a destination type to allow a call, and reports whether it did before the
+// context was done. A call that would wait past the deadline of the context gives its token back at
+// once, to be sent on a later tick instead.
+func pace(ctx context.Context, b *limits.Buckets, destType string) bool {
+	wait := b.Reserve(destType, time.Now())
+	if wait <= 0 {
+		return true
+	}
+	if deadline, ok := ctx.Deadline(); ok && time.Now().Add(wait).After(deadline) {
+		b.Release(destType)
+		return false
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		b.Release(destType)
+		return false
+	}
+}
+
+// allowedAt checks the limits of a recipient at send time, as a backstop to the deferral of the
+// scheduler, counting the messages already sent to it. It returns the time the limits next allow a
+// message, and whether that is now.
+func allowedAt(l *limits.Limits, store kv.Storer, destType, to string, now time.Time) (time.Time, bool) {
+	rule, ok := l.For(destType, to)
+	if !ok {
+		return now, true
+	}
+
+	sent, err := store.ListSentMessagesByDestination(to, 0)
+	if err != nil {
+		// The limits cannot be checked, so the message is sent rather than held back indefinitely.
+		slog.Error("failed to count the messages sent to a destination", "destination", to, "error", err)
+		return now, true
+	}
+	count := func(day time.Time) int {
+		n := 0
+		for _, sm := range sent {
+			if sm.Type == destType && !sm.Failed() && rule.Day(sm.ScheduledAt).Equal(day) {
+				n++
+			}
+		}
+		return n
+	}
+
+	at, ok := rule.Next(now, count)
+	if !ok {
+		return time.Time{}, false
+	}
+	return at, !at.After(now)
+}
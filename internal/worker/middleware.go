@@ -0,0 +1,186 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/andrewhowdencom/ruf/internal/kv"
+	"github.com/andrewhowdencom/ruf/internal/limits"
+	"github.com/andrewhowdencom/ruf/internal/model"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// AllTypes is the destination type of middleware added around the sending of every destination type.
+const AllTypes = "all"
+
+// Delivery is a call rendered for a single recipient, on its way to the destination.
+type Delivery struct {
+	Call    *model.Call
+	Type    string
+	To      string
+	Subject string
+	// Content is the body after it was converted for the destination type.
+	Content string
+	// Data is the data the call was rendered with.
+	Data map[string]interface{}
+	// Snapshot is what is kept with the message, so that it can be exported as it was delivered.
+	Snapshot *kv.Snapshot
+}
+
+// message returns the message a delivery is recorded as, once its destination has accepted it with the
+// ID, or failed with the error.
+func (d *Delivery) message(messageID string, latency time.Duration, err error) *kv.SentMessage {
+	sm := &kv.SentMessage{
+		SourceID:     d.Call.ID,
+		ScheduledAt:  d.Call.ScheduledAt,
+		Destination:  d.To,
+		Type:         d.Type,
+		CampaignName: d.Call.Campaign.Name,
+		MessageID:    messageID,
+		Latency:      latency,
+		Snapshot:     d.Snapshot,
+		Status:       kv.StatusSent,
+	}
+	if err != nil {
+		sm.Status, sm.Error = kv.StatusFailed, err.Error()
+	}
+	return sm
+}
+
+// Sender sends a delivery to its destination. It returns the message to record, which may have failed,
+// or an error if nothing was sent. An error wrapping ErrDeferred keeps the recipient for a later tick;
+// any other error stops the call from being processed.
+type Sender interface {
+	Send(ctx context.Context, d *Delivery) (*kv.SentMessage, error)
+}
+
+// SenderFunc is a function that sends a delivery.
+type SenderFunc func(ctx context.Context, d *Delivery) (*kv.SentMessage, error)
+
+// Send calls f.
+func (f SenderFunc) Send(ctx context.Context, d *Delivery) (*kv.SentMessage, error) {
+	return f(ctx, d)
+}
+
+// Middleware wraps the sending of deliveries with a behaviour that does not depend on the destination,
+// such as logging or metrics. It may send the delivery with next, change it first, or decide not to.
+type Middleware func(next Sender) Sender
+
+// WithMiddleware adds middleware around the sending of messages to a destination type, or to every type
+// with AllTypes. The middleware of every type wraps that of a single type, and the first given is the
+// outermost. Each is outside the pacing of the rate limits and the record of the message being sent.
+func WithMiddleware(destType string, mw ...Middleware) Option {
+	return func(o *options) {
+		if o.middleware == nil {
+			o.middleware = make(map[string][]Middleware)
+		}
+		o.middleware[destType] = append(o.middleware[destType], mw...)
+	}
+}
+
+// sender returns the sender of a destination type with its middleware around it.
+func (o *options) sender(store kv.Storer, send Sender, destType string) Sender {
+	chain := append(append([]Middleware{}, o.middleware[AllTypes]...), o.middleware[destType]...)
+	chain = append(chain, pacing(o.rates, destType), recording(store))
+	for i := len(chain) - 1; i >= 0; i-- {
+		send = chain[i](send)
+	}
+	return send
+}
+
+// pacing waits for the rate of a destination type to allow each delivery, deferring those that would
+// wait past the send timeout.
+func pacing(b *limits.Buckets, destType string) Middleware {
+	return func(next Sender) Sender {
+		return SenderFunc(func(ctx context.Context, d *Delivery) (*kv.SentMessage, error) {
+			if !pace(ctx, b, destType) {
+				slog.Info("deferring message to respect the rate limit of its destination type", "call_id", d.Call.ID, "destination", d.To, "type", d.Type)
+				return nil, fmt.Errorf("%w: rate limit of %s", ErrDeferred, destType)
+			}
+			return next.Send(ctx, d)
+		})
+	}
+}
+
+// recording records each delivery as being sent before it is, and puts the record back if nothing was
+// sent after all.
+func recording(store kv.Storer) Middleware {
+	return func(next Sender) Sender {
+		return SenderFunc(func(ctx context.Context, d *Delivery) (*kv.SentMessage, error) {
+			pending, err := beginSending(store, d.Call, d.Type, d.To)
+			if err != nil {
+				return nil, err
+			}
+			sm, err := next.Send(ctx, d)
+			if err != nil {
+				if abandonErr := pending.abandon(); abandonErr != nil {
+					return nil, errors.Join(err, fmt.Errorf("failed to record that the message to %s was not sent: %w", d.To, abandonErr))
+				}
+				return nil, err
+			}
+			return sm, nil
+		})
+	}
+}
+
+// Logging logs each delivery once it has been attempted: its recipient, subject, outcome and how long the
+// destination took, as a single line for log pipelines to follow deliveries by.
+func Logging() Middleware {
+	return func(next Sender) Sender {
+		return SenderFunc(func(ctx context.Context, d *Delivery) (*kv.SentMessage, error) {
+			start := time.Now()
+			sm, err := next.Send(ctx, d)
+			attrs := []any{"call_id", d.Call.ID, "campaign", d.Call.Campaign.Name, "type", d.Type, "destination", d.To, "subject", d.Subject, "duration", time.Since(start)}
+			switch {
+			case errors.Is(err, ErrDeferred):
+				slog.Info("delivery deferred", append(attrs, "error", err)...)
+			case err != nil:
+				slog.Error("delivery not attempted", append(attrs, "error", err)...)
+			case sm.Failed():
+				slog.Warn("delivery failed", append(attrs, "status", sm.Status, "error", sm.Error)...)
+			default:
+				slog.Info("delivery sent", append(attrs, "status", sm.Status, "message_id", sm.MessageID)...)
+			}
+			return sm, err
+		})
+	}
+}
+
+// Metrics counts the deliveries by destination type and outcome, and records how long each took, with the
+// meter.
+func Metrics(meter metric.Meter) (Middleware, error) {
+	deliveries, err := meter.Int64Counter("ruf.deliveries",
+		metric.WithDescription("The number of messages sent, or attempted, by destination type and status."))
+	if err != nil {
+		return nil, err
+	}
+	duration, err := meter.Float64Histogram("ruf.delivery.duration",
+		metric.WithDescription("How long sending a message took, including waiting for its rate limit."),
+		metric.WithUnit("s"))
+	if err != nil {
+		return nil, err
+	}
+
+	return func(next Sender) Sender {
+		return SenderFunc(func(ctx context.Context, d *Delivery) (*kv.SentMessage, error) {
+			start := time.Now()
+			sm, err := next.Send(ctx, d)
+
+			status := "error"
+			switch {
+			case errors.Is(err, ErrDeferred):
+				status = "deferred"
+			case err == nil:
+				status = string(sm.Status)
+			}
+			attrs := metric.WithAttributes(attribute.String("type", d.Type), attribute.String("status", status))
+			deliveries.Add(ctx, 1, attrs)
+			duration.Record(ctx, time.Since(start).Seconds(), attrs)
+			return sm, err
+		})
+	}, nil
+}
@@ -0,0 +1,155 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/andrewhowdencom/ruf/internal/clients/email"
+	"github.com/andrewhowdencom/ruf/internal/clients/slack"
+	"github.com/andrewhowdencom/ruf/internal/kv"
+	"github.com/andrewhowdencom/ruf/internal/model"
+)
+
+// recordSentMessage records the outcome of sending a call to a recipient, and tells the author and the
+// owners of the call, and the failure channel, when it failed. A failed message is also kept as a dead letter, to be replayed.
+func recordSentMessage(ctx context.Context, o *options, store kv.Storer, slackClient slack.Client, emailClient email.Client, call *model.Call, sm *kv.SentMessage) error {
+	if sm.Status == kv.StatusSent && sm.SentAt.IsZero() {
+		sm.SentAt = time.Now().UTC()
+	}
+	if sm.OccurredAt.IsZero() {
+		sm.OccurredAt = call.OccurredAt().UTC()
+	}
+	if sm.Failed() {
+		if err := addDeadLetter(store, call, sm); err != nil {
+			slog.Error("failed to keep the failed message for replay", "call_id", call.ID, "destination", sm.Destination, "error", err)
+		}
+	}
+	if err := store.AddSentMessage(call.Campaign.ID, call.ID, sm); err != nil {
+		return err
+	}
+	if sm.Failed() && o.failureChannel != nil {
+		reportFailure(ctx, o, store, slackClient, emailClient, call, sm)
+	}
+	if sm.Failed() && o.notifyFailures {
+		for _, to := range recipients(call.Author, o.ownersOf(call)) {
+			notifyFailure(ctx, slackClient, emailClient, call, sm, to)
+		}
+	}
+	return nil
+}
+
+// recipients returns the author and the owners of a call, each once.
+func recipients(author string, owners []string) []string {
+	var to []string
+	if author != "" {
+		to = append(to, author)
+	}
+	for _, owner := range owners {
+		if !slices.Contains(to, owner) {
+			to = append(to, owner)
+		}
+	}
+	return to
+}
+
+// notifyFailure tells the author or an owner of a call that it could not be sent, why, and how to send
+// it again. They are sent a direct message on Slack, or an email when the call was an email or the
+// direct message could not be sent.
+func notifyFailure(ctx context.Context, slackClient slack.Client, emailClient email.Client, call *model.Call, sm *kv.SentMessage, to string) {
+	reason := sm.Error
+	if reason == "" {
+		reason = "unknown error"
+	}
+	retry := retryCommand(sm)
+
+	if sm.Type != "email" && slackClient != nil {
+		err := slackClient.NotifyAuthorOfFailure(ctx, to, sm.Destination, reason, retry)
+		if err == nil {
+			return
+		}
+		slog.Warn("failed to notify of failure on slack, trying email", "call_id", call.ID, "to", to, "error", err)
+	}
+	if emailClient == nil {
+		return
+	}
+
+	subject := fmt.Sprintf("Your message could not be sent to %s", sm.Destination)
+	if call.Subject != "" {
+		subject = fmt.Sprintf("Your message '%s' could not be sent to %s", call.Subject, sm.Destination)
+	}
+	body := fmt.Sprintf("I could not send your message to %s (%s): %s\n\nTo try again, run:\n\n    %s\n", sm.Destination, sm.Type, reason, retry)
+	if _, err := emailClient.Send(ctx, []string{to}, "", subject, body, call.Campaign); err != nil {
+		slog.Error("failed to notify of failure", "call_id", call.ID, "to", to, "error", err)
+	}
+}
+
+// requestSignoff asks the owners of a call that is due to sign off the items of its checklist that it
+// is held for. They are sent a direct message on Slack, or an email when it could not be sent.
+func requestSignoff(ctx context.Context, slackClient slack.Client, emailClient email.Client, call *kv.ScheduledCall, owners, pending []string) {
+	shortID := kv.GenerateShortID(call.ID)
+	for _, to := range owners {
+		if slackClient != nil {
+			err := slackClient.RequestSignoff(ctx, to, call.ID, shortID, pending)
+			if err == nil {
+				continue
+			}
+			slog.Warn("failed to request sign-off on slack, trying email", "call_id", call.ID, "to", to, "error", err)
+		}
+		if emailClient == nil {
+			continue
+		}
+
+		subject := fmt.Sprintf("Sign-off needed for '%s'", call.Call.ID)
+		if call.Call.Subject != "" {
+			subject = fmt.Sprintf("Sign-off needed for '%s'", call.Call.Subject)
+		}
+		body := fmt.Sprintf("The call %s is due, but waits for sign-off of: %s\n\nTo sign off an item, run:\n\n    ruf checklist check %s <item>\n", call.ID, strings.Join(pending, ", "), shortID)
+		if _, err := emailClient.Send(ctx, []string{to}, "", subject, body, call.Call.Campaign); err != nil {
+			slog.Error("failed to request sign-off", "call_id", call.ID, "to", to, "error", err)
+		}
+	}
+}
+
+// requestApproval asks the approvers of a call that is due to approve it. They are sent a direct message
+// on Slack, with buttons that approve or reject it, or an email when it could not be sent.
+func requestApproval(ctx context.Context, slackClient slack.Client, emailClient email.Client, call *kv.ScheduledCall, approvers []string) {
+	shortID := kv.GenerateShortID(call.ID)
+	summary := call.Call.Subject
+	if summary == "" {
+		summary = call.Call.Content
+	}
+	if r := []rune(summary); len(r) > 200 {
+		summary = string(r[:200]) + "…"
+	}
+
+	for _, to := range approvers {
+		if slackClient != nil {
+			err := slackClient.RequestApproval(ctx, to, call.ID, shortID, summary)
+			if err == nil {
+				continue
+			}
+			slog.Warn("failed to request approval on slack, trying email", "call_id", call.ID, "to", to, "error", err)
+		}
+		if emailClient == nil {
+			continue
+		}
+
+		subject := fmt.Sprintf("Approval needed for '%s'", call.Call.ID)
+		if call.Call.Subject != "" {
+			subject = fmt.Sprintf("Approval needed for '%s'", call.Call.Subject)
+		}
+		body := fmt.Sprintf("The call %s is due, but waits for approval:\n\n%s\n\nTo approve it, run:\n\n    ruf approvals approve %s\n\nTo reject it, run:\n\n    ruf approvals reject %s\n", call.ID, summary, shortID, shortID)
+		if _, err := emailClient.Send(ctx, []string{to}, "", subject, body, call.Call.Campaign); err != nil {
+			slog.Error("failed to request approval", "call_id", call.ID, "to", to, "error", err)
+		}
+	}
+}
+
+// retryCommand returns the command that replays the message that failed, from its dead letter.
+func retryCommand(sm *kv.SentMessage) string {
+	return fmt.Sprintf("ruf dispatcher replay %s", sm.ShortID)
+}
@@ -1,8 +1,12 @@
 package worker
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"strings"
+	"time"
 
 	"github.com/andrewhowdencom/ruf/internal/clients/email"
 	"github.com/andrewhowdencom/ruf/internal/clients/slack"
@@ -12,8 +16,30 @@ import (
 )
 
 // ProcessCall handles the processing of a single call, including rendering, sending, and recording the status.
-func ProcessCall(call *model.Call, store kv.Storer, slackClient slack.Client, emailClient email.Client, dryRun bool) error {
+// Sending gives up once the context is done, or once the call has taken longer than its send timeout.
+// While the campaign of the call is on trial, the call is logged or sent to its canary destination.
+// Each message is sent through the middleware of its destination type, and is recorded as being sent
+// before its destination is called, with the record replaced by the outcome once it has been.
+func ProcessCall(ctx context.Context, call *model.Call, store kv.Storer, slackClient slack.Client, emailClient email.Client, dryRun bool, opts ...Option) error {
 	slog.Debug("processing call", "call_id", call.ID)
+	o := newOptions(opts)
+
+	trial, err := onTrial(store, call)
+	if err != nil {
+		return fmt.Errorf("failed to check if the campaign of the call is on trial: %w", err)
+	}
+	if trial {
+		return processTrial(ctx, call, store, slackClient, emailClient, dryRun, opts...)
+	}
+
+	// What was sent is recorded even once the call has run out of time, so that it is not sent again.
+	recorder := kv.WithContext(context.WithoutCancel(ctx), store)
+	if o.sendTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, o.sendTimeout)
+		defer cancel()
+	}
+	store = kv.WithContext(ctx, store)
 	effectiveScheduledAt := call.ScheduledAt
 
 	dest := call.Destinations[0]
@@ -22,8 +48,14 @@ func ProcessCall(call *model.Call, store kv.Storer, slackClient slack.Client, em
 		return nil
 	}
 
+	contentProcessor, err := contentProcessors(o, call, dest.Type)
+	if err != nil {
+		return err
+	}
+	sender := o.sender(recorder, destination(o, store, slackClient, emailClient, dest.Type), dest.Type)
+	var deferred, held []string
 	for _, to := range dest.To {
-		hasBeenSent, err := store.HasBeenSent(call.Campaign.ID, call.ID, dest.Type, to)
+		hasBeenSent, err := store.HasBeenSent(call.Campaign.ID, call.ID, call.OccurredAt(), dest.Type, to)
 		if err != nil {
 			return fmt.Errorf("failed to check if call has been sent: %w", err)
 		}
@@ -32,126 +64,213 @@ func ProcessCall(call *model.Call, store kv.Storer, slackClient slack.Client, em
 			continue
 		}
 
-		// Define the processor stacks for each destination type
-		var subjectProcessor, contentProcessor processor.ProcessorStack
-		switch dest.Type {
-		case "slack":
-			subjectProcessor = processor.ProcessorStack{
-				processor.NewTemplateProcessor(),
-			}
-			contentProcessor = processor.ProcessorStack{
-				processor.NewTemplateProcessor(),
-				processor.NewMarkdownToSlackProcessor(),
-			}
-		case "email":
-			subjectProcessor = processor.ProcessorStack{
-				processor.NewTemplateProcessor(),
+		// The cancellation is checked before each recipient, so that cancelling a call part way through
+		// sending it stops it from reaching the recipients that remain.
+		cancelled, err := cancellation(store, call.ID)
+		if err != nil {
+			return fmt.Errorf("failed to check if call has been cancelled: %w", err)
+		}
+		if cancelled != nil {
+			if dryRun {
+				slog.Info("dry run: message would not be sent as the call was cancelled", "call_id", call.ID, "destination", to, "type", dest.Type)
+				continue
 			}
-			contentProcessor = processor.ProcessorStack{
-				processor.NewTemplateProcessor(),
-				processor.NewMarkdownToHTMLProcessor(),
+			slog.Info("not sending cancelled call", "call_id", call.ID, "destination", to, "type", dest.Type, "by", cancelled.By)
+			if err := recordSentMessage(ctx, o, recorder, slackClient, emailClient, call, cancelledMessage(call, dest.Type, to, cancelled)); err != nil {
+				return err
 			}
-		default:
-			return fmt.Errorf("unsupported destination type: %s", dest.Type)
+			continue
 		}
 
+		// Define the processor stacks for each destination type. The content is rendered as a template
+		// first, and then converted for the destination.
+		var funcs []processor.TemplateOption
+		if o.assets != nil {
+			funcs = append(funcs, processor.WithFuncs(o.assets.Funcs(call)))
+		}
+		strict := call.Processors.Strict(o.strict)
+		if strict {
+			funcs = append(funcs, processor.WithStrict())
+		}
+		subjectProcessor := processor.ProcessorStack{
+			processor.NewTemplateProcessor(funcs...),
+		}
+		callData, dataErr := renderData(o, call)
+		err = dataErr
 		data := make(map[string]interface{})
-		if call.Data != nil {
-			for k, v := range call.Data {
-				data[k] = v
-			}
+		for k, v := range callData {
+			data[k] = v
 		}
 		data["ScheduledAt"] = effectiveScheduledAt
 
-		subject, err := subjectProcessor.Process(call.Subject, data)
+		var subject string
+		if err == nil {
+			subject, err = subjectProcessor.Process(call.Subject, data)
+		}
+		if strict && (dataErr != nil || errors.Is(err, processor.ErrMissingValue)) {
+			if err := holdCall(recorder, call, dest.Type, to, err, dryRun); err != nil {
+				return err
+			}
+			held = append(held, to)
+			continue
+		}
 		if err != nil {
 			slog.Error("failed to process subject", "error", err)
-			store.AddSentMessage(call.Campaign.ID, call.ID, &kv.SentMessage{
+			recordSentMessage(ctx, o, recorder, slackClient, emailClient, call, &kv.SentMessage{
 				SourceID:     call.ID,
 				ScheduledAt:  effectiveScheduledAt,
 				Status:       kv.StatusFailed,
 				Type:         dest.Type,
 				Destination:  to,
 				CampaignName: call.Campaign.Name,
+				Error:        err.Error(),
 			})
 			continue
 		}
-		content, err := contentProcessor.Process(call.Content, data)
+		rendered, err := processor.NewTemplateProcessor(funcs...).Process(call.Content, data)
+		var content string
+		if err == nil {
+			content, err = contentProcessor.Process(rendered, data)
+		}
+		if strict && errors.Is(err, processor.ErrMissingValue) {
+			if err := holdCall(recorder, call, dest.Type, to, err, dryRun); err != nil {
+				return err
+			}
+			held = append(held, to)
+			continue
+		}
 		if err != nil {
 			slog.Error("failed to process content", "error", err)
-			store.AddSentMessage(call.Campaign.ID, call.ID, &kv.SentMessage{
+			recordSentMessage(ctx, o, recorder, slackClient, emailClient, call, &kv.SentMessage{
 				SourceID:     call.ID,
 				ScheduledAt:  effectiveScheduledAt,
 				Status:       kv.StatusFailed,
 				Type:         dest.Type,
 				Destination:  to,
 				CampaignName: call.Campaign.Name,
+				Error:        err.Error(),
 			})
 			continue
 		}
 
-		if dryRun {
-			slog.Info("dry run: would send message", "call_id", call.ID, "campaign", call.Campaign.Name, "subject", subject, "destination", to, "type", dest.Type, "scheduled_at", effectiveScheduledAt)
-			continue
+		// What is sent is kept with the message, so that it can be exported as it was delivered.
+		snapshot := &kv.Snapshot{Author: call.Author, Data: callData}
+		if o.recordContent {
+			snapshot.Subject, snapshot.Content = subject, rendered
 		}
 
-		switch dest.Type {
-		case "slack":
-			slog.Info("sending slack message", "call_id", call.ID, "destination", to, "scheduled_at", effectiveScheduledAt)
-			channelID, timestamp, err := slackClient.PostMessage(to, call.Author, subject, content, call.Campaign)
-			sentMessage := &kv.SentMessage{
+		if err := o.policy.Dispatch(call, dest.Type, to); err != nil {
+			if dryRun {
+				slog.Info("dry run: message would be blocked by policy", "call_id", call.ID, "destination", to, "type", dest.Type, "error", err)
+				continue
+			}
+			slog.Warn("blocking message that violates policy", "call_id", call.ID, "destination", to, "type", dest.Type, "error", err)
+			if err := recordSentMessage(ctx, o, recorder, slackClient, emailClient, call, &kv.SentMessage{
 				SourceID:     call.ID,
 				ScheduledAt:  effectiveScheduledAt,
-				Timestamp:    timestamp,
-				Destination:  to,
+				Status:       kv.StatusFailed,
 				Type:         dest.Type,
+				Destination:  to,
 				CampaignName: call.Campaign.Name,
+				Error:        err.Error(),
+			}); err != nil {
+				return err
 			}
+			continue
+		}
 
-			if err != nil {
-				sentMessage.Status = kv.StatusFailed
-				slog.Error("failed to send slack message", "error", err)
-			} else {
-				sentMessage.Status = kv.StatusSent
-				slog.Info("sent slack message", "call_id", call.ID, "destination", to, "scheduled_at", effectiveScheduledAt)
-
-				if call.Author != "" {
-					err := slackClient.NotifyAuthor(call.Author, channelID, timestamp, to)
-					if err != nil {
-						slog.Error("failed to send author notification", "error", err)
-					}
-				}
-			}
-
-			if err := store.AddSentMessage(call.Campaign.ID, call.ID, sentMessage); err != nil {
-				return err
+		if err := validateDestination(ctx, slackClient, dest.Type, to); err != nil {
+			if dryRun {
+				slog.Info("dry run: message would not be sent as its destination is invalid", "call_id", call.ID, "destination", to, "type", dest.Type, "error", err)
+				continue
 			}
-		case "email":
-			slog.Info("sending email", "call_id", call.ID, "recipient", to, "scheduled_at", effectiveScheduledAt)
-			err := emailClient.Send([]string{to}, call.Author, subject, content, call.Campaign)
-			sentMessage := &kv.SentMessage{
+			slog.Warn("not sending message to invalid destination", "call_id", call.ID, "destination", to, "type", dest.Type, "error", err)
+			if err := recordSentMessage(ctx, o, recorder, slackClient, emailClient, call, &kv.SentMessage{
 				SourceID:     call.ID,
 				ScheduledAt:  effectiveScheduledAt,
-				Destination:  to,
+				Status:       kv.StatusInvalidDestination,
 				Type:         dest.Type,
+				Destination:  to,
 				CampaignName: call.Campaign.Name,
+				Error:        err.Error(),
+			}); err != nil {
+				return err
 			}
+			continue
+		}
 
-			if err != nil {
-				sentMessage.Status = kv.StatusFailed
-				slog.Error("failed to send email", "error", err)
-			} else {
-				sentMessage.Status = kv.StatusSent
-				slog.Info("sent email", "call_id", call.ID, "recipient", to, "scheduled_at", effectiveScheduledAt)
+		if at, ok := allowedAt(o.limits, store, dest.Type, to, time.Now()); !ok {
+			if dryRun {
+				slog.Info("dry run: message would be deferred by destination limits", "call_id", call.ID, "destination", to, "type", dest.Type, "deferred_to", at)
+				continue
 			}
+			slog.Info("deferring message to respect destination limits", "call_id", call.ID, "destination", to, "type", dest.Type, "deferred_to", at)
+			deferred = append(deferred, to)
+			continue
+		}
 
-			if err := store.AddSentMessage(call.Campaign.ID, call.ID, sentMessage); err != nil {
-				return err
-			}
-		default:
-			return fmt.Errorf("unsupported destination type: %s", dest.Type)
+		if dryRun {
+			slog.Info("dry run: would send message", "call_id", call.ID, "campaign", call.Campaign.Name, "subject", subject, "destination", to, "type", dest.Type, "scheduled_at", effectiveScheduledAt)
+			continue
+		}
+
+		sm, err := sender.Send(ctx, &Delivery{
+			Call:     call,
+			Type:     dest.Type,
+			To:       to,
+			Subject:  subject,
+			Content:  content,
+			Data:     data,
+			Snapshot: snapshot,
+		})
+		if errors.Is(err, ErrDeferred) {
+			deferred = append(deferred, to)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if err := recordSentMessage(ctx, o, recorder, slackClient, emailClient, call, sm); err != nil {
+			return err
 		}
 	}
 
+	if len(deferred) > 0 {
+		return fmt.Errorf("%w: %s", ErrDeferred, strings.Join(deferred, ", "))
+	}
+	if len(held) > 0 {
+		return fmt.Errorf("%w: %s", ErrHeld, strings.Join(held, ", "))
+	}
 	return nil
 }
+
+// holdCall records that a call could not be rendered for a recipient in strict mode, and why, so that
+// it is shown while the call is held. The record is replaced when the call is sent, or missed.
+func holdCall(store kv.Storer, call *model.Call, destType, to string, reason error, dryRun bool) error {
+	if dryRun {
+		slog.Info("dry run: message would be held as it cannot be rendered", "call_id", call.ID, "destination", to, "type", destType, "error", reason)
+		return nil
+	}
+	slog.Warn("holding message that cannot be rendered", "call_id", call.ID, "destination", to, "type", destType, "error", reason)
+	return store.AddSentMessage(call.Campaign.ID, call.ID, &kv.SentMessage{
+		SourceID:     call.ID,
+		ScheduledAt:  call.ScheduledAt,
+		OccurredAt:   call.OccurredAt().UTC(),
+		Status:       kv.StatusHeld,
+		Type:         destType,
+		Destination:  to,
+		CampaignName: call.Campaign.Name,
+		Error:        reason.Error(),
+	})
+}
+
+// renderData returns the data a call is rendered with, including that of its data_from.
+func renderData(o *options, call *model.Call) (map[string]interface{}, error) {
+	if o.assets == nil {
+		if call.DataFrom != "" {
+			return nil, fmt.Errorf("call %s takes its data from %s, but no asset cache is configured", call.ID, call.DataFrom)
+		}
+		return call.Data, nil
+	}
+	return o.assets.Data(call)
+}
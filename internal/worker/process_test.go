@@ -0,0 +1,825 @@
+package worker_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/andrewhowdencom/ruf/internal/clients/chatwork"
+	"github.com/andrewhowdencom/ruf/internal/clients/email"
+	"github.com/andrewhowdencom/ruf/internal/clients/homeassistant"
+	"github.com/andrewhowdencom/ruf/internal/clients/slack"
+	"github.com/andrewhowdencom/ruf/internal/clients/slackworkflow"
+	"github.com/andrewhowdencom/ruf/internal/clients/webhook"
+	"github.com/andrewhowdencom/ruf/internal/datastore"
+	"github.com/andrewhowdencom/ruf/internal/kv"
+	"github.com/andrewhowdencom/ruf/internal/limits"
+	"github.com/andrewhowdencom/ruf/internal/model"
+	"github.com/andrewhowdencom/ruf/internal/owners"
+	"github.com/andrewhowdencom/ruf/internal/policy"
+	"github.com/andrewhowdencom/ruf/internal/processor"
+	"github.com/andrewhowdencom/ruf/internal/worker"
+	slackapi "github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProcessCall_RecordsDeliveryMetadata(t *testing.T) {
+	call := &model.Call{
+		ID:          "1",
+		Content:     "Hello, world!",
+		ScheduledAt: time.Now(),
+		Destinations: []model.Destination{
+			{Type: "slack", To: []string{"#general"}},
+		},
+		Campaign: model.Campaign{ID: "campaign", Name: "Campaign"},
+	}
+
+	t.Run("successful delivery", func(t *testing.T) {
+		store := datastore.NewMockStore()
+		slackClient := slack.NewMockClient()
+
+		assert.NoError(t, worker.ProcessCall(context.Background(), call, store, slackClient, email.NewMockClient(), false))
+
+		sm, err := store.GetSentMessage(kv.GenerateID("campaign", "1", call.OccurredAt(), "slack", "#general"))
+		assert.NoError(t, err)
+		assert.Equal(t, kv.StatusSent, sm.Status)
+		assert.Equal(t, "1234567890.123456", sm.MessageID)
+		assert.Equal(t, "https://example.slack.com/archives/C1234567890/p1234567890123456", sm.Permalink)
+		assert.Empty(t, sm.Error)
+	})
+
+	t.Run("failed delivery", func(t *testing.T) {
+		store := datastore.NewMockStore()
+		slackClient := slack.NewMockClient()
+		slackClient.PostMessageFunc = func(_ context.Context, channel, author, subject, text string, campaign model.Campaign) (string, string, error) {
+			return "", "", errors.New("channel_not_found")
+		}
+
+		assert.NoError(t, worker.ProcessCall(context.Background(), call, store, slackClient, email.NewMockClient(), false))
+
+		sm, err := store.GetSentMessage(kv.GenerateID("campaign", "1", call.OccurredAt(), "slack", "#general"))
+		assert.NoError(t, err)
+		assert.Equal(t, kv.StatusFailed, sm.Status)
+		assert.Equal(t, "channel_not_found", sm.Error)
+	})
+
+	t.Run("content snapshot", func(t *testing.T) {
+		call := *call
+		call.Content = "Hello, **{{ .Name }}**!"
+		call.Data = map[string]interface{}{"Name": "world"}
+
+		store := datastore.NewMockStore()
+		assert.NoError(t, worker.ProcessCall(context.Background(), &call, store, slack.NewMockClient(), email.NewMockClient(), false, worker.WithContentSnapshots()))
+		sm, err := store.GetSentMessage(kv.GenerateID("campaign", "1", call.OccurredAt(), "slack", "#general"))
+		assert.NoError(t, err)
+		// The content is kept as Markdown, before it was converted for Slack.
+		assert.Equal(t, &kv.Snapshot{Content: "Hello, **world**!", Data: call.Data}, sm.Snapshot)
+
+		store = datastore.NewMockStore()
+		assert.NoError(t, worker.ProcessCall(context.Background(), &call, store, slack.NewMockClient(), email.NewMockClient(), false))
+		sm, err = store.GetSentMessage(kv.GenerateID("campaign", "1", call.OccurredAt(), "slack", "#general"))
+		assert.NoError(t, err)
+		assert.Equal(t, &kv.Snapshot{Data: call.Data}, sm.Snapshot)
+	})
+}
+
+func TestProcessCall_Chatwork(t *testing.T) {
+	call := &model.Call{
+		ID:          "1",
+		Subject:     "Hello",
+		Content:     "Hello, {{ .Name }}!",
+		Data:        map[string]interface{}{"Name": "world"},
+		ScheduledAt: time.Now(),
+		Destinations: []model.Destination{
+			{Type: "chatwork", To: []string{"12345"}},
+		},
+		Campaign: model.Campaign{ID: "campaign", Name: "Campaign"},
+	}
+
+	t.Run("requires a configured client", func(t *testing.T) {
+		err := worker.ProcessCall(context.Background(), call, datastore.NewMockStore(), slack.NewMockClient(), email.NewMockClient(), false)
+		assert.Error(t, err)
+	})
+
+	t.Run("sends through the configured client", func(t *testing.T) {
+		store := datastore.NewMockStore()
+		chatworkClient := chatwork.NewMockClient()
+
+		err := worker.ProcessCall(context.Background(), call, store, slack.NewMockClient(), email.NewMockClient(), false, worker.WithChatworkClient(chatworkClient))
+		assert.NoError(t, err)
+
+		assert.Len(t, chatworkClient.PostMessageCalls(), 1)
+		assert.Equal(t, "12345", chatworkClient.PostMessageCalls()[0].RoomID)
+		assert.Equal(t, "Hello, world!", chatworkClient.PostMessageCalls()[0].Body)
+
+		sm, err := store.GetSentMessage(kv.GenerateID("campaign", "1", call.OccurredAt(), "chatwork", "12345"))
+		assert.NoError(t, err)
+		assert.Equal(t, kv.StatusSent, sm.Status)
+		assert.Equal(t, "https://www.chatwork.com/#!rid12345-1234567890", sm.Permalink)
+	})
+}
+
+func TestProcessCall_HomeAssistant(t *testing.T) {
+	call := &model.Call{
+		ID:          "1",
+		Subject:     "Bins",
+		Content:     "Put the **{{ .Bin }}** bin out.",
+		Data:        map[string]interface{}{"Bin": "green"},
+		ScheduledAt: time.Now(),
+		Destinations: []model.Destination{
+			{Type: "homeassistant", To: []string{"mobile_app_pixel"}},
+		},
+		Campaign: model.Campaign{ID: "campaign", Name: "Campaign"},
+	}
+
+	t.Run("requires a configured client", func(t *testing.T) {
+		err := worker.ProcessCall(context.Background(), call, datastore.NewMockStore(), slack.NewMockClient(), email.NewMockClient(), false)
+		assert.Error(t, err)
+	})
+
+	t.Run("calls the service through the configured client", func(t *testing.T) {
+		store := datastore.NewMockStore()
+		client := homeassistant.NewMockClient()
+
+		err := worker.ProcessCall(context.Background(), call, store, slack.NewMockClient(), email.NewMockClient(), false, worker.WithHomeAssistantClient(client))
+		assert.NoError(t, err)
+
+		assert.Len(t, client.CallServiceCalls(), 1)
+		assert.Equal(t, "mobile_app_pixel", client.CallServiceCalls()[0].To)
+		assert.Equal(t, "Bins", client.CallServiceCalls()[0].Subject)
+		assert.Equal(t, "Put the **green** bin out.", client.CallServiceCalls()[0].Body)
+
+		sm, err := store.GetSentMessage(kv.GenerateID("campaign", "1", call.OccurredAt(), "homeassistant", "mobile_app_pixel"))
+		assert.NoError(t, err)
+		assert.Equal(t, kv.StatusSent, sm.Status)
+	})
+}
+
+func TestProcessCall_SlackWorkflow(t *testing.T) {
+	call := &model.Call{
+		ID:          "1",
+		Subject:     "Release",
+		Content:     "Version **{{ .Version }}** is out.",
+		Data:        map[string]interface{}{"Version": "1.2.0"},
+		ScheduledAt: time.Now(),
+		Destinations: []model.Destination{
+			{Type: "slack_workflow", To: []string{"release"}},
+		},
+		Campaign: model.Campaign{ID: "campaign", Name: "Campaign"},
+	}
+
+	store := datastore.NewMockStore()
+	client := slackworkflow.NewMockClient()
+
+	err := worker.ProcessCall(context.Background(), call, store, slack.NewMockClient(), email.NewMockClient(), false, worker.WithSlackWorkflowClient(client))
+	assert.NoError(t, err)
+
+	assert.Len(t, client.TriggerCalls(), 1)
+	assert.Equal(t, "release", client.TriggerCalls()[0].To)
+	// The content is converted to Slack markup, as the workflow posts it to Slack.
+	assert.Equal(t, "Version *1.2.0* is out.", strings.TrimSpace(client.TriggerCalls()[0].Body))
+	assert.Equal(t, "1.2.0", client.TriggerCalls()[0].Data["Version"])
+
+	sm, err := store.GetSentMessage(kv.GenerateID("campaign", "1", call.OccurredAt(), "slack_workflow", "release"))
+	assert.NoError(t, err)
+	assert.Equal(t, kv.StatusSent, sm.Status)
+}
+
+func TestProcessCall_Webhook(t *testing.T) {
+	call := &model.Call{
+		ID:          "1",
+		Subject:     "Hello",
+		Content:     "Hello, **{{ .Name }}**!",
+		Data:        map[string]interface{}{"Name": "world"},
+		ScheduledAt: time.Now(),
+		Destinations: []model.Destination{
+			{Type: "statusbot", To: []string{"ops"}},
+		},
+		Campaign: model.Campaign{ID: "campaign", Name: "Campaign"},
+	}
+
+	t.Run("requires a configured type", func(t *testing.T) {
+		err := worker.ProcessCall(context.Background(), call, datastore.NewMockStore(), slack.NewMockClient(), email.NewMockClient(), false)
+		assert.ErrorContains(t, err, "unsupported destination type: statusbot")
+	})
+
+	t.Run("posts in the format of the type", func(t *testing.T) {
+		store := datastore.NewMockStore()
+		hook := webhook.NewMockClient(webhook.FormatSlack)
+
+		err := worker.ProcessCall(context.Background(), call, store, slack.NewMockClient(), email.NewMockClient(), false, worker.WithWebhook("statusbot", hook))
+		assert.NoError(t, err)
+
+		if assert.Len(t, hook.PostCalls(), 1) {
+			assert.Equal(t, "ops", hook.PostCalls()[0].To)
+			assert.Equal(t, "Hello, *world*!", hook.PostCalls()[0].Body)
+		}
+
+		sm, err := store.GetSentMessage(kv.GenerateID("campaign", "1", call.OccurredAt(), "statusbot", "ops"))
+		assert.NoError(t, err)
+		assert.Equal(t, kv.StatusSent, sm.Status)
+	})
+}
+
+func TestProcessCall_Policy(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "policy.star")
+	assert.NoError(t, os.WriteFile(path, []byte(`
+def dispatch(call, destination):
+    if destination.to == "#engineering":
+        return "no announcements in #engineering"
+`), 0644))
+	engine, err := policy.Load(path)
+	assert.NoError(t, err)
+
+	call := &model.Call{
+		ID:           "1",
+		Content:      "Hello, world!",
+		ScheduledAt:  time.Now(),
+		Destinations: []model.Destination{{Type: "slack", To: []string{"#general", "#engineering"}}},
+		Campaign:     model.Campaign{ID: "campaign", Name: "Campaign"},
+	}
+	store := datastore.NewMockStore()
+	slackClient := slack.NewMockClient()
+
+	assert.NoError(t, worker.ProcessCall(context.Background(), call, store, slackClient, email.NewMockClient(), false, worker.WithPolicy(engine)))
+
+	assert.Len(t, slackClient.PostMessageCalls(), 1)
+	sm, err := store.GetSentMessage(kv.GenerateID("campaign", "1", call.OccurredAt(), "slack", "#engineering"))
+	assert.NoError(t, err)
+	assert.Equal(t, kv.StatusFailed, sm.Status)
+	assert.Contains(t, sm.Error, "no announcements in #engineering")
+}
+
+func TestProcessCall_InvalidDestination(t *testing.T) {
+	call := &model.Call{
+		ID:           "1",
+		Content:      "Hello, world!",
+		ScheduledAt:  time.Now(),
+		Destinations: []model.Destination{{Type: "slack", To: []string{"#general", "#old", "#flaky"}}},
+		Campaign:     model.Campaign{ID: "campaign", Name: "Campaign"},
+	}
+	store := datastore.NewMockStore()
+	slackClient := slack.NewMockClient()
+	slackClient.ValidateDestinationFunc = func(_ context.Context, destination string) error {
+		switch destination {
+		case "#old":
+			return fmt.Errorf("%w: channel '#old' is archived", slack.ErrInvalidDestination)
+		case "#flaky":
+			return errors.New("failed to get conversations: timeout")
+		}
+		return nil
+	}
+
+	// A destination that could not be checked is sent to anyway.
+	assert.NoError(t, worker.ProcessCall(context.Background(), call, store, slackClient, email.NewMockClient(), false))
+	var posted []string
+	for _, post := range slackClient.PostMessageCalls() {
+		posted = append(posted, post.Destination)
+	}
+	assert.Equal(t, []string{"#general", "#flaky"}, posted)
+
+	id := kv.GenerateID("campaign", "1", call.OccurredAt(), "slack", "#old")
+	sm, err := store.GetSentMessage(id)
+	assert.NoError(t, err)
+	assert.Equal(t, kv.StatusInvalidDestination, sm.Status)
+	assert.Equal(t, "invalid destination: channel '#old' is archived", sm.Error)
+	_, err = store.GetDeadLetter(id)
+	assert.NoError(t, err, "the message is kept to be replayed once the channel is restored")
+
+	// A destination that becomes invalid between being checked and sent to is recorded as invalid too.
+	call.ID = "2"
+	call.Destinations = []model.Destination{{Type: "slack", To: []string{"#general"}}}
+	slackClient.PostMessageFunc = func(_ context.Context, channel, author, subject, text string, campaign model.Campaign) (string, string, error) {
+		return "", "", fmt.Errorf("failed to post message: %w: %w", slack.ErrInvalidDestination, slackapi.SlackErrorResponse{Err: "is_archived"})
+	}
+	assert.NoError(t, worker.ProcessCall(context.Background(), call, store, slackClient, email.NewMockClient(), false))
+	sm, err = store.GetSentMessage(kv.GenerateID("campaign", "2", call.OccurredAt(), "slack", "#general"))
+	assert.NoError(t, err)
+	assert.Equal(t, kv.StatusInvalidDestination, sm.Status)
+
+	// Email addresses are checked to parse.
+	call.ID = "3"
+	call.Destinations = []model.Destination{{Type: "email", To: []string{"jane@example.com", "not an address"}}}
+	emailClient := email.NewMockClient()
+	assert.NoError(t, worker.ProcessCall(context.Background(), call, store, slackClient, emailClient, false))
+	if assert.Len(t, emailClient.SendCalls(), 1) {
+		assert.Equal(t, []string{"jane@example.com"}, emailClient.SendCalls()[0].To)
+	}
+	sm, err = store.GetSentMessage(kv.GenerateID("campaign", "3", call.OccurredAt(), "email", "not an address"))
+	assert.NoError(t, err)
+	assert.Equal(t, kv.StatusInvalidDestination, sm.Status)
+	assert.ErrorIs(t, email.ValidateAddress("not an address"), email.ErrInvalidAddress)
+}
+
+func TestProcessCall_Limits(t *testing.T) {
+	l := limits.New()
+	l.Set("slack", "#general", limits.Rule{MaxPerDay: 1})
+
+	store := datastore.NewMockStore()
+	assert.NoError(t, store.AddSentMessage("campaign", "earlier", &kv.SentMessage{
+		SourceID:    "earlier",
+		ScheduledAt: time.Now(),
+		Status:      kv.StatusSent,
+		Type:        "slack",
+		Destination: "#general",
+	}))
+
+	call := &model.Call{
+		ID:           "1",
+		Content:      "Hello, world!",
+		ScheduledAt:  time.Now(),
+		Destinations: []model.Destination{{Type: "slack", To: []string{"#general", "#random"}}},
+		Campaign:     model.Campaign{ID: "campaign", Name: "Campaign"},
+	}
+	slackClient := slack.NewMockClient()
+
+	// The cap of #general has been reached today, so the call is only sent to #random, and is kept to be
+	// sent to #general later.
+	err := worker.ProcessCall(context.Background(), call, store, slackClient, email.NewMockClient(), false, worker.WithLimits(l))
+	assert.ErrorIs(t, err, worker.ErrDeferred)
+	assert.Len(t, slackClient.PostMessageCalls(), 1)
+	assert.Equal(t, "#random", slackClient.PostMessageCalls()[0].Destination)
+
+	sent, err := store.HasBeenSent("campaign", "1", call.OccurredAt(), "slack", "#general")
+	assert.NoError(t, err)
+	assert.False(t, sent)
+}
+
+func TestProcessCall_RateLimits(t *testing.T) {
+	store := datastore.NewMockStore()
+	call := &model.Call{
+		ID:           "1",
+		Content:      "Hello, world!",
+		ScheduledAt:  time.Now(),
+		Destinations: []model.Destination{{Type: "slack", To: []string{"@one", "@two", "@three"}}},
+		Campaign:     model.Campaign{ID: "campaign", Name: "Campaign"},
+	}
+
+	// Slack rate limits the second message, which is kept rather than failed, along with the rest.
+	slackClient := slack.NewMockClient()
+	slackClient.PostMessageFunc = func(_ context.Context, channel, author, subject, text string, campaign model.Campaign) (string, string, error) {
+		if channel == "@two" {
+			return "", "", fmt.Errorf("failed to post message: %w", &slackapi.RateLimitedError{RetryAfter: time.Hour})
+		}
+		return "C1234567890", "1234567890.123456", nil
+	}
+	rates := limits.NewBuckets()
+	err := worker.ProcessCall(context.Background(), call, store, slackClient, email.NewMockClient(), false, worker.WithRateLimits(rates), worker.WithSendTimeout(time.Minute))
+	assert.ErrorIs(t, err, worker.ErrDeferred)
+	assert.Len(t, slackClient.PostMessageCalls(), 2)
+
+	// The message Slack rate limited was recorded as being sent before it was posted, and is recorded as
+	// deferred rather than sending, so that it is sent on a later tick.
+	sent, err := store.ListSentMessages()
+	assert.NoError(t, err)
+	statuses := map[string]kv.Status{}
+	for _, sm := range sent {
+		statuses[sm.Destination] = sm.Status
+	}
+	assert.Equal(t, map[string]kv.Status{"@one": kv.StatusSent, "@two": kv.StatusDeferred}, statuses)
+	settled, err := store.HasBeenSent("campaign", "1", call.OccurredAt(), "slack", "@two")
+	assert.NoError(t, err)
+	assert.False(t, settled)
+
+	// The bucket of Slack is paused until it asked to be retried.
+	assert.InDelta(t, time.Hour, rates.Reserve("slack", time.Now()), float64(time.Second))
+
+	// A rate paces the messages, and those that would wait past the send timeout are kept.
+	rates = limits.NewBuckets()
+	rates.Set("slack", limits.Rate{PerSecond: 0.01, Burst: 1})
+	slackClient = slack.NewMockClient()
+	err = worker.ProcessCall(context.Background(), call, datastore.NewMockStore(), slackClient, email.NewMockClient(), false, worker.WithRateLimits(rates), worker.WithSendTimeout(time.Minute))
+	assert.ErrorIs(t, err, worker.ErrDeferred)
+	assert.Len(t, slackClient.PostMessageCalls(), 1)
+}
+
+func TestProcessCall_Occurrences(t *testing.T) {
+	store := datastore.NewMockStore()
+	slackClient := slack.NewMockClient()
+
+	// Calls that follow another call have the same ID every time they are due.
+	first := time.Now().Add(-7 * 24 * time.Hour).UTC().Truncate(time.Second)
+	call := &model.Call{
+		ID:           "followup:after:launch:slack:#general",
+		Content:      "Hello, world!",
+		ScheduledAt:  first.Add(time.Hour),
+		Shifts:       []model.Shift{{Reason: model.ShiftHoliday, From: first, To: first.Add(time.Hour)}},
+		Destinations: []model.Destination{{Type: "slack", To: []string{"#general"}}},
+		Campaign:     model.Campaign{ID: "campaign", Name: "Campaign"},
+	}
+	assert.NoError(t, worker.ProcessCall(context.Background(), call, store, slackClient, email.NewMockClient(), false))
+
+	// Moving the occurrence again does not send it twice.
+	moved := *call
+	moved.ScheduledAt = first.Add(2 * time.Hour)
+	moved.Shifts = []model.Shift{{Reason: model.ShiftHoliday, From: first, To: first.Add(2 * time.Hour)}}
+	assert.NoError(t, worker.ProcessCall(context.Background(), &moved, store, slackClient, email.NewMockClient(), false))
+	assert.Len(t, slackClient.PostMessageCalls(), 1)
+
+	// The next occurrence is sent.
+	next := *call
+	next.ScheduledAt = time.Now()
+	next.Shifts = nil
+	assert.NoError(t, worker.ProcessCall(context.Background(), &next, store, slackClient, email.NewMockClient(), false))
+	assert.Len(t, slackClient.PostMessageCalls(), 2)
+
+	sm, err := store.GetSentMessage(kv.GenerateID("campaign", call.ID, first, "slack", "#general"))
+	assert.NoError(t, err)
+	assert.Equal(t, first, sm.OccurredAt)
+}
+
+func TestProcessCall_FailureNotifications(t *testing.T) {
+	call := &model.Call{
+		ID:           "launch:scheduled_at:2025-03-04T09:00:00Z:slack:#general",
+		Author:       "jane@example.com",
+		Subject:      "Launch",
+		Content:      "Hello, world!",
+		ScheduledAt:  time.Now(),
+		Destinations: []model.Destination{{Type: "slack", To: []string{"#general"}}},
+		Campaign:     model.Campaign{ID: "campaign", Name: "Campaign"},
+	}
+	slackClient := slack.NewMockClient()
+	slackClient.PostMessageFunc = func(_ context.Context, channel, author, subject, text string, campaign model.Campaign) (string, string, error) {
+		return "", "", errors.New("channel_not_found")
+	}
+	var notified []string
+	slackClient.NotifyAuthorOfFailureFunc = func(_ context.Context, authorEmail, destination, reason, retry string) error {
+		notified = append(notified, authorEmail, destination, reason, retry)
+		return nil
+	}
+	emailClient := email.NewMockClient()
+
+	// Without the option, authors are not told.
+	assert.NoError(t, worker.ProcessCall(context.Background(), call, datastore.NewMockStore(), slackClient, emailClient, false))
+	assert.Empty(t, notified)
+
+	assert.NoError(t, worker.ProcessCall(context.Background(), call, datastore.NewMockStore(), slackClient, emailClient, false, worker.WithFailureNotifications()))
+	assert.Equal(t, []string{
+		"jane@example.com",
+		"#general",
+		"channel_not_found",
+		"ruf dispatcher replay " + kv.GenerateShortID(kv.GenerateID("campaign", call.ID, call.OccurredAt(), "slack", "#general")),
+	}, notified)
+	assert.Empty(t, emailClient.SendCalls())
+
+	// When the author cannot be sent a direct message, they are sent an email.
+	slackClient.NotifyAuthorOfFailureFunc = func(_ context.Context, authorEmail, destination, reason, retry string) error {
+		return errors.New("users_not_found")
+	}
+	assert.NoError(t, worker.ProcessCall(context.Background(), call, datastore.NewMockStore(), slackClient, emailClient, false, worker.WithFailureNotifications()))
+	assert.Len(t, emailClient.SendCalls(), 1)
+	assert.Equal(t, []string{"jane@example.com"}, emailClient.SendCalls()[0].To)
+	assert.Contains(t, emailClient.SendCalls()[0].Body, "ruf dispatcher replay ")
+
+	// The owners of the campaign are told alongside the author, once each.
+	notified = nil
+	slackClient.NotifyAuthorOfFailureFunc = func(_ context.Context, authorEmail, destination, reason, retry string) error {
+		notified = append(notified, authorEmail)
+		return nil
+	}
+	router, err := owners.New([]owners.Rule{{Match: "campaign", Owners: []string{"jane@example.com", "launch-team@example.com"}}})
+	assert.NoError(t, err)
+	assert.NoError(t, worker.ProcessCall(context.Background(), call, datastore.NewMockStore(), slackClient, emailClient, false, worker.WithFailureNotifications(), worker.WithOwners(router)))
+	assert.Equal(t, []string{"jane@example.com", "launch-team@example.com"}, notified)
+}
+
+func TestProcessCall_UpdateStream(t *testing.T) {
+	store := datastore.NewMockStore()
+	slackClient := slack.NewMockClient()
+	var updated []string
+	slackClient.UpdateMessageFunc = func(_ context.Context, destination, timestamp, subject, text string) (string, string, error) {
+		updated = append(updated, destination, timestamp, text)
+		return "C1234567890", timestamp, nil
+	}
+	emailClient := email.NewMockClient()
+
+	occurrence := func(at time.Time, content string, dest model.Destination) *model.Call {
+		return &model.Call{
+			ID:           "deploy-status",
+			Content:      content,
+			Mode:         model.ModeUpdateStream,
+			ScheduledAt:  at,
+			Destinations: []model.Destination{dest},
+			Campaign:     model.Campaign{ID: "campaign", Name: "Campaign"},
+		}
+	}
+	first, second := time.Now().Add(-time.Hour), time.Now()
+
+	// The first occurrence posts the message, and the next edits it.
+	slackDest := model.Destination{Type: "slack", To: []string{"#deploys"}}
+	assert.NoError(t, worker.ProcessCall(context.Background(), occurrence(first, "Deploying", slackDest), store, slackClient, emailClient, false))
+	assert.NoError(t, worker.ProcessCall(context.Background(), occurrence(second, "Deployed", slackDest), store, slackClient, emailClient, false))
+	assert.Len(t, slackClient.PostMessageCalls(), 1)
+	assert.Equal(t, []string{"#deploys", "1234567890.123456", "Deployed"}, updated)
+
+	// Emails of later occurrences are sent in the thread of the first.
+	emailDest := model.Destination{Type: "email", To: []string{"team@example.com"}}
+	assert.NoError(t, worker.ProcessCall(context.Background(), occurrence(first, "Deploying", emailDest), store, slackClient, emailClient, false))
+	assert.NoError(t, worker.ProcessCall(context.Background(), occurrence(second, "Deployed", emailDest), store, slackClient, emailClient, false))
+	assert.NoError(t, worker.ProcessCall(context.Background(), occurrence(second.Add(time.Hour), "Rolled back", emailDest), store, slackClient, emailClient, false))
+	sends := emailClient.SendCalls()
+	assert.Len(t, sends, 3)
+	assert.Empty(t, sends[0].References)
+	assert.Equal(t, []string{"<1@example.com>"}, sends[1].References)
+	assert.Equal(t, []string{"<1@example.com>", "<2@example.com>"}, sends[2].References)
+}
+
+func TestProcessCall_Cancelled(t *testing.T) {
+	store := datastore.NewMockStore()
+	call := &model.Call{
+		ID:           "1",
+		Content:      "Hello, world!",
+		ScheduledAt:  time.Now(),
+		Destinations: []model.Destination{{Type: "slack", To: []string{"#one", "#two", "#three"}}},
+		Campaign:     model.Campaign{ID: "campaign", Name: "Campaign"},
+	}
+
+	// The call is cancelled once it has been sent to the first recipient.
+	slackClient := slack.NewMockClient()
+	slackClient.PostMessageFunc = func(_ context.Context, channel, author, subject, text string, campaign model.Campaign) (string, string, error) {
+		assert.NoError(t, store.CancelCall(&kv.Cancellation{CallID: "1", By: "jane", Reason: "wrong date"}))
+		return "C1234567890", "1234567890.123456", nil
+	}
+
+	assert.NoError(t, worker.ProcessCall(context.Background(), call, store, slackClient, email.NewMockClient(), false))
+	assert.Len(t, slackClient.PostMessageCalls(), 1)
+
+	sm, err := store.GetSentMessage(kv.GenerateID("campaign", "1", call.OccurredAt(), "slack", "#one"))
+	assert.NoError(t, err)
+	assert.Equal(t, kv.StatusSent, sm.Status)
+	for _, to := range []string{"#two", "#three"} {
+		sm, err := store.GetSentMessage(kv.GenerateID("campaign", "1", call.OccurredAt(), "slack", to))
+		assert.NoError(t, err)
+		assert.Equal(t, kv.StatusCancelled, sm.Status)
+		assert.Equal(t, "cancelled by jane: wrong date", sm.Error)
+
+		sent, err := store.HasBeenSent("campaign", "1", call.OccurredAt(), "slack", to)
+		assert.NoError(t, err)
+		assert.True(t, sent, "cancelled recipients are not sent the call again")
+	}
+}
+
+func TestProcessCall_SendTimeout(t *testing.T) {
+	store := datastore.NewMockStore()
+	call := &model.Call{
+		ID:           "1",
+		Content:      "Hello, world!",
+		ScheduledAt:  time.Now(),
+		Destinations: []model.Destination{{Type: "slack", To: []string{"#one", "#two"}}},
+		Campaign:     model.Campaign{ID: "campaign", Name: "Campaign"},
+	}
+
+	// Slack stops responding, until the call runs out of time.
+	slackClient := slack.NewMockClient()
+	slackClient.PostMessageFunc = func(ctx context.Context, channel, author, subject, text string, campaign model.Campaign) (string, string, error) {
+		<-ctx.Done()
+		return "", "", ctx.Err()
+	}
+
+	start := time.Now()
+	assert.NoError(t, worker.ProcessCall(context.Background(), call, store, slackClient, email.NewMockClient(), false, worker.WithSendTimeout(20*time.Millisecond)))
+	assert.Less(t, time.Since(start), time.Second)
+
+	// The failures are recorded, although the call has run out of time.
+	for _, to := range []string{"#one", "#two"} {
+		sm, err := store.GetSentMessage(kv.GenerateID("campaign", "1", call.OccurredAt(), "slack", to))
+		assert.NoError(t, err)
+		assert.Equal(t, kv.StatusFailed, sm.Status)
+		assert.Contains(t, sm.Error, context.DeadlineExceeded.Error())
+	}
+}
+
+func TestProcessCall_RecordsSending(t *testing.T) {
+	store := datastore.NewMockStore()
+	call := &model.Call{
+		ID:           "1",
+		Content:      "Hello, world!",
+		ScheduledAt:  time.Now(),
+		Destinations: []model.Destination{{Type: "slack", To: []string{"#general"}}},
+		Campaign:     model.Campaign{ID: "campaign", Name: "Campaign"},
+	}
+	id := kv.GenerateID("campaign", "1", call.OccurredAt(), "slack", "#general")
+
+	// The message is recorded as being sent by the time Slack is called.
+	slackClient := slack.NewMockClient()
+	slackClient.PostMessageFunc = func(_ context.Context, channel, author, subject, text string, campaign model.Campaign) (string, string, error) {
+		sm, err := store.GetSentMessage(id)
+		if assert.NoError(t, err) {
+			assert.Equal(t, kv.StatusSending, sm.Status)
+			assert.False(t, sm.AttemptedAt.IsZero())
+		}
+		return "C1234567890", "1234567890.123456", nil
+	}
+	assert.NoError(t, worker.ProcessCall(context.Background(), call, store, slackClient, email.NewMockClient(), false))
+	assert.Len(t, slackClient.PostMessageCalls(), 1)
+
+	sm, err := store.GetSentMessage(id)
+	assert.NoError(t, err)
+	assert.Equal(t, kv.StatusSent, sm.Status)
+
+	// A message a worker stopped part way through sending is not sent again.
+	sm.Status = kv.StatusSending
+	assert.NoError(t, store.UpdateSentMessage(sm))
+	slackClient = slack.NewMockClient()
+	assert.NoError(t, worker.ProcessCall(context.Background(), call, store, slackClient, email.NewMockClient(), false))
+	assert.Empty(t, slackClient.PostMessageCalls())
+}
+
+func TestProcessCall_Middleware(t *testing.T) {
+	store := datastore.NewMockStore()
+	slackClient := slack.NewMockClient()
+	call := &model.Call{
+		ID:           "1",
+		Subject:      "Hello",
+		Content:      "Hello, world!",
+		ScheduledAt:  time.Now(),
+		Destinations: []model.Destination{{Type: "slack", To: []string{"#general", "#skipped"}}},
+		Campaign:     model.Campaign{ID: "campaign", Name: "Campaign"},
+	}
+
+	var order []string
+	trace := func(name string) worker.Middleware {
+		return func(next worker.Sender) worker.Sender {
+			return worker.SenderFunc(func(ctx context.Context, d *worker.Delivery) (*kv.SentMessage, error) {
+				order = append(order, name+" "+d.To)
+				return next.Send(ctx, d)
+			})
+		}
+	}
+	// A middleware may keep a delivery for later without sending it.
+	skip := func(next worker.Sender) worker.Sender {
+		return worker.SenderFunc(func(ctx context.Context, d *worker.Delivery) (*kv.SentMessage, error) {
+			if d.To == "#skipped" {
+				return nil, fmt.Errorf("%w: skipped", worker.ErrDeferred)
+			}
+			return next.Send(ctx, d)
+		})
+	}
+
+	err := worker.ProcessCall(context.Background(), call, store, slackClient, email.NewMockClient(), false,
+		worker.WithMiddleware("slack", trace("slack"), skip),
+		worker.WithMiddleware("email", trace("email")),
+		worker.WithMiddleware(worker.AllTypes, trace("all")),
+	)
+	assert.ErrorIs(t, err, worker.ErrDeferred)
+	assert.Equal(t, []string{"all #general", "slack #general", "all #skipped", "slack #skipped"}, order)
+	if assert.Len(t, slackClient.PostMessageCalls(), 1) {
+		assert.Equal(t, "#general", slackClient.PostMessageCalls()[0].Destination)
+	}
+
+	sm, err := store.GetSentMessage(kv.GenerateID("campaign", "1", call.OccurredAt(), "slack", "#general"))
+	assert.NoError(t, err)
+	assert.Equal(t, kv.StatusSent, sm.Status)
+	_, err = store.GetSentMessage(kv.GenerateID("campaign", "1", call.OccurredAt(), "slack", "#skipped"))
+	assert.ErrorIs(t, err, kv.ErrNotFound)
+}
+
+func TestProcessCall_ContentProcessors(t *testing.T) {
+	newCall := func(id string, processors *model.ProcessorOptions) *model.Call {
+		return &model.Call{
+			ID:           id,
+			Subject:      "Release",
+			Content:      "**v2** is out",
+			ScheduledAt:  time.Now(),
+			Destinations: []model.Destination{{Type: "email", To: []string{"list@example.com"}}},
+			Campaign:     model.Campaign{ID: "campaign", Name: "Campaign"},
+			Processors:   processors,
+		}
+	}
+
+	// Email is converted to HTML by default.
+	emailClient := email.NewMockClient()
+	assert.NoError(t, worker.ProcessCall(context.Background(), newCall("default", nil), datastore.NewMockStore(), slack.NewMockClient(), emailClient, false))
+	assert.Contains(t, emailClient.SendCalls()[0].Body, "<strong>v2</strong>")
+
+	// Configuration replaces the processors of a type, here to send plain text.
+	plain := worker.WithContentProcessors("email", processor.ProcessorStack{})
+	emailClient = email.NewMockClient()
+	assert.NoError(t, worker.ProcessCall(context.Background(), newCall("configured", nil), datastore.NewMockStore(), slack.NewMockClient(), emailClient, false, plain))
+	assert.Equal(t, "**v2** is out", emailClient.SendCalls()[0].Body)
+
+	// A call replaces them again.
+	emailClient = email.NewMockClient()
+	own := &model.ProcessorOptions{Content: map[string][]string{"email": {"markdown_to_html"}}}
+	assert.NoError(t, worker.ProcessCall(context.Background(), newCall("own", own), datastore.NewMockStore(), slack.NewMockClient(), emailClient, false, plain))
+	assert.Contains(t, emailClient.SendCalls()[0].Body, "<strong>v2</strong>")
+
+	// A processor that is not registered stops the call from being sent.
+	emailClient = email.NewMockClient()
+	unknown := &model.ProcessorOptions{Content: map[string][]string{"email": {"markdown_to_pdf"}}}
+	err := worker.ProcessCall(context.Background(), newCall("unknown", unknown), datastore.NewMockStore(), slack.NewMockClient(), emailClient, false)
+	assert.ErrorIs(t, err, processor.ErrUnknownProcessor)
+	assert.Empty(t, emailClient.SendCalls())
+}
+
+func TestProcessCall_StrictTemplates(t *testing.T) {
+	store := datastore.NewMockStore()
+	slackClient := slack.NewMockClient()
+	call := &model.Call{
+		ID:           "deploy",
+		Content:      "Deploy of {{ .version }}",
+		Destinations: []model.Destination{{Type: "slack", To: []string{"#deploys"}}},
+		Campaign:     model.Campaign{ID: "campaign"},
+		ScheduledAt:  time.Now().UTC(),
+	}
+
+	// The call is held, with the value that is missing, rather than sent.
+	err := worker.ProcessCall(context.Background(), call, store, slackClient, email.NewMockClient(), false, worker.WithStrictTemplates())
+	assert.ErrorIs(t, err, worker.ErrHeld)
+	assert.Empty(t, slackClient.PostMessageCalls())
+	sent, err := store.ListSentMessages()
+	assert.NoError(t, err)
+	if assert.Len(t, sent, 1) {
+		assert.Equal(t, kv.StatusHeld, sent[0].Status)
+		assert.Contains(t, sent[0].Error, "missing template value .version")
+	}
+
+	// Once the data has the value, the call is sent, and replaces the record of it being held.
+	call.Data = map[string]interface{}{"version": "1.2"}
+	assert.NoError(t, worker.ProcessCall(context.Background(), call, store, slackClient, email.NewMockClient(), false, worker.WithStrictTemplates()))
+	assert.Len(t, slackClient.PostMessageCalls(), 1)
+	sent, err = store.ListSentMessages()
+	assert.NoError(t, err)
+	if assert.Len(t, sent, 1) {
+		assert.Equal(t, kv.StatusSent, sent[0].Status)
+	}
+}
+
+func TestProcessCall_ProcessorOptions(t *testing.T) {
+	store := datastore.NewMockStore()
+	slackClient := slack.NewMockClient()
+	strict, markdown := false, false
+	call := &model.Call{
+		ID:           "deploy",
+		Content:      "**Deploy** of {{ .version }}",
+		Destinations: []model.Destination{{Type: "slack", To: []string{"#deploys"}}},
+		Processors:   &model.ProcessorOptions{StrictTemplates: &strict, Markdown: &markdown},
+		Campaign:     model.Campaign{ID: "campaign"},
+		ScheduledAt:  time.Now().UTC(),
+	}
+
+	// The options of the call override those of the worker, and the content is sent as it is written.
+	assert.NoError(t, worker.ProcessCall(context.Background(), call, store, slackClient, email.NewMockClient(), false, worker.WithStrictTemplates()))
+	if assert.Len(t, slackClient.PostMessageCalls(), 1) {
+		assert.Equal(t, "**Deploy** of <no value>", slackClient.PostMessageCalls()[0].Text)
+	}
+}
+
+func TestProcessCall_Trial(t *testing.T) {
+	store := datastore.NewMockStore()
+	slackClient := slack.NewMockClient()
+	first := time.Now().Add(-48 * time.Hour).UTC().Truncate(time.Second)
+	call := &model.Call{
+		ID:           "1",
+		Content:      "Hello, world!",
+		ScheduledAt:  first,
+		Destinations: []model.Destination{{Type: "slack", To: []string{"#one", "#two"}}},
+		Campaign: model.Campaign{
+			ID:                "campaign",
+			Name:              "Campaign",
+			CanaryDestination: &model.Destination{Type: "slack", To: []string{"#test"}},
+			TrialOccurrences:  1,
+		},
+	}
+
+	// The first occurrence is sent to the canary destination alone, and kept from the recipients.
+	assert.NoError(t, worker.ProcessCall(context.Background(), call, store, slackClient, email.NewMockClient(), false))
+	if assert.Len(t, slackClient.PostMessageCalls(), 1) {
+		assert.Equal(t, "#test", slackClient.PostMessageCalls()[0].Destination)
+	}
+	for _, to := range []string{"#one", "#two"} {
+		sm, err := store.GetSentMessage(kv.GenerateID("campaign", "1", first, "slack", to))
+		assert.NoError(t, err)
+		assert.Equal(t, kv.StatusTrial, sm.Status)
+		assert.Equal(t, "sent to the canary destination of the campaign, slack:#test", sm.Error)
+	}
+
+	// Processing the occurrence again sends it nowhere.
+	assert.NoError(t, worker.ProcessCall(context.Background(), call, store, slackClient, email.NewMockClient(), false))
+	assert.Len(t, slackClient.PostMessageCalls(), 1)
+
+	// Once the trial is over, the call goes live.
+	next := *call
+	next.ScheduledAt = first.Add(24 * time.Hour)
+	assert.NoError(t, worker.ProcessCall(context.Background(), &next, store, slackClient, email.NewMockClient(), false))
+	if assert.Len(t, slackClient.PostMessageCalls(), 3) {
+		assert.Equal(t, "#one", slackClient.PostMessageCalls()[1].Destination)
+		assert.Equal(t, "#two", slackClient.PostMessageCalls()[2].Destination)
+	}
+
+	// A campaign in dry run sends nothing, and keeps the call from every recipient.
+	dryRun := *call
+	dryRun.ID = "2"
+	dryRun.Campaign.DryRun = true
+	dryRun.Campaign.TrialOccurrences = 0
+	assert.NoError(t, worker.ProcessCall(context.Background(), &dryRun, store, slackClient, email.NewMockClient(), false))
+	assert.Len(t, slackClient.PostMessageCalls(), 3)
+	sent, err := store.HasBeenSent("campaign", "2", first, "slack", "#one")
+	assert.NoError(t, err)
+	assert.True(t, sent)
+}
@@ -0,0 +1,70 @@
+package worker
+
+import (
+	"fmt"
+
+	"github.com/andrewhowdencom/ruf/internal/clients/webhook"
+	"github.com/andrewhowdencom/ruf/internal/model"
+	"github.com/andrewhowdencom/ruf/internal/processor"
+)
+
+// WithContentProcessors replaces the processors the content of calls is converted with for a destination
+// type, after its templates are rendered. An empty stack sends the content as it is written. A call can
+// replace them again with its own processors.
+func WithContentProcessors(destType string, stack processor.ProcessorStack) Option {
+	return func(o *options) {
+		if o.processors == nil {
+			o.processors = make(map[string]processor.ProcessorStack)
+		}
+		o.processors[destType] = stack
+	}
+}
+
+// contentProcessors returns the processors the content of a call is converted with for a destination
+// type: those the call sets, those configured for the type, or those of the type by default. A call that
+// does not convert Markdown is sent as it is written.
+func contentProcessors(o *options, call *model.Call, destType string) (processor.ProcessorStack, error) {
+	defaults, err := defaultProcessors(o, destType)
+	if err != nil {
+		return nil, err
+	}
+	if !call.Processors.ConvertsMarkdown() {
+		return nil, nil
+	}
+	if names, ok := call.Processors.ContentProcessors(destType); ok {
+		stack, err := processor.Stack(names...)
+		if err != nil {
+			return nil, fmt.Errorf("invalid content processors of call %s for %s: %w", call.ID, destType, err)
+		}
+		return stack, nil
+	}
+	if stack, ok := o.processors[destType]; ok {
+		return stack, nil
+	}
+	return defaults, nil
+}
+
+// defaultProcessors returns the processors the content of calls is converted with for a destination type
+// when neither the call nor the configuration sets them: from Markdown to the format of the destination.
+func defaultProcessors(o *options, destType string) (processor.ProcessorStack, error) {
+	switch destType {
+	case "slack", "slack_workflow":
+		return processor.ProcessorStack{processor.NewMarkdownToSlackProcessor()}, nil
+	case "email":
+		return processor.ProcessorStack{processor.NewMarkdownToHTMLProcessor()}, nil
+	case "chatwork", "line", "homeassistant":
+		return nil, nil
+	}
+
+	hook, ok := o.webhooks[destType]
+	if !ok {
+		return nil, fmt.Errorf("unsupported destination type: %s", destType)
+	}
+	switch hook.Format() {
+	case webhook.FormatHTML:
+		return processor.ProcessorStack{processor.NewMarkdownToHTMLProcessor()}, nil
+	case webhook.FormatSlack:
+		return processor.ProcessorStack{processor.NewMarkdownToSlackProcessor()}, nil
+	}
+	return nil, nil
+}
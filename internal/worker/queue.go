@@ -0,0 +1,63 @@
+package worker
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/andrewhowdencom/ruf/internal/kv"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// budget bounds the work done in a single tick. Zero values disable a bound.
+type budget struct {
+	maxCalls    int
+	maxDuration time.Duration
+}
+
+// exhausted reports whether the budget is spent, given the calls processed and the time taken so far.
+func (b *budget) exhausted(processed int, elapsed time.Duration) bool {
+	if b == nil {
+		return false
+	}
+	return (b.maxCalls > 0 && processed >= b.maxCalls) || (b.maxDuration > 0 && elapsed >= b.maxDuration)
+}
+
+// prioritise orders the due calls in the order they are processed: by priority, then by the time they
+// were scheduled, oldest first. Each tick a call has been carried over raises its priority by one, so
+// that a steady stream of higher priority calls cannot hold a call back forever.
+func prioritise(calls []*kv.ScheduledCall, carried map[string]int) {
+	sort.SliceStable(calls, func(i, j int) bool {
+		pi, pj := calls[i].Priority+carried[calls[i].ID], calls[j].Priority+carried[calls[j].ID]
+		if pi != pj {
+			return pi > pj
+		}
+		if !calls[i].ScheduledAt.Equal(calls[j].ScheduledAt) {
+			return calls[i].ScheduledAt.Before(calls[j].ScheduledAt)
+		}
+		return calls[i].ID < calls[j].ID
+	})
+}
+
+// QueueDepth returns the number of due calls that the last tick carried over to the next, because its
+// budget was spent.
+func (w *Worker) QueueDepth() int {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return len(w.carried)
+}
+
+// RegisterMetrics registers gauges describing the worker with the meter.
+func (w *Worker) RegisterMetrics(meter metric.Meter) error {
+	queueDepth, err := meter.Int64ObservableGauge("ruf.worker.queue_depth",
+		metric.WithDescription("The number of due calls carried over to the next tick."))
+	if err != nil {
+		return err
+	}
+
+	_, err = meter.RegisterCallback(func(_ context.Context, o metric.Observer) error {
+		o.ObserveInt64(queueDepth, int64(w.QueueDepth()))
+		return nil
+	}, queueDepth)
+	return err
+}
@@ -0,0 +1,57 @@
+package worker
+
+import (
+	"fmt"
+
+	"github.com/andrewhowdencom/ruf/internal/model"
+	"github.com/andrewhowdencom/ruf/internal/processor"
+)
+
+// Rendered is a call rendered for a destination type.
+type Rendered struct {
+	Subject string
+	// Text is the content once its templates are rendered, before it is converted for the destination.
+	Text string
+	// Content is the content as it is sent to the destination.
+	Content string
+}
+
+// Render renders the subject and content of a call for a destination type as ProcessCall would send
+// them at the time the call is scheduled at, with the same data, template functions and processors,
+// but without sending anything.
+func Render(call *model.Call, destType string, opts ...Option) (*Rendered, error) {
+	o := newOptions(opts)
+	stack, err := contentProcessors(o, call, destType)
+	if err != nil {
+		return nil, err
+	}
+
+	var funcs []processor.TemplateOption
+	if o.assets != nil {
+		funcs = append(funcs, processor.WithFuncs(o.assets.Funcs(call)))
+	}
+	if call.Processors.Strict(o.strict) {
+		funcs = append(funcs, processor.WithStrict())
+	}
+	callData, err := renderData(o, call)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render the data of call %s: %w", call.ID, err)
+	}
+	data := make(map[string]interface{}, len(callData)+1)
+	for k, v := range callData {
+		data[k] = v
+	}
+	data["ScheduledAt"] = call.ScheduledAt
+
+	r := &Rendered{}
+	if r.Subject, err = processor.NewTemplateProcessor(funcs...).Process(call.Subject, data); err != nil {
+		return nil, fmt.Errorf("failed to render subject: %w", err)
+	}
+	if r.Text, err = processor.NewTemplateProcessor(funcs...).Process(call.Content, data); err != nil {
+		return nil, fmt.Errorf("failed to render content: %w", err)
+	}
+	if r.Content, err = stack.Process(r.Text, data); err != nil {
+		return nil, fmt.Errorf("failed to convert content for %s: %w", destType, err)
+	}
+	return r, nil
+}
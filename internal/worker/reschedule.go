@@ -0,0 +1,16 @@
+package worker
+
+import (
+	"errors"
+	"log/slog"
+
+	"github.com/andrewhowdencom/ruf/internal/kv"
+)
+
+// clearReschedule removes the move of a call once the call has been processed, so that it does not
+// outlive the call.
+func clearReschedule(store kv.Storer, callID string) {
+	if err := store.DeleteReschedule(callID); err != nil && !errors.Is(err, kv.ErrNotFound) {
+		slog.Error("failed to delete the move of the call", "call_id", callID, "error", err)
+	}
+}
@@ -0,0 +1,198 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/andrewhowdencom/ruf/internal/clients/chatwork"
+	"github.com/andrewhowdencom/ruf/internal/clients/email"
+	"github.com/andrewhowdencom/ruf/internal/clients/slack"
+	"github.com/andrewhowdencom/ruf/internal/kv"
+	"github.com/andrewhowdencom/ruf/internal/model"
+)
+
+// destination returns the sender that delivers messages to a destination type through its client, before
+// any middleware is added around it.
+func destination(o *options, store kv.Storer, slackClient slack.Client, emailClient email.Client, destType string) Sender {
+	switch destType {
+	case "slack":
+		return SenderFunc(func(ctx context.Context, d *Delivery) (*kv.SentMessage, error) {
+			return sendSlack(ctx, o, store, slackClient, d)
+		})
+	case "email":
+		return SenderFunc(func(ctx context.Context, d *Delivery) (*kv.SentMessage, error) {
+			return sendEmail(ctx, store, emailClient, d)
+		})
+	case "chatwork":
+		return SenderFunc(func(ctx context.Context, d *Delivery) (*kv.SentMessage, error) {
+			if o.chatworkClient == nil {
+				return nil, fmt.Errorf("chatwork destination used but chatwork is not configured")
+			}
+			slog.Info("sending chatwork message", "call_id", d.Call.ID, "destination", d.To, "scheduled_at", d.Call.ScheduledAt)
+			start := time.Now()
+			messageID, err := o.chatworkClient.PostMessage(d.To, d.Call.Author, d.Subject, d.Content, d.Call.Campaign)
+			sm := d.message(messageID, time.Since(start), err)
+			if err != nil {
+				slog.Error("failed to send chatwork message", "error", err)
+			} else {
+				sm.Permalink = chatwork.Permalink(d.To, messageID)
+				slog.Info("sent chatwork message", "call_id", d.Call.ID, "destination", d.To, "scheduled_at", d.Call.ScheduledAt)
+			}
+			return sm, nil
+		})
+	case "line":
+		return SenderFunc(func(ctx context.Context, d *Delivery) (*kv.SentMessage, error) {
+			if o.lineClient == nil {
+				return nil, fmt.Errorf("line destination used but line is not configured")
+			}
+			slog.Info("sending line message", "call_id", d.Call.ID, "destination", d.To, "scheduled_at", d.Call.ScheduledAt)
+			start := time.Now()
+			messageID, err := o.lineClient.PushMessage(d.To, d.Call.Author, d.Subject, d.Content, d.Call.Campaign)
+			sm := d.message(messageID, time.Since(start), err)
+			if err != nil {
+				slog.Error("failed to send line message", "error", err)
+			} else {
+				slog.Info("sent line message", "call_id", d.Call.ID, "destination", d.To, "scheduled_at", d.Call.ScheduledAt)
+			}
+			return sm, nil
+		})
+	case "homeassistant":
+		return SenderFunc(func(ctx context.Context, d *Delivery) (*kv.SentMessage, error) {
+			if o.homeAssistant == nil {
+				return nil, fmt.Errorf("homeassistant destination used but home assistant is not configured")
+			}
+			slog.Info("calling home assistant service", "call_id", d.Call.ID, "destination", d.To, "scheduled_at", d.Call.ScheduledAt)
+			start := time.Now()
+			messageID, err := o.homeAssistant.CallService(d.To, d.Call.Author, d.Subject, d.Content, d.Call.Campaign)
+			sm := d.message(messageID, time.Since(start), err)
+			if err != nil {
+				slog.Error("failed to call home assistant service", "error", err)
+			} else {
+				slog.Info("called home assistant service", "call_id", d.Call.ID, "destination", d.To, "scheduled_at", d.Call.ScheduledAt)
+			}
+			return sm, nil
+		})
+	case "slack_workflow":
+		return SenderFunc(func(ctx context.Context, d *Delivery) (*kv.SentMessage, error) {
+			if o.slackWorkflows == nil {
+				return nil, fmt.Errorf("slack_workflow destination used but no slack workflows are configured")
+			}
+			slog.Info("triggering slack workflow", "call_id", d.Call.ID, "destination", d.To, "scheduled_at", d.Call.ScheduledAt)
+			start := time.Now()
+			messageID, err := o.slackWorkflows.Trigger(d.To, d.Call.Author, d.Subject, d.Content, d.Call.Campaign, d.Data)
+			sm := d.message(messageID, time.Since(start), err)
+			if err != nil {
+				slog.Error("failed to trigger slack workflow", "error", err)
+			} else {
+				slog.Info("triggered slack workflow", "call_id", d.Call.ID, "destination", d.To, "scheduled_at", d.Call.ScheduledAt)
+			}
+			return sm, nil
+		})
+	default:
+		return SenderFunc(func(ctx context.Context, d *Delivery) (*kv.SentMessage, error) {
+			hook, ok := o.webhooks[d.Type]
+			if !ok {
+				return nil, fmt.Errorf("unsupported destination type: %s", d.Type)
+			}
+			slog.Info("posting webhook", "call_id", d.Call.ID, "type", d.Type, "destination", d.To, "scheduled_at", d.Call.ScheduledAt)
+			start := time.Now()
+			messageID, err := hook.Post(d.To, d.Call.Author, d.Subject, d.Content, d.Call.Campaign)
+			sm := d.message(messageID, time.Since(start), err)
+			if err != nil {
+				slog.Error("failed to post webhook", "type", d.Type, "error", err)
+			} else {
+				slog.Info("posted webhook", "call_id", d.Call.ID, "type", d.Type, "destination", d.To, "scheduled_at", d.Call.ScheduledAt)
+			}
+			return sm, nil
+		})
+	}
+}
+
+// sendSlack posts a message to Slack, or edits the last message of an update stream. A message Slack rate
+// limits is deferred to a later tick rather than failed, and no more are sent to Slack until it has asked
+// the worker to wait.
+func sendSlack(ctx context.Context, o *options, store kv.Storer, slackClient slack.Client, d *Delivery) (*kv.SentMessage, error) {
+	call, to := d.Call, d.To
+	previous, err := streamMessage(store, call, d.Type, to)
+	if err != nil {
+		return nil, err
+	}
+	start := time.Now()
+	var channelID, timestamp string
+	if previous != nil {
+		slog.Info("updating slack message", "call_id", call.ID, "destination", to, "timestamp", previous.Timestamp, "scheduled_at", call.ScheduledAt)
+		channelID, timestamp, err = slackClient.UpdateMessage(ctx, to, previous.Timestamp, d.Subject, d.Content)
+		if _, limited := slack.RetryAfter(err); err != nil && !limited {
+			// The message may have been deleted, so the stream starts again with a new one.
+			slog.Warn("failed to update slack message, posting a new one", "call_id", call.ID, "destination", to, "error", err)
+			previous = nil
+		}
+	}
+	if previous == nil {
+		slog.Info("sending slack message", "call_id", call.ID, "destination", to, "scheduled_at", call.ScheduledAt)
+		channelID, timestamp, err = slackClient.PostMessage(ctx, to, call.Author, d.Subject, d.Content, call.Campaign)
+	}
+	if retryAfter, limited := slack.RetryAfter(err); limited {
+		slog.Warn("slack rate limited the message, keeping it for later", "call_id", call.ID, "destination", to, "retry_after", retryAfter)
+		o.rates.Pause(d.Type, time.Now().Add(retryAfter))
+		return nil, fmt.Errorf("%w: slack asked to retry after %s", ErrDeferred, retryAfter)
+	}
+
+	sm := d.message(timestamp, time.Since(start), err)
+	sm.Timestamp = timestamp
+	if err != nil {
+		// The destination may have changed since it was validated, such as by being archived.
+		sm.Status = failedStatus(err)
+		slog.Error("failed to send slack message", "error", err)
+		return sm, nil
+	}
+	sm.DeleteAt = deleteAt(call, time.Now())
+	slog.Info("sent slack message", "call_id", call.ID, "destination", to, "scheduled_at", call.ScheduledAt)
+
+	permalink, err := slackClient.GetPermalink(ctx, channelID, timestamp)
+	if err != nil {
+		slog.Warn("failed to get permalink for slack message", "error", err)
+	}
+	sm.Permalink = permalink
+
+	// Authors are told of the first message of an update stream only.
+	if call.Author != "" && previous == nil {
+		if err := slackClient.NotifyAuthor(ctx, call.Author, channelID, timestamp, to); err != nil {
+			slog.Error("failed to send author notification", "error", err)
+		}
+	}
+	return sm, nil
+}
+
+// sendEmail sends an email, or replies in the thread of the last email of an update stream.
+func sendEmail(ctx context.Context, store kv.Storer, emailClient email.Client, d *Delivery) (*kv.SentMessage, error) {
+	call, to := d.Call, d.To
+	previous, err := streamMessage(store, call, d.Type, to)
+	if err != nil {
+		return nil, err
+	}
+	slog.Info("sending email", "call_id", call.ID, "recipient", to, "scheduled_at", call.ScheduledAt)
+	start := time.Now()
+	var messageID, threadID string
+	if previous != nil {
+		references := streamReferences(previous)
+		threadID = references[0]
+		messageID, err = emailClient.Reply(ctx, []string{to}, call.Author, d.Subject, d.Content, call.Campaign, references)
+	} else {
+		messageID, err = emailClient.Send(ctx, []string{to}, call.Author, d.Subject, d.Content, call.Campaign)
+		if call.Mode == model.ModeUpdateStream {
+			threadID = messageID
+		}
+	}
+
+	sm := d.message(messageID, time.Since(start), err)
+	sm.ThreadID = threadID
+	if err != nil {
+		slog.Error("failed to send email", "error", err)
+	} else {
+		slog.Info("sent email", "call_id", call.ID, "recipient", to, "scheduled_at", call.ScheduledAt)
+	}
+	return sm, nil
+}
@@ -0,0 +1,117 @@
+package worker
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/andrewhowdencom/ruf/internal/kv"
+	"github.com/andrewhowdencom/ruf/internal/model"
+)
+
+// defaultInterruptedAfter is how long a message may be recorded as being sent before it is taken to have
+// been interrupted, when no send timeout bounds how long sending it takes.
+const defaultInterruptedAfter = 10 * time.Minute
+
+// interruptedError is the error recorded on a message the worker stopped part way through sending.
+const interruptedError = "the worker stopped while sending the message, so it may or may not have reached its destination"
+
+// sending is the record of a message the worker has started to send. It is replaced by the message once
+// the destination has been called, or put back if the message was not sent after all.
+type sending struct {
+	store    kv.Storer
+	id       string
+	previous *kv.SentMessage
+}
+
+// beginSending records that a call is being sent to a recipient, before its destination is called, so that
+// a worker that stops before it records the outcome does not send the call again. The record it replaces,
+// such as that of a failure being replayed, is kept so that it can be put back.
+func beginSending(store kv.Storer, call *model.Call, destType, to string) (*sending, error) {
+	occurredAt := call.OccurredAt().UTC()
+	id := kv.GenerateID(call.Campaign.ID, call.ID, occurredAt, destType, to)
+	previous, err := store.GetSentMessage(id)
+	if err != nil && !errors.Is(err, kv.ErrNotFound) {
+		return nil, fmt.Errorf("failed to get the record of the message to %s: %w", to, err)
+	}
+	if previous != nil && previous.ID != id {
+		// The store fell back on a message with a short ID that id starts with.
+		previous = nil
+	}
+
+	err = store.AddSentMessage(call.Campaign.ID, call.ID, &kv.SentMessage{
+		SourceID:     call.ID,
+		ScheduledAt:  call.ScheduledAt,
+		OccurredAt:   occurredAt,
+		AttemptedAt:  time.Now().UTC(),
+		Status:       kv.StatusSending,
+		Type:         destType,
+		Destination:  to,
+		CampaignName: call.Campaign.Name,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to record that the message to %s is being sent: %w", to, err)
+	}
+	return &sending{store: store, id: id, previous: previous}, nil
+}
+
+// abandon records that the message was not sent after all, such as because its destination rate limited
+// it, so that it is sent on a later tick. The record it replaced is put back, if there was one.
+func (s *sending) abandon() error {
+	if s.previous != nil {
+		return s.store.UpdateSentMessage(s.previous)
+	}
+	sm, err := s.store.GetSentMessage(s.id)
+	if err != nil {
+		return err
+	}
+	sm.Status, sm.AttemptedAt = kv.StatusDeferred, time.Time{}
+	return s.store.UpdateSentMessage(sm)
+}
+
+// ReconcileSending records the messages a worker stopped part way through sending as interrupted, since
+// whether they reached their destination is not known. They are left for someone to check rather than
+// sent again, which could send them twice. A message is only taken to have been interrupted once it has
+// been sending for a minute longer than the send timeout allows, so that those the leader is still
+// sending are left alone. It returns the messages that were interrupted.
+func (w *Worker) ReconcileSending(now time.Time) ([]*kv.SentMessage, error) {
+	after := defaultInterruptedAfter
+	if o := newOptions(w.opts); o.sendTimeout > 0 {
+		after = o.sendTimeout + time.Minute
+	}
+
+	messages, err := w.store.ListSentMessages()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sent messages: %w", err)
+	}
+
+	var interrupted []*kv.SentMessage
+	for _, sm := range messages {
+		if sm.Status != kv.StatusSending || now.Sub(sm.AttemptedAt) < after {
+			continue
+		}
+		if w.dryRun {
+			slog.Info("dry run: would record message as interrupted", "id", sm.ID, "destination", sm.Destination, "type", sm.Type, "attempted_at", sm.AttemptedAt)
+			continue
+		}
+		slog.Warn("recording message that was interrupted while being sent", "id", sm.ID, "destination", sm.Destination, "type", sm.Type, "attempted_at", sm.AttemptedAt)
+		sm.Status, sm.Error = kv.StatusInterrupted, interruptedError
+		if err := w.store.UpdateSentMessage(sm); err != nil {
+			return interrupted, fmt.Errorf("failed to record message '%s' as interrupted: %w", sm.ID, err)
+		}
+		interrupted = append(interrupted, sm)
+	}
+	return interrupted, nil
+}
+
+// reconcileSending records the messages a previous leader was interrupted while sending, once the worker
+// leads. Messages it cannot record are tried again the next time it is promoted or started.
+func (w *Worker) reconcileSending() {
+	if !w.IsLeader() {
+		return
+	}
+	if _, err := w.ReconcileSending(time.Now().UTC()); err != nil {
+		slog.Error("failed to reconcile messages being sent", "error", err)
+	}
+}
@@ -0,0 +1,84 @@
+package worker
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/andrewhowdencom/ruf/internal/kv"
+)
+
+// tick holds the counts of a single tick of the worker.
+type tick struct {
+	scheduled, due, processed, kept, errors int
+}
+
+// recordRefresh writes the outcome of a refresh of the sources to the status of the worker.
+func (w *Worker) recordRefresh(err error) {
+	w.writeStatus(func(ws *kv.WorkerStatus) {
+		ws.LastRefresh, ws.RefreshError = time.Now().UTC(), errorString(err)
+	})
+}
+
+// recordTick writes the outcome and counts of a tick to the status of the worker.
+func (w *Worker) recordTick(t tick, err error) {
+	w.writeStatus(func(ws *kv.WorkerStatus) {
+		ws.LastTick, ws.TickError = time.Now().UTC(), errorString(err)
+		ws.Scheduled, ws.Due, ws.Processed, ws.Kept, ws.Errors = t.scheduled, t.due, t.processed, t.kept, t.errors
+	})
+}
+
+// recordStop writes to the status of the worker that it has shut down.
+func (w *Worker) recordStop() {
+	w.writeStatus(func(ws *kv.WorkerStatus) {
+		ws.StoppedAt = time.Now().UTC()
+	})
+}
+
+// writeStatus updates the status of the worker and writes it to the datastore, if the worker writes one.
+// A status that cannot be written is logged rather than failing the worker, since the next refresh or
+// tick writes it again.
+func (w *Worker) writeStatus(update func(*kv.WorkerStatus)) {
+	if w.status == nil {
+		return
+	}
+
+	w.mu.Lock()
+	update(w.status)
+	w.status.Leader = w.leader
+	w.status.UpdatedAt = time.Now().UTC()
+	status := *w.status
+	w.mu.Unlock()
+
+	if err := w.store.SetWorkerStatus(&status); err != nil {
+		slog.Warn("failed to write worker status", "holder", status.Holder, "error", err)
+	}
+}
+
+func errorString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
+
+// NewStatusHandler returns the handler serving the status of every worker writing one to the datastore,
+// as JSON.
+func NewStatusHandler(store kv.Storer) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		statuses, err := store.ListWorkerStatuses()
+		if err != nil {
+			slog.Error("failed to list worker statuses", "error", err)
+			http.Error(w, "failed to list worker statuses", http.StatusInternalServerError)
+			return
+		}
+		sort.Slice(statuses, func(i, j int) bool { return statuses[i].Holder < statuses[j].Holder })
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(statuses); err != nil {
+			slog.Error("failed to write worker statuses", "error", err)
+		}
+	})
+}
@@ -0,0 +1,48 @@
+package worker
+
+import (
+	"fmt"
+
+	"github.com/andrewhowdencom/ruf/internal/kv"
+	"github.com/andrewhowdencom/ruf/internal/model"
+)
+
+// streamMessage returns the last message an update stream call sent to a recipient, which its next
+// occurrence edits or replies to. It returns nil when the call is not an update stream, or it has not
+// yet been sent to the recipient.
+func streamMessage(store kv.Storer, call *model.Call, destType, to string) (*kv.SentMessage, error) {
+	if call.Mode != model.ModeUpdateStream {
+		return nil, nil
+	}
+	messages, err := store.ListSentMessagesByCampaign(call.Campaign.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find the last message of update stream %s: %w", call.ID, err)
+	}
+
+	var last *kv.SentMessage
+	for _, sm := range messages {
+		if sm.SourceID != call.ID || sm.Type != destType || sm.Destination != to || sm.Status != kv.StatusSent {
+			continue
+		}
+		if sm.MessageID == "" && sm.Timestamp == "" {
+			continue
+		}
+		if last == nil || sm.SentAt.After(last.SentAt) {
+			last = sm
+		}
+	}
+	return last, nil
+}
+
+// streamReferences returns the Message-IDs an email of an update stream is sent in the thread of: the
+// first email of the stream, followed by the last one, if it is another.
+func streamReferences(previous *kv.SentMessage) []string {
+	thread := previous.ThreadID
+	if thread == "" {
+		thread = previous.MessageID
+	}
+	if previous.MessageID == thread {
+		return []string{thread}
+	}
+	return []string{thread, previous.MessageID}
+}
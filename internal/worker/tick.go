@@ -0,0 +1,54 @@
+package worker
+
+import "time"
+
+// defaultTickInterval is how often the worker sends the calls that are due, unless configured otherwise.
+const defaultTickInterval = time.Minute
+
+// WithTickInterval sets how often the worker sends the calls that are due. Ticks are aligned to multiples
+// of the interval since midnight UTC, such as the start of each minute, rather than to when the worker
+// started.
+func WithTickInterval(interval time.Duration) Option {
+	return func(o *options) {
+		o.tickInterval = interval
+	}
+}
+
+// NextTick returns when the tick after now is due: the next multiple of the interval since midnight UTC,
+// so that a call scheduled for 10:00 is sent at 10:00 however long the worker has been running.
+func NextTick(now time.Time, interval time.Duration) time.Time {
+	if interval <= 0 {
+		interval = defaultTickInterval
+	}
+	utc := now.UTC()
+	day := time.Date(utc.Year(), utc.Month(), utc.Day(), 0, 0, 0, 0, time.UTC)
+	next := day.Add(now.Sub(day).Truncate(interval) + interval)
+	if midnight := day.AddDate(0, 0, 1); next.After(midnight) {
+		// An interval that does not divide a day starts again at midnight.
+		next = midnight
+	}
+	return next.In(now.Location())
+}
+
+// ticker fires at each tick of the interval. It is timed again from the clock after each tick, so that
+// ticks that are late, such as while the process was suspended, do not drift.
+type ticker struct {
+	interval time.Duration
+	timer    *time.Timer
+	C        <-chan time.Time
+}
+
+func newTicker(interval time.Duration) *ticker {
+	timer := time.NewTimer(time.Until(NextTick(time.Now(), interval)))
+	return &ticker{interval: interval, timer: timer, C: timer.C}
+}
+
+// next times the ticker for the tick after now. It is called once a tick has been received.
+func (t *ticker) next() {
+	t.timer.Reset(time.Until(NextTick(time.Now(), t.interval)))
+}
+
+// Stop stops the ticker.
+func (t *ticker) Stop() {
+	t.timer.Stop()
+}
@@ -0,0 +1,93 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/andrewhowdencom/ruf/internal/clients/email"
+	"github.com/andrewhowdencom/ruf/internal/clients/slack"
+	"github.com/andrewhowdencom/ruf/internal/kv"
+	"github.com/andrewhowdencom/ruf/internal/model"
+)
+
+// onTrial reports whether an occurrence of a call is on trial: its campaign is in dry run or has a
+// canary destination, and fewer than trial_occurrences other occurrences of the call have been on
+// trial. Occurrences are counted by the messages recorded for them, so an occurrence that was part way
+// through its trial stays on it.
+func onTrial(store kv.Storer, call *model.Call) (bool, error) {
+	campaign := call.Campaign
+	if !campaign.DryRun && campaign.CanaryDestination == nil {
+		return false, nil
+	}
+	if campaign.TrialOccurrences <= 0 {
+		return true, nil
+	}
+
+	messages, err := store.ListSentMessagesByCampaign(campaign.ID)
+	if err != nil {
+		return false, err
+	}
+	occurredAt := call.OccurredAt().UTC()
+	occurrences := make(map[time.Time]bool)
+	for _, sm := range messages {
+		if sm.SourceID != call.ID || sm.Status != kv.StatusTrial {
+			continue
+		}
+		if sm.OccurredAt.Equal(occurredAt) {
+			return true, nil
+		}
+		occurrences[sm.OccurredAt.UTC()] = true
+	}
+	return len(occurrences) < campaign.TrialOccurrences, nil
+}
+
+// processTrial processes an occurrence of a call on trial. It is logged, if its campaign is in dry run,
+// or sent to the canary destination of the campaign otherwise. Once it has been, the recipients of the
+// call are recorded as kept from it, so that the occurrence is not sent to them when it is scheduled
+// again, and so that it counts towards the trial.
+func processTrial(ctx context.Context, call *model.Call, store kv.Storer, slackClient slack.Client, emailClient email.Client, dryRun bool, opts ...Option) error {
+	campaign := call.Campaign
+	trial := *call
+	trial.Campaign.DryRun, trial.Campaign.CanaryDestination = false, nil
+	reason := "dry run of the campaign"
+	if !campaign.DryRun {
+		trial.Destinations = []model.Destination{{Type: campaign.CanaryDestination.Type, To: campaign.CanaryDestination.To}}
+		reason = fmt.Sprintf("sent to the canary destination of the campaign, %s:%s", campaign.CanaryDestination.Type, strings.Join(campaign.CanaryDestination.To, ","))
+	}
+
+	if err := ProcessCall(ctx, &trial, store, slackClient, emailClient, dryRun || campaign.DryRun, opts...); err != nil {
+		return err
+	}
+	if dryRun {
+		return nil
+	}
+
+	o := newOptions(opts)
+	recorder := kv.WithContext(context.WithoutCancel(ctx), store)
+	dest := call.Destinations[0]
+	for _, to := range dest.To {
+		settled, err := recorder.HasBeenSent(campaign.ID, call.ID, call.OccurredAt(), dest.Type, to)
+		if err != nil {
+			return fmt.Errorf("failed to check if call has been sent: %w", err)
+		}
+		if settled {
+			continue
+		}
+		slog.Info("keeping call from its destination while its campaign is on trial", "call_id", call.ID, "destination", to, "type", dest.Type, "reason", reason)
+		if err := recordSentMessage(ctx, o, recorder, slackClient, emailClient, call, &kv.SentMessage{
+			SourceID:     call.ID,
+			ScheduledAt:  call.ScheduledAt,
+			Status:       kv.StatusTrial,
+			Type:         dest.Type,
+			Destination:  to,
+			CampaignName: campaign.Name,
+			Error:        reason,
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
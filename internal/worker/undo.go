@@ -0,0 +1,55 @@
+package worker
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/andrewhowdencom/ruf/internal/kv"
+)
+
+// WithUndoWindow holds each call for a while after it is due before sending it, with its recipients
+// recorded as pending, so that a call scheduled by mistake can still be cancelled with `ruf sent cancel`.
+func WithUndoWindow(window time.Duration) Option {
+	return func(o *options) {
+		o.undoWindow = window
+	}
+}
+
+// undoing reports whether a due call is still within the undo window, and records its recipients as
+// pending the first time it is, so that they are listed with the short IDs that cancel it.
+func (w *Worker) undoing(call *kv.ScheduledCall, window time.Duration, now time.Time) bool {
+	sendsAt := call.ScheduledAt.Add(window)
+	if window <= 0 || !now.Before(sendsAt) {
+		return false
+	}
+	if w.dryRun {
+		slog.Info("dry run: would hold call for its undo window", "call_id", call.ID, "sends_at", sendsAt)
+		return true
+	}
+
+	dest := call.Destinations[0]
+	occurredAt := call.OccurredAt().UTC()
+	for _, to := range dest.To {
+		// A recipient that has been recorded already, as pending or otherwise, is left as it is.
+		id := kv.GenerateID(call.Campaign.ID, call.ID, occurredAt, dest.Type, to)
+		if sm, err := w.store.GetSentMessage(id); err == nil && sm.ID == id {
+			continue
+		}
+
+		sm := &kv.SentMessage{
+			SourceID:     call.ID,
+			ScheduledAt:  call.ScheduledAt,
+			OccurredAt:   occurredAt,
+			Status:       kv.StatusPending,
+			Type:         dest.Type,
+			Destination:  to,
+			CampaignName: call.Campaign.Name,
+		}
+		if err := w.store.AddSentMessage(call.Campaign.ID, call.ID, sm); err != nil {
+			slog.Error("failed to record call as pending", "call_id", call.ID, "destination", to, "error", err)
+			continue
+		}
+		slog.Info("holding call for its undo window", "call_id", call.ID, "destination", to, "short_id", sm.ShortID, "sends_at", sendsAt)
+	}
+	return true
+}
@@ -1,9 +1,11 @@
 package worker
 
 import (
+	"context"
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"os"
@@ -12,10 +14,24 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/andrewhowdencom/ruf/internal/approval"
+	"github.com/andrewhowdencom/ruf/internal/assets"
+	"github.com/andrewhowdencom/ruf/internal/checklist"
+	"github.com/andrewhowdencom/ruf/internal/clients/chatwork"
 	"github.com/andrewhowdencom/ruf/internal/clients/email"
+	"github.com/andrewhowdencom/ruf/internal/clients/homeassistant"
+	"github.com/andrewhowdencom/ruf/internal/clients/line"
 	"github.com/andrewhowdencom/ruf/internal/clients/slack"
+	"github.com/andrewhowdencom/ruf/internal/clients/slackworkflow"
+	"github.com/andrewhowdencom/ruf/internal/clients/webhook"
+	"github.com/andrewhowdencom/ruf/internal/health"
 	"github.com/andrewhowdencom/ruf/internal/kv"
+	"github.com/andrewhowdencom/ruf/internal/limits"
+	"github.com/andrewhowdencom/ruf/internal/model"
+	"github.com/andrewhowdencom/ruf/internal/owners"
+	"github.com/andrewhowdencom/ruf/internal/policy"
 	"github.com/andrewhowdencom/ruf/internal/poller"
+	"github.com/andrewhowdencom/ruf/internal/processor"
 	"github.com/andrewhowdencom/ruf/internal/scheduler"
 	"github.com/andrewhowdencom/ruf/internal/sourcer"
 	"github.com/spf13/viper"
@@ -29,16 +45,295 @@ type Worker struct {
 	poller            *poller.Poller
 	scheduler         *scheduler.Scheduler
 	refreshInterval   time.Duration
+	tickInterval      time.Duration
 	sources           []*sourcer.Source
 	lastSourcesHash   string
 	mu                sync.RWMutex
 	calculationBefore time.Duration
 	calculationAfter  time.Duration
 	dryRun            bool
+	opts              []Option
+	monitor           *health.Monitor
+	assets            *assets.Cache
+	lease             *leaseOptions
+	leader            bool
+	// signoffsRequested holds the calls whose owners have been asked for their sign-offs, by ID, so
+	// that they are asked once rather than on every tick.
+	signoffsRequested map[string]bool
+	// carried holds the due calls the last tick did not process, by ID, with the number of ticks
+	// they have been carried over.
+	carried map[string]int
+	// refresh asks the running worker to refresh its sources now.
+	refresh chan struct{}
+	// stopping is closed once the running worker has been asked to shut down, so that it starts no
+	// more sends.
+	stopping        chan struct{}
+	shutdownTimeout time.Duration
+	// status is the heartbeat the worker writes to the datastore, or nil if it writes none.
+	status *kv.WorkerStatus
+	// invalidReported holds the problems of each source that is not valid that were last reported to the
+	// failure channel, by URL, so that they are reported once rather than on every poll.
+	invalidReported map[string]string
+}
+
+// Option configures the optional destination clients used to send calls.
+type Option func(*options)
+
+type options struct {
+	chatworkClient  chatwork.Client
+	lineClient      line.Client
+	homeAssistant   homeassistant.Client
+	slackWorkflows  slackworkflow.Client
+	webhooks        map[string]webhook.Client
+	monitor         *health.Monitor
+	assets          *assets.Cache
+	lease           *leaseOptions
+	policy          *policy.Engine
+	limits          *limits.Limits
+	rates           *limits.Buckets
+	owners          *owners.Router
+	notifyFailures  bool
+	recordContent   bool
+	strict          bool
+	budget          *budget
+	sendTimeout     time.Duration
+	shutdownTimeout time.Duration
+	approvers       []string
+	confirmBefore   time.Duration
+	confirmDefault  string
+	statusHolder    string
+	middleware      map[string][]Middleware
+	processors      map[string]processor.ProcessorStack
+	undoWindow      time.Duration
+	failureChannel  *model.Destination
+	tickInterval    time.Duration
+}
+
+type leaseOptions struct {
+	holder string
+	ttl    time.Duration
+}
+
+// WithChatworkClient enables the "chatwork" destination type.
+func WithChatworkClient(client chatwork.Client) Option {
+	return func(o *options) {
+		o.chatworkClient = client
+	}
+}
+
+// WithLineClient enables the "line" destination type.
+func WithLineClient(client line.Client) Option {
+	return func(o *options) {
+		o.lineClient = client
+	}
+}
+
+// WithHomeAssistantClient enables the "homeassistant" destination type.
+func WithHomeAssistantClient(client homeassistant.Client) Option {
+	return func(o *options) {
+		o.homeAssistant = client
+	}
+}
+
+// WithSlackWorkflowClient enables the "slack_workflow" destination type.
+func WithSlackWorkflowClient(client slackworkflow.Client) Option {
+	return func(o *options) {
+		o.slackWorkflows = client
+	}
+}
+
+// WithWebhook enables a destination type, defined in configuration, that is sent through a webhook.
+func WithWebhook(name string, client webhook.Client) Option {
+	return func(o *options) {
+		if o.webhooks == nil {
+			o.webhooks = make(map[string]webhook.Client)
+		}
+		o.webhooks[name] = client
+	}
+}
+
+// WithMonitor records each refresh of the schedule with the health monitor.
+func WithMonitor(monitor *health.Monitor) Option {
+	return func(o *options) {
+		o.monitor = monitor
+	}
+}
+
+// WithAssets prefetches the remote content calls render when their sources are refreshed, and renders
+// calls with the content they were pinned to then.
+func WithAssets(cache *assets.Cache) Option {
+	return func(o *options) {
+		o.assets = cache
+	}
+}
+
+// WithPolicy blocks the calls that violate the dispatch hooks of the policy engine, recording them
+// as failed.
+func WithPolicy(engine *policy.Engine) Option {
+	return func(o *options) {
+		o.policy = engine
+	}
+}
+
+// WithLimits holds back the calls that would be sent in the quiet hours of their destination, or that
+// would exceed its daily cap, until the limits allow them.
+func WithLimits(l *limits.Limits) Option {
+	return func(o *options) {
+		o.limits = l
+	}
+}
+
+// WithRateLimits paces the calls sent to each destination type with the buckets, waiting for them
+// within the send timeout of a call and keeping the recipients that would wait longer for a later tick.
+// Destination types that ask the worker to retry later, as Slack does when it rate limits a client,
+// pause their bucket until then.
+func WithRateLimits(b *limits.Buckets) Option {
+	return func(o *options) {
+		o.rates = b
+	}
+}
+
+// WithFailureNotifications tells the author of a call, by Slack direct message or email, when it could
+// not be sent to a recipient, with the reason and the command that sends it again.
+func WithFailureNotifications() Option {
+	return func(o *options) {
+		o.notifyFailures = true
+	}
+}
+
+// WithOwners finds the owners of calls with the rules of the router, as well as from their campaigns.
+// Owners are told of the failures of calls alongside their authors, and asked for the sign-offs that
+// due calls wait for.
+func WithOwners(router *owners.Router) Option {
+	return func(o *options) {
+		o.owners = router
+	}
+}
+
+// WithApprovers asks the approvers, by email address, to approve the calls that require approval once
+// they are due. Without approvers, the owners of the campaign of each call are asked.
+func WithApprovers(approvers ...string) Option {
+	return func(o *options) {
+		o.approvers = approvers
+	}
+}
+
+// approversOf returns who is asked to approve a call.
+func (o *options) approversOf(call *model.Call) []string {
+	if len(o.approvers) > 0 {
+		return o.approvers
+	}
+	return o.ownersOf(call)
+}
+
+// WithConfirmation sets how long before they are due the authors of calls that ask for confirmation are
+// sent a preview, and what happens to a call whose author has not answered by then: model.ConfirmSend or
+// model.ConfirmCancel. The confirm field of a call overrides either.
+func WithConfirmation(before time.Duration, fallback string) Option {
+	return func(o *options) {
+		o.confirmBefore = before
+		o.confirmDefault = fallback
+	}
+}
+
+// confirmationOf returns how long before a call is due its author is asked to confirm it, and what
+// happens if they do not answer.
+func (o *options) confirmationOf(call *model.Call) (time.Duration, string) {
+	before, fallback := o.confirmBefore, o.confirmDefault
+	if call.Confirm != nil {
+		if d, err := time.ParseDuration(call.Confirm.Before); err == nil && d > 0 {
+			before = d
+		}
+		if call.Confirm.Default != "" {
+			fallback = call.Confirm.Default
+		}
+	}
+	if before <= 0 {
+		before = defaultConfirmBefore
+	}
+	if fallback == "" {
+		fallback = model.ConfirmSend
+	}
+	return before, fallback
+}
+
+// WithContentSnapshots records the subject and content of each message as it was sent, so that it can
+// be exported later. Without it, only the author and the template data are recorded.
+func WithContentSnapshots() Option {
+	return func(o *options) {
+		o.recordContent = true
+	}
+}
+
+// WithStrictTemplates holds a call, rather than sending it, when its templates refer to values its data
+// does not have or its data_from cannot be fetched, so that "<no value>" never reaches a recipient. The
+// call is tried again on each tick until it renders, or it is missed.
+func WithStrictTemplates() Option {
+	return func(o *options) {
+		o.strict = true
+	}
+}
+
+// WithTickBudget bounds the calls the worker processes in a tick, by number and by time. Zero values
+// leave a bound unset. Due calls beyond the budget are carried over to the next tick, and calls are
+// processed by priority and age, so that the most important and longest waiting calls go first.
+func WithTickBudget(maxCalls int, maxDuration time.Duration) Option {
+	return func(o *options) {
+		o.budget = &budget{maxCalls: maxCalls, maxDuration: maxDuration}
+	}
+}
+
+// WithSendTimeout bounds how long a call may take to send, so that a destination that stops responding
+// cannot hold up the rest of the tick. Once it has run out, the call fails for the recipients it has not
+// reached, and the failures are recorded as any other.
+func WithSendTimeout(timeout time.Duration) Option {
+	return func(o *options) {
+		o.sendTimeout = timeout
+	}
+}
+
+// WithShutdownTimeout bounds how long a worker that has been asked to shut down waits for the calls it
+// is sending. Once it has run out, the sends still running are cancelled, and fail as they would once
+// the send timeout has run out. Without it, the worker waits for as long as the sends take.
+func WithShutdownTimeout(timeout time.Duration) Option {
+	return func(o *options) {
+		o.shutdownTimeout = timeout
+	}
+}
+
+// WithLease runs the worker as one of several instances sharing a datastore. Only the instance holding
+// the lease refreshes the schedule and sends calls; the others follow, polling sources so that they
+// are ready to take over once the leader has not renewed the lease for the ttl.
+func WithLease(holder string, ttl time.Duration) Option {
+	return func(o *options) {
+		o.lease = &leaseOptions{holder: holder, ttl: ttl}
+	}
+}
+
+// WithStatus has the worker write its status to the datastore, under the holder, after each refresh and
+// tick, so that whether it is alive and current can be told from another machine.
+func WithStatus(holder string) Option {
+	return func(o *options) {
+		o.statusHolder = holder
+	}
+}
+
+// ownersOf returns the owners of the campaign of a call.
+func (o *options) ownersOf(call *model.Call) []string {
+	owners, _ := o.owners.Owners(call.Campaign)
+	return owners
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
 }
 
 // New creates a new worker.
-func New(store kv.Storer, slackClient slack.Client, emailClient email.Client, poller *poller.Poller, scheduler *scheduler.Scheduler, refreshInterval time.Duration, dryRun bool) (*Worker, error) {
+func New(store kv.Storer, slackClient slack.Client, emailClient email.Client, poller *poller.Poller, scheduler *scheduler.Scheduler, refreshInterval time.Duration, dryRun bool, opts ...Option) (*Worker, error) {
 	before, err := time.ParseDuration(viper.GetString("worker.calculation.before"))
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse worker.calculation.before: %w", err)
@@ -48,6 +343,11 @@ func New(store kv.Storer, slackClient slack.Client, emailClient email.Client, po
 		return nil, fmt.Errorf("failed to parse worker.calculation.after: %w", err)
 	}
 
+	o := newOptions(opts)
+	var status *kv.WorkerStatus
+	if o.statusHolder != "" {
+		status = &kv.WorkerStatus{Holder: o.statusHolder, DryRun: dryRun, StartedAt: time.Now().UTC()}
+	}
 	return &Worker{
 		store:             store,
 		slackClient:       slackClient,
@@ -58,78 +358,197 @@ func New(store kv.Storer, slackClient slack.Client, emailClient email.Client, po
 		calculationBefore: before,
 		calculationAfter:  after,
 		dryRun:            dryRun,
+		opts:              opts,
+		monitor:           o.monitor,
+		assets:            o.assets,
+		lease:             o.lease,
+		leader:            o.lease == nil,
+		signoffsRequested: make(map[string]bool),
+		refresh:           make(chan struct{}, 1),
+		stopping:          make(chan struct{}),
+		shutdownTimeout:   o.shutdownTimeout,
+		tickInterval:      o.tickInterval,
+		status:            status,
 	}, nil
 }
 
 // RunOnce performs a single poll for calls and sends them.
-func (w *Worker) RunOnce() error {
+func (w *Worker) RunOnce(ctx context.Context) error {
 	if err := w.RefreshSources(); err != nil {
 		return fmt.Errorf("failed to refresh sources: %w", err)
 	}
-	if err := w.ProcessMessages(); err != nil {
+	if err := w.ProcessMessages(ctx); err != nil {
 		return fmt.Errorf("failed to process messages: %w", err)
 	}
 	return nil
 }
 
-// Run starts the worker.
-func (w *Worker) Run() error {
+// Run starts the worker, which runs until the context is done or the process is sent SIGINT or
+// SIGTERM. On shutdown the worker starts no more sends, waits for those in flight for up to the
+// shutdown timeout, and releases its lease so that a standby can take over at once. Calls that were due
+// but not yet sent stay scheduled for the next run.
+func (w *Worker) Run(ctx context.Context) error {
 	slog.Info("starting worker")
 
 	signals := make(chan os.Signal, 1)
 	signal.Notify(signals, syscall.SIGHUP)
+	defer signal.Stop(signals)
+
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(stop)
+
+	// Sends outlive the context of the worker, so that those in flight when it is done can finish, and
+	// are only cancelled once the shutdown timeout has run out.
+	sendCtx, cancelSends := context.WithCancel(context.WithoutCancel(ctx))
+	defer cancelSends()
+	done := make(chan struct{})
+	defer close(done)
+	go w.awaitShutdown(ctx, stop, done, cancelSends)
 
 	refreshTicker := time.NewTicker(w.refreshInterval)
 	defer refreshTicker.Stop()
 
-	messageTicker := time.NewTicker(1 * time.Minute)
+	messageTicker := newTicker(w.tickInterval)
 	defer messageTicker.Stop()
 
+	// Without a lease the worker always leads, and there is no heartbeat to send.
+	var heartbeat <-chan time.Time
+	if w.lease != nil {
+		heartbeatTicker := time.NewTicker(w.lease.ttl / 3)
+		defer heartbeatTicker.Stop()
+		heartbeat = heartbeatTicker.C
+		w.Heartbeat()
+	}
+	w.reconcileSending()
+
 	// Run a poll on startup
 	if err := w.RefreshSources(); err != nil {
 		slog.Error("error running initial source refresh", "error", err)
 	}
-	if err := w.ProcessMessages(); err != nil {
+	if err := w.ProcessMessages(sendCtx); err != nil {
 		slog.Error("error running initial message processing", "error", err)
 	}
 
 	for {
 		select {
+		case <-w.stopping:
+			w.releaseLease()
+			w.recordStop()
+			slog.Info("worker stopped")
+			return nil
 		case <-refreshTicker.C:
 			if err := w.RefreshSources(); err != nil {
 				slog.Error("error running source refresh", "error", err)
 			}
 		case <-messageTicker.C:
-			if err := w.ProcessMessages(); err != nil {
+			messageTicker.next()
+			if err := w.ProcessMessages(sendCtx); err != nil {
 				slog.Error("error running message processing", "error", err)
 			}
+		case <-heartbeat:
+			if promoted := w.Heartbeat(); promoted {
+				w.reconcileSending()
+				if err := w.RefreshSources(); err != nil {
+					slog.Error("error running source refresh", "error", err)
+				}
+				if err := w.ProcessMessages(sendCtx); err != nil {
+					slog.Error("error running message processing", "error", err)
+				}
+			}
 		case <-signals:
 			slog.Info("SIGHUP received, running poller")
 			refreshTicker.Reset(w.refreshInterval)
 			if err := w.RefreshSources(); err != nil {
 				slog.Error("error running source refresh", "error", err)
 			}
+		case <-w.refresh:
+			slog.Info("refresh requested, running poller")
+			refreshTicker.Reset(w.refreshInterval)
+			if err := w.RefreshSources(); err != nil {
+				slog.Error("error running source refresh", "error", err)
+			}
 		}
 	}
 }
 
+// awaitShutdown waits for the context to be done or a signal to stop, and then marks the worker as
+// stopping. The sends in flight are cancelled once the shutdown timeout has run out, unless the worker
+// has returned first.
+func (w *Worker) awaitShutdown(ctx context.Context, stop <-chan os.Signal, done <-chan struct{}, cancelSends context.CancelFunc) {
+	select {
+	case sig := <-stop:
+		slog.Info("shutting down", "signal", sig.String(), "timeout", w.shutdownTimeout)
+	case <-ctx.Done():
+		slog.Info("shutting down", "timeout", w.shutdownTimeout)
+	case <-done:
+		return
+	}
+	close(w.stopping)
+
+	if w.shutdownTimeout <= 0 {
+		return
+	}
+	timer := time.NewTimer(w.shutdownTimeout)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		slog.Warn("shutdown timeout ran out, cancelling the calls being sent")
+		cancelSends()
+	case <-done:
+	}
+}
+
+// isStopping reports whether the worker has been asked to shut down.
+func (w *Worker) isStopping() bool {
+	select {
+	case <-w.stopping:
+		return true
+	default:
+		return false
+	}
+}
+
+// Refresh asks the running worker to refresh its sources now, without waiting for the refresh. Asking
+// again before the refresh has started asks for it once.
+func (w *Worker) Refresh() {
+	select {
+	case w.refresh <- struct{}{}:
+	default:
+	}
+}
+
 // RefreshSources performs a poll for sources
-func (w *Worker) RefreshSources() error {
+func (w *Worker) RefreshSources() (err error) {
+	defer func() { w.recordRefresh(err) }()
+
 	slog.Debug("refreshing sources")
 	urls := viper.GetStringSlice("source.urls")
 	slog.Debug("polling for calls", "urls", urls)
 	sources, err := w.poller.Poll(urls)
+	w.reportInvalidSources(context.Background())
 	if err != nil {
 		return err
 	}
 
+	// Assets are pinned before the sources are hashed and scheduled, so that the scheduled calls keep
+	// their pins, and new content for an asset counts as a change.
+	if w.assets != nil {
+		if err := w.assets.Prefetch(sources); err != nil {
+			slog.Warn("failed to prefetch assets, they will be fetched when sent", "error", err)
+		}
+	}
+
 	// Check if the sources have changed
 	newSourcesHash, err := w.hashSources(sources)
 	if err != nil {
 		return fmt.Errorf("failed to hash sources: %w", err)
 	}
 
-	if newSourcesHash != w.lastSourcesHash {
+	if !w.IsLeader() {
+		// Followers keep their sources current, but leave the schedule to the leader.
+		slog.Debug("following the leader, not refreshing schedule")
+	} else if newSourcesHash != w.lastSourcesHash {
 		slog.Info("sources have changed, refreshing schedule")
 		if err := w.scheduler.RefreshSchedule(sources, time.Now(), w.calculationBefore, w.calculationAfter); err != nil {
 			return fmt.Errorf("failed to refresh schedule: %w", err)
@@ -141,20 +560,54 @@ func (w *Worker) RefreshSources() error {
 	w.sources = sources
 	w.mu.Unlock()
 
+	if w.monitor != nil {
+		w.monitor.RecordRefresh(time.Now())
+	}
+
 	return nil
 }
 
-// ProcessMessages performs a single poll for calls and sends them.
-func (w *Worker) ProcessMessages() error {
+// ProcessMessages performs a single poll for calls and sends them. Calls stop being sent once the
+// context is done.
+func (w *Worker) ProcessMessages(ctx context.Context) (err error) {
+	var t tick
+	defer func() { w.recordTick(t, err) }()
+
+	if !w.IsLeader() {
+		slog.Debug("following the leader, not sending calls")
+		return nil
+	}
+	if w.isStopping() {
+		return nil
+	}
+
+	w.expireMessages(ctx, time.Now().UTC())
+
 	calls, err := w.store.ListScheduledCalls()
 	if err != nil {
 		return fmt.Errorf("failed to list scheduled calls: %w", err)
 	}
+	t.scheduled = len(calls)
 
+	started := time.Now()
+	undoWindow := newOptions(w.opts).undoWindow
+	var due []*kv.ScheduledCall
 	for _, call := range calls {
+		if ok, err := resolveDependency(w.store, call); err != nil {
+			slog.Error("failed to resolve the call waited for", "call_id", call.Call.ID, "error", err)
+			continue
+		} else if !ok {
+			slog.Debug("skipping call waiting for another call to be sent", "call_id", call.Call.ID, "after", call.DependsOn.CallID)
+			continue
+		}
+
 		now := time.Now().UTC()
 		effectiveScheduledAt := call.ScheduledAt
 
+		if call.Call.Confirm != nil {
+			w.confirm(ctx, call, now)
+		}
+
 		// Don't process calls scheduled for the future.
 		if now.Before(effectiveScheduledAt) {
 			slog.Debug("skipping call scheduled for the future", "call_id", call.ID, "effective_scheduled_at", effectiveScheduledAt)
@@ -166,13 +619,14 @@ func (w *Worker) ProcessMessages() error {
 			slog.Warn("skipping call outside lookback period", "call_id", call.Call.ID, "scheduled_at", effectiveScheduledAt)
 			dest := call.Call.Destinations[0]
 			to := dest.To[0]
-			err := w.store.AddSentMessage(call.Call.Campaign.ID, call.Call.ID, &kv.SentMessage{
+			err := recordSentMessage(ctx, newOptions(w.opts), w.store, w.slackClient, w.emailClient, &call.Call, &kv.SentMessage{
 				SourceID:     call.Call.ID,
 				ScheduledAt:  effectiveScheduledAt,
 				Status:       kv.StatusFailed,
 				Type:         dest.Type,
 				Destination:  to,
 				CampaignName: call.Call.Campaign.Name,
+				Error:        "missed: not sent within worker.missed_lookback of its scheduled time",
 			})
 			if err != nil {
 				slog.Error("failed to add sent message for missed call", "call_id", call.Call.ID, "error", err)
@@ -182,19 +636,89 @@ func (w *Worker) ProcessMessages() error {
 			if err := w.store.DeleteScheduledCall(call.Call.ID); err != nil {
 				slog.Error("failed to delete scheduled call", "call_id", call.Call.ID, "error", err)
 			}
+			t.processed++
+			continue
+		}
+
+		if pending, err := checklist.Pending(w.store, call); err != nil {
+			slog.Error("failed to check the checklist of the call", "call_id", call.Call.ID, "error", err)
+			continue
+		} else if len(pending) > 0 {
+			slog.Debug("skipping call with checklist items that are not signed off", "call_id", call.Call.ID, "pending", pending)
+			w.requestSignoff(ctx, call, pending)
+			continue
+		}
+
+		if call.Call.RequiresApproval {
+			if a, requested, err := w.awaitApproval(call, now); err != nil {
+				slog.Error("failed to check the approval of the call", "call_id", call.Call.ID, "error", err)
+				continue
+			} else if a.Status == kv.ApprovalPending {
+				slog.Debug("skipping call waiting for approval", "call_id", call.Call.ID)
+				if requested {
+					w.requestApproval(ctx, call)
+				}
+				continue
+			}
+		}
+
+		if w.undoing(call, undoWindow, now) {
+			slog.Debug("skipping call within its undo window", "call_id", call.Call.ID)
 			continue
 		}
 
-		if err := ProcessCall(&call.Call, w.store, w.slackClient, w.emailClient, w.dryRun); err != nil {
+		due = append(due, call)
+	}
+
+	t.due = len(due)
+
+	w.mu.RLock()
+	carried := w.carried
+	w.mu.RUnlock()
+	prioritise(due, carried)
+
+	b := newOptions(w.opts).budget
+	next := make(map[string]int)
+	for i, call := range due {
+		if b.exhausted(i, time.Since(started)) {
+			for _, c := range due[i:] {
+				next[c.ID] = carried[c.ID] + 1
+			}
+			slog.Warn("tick budget spent, carrying calls over to the next tick", "processed", i, "carried", len(due)-i)
+			t.kept += len(due) - i
+			break
+		}
+		// Calls not yet sent when the worker is shut down stay scheduled, and are sent by the next run.
+		if w.isStopping() {
+			slog.Info("shutting down, leaving the remaining calls scheduled", "processed", i, "remaining", len(due)-i)
+			t.kept += len(due) - i
+			break
+		}
+
+		if err := ProcessCall(ctx, &call.Call, w.store, w.slackClient, w.emailClient, w.dryRun, w.opts...); errors.Is(err, ErrDeferred) || errors.Is(err, ErrHeld) {
+			// The call is kept, and sent once the limits of its destination allow it, or it renders.
+			slog.Debug("keeping deferred call", "call_id", call.Call.ID, "error", err)
+			t.kept++
+		} else if err != nil {
 			slog.Error("error processing call", "call_id", call.Call.ID, "error", err)
+			t.errors++
 		} else {
+			t.processed++
 			// Clean up the scheduled call from the datastore
 			if err := w.store.DeleteScheduledCall(call.Call.ID); err != nil {
 				slog.Error("failed to delete scheduled call", "call_id", call.Call.ID, "error", err)
 			}
+			if !w.dryRun {
+				clearCancellation(w.store, call.Call.ID)
+				clearReschedule(w.store, call.Call.ID)
+			}
 		}
 	}
 
+	w.mu.Lock()
+	w.carried = next
+	w.mu.Unlock()
+
 	return nil
 }
 
@@ -206,3 +730,41 @@ func (w *Worker) hashSources(sources []*sourcer.Source) (string, error) {
 	hash := sha256.Sum256(b)
 	return hex.EncodeToString(hash[:]), nil
 }
+
+// awaitApproval returns the approval of a due call that requires one, recording it as pending the
+// first time. A dry run records nothing, and so asks no one.
+func (w *Worker) awaitApproval(call *kv.ScheduledCall, now time.Time) (*kv.Approval, bool, error) {
+	if w.dryRun {
+		a, err := approval.Status(w.store, call)
+		return a, false, err
+	}
+	return approval.Await(w.store, call, now)
+}
+
+// requestApproval asks the approvers of a due call to approve it.
+func (w *Worker) requestApproval(ctx context.Context, call *kv.ScheduledCall) {
+	approvers := newOptions(w.opts).approversOf(&call.Call)
+	if len(approvers) == 0 {
+		slog.Warn("call waits for approval, but has no one to ask", "call_id", call.Call.ID)
+		return
+	}
+	slog.Info("requesting approval of the call", "call_id", call.Call.ID, "approvers", approvers)
+	requestApproval(ctx, w.slackClient, w.emailClient, call, approvers)
+}
+
+// requestSignoff asks the owners of a due call for the sign-offs it waits for, once.
+func (w *Worker) requestSignoff(ctx context.Context, call *kv.ScheduledCall, pending []string) {
+	owners := newOptions(w.opts).ownersOf(&call.Call)
+	if len(owners) == 0 || w.dryRun {
+		return
+	}
+	w.mu.Lock()
+	requested := w.signoffsRequested[call.ID]
+	w.signoffsRequested[call.ID] = true
+	w.mu.Unlock()
+	if requested {
+		return
+	}
+	slog.Info("requesting sign-off from the owners of the call", "call_id", call.Call.ID, "owners", owners, "pending", pending)
+	requestSignoff(ctx, w.slackClient, w.emailClient, call, owners, pending)
+}
@@ -1,6 +1,7 @@
 package worker_test
 
 import (
+	"context"
 	"testing"
 	"time"
 
@@ -76,7 +77,7 @@ _italic_
 
 	err = w.RefreshSources()
 	assert.NoError(t, err)
-	err = w.ProcessMessages()
+	err = w.ProcessMessages(context.Background())
 	assert.NoError(t, err)
 
 	// Assertions for Slack mrkdwn
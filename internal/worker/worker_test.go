@@ -1,14 +1,20 @@
 package worker_test
 
 import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 
+	"github.com/andrewhowdencom/ruf/internal/approval"
 	"github.com/andrewhowdencom/ruf/internal/clients/email"
 	"github.com/andrewhowdencom/ruf/internal/clients/slack"
 	"github.com/andrewhowdencom/ruf/internal/datastore"
 	"github.com/andrewhowdencom/ruf/internal/kv"
 	"github.com/andrewhowdencom/ruf/internal/model"
+	"github.com/andrewhowdencom/ruf/internal/owners"
 	"github.com/andrewhowdencom/ruf/internal/poller"
 	"github.com/andrewhowdencom/ruf/internal/scheduler"
 	"github.com/andrewhowdencom/ruf/internal/sourcer"
@@ -88,7 +94,7 @@ func TestWorker_RunTick(t *testing.T) {
 	err = w.RefreshSources()
 	assert.NoError(t, err)
 
-	err = w.ProcessMessages()
+	err = w.ProcessMessages(context.Background())
 	assert.NoError(t, err)
 
 	sentMessages, err := store.ListSentMessages()
@@ -152,7 +158,7 @@ func TestWorker_RunTickWithOldCall(t *testing.T) {
 
 	err = w.RefreshSources()
 	assert.NoError(t, err)
-	err = w.ProcessMessages()
+	err = w.ProcessMessages(context.Background())
 	assert.NoError(t, err)
 
 	sentMessages, err := store.ListSentMessages()
@@ -224,7 +230,7 @@ func TestWorker_RunTickWithDeletedCall(t *testing.T) {
 
 	err = w.RefreshSources()
 	assert.NoError(t, err)
-	err = w.ProcessMessages()
+	err = w.ProcessMessages(context.Background())
 	assert.NoError(t, err)
 
 	// Check that the slack client was not called
@@ -296,10 +302,521 @@ func TestWorker_RunTickWithEvent(t *testing.T) {
 
 	err = w.RefreshSources()
 	assert.NoError(t, err)
-	err = w.ProcessMessages()
+	err = w.ProcessMessages(context.Background())
 	assert.NoError(t, err)
 
 	sentMessages, err := store.ListSentMessages()
 	assert.NoError(t, err)
 	assert.Len(t, sentMessages, 1)
 }
+
+func TestWorker_RunTickWithAfter(t *testing.T) {
+	store := datastore.NewMockStore()
+	slackClient := slack.NewMockClient()
+	emailClient := email.NewMockClient()
+
+	campaign := model.Campaign{ID: "mock-campaign", Name: "Mock Campaign"}
+	dest := []model.Destination{{Type: "slack", To: []string{"test-channel"}}}
+	s := &mockSourcer{
+		sourcesBySource: map[string]*sourcer.Source{
+			"mock://url": {
+				Calls: []model.Call{
+					{ID: "first", Subject: "First", Content: "First", Campaign: campaign, Destinations: dest, Triggers: []model.Trigger{{ScheduledAt: time.Now().Add(-1 * time.Minute)}}},
+					{ID: "second", Subject: "Second", Content: "Second", Campaign: campaign, Destinations: dest, Triggers: []model.Trigger{{After: "first"}}},
+				},
+			},
+		},
+	}
+
+	p := poller.New(s, 1*time.Minute)
+	viper.Set("source.urls", []string{"mock://url"})
+	viper.Set("worker.missed_lookback", "10m")
+	viper.Set("worker.calculation.before", "24h")
+	viper.Set("worker.calculation.after", "24h")
+
+	w, err := worker.New(store, slackClient, emailClient, p, scheduler.New(store), 1*time.Minute, false)
+	assert.NoError(t, err)
+	assert.NoError(t, w.RefreshSources())
+
+	// The second call is sent once the first has been, without the schedule being refreshed.
+	assert.NoError(t, w.ProcessMessages(context.Background()))
+	assert.NoError(t, w.ProcessMessages(context.Background()))
+
+	posts := slackClient.PostMessageCalls()
+	if assert.Len(t, posts, 2) {
+		assert.Equal(t, "First", posts[0].Text)
+		assert.Equal(t, "Second", posts[1].Text)
+	}
+	scheduled, err := store.ListScheduledCalls()
+	assert.NoError(t, err)
+	assert.Empty(t, scheduled)
+}
+
+func TestWorker_ProcessMessagesWithTickBudget(t *testing.T) {
+	store := datastore.NewMockStore()
+	slackClient := slack.NewMockClient()
+	emailClient := email.NewMockClient()
+
+	viper.Set("worker.missed_lookback", "1h")
+	viper.Set("worker.calculation.before", "24h")
+	viper.Set("worker.calculation.after", "24h")
+
+	now := time.Now().UTC()
+	schedule := func(id string, priority int, age time.Duration) {
+		assert.NoError(t, store.AddScheduledCall(&kv.ScheduledCall{
+			Call: model.Call{
+				ID:           id,
+				Content:      id,
+				Priority:     priority,
+				Destinations: []model.Destination{{Type: "slack", To: []string{"#general"}}},
+				Campaign:     model.Campaign{ID: "campaign", Name: "Campaign"},
+			},
+			ScheduledAt: now.Add(-age),
+		}))
+	}
+	schedule("urgent", 2, time.Minute)
+	schedule("older", 0, 3*time.Minute)
+	schedule("newer", 0, 2*time.Minute)
+
+	w, err := worker.New(store, slackClient, emailClient, nil, nil, time.Minute, false, worker.WithTickBudget(1, 0))
+	assert.NoError(t, err)
+
+	assert.NoError(t, w.ProcessMessages(context.Background()))
+	assert.Equal(t, 2, w.QueueDepth())
+
+	// The calls carried over have gained a priority of one, and the oldest of them goes before a new
+	// call of the same priority.
+	schedule("later", 1, 0)
+	assert.NoError(t, w.ProcessMessages(context.Background()))
+	assert.NoError(t, w.ProcessMessages(context.Background()))
+	assert.NoError(t, w.ProcessMessages(context.Background()))
+	assert.Equal(t, 0, w.QueueDepth())
+
+	var sent []string
+	for _, post := range slackClient.PostMessageCalls() {
+		sent = append(sent, post.Text)
+	}
+	assert.Equal(t, []string{"urgent", "older", "newer", "later"}, sent)
+}
+
+func TestWorker_ProcessMessagesWithStatus(t *testing.T) {
+	store := datastore.NewMockStore()
+
+	viper.Set("worker.missed_lookback", "1h")
+	viper.Set("worker.calculation.before", "24h")
+	viper.Set("worker.calculation.after", "24h")
+
+	now := time.Now().UTC()
+	for _, id := range []string{"first", "second", "third"} {
+		assert.NoError(t, store.AddScheduledCall(&kv.ScheduledCall{
+			Call: model.Call{
+				ID:           id,
+				Content:      id,
+				Destinations: []model.Destination{{Type: "slack", To: []string{"#general"}}},
+				Campaign:     model.Campaign{ID: "campaign", Name: "Campaign"},
+			},
+			ScheduledAt: now.Add(-time.Minute),
+		}))
+	}
+	assert.NoError(t, store.AddScheduledCall(&kv.ScheduledCall{Call: model.Call{ID: "later"}, ScheduledAt: now.Add(time.Hour)}))
+
+	w, err := worker.New(store, slack.NewMockClient(), email.NewMockClient(), nil, nil, time.Minute, false,
+		worker.WithTickBudget(1, 0), worker.WithStatus("host/1"))
+	assert.NoError(t, err)
+	assert.NoError(t, w.ProcessMessages(context.Background()))
+
+	// The status records the counts of the tick, and is served with that of every other worker.
+	rec := httptest.NewRecorder()
+	worker.NewStatusHandler(store).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/status/workers", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	var statuses []*kv.WorkerStatus
+	assert.NoError(t, json.NewDecoder(rec.Body).Decode(&statuses))
+	if assert.Len(t, statuses, 1) {
+		status := statuses[0]
+		assert.Equal(t, "host/1", status.Holder)
+		assert.True(t, status.Leader)
+		assert.WithinDuration(t, time.Now(), status.LastTick, time.Minute)
+		assert.Empty(t, status.TickError)
+		assert.Equal(t, 4, status.Scheduled)
+		assert.Equal(t, 3, status.Due)
+		assert.Equal(t, 1, status.Processed)
+		assert.Equal(t, 2, status.Kept)
+		assert.Zero(t, status.Errors)
+	}
+}
+
+func TestWorker_ProcessMessagesWithChecklist(t *testing.T) {
+	store := datastore.NewMockStore()
+	slackClient := slack.NewMockClient()
+	emailClient := email.NewMockClient()
+
+	viper.Set("worker.missed_lookback", "1h")
+
+	call := &kv.ScheduledCall{
+		Call: model.Call{
+			ID:           "launch",
+			Content:      "We have launched",
+			Destinations: []model.Destination{{Type: "slack", To: []string{"#general"}}},
+			Campaign:     model.Campaign{ID: "campaign", Name: "Campaign", Checklist: []string{"content reviewed", "legal"}},
+		},
+		ScheduledAt: time.Now().UTC().Add(-time.Minute),
+	}
+	assert.NoError(t, store.AddScheduledCall(call))
+
+	var requested [][]string
+	slackClient.RequestSignoffFunc = func(_ context.Context, ownerEmail, callID, shortID string, pending []string) error {
+		requested = append(requested, append([]string{ownerEmail}, pending...))
+		return nil
+	}
+	router, err := owners.New([]owners.Rule{{Match: "camp*", Owners: []string{"launch-team@example.com"}}})
+	assert.NoError(t, err)
+
+	w, err := worker.New(store, slackClient, emailClient, nil, nil, time.Minute, false, worker.WithOwners(router))
+	assert.NoError(t, err)
+
+	// The call is held until every item of the checklist is signed off, and its owners are asked for
+	// the sign-offs once.
+	assert.NoError(t, store.AddSignoff(&kv.Signoff{CampaignID: "campaign", CallID: "launch", Item: "content reviewed", By: "jane"}))
+	assert.NoError(t, w.ProcessMessages(context.Background()))
+	assert.NoError(t, w.ProcessMessages(context.Background()))
+	assert.Empty(t, slackClient.PostMessageCalls())
+	assert.Equal(t, [][]string{{"launch-team@example.com", "legal"}}, requested)
+
+	assert.NoError(t, store.AddSignoff(&kv.Signoff{CampaignID: "campaign", CallID: "launch", Item: "legal", By: "joe"}))
+	assert.NoError(t, w.ProcessMessages(context.Background()))
+	assert.Len(t, slackClient.PostMessageCalls(), 1)
+}
+
+func TestWorker_ProcessMessagesWithConfirmation(t *testing.T) {
+	store := datastore.NewMockStore()
+	slackClient := slack.NewMockClient()
+
+	viper.Set("worker.missed_lookback", "1h")
+
+	newCall := func(id, fallback string, in time.Duration) *kv.ScheduledCall {
+		return &kv.ScheduledCall{
+			Call: model.Call{
+				ID:           id,
+				Author:       "jane@example.com",
+				Content:      "Hello, {{ .name }}!",
+				Data:         map[string]interface{}{"name": "world"},
+				Confirm:      &model.Confirm{Before: "1h", Default: fallback},
+				Destinations: []model.Destination{{Type: "slack", To: []string{"#general"}}},
+				Campaign:     model.Campaign{ID: "campaign", Name: "Campaign"},
+			},
+			ScheduledAt: time.Now().UTC().Add(in),
+		}
+	}
+	assert.NoError(t, store.AddScheduledCall(newCall("unanswered", model.ConfirmCancel, 10*time.Minute)))
+	assert.NoError(t, store.AddScheduledCall(newCall("later", model.ConfirmSend, 2*time.Hour)))
+
+	var previews []string
+	slackClient.RequestConfirmationFunc = func(_ context.Context, authorEmail, callID, shortID, preview, fallback string, at time.Time) error {
+		previews = append(previews, authorEmail, callID, preview, fallback)
+		return nil
+	}
+
+	w, err := worker.New(store, slackClient, email.NewMockClient(), nil, nil, time.Minute, false)
+	assert.NoError(t, err)
+
+	// The author is sent the rendered preview once the call is within an hour of being due, and once.
+	assert.NoError(t, w.ProcessMessages(context.Background()))
+	assert.NoError(t, w.ProcessMessages(context.Background()))
+	assert.Equal(t, []string{"jane@example.com", "unanswered", "To: #general (slack)\nHello, world!", model.ConfirmCancel}, previews)
+
+	// Once it is due without an answer, a call not to be sent without one is cancelled.
+	assert.NoError(t, store.AddScheduledCall(newCall("unanswered", model.ConfirmCancel, -time.Minute)))
+	assert.NoError(t, w.ProcessMessages(context.Background()))
+	assert.Empty(t, slackClient.PostMessageCalls())
+	c, err := store.GetConfirmation("unanswered")
+	assert.NoError(t, err)
+	assert.Equal(t, kv.ConfirmationCancelled, c.Status)
+	sent, err := store.ListSentMessages()
+	assert.NoError(t, err)
+	if assert.Len(t, sent, 1) {
+		assert.Equal(t, kv.StatusCancelled, sent[0].Status)
+	}
+}
+
+func TestWorker_ProcessMessagesWithApproval(t *testing.T) {
+	store := datastore.NewMockStore()
+	slackClient := slack.NewMockClient()
+	emailClient := email.NewMockClient()
+
+	viper.Set("worker.missed_lookback", "1h")
+
+	newCall := func(id string) *kv.ScheduledCall {
+		call := &kv.ScheduledCall{
+			Call: model.Call{
+				ID:               id,
+				Content:          "We have launched",
+				Destinations:     []model.Destination{{Type: "slack", To: []string{"#announcements"}}},
+				Campaign:         model.Campaign{ID: "campaign", Name: "Campaign"},
+				RequiresApproval: true,
+			},
+			ScheduledAt: time.Now().UTC().Add(-time.Minute),
+		}
+		assert.NoError(t, store.AddScheduledCall(call))
+		return call
+	}
+	launch, recall := newCall("launch"), newCall("recall")
+
+	var requested []string
+	slackClient.RequestApprovalFunc = func(_ context.Context, approverEmail, callID, shortID, summary string) error {
+		requested = append(requested, approverEmail+" "+callID)
+		return nil
+	}
+
+	w, err := worker.New(store, slackClient, emailClient, nil, nil, time.Minute, false, worker.WithApprovers("legal@example.com"))
+	assert.NoError(t, err)
+
+	// The calls are held until they are approved, and the approvers are asked once.
+	assert.NoError(t, w.ProcessMessages(context.Background()))
+	assert.NoError(t, w.ProcessMessages(context.Background()))
+	assert.Empty(t, slackClient.PostMessageCalls())
+	assert.ElementsMatch(t, []string{"legal@example.com launch", "legal@example.com recall"}, requested)
+	a, err := store.GetApproval("launch")
+	assert.NoError(t, err)
+	assert.Equal(t, kv.ApprovalPending, a.Status)
+
+	// The approved call is sent, and the rejected call is cancelled.
+	_, err = approval.Approve(store, launch, "jane", time.Now())
+	assert.NoError(t, err)
+	_, err = approval.Reject(store, recall, "jane", "not yet", time.Now())
+	assert.NoError(t, err)
+	assert.NoError(t, w.ProcessMessages(context.Background()))
+	assert.Len(t, slackClient.PostMessageCalls(), 1)
+	sm, err := store.GetSentMessage(kv.GenerateID("campaign", "recall", recall.OccurredAt(), "slack", "#announcements"))
+	assert.NoError(t, err)
+	assert.Equal(t, kv.StatusCancelled, sm.Status)
+	assert.Equal(t, "cancelled by jane: rejected: not yet", sm.Error)
+}
+
+func TestWorker_ProcessMessagesWithAutoDelete(t *testing.T) {
+	store := datastore.NewMockStore()
+	slackClient := slack.NewMockClient()
+	emailClient := email.NewMockClient()
+
+	viper.Set("worker.missed_lookback", "1h")
+
+	call := &kv.ScheduledCall{
+		Call: model.Call{
+			ID:              "lunch",
+			Content:         "Lunch is served",
+			Destinations:    []model.Destination{{Type: "slack", To: []string{"#general"}}},
+			Campaign:        model.Campaign{ID: "campaign", Name: "Campaign"},
+			AutoDeleteAfter: "24h",
+		},
+		ScheduledAt: time.Now().UTC().Add(-time.Minute),
+	}
+	assert.NoError(t, store.AddScheduledCall(call))
+
+	var deleted []string
+	slackClient.DeleteMessageFunc = func(_ context.Context, channel, timestamp string) error {
+		if timestamp == "broken" {
+			return assert.AnError
+		}
+		deleted = append(deleted, timestamp)
+		return nil
+	}
+
+	w, err := worker.New(store, slackClient, emailClient, nil, nil, time.Minute, false)
+	assert.NoError(t, err)
+
+	// The message is sent, to be deleted once its time to live has passed.
+	assert.NoError(t, w.ProcessMessages(context.Background()))
+	sent, err := store.ListSentMessages()
+	assert.NoError(t, err)
+	if assert.Len(t, sent, 1) {
+		assert.WithinDuration(t, time.Now().Add(24*time.Hour), sent[0].DeleteAt, time.Minute)
+	}
+	assert.Empty(t, deleted)
+
+	// Messages that are due are deleted, and those that cannot be deleted are tried again until the
+	// lookback has passed.
+	expired := &kv.SentMessage{Type: "slack", Destination: "C1", Timestamp: "1.1", OccurredAt: time.Now().Add(-2 * time.Hour), DeleteAt: time.Now().Add(-time.Minute)}
+	retried := &kv.SentMessage{Type: "slack", Destination: "C2", Timestamp: "broken", OccurredAt: time.Now().Add(-2 * time.Hour), DeleteAt: time.Now().Add(-time.Minute)}
+	failed := &kv.SentMessage{Type: "slack", Destination: "C3", Timestamp: "broken", OccurredAt: time.Now().Add(-3 * time.Hour), DeleteAt: time.Now().Add(-2 * time.Hour)}
+	for _, sm := range []*kv.SentMessage{expired, retried, failed} {
+		assert.NoError(t, store.AddSentMessage("other", "call", sm))
+	}
+
+	assert.NoError(t, w.ProcessMessages(context.Background()))
+	assert.Equal(t, []string{"1.1"}, deleted)
+	assert.Equal(t, kv.StatusExpired, expired.Status)
+	assert.Equal(t, kv.StatusSent, retried.Status)
+	assert.False(t, retried.DeleteAt.IsZero())
+	assert.Equal(t, kv.StatusSent, failed.Status)
+	assert.True(t, failed.DeleteAt.IsZero())
+	assert.Contains(t, failed.Error, "failed to delete")
+}
+
+func TestWorker_ReconcileSending(t *testing.T) {
+	store := datastore.NewMockStore()
+	now := time.Now().UTC()
+
+	stuck := &kv.SentMessage{Type: "slack", Destination: "#stuck", Status: kv.StatusSending, AttemptedAt: now.Add(-time.Hour)}
+	inFlight := &kv.SentMessage{Type: "slack", Destination: "#in-flight", Status: kv.StatusSending, AttemptedAt: now.Add(-30 * time.Second)}
+	sent := &kv.SentMessage{Type: "slack", Destination: "#sent", Status: kv.StatusSent, AttemptedAt: now.Add(-time.Hour)}
+	for _, sm := range []*kv.SentMessage{stuck, inFlight, sent} {
+		assert.NoError(t, store.AddSentMessage("campaign", "call", sm))
+	}
+
+	w, err := worker.New(store, slack.NewMockClient(), email.NewMockClient(), nil, nil, time.Minute, false, worker.WithSendTimeout(time.Minute))
+	assert.NoError(t, err)
+
+	// Only the message that has been sending for longer than sending it can take is interrupted.
+	interrupted, err := w.ReconcileSending(now)
+	assert.NoError(t, err)
+	if assert.Len(t, interrupted, 1) {
+		assert.Equal(t, "#stuck", interrupted[0].Destination)
+	}
+	assert.Equal(t, kv.StatusInterrupted, stuck.Status)
+	assert.NotEmpty(t, stuck.Error)
+	assert.Equal(t, kv.StatusSending, inFlight.Status)
+	assert.Equal(t, kv.StatusSent, sent.Status)
+
+	// The interrupted message is not sent again.
+	settled, err := store.HasBeenSent("campaign", "call", time.Time{}, "slack", "#stuck")
+	assert.NoError(t, err)
+	assert.True(t, settled)
+}
+
+func TestWorker_ProcessMessagesWithUndoWindow(t *testing.T) {
+	store := datastore.NewMockStore()
+	slackClient := slack.NewMockClient()
+	viper.Set("worker.missed_lookback", "1h")
+	viper.Set("worker.calculation.before", "24h")
+	viper.Set("worker.calculation.after", "24h")
+
+	newCall := func(id string, scheduledAt time.Time) *kv.ScheduledCall {
+		return &kv.ScheduledCall{
+			Call: model.Call{
+				ID:           id,
+				Content:      "Hello",
+				Destinations: []model.Destination{{Type: "slack", To: []string{"#general"}}},
+				Campaign:     model.Campaign{ID: "campaign", Name: "Campaign"},
+			},
+			ScheduledAt: scheduledAt,
+		}
+	}
+	now := time.Now().UTC()
+	held, cancelled, due := newCall("held", now.Add(-time.Minute)), newCall("cancelled", now.Add(-time.Minute)), newCall("due", now.Add(-10*time.Minute))
+	for _, call := range []*kv.ScheduledCall{held, cancelled, due} {
+		assert.NoError(t, store.AddScheduledCall(call))
+	}
+
+	w, err := worker.New(store, slackClient, email.NewMockClient(), nil, nil, time.Minute, false, worker.WithUndoWindow(5*time.Minute))
+	assert.NoError(t, err)
+
+	// Calls within the window are held, with their recipients recorded as pending, and the rest are sent.
+	assert.NoError(t, w.ProcessMessages(context.Background()))
+	if assert.Len(t, slackClient.PostMessageCalls(), 1) {
+		assert.Equal(t, "#general", slackClient.PostMessageCalls()[0].Destination)
+	}
+	for _, call := range []*kv.ScheduledCall{held, cancelled} {
+		sm, err := store.GetSentMessage(kv.GenerateID("campaign", call.ID, call.OccurredAt(), "slack", "#general"))
+		if assert.NoError(t, err) {
+			assert.Equal(t, kv.StatusPending, sm.Status)
+		}
+		_, err = store.GetScheduledCall(call.ID)
+		assert.NoError(t, err)
+	}
+
+	// Once the window has passed, here by shortening it, the held call is sent, and the one cancelled
+	// within it is not.
+	assert.NoError(t, store.CancelCall(&kv.Cancellation{CallID: "cancelled", By: "jane", At: now}))
+	w, err = worker.New(store, slackClient, email.NewMockClient(), nil, nil, time.Minute, false, worker.WithUndoWindow(30*time.Second))
+	assert.NoError(t, err)
+	assert.NoError(t, w.ProcessMessages(context.Background()))
+	assert.Len(t, slackClient.PostMessageCalls(), 2)
+	sm, err := store.GetSentMessage(kv.GenerateID("campaign", "cancelled", cancelled.OccurredAt(), "slack", "#general"))
+	if assert.NoError(t, err) {
+		assert.Equal(t, kv.StatusCancelled, sm.Status)
+	}
+}
+
+func TestWorker_FailureChannel(t *testing.T) {
+	store := datastore.NewMockStore()
+	slackClient := slack.NewMockClient()
+	emailClient := email.NewMockClient()
+	s := &mockSourcer{
+		sourcesBySource: map[string]*sourcer.Source{
+			"mock://url": {
+				Calls: []model.Call{
+					{
+						ID:           "missed",
+						Subject:      "Launch",
+						Content:      "Hello, world!",
+						Destinations: []model.Destination{{Type: "slack", To: []string{"#general"}}},
+						Triggers:     []model.Trigger{{ScheduledAt: time.Now().Add(-2 * time.Hour)}},
+						Campaign:     model.Campaign{ID: "campaign", Name: "Campaign"},
+					},
+				},
+				Invalid: []*sourcer.ValidationError{
+					{URL: "mock://url/broken.yaml", Fields: []sourcer.FieldError{{Field: "calls.0.content", Description: "is required"}}},
+				},
+			},
+		},
+	}
+
+	viper.Set("source.urls", []string{"mock://url"})
+	viper.Set("worker.missed_lookback", "10m")
+	viper.Set("worker.calculation.before", "24h")
+	viper.Set("worker.calculation.after", "24h")
+
+	w, err := worker.New(store, slackClient, emailClient, poller.New(s, time.Minute), scheduler.New(store), time.Minute, false,
+		worker.WithFailureChannel(model.Destination{Type: "email", To: []string{"ops@example.com"}}))
+	assert.NoError(t, err)
+
+	assert.NoError(t, w.RefreshSources())
+	if assert.Len(t, emailClient.SendCalls(), 1) {
+		assert.Equal(t, []string{"ops@example.com"}, emailClient.SendCalls()[0].To)
+		assert.Equal(t, "Source 'mock://url' is not valid", emailClient.SendCalls()[0].Subject)
+		assert.Contains(t, emailClient.SendCalls()[0].Body, "calls.0.content: is required")
+	}
+
+	// A call that is missed is reported too.
+	assert.NoError(t, w.ProcessMessages(context.Background()))
+	if assert.Len(t, emailClient.SendCalls(), 2) {
+		assert.Equal(t, "Missed 'Launch'", emailClient.SendCalls()[1].Subject)
+		assert.Contains(t, emailClient.SendCalls()[1].Body, "#general")
+		assert.Contains(t, emailClient.SendCalls()[1].Body, "ruf dispatcher replay ")
+	}
+
+	// The source is reported once, rather than on every poll.
+	assert.NoError(t, w.RefreshSources())
+	assert.Len(t, emailClient.SendCalls(), 2)
+	assert.Empty(t, slackClient.PostMessageCalls())
+}
+
+func TestNextTick(t *testing.T) {
+	at := func(value string) time.Time {
+		v, err := time.Parse(time.RFC3339Nano, value)
+		assert.NoError(t, err)
+		return v
+	}
+
+	tests := []struct {
+		name     string
+		now      string
+		interval time.Duration
+		want     string
+	}{
+		{"start of the next minute", "2025-03-04T09:59:47Z", time.Minute, "2025-03-04T10:00:00Z"},
+		{"on a tick", "2025-03-04T10:00:00Z", time.Minute, "2025-03-04T10:01:00Z"},
+		{"just after a tick", "2025-03-04T10:00:00.001Z", time.Minute, "2025-03-04T10:01:00Z"},
+		{"default interval", "2025-03-04T09:59:47Z", 0, "2025-03-04T10:00:00Z"},
+		{"multiple of the interval", "2025-03-04T10:03:12Z", 5 * time.Minute, "2025-03-04T10:05:00Z"},
+		{"across midnight", "2025-03-04T23:59:30Z", time.Minute, "2025-03-05T00:00:00Z"},
+		{"interval that does not divide a day", "2025-03-04T23:50:00Z", 7 * time.Hour, "2025-03-05T00:00:00Z"},
+		{"aligned in UTC", "2025-03-04T10:59:47+05:30", 15 * time.Minute, "2025-03-04T11:00:00+05:30"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := worker.NextTick(at(tt.now), tt.interval)
+			assert.True(t, at(tt.want).Equal(got), "got %s", got)
+		})
+	}
+}
@@ -6,6 +6,18 @@ package main
 
 import "github.com/andrewhowdencom/ruf/cmd"
 
+// Build information, populated by goreleaser at link time.
+var (
+	version = "dev"
+	commit  = "none"
+	date    = "unknown"
+	// releaseKey is the base64 encoded ed25519 key releases are signed with, set with
+	// -ldflags "-X main.releaseKey=...".
+	releaseKey = ""
+)
+
 func main() {
+	cmd.SetVersionInfo(version, commit, date)
+	cmd.SetReleaseKey(releaseKey)
 	cmd.Execute()
 }
@@ -0,0 +1,32 @@
+// Package schema embeds the JSON schemas that source files are validated against, so that they are
+// available wherever ruf is run from rather than only next to its source.
+package schema
+
+import _ "embed"
+
+// The versions of the source file format, given by the apiVersion of a file.
+const (
+	// V1 is the format in which the destinations of a call are given on the call.
+	V1 = "v1"
+	// V2 is the format in which triggers may also have destinations of their own.
+	V2 = "v2"
+	// Latest is the version of files that do not give one.
+	Latest = V2
+)
+
+// Calls is the schema of the latest version of the format, which the schemas of the other versions
+// refer to by its ID.
+//
+//go:embed calls.json
+var Calls []byte
+
+//go:embed calls.v1.json
+var callsV1 []byte
+
+// Versions returns the schemas of the versions of the format, by version.
+func Versions() map[string][]byte {
+	return map[string][]byte{
+		V1: callsV1,
+		V2: Calls,
+	}
+}